@@ -0,0 +1,213 @@
+// Package audit records the configuration and admin actions.
+package audit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ActorSystem is the actor of the actions taken by the gateway itself, such as the config reloads.
+const ActorSystem = "system"
+
+var _metricRecords = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "audit_records_total",
+	Help:      "The total number of audit records written to the sinks",
+}, []string{"sink", "result"})
+
+func init() {
+	metrics.Register(_metricRecords)
+}
+
+// Record is an audit record of an action.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"`
+	Remote    string    `json:"remote,omitempty"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Before    string    `json:"before,omitempty"`
+	After     string    `json:"after,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+}
+
+// Sink writes the audit records.
+type Sink interface {
+	Name() string
+	Write(*Record) error
+}
+
+// Reader reads the audit records in the order they were written.
+type Reader interface {
+	Read(offset, limit int) ([]*Record, int, error)
+}
+
+var (
+	lock  sync.RWMutex
+	sinks []Sink
+)
+
+// SetSinks replaces the sinks of the audit records, the records are only logged to the audit stream without sinks
+// or also logged if the audit stream is configured.
+func SetSinks(in ...Sink) {
+	lock.Lock()
+	defer lock.Unlock()
+	sinks = in
+}
+
+// Write writes the record to all the sinks, the action must not take effect if it fails.
+func Write(rec *Record) error {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	lock.RLock()
+	defer lock.RUnlock()
+	if len(sinks) == 0 || logs.Audit.Configured() {
+		b, _ := json.Marshal(rec)
+		log.NewHelper(logs.Audit).Infof("audit: %s", b)
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Write(rec); err != nil {
+			_metricRecords.WithLabelValues(sink.Name(), "failure").Inc()
+			errs = append(errs, err)
+			continue
+		}
+		_metricRecords.WithLabelValues(sink.Name(), "success").Inc()
+	}
+	return errors.Join(errs...)
+}
+
+// WriteRequest writes the record of the action requested by req.
+func WriteRequest(req *http.Request, rec *Record) error {
+	rec.Actor = Actor(req)
+	rec.Remote = req.RemoteAddr
+	rec.RequestID = req.Header.Get("X-Request-Id")
+	return Write(rec)
+}
+
+func reader() (Reader, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	for _, sink := range sinks {
+		if r, ok := sink.(Reader); ok {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// Actor returns the actor of the request from the client certificate or the credentials,
+// the token is not verified, the admin endpoints are expected to be protected by the network.
+func Actor(req *http.Request) string {
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return "cert:" + req.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	if user, _, ok := req.BasicAuth(); ok {
+		return "basic:" + user
+	}
+	auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(auths) == 2 && strings.EqualFold(auths[0], "Bearer") {
+		if sub := tokenSubject(auths[1]); sub != "" {
+			return "token:" + sub
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "anonymous@" + host
+}
+
+func tokenSubject(token string) string {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := struct {
+		Subject string `json:"sub"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	return claims.Subject
+}
+
+const (
+	_defaultPageSize = 100
+	_maxPageSize     = 1000
+)
+
+type debugger struct{}
+
+// Debugger is the debug handler to read the audit records, eg:
+// curl /debug/audit/records?offset=0&limit=100
+var Debugger = debugger{}
+
+// Page is a page of the audit records.
+type Page struct {
+	Records    []*Record `json:"records"`
+	Total      int       `json:"total"`
+	NextOffset int       `json:"nextOffset,omitempty"`
+}
+
+// DebugHandler implemented debug handler.
+func (debugger) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	debugMux.HandleFunc("/debug/audit/records", func(rw http.ResponseWriter, r *http.Request) {
+		rd, ok := reader()
+		if !ok {
+			http.Error(rw, "audit file is not configured", http.StatusNotFound)
+			return
+		}
+		offset, limit := 0, _defaultPageSize
+		if v := r.FormValue("offset"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(rw, "invalid offset", http.StatusBadRequest)
+				return
+			}
+			offset = n
+		}
+		if v := r.FormValue("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				http.Error(rw, "invalid limit", http.StatusBadRequest)
+				return
+			}
+			limit = min(n, _maxPageSize)
+		}
+		records, total, err := rd.Read(offset, limit)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		page := &Page{Records: records, Total: total}
+		if next := offset + len(records); next < total {
+			page.NextOffset = next
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(page)
+	})
+	return debugMux
+}
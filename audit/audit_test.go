@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 256, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	SetSinks(sink)
+	defer SetSinks()
+
+	for i := 0; i < 10; i++ {
+		if err := Write(&Record{Actor: ActorSystem, Action: "config.reload", Target: fmt.Sprintf("v%d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(path + ".2"); err != nil {
+		t.Fatalf("want rotated files: %v", err)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("want backups beyond max removed: %v", err)
+	}
+	records, total, err := sink.Read(0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(records) || total == 0 || records[total-1].Target != "v9" {
+		t.Fatalf("unexpected records: %d %+v", total, records)
+	}
+	// the records are kept in the written order across the rotated files
+	first := records[0].Target
+
+	w := httptest.NewRecorder()
+	Debugger.DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/audit/records?offset=1&limit=2", nil))
+	page := &Page{}
+	if err := json.Unmarshal(w.Body.Bytes(), page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != total || len(page.Records) != 2 || page.NextOffset != 3 || page.Records[0].Target == first {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+}
+
+func TestActor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/debug/admin/drain-node", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	if got := Actor(req); got != "anonymous@10.0.0.1" {
+		t.Fatalf("unexpected actor: %s", got)
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"alice"}`))
+	req.Header.Set("Authorization", "Bearer header."+payload+".signature")
+	if got := Actor(req); got != "token:alice" {
+		t.Fatalf("unexpected actor: %s", got)
+	}
+	req.SetBasicAuth("bob", "secret")
+	if got := Actor(req); got != "basic:bob" {
+		t.Fatalf("unexpected actor: %s", got)
+	}
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const _defaultMaxSize = 100 << 20
+
+// FileSink appends the records to a JSONL file, the file is rotated to path.1, path.2 ... when it exceeds the max size.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens the audit file, maxSize is in bytes and the rotated files beyond maxBackups are removed.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if maxSize <= 0 {
+		maxSize = _defaultMaxSize
+	}
+	s := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file, s.size = f, info.Size()
+	return nil
+}
+
+func (s *FileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		_ = os.Remove(s.backup(s.maxBackups))
+		for i := s.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(s.path, s.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Write appends the record and syncs the file before returning.
+func (s *FileSink) Write(rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.size > 0 && s.size+int64(len(b)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(b)
+	s.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Read returns the records from offset of all the files, oldest first, and the total number of records.
+func (s *FileSink) Read(offset, limit int) ([]*Record, int, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	paths := make([]string, 0, s.maxBackups+1)
+	for i := s.maxBackups; i > 0; i-- {
+		paths = append(paths, s.backup(i))
+	}
+	paths = append(paths, s.path)
+	var out []*Record
+	total := 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 16<<20)
+		for scanner.Scan() {
+			if total >= offset && len(out) < limit {
+				rec := &Record{}
+				if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+					f.Close()
+					return nil, 0, fmt.Errorf("%s: %w", path, err)
+				}
+				out = append(out, rec)
+			}
+			total++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return out, total, nil
+}
+
+func (s *FileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
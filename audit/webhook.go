@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const _webhookQueueSize = 1024
+
+var _webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// WebhookSink posts the records to the webhook asynchronously, the records are dropped if the queue is full,
+// so it is used along with the file sink which is written synchronously.
+type WebhookSink struct {
+	url   string
+	queue chan *Record
+	done  chan struct{}
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	s := &WebhookSink{
+		url:   url,
+		queue: make(chan *Record, _webhookQueueSize),
+		done:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Write(rec *Record) error {
+	select {
+	case s.queue <- rec:
+		return nil
+	default:
+		_metricRecords.WithLabelValues(s.Name(), "dropped").Inc()
+		log.Errorf("Audit webhook queue is full, record %s dropped", rec.Action)
+		return nil
+	}
+}
+
+func (s *WebhookSink) run() {
+	defer close(s.done)
+	for rec := range s.queue {
+		if err := s.post(rec); err != nil {
+			_metricRecords.WithLabelValues(s.Name(), "delivery_failure").Inc()
+			log.Errorf("Failed to post audit record %s to webhook: %+v", rec.Action, err)
+		}
+	}
+}
+
+func (s *WebhookSink) post(rec *Record) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := _webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close delivers the queued records.
+func (s *WebhookSink) Close() error {
+	close(s.queue)
+	<-s.done
+	return nil
+}
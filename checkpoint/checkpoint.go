@@ -0,0 +1,318 @@
+// Package checkpoint persists the counters of the gateway across the restarts, so the long window queries, eg:
+// the error budgets of the SLOs, see the counters continue rather than reset by the deploys.
+//
+// The counter vectors are registered by their packages, the checkpointer snapshots them to a local file
+// periodically and the next process adds the values of the snapshot to them before it serves.
+package checkpoint
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	_defaultInterval = time.Minute
+	_defaultMaxBytes = 8 << 20
+)
+
+var (
+	_metricRestored = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "counter_checkpoint_restored_info",
+		Help:      "The counters are restored from the checkpoint of the process, 1 if restored",
+	}, []string{"from_instance", "from_start"})
+	_metricSnapshots = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "counter_checkpoint_snapshots_total",
+		Help:      "The total number of the counter checkpoint snapshots by the result",
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Register(_metricRestored, _metricSnapshots)
+}
+
+// the identity of the process, a snapshot is never restored by the process writing it
+var (
+	_instance     = newInstanceID()
+	_processStart = time.Now()
+	// the counters are restored once in a process
+	_restored atomic.Bool
+)
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d-%d", os.Getpid(), time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+var (
+	_countersLock sync.Mutex
+	_counters     = map[string]*prometheus.CounterVec{}
+)
+
+// Register registers the counter vector by the name of its metric, eg: go_gateway_requests_code_total, the
+// registered vectors are checkpointed if selected.
+func Register(name string, vec *prometheus.CounterVec) {
+	_countersLock.Lock()
+	defer _countersLock.Unlock()
+	_counters[name] = vec
+}
+
+// Registered returns the names of the registered counter vectors.
+func Registered() []string {
+	_countersLock.Lock()
+	defer _countersLock.Unlock()
+	names := make([]string, 0, len(_counters))
+	for name := range _counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config is the config of the checkpointer.
+type Config struct {
+	// File is the snapshot file, written atomically.
+	File string
+	// Interval is the interval of the snapshots, default is 1 minute.
+	Interval time.Duration
+	// Counters are the names of the registered counter vectors checkpointed, all if empty.
+	Counters []string
+	// MaxBytes bounds the size of the snapshot, the snapshot is not written over it, default is 8MB.
+	MaxBytes int64
+	// MaxAge is the max age of the snapshot restored, unlimited if 0.
+	MaxAge time.Duration
+}
+
+// Checkpointer snapshots the selected counter vectors to the file.
+type Checkpointer struct {
+	file     string
+	interval time.Duration
+	maxBytes int64
+	maxAge   time.Duration
+	counters map[string]*prometheus.CounterVec
+
+	lock sync.Mutex
+}
+
+// snapshot is the content of the checkpoint file.
+type snapshot struct {
+	// the identity of the process writing the snapshot
+	Instance     string    `json:"instance"`
+	ProcessStart time.Time `json:"process_start"`
+	Written      time.Time `json:"written"`
+	// the series of the counter vectors by their names
+	Counters map[string][]series `json:"counters"`
+}
+
+type series struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// New creates the checkpointer of the selected counter vectors.
+func New(c Config) (*Checkpointer, error) {
+	if c.File == "" {
+		return nil, errors.New("checkpoint: file is required")
+	}
+	cp := &Checkpointer{
+		file:     c.File,
+		interval: c.Interval,
+		maxBytes: c.MaxBytes,
+		maxAge:   c.MaxAge,
+		counters: map[string]*prometheus.CounterVec{},
+	}
+	if cp.interval <= 0 {
+		cp.interval = _defaultInterval
+	}
+	if cp.maxBytes <= 0 {
+		cp.maxBytes = _defaultMaxBytes
+	}
+	names := c.Counters
+	if len(names) == 0 {
+		names = Registered()
+	}
+	_countersLock.Lock()
+	defer _countersLock.Unlock()
+	for _, name := range names {
+		vec, ok := _counters[name]
+		if !ok {
+			return nil, fmt.Errorf("checkpoint: counter %s is not registered", name)
+		}
+		cp.counters[name] = vec
+	}
+	return cp, nil
+}
+
+// Restore adds the values of the snapshot to the counter vectors, it is called before the requests are served.
+// The snapshot is skipped if the counters are restored already by the process, the snapshot is written by the
+// process itself, or the times of the snapshot are inconsistent with the clock, eg: written in the future.
+func (c *Checkpointer) Restore() error {
+	info, err := os.Stat(c.file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() > c.maxBytes {
+		return fmt.Errorf("checkpoint: the snapshot of %d bytes is over the max size %d", info.Size(), c.maxBytes)
+	}
+	data, err := os.ReadFile(c.file)
+	if err != nil {
+		return err
+	}
+	s := &snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return fmt.Errorf("checkpoint: invalid snapshot: %w", err)
+	}
+	if err := c.check(s, time.Now()); err != nil {
+		return err
+	}
+	if !_restored.CompareAndSwap(false, true) {
+		return errors.New("checkpoint: the counters are restored already")
+	}
+	restored := 0
+	for name, all := range s.Counters {
+		vec, ok := c.counters[name]
+		if !ok {
+			continue
+		}
+		for _, series := range all {
+			counter, err := vec.GetMetricWith(series.Labels)
+			if err != nil {
+				// the labels of the counter are changed by the upgrade
+				log.Warnf("checkpoint: skip the series of %s: %v", name, err)
+				continue
+			}
+			counter.Add(series.Value)
+			restored++
+		}
+	}
+	_metricRestored.WithLabelValues(s.Instance, s.ProcessStart.UTC().Format(time.RFC3339)).Set(1)
+	log.Infof("checkpoint: restored %d series written at %s by the process %s started at %s", restored, s.Written, s.Instance, s.ProcessStart)
+	return nil
+}
+
+func (c *Checkpointer) check(s *snapshot, now time.Time) error {
+	switch {
+	case s.Instance == _instance:
+		return errors.New("checkpoint: the snapshot is written by the process itself")
+	case s.Written.Before(s.ProcessStart):
+		return fmt.Errorf("checkpoint: the snapshot is written at %s before its process started at %s", s.Written, s.ProcessStart)
+	case s.Written.After(now):
+		return fmt.Errorf("checkpoint: the snapshot is written at %s in the future", s.Written)
+	case s.ProcessStart.After(_processStart):
+		// another process is writing the file, or the clock is set back
+		return fmt.Errorf("checkpoint: the process of the snapshot started at %s after this process", s.ProcessStart)
+	case c.maxAge > 0 && now.Sub(s.Written) > c.maxAge:
+		return fmt.Errorf("checkpoint: the snapshot written at %s is older than %s", s.Written, c.maxAge)
+	}
+	return nil
+}
+
+// Snapshot writes the current values of the counter vectors to the file.
+func (c *Checkpointer) Snapshot() (err error) {
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		_metricSnapshots.WithLabelValues(result).Inc()
+	}()
+	s := &snapshot{
+		Instance:     _instance,
+		ProcessStart: _processStart,
+		Written:      time.Now(),
+		Counters:     make(map[string][]series, len(c.counters)),
+	}
+	for name, vec := range c.counters {
+		s.Counters[name] = collect(vec)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > c.maxBytes {
+		return fmt.Errorf("checkpoint: the snapshot of %d bytes is over the max size %d", len(data), c.maxBytes)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return writeFileAtomic(c.file, data)
+}
+
+// Run writes the snapshots until ctx is done, and the last one then.
+func (c *Checkpointer) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := c.Snapshot(); err != nil {
+				log.Errorf("checkpoint: failed to write the last snapshot: %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := c.Snapshot(); err != nil {
+				log.Errorf("checkpoint: failed to write the snapshot: %v", err)
+			}
+		}
+	}
+}
+
+func collect(vec *prometheus.CounterVec) []series {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+	var out []series
+	for m := range ch {
+		pb := &dto.Metric{}
+		if err := m.Write(pb); err != nil || pb.Counter == nil {
+			continue
+		}
+		labels := make(map[string]string, len(pb.Label))
+		for _, l := range pb.Label {
+			labels[l.GetName()] = l.GetValue()
+		}
+		out = append(out, series{Labels: labels, Value: pb.Counter.GetValue()})
+	}
+	return out
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,131 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+var _testCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "test_checkpoint_requests_total",
+}, []string{"code"})
+
+func init() {
+	Register("test_checkpoint_requests_total", _testCounter)
+}
+
+// rewrite changes the snapshot as if it is written by another process.
+func rewrite(t *testing.T, file string, fn func(*snapshot)) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		t.Fatal(err)
+	}
+	fn(s)
+	if data, err = json.Marshal(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	_testCounter.Reset()
+	file := filepath.Join(t.TempDir(), "counters.json")
+	cp, err := New(Config{File: file, Counters: []string{"test_checkpoint_requests_total"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// no snapshot yet
+	if err := cp.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	_testCounter.WithLabelValues("200").Add(10)
+	_testCounter.WithLabelValues("500").Add(2)
+	if err := cp.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Restore(); err == nil {
+		t.Fatal("want the snapshot of the process itself skipped")
+	}
+
+	// the next process
+	_testCounter.Reset()
+	_restored.Store(false)
+	rewrite(t, file, func(s *snapshot) {
+		s.Instance = "previous"
+		s.ProcessStart = _processStart.Add(-time.Hour)
+	})
+	if err := cp.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	if v := testutil.ToFloat64(_testCounter.WithLabelValues("200")); v != 10 {
+		t.Fatalf("want 10 restored but got %v", v)
+	}
+	if v := testutil.ToFloat64(_testCounter.WithLabelValues("500")); v != 2 {
+		t.Fatalf("want 2 restored but got %v", v)
+	}
+	if v := testutil.ToFloat64(_metricRestored); v != 1 {
+		t.Fatalf("want the restored info set but got %v", v)
+	}
+	if err := cp.Restore(); err == nil {
+		t.Fatal("want the counters restored once")
+	}
+}
+
+func TestCheckpointClock(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "counters.json")
+	cp, err := New(Config{File: file, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for name, fn := range map[string]func(*snapshot){
+		"future":        func(s *snapshot) { s.Written = now.Add(time.Hour) },
+		"before start":  func(s *snapshot) { s.ProcessStart = s.Written.Add(time.Minute) },
+		"later process": func(s *snapshot) { s.ProcessStart, s.Written = now, now },
+		"too old":       func(s *snapshot) { s.ProcessStart, s.Written = now.Add(-3*time.Hour), now.Add(-2*time.Hour) },
+	} {
+		if err := cp.Snapshot(); err != nil {
+			t.Fatal(err)
+		}
+		rewrite(t, file, func(s *snapshot) {
+			s.Instance = "previous"
+			fn(s)
+		})
+		if err := cp.Restore(); err == nil {
+			t.Fatalf("want the snapshot %s skipped", name)
+		}
+	}
+}
+
+func TestCheckpointMaxBytes(t *testing.T) {
+	_testCounter.WithLabelValues("200").Inc()
+	file := filepath.Join(t.TempDir(), "counters.json")
+	cp, err := New(Config{File: file, MaxBytes: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Snapshot(); err == nil {
+		t.Fatal("want the snapshot over the max size not written")
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("want no snapshot file but got %v", err)
+	}
+	if _, err := New(Config{File: file, Counters: []string{"unknown_total"}}); err == nil {
+		t.Fatal("want the unregistered counter rejected")
+	}
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"time"
@@ -12,6 +13,12 @@ import (
 type client struct {
 	applier  *nodeApplier
 	selector selector.Selector
+	sticky   *stickyCookie
+	// empty handles the requests while the endpoint has zero nodes, fail fast if nil
+	empty *emptyUpstream
+	// the time waiting for the response headers once the request is written, unlimited if 0
+	headerTimeout time.Duration
+	conns         *connTracer
 }
 
 type Client interface {
@@ -19,33 +26,57 @@ type Client interface {
 	io.Closer
 }
 
-func newClient(applier *nodeApplier, selector selector.Selector) *client {
+func newClient(applier *nodeApplier, selector selector.Selector, sticky *stickyCookie) *client {
 	return &client{
 		applier:  applier,
 		selector: selector,
+		sticky:   sticky,
 	}
 }
 
 func (c *client) Close() error {
 	c.applier.Cancel()
-	return nil
+	c.applier.decorated.close()
+	return c.empty.Close()
 }
 
 func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	if c.applier.empty() {
+		resp, retry, err := c.empty.roundTrip(c.applier, req)
+		if !retry {
+			return resp, err
+		}
+	}
 	ctx := req.Context()
 	reqOpt, _ := middleware.FromRequestContext(ctx)
 	filter, _ := middleware.SelectorFiltersFromContext(ctx)
+	var noMatch bool
+	if c.applier.filter != nil {
+		filter = append(filter[:len(filter):len(filter)], c.applier.filter.selectorFilter(&noMatch))
+	}
+	var stickyAddr string
+	if c.sticky != nil {
+		if addr, ok := c.sticky.address(req); ok {
+			stickyAddr = addr
+			filter = append(filter[:len(filter):len(filter)], c.sticky.filter(addr))
+		}
+	}
 	n, done, err := c.selector.Select(ctx, selector.WithNodeFilter(filter...))
 	if err != nil {
+		if noMatch || (errors.Is(err, selector.ErrNoAvailable) && c.applier.noMatch.Load()) {
+			return nil, ErrNoMatchingNodes
+		}
 		return nil, err
 	}
 	reqOpt.CurrentNode = n
 
 	addr := n.Address()
 	reqOpt.Backends = append(reqOpt.Backends, addr)
+	middleware.UpstreamAddr.Set(reqOpt, addr)
 	backendNode := n.(*node)
 	req.URL.Host = addr
 	req.URL.Scheme = "http"
+	backendNode.pathPrefix.apply(req.URL)
 	if backendNode.tls {
 		req.URL.Scheme = "https"
 		req.Host = addr
@@ -54,15 +85,29 @@ func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 		req.Host = nodeHost
 	}
 	req.RequestURI = ""
+	hooks := reqOpt.UpstreamRequestHooks
+	reqOpt.UpstreamRequestHooks = nil
+	for _, hook := range hooks {
+		if err := hook(req); err != nil {
+			done(ctx, selector.DoneInfo{Err: err})
+			return nil, err
+		}
+	}
 	startAt := time.Now()
-	resp, err = backendNode.client.Do(req)
+	tracedReq, trace := c.conns.start(req, backendNode)
+	resp, err = doWithHeaderTimeout(backendNode.client, tracedReq, c.headerTimeout)
+	c.conns.done(trace)
 	reqOpt.UpstreamResponseTime = append(reqOpt.UpstreamResponseTime, time.Since(startAt).Seconds())
 	if err != nil {
+		err = classifyUpstreamProtocolError(req, backendNode.upstreamProtocol, err)
 		done(ctx, selector.DoneInfo{Err: err})
 		reqOpt.UpstreamStatusCode = append(reqOpt.UpstreamStatusCode, 0)
 		return nil, err
 	}
 	reqOpt.UpstreamStatusCode = append(reqOpt.UpstreamStatusCode, resp.StatusCode)
+	if c.sticky != nil && addr != stickyAddr {
+		c.sticky.setCookie(resp.Header, addr)
+	}
 	reqOpt.DoneFunc = done
 	return resp, nil
 }
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// ConnMetrics is the granularity of the upstream connection metrics.
+type ConnMetrics int32
+
+const (
+	// ConnMetricsService labels the connection metrics by the service of the endpoint, the node label is empty.
+	ConnMetricsService ConnMetrics = iota
+	// ConnMetricsNode labels the connection metrics by the address of the node as well.
+	ConnMetricsNode
+	// ConnMetricsOff disables the connection metrics, the upstream connections are not traced at all.
+	ConnMetricsOff
+)
+
+var _connMetricsNames = map[string]ConnMetrics{"service": ConnMetricsService, "node": ConnMetricsNode, "off": ConnMetricsOff}
+
+// ParseConnMetrics parses the granularity of the connection metrics: service, node or off.
+func ParseConnMetrics(s string) (ConnMetrics, error) {
+	m, ok := _connMetricsNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown upstream connection metrics %q, want service, node or off", s)
+	}
+	return m, nil
+}
+
+var _connMetrics atomic.Int32
+
+// SetConnMetrics sets the granularity of the upstream connection metrics, it applies to the following requests.
+func SetConnMetrics(m ConnMetrics) {
+	_connMetrics.Store(int32(m))
+}
+
+var (
+	_metricConnDNSSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_conn_dns_seconds",
+		Help:      "The time of the DNS lookups of the new upstream connections",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"service", "node"})
+	_metricConnConnectSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_conn_connect_seconds",
+		Help:      "The time of the TCP connects of the new upstream connections",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"service", "node"})
+	_metricConnTLSSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_conn_tls_handshake_seconds",
+		Help:      "The time of the TLS handshakes of the new upstream connections",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"service", "node"})
+	_metricConnWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_conn_wait_seconds",
+		Help:      "The time the requests wait for an upstream connection, the idle pool wait if reused and the dial otherwise, the connect phase of the server timing",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+	}, []string{"service", "node", "reused"})
+	_metricConnAcquired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_conn_acquired_total",
+		Help:      "The total number of the upstream connections got by the requests, reused from the pool or dialed",
+	}, []string{"service", "node", "reused"})
+)
+
+func init() {
+	metrics.Register(_metricConnDNSSeconds, _metricConnConnectSeconds, _metricConnTLSSeconds, _metricConnWaitSeconds, _metricConnAcquired)
+}
+
+// endpointService returns the service label of the connection metrics of the endpoint, the service of the
+// metadata or the target of the first backend.
+func endpointService(e *config.Endpoint) string {
+	if s := e.Metadata["service"]; s != "" {
+		return s
+	}
+	if len(e.Backends) > 0 {
+		return e.Backends[0].Target
+	}
+	return ""
+}
+
+type connLabels struct {
+	service string
+	node    string
+}
+
+// connTrace records the connection metrics of the requests, it is pooled by the client so the hooks are
+// allocated once. The trace of a dial outliving its request is not pooled again, so the dial is not recorded
+// with the labels of the next request, eg: its node.
+type connTrace struct {
+	trace  httptrace.ClientTrace
+	labels atomic.Pointer[connLabels]
+	// the dns lookups, connects and tls handshakes in progress
+	dialing atomic.Int32
+
+	getConn      atomic.Int64
+	dnsStart     atomic.Int64
+	connectStart atomic.Int64
+	tlsStart     atomic.Int64
+}
+
+func newConnTrace() *connTrace {
+	t := &connTrace{}
+	t.trace = httptrace.ClientTrace{
+		GetConn: func(string) { t.getConn.Store(time.Now().UnixNano()) },
+		GotConn: t.gotConn,
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.dialing.Add(1)
+			t.dnsStart.Store(time.Now().UnixNano())
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			defer t.dialing.Add(-1)
+			if info.Err == nil {
+				t.observe(_metricConnDNSSeconds, &t.dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) {
+			t.dialing.Add(1)
+			t.connectStart.Store(time.Now().UnixNano())
+		},
+		ConnectDone: func(_, _ string, err error) {
+			defer t.dialing.Add(-1)
+			if err == nil {
+				t.observe(_metricConnConnectSeconds, &t.connectStart)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.dialing.Add(1)
+			t.tlsStart.Store(time.Now().UnixNano())
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			defer t.dialing.Add(-1)
+			if err == nil {
+				t.observe(_metricConnTLSSeconds, &t.tlsStart)
+			}
+		},
+	}
+	return t
+}
+
+func (t *connTrace) observe(h *prometheus.HistogramVec, start *atomic.Int64) {
+	since := start.Load()
+	if since == 0 {
+		return
+	}
+	labels := t.labels.Load()
+	h.WithLabelValues(labels.service, labels.node).Observe(time.Since(time.Unix(0, since)).Seconds())
+}
+
+func (t *connTrace) gotConn(info httptrace.GotConnInfo) {
+	labels := t.labels.Load()
+	reused := strconv.FormatBool(info.Reused)
+	_metricConnAcquired.WithLabelValues(labels.service, labels.node, reused).Inc()
+	if since := t.getConn.Load(); since != 0 {
+		_metricConnWaitSeconds.WithLabelValues(labels.service, labels.node, reused).Observe(time.Since(time.Unix(0, since)).Seconds())
+	}
+}
+
+// connTracer traces the upstream connections of the requests of a client.
+type connTracer struct {
+	service *connLabels
+	traces  sync.Pool
+}
+
+func newConnTracer(e *config.Endpoint) *connTracer {
+	return &connTracer{
+		service: &connLabels{service: endpointService(e)},
+		traces:  sync.Pool{New: func() any { return newConnTrace() }},
+	}
+}
+
+// start returns the request traced by the connection metrics, the trace is released by done once the response
+// headers arrive. The request is returned as it is if the metrics are off.
+func (c *connTracer) start(req *http.Request, n *node) (*http.Request, *connTrace) {
+	mode := ConnMetrics(_connMetrics.Load())
+	if c == nil || mode == ConnMetricsOff {
+		return req, nil
+	}
+	labels := c.service
+	if mode == ConnMetricsNode {
+		if labels = n.connLabels.Load(); labels == nil {
+			labels = &connLabels{service: c.service.service, node: n.address}
+			n.connLabels.Store(labels)
+		}
+	}
+	t := c.traces.Get().(*connTrace)
+	t.labels.Store(labels)
+	t.getConn.Store(0)
+	t.dnsStart.Store(0)
+	t.connectStart.Store(0)
+	t.tlsStart.Store(0)
+	trace := &t.trace
+	if httptrace.ContextClientTrace(req.Context()) != nil {
+		// the trace in the context is composed into the hooks of the given one, a copy keeps the pooled hooks intact
+		copied := t.trace
+		trace = &copied
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+func (c *connTracer) done(t *connTrace) {
+	// the dial still in progress keeps reporting to the trace with the labels of its request
+	if t != nil && t.dialing.Load() == 0 {
+		c.traces.Put(t)
+	}
+}
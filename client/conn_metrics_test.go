@@ -0,0 +1,138 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestConnMetrics(t *testing.T) {
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upstream.Close()
+	addr := strings.TrimPrefix(upstream.URL, "https://")
+	roots := x509.NewCertPool()
+	roots.AddCert(upstream.Certificate())
+	tlsConfigs := map[string]*tls.Config{"upstream": {RootCAs: roots}}
+	buildCtx := NewBuildContext(&config.Gateway{})
+	buildCtx.TLSConfigs, buildCtx.TLSClientStore = tlsConfigs, NewHTTPSClientStore(tlsConfigs)
+	endpoint := &config.Endpoint{
+		Path:     "/orders",
+		Protocol: config.Protocol_HTTP,
+		Metadata: map[string]string{"service": "conn-metrics"},
+		Backends: []*config.Backend{{Target: addr, Tls: true, TlsConfigName: "upstream"}},
+	}
+	c, err := NewFactory(nil)(buildCtx, endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	do := func() {
+		req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	count := func(h *prometheus.HistogramVec, labels ...string) uint64 {
+		m := &dto.Metric{}
+		if err := h.WithLabelValues(labels...).(prometheus.Histogram).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	do()
+	do()
+	if got := testutil.ToFloat64(_metricConnAcquired.WithLabelValues("conn-metrics", "", "false")); got != 1 {
+		t.Fatalf("want one connection dialed but got %v", got)
+	}
+	if got := testutil.ToFloat64(_metricConnAcquired.WithLabelValues("conn-metrics", "", "true")); got != 1 {
+		t.Fatalf("want one connection reused but got %v", got)
+	}
+	if count(_metricConnConnectSeconds, "conn-metrics", "") != 1 || count(_metricConnTLSSeconds, "conn-metrics", "") != 1 {
+		t.Fatal("want the connect and the tls handshake of the dialed connection observed")
+	}
+	if count(_metricConnWaitSeconds, "conn-metrics", "", "true") != 1 {
+		t.Fatal("want the idle pool wait of the reused connection observed")
+	}
+
+	SetConnMetrics(ConnMetricsNode)
+	do()
+	if got := testutil.ToFloat64(_metricConnAcquired.WithLabelValues("conn-metrics", addr, "true")); got != 1 {
+		t.Fatalf("want the connection counted by node but got %v", got)
+	}
+
+	SetConnMetrics(ConnMetricsOff)
+	defer SetConnMetrics(ConnMetricsService)
+	do()
+	if got := testutil.ToFloat64(_metricConnAcquired.WithLabelValues("conn-metrics", addr, "true")); got != 1 {
+		t.Fatalf("want nothing counted with the metrics off but got %v", got)
+	}
+}
+
+func TestParseConnMetrics(t *testing.T) {
+	if m, err := ParseConnMetrics("node"); err != nil || m != ConnMetricsNode {
+		t.Fatalf("want node but got %v %v", m, err)
+	}
+	if _, err := ParseConnMetrics("nodes"); err == nil {
+		t.Fatal("want the unknown granularity rejected")
+	}
+}
+
+func TestConnTraceDialing(t *testing.T) {
+	SetConnMetrics(ConnMetricsNode)
+	defer SetConnMetrics(ConnMetricsService)
+	tracer := newConnTracer(&config.Endpoint{Metadata: map[string]string{"service": "conn-dialing"}})
+	first, second := &node{address: "10.0.0.1:80"}, &node{address: "10.0.0.2:80"}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	// the request is done while its dial is still connecting
+	_, trace := tracer.start(req, first)
+	trace.trace.ConnectStart("tcp", first.address)
+	tracer.done(trace)
+	_, next := tracer.start(req, second)
+	defer tracer.done(next)
+	if next == trace {
+		t.Fatal("want the trace of the pending dial kept out of the pool")
+	}
+	count := func(n *node) uint64 {
+		m := &dto.Metric{}
+		if err := _metricConnConnectSeconds.WithLabelValues("conn-dialing", n.address).(prometheus.Histogram).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	before := count(first)
+	trace.trace.ConnectDone("tcp", first.address, nil)
+	if count(first) != before+1 {
+		t.Fatal("want the connect recorded with the node of its request")
+	}
+}
+
+func BenchmarkConnTrace(b *testing.B) {
+	tracer := newConnTracer(&config.Endpoint{Metadata: map[string]string{"service": "bench"}})
+	n := &node{address: "127.0.0.1:80"}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, trace := tracer.start(req, n)
+		trace.trace.GetConn(n.address)
+		trace.trace.GotConn(httptrace.GotConnInfo{Reused: true})
+		tracer.done(trace)
+	}
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"golang.org/x/net/proxy"
+)
+
+// EgressProxyError is the failure to establish the tunnel to the upstream through the egress proxy.
+type EgressProxyError struct {
+	Proxy string
+	Err   error
+}
+
+func (e *EgressProxyError) Error() string {
+	return fmt.Sprintf("egress proxy %s: %v", e.Proxy, e.Err)
+}
+
+func (e *EgressProxyError) Unwrap() error {
+	return e.Err
+}
+
+// egressProxy tunnels the upstream connections through an HTTP CONNECT or SOCKS5 proxy.
+type egressProxy struct {
+	url     *url.URL
+	hosts   []string
+	domains []string
+	nets    []*net.IPNet
+	// key identifies the clients dialing through the proxy
+	key string
+}
+
+func newEgressProxy(c *config.EgressProxy) (*egressProxy, error) {
+	if c.GetUrl() == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(c.Url)
+	if err != nil {
+		return nil, fmt.Errorf("egress proxy: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "socks5":
+	default:
+		return nil, fmt.Errorf("egress proxy: unsupported scheme %q, http or socks5 is expected", u.Scheme)
+	}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("egress proxy: port is required in %s", u.Redacted())
+	}
+	p := &egressProxy{url: u, key: c.Url + "|" + strings.Join(c.NoProxy, ",")}
+	for _, host := range c.NoProxy {
+		host = strings.ToLower(strings.TrimSpace(host))
+		switch {
+		case host == "":
+		case strings.Contains(host, "/"):
+			_, ipNet, err := net.ParseCIDR(host)
+			if err != nil {
+				return nil, fmt.Errorf("egress proxy: invalid no_proxy %q: %w", host, err)
+			}
+			p.nets = append(p.nets, ipNet)
+		case strings.HasPrefix(host, "*."):
+			p.domains = append(p.domains, host[1:])
+		case strings.HasPrefix(host, "."):
+			p.domains = append(p.domains, host)
+		default:
+			p.hosts = append(p.hosts, host)
+		}
+	}
+	return p, nil
+}
+
+// endpointEgressProxy returns the egress proxy of the endpoint, the one of the gateway if not set.
+func endpointEgressProxy(ctx *BuildContext, e *config.Endpoint) (*egressProxy, error) {
+	if e.EgressProxy != nil {
+		return newEgressProxy(e.EgressProxy)
+	}
+	return ctx.egressProxy, ctx.egressProxyErr
+}
+
+// proxied reports whether the address is dialed through the proxy.
+func (p *egressProxy) proxied(addr string) bool {
+	if p == nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	host = strings.ToLower(host)
+	if ip := net.ParseIP(host); ip != nil {
+		for _, ipNet := range p.nets {
+			if ipNet.Contains(ip) {
+				return false
+			}
+		}
+	}
+	for _, h := range p.hosts {
+		if h == host {
+			return false
+		}
+	}
+	for _, domain := range p.domains {
+		if strings.HasSuffix(host, domain) || host == domain[1:] {
+			return false
+		}
+	}
+	return true
+}
+
+// dial establishes the tunnel to addr through the proxy.
+func (p *egressProxy) dial(ctx context.Context, d *net.Dialer, network, addr string) (net.Conn, error) {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if p.url.Scheme == "socks5" {
+		conn, err = p.dialSOCKS5(ctx, d, network, addr)
+	} else {
+		conn, err = p.dialConnect(ctx, d, network, addr)
+	}
+	if err != nil {
+		return nil, &EgressProxyError{Proxy: p.url.Host, Err: err}
+	}
+	return conn, nil
+}
+
+func (p *egressProxy) dialSOCKS5(ctx context.Context, d *net.Dialer, network, addr string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if p.url.User != nil {
+		password, _ := p.url.User.Password()
+		auth = &proxy.Auth{User: p.url.User.Username(), Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", p.url.Host, auth, d)
+	if err != nil {
+		return nil, err
+	}
+	return dialer.(proxy.ContextDialer).DialContext(ctx, network, addr)
+}
+
+func (p *egressProxy) dialConnect(ctx context.Context, d *net.Dialer, network, addr string) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, network, p.url.Host)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if p.url.User != nil {
+		password, _ := p.url.User.Password()
+		credentials := p.url.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	// the body of the successful response is the tunnel
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, errors.New("CONNECT " + addr + ": " + resp.Status)
+	}
+	conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		// the bytes of the upstream read ahead with the response
+		return &bufferedConn{Conn: conn, reader: br}, nil
+	}
+	return conn, nil
+}
+
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
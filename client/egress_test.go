@@ -0,0 +1,174 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// connectProxy is an in-process HTTP CONNECT proxy requiring the basic auth of user:password.
+func connectProxy(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		lock    sync.Mutex
+		tunnels []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		if user, password, ok := parseProxyAuth(r); !ok || user != "user" || password != "password" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		lock.Lock()
+		tunnels = append(tunnels, r.Host)
+		lock.Unlock()
+		w.WriteHeader(http.StatusOK)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		go func() {
+			io.Copy(upstream, conn)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+		conn.Close()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), tunnels...)
+	}
+}
+
+func parseProxyAuth(r *http.Request) (string, string, bool) {
+	req := &http.Request{Header: http.Header{"Authorization": r.Header.Values("Proxy-Authorization")}}
+	return req.BasicAuth()
+}
+
+func TestEgressProxy(t *testing.T) {
+	var tlsRequests sync.Map
+	upstream := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tlsRequests.Store(r.URL.Path, r.TLS != nil)
+	}))
+	defer upstream.Close()
+	proxySrv, tunnels := connectProxy(t)
+	proxyAddr := strings.TrimPrefix(proxySrv.URL, "http://")
+	upstreamAddr := strings.TrimPrefix(upstream.URL, "https://")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(upstream.Certificate())
+	tlsConfigs := map[string]*tls.Config{"upstream": {RootCAs: roots}}
+	newBuildContext := func(egress *config.EgressProxy) *BuildContext {
+		ctx := NewBuildContext(&config.Gateway{EgressProxy: egress})
+		ctx.TLSConfigs, ctx.TLSClientStore = tlsConfigs, NewHTTPSClientStore(tlsConfigs)
+		return ctx
+	}
+	do := func(buildCtx *BuildContext, endpoint *config.Endpoint) error {
+		c, err := NewFactory(nil)(buildCtx, endpoint)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		req := httptest.NewRequest(http.MethodGet, endpoint.Path, nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	newEndpoint := func(path string) *config.Endpoint {
+		return &config.Endpoint{
+			Path:     path,
+			Protocol: config.Protocol_HTTP,
+			Backends: []*config.Backend{{Target: upstreamAddr, Tls: true, TlsConfigName: "upstream"}},
+		}
+	}
+
+	// the TLS to the upstream is established through the tunnel of the gateway default proxy
+	buildCtx := newBuildContext(&config.EgressProxy{Url: "http://user:password@" + proxyAddr})
+	if err := do(buildCtx, newEndpoint("/tunneled")); err != nil {
+		t.Fatal(err)
+	}
+	if got := tunnels(); len(got) != 1 || got[0] != upstreamAddr {
+		t.Fatalf("want the upstream tunneled but got %v", got)
+	}
+	if tls, _ := tlsRequests.Load("/tunneled"); tls != true {
+		t.Fatal("want the request over TLS end to end")
+	}
+
+	// the no_proxy hosts and the endpoint disabling the proxy are dialed directly
+	direct := newEndpoint("/no-proxy")
+	direct.EgressProxy = &config.EgressProxy{Url: "http://user:password@" + proxyAddr, NoProxy: []string{"10.0.0.0/8", "127.0.0.0/8"}}
+	if err := do(buildCtx, direct); err != nil {
+		t.Fatal(err)
+	}
+	direct = newEndpoint("/disabled")
+	direct.EgressProxy = &config.EgressProxy{}
+	if err := do(buildCtx, direct); err != nil {
+		t.Fatal(err)
+	}
+	if got := tunnels(); len(got) != 1 {
+		t.Fatalf("want the direct upstreams not tunneled but got %v", got)
+	}
+
+	// the rejection of the proxy is an egress proxy error
+	rejected := newEndpoint("/rejected")
+	rejected.EgressProxy = &config.EgressProxy{Url: "http://user:wrong@" + proxyAddr}
+	err := do(buildCtx, rejected)
+	var egressErr *EgressProxyError
+	if !errors.As(err, &egressErr) || !strings.Contains(err.Error(), "407") {
+		t.Fatalf("want the egress proxy error but got %v", err)
+	}
+
+	for _, url := range []string{"https://" + proxyAddr, "http://proxy.internal", "://"} {
+		if _, err := NewFactory(nil)(newBuildContext(&config.EgressProxy{Url: url}), newEndpoint("/")); err == nil {
+			t.Fatalf("want the invalid proxy %q rejected", url)
+		}
+	}
+}
+
+func TestEgressProxyNoProxy(t *testing.T) {
+	p, err := newEgressProxy(&config.EgressProxy{
+		Url:     "socks5://proxy.internal:1080",
+		NoProxy: []string{"api.internal", ".svc.cluster.local", "*.corp", "10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for addr, want := range map[string]bool{
+		"api.internal:80":                  false,
+		"API.internal:443":                 false,
+		"orders.default.svc.cluster.local": false,
+		"svc.cluster.local:80":             false,
+		"git.corp:22":                      false,
+		"10.1.2.3:8080":                    false,
+		"11.1.2.3:8080":                    true,
+		"api.example.com:443":              true,
+	} {
+		if got := p.proxied(addr); got != want {
+			t.Errorf("proxied(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
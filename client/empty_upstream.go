@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNoHealthyUpstream is returned if the discovery returns zero instances of the endpoint.
+var ErrNoHealthyUpstream = errors.New("no healthy upstream")
+
+const _defaultEmptyUpstreamWait = time.Second
+
+var _metricEmptyServices = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "discovery_empty_services",
+	Help:      "Whether the discovery returns zero instances of the service, 1 if empty",
+}, []string{"service"})
+
+func init() {
+	metrics.Register(_metricEmptyServices)
+}
+
+// emptyUpstream handles the requests of the endpoint while it has zero nodes.
+type emptyUpstream struct {
+	action   config.EmptyUpstream_Action
+	wait     time.Duration
+	fallback Client
+}
+
+// newEmptyUpstream builds the fallback client by the factory, nil is returned for fail fast.
+func newEmptyUpstream(factory Factory, buildCtx *BuildContext, endpoint *config.Endpoint) (*emptyUpstream, error) {
+	c := endpoint.EmptyUpstream
+	switch c.GetAction() {
+	case config.EmptyUpstream_WAIT:
+		wait := c.WaitTimeout.AsDuration()
+		if wait <= 0 {
+			wait = _defaultEmptyUpstreamWait
+		}
+		return &emptyUpstream{action: c.Action, wait: wait}, nil
+	case config.EmptyUpstream_FALLBACK:
+		if c.Fallback == nil {
+			return nil, errors.New("empty_upstream: fallback backend is required")
+		}
+		fallbackEndpoint := &config.Endpoint{
+			Path:             endpoint.Path,
+			Method:           endpoint.Method,
+			Protocol:         endpoint.Protocol,
+			UpstreamProtocol: endpoint.UpstreamProtocol,
+			HostOverrides:    endpoint.HostOverrides,
+			Backends:         []*config.Backend{c.Fallback},
+		}
+		fallback, err := factory(buildCtx, fallbackEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		return &emptyUpstream{action: c.Action, fallback: fallback}, nil
+	}
+	return nil, nil
+}
+
+// roundTrip waits for the nodes or routes the request to the fallback backend,
+// retry reports whether the nodes appeared in time and the request is sent to them.
+func (u *emptyUpstream) roundTrip(applier *nodeApplier, req *http.Request) (resp *http.Response, retry bool, err error) {
+	if u == nil {
+		return nil, false, ErrNoHealthyUpstream
+	}
+	switch u.action {
+	case config.EmptyUpstream_WAIT:
+		if applier.waitNodes(req.Context(), u.wait) {
+			return nil, true, nil
+		}
+	case config.EmptyUpstream_FALLBACK:
+		resp, err = u.fallback.RoundTrip(req)
+		return resp, false, err
+	}
+	return nil, false, ErrNoHealthyUpstream
+}
+
+func (u *emptyUpstream) Close() error {
+	if u == nil || u.fallback == nil {
+		return nil
+	}
+	return u.fallback.Close()
+}
+
+// store applies the nodes and wakes up the requests waiting for the nodes.
+func (na *nodeApplier) store(nodes []selector.Node, noMatch bool) {
+	na.noMatch.Store(noMatch)
+	na.picker.Apply(nodes)
+	na.nodes.Store(nodes)
+	na.notifyLock.Lock()
+	defer na.notifyLock.Unlock()
+	if na.notify != nil && len(nodes) > 0 {
+		close(na.notify)
+		na.notify = nil
+	}
+}
+
+// empty reports whether the endpoint has zero nodes, not because the node filters filtered out all of them.
+func (na *nodeApplier) empty() bool {
+	return len(na.Nodes()) == 0 && !na.noMatch.Load()
+}
+
+// waitNodes waits up to the timeout for the nodes to appear.
+func (na *nodeApplier) waitNodes(ctx context.Context, timeout time.Duration) bool {
+	na.notifyLock.Lock()
+	if !na.empty() {
+		na.notifyLock.Unlock()
+		return true
+	}
+	if na.notify == nil {
+		na.notify = make(chan struct{})
+	}
+	notify := na.notify
+	na.notifyLock.Unlock()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-notify:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// setEmpty logs the transitions of the service to and from zero instances.
+func (ws *watcherStatus) setEmpty(endpoint string, instances int) {
+	empty := instances == 0
+	if empty == ws.empty {
+		return
+	}
+	ws.empty = empty
+	if empty {
+		_metricEmptyServices.WithLabelValues(endpoint).Set(1)
+		LOG.Warnf("Service on endpoint: %s has zero instances, the endpoints with empty_upstream handle the requests by it, the others keep the last instances", endpoint)
+		return
+	}
+	_metricEmptyServices.WithLabelValues(endpoint).Set(0)
+	LOG.Infof("Service on endpoint: %s recovered with %d instances", endpoint, instances)
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector/p2c"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestEmptyUpstream(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Host
+	}))
+	defer srv.Close()
+	instances := []*registry.ServiceInstance{{ID: "1", Name: "svc", Endpoints: []string{srv.URL}}}
+
+	newEmptyClient := func(c *config.EmptyUpstream) (*client, *nodeApplier) {
+		endpoint := &config.Endpoint{Protocol: config.Protocol_HTTP, EmptyUpstream: c}
+		na := &nodeApplier{
+			endpoint:     endpoint,
+			picker:       p2c.NewBuilder().Build(),
+			buildContext: EmptyBuildContext(),
+			cancel:       func() {},
+		}
+		empty, err := newEmptyUpstream(NewFactory(nil), EmptyBuildContext(), endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ec := newClient(na, na.picker, nil)
+		ec.empty = empty
+		// the service scaled to zero
+		if err := na.Callback(nil); err != nil {
+			t.Fatal(err)
+		}
+		return ec, na
+	}
+	do := func(c *client) (*http.Response, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		opts := middleware.NewRequestOptions(c.applier.endpoint)
+		return c.RoundTrip(req.WithContext(middleware.NewRequestContext(context.Background(), opts)))
+	}
+
+	c, _ := newEmptyClient(nil)
+	if _, err := do(c); !errors.Is(err, ErrNoHealthyUpstream) {
+		t.Fatalf("want fail fast but got %v", err)
+	}
+
+	c, _ = newEmptyClient(&config.EmptyUpstream{Action: config.EmptyUpstream_WAIT, WaitTimeout: durationpb.New(10 * time.Millisecond)})
+	if _, err := do(c); !errors.Is(err, ErrNoHealthyUpstream) {
+		t.Fatalf("want the wait timed out but got %v", err)
+	}
+
+	// the instances appear during the wait
+	c, na := newEmptyClient(&config.EmptyUpstream{Action: config.EmptyUpstream_WAIT, WaitTimeout: durationpb.New(time.Minute)})
+	time.AfterFunc(10*time.Millisecond, func() { na.Callback(instances) })
+	if resp, err := do(c); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("want the request sent once the instances appear but got %v", err)
+	}
+	<-received
+
+	c, _ = newEmptyClient(&config.EmptyUpstream{Action: config.EmptyUpstream_FALLBACK, Fallback: &config.Backend{Target: srv.Listener.Addr().String()}})
+	if resp, err := do(c); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("want the request routed to the fallback but got %v", err)
+	}
+	<-received
+	c.Close()
+
+	if _, err := newEmptyUpstream(NewFactory(nil), EmptyBuildContext(), &config.Endpoint{EmptyUpstream: &config.EmptyUpstream{Action: config.EmptyUpstream_FALLBACK}}); err == nil {
+		t.Fatal("want the fallback without backend rejected")
+	}
+}
+
+func TestEmptyUpdateKeepsNodes(t *testing.T) {
+	instances := []*registry.ServiceInstance{{ID: "1", Name: "svc", Endpoints: []string{"http://127.0.0.1:8000"}}}
+	newApplier := func(c *config.EmptyUpstream) *nodeApplier {
+		na := &nodeApplier{
+			endpoint:     &config.Endpoint{Protocol: config.Protocol_HTTP, EmptyUpstream: c},
+			picker:       p2c.NewBuilder().Build(),
+			buildContext: EmptyBuildContext(),
+			cancel:       func() {},
+		}
+		if err := na.Callback(instances); err != nil {
+			t.Fatal(err)
+		}
+		if err := na.Callback(nil); err != nil {
+			t.Fatal(err)
+		}
+		return na
+	}
+	if na := newApplier(nil); len(na.Nodes()) != 1 || na.empty() {
+		t.Fatalf("want the last nodes kept without empty_upstream but got %d", len(na.Nodes()))
+	}
+	if na := newApplier(&config.EmptyUpstream{Action: config.EmptyUpstream_FAIL_FAST}); !na.empty() {
+		t.Fatal("want the nodes emptied with empty_upstream")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	config "github.com/aide-family/goddess/pkg/config/v1"
@@ -20,6 +21,15 @@ import (
 type BuildContext struct {
 	TLSConfigs     map[string]*tls.Config
 	TLSClientStore *HTTPSClientStore
+	// HostOverrides is the addresses of the overridden hostnames of the gateway.
+	HostOverrides map[string][]string
+
+	hostClients hostClientStore
+	// the endpoints fail to build if the host overrides of the gateway are invalid
+	hostOverridesErr error
+	// the egress proxy of the gateway, the endpoints fail to build if it is invalid
+	egressProxy    *egressProxy
+	egressProxyErr error
 }
 
 // Factory is returns service client.
@@ -65,9 +75,16 @@ func NewBuildContext(cfg *config.Gateway) *BuildContext {
 		}
 		tlsConfigs[k] = cfg
 	}
+	hostOverrides, hostOverridesErr := newHostOverrides(cfg.HostOverrides)
+	_dnsCache.setConfig(newResolverConfig(cfg))
+	egressProxy, egressProxyErr := newEgressProxy(cfg.EgressProxy)
 	return &BuildContext{
-		TLSConfigs:     tlsConfigs,
-		TLSClientStore: NewHTTPSClientStore(tlsConfigs),
+		TLSConfigs:       tlsConfigs,
+		TLSClientStore:   NewHTTPSClientStore(tlsConfigs),
+		HostOverrides:    hostOverrides,
+		hostOverridesErr: hostOverridesErr,
+		egressProxy:      egressProxy,
+		egressProxyErr:   egressProxyErr,
 	}
 }
 
@@ -79,31 +96,101 @@ func NewFactory(r registry.Discovery, opts ...Option) Factory {
 	for _, opt := range opts {
 		opt(o)
 	}
-	return func(builderCtx *BuildContext, endpoint *config.Endpoint) (Client, error) {
+	var factory Factory
+	factory = func(builderCtx *BuildContext, endpoint *config.Endpoint) (Client, error) {
+		if err := validateUpstreamProtocol(endpoint); err != nil {
+			return nil, err
+		}
+		if builderCtx.hostOverridesErr != nil {
+			return nil, fmt.Errorf("gateway host_overrides: %w", builderCtx.hostOverridesErr)
+		}
+		if endpoint.ResponseHeaderTimeout.AsDuration() < 0 {
+			return nil, fmt.Errorf("response_header_timeout must not be negative: %s", endpoint.ResponseHeaderTimeout.AsDuration())
+		}
+		if endpoint.Failover != nil {
+			return newFailoverClient(factory, builderCtx, endpoint)
+		}
+		sticky, err := newStickyCookie(endpoint.StickyCookie)
+		if err != nil {
+			return nil, err
+		}
+		filter, err := newNodeFilter(endpoint.NodeFilters)
+		if err != nil {
+			return nil, err
+		}
+		hostOverrides, err := newHostOverrides(endpoint.HostOverrides)
+		if err != nil {
+			return nil, err
+		}
+		egress, err := endpointEgressProxy(builderCtx, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		decorated, err := newDecoratedClients(endpoint)
+		if err != nil {
+			return nil, err
+		}
 		picker := o.pickerBuilder.Build()
 		ctx, cancel := context.WithCancel(context.Background())
 		applier := &nodeApplier{
-			cancel:       cancel,
-			endpoint:     endpoint,
-			registry:     r,
-			picker:       picker,
-			buildContext: builderCtx,
+			cancel:        cancel,
+			endpoint:      endpoint,
+			registry:      r,
+			picker:        picker,
+			buildContext:  builderCtx,
+			filter:        filter,
+			hostOverrides: mergeHostOverrides(builderCtx.HostOverrides, hostOverrides),
+			egress:        egress,
+			decorated:     decorated,
+		}
+		empty, err := newEmptyUpstream(factory, builderCtx, endpoint)
+		if err != nil {
+			cancel()
+			return nil, err
 		}
 		if err := applier.apply(ctx); err != nil {
+			empty.Close()
+			return nil, err
+		}
+		if err := decorated.firstError(); err != nil {
+			applier.Cancel()
+			empty.Close()
 			return nil, err
 		}
-		client := newClient(applier, picker)
+		client := newClient(applier, picker, sticky)
+		client.empty = empty
+		client.headerTimeout = endpoint.ResponseHeaderTimeout.AsDuration()
+		client.conns = newConnTracer(endpoint)
 		return client, nil
 	}
+	return factory
 }
 
 type nodeApplier struct {
 	canceled     int64
+	nodes        atomic.Value
 	buildContext *BuildContext
 	cancel       context.CancelFunc
 	endpoint     *config.Endpoint
 	registry     registry.Discovery
 	picker       selector.Selector
+	// filter is the node filter of the endpoint applied on selecting
+	filter *nodeFilter
+	// discoveryFilter is the node filter of the discovery backend applied on the callback
+	discoveryFilter *nodeFilter
+	// noMatch is set if the backend node filters filtered out all the nodes
+	noMatch atomic.Bool
+	// notify is closed once the nodes are applied to wake up the requests waiting for the nodes
+	notifyLock sync.Mutex
+	notify     chan struct{}
+	// discoveryPathPrefix is the upstream path prefix of the discovery backend
+	discoveryPathPrefix *pathPrefix
+	// hostOverrides is the effective host overrides of the endpoint
+	hostOverrides map[string][]string
+	// egress is the effective egress proxy of the endpoint, nil if none
+	egress *egressProxy
+	// decorated is the clients with the transport decorators of the endpoint, nil if none
+	decorated *decoratedClients
 }
 
 func (na *nodeApplier) apply(ctx context.Context) error {
@@ -113,13 +200,29 @@ func (na *nodeApplier) apply(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		filter, err := newNodeFilter(backend.NodeFilters)
+		if err != nil {
+			return fmt.Errorf("backend %s: %w", backend.Target, err)
+		}
+		prefix, err := newPathPrefix(na.endpoint, backend)
+		if err != nil {
+			return fmt.Errorf("backend %s: %w", backend.Target, err)
+		}
 		switch target.Scheme {
 		case "direct":
 			weighted := backend.Weight // weight is only valid for direct scheme
-			node := newNode(na.buildContext, backend.Target, na.endpoint.Protocol, weighted, backend.Metadata, "", "", WithTLS(backend.Tls), WithTLSConfigName(backend.TlsConfigName))
-			nodes = append(nodes, node)
-			na.picker.Apply(nodes)
+			node := newNode(na.buildContext, backend.Target, na.endpoint.Protocol, weighted, backend.Metadata, "", "", WithTLS(backend.Tls), WithTLSConfigName(backend.TlsConfigName), WithUpstreamProtocol(na.endpoint.UpstreamProtocol), withPathPrefix(prefix), withHostOverrides(na.hostOverrides), withEgressProxy(na.egress), withDecoratedClients(na.decorated))
+			matched, _ := filter.filter([]selector.Node{node})
+			nodes = append(nodes, matched...)
+			na.store(nodes, len(nodes) == 0)
 		case "discovery":
+			if na.decorated != nil {
+				// the nodes are created by the discovery later, the transport of them is decorated up front
+				// so the failure of the decorators fails the build
+				newNode(na.buildContext, target.Endpoint+":0", na.endpoint.Protocol, nil, nil, "", "", WithUpstreamProtocol(na.endpoint.UpstreamProtocol), withEgressProxy(na.egress), withDecoratedClients(na.decorated))
+			}
+			na.discoveryFilter = filter
+			na.discoveryPathPrefix = prefix
 			existed := AddWatch(ctx, na.registry, target.Endpoint, na)
 			if existed {
 				log.Infof("watch target %+v already existed", target)
@@ -149,7 +252,8 @@ func (na *nodeApplier) Callback(services []*registry.ServiceInstance) error {
 	if atomic.LoadInt64(&na.canceled) == 1 {
 		return ErrCancelWatch
 	}
-	if len(services) == 0 {
+	if len(services) == 0 && na.endpoint.EmptyUpstream == nil && len(na.Nodes()) > 0 {
+		// the endpoints without empty_upstream keep the last nodes, eg: during a hiccup of the registry
 		return nil
 	}
 	scheme := strings.ToLower(na.endpoint.Protocol.String())
@@ -160,13 +264,20 @@ func (na *nodeApplier) Callback(services []*registry.ServiceInstance) error {
 			log.Errorf("failed to parse endpoint: %v/%s: %v", ser.Endpoints, scheme, err)
 			continue
 		}
-		node := newNode(na.buildContext, addr, na.endpoint.Protocol, nodeWeight(ser), ser.Metadata, ser.Version, ser.Name, WithTLS(false))
+		node := newNode(na.buildContext, addr, na.endpoint.Protocol, nodeWeight(ser), ser.Metadata, ser.Version, ser.Name, WithTLS(false), WithUpstreamProtocol(na.endpoint.UpstreamProtocol), withPathPrefix(na.discoveryPathPrefix), withHostOverrides(na.hostOverrides), withEgressProxy(na.egress), withDecoratedClients(na.decorated))
 		nodes = append(nodes, node)
 	}
-	na.picker.Apply(nodes)
+	nodes, ok := na.discoveryFilter.filter(nodes)
+	na.store(nodes, !ok)
 	return nil
 }
 
+// Nodes returns the last applied nodes.
+func (na *nodeApplier) Nodes() []selector.Node {
+	nodes, _ := na.nodes.Load().([]selector.Node)
+	return nodes
+}
+
 func (na *nodeApplier) Cancel() {
 	log.Infof("Closing node applier for endpoint: %+v", na.endpoint)
 	atomic.StoreInt64(&na.canceled, 1)
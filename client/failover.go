@@ -0,0 +1,296 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	_primaryGroup = "primary"
+
+	_defaultFailoverMinRequests = 20
+	_defaultFailoverWindow      = 30 * time.Second
+
+	failoverReasonNoNodes   = "no_nodes"
+	failoverReasonErrorRate = "error_rate"
+	failoverReasonRecovered = "recovered"
+)
+
+var (
+	_metricBackendGroupRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "backend_group_requests_total",
+		Help:      "The total number of the requests sent to the backend groups of the endpoints with failover",
+	}, []string{"protocol", "method", "path", "service", "basePath", "group", "result"})
+	_metricFailoverActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "failover_active_group",
+		Help:      "The backend group serving the endpoint with failover, 1 if serving",
+	}, []string{"protocol", "method", "path", "service", "basePath", "group"})
+	_metricFailoverTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "failover_transitions_total",
+		Help:      "The total number of the transitions between the backend groups of the endpoints with failover",
+	}, []string{"protocol", "method", "path", "service", "basePath", "from", "to", "reason"})
+)
+
+func init() {
+	metrics.Register(_metricBackendGroupRequests, _metricFailoverActive, _metricFailoverTransitions)
+}
+
+// errorWindow counts the requests and the errors of a backend group in a fixed window.
+type errorWindow struct {
+	start    time.Time
+	requests uint32
+	errors   uint32
+	// the group is skipped until then once its error rate is over the threshold
+	trippedUntil time.Time
+}
+
+// failoverGroup is a backend group with its own nodes.
+type failoverGroup struct {
+	name   string
+	client *client
+	window errorWindow
+}
+
+// failoverClient sends the requests to the first group with the nodes and not tripped by its error rate.
+type failoverClient struct {
+	groups      []*failoverGroup
+	errorRate   float64
+	minRequests uint32
+	window      time.Duration
+	warm        float64
+	labels      []string
+
+	lock sync.Mutex
+	// the group serving the requests last, the transitions are reported when it changes
+	serving int
+}
+
+func newFailoverClient(factory Factory, buildCtx *BuildContext, endpoint *config.Endpoint) (Client, error) {
+	c := endpoint.Failover
+	if len(c.Groups) == 0 {
+		return nil, errors.New("failover: at least one backend group is required")
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 {
+		return nil, fmt.Errorf("failover: error_rate must be in [0, 1]: %v", c.ErrorRate)
+	}
+	if c.WarmPercent < 0 || c.WarmPercent > 100 {
+		return nil, fmt.Errorf("failover: warm_percent must be in [0, 100]: %v", c.WarmPercent)
+	}
+	fc := &failoverClient{
+		errorRate:   c.ErrorRate,
+		minRequests: c.MinRequests,
+		window:      c.Window.AsDuration(),
+		warm:        c.WarmPercent / 100,
+		labels:      []string{endpoint.Protocol.String(), endpoint.Method, endpoint.Path, endpoint.Metadata["service"], endpoint.Metadata["basePath"]},
+	}
+	if fc.minRequests == 0 {
+		fc.minRequests = _defaultFailoverMinRequests
+	}
+	if fc.window <= 0 {
+		fc.window = _defaultFailoverWindow
+	}
+	groups := make([]*config.BackendGroup, 0, len(c.Groups)+1)
+	groups = append(groups, &config.BackendGroup{Name: _primaryGroup, Backends: endpoint.Backends})
+	groups = append(groups, c.Groups...)
+	names := make(map[string]struct{}, len(groups))
+	for i, group := range groups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("failover: the name of group %d is required", i)
+		}
+		if _, ok := names[group.Name]; ok {
+			return nil, fmt.Errorf("failover: duplicate group: %s", group.Name)
+		}
+		names[group.Name] = struct{}{}
+		if len(group.Backends) == 0 {
+			return nil, fmt.Errorf("failover: group %s has no backends", group.Name)
+		}
+	}
+	for i, group := range groups {
+		groupEndpoint := proto.Clone(endpoint).(*config.Endpoint)
+		groupEndpoint.Failover = nil
+		groupEndpoint.Backends = group.Backends
+		if i < len(groups)-1 {
+			// the groups without the nodes are failed over, only the last group handles the empty upstream
+			groupEndpoint.EmptyUpstream = nil
+		}
+		groupClient, err := factory(buildCtx, groupEndpoint)
+		if err != nil {
+			fc.Close()
+			return nil, fmt.Errorf("failover: group %s: %w", group.Name, err)
+		}
+		fc.groups = append(fc.groups, &failoverGroup{name: group.Name, client: groupClient.(*client)})
+	}
+	fc.setActive(0)
+	return fc, nil
+}
+
+func (c *failoverClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	now := time.Now()
+	first, reason := c.first(now)
+	c.transition(first, reason)
+	if first == 0 && c.warm > 0 && rand.Float64() < c.warm && len(c.groups[1].client.applier.Nodes()) > 0 {
+		// the warm requests do not change the serving group
+		return c.roundTrip(req, 1, now)
+	}
+	return c.roundTrip(req, first, now)
+}
+
+func (c *failoverClient) roundTrip(req *http.Request, i int, now time.Time) (*http.Response, error) {
+	group := c.groups[i]
+	if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+		middleware.BackendGroup.Set(reqOpts, group.name)
+	}
+	resp, err := group.client.RoundTrip(req)
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+	result := "success"
+	if failed {
+		result = "failure"
+	}
+	_metricBackendGroupRequests.WithLabelValues(append(c.labels[:len(c.labels):len(c.labels)], group.name, result)...).Inc()
+	c.observe(group, failed, now)
+	return resp, err
+}
+
+// first returns the first group with the nodes and not tripped, the last group if none, and the reason the
+// groups before it are skipped.
+func (c *failoverClient) first(now time.Time) (int, string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	reason := ""
+	for i, group := range c.groups[:len(c.groups)-1] {
+		if now.Before(group.window.trippedUntil) {
+			reason = failoverReasonErrorRate
+			continue
+		}
+		if len(group.client.applier.Nodes()) == 0 {
+			reason = failoverReasonNoNodes
+			continue
+		}
+		return i, reason
+	}
+	return len(c.groups) - 1, reason
+}
+
+// observe counts the request of the group and trips it once its error rate is over the threshold.
+func (c *failoverClient) observe(group *failoverGroup, failed bool, now time.Time) {
+	if c.errorRate <= 0 || group == c.groups[len(c.groups)-1] {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	w := &group.window
+	if now.Sub(w.start) >= c.window {
+		w.start, w.requests, w.errors = now, 0, 0
+	}
+	w.requests++
+	if failed {
+		w.errors++
+	}
+	if w.requests >= c.minRequests && float64(w.errors)/float64(w.requests) >= c.errorRate && !now.Before(w.trippedUntil) {
+		w.trippedUntil = now.Add(c.window)
+		w.start, w.requests, w.errors = time.Time{}, 0, 0
+		LOG.Warnf("Backend group %s of endpoint %s %s is skipped for %s, its error rate is over %v", group.name, c.labels[1], c.labels[2], c.window, c.errorRate)
+	}
+}
+
+// transition reports the change of the serving group.
+func (c *failoverClient) transition(to int, reason string) {
+	c.lock.Lock()
+	from := c.serving
+	if from == to {
+		c.lock.Unlock()
+		return
+	}
+	c.serving = to
+	c.lock.Unlock()
+	if to < from {
+		reason = failoverReasonRecovered
+	}
+	c.setActive(to)
+	labels := append(c.labels[:len(c.labels):len(c.labels)], c.groups[from].name, c.groups[to].name, reason)
+	_metricFailoverTransitions.WithLabelValues(labels...).Inc()
+	if reason == failoverReasonRecovered {
+		LOG.Infof("Endpoint %s %s recovered from backend group %s to %s", c.labels[1], c.labels[2], c.groups[from].name, c.groups[to].name)
+		return
+	}
+	LOG.Warnf("Endpoint %s %s failed over from backend group %s to %s: %s", c.labels[1], c.labels[2], c.groups[from].name, c.groups[to].name, reason)
+}
+
+func (c *failoverClient) setActive(serving int) {
+	for i, group := range c.groups {
+		value := 0.0
+		if i == serving {
+			value = 1
+		}
+		_metricFailoverActive.WithLabelValues(append(c.labels[:len(c.labels):len(c.labels)], group.name)...).Set(value)
+	}
+}
+
+func (c *failoverClient) Close() error {
+	var errs []error
+	for _, group := range c.groups {
+		errs = append(errs, group.client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	_ NodeFilterInspector    = (*failoverClient)(nil)
+	_ HostOverridesInspector = (*failoverClient)(nil)
+	_ Prewarmer              = (*failoverClient)(nil)
+)
+
+// NodeFilterInspect sums the nodes of the groups, the endpoint is served while any group has the matching nodes.
+func (c *failoverClient) NodeFilterInspect() *NodeFilterInspect {
+	out := &NodeFilterInspect{}
+	for _, group := range c.groups {
+		inspect := group.client.NodeFilterInspect()
+		if out.Filter == "" {
+			out.Filter, out.OnNoMatch = inspect.Filter, inspect.OnNoMatch
+		}
+		out.Nodes += inspect.Nodes
+		out.Matching += inspect.Matching
+	}
+	return out
+}
+
+// HostOverrides merges the effective overrides of the groups.
+func (c *failoverClient) HostOverrides() map[string][]string {
+	var out map[string][]string
+	for _, group := range c.groups {
+		for host, addrs := range group.client.HostOverrides() {
+			if out == nil {
+				out = map[string][]string{}
+			}
+			out[host] = addrs
+		}
+	}
+	return out
+}
+
+// Prewarm prewarms the groups in order within the budget, the standby groups are kept warm for the failover.
+func (c *failoverClient) Prewarm(ctx context.Context, conns int, budget *PrewarmBudget) []PrewarmResult {
+	var results []PrewarmResult
+	for _, group := range c.groups {
+		results = append(results, group.client.Prewarm(ctx, conns, budget)...)
+	}
+	return results
+}
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestFailover(t *testing.T) {
+	var primaryStatus atomic.Int32
+	primaryStatus.Store(http.StatusOK)
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(primaryStatus.Load()))
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer secondary.Close()
+
+	endpoint := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/failover",
+		Backends: []*config.Backend{{Target: primary.Listener.Addr().String()}},
+		Failover: &config.Failover{
+			Groups:      []*config.BackendGroup{{Name: "secondary", Backends: []*config.Backend{{Target: secondary.Listener.Addr().String()}}}},
+			ErrorRate:   0.5,
+			MinRequests: 4,
+			Window:      durationpb.New(100 * time.Millisecond),
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	fc := c.(*failoverClient)
+	baseline := map[string]float64{}
+	counter := func(from, to, reason string) float64 {
+		return testutil.ToFloat64(_metricFailoverTransitions.WithLabelValues("HTTP", "", "/failover", "", "", from, to, reason))
+	}
+	for _, reason := range []string{failoverReasonNoNodes, failoverReasonErrorRate} {
+		baseline[reason] = counter("primary", "secondary", reason)
+	}
+	baseline[failoverReasonRecovered] = counter("secondary", "primary", failoverReasonRecovered)
+	transitions := func(from, to, reason string) float64 {
+		return counter(from, to, reason) - baseline[reason]
+	}
+	do := func() (int, string) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/failover", nil)
+		opts := middleware.NewRequestOptions(endpoint)
+		resp, err := c.RoundTrip(req.WithContext(middleware.NewRequestContext(context.Background(), opts)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		group, _ := middleware.BackendGroup.Get(opts)
+		return resp.StatusCode, group
+	}
+	if code, group := do(); code != http.StatusOK || group != "primary" {
+		t.Fatalf("want the primary group but got %d %s", code, group)
+	}
+
+	// the primary group has no nodes
+	nodes := fc.groups[0].client.applier.Nodes()
+	fc.groups[0].client.applier.store(nil, false)
+	if code, group := do(); code != http.StatusAccepted || group != "secondary" {
+		t.Fatalf("want failed over to the secondary group but got %d %s", code, group)
+	}
+	fc.groups[0].client.applier.store(nodes, false)
+	if _, group := do(); group != "primary" {
+		t.Fatalf("want recovered to the primary group but got %s", group)
+	}
+	if n := transitions("primary", "secondary", failoverReasonNoNodes); n != 1 {
+		t.Fatalf("want 1 failover for no nodes but got %v", n)
+	}
+	if n := transitions("secondary", "primary", failoverReasonRecovered); n != 1 {
+		t.Fatalf("want 1 recovery but got %v", n)
+	}
+
+	// the error rate of the primary group is over the threshold in a new window
+	time.Sleep(150 * time.Millisecond)
+	primaryStatus.Store(http.StatusInternalServerError)
+	for i := 0; i < 3; i++ {
+		if _, group := do(); group != "primary" {
+			t.Fatalf("want the primary group below min_requests but got %s", group)
+		}
+	}
+	do()
+	if code, group := do(); code != http.StatusAccepted || group != "secondary" {
+		t.Fatalf("want failed over for the error rate but got %d %s", code, group)
+	}
+	if n := transitions("primary", "secondary", failoverReasonErrorRate); n != 1 {
+		t.Fatalf("want 1 failover for the error rate but got %v", n)
+	}
+	primaryStatus.Store(http.StatusOK)
+	time.Sleep(150 * time.Millisecond)
+	if code, group := do(); code != http.StatusOK || group != "primary" {
+		t.Fatalf("want the primary group tried again after the window but got %d %s", code, group)
+	}
+}
+
+func TestFailoverWarm(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	endpoint := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/warm",
+		Backends: []*config.Backend{{Target: srv.Listener.Addr().String()}},
+		Failover: &config.Failover{
+			Groups:      []*config.BackendGroup{{Name: "secondary", Backends: []*config.Backend{{Target: srv.Listener.Addr().String()}}}},
+			WarmPercent: 20,
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	warm := 0
+	const total = 1000
+	for i := 0; i < total; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/warm", nil)
+		opts := middleware.NewRequestOptions(endpoint)
+		resp, err := c.RoundTrip(req.WithContext(middleware.NewRequestContext(context.Background(), opts)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if group, _ := middleware.BackendGroup.Get(opts); group == "secondary" {
+			warm++
+		}
+	}
+	if warm < total/10 || warm > total*3/10 {
+		t.Fatalf("want about 20%% of the requests sent to the secondary group but got %d/%d", warm, total)
+	}
+}
+
+func TestFailoverInvalid(t *testing.T) {
+	backends := []*config.Backend{{Target: "127.0.0.1:8000"}}
+	for _, c := range []*config.Failover{
+		{},
+		{Groups: []*config.BackendGroup{{Name: "secondary"}}},
+		{Groups: []*config.BackendGroup{{Name: "primary", Backends: backends}}},
+		{Groups: []*config.BackendGroup{{Name: "secondary", Backends: backends}}, ErrorRate: 2},
+	} {
+		if _, err := NewFactory(nil)(EmptyBuildContext(), &config.Endpoint{Protocol: config.Protocol_HTTP, Backends: backends, Failover: c}); err == nil {
+			t.Fatalf("want the failover rejected: %v", c)
+		}
+	}
+}
+
+func TestFailoverInspect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	endpoint := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/inspect",
+		Backends: []*config.Backend{{Target: srv.Listener.Addr().String()}},
+		Failover: &config.Failover{
+			Groups: []*config.BackendGroup{{Name: "secondary", Backends: []*config.Backend{{Target: srv.Listener.Addr().String()}}}},
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	inspector, ok := c.(NodeFilterInspector)
+	if !ok {
+		t.Fatal("want the failover client inspected")
+	}
+	// the endpoint is still served by the secondary group
+	c.(*failoverClient).groups[0].client.applier.store(nil, false)
+	if inspect := inspector.NodeFilterInspect(); inspect.Nodes != 1 || inspect.Matching != 1 {
+		t.Fatalf("want the nodes of the secondary group but got %+v", inspect)
+	}
+	results := c.(Prewarmer).Prewarm(context.Background(), 2, NewPrewarmBudget(3))
+	if len(results) != 2 {
+		t.Fatalf("want the secondary group prewarmed but got %+v", results)
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+)
+
+// doWithHeaderTimeout sends the request by the client, the request is canceled if the response headers do not
+// arrive in timeout once the request is written, eg: an upstream accepting the connection but sending nothing.
+// The timer starts when the request is written, so the streaming request bodies are not limited.
+func doWithHeaderTimeout(client *http.Client, req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return client.Do(req)
+	}
+	timeoutErr := middleware.NewTimeoutError(middleware.TimeoutSourceResponseHeader)
+	ctx, cancel := context.WithCancelCause(req.Context())
+	var timer atomic.Pointer[time.Timer]
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			// the transport writes the request again if a reused connection is found broken
+			t := time.AfterFunc(timeout, func() { cancel(timeoutErr) })
+			if old := timer.Swap(t); old != nil {
+				old.Stop()
+			}
+		},
+	})
+	resp, err := client.Do(req.WithContext(ctx))
+	if t := timer.Load(); t != nil {
+		t.Stop()
+	}
+	if errors.Is(context.Cause(ctx), timeoutErr) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, fmt.Errorf("upstream %s sent no response headers in %s: %w", req.URL.Host, timeout, timeoutErr)
+	}
+	if err != nil {
+		cancel(nil)
+		return nil, err
+	}
+	// the context lives as long as the response body, it is released with the context of the attempt
+	return resp, nil
+}
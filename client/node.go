@@ -1,14 +1,17 @@
 package client
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/aide-family/goddess/metrics"
 	"github.com/go-kratos/kratos/v2/selector"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/http2"
@@ -24,9 +27,13 @@ var (
 )
 
 var (
+	// _globalDialer dials the hosts not overridden
+	_globalDialer      *dialer      = nil
 	_globalClient      *http.Client = nil
 	_globalH2CClient   *http.Client = nil
 	_globalHTTPSClient *http.Client = nil
+	// the https clients of the forced upstream protocols
+	_globalHTTPSClients = map[config.UpstreamProtocol]*http.Client{}
 )
 
 func init() {
@@ -39,11 +46,15 @@ func init() {
 	if val := os.Getenv("PROXY_FOLLOW_REDIRECT"); val != "" {
 		followRedirect = true
 	}
-	_globalClient = defaultClient()
-	_globalH2CClient = defaultH2CClient()
-	_globalHTTPSClient = createHTTPSClient(nil)
+	_globalDialer = newDialer(nil)
+	_globalClient = defaultClient(_globalDialer)
+	_globalH2CClient = defaultH2CClient(_globalDialer)
+	_globalHTTPSClient = createHTTPSClient(nil, _globalDialer)
+	_globalHTTPSClients[config.UpstreamProtocol_AUTO] = _globalHTTPSClient
+	_globalHTTPSClients[config.UpstreamProtocol_HTTP1] = createHTTP1HTTPSClient(nil, _globalDialer)
+	_globalHTTPSClients[config.UpstreamProtocol_HTTP2] = createHTTP2HTTPSClient(nil, _globalDialer)
 
-	prometheus.MustRegister(_metricClientRedirect)
+	metrics.Register(_metricClientRedirect)
 }
 
 var _metricClientRedirect = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -67,15 +78,12 @@ func defaultCheckRedirect(req *http.Request, via []*http.Request) error {
 	return http.ErrUseLastResponse
 }
 
-func defaultClient() *http.Client {
+func defaultClient(d *dialer) *http.Client {
 	return &http.Client{
 		CheckRedirect: defaultCheckRedirect,
 		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   _dialTimeout,
-				KeepAlive: 30 * time.Second,
-			}).DialContext,
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           d.DialContext,
 			MaxIdleConns:          10000,
 			MaxIdleConnsPerHost:   1000,
 			MaxConnsPerHost:       1000,
@@ -87,7 +95,7 @@ func defaultClient() *http.Client {
 	}
 }
 
-func defaultH2CClient() *http.Client {
+func defaultH2CClient(d *dialer) *http.Client {
 	return &http.Client{
 		CheckRedirect: defaultCheckRedirect,
 		Transport: &http2.Transport{
@@ -96,21 +104,18 @@ func defaultH2CClient() *http.Client {
 			DisableCompression: true,
 			// Pretend we are dialing a TLS endpoint.
 			// Note, we ignore the passed tls.Config
-			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
-				return net.DialTimeout(network, addr, _dialTimeout)
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return d.DialContext(ctx, network, addr)
 			},
 		},
 	}
 }
 
-func createHTTPSClient(tlsConfig *tls.Config) *http.Client {
+func createHTTPSClient(tlsConfig *tls.Config, d *dialer) *http.Client {
 	tr := &http.Transport{
-		TLSClientConfig: tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   _dialTimeout,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           d.DialContext,
 		MaxIdleConns:          10000,
 		MaxIdleConnsPerHost:   1000,
 		MaxConnsPerHost:       1000,
@@ -139,26 +144,39 @@ func NewHTTPSClientStore(clientConfigs map[string]*tls.Config) *HTTPSClientStore
 }
 
 func (s *HTTPSClientStore) GetClient(name string) *http.Client {
+	return s.getClient(name, config.UpstreamProtocol_AUTO)
+}
+
+func (s *HTTPSClientStore) getClient(name string, protocol config.UpstreamProtocol) *http.Client {
 	if name == "" {
 		return _globalClient
 	}
-	client, ok := s.clients[name]
+	key := name
+	if protocol != config.UpstreamProtocol_AUTO {
+		key = name + "/" + protocol.String()
+	}
+	client, ok := s.clients[key]
 	if ok {
 		return client
 	}
 	tlsConfig, ok := s.clientConfigs[name]
 	if !ok {
 		LOG.Warnf("tls config not found for %s, using default instead", name)
-		return _globalHTTPSClient
+		return _globalHTTPSClients[protocol]
 	}
-	client = createHTTPSClient(tlsConfig)
-	s.clients[name] = client
+	client = createHTTPSClientWithProtocol(tlsConfig, protocol, _globalDialer)
+	s.clients[key] = client
 	return client
 }
 
 type NodeOptions struct {
-	TLS           bool
-	TLSConfigName string
+	TLS              bool
+	TLSConfigName    string
+	UpstreamProtocol config.UpstreamProtocol
+	pathPrefix       *pathPrefix
+	hostOverrides    map[string][]string
+	egress           *egressProxy
+	decorated        *decoratedClients
 }
 type NewNodeOption func(*NodeOptions)
 
@@ -174,6 +192,36 @@ func WithTLSConfigName(in string) NewNodeOption {
 	}
 }
 
+func WithUpstreamProtocol(in config.UpstreamProtocol) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.UpstreamProtocol = in
+	}
+}
+
+func withPathPrefix(in *pathPrefix) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.pathPrefix = in
+	}
+}
+
+func withHostOverrides(in map[string][]string) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.hostOverrides = in
+	}
+}
+
+func withEgressProxy(in *egressProxy) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.egress = in
+	}
+}
+
+func withDecoratedClients(in *decoratedClients) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.decorated = in
+	}
+}
+
 func newNode(ctx *BuildContext, addr string, protocol config.Protocol, weight *int64, md map[string]string, version string, name string, opts ...NewNodeOption) *node {
 	node := &node{
 		protocol: protocol,
@@ -183,21 +231,32 @@ func newNode(ctx *BuildContext, addr string, protocol config.Protocol, weight *i
 		version:  version,
 		name:     name,
 	}
-	node.client = _globalClient
-	if protocol == config.Protocol_GRPC {
-		node.client = _globalH2CClient
-	}
 	opt := &NodeOptions{}
 	for _, o := range opts {
 		o(opt)
 	}
+	node.upstreamProtocol = effectiveUpstreamProtocol(protocol, opt.UpstreamProtocol, opt.TLS)
+	node.pathPrefix = opt.pathPrefix
+	node.client = _globalClient
+	if node.upstreamProtocol == config.UpstreamProtocol_H2C {
+		node.client = _globalH2CClient
+	}
 	if opt.TLS {
 		node.tls = true
-		node.client = _globalHTTPSClient
+		node.client = _globalHTTPSClients[node.upstreamProtocol]
 		if opt.TLSConfigName != "" {
-			node.client = ctx.TLSClientStore.GetClient(opt.TLSConfigName)
+			node.client = ctx.TLSClientStore.getClient(opt.TLSConfigName, node.upstreamProtocol)
+		}
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if addrs, ok := opt.hostOverrides[strings.ToLower(host)]; ok {
+			// the connections to the overridden host are not shared with the ones resolved by DNS
+			node.client = ctx.hostClients.get(ctx, strings.ToLower(host), addrs, opt, node.upstreamProtocol)
+		} else if opt.egress != nil {
+			node.client = ctx.hostClients.get(ctx, "", nil, opt, node.upstreamProtocol)
 		}
 	}
+	node.client = opt.decorated.get(node.client)
 	return node
 }
 
@@ -208,9 +267,13 @@ type node struct {
 	version  string
 	metadata map[string]string
 
-	client   *http.Client
-	protocol config.Protocol
-	tls      bool
+	client           *http.Client
+	protocol         config.Protocol
+	upstreamProtocol config.UpstreamProtocol
+	tls              bool
+	pathPrefix       *pathPrefix
+	// the labels of the connection metrics by node
+	connLabels atomic.Pointer[connLabels]
 }
 
 func (n *node) Scheme() string {
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+// ErrNoMatchingNodes is returned if none of the upstream nodes matches the node filters.
+var ErrNoMatchingNodes = errors.New("no upstream nodes match the node filters")
+
+// nodeFilter selects the subset of the nodes by the metadata.
+type nodeFilter struct {
+	matchers []*config.NodeMatcher
+	fallback bool
+}
+
+func newNodeFilter(c *config.NodeFilters) (*nodeFilter, error) {
+	if len(c.GetMatchers()) == 0 {
+		return nil, nil
+	}
+	for _, m := range c.Matchers {
+		if m.Key == "" {
+			return nil, errors.New("node matcher key is required")
+		}
+		if (m.Equals == "") == (len(m.In) == 0) {
+			return nil, fmt.Errorf("node matcher %q requires exactly one of equals and in", m.Key)
+		}
+	}
+	return &nodeFilter{
+		matchers: c.Matchers,
+		fallback: c.OnNoMatch == config.NodeFilters_FALLBACK,
+	}, nil
+}
+
+func (f *nodeFilter) match(n selector.Node) bool {
+	for _, m := range f.matchers {
+		value, ok := n.Metadata()[m.Key]
+		if !ok && m.Key == "version" {
+			value = n.Version()
+		}
+		if m.Equals != "" && value != m.Equals {
+			return false
+		}
+		if len(m.In) > 0 && !slices.Contains(m.In, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// filter returns the matched nodes, all the nodes are returned if none matches and falling back is allowed.
+func (f *nodeFilter) filter(nodes []selector.Node) ([]selector.Node, bool) {
+	if f == nil {
+		return nodes, true
+	}
+	matched := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if f.match(n) {
+			matched = append(matched, n)
+		}
+	}
+	if len(matched) == 0 && len(nodes) > 0 {
+		if f.fallback {
+			return nodes, true
+		}
+		return nil, false
+	}
+	return matched, true
+}
+
+// selectorFilter returns the selector node filter, noMatch is set if none of the nodes matches.
+func (f *nodeFilter) selectorFilter(noMatch *bool) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		out, ok := f.filter(nodes)
+		if !ok {
+			*noMatch = true
+		}
+		return out
+	}
+}
+
+func (f *nodeFilter) String() string {
+	if f == nil {
+		return ""
+	}
+	return FormatNodeMatchers(f.matchers)
+}
+
+// FormatNodeMatchers formats the matchers like "version=v2, tier in (premium,gold)".
+func FormatNodeMatchers(matchers []*config.NodeMatcher) string {
+	parts := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Equals != "" {
+			parts = append(parts, m.Key+"="+m.Equals)
+			continue
+		}
+		parts = append(parts, m.Key+" in ("+strings.Join(m.In, ",")+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// NodeFilterInspect is the node filters of the endpoint and the nodes they currently match.
+type NodeFilterInspect struct {
+	Filter    string `json:"filter,omitempty"`
+	OnNoMatch string `json:"on_no_match,omitempty"`
+	Nodes     int    `json:"nodes"`
+	Matching  int    `json:"matching"`
+}
+
+// NodeFilterInspector is implemented by the clients which select the subset of the nodes.
+type NodeFilterInspector interface {
+	NodeFilterInspect() *NodeFilterInspect
+}
+
+var _ NodeFilterInspector = (*client)(nil)
+
+func (c *client) NodeFilterInspect() *NodeFilterInspect {
+	nodes := c.applier.Nodes()
+	out := &NodeFilterInspect{Nodes: len(nodes), Matching: len(nodes)}
+	if f := c.applier.filter; f != nil {
+		out.Filter = f.String()
+		out.OnNoMatch = config.NodeFilters_ERROR.String()
+		if f.fallback {
+			out.OnNoMatch = config.NodeFilters_FALLBACK.String()
+		}
+		matched := 0
+		for _, n := range nodes {
+			if f.match(n) {
+				matched++
+			}
+		}
+		out.Matching = matched
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector/p2c"
+)
+
+func TestNodeFilters(t *testing.T) {
+	var backends []*config.Backend
+	for _, version := range []string{"v1", "v2", "v2"} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+		backends = append(backends, &config.Backend{
+			Target:   strings.TrimPrefix(srv.URL, "http://"),
+			Metadata: map[string]string{"version": version, "tier": "premium"},
+		})
+	}
+	do := func(filters *config.NodeFilters) (string, error) {
+		endpoint := &config.Endpoint{Path: "/beta", Protocol: config.Protocol_HTTP, Backends: backends, NodeFilters: filters}
+		c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		req := httptest.NewRequest(http.MethodGet, "/beta", nil)
+		opts := middleware.NewRequestOptions(endpoint)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), opts))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			return "", err
+		}
+		resp.Body.Close()
+		return opts.Upstream().Addr, nil
+	}
+
+	v2 := &config.NodeFilters{Matchers: []*config.NodeMatcher{
+		{Key: "version", Equals: "v2"},
+		{Key: "tier", In: []string{"premium", "gold"}},
+	}}
+	for i := 0; i < 20; i++ {
+		addr, err := do(v2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr == backends[0].Target {
+			t.Fatalf("want v2 nodes only but got %s", addr)
+		}
+	}
+
+	none := &config.NodeFilters{Matchers: []*config.NodeMatcher{{Key: "version", Equals: "v3"}}}
+	if _, err := do(none); !errors.Is(err, ErrNoMatchingNodes) {
+		t.Fatalf("want no matching nodes error but got %v", err)
+	}
+	none.OnNoMatch = config.NodeFilters_FALLBACK
+	if _, err := do(none); err != nil {
+		t.Fatalf("want fallback to all nodes but got %v", err)
+	}
+
+	invalid := &config.NodeFilters{Matchers: []*config.NodeMatcher{{Key: "version", Equals: "v2", In: []string{"v3"}}}}
+	if _, err := NewFactory(nil)(EmptyBuildContext(), &config.Endpoint{Backends: backends, NodeFilters: invalid}); err == nil {
+		t.Fatal("want invalid matcher error")
+	}
+}
+
+func TestDiscoveryNodeFilters(t *testing.T) {
+	filter, err := newNodeFilter(&config.NodeFilters{Matchers: []*config.NodeMatcher{{Key: "version", Equals: "v2"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	na := &nodeApplier{
+		endpoint:        &config.Endpoint{Protocol: config.Protocol_HTTP},
+		picker:          p2c.NewBuilder().Build(),
+		buildContext:    EmptyBuildContext(),
+		discoveryFilter: filter,
+		cancel:          func() {},
+	}
+	c := newClient(na, na.picker, nil)
+	if err := na.Callback([]*registry.ServiceInstance{
+		{ID: "1", Name: "svc", Version: "v1", Endpoints: []string{"http://127.0.0.1:8001"}},
+		{ID: "2", Name: "svc", Version: "v2", Endpoints: []string{"http://127.0.0.1:8002"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if nodes := na.Nodes(); len(nodes) != 1 || nodes[0].Address() != "127.0.0.1:8002" {
+		t.Fatalf("want the v2 instance only but got %v", nodes)
+	}
+	if got := c.NodeFilterInspect(); got.Nodes != 1 || got.Matching != 1 {
+		t.Fatalf("unexpected inspect: %+v", got)
+	}
+
+	// the filter matches none of the instances after the registry changed
+	if err := na.Callback([]*registry.ServiceInstance{
+		{ID: "1", Name: "svc", Version: "v1", Endpoints: []string{"http://127.0.0.1:8001"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(na.endpoint)))
+	if _, err := c.RoundTrip(req); !errors.Is(err, ErrNoMatchingNodes) {
+		t.Fatalf("want no matching nodes error but got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// pathPrefix is the base path the upstream path is joined under.
+type pathPrefix struct {
+	path    string
+	rawPath string
+}
+
+// newPathPrefix parses the prefix of the backend, the prefix of the endpoint is used if the backend has none.
+func newPathPrefix(endpoint *config.Endpoint, backend *config.Backend) (*pathPrefix, error) {
+	prefix := backend.GetUpstreamPathPrefix()
+	if prefix == "" {
+		prefix = endpoint.GetUpstreamPathPrefix()
+	}
+	if prefix == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream path prefix %q: %w", prefix, err)
+	}
+	if !strings.HasPrefix(prefix, "/") || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid upstream path prefix %q: only an absolute path is allowed", prefix)
+	}
+	return &pathPrefix{path: u.Path, rawPath: u.RawPath}, nil
+}
+
+// apply joins the path of u under the prefix with exactly one slash in between,
+// the escaped segments such as %2F are kept as they are.
+func (p *pathPrefix) apply(u *url.URL) {
+	if p == nil {
+		return
+	}
+	if p.rawPath == "" && u.RawPath == "" {
+		u.Path = singleJoiningSlash(p.path, u.Path)
+		return
+	}
+	prefix := &url.URL{Path: p.path, RawPath: p.rawPath}
+	escapedPrefix, escapedPath := prefix.EscapedPath(), u.EscapedPath()
+	switch prefixSlash, pathSlash := strings.HasSuffix(escapedPrefix, "/"), strings.HasPrefix(escapedPath, "/"); {
+	case prefixSlash && pathSlash:
+		u.Path, u.RawPath = p.path+u.Path[1:], escapedPrefix+escapedPath[1:]
+	case !prefixSlash && !pathSlash:
+		u.Path, u.RawPath = p.path+"/"+u.Path, escapedPrefix+"/"+escapedPath
+	default:
+		u.Path, u.RawPath = p.path+u.Path, escapedPrefix+escapedPath
+	}
+}
+
+func singleJoiningSlash(a, b string) string {
+	prefixSlash, pathSlash := strings.HasSuffix(a, "/"), strings.HasPrefix(b, "/")
+	switch {
+	case prefixSlash && pathSlash:
+		return a + b[1:]
+	case !prefixSlash && !pathSlash:
+		return a + "/" + b
+	}
+	return a + b
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/rewrite"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	rewritev1 "github.com/aide-family/goddess/pkg/middleware/rewrite/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestPathPrefix(t *testing.T) {
+	testCases := []struct {
+		prefix string
+		path   string
+		want   string
+	}{
+		{prefix: "", path: "/users?id=1", want: "/users?id=1"},
+		{prefix: "/internal/api/v3", path: "/users?id=1", want: "/internal/api/v3/users?id=1"},
+		{prefix: "/internal/api/v3/", path: "/users", want: "/internal/api/v3/users"},
+		{prefix: "/internal/api/v3", path: "/", want: "/internal/api/v3/"},
+		{prefix: "/internal/api/v3", path: "/files/a%2Fb?q=%2F", want: "/internal/api/v3/files/a%2Fb?q=%2F"},
+		{prefix: "/tenants/a%2Fb/", path: "/users", want: "/tenants/a%2Fb/users"},
+		{prefix: "/tenants/a%2Fb", path: "/files/c%2Fd", want: "/tenants/a%2Fb/files/c%2Fd"},
+	}
+	for _, tc := range testCases {
+		prefix, err := newPathPrefix(&config.Endpoint{UpstreamPathPrefix: tc.prefix}, &config.Backend{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(tc.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prefix.apply(u)
+		if got := u.RequestURI(); got != tc.want {
+			t.Errorf("join %q and %q = %q, want %q", tc.prefix, tc.path, got, tc.want)
+		}
+	}
+
+	for _, prefix := range []string{"api", "/api?x=1", "/api#x"} {
+		if _, err := newPathPrefix(&config.Endpoint{}, &config.Backend{UpstreamPathPrefix: prefix}); err == nil {
+			t.Errorf("want %q rejected", prefix)
+		}
+	}
+	backend, err := newPathPrefix(&config.Endpoint{UpstreamPathPrefix: "/endpoint"}, &config.Backend{UpstreamPathPrefix: "/backend"})
+	if err != nil || backend.path != "/backend" {
+		t.Fatalf("want the backend prefix to override the endpoint's but got %+v %v", backend, err)
+	}
+}
+
+func TestPathPrefixWithRewrite(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.RequestURI
+	}))
+	defer srv.Close()
+	endpoint := &config.Endpoint{
+		Path:               "/beta/*",
+		Protocol:           config.Protocol_HTTP,
+		UpstreamPathPrefix: "/internal/api/v3",
+		Backends:           []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	stripPrefix := "/beta"
+	options, err := anypb.New(&rewritev1.Rewrite{StripPrefix: &stripPrefix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := rewrite.Middleware(&config.Middleware{Name: "rewrite", Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the prefix is joined after the path is rewritten by the middlewares
+	req := httptest.NewRequest(http.MethodGet, "/beta/files/a%2Fb?q=1", nil)
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+	resp, err := m(c).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := <-received; got != "/internal/api/v3/files/a%2Fb?q=1" {
+		t.Fatalf("unexpected upstream request uri %s", got)
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+// PrewarmResult is the result of prewarming one upstream node.
+type PrewarmResult struct {
+	Address string
+	Err     error
+}
+
+// PrewarmBudget limits the connections established by prewarming, nil means unlimited.
+type PrewarmBudget struct {
+	left atomic.Int64
+}
+
+// NewPrewarmBudget returns a budget of n connections, it returns nil if n <= 0.
+func NewPrewarmBudget(n int) *PrewarmBudget {
+	if n <= 0 {
+		return nil
+	}
+	b := &PrewarmBudget{}
+	b.left.Store(int64(n))
+	return b
+}
+
+func (b *PrewarmBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	return b.left.Add(-1) >= 0
+}
+
+// Prewarmer is implemented by the clients which are able to establish upstream connections in advance.
+type Prewarmer interface {
+	// Prewarm establishes conns connections per upstream node within the budget.
+	Prewarm(ctx context.Context, conns int, budget *PrewarmBudget) []PrewarmResult
+}
+
+var _ Prewarmer = (*client)(nil)
+
+func (c *client) Prewarm(ctx context.Context, conns int, budget *PrewarmBudget) []PrewarmResult {
+	var targets []*node
+	for _, n := range c.applier.Nodes() {
+		backendNode, ok := n.(*node)
+		if !ok {
+			continue
+		}
+		for i := 0; i < conns; i++ {
+			if !budget.take() {
+				break
+			}
+			targets = append(targets, backendNode)
+		}
+	}
+	results := make([]PrewarmResult, len(targets))
+	wg := sync.WaitGroup{}
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = PrewarmResult{Address: target.address, Err: target.prewarm(ctx)}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// prewarm sends an `OPTIONS *` request, the connection is kept idle in the transport after the response is drained.
+func (n *node) prewarm(ctx context.Context) error {
+	scheme := "http"
+	if n.tls {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodOptions, scheme+"://"+n.address, nil)
+	if err != nil {
+		return err
+	}
+	req.URL = &url.URL{Scheme: scheme, Host: n.address, Opaque: "*"}
+	if host := n.metadata["host"]; host != "" {
+		req.Host = host
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
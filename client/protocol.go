@@ -0,0 +1,157 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+var _metricUpstreamProtocolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "upstream_protocol_errors_total",
+	Help:      "The total number of requests failed since the backend does not speak the upstream protocol",
+}, []string{"protocol", "method", "path", "service", "basePath", "upstream_protocol"})
+
+func init() {
+	metrics.Register(_metricUpstreamProtocolErrors)
+}
+
+// the errors of talking a HTTP version the backend does not speak.
+var _protocolMismatchErrors = []string{
+	// h2c to a HTTP/1.1 backend
+	"frame header looked like an HTTP/1.1 header",
+	// HTTP/1.1 to a h2c backend
+	"malformed HTTP response",
+	// h2 to a TLS backend without h2 in ALPN
+	"tls: no application protocol",
+	"unexpected ALPN protocol",
+}
+
+// UpstreamProtocolError is the error of the backend not speaking the upstream protocol.
+type UpstreamProtocolError struct {
+	Protocol config.UpstreamProtocol
+	Err      error
+}
+
+func (e *UpstreamProtocolError) Error() string {
+	return fmt.Sprintf("upstream protocol mismatch, the backend may not speak %s: %v", e.Protocol, e.Err)
+}
+
+func (e *UpstreamProtocolError) Unwrap() error {
+	return e.Err
+}
+
+func classifyUpstreamProtocolError(req *http.Request, protocol config.UpstreamProtocol, err error) error {
+	msg := err.Error()
+	for _, s := range _protocolMismatchErrors {
+		if !strings.Contains(msg, s) {
+			continue
+		}
+		if labels, ok := middleware.MetricsLabelsFromContext(req.Context()); ok {
+			_metricUpstreamProtocolErrors.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), protocol.String()).Inc()
+		}
+		return &UpstreamProtocolError{Protocol: protocol, Err: err}
+	}
+	return err
+}
+
+// effectiveUpstreamProtocol resolves the AUTO protocol of the gRPC backends.
+func effectiveUpstreamProtocol(protocol config.Protocol, upstream config.UpstreamProtocol, tls bool) config.UpstreamProtocol {
+	if upstream != config.UpstreamProtocol_AUTO || protocol != config.Protocol_GRPC {
+		return upstream
+	}
+	if tls {
+		return config.UpstreamProtocol_HTTP2
+	}
+	return config.UpstreamProtocol_H2C
+}
+
+// validateUpstreamProtocol reports the upstream protocol conflicting with the protocol or the backends.
+func validateUpstreamProtocol(e *config.Endpoint) error {
+	switch e.UpstreamProtocol {
+	case config.UpstreamProtocol_HTTP1:
+		if e.Protocol == config.Protocol_GRPC {
+			return fmt.Errorf("endpoint %s %s: gRPC requires HTTP/2, upstream protocol can not be HTTP1", e.Method, e.Path)
+		}
+	case config.UpstreamProtocol_HTTP2:
+		for _, backend := range e.Backends {
+			if isDiscoveryBackend(backend) {
+				return fmt.Errorf("endpoint %s %s: upstream protocol HTTP2 requires TLS backends, the nodes of %s are dialed in cleartext, use H2C", e.Method, e.Path, backend.Target)
+			}
+			if !backend.Tls {
+				return fmt.Errorf("endpoint %s %s: upstream protocol HTTP2 requires TLS backends, use H2C for %s", e.Method, e.Path, backend.Target)
+			}
+		}
+	case config.UpstreamProtocol_H2C:
+		for _, backend := range e.Backends {
+			if backend.Tls {
+				return fmt.Errorf("endpoint %s %s: upstream protocol H2C requires cleartext backends, use HTTP2 for %s", e.Method, e.Path, backend.Target)
+			}
+		}
+	}
+	return nil
+}
+
+// isDiscoveryBackend reports whether the nodes of the backend are resolved by the discovery, they are dialed in
+// cleartext regardless of the tls of the backend.
+func isDiscoveryBackend(backend *config.Backend) bool {
+	target, err := parseTarget(backend.Target)
+	return err == nil && target.Scheme == "discovery"
+}
+
+func createHTTP1HTTPSClient(tlsConfig *tls.Config, d *dialer) *http.Client {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{"http/1.1"}
+	return &http.Client{
+		CheckRedirect: defaultCheckRedirect,
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			// an empty TLSNextProto disables HTTP/2
+			TLSNextProto:          map[string]func(string, *tls.Conn) http.RoundTripper{},
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           d.DialContext,
+			MaxIdleConns:          10000,
+			MaxIdleConnsPerHost:   1000,
+			MaxConnsPerHost:       1000,
+			DisableCompression:    true,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+	}
+}
+
+func createHTTP2HTTPSClient(tlsConfig *tls.Config, d *dialer) *http.Client {
+	return &http.Client{
+		CheckRedirect: defaultCheckRedirect,
+		Transport: &http2.Transport{
+			TLSClientConfig:    tlsConfig,
+			DisableCompression: true,
+			DialTLSContext:     d.DialTLSContext,
+		},
+	}
+}
+
+func createHTTPSClientWithProtocol(tlsConfig *tls.Config, protocol config.UpstreamProtocol, d *dialer) *http.Client {
+	switch protocol {
+	case config.UpstreamProtocol_HTTP1:
+		return createHTTP1HTTPSClient(tlsConfig, d)
+	case config.UpstreamProtocol_HTTP2:
+		return createHTTP2HTTPSClient(tlsConfig, d)
+	default:
+		return createHTTPSClient(tlsConfig, d)
+	}
+}
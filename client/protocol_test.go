@@ -0,0 +1,91 @@
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestUpstreamProtocol(t *testing.T) {
+	protos := make(chan string, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protos <- r.Proto
+	})
+	srv := httptest.NewUnstartedServer(handler)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	buildCtx := &BuildContext{TLSClientStore: NewHTTPSClientStore(map[string]*tls.Config{"test": {InsecureSkipVerify: true}})}
+	do := func(e *config.Endpoint) error {
+		c, err := NewFactory(nil)(buildCtx, e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(e)))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	tlsBackend := []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "https://"), Tls: true, TlsConfigName: "test"}}
+	for _, tc := range []struct {
+		protocol config.UpstreamProtocol
+		want     string
+	}{
+		{config.UpstreamProtocol_AUTO, "HTTP/2.0"},
+		{config.UpstreamProtocol_HTTP1, "HTTP/1.1"},
+		{config.UpstreamProtocol_HTTP2, "HTTP/2.0"},
+	} {
+		if err := do(&config.Endpoint{Path: "/", Protocol: config.Protocol_HTTP, UpstreamProtocol: tc.protocol, Backends: tlsBackend}); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-protos; got != tc.want {
+			t.Fatalf("%s: want %s but got %s", tc.protocol, tc.want, got)
+		}
+	}
+
+	err := do(&config.Endpoint{
+		Path:             "/",
+		Protocol:         config.Protocol_HTTP,
+		UpstreamProtocol: config.UpstreamProtocol_H2C,
+		Backends:         []*config.Backend{{Target: strings.TrimPrefix(plain.URL, "http://")}},
+	})
+	var protocolErr *UpstreamProtocolError
+	if !errors.As(err, &protocolErr) || protocolErr.Protocol != config.UpstreamProtocol_H2C {
+		t.Fatalf("want upstream protocol error but got %v", err)
+	}
+}
+
+func TestValidateUpstreamProtocol(t *testing.T) {
+	for _, e := range []*config.Endpoint{
+		{Protocol: config.Protocol_GRPC, UpstreamProtocol: config.UpstreamProtocol_HTTP1},
+		{UpstreamProtocol: config.UpstreamProtocol_HTTP2, Backends: []*config.Backend{{Target: "127.0.0.1:8000"}}},
+		{UpstreamProtocol: config.UpstreamProtocol_H2C, Backends: []*config.Backend{{Target: "127.0.0.1:8000", Tls: true}}},
+		// the discovery nodes are cleartext even if the backend sets tls
+		{UpstreamProtocol: config.UpstreamProtocol_HTTP2, Backends: []*config.Backend{{Target: "discovery:///orders", Tls: true}}},
+		{UpstreamProtocol: config.UpstreamProtocol_H2C, Backends: []*config.Backend{{Target: "discovery:///orders", Tls: true}}},
+	} {
+		if err := validateUpstreamProtocol(e); err == nil {
+			t.Fatalf("want error with %v", e)
+		}
+	}
+	if err := validateUpstreamProtocol(&config.Endpoint{UpstreamProtocol: config.UpstreamProtocol_H2C, Backends: []*config.Backend{{Target: "discovery:///orders"}}}); err != nil {
+		t.Fatalf("want H2C accepted for the discovery backend but got %v", err)
+	}
+	if got := effectiveUpstreamProtocol(config.Protocol_GRPC, config.UpstreamProtocol_AUTO, true); got != config.UpstreamProtocol_HTTP2 {
+		t.Fatalf("want gRPC over TLS to require HTTP2 but got %s", got)
+	}
+}
@@ -0,0 +1,414 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const _defaultLookupTimeout = 2 * time.Second
+
+var (
+	_metricDNSCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "dns_cache_total",
+		Help:      "The total number of the upstream hostname lookups by the cache result",
+	}, []string{"result"})
+	_metricDNSLookupDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "dns_lookup_duration_seconds",
+		Help:      "Upstream hostname lookup duration(sec).",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2},
+	}, []string{"result"})
+)
+
+func init() {
+	metrics.Register(_metricDNSCache)
+	metrics.Register(_metricDNSLookupDuration)
+}
+
+// _dnsCache caches the lookups of the upstream hostnames for all the transports.
+var _dnsCache = newDNSCache(net.DefaultResolver.LookupHost, exchangeNameservers)
+
+// resolverConfig is the gateway resolver config, the legacy dns_cache_ttl caches for exactly the ttl.
+type resolverConfig struct {
+	nameservers []string
+	timeout     time.Duration
+	minTTL      time.Duration
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	roundRobin  bool
+}
+
+func newResolverConfig(c *config.Gateway) *resolverConfig {
+	r := c.GetResolver()
+	if r == nil {
+		ttl := c.GetDnsCacheTtl().AsDuration()
+		return &resolverConfig{timeout: _defaultLookupTimeout, minTTL: ttl, maxTTL: ttl}
+	}
+	out := &resolverConfig{
+		timeout:     _defaultLookupTimeout,
+		minTTL:      r.GetMinTtl().AsDuration(),
+		maxTTL:      r.GetMaxTtl().AsDuration(),
+		negativeTTL: r.GetNegativeTtl().AsDuration(),
+		roundRobin:  r.GetRoundRobin(),
+	}
+	if r.GetLookupTimeout().AsDuration() > 0 {
+		out.timeout = r.GetLookupTimeout().AsDuration()
+	}
+	for _, ns := range r.GetNameservers() {
+		if _, _, err := net.SplitHostPort(ns); err != nil {
+			ns = net.JoinHostPort(ns, "53")
+		}
+		out.nameservers = append(out.nameservers, ns)
+	}
+	return out
+}
+
+// enabled reports whether the lookups go through the cache instead of the dial of the transport.
+func (c *resolverConfig) enabled() bool {
+	return c.maxTTL > 0 || c.negativeTTL > 0 || len(c.nameservers) > 0
+}
+
+func (c *resolverConfig) clamp(ttl time.Duration) time.Duration {
+	return min(max(ttl, c.minTTL), c.maxTTL)
+}
+
+// dnsCache resolves the hostnames through the cache, it is disabled until configured.
+type dnsCache struct {
+	config       atomic.Pointer[resolverConfig]
+	systemLookup func(ctx context.Context, host string) ([]string, error)
+	exchange     func(ctx context.Context, msg *dns.Msg, nameservers []string) (*dns.Msg, error)
+
+	lock    sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	err     error
+	expires time.Time
+	next    atomic.Uint32
+}
+
+func newDNSCache(systemLookup func(ctx context.Context, host string) ([]string, error),
+	exchange func(ctx context.Context, msg *dns.Msg, nameservers []string) (*dns.Msg, error)) *dnsCache {
+	c := &dnsCache{systemLookup: systemLookup, exchange: exchange, entries: map[string]*dnsEntry{}}
+	c.config.Store(&resolverConfig{})
+	return c
+}
+
+// setConfig replaces the config, the cached entries are dropped if it changes.
+func (c *dnsCache) setConfig(rc *resolverConfig) {
+	if reflect.DeepEqual(c.config.Swap(rc), rc) {
+		return
+	}
+	c.lock.Lock()
+	c.entries = map[string]*dnsEntry{}
+	c.lock.Unlock()
+}
+
+// resolve returns the addresses of the host, nil if the cache is disabled.
+func (c *dnsCache) resolve(ctx context.Context, host string) ([]string, error) {
+	rc := c.config.Load()
+	if !rc.enabled() {
+		return nil, nil
+	}
+	now := time.Now()
+	c.lock.Lock()
+	entry, ok := c.entries[host]
+	c.lock.Unlock()
+	if ok && now.Before(entry.expires) {
+		if entry.err != nil {
+			_metricDNSCache.WithLabelValues("negative_hit").Inc()
+			return nil, entry.err
+		}
+		_metricDNSCache.WithLabelValues("hit").Inc()
+		return entry.ordered(rc.roundRobin), nil
+	}
+	_metricDNSCache.WithLabelValues("miss").Inc()
+	addrs, ttl, err := c.lookup(ctx, rc, host)
+	entry = &dnsEntry{addrs: addrs, err: err, expires: now.Add(rc.clamp(ttl))}
+	if err != nil {
+		// only the hosts without the records are cached, the timeouts and the server failures are retried
+		entry.expires = now
+		if dnsErr := (*net.DNSError)(nil); errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			entry.expires = now.Add(rc.negativeTTL)
+		}
+	}
+	if entry.expires.After(now) {
+		c.lock.Lock()
+		c.entries[host] = entry
+		c.lock.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry.ordered(rc.roundRobin), nil
+}
+
+// ordered returns the addresses rotated by one on each call if round robin is enabled.
+func (e *dnsEntry) ordered(roundRobin bool) []string {
+	if !roundRobin || len(e.addrs) < 2 {
+		return e.addrs
+	}
+	start := int((e.next.Add(1) - 1) % uint32(len(e.addrs)))
+	out := make([]string, 0, len(e.addrs))
+	out = append(out, e.addrs[start:]...)
+	return append(out, e.addrs[:start]...)
+}
+
+// lookup resolves the host through the nameservers, or the system resolver whose records are kept for max_ttl.
+// The failures are *net.DNSError so they are classified as the upstream connect errors.
+func (c *dnsCache) lookup(ctx context.Context, rc *resolverConfig, host string) (addrs []string, ttl time.Duration, err error) {
+	start := time.Now()
+	defer func() {
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		_metricDNSLookupDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+	}()
+	ctx, cancel := context.WithTimeout(ctx, rc.timeout)
+	defer cancel()
+	if len(rc.nameservers) == 0 {
+		addrs, err = c.systemLookup(ctx, host)
+		if err != nil {
+			var dnsErr *net.DNSError
+			if !errors.As(err, &dnsErr) {
+				err = &net.DNSError{Err: err.Error(), Name: host, IsTimeout: ctx.Err() != nil}
+			}
+		}
+		return addrs, rc.maxTTL, err
+	}
+	return c.queryNameservers(ctx, rc.nameservers, host)
+}
+
+func (c *dnsCache) queryNameservers(ctx context.Context, nameservers []string, host string) ([]string, time.Duration, error) {
+	var (
+		addrs  []string
+		minTTL = uint32(math.MaxUint32)
+	)
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(host), qtype)
+		resp, err := c.exchange(ctx, msg, nameservers)
+		if err != nil {
+			return nil, 0, &net.DNSError{Err: err.Error(), Name: host, IsTimeout: ctx.Err() != nil, IsTemporary: true}
+		}
+		switch resp.Rcode {
+		case dns.RcodeSuccess:
+		case dns.RcodeNameError:
+			return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		default:
+			return nil, 0, &net.DNSError{Err: "server misbehaving: " + dns.RcodeToString[resp.Rcode], Name: host, IsTemporary: true}
+		}
+		for _, rr := range resp.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				addrs = append(addrs, rr.A.String())
+				minTTL = min(minTTL, rr.Hdr.Ttl)
+			case *dns.AAAA:
+				addrs = append(addrs, rr.AAAA.String())
+				minTTL = min(minTTL, rr.Hdr.Ttl)
+			}
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, 0, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, time.Duration(minTTL) * time.Second, nil
+}
+
+// exchangeNameservers sends the query to the nameservers in order until one answers,
+// the truncated answers are retried over TCP.
+func exchangeNameservers(ctx context.Context, msg *dns.Msg, nameservers []string) (*dns.Msg, error) {
+	var lastErr error
+	for _, ns := range nameservers {
+		resp, _, err := (&dns.Client{}).ExchangeContext(ctx, msg, ns)
+		if err == nil && resp.Truncated {
+			resp, _, err = (&dns.Client{Net: "tcp"}).ExchangeContext(ctx, msg, ns)
+		}
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// dialer dials the upstreams through the host overrides first, then the DNS cache.
+type dialer struct {
+	net       *net.Dialer
+	overrides map[string][]string
+	// egress tunnels the connections through the proxy, the proxy resolves the hosts instead of the overrides
+	egress *egressProxy
+}
+
+func newDialer(overrides map[string][]string) *dialer {
+	return &dialer{
+		net: &net.Dialer{
+			Timeout:   _dialTimeout,
+			KeepAlive: 30 * time.Second,
+		},
+		overrides: overrides,
+	}
+}
+
+func (d *dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d.egress.proxied(addr) {
+		return d.egress.dial(ctx, d.net, network, addr)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return d.net.DialContext(ctx, network, addr)
+	}
+	targets, ok := d.overrides[strings.ToLower(host)]
+	if !ok {
+		if net.ParseIP(host) != nil {
+			return d.net.DialContext(ctx, network, addr)
+		}
+		if targets, err = _dnsCache.resolve(ctx, host); err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			return d.net.DialContext(ctx, network, addr)
+		}
+	}
+	var lastErr error
+	for _, target := range targets {
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			target = net.JoinHostPort(target, port)
+		}
+		conn, err := d.net.DialContext(ctx, network, target)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// DialTLSContext dials the TLS connection, the server name of cfg is the hostname of the upstream.
+func (d *dialer) DialTLSContext(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+	conn, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// newHostOverrides validates the addresses of the overrides.
+func newHostOverrides(in map[string]*config.HostOverride) (map[string][]string, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	out := make(map[string][]string, len(in))
+	for host, override := range in {
+		if len(override.GetAddresses()) == 0 {
+			return nil, fmt.Errorf("host override %s: addresses are required", host)
+		}
+		for _, addr := range override.GetAddresses() {
+			ip := addr
+			if h, _, err := net.SplitHostPort(addr); err == nil {
+				ip = h
+			}
+			if net.ParseIP(ip) == nil {
+				return nil, fmt.Errorf("host override %s: %q is not an IP with an optional port", host, addr)
+			}
+		}
+		out[strings.ToLower(host)] = override.GetAddresses()
+	}
+	return out, nil
+}
+
+// mergeHostOverrides returns the overrides of the gateway replaced by the endpoint ones of the same hostname.
+func mergeHostOverrides(gateway, endpoint map[string][]string) map[string][]string {
+	if len(endpoint) == 0 {
+		return gateway
+	}
+	out := make(map[string][]string, len(gateway)+len(endpoint))
+	for host, addrs := range gateway {
+		out[host] = addrs
+	}
+	for host, addrs := range endpoint {
+		out[host] = addrs
+	}
+	return out
+}
+
+// hostClientStore holds the clients dialing the overridden hosts or through the egress proxies, the connections
+// of them are not shared with the clients resolving the hosts by DNS.
+type hostClientStore struct {
+	lock    sync.Mutex
+	clients map[string]*http.Client
+}
+
+func (s *hostClientStore) get(ctx *BuildContext, host string, addrs []string, opt *NodeOptions, protocol config.UpstreamProtocol) *http.Client {
+	key := fmt.Sprintf("%s=%s/%t/%s/%s", host, strings.Join(addrs, ","), opt.TLS, opt.TLSConfigName, protocol)
+	if opt.egress != nil {
+		key += "/" + opt.egress.key
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if client, ok := s.clients[key]; ok {
+		return client
+	}
+	var overrides map[string][]string
+	if host != "" {
+		overrides = map[string][]string{host: addrs}
+	}
+	d := newDialer(overrides)
+	d.egress = opt.egress
+	var client *http.Client
+	switch {
+	case opt.TLS:
+		var tlsConfig *tls.Config
+		if opt.TLSConfigName != "" {
+			tlsConfig = ctx.TLSConfigs[opt.TLSConfigName]
+		}
+		client = createHTTPSClientWithProtocol(tlsConfig, protocol, d)
+	case protocol == config.UpstreamProtocol_H2C:
+		client = defaultH2CClient(d)
+	default:
+		client = defaultClient(d)
+	}
+	if s.clients == nil {
+		s.clients = map[string]*http.Client{}
+	}
+	s.clients[key] = client
+	return client
+}
+
+// HostOverridesInspector is implemented by the clients dialing the overridden hosts.
+type HostOverridesInspector interface {
+	HostOverrides() map[string][]string
+}
+
+var _ HostOverridesInspector = (*client)(nil)
+
+// HostOverrides returns the effective overrides of the endpoint.
+func (c *client) HostOverrides() map[string][]string {
+	return c.applier.hostOverrides
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestHostOverrides(t *testing.T) {
+	received := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.URL.Path
+	}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(srv.URL, "http://"))
+
+	do := func(buildCtx *BuildContext, endpoint *config.Endpoint) error {
+		c, err := NewFactory(nil)(buildCtx, endpoint)
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	// the endpoint override replaces the gateway one of the same hostname
+	buildCtx := NewBuildContext(&config.Gateway{HostOverrides: map[string]*config.HostOverride{
+		"api.internal": {Addresses: []string{"127.0.0.2:1"}},
+	}})
+	endpoint := &config.Endpoint{
+		Path:          "/",
+		Protocol:      config.Protocol_HTTP,
+		Backends:      []*config.Backend{{Target: "api.internal:" + port}},
+		HostOverrides: map[string]*config.HostOverride{"API.internal": {Addresses: []string{"127.0.0.1"}}},
+	}
+	if err := do(buildCtx, endpoint); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+
+	// the port of the override address takes precedence
+	endpoint = &config.Endpoint{
+		Path:          "/",
+		Protocol:      config.Protocol_HTTP,
+		Backends:      []*config.Backend{{Target: "api.internal:1"}},
+		HostOverrides: map[string]*config.HostOverride{"api.internal": {Addresses: []string{"127.0.0.1:" + port}}},
+	}
+	if err := do(EmptyBuildContext(), endpoint); err != nil {
+		t.Fatal(err)
+	}
+	<-received
+
+	endpoint.HostOverrides = map[string]*config.HostOverride{"api.internal": {Addresses: []string{"api.example.com"}}}
+	if err := do(EmptyBuildContext(), endpoint); err == nil {
+		t.Fatal("want the hostname address rejected")
+	}
+	// the invalid overrides of the gateway fail the endpoints instead of being ignored
+	buildCtx = NewBuildContext(&config.Gateway{HostOverrides: map[string]*config.HostOverride{
+		"api.internal": {Addresses: []string{"api.example.com"}},
+	}})
+	endpoint.HostOverrides = nil
+	if err := do(buildCtx, endpoint); err == nil {
+		t.Fatal("want the invalid gateway overrides rejected")
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	lookups := 0
+	cache := newDNSCache(func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		return []string{"10.0.0.1"}, nil
+	}, nil)
+	if addrs, _ := cache.resolve(context.Background(), "api.internal"); addrs != nil {
+		t.Fatalf("want the cache disabled by default but got %v", addrs)
+	}
+	cache.setConfig(&resolverConfig{timeout: time.Second, minTTL: time.Minute, maxTTL: time.Minute})
+	for i := 0; i < 3; i++ {
+		if addrs, err := cache.resolve(context.Background(), "api.internal"); err != nil || addrs[0] != "10.0.0.1" {
+			t.Fatalf("unexpected lookup %v %v", addrs, err)
+		}
+	}
+	if lookups != 1 {
+		t.Fatalf("want the lookup cached but got %d lookups", lookups)
+	}
+	// the entries are dropped once the ttl changes
+	cache.setConfig(&resolverConfig{timeout: time.Second, minTTL: time.Second, maxTTL: time.Second})
+	cache.resolve(context.Background(), "api.internal")
+	if lookups != 2 {
+		t.Fatalf("want looked up again but got %d lookups", lookups)
+	}
+}
+
+func TestDNSCacheNameservers(t *testing.T) {
+	var (
+		queries int
+		rcode   = dns.RcodeSuccess
+	)
+	cache := newDNSCache(nil, func(ctx context.Context, msg *dns.Msg, nameservers []string) (*dns.Msg, error) {
+		queries++
+		if nameservers[0] != "10.0.0.53:53" {
+			t.Fatalf("want the default port appended but got %v", nameservers)
+		}
+		resp := new(dns.Msg)
+		resp.SetRcode(msg, rcode)
+		if rcode == dns.RcodeSuccess && msg.Question[0].Qtype == dns.TypeA {
+			for i, ttl := range []uint32{1, 600} {
+				resp.Answer = append(resp.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: msg.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: ttl},
+					A:   net.IPv4(10, 0, 0, byte(i+1)),
+				})
+			}
+		}
+		return resp, nil
+	})
+	cache.setConfig(newResolverConfig(&config.Gateway{Resolver: &config.Resolver{
+		Nameservers: []string{"10.0.0.53"},
+		MinTtl:      durationpb.New(time.Minute),
+		MaxTtl:      durationpb.New(time.Hour),
+		NegativeTtl: durationpb.New(time.Minute),
+		RoundRobin:  true,
+	}}))
+
+	// the 1s record ttl is raised to min_ttl so the second resolve is a hit
+	first, err := cache.resolve(context.Background(), "api.internal")
+	if err != nil || len(first) != 2 {
+		t.Fatalf("unexpected lookup %v %v", first, err)
+	}
+	second, _ := cache.resolve(context.Background(), "api.internal")
+	if queries != 2 {
+		t.Fatalf("want the A and AAAA queries once but got %d", queries)
+	}
+	if first[0] == second[0] {
+		t.Fatalf("want the addresses rotated but got %v and %v", first, second)
+	}
+
+	// the failures are cached for negative_ttl as the dns errors classified as upstream_connect
+	rcode = dns.RcodeNameError
+	for i := 0; i < 2; i++ {
+		_, err := cache.resolve(context.Background(), "missing.internal")
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Fatalf("want the not found dns error but got %v", err)
+		}
+	}
+	if queries != 3 {
+		t.Fatalf("want the failure cached but got %d queries", queries)
+	}
+
+	// the server failures are retried
+	rcode = dns.RcodeServerFailure
+	for i := 0; i < 2; i++ {
+		if _, err := cache.resolve(context.Background(), "flaky.internal"); err == nil {
+			t.Fatal("want the server failure returned")
+		}
+	}
+	if queries != 5 {
+		t.Fatalf("want the server failure not cached but got %d queries", queries)
+	}
+}
@@ -16,6 +16,10 @@ import (
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var ErrCancelWatch = errors.New("cancel watch")
@@ -24,6 +28,9 @@ var LOG = log.NewHelper(log.With(log.GetLogger(), "source", "servicewatch"))
 
 var _initialResolveTimeout = time.Duration(0)
 
+// _tracer traces the watches of the discovery with the tracer provider of the tracing middleware.
+var _tracer = otel.Tracer("gateway")
+
 func init() {
 	debug.Register("watcher", globalServiceWatcher)
 
@@ -58,6 +65,8 @@ type watcherStatus struct {
 	watcher           registry.Watcher
 	initializedChan   chan struct{}
 	selectedInstances []*registry.ServiceInstance
+	// empty is set while the discovery returns zero instances
+	empty bool
 }
 
 type serviceWatcher struct {
@@ -131,8 +140,12 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 		ws = &watcherStatus{
 			initializedChan: make(chan struct{}),
 		}
+		_, span := _tracer.Start(ctx, "discovery watch", trace.WithAttributes(attribute.String("discovery.endpoint", endpoint)))
+		defer span.End()
 		watcher, err := discovery.Watch(ctx, endpoint)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
 			LOG.Errorf("Failed to initialize watcher on endpoint: %s, err: %+v", endpoint, err)
 			return false
 		}
@@ -167,12 +180,16 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 
 			select {
 			case services := <-initialServicesChan:
+				span.SetAttributes(attribute.Int("discovery.instances", len(services)))
 				ws.selectedInstances = services
+				ws.setEmpty(endpoint, len(services))
 				applier.Callback(services)
 			case <-initialResolveCtx.Done():
 				emptyServices := []*registry.ServiceInstance{}
 				ws.selectedInstances = emptyServices
+				ws.setEmpty(endpoint, 0)
 				applier.Callback(emptyServices)
+				span.SetAttributes(attribute.Bool("discovery.initial_resolve_timeout", true))
 				LOG.Warnf("Initial resolve timeout on endpoint: %s, will attempt asynchronously", endpoint)
 			}
 		}()
@@ -185,17 +202,15 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 						LOG.Warnf("The watch process on: %s has been canceled", endpoint)
 						return
 					}
+					_, span := _tracer.Start(context.Background(), "discovery watch event", trace.WithAttributes(attribute.String("discovery.endpoint", endpoint)))
+					span.RecordError(err)
+					span.SetStatus(codes.Error, err.Error())
+					span.End()
 					LOG.Errorf("Failed to watch on endpoint: %s, err: %+v, the watch process will attempt again after 1 second", endpoint, err)
 					time.Sleep(time.Second)
 					continue
 				}
-				if len(services) == 0 {
-					LOG.Warnf("Empty services on endpoint: %s, this most likely no available instance in discovery", endpoint)
-					continue
-				}
-				LOG.Infof("Received %d services on endpoint: %s, hash: %s", len(services), endpoint, instancesSetHash(services))
-				s.setSelectedCache(endpoint, services)
-				s.doCallback(endpoint, services)
+				s.applyWatchEvent(ws, endpoint, services)
 			}
 		}()
 
@@ -213,6 +228,21 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 	return existed
 }
 
+// applyWatchEvent applies the instances received by the watcher to the appliers of the endpoint in a span.
+func (s *serviceWatcher) applyWatchEvent(ws *watcherStatus, endpoint string, services []*registry.ServiceInstance) {
+	hash := instancesSetHash(services)
+	_, span := _tracer.Start(context.Background(), "discovery watch event", trace.WithAttributes(
+		attribute.String("discovery.endpoint", endpoint),
+		attribute.Int("discovery.instances", len(services)),
+		attribute.String("discovery.hash", hash),
+	))
+	defer span.End()
+	ws.setEmpty(endpoint, len(services))
+	LOG.Infof("Received %d services on endpoint: %s, hash: %s", len(services), endpoint, hash)
+	s.setSelectedCache(endpoint, services)
+	s.doCallback(endpoint, services)
+}
+
 func (s *serviceWatcher) doCallback(endpoint string, services []*registry.ServiceInstance) {
 	canceled := 0
 	func() {
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/selector"
+)
+
+const _defaultStickyCookieName = "goddess_affinity"
+
+// stickyCookie pins the client to the upstream node recorded in a signed cookie,
+// the value is base64(address).expire_at.base64(hmac-sha256(key, base64(address).expire_at)).
+type stickyCookie struct {
+	name     string
+	path     string
+	ttl      time.Duration
+	key      []byte
+	secure   bool
+	httpOnly bool
+	sameSite http.SameSite
+}
+
+func newStickyCookie(c *config.StickyCookie) (*stickyCookie, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.SigningKey == "" {
+		return nil, errors.New("sticky cookie signing key is required")
+	}
+	s := &stickyCookie{
+		name:     c.Name,
+		path:     c.Path,
+		ttl:      c.Ttl.AsDuration(),
+		key:      []byte(c.SigningKey),
+		secure:   c.Secure,
+		httpOnly: !c.DisableHttpOnly,
+		sameSite: http.SameSiteLaxMode,
+	}
+	if s.name == "" {
+		s.name = _defaultStickyCookieName
+	}
+	if s.path == "" {
+		s.path = "/"
+	}
+	switch c.SameSite {
+	case config.StickyCookie_STRICT:
+		s.sameSite = http.SameSiteStrictMode
+	case config.StickyCookie_NONE:
+		s.sameSite = http.SameSiteNoneMode
+	}
+	return s, nil
+}
+
+func (s *stickyCookie) signature(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *stickyCookie) encode(addr string, now time.Time) string {
+	var expireAt int64
+	if s.ttl > 0 {
+		expireAt = now.Add(s.ttl).Unix()
+	}
+	payload := base64.RawURLEncoding.EncodeToString([]byte(addr)) + "." + strconv.FormatInt(expireAt, 10)
+	return payload + "." + s.signature(payload)
+}
+
+func (s *stickyCookie) decode(value string, now time.Time) (string, bool) {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return "", false
+	}
+	payload, signature := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.signature(payload))) {
+		return "", false
+	}
+	encoded, expire, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", false
+	}
+	expireAt, err := strconv.ParseInt(expire, 10, 64)
+	if err != nil || (expireAt > 0 && now.Unix() > expireAt) {
+		return "", false
+	}
+	addr, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	return string(addr), true
+}
+
+// address returns the pinned node address of the request, forged and expired cookies are ignored.
+func (s *stickyCookie) address(req *http.Request) (string, bool) {
+	cookie, err := req.Cookie(s.name)
+	if err != nil {
+		return "", false
+	}
+	return s.decode(cookie.Value, time.Now())
+}
+
+// filter selects the pinned node only, all nodes are kept to re-balance if it is gone or filtered out.
+func (s *stickyCookie) filter(addr string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		for _, n := range nodes {
+			if n.Address() == addr {
+				return []selector.Node{n}
+			}
+		}
+		return nodes
+	}
+}
+
+// setCookie pins the client to the node by the response.
+func (s *stickyCookie) setCookie(h http.Header, addr string) {
+	cookie := &http.Cookie{
+		Name:     s.name,
+		Value:    s.encode(addr, time.Now()),
+		Path:     s.path,
+		Secure:   s.secure,
+		HttpOnly: s.httpOnly,
+		SameSite: s.sameSite,
+	}
+	if s.ttl > 0 {
+		cookie.MaxAge = int(s.ttl / time.Second)
+	}
+	h.Add("Set-Cookie", cookie.String())
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestStickyCookie(t *testing.T) {
+	var backends []*config.Backend
+	for i := 0; i < 3; i++ {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+		backends = append(backends, &config.Backend{Target: strings.TrimPrefix(srv.URL, "http://")})
+	}
+	endpoint := &config.Endpoint{
+		Path:     "/sticky",
+		Protocol: config.Protocol_HTTP,
+		Backends: backends,
+		StickyCookie: &config.StickyCookie{
+			SigningKey: "secret",
+			Ttl:        durationpb.New(time.Hour),
+			Secure:     true,
+			SameSite:   config.StickyCookie_STRICT,
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	do := func(cookie *http.Cookie) (*http.Response, string) {
+		req := httptest.NewRequest(http.MethodGet, "/sticky", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		opts := middleware.NewRequestOptions(endpoint)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), opts))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp, opts.Upstream().Addr
+	}
+
+	resp, pinned := do(nil)
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != _defaultStickyCookieName {
+		t.Fatalf("want affinity cookie but got %v", resp.Header["Set-Cookie"])
+	}
+	if setCookie := resp.Header.Get("Set-Cookie"); !strings.Contains(setCookie, "HttpOnly") ||
+		!strings.Contains(setCookie, "Secure") || !strings.Contains(setCookie, "SameSite=Strict") || !strings.Contains(setCookie, "Max-Age=3600") {
+		t.Fatalf("unexpected cookie attributes: %s", setCookie)
+	}
+	for i := 0; i < 20; i++ {
+		resp, addr := do(cookies[0])
+		if addr != pinned {
+			t.Fatalf("want pinned node %s but got %s", pinned, addr)
+		}
+		if len(resp.Cookies()) != 0 {
+			t.Fatalf("want no cookie update but got %v", resp.Header["Set-Cookie"])
+		}
+	}
+
+	forged := &http.Cookie{Name: _defaultStickyCookieName, Value: cookies[0].Value + "x"}
+	if resp, _ := do(forged); len(resp.Cookies()) != 1 {
+		t.Fatal("want forged cookie to be replaced")
+	}
+
+	gone, err := newStickyCookie(endpoint.StickyCookie)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, addr := do(&http.Cookie{Name: _defaultStickyCookieName, Value: gone.encode("127.0.0.1:1", time.Now())})
+	if addr == "127.0.0.1:1" || len(resp.Cookies()) != 1 {
+		t.Fatalf("want re-balanced node with new cookie but got %s", addr)
+	}
+}
+
+func TestStickyCookieExpired(t *testing.T) {
+	s, err := newStickyCookie(&config.StickyCookie{SigningKey: "secret", Ttl: durationpb.New(time.Minute)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := s.encode("127.0.0.1:8000", time.Now().Add(-time.Hour))
+	if _, ok := s.decode(value, time.Now()); ok {
+		t.Fatal("want expired cookie to be ignored")
+	}
+	if addr, ok := s.decode(s.encode("127.0.0.1:8000", time.Now()), time.Now()); !ok || addr != "127.0.0.1:8000" {
+		t.Fatalf("unexpected address: %s", addr)
+	}
+	if _, err := newStickyCookie(&config.StickyCookie{}); err == nil {
+		t.Fatal("want error without signing key")
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"golang.org/x/net/http2"
+)
+
+// TransportDecorator customizes the upstream transport of the endpoints referencing it in transport_decorators,
+// eg: a custom dialer or a proxy of one upstream. The transport is a copy owned by the endpoint, the
+// decorators are applied again to a fresh copy whenever the endpoint is built by a reload.
+type TransportDecorator func(*http.Transport, *config.Endpoint) error
+
+var (
+	_transportDecoratorsLock sync.RWMutex
+	_transportDecorators     = map[string]TransportDecorator{}
+)
+
+// RegisterTransportDecorator registers the decorator by name, it is expected to be called by the embedders on init.
+func RegisterTransportDecorator(name string, decorator TransportDecorator) {
+	_transportDecoratorsLock.Lock()
+	defer _transportDecoratorsLock.Unlock()
+	_transportDecorators[name] = decorator
+}
+
+// decoratedClients holds the clients of an endpoint with the decorated copies of the base transports.
+type decoratedClients struct {
+	endpoint   *config.Endpoint
+	decorators []TransportDecorator
+
+	lock    sync.Mutex
+	clients map[*http.Client]*http.Client
+	failed  error
+}
+
+func newDecoratedClients(e *config.Endpoint) (*decoratedClients, error) {
+	if len(e.TransportDecorators) == 0 {
+		return nil, nil
+	}
+	_transportDecoratorsLock.RLock()
+	defer _transportDecoratorsLock.RUnlock()
+	d := &decoratedClients{endpoint: e, clients: map[*http.Client]*http.Client{}}
+	for _, name := range e.TransportDecorators {
+		decorator, ok := _transportDecorators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transport decorator: %q", name)
+		}
+		d.decorators = append(d.decorators, decorator)
+	}
+	return d, nil
+}
+
+// get returns the client with the decorated copy of the transport of base, the client replies the error of
+// decorating if it fails, the first error is kept by firstError.
+func (d *decoratedClients) get(base *http.Client) *http.Client {
+	if d == nil {
+		return base
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if client, ok := d.clients[base]; ok {
+		return client
+	}
+	client, err := d.decorate(base)
+	if err != nil {
+		LOG.Errorf("failed to decorate the transport of endpoint %s %s: %v", d.endpoint.Method, d.endpoint.Path, err)
+		if d.failed == nil {
+			d.failed = err
+		}
+		client = &http.Client{Transport: errorTransport{err: err}}
+	}
+	d.clients[base] = client
+	return client
+}
+
+func (d *decoratedClients) decorate(base *http.Client) (*http.Client, error) {
+	tr, ok := base.Transport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("transport decorators require HTTP/1 or AUTO upstream protocol")
+	}
+	tr = tr.Clone()
+	// HTTP/2 is configured again on the copy, the cloned one is bound to the base transport
+	_, h2 := tr.TLSNextProto["h2"]
+	if h2 {
+		tr.TLSNextProto = nil
+	}
+	for i, decorator := range d.decorators {
+		if err := decorator(tr, d.endpoint); err != nil {
+			return nil, fmt.Errorf("transport decorator %q: %w", d.endpoint.TransportDecorators[i], err)
+		}
+	}
+	if h2 && tr.TLSNextProto == nil {
+		if err := http2.ConfigureTransport(tr); err != nil {
+			return nil, err
+		}
+	}
+	return &http.Client{CheckRedirect: base.CheckRedirect, Transport: tr}, nil
+}
+
+func (d *decoratedClients) firstError() error {
+	if d == nil {
+		return nil
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.failed
+}
+
+// close closes the idle connections of the decorated transports, the connections are not shared with the others.
+func (d *decoratedClients) close() {
+	if d == nil {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, client := range d.clients {
+		client.CloseIdleConnections()
+	}
+}
+
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
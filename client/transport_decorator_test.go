@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestTransportDecorators(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	var dials, applied atomic.Int64
+	RegisterTransportDecorator("test-dial", func(tr *http.Transport, e *config.Endpoint) error {
+		applied.Add(1)
+		dial := tr.DialContext
+		tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dials.Add(1)
+			return dial(ctx, network, addr)
+		}
+		return nil
+	})
+	RegisterTransportDecorator("test-fail", func(*http.Transport, *config.Endpoint) error {
+		return errors.New("failed")
+	})
+
+	build := func(endpoint *config.Endpoint) (Client, error) {
+		return NewFactory(nil)(NewBuildContext(&config.Gateway{}), endpoint)
+	}
+	endpoint := &config.Endpoint{
+		Path:                "/",
+		Protocol:            config.Protocol_HTTP,
+		Backends:            []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+		TransportDecorators: []string{"test-dial"},
+	}
+	// the decorators are applied again to a fresh transport by every build
+	for i := 1; i <= 2; i++ {
+		c, err := build(endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		c.Close()
+		if applied.Load() != int64(i) || dials.Load() != int64(i) {
+			t.Fatalf("want the decorated transport dialing but got %d applied and %d dials", applied.Load(), dials.Load())
+		}
+	}
+	// the shared transport is not decorated
+	resp, err := _globalClient.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if dials.Load() != 2 {
+		t.Fatalf("want the global transport not decorated but got %d dials", dials.Load())
+	}
+
+	for _, names := range [][]string{{"missing"}, {"test-dial", "test-fail"}} {
+		endpoint.TransportDecorators = names
+		if _, err := build(endpoint); err == nil {
+			t.Fatalf("want the build failed by %v", names)
+		}
+	}
+	endpoint.UpstreamProtocol = config.UpstreamProtocol_H2C
+	endpoint.TransportDecorators = []string{"test-dial"}
+	if _, err := build(endpoint); err == nil {
+		t.Fatal("want the decorators rejected by the HTTP/2 transport")
+	}
+}
@@ -11,12 +11,10 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/aide-family/magicbox/log"
-	"github.com/aide-family/magicbox/log/stdio"
 	klog "github.com/go-kratos/kratos/v2/log"
 	"github.com/spf13/cobra"
 
-	"github.com/aide-family/goddess/pkg/merr"
+	"github.com/aide-family/goddess/logs"
 )
 
 // Command groups for organized help display
@@ -41,16 +39,7 @@ func NewCmd() *cobra.Command {
 			cmd.Help()
 		},
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			logger, err := log.NewLogger(stdio.LoggerDriver())
-			if err != nil {
-				panic(merr.ErrorInternal("new logger failed with error: %v", err).WithCause(err))
-			}
-			logger = klog.With(logger,
-				"ts", klog.DefaultTimestamp,
-			)
-			filterLogger := klog.NewFilter(logger, klog.FilterLevel(klog.ParseLevel(globalFlags.LogLevel)))
-			helper := klog.NewHelper(filterLogger)
-			klog.SetLogger(helper.Logger())
+			klog.SetLogger(logs.NewGlobalLogger(os.Stdout, globalFlags.LogFormat, klog.ParseLevel(globalFlags.LogLevel)))
 		},
 	}
 	globalFlags.addFlags(rootCmd)
@@ -0,0 +1,84 @@
+// Package config is the config command for the gateway config files
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-family/goddess/cmd"
+	goddessconfig "github.com/aide-family/goddess/config"
+)
+
+type Flags struct {
+	from string
+	to   string
+	in   string
+	out  string
+}
+
+var flags Flags
+
+func NewCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage the gateway config files",
+		Long:  "manage the gateway config files, eg: migrate the config files between the schema versions",
+		Annotations: map[string]string{
+			"group": cmd.BasicCommands,
+		},
+		Run: func(c *cobra.Command, _ []string) {
+			c.Help()
+		},
+	}
+	configCmd.AddCommand(newMigrateCmd())
+	return configCmd
+}
+
+func newMigrateCmd() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "migrate the gateway config between the schema versions",
+		Long: "apply the registered migrations of the schema versions to the gateway config, report the changes and the fields " +
+			"to migrate by hand, and check the migrated config the same as `gateway check`",
+		Run: migrate,
+	}
+	migrateCmd.Flags().StringVar(&flags.from, "from", "v1", "the schema version of the input config")
+	migrateCmd.Flags().StringVar(&flags.to, "to", goddessconfig.LatestSchemaVersion(), "the schema version of the output config")
+	migrateCmd.Flags().StringVar(&flags.in, "in", "", "the input config path, eg: --in config.yaml")
+	migrateCmd.Flags().StringVar(&flags.out, "out", "", "the output config path, the migrated config is printed if not set")
+	migrateCmd.MarkFlagRequired("in")
+	return migrateCmd
+}
+
+func migrate(_ *cobra.Command, _ []string) {
+	data, err := os.ReadFile(flags.in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", flags.in, err)
+		os.Exit(1)
+	}
+	out, report, err := goddessconfig.Migrate(data, flags.from, flags.to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to migrate %s: %v\n", flags.in, err)
+		os.Exit(1)
+	}
+	if flags.out == "" {
+		os.Stdout.Write(out)
+	} else if err := os.WriteFile(flags.out, out, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", flags.out, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "migrated %s from %s to %s: %d changes, %d to migrate by hand\n",
+		flags.in, flags.from, flags.to, len(report.Changes), len(report.Manual))
+	for _, n := range report.Changes {
+		fmt.Fprintf(os.Stderr, "  changed %s\n", n)
+	}
+	for _, n := range report.Manual {
+		fmt.Fprintf(os.Stderr, "  manual  %s\n", n)
+	}
+	if len(report.Errors) > 0 {
+		fmt.Fprintf(os.Stderr, "the migrated config is invalid:\n%v\n", report.Errors)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,97 @@
+// Package control is the command for editing the gateway configs through the control service.
+package control
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-family/goddess/tlsreload"
+)
+
+func NewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "control",
+		Short: "edit the gateway configs through the control service",
+		Long:  "edit the configs and the features of the gateways through the write API of the control service",
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Help()
+		},
+	}
+	flags.addFlags(cmd)
+	cmd.AddCommand(newPatchCmd())
+	return cmd
+}
+
+// newClient returns the client of the control service of the flags.
+func newClient() (*client, error) {
+	if flags.service == "" {
+		return nil, fmt.Errorf("the control service is required, set -ctrl.service")
+	}
+	token := flags.token
+	if flags.tokenFile != "" {
+		b, err := os.ReadFile(flags.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read control service token: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	httpClient := http.DefaultClient
+	tlsConfig, err := tlsreload.ClientConfig(tlsreload.Config{CertFile: flags.tlsCert, KeyFile: flags.tlsKey, CAFile: flags.tlsCA})
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		httpClient = &http.Client{Transport: transport}
+	}
+	return &client{service: strings.TrimSuffix(flags.service, "/"), token: token, client: httpClient}, nil
+}
+
+// client calls the write API of the control service.
+type client struct {
+	service string
+	token   string
+	client  *http.Client
+}
+
+func (c *client) do(method, path string, header http.Header, body []byte) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, c.service+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, b, nil
+}
+
+// statusError returns the error of the unexpected response, the 401 and the 403 are told apart as the gateway does.
+func statusError(resp *http.Response, body []byte) error {
+	msg := strings.TrimSpace(string(body))
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Errorf("control service rejected the token: %s", msg)
+	case http.StatusForbidden:
+		return fmt.Errorf("the token is not scoped to write the gateway: %s", msg)
+	}
+	return fmt.Errorf("invalid status code: %d: %s", resp.StatusCode, msg)
+}
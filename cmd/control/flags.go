@@ -0,0 +1,51 @@
+package control
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type Flags struct {
+	service   string
+	token     string
+	tokenFile string
+	tlsCert   string
+	tlsKey    string
+	tlsCA     string
+
+	metricsPath       string
+	metricsGatewayTTL time.Duration
+
+	gateway   string
+	patch     string
+	features  bool
+	jsonPatch bool
+	ifMatch   string
+	retries   int
+}
+
+var flags Flags
+
+func (f *Flags) addFlags(c *cobra.Command) {
+	c.PersistentFlags().StringVar(&f.service, "ctrl.service", os.Getenv("CTRL_SERVICE"), "control service host, eg: http://127.0.0.1:8000")
+	c.PersistentFlags().StringVar(&f.token, "ctrl.token", os.Getenv("CTRL_TOKEN"), "control service bearer token write-scoped to the gateway")
+	c.PersistentFlags().StringVar(&f.tokenFile, "ctrl.token-file", "", "control service bearer token file, takes precedence over -ctrl.token")
+	c.PersistentFlags().StringVar(&f.tlsCert, "ctrl.tls-cert", "", "control service client certificate file")
+	c.PersistentFlags().StringVar(&f.tlsKey, "ctrl.tls-key", "", "control service client key file")
+	c.PersistentFlags().StringVar(&f.tlsCA, "ctrl.tls-ca", "", "control service CA file, the system roots if empty")
+	c.PersistentFlags().StringVar(&f.metricsPath, "metrics.path", "/metrics", "the path the metrics of the control service are served at, see Metrics.Mount")
+	c.PersistentFlags().DurationVar(&f.metricsGatewayTTL, "metrics.gateway-ttl", 5*time.Minute, "the gateways are known to the control service until the ttl after their last request")
+}
+
+func (f *Flags) addPatchFlags(c *cobra.Command) {
+	c.Flags().StringVar(&f.gateway, "gateway", "", "the name of the gateway to patch")
+	c.Flags().StringVar(&f.patch, "patch", "", "the patch file, - for stdin")
+	c.Flags().BoolVar(&f.features, "features", false, "patch the features of the gateway rather than its config")
+	c.Flags().BoolVar(&f.jsonPatch, "json-patch", false, "the patch is a RFC 6902 JSON Patch rather than a RFC 7386 JSON Merge Patch")
+	c.Flags().StringVar(&f.ifMatch, "if-match", "", "the ETag the patch is based on, the patch fails on a conflict rather than being retried; the current ETag if empty")
+	c.Flags().IntVar(&f.retries, "retries", 3, "the retries of the patch on a conflict with a concurrent edit, applied to the new current version")
+	_ = c.MarkFlagRequired("gateway")
+	_ = c.MarkFlagRequired("patch")
+}
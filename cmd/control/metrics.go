@@ -0,0 +1,211 @@
+package control
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/aide-family/goddess/cmd"
+)
+
+const (
+	// APIRelease is the api of the release requests of the gateways, /v1/control/gateway/release.
+	APIRelease = "release"
+	// APIFeatures is the api of the feature requests of the gateways, /v1/control/gateway/features.
+	APIFeatures = "features"
+
+	// StorageRead is the storage operation of reading the configs.
+	StorageRead = "read"
+	// StorageWrite is the storage operation of writing the configs, eg: releasing a patched version.
+	StorageWrite = "write"
+
+	// the class of the releases not modified since the last version of the gateway
+	_classNotModified   = "not_modified"
+	_defaultGatewayTTL  = 5 * time.Minute
+	_defaultMetricsPath = "/metrics"
+)
+
+// Metrics is the metrics of the control service: the release and feature requests of the gateways, the known
+// gateways, the storage and the webhook deliveries, with the Go and process collectors and the build info.
+//
+// The control service wraps its release and feature handlers with Instrument, times the storage with
+// ObserveStorage, reports the webhook deliveries with ObserveWebhook and mounts the registry at -metrics.path
+// with Mount, on its kratos HTTP server or on a separate admin address.
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	payloadBytes *prometheus.HistogramVec
+	storage      *prometheus.HistogramVec
+	webhooks     *prometheus.CounterVec
+
+	gatewayTTL time.Duration
+	now        func() time.Time
+	lock       sync.Mutex
+	// the last requests of the gateways
+	gateways map[string]time.Time
+}
+
+// NewMetrics creates the metrics of the control service and registers them into r, the gateways are known
+// until -metrics.gateway-ttl after their last request.
+func NewMetrics(r prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "control",
+			Name:      "requests_total",
+			Help:      "The total number of the release and feature requests of the gateways by the response class",
+		}, []string{"api", "gateway", "class"}),
+		payloadBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "go",
+			Subsystem: "control",
+			Name:      "payload_bytes",
+			Help:      "The size of the configs and the features returned to the gateways",
+			Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 8),
+		}, []string{"api"}),
+		storage: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "go",
+			Subsystem: "control",
+			Name:      "storage_duration_seconds",
+			Help:      "The latency of the storage operations by the operation and the result",
+			Buckets:   []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		}, []string{"op", "result"}),
+		webhooks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "control",
+			Name:      "webhook_deliveries_total",
+			Help:      "The total number of the webhook deliveries by the result",
+		}, []string{"result"}),
+		gatewayTTL: flags.metricsGatewayTTL,
+		now:        time.Now,
+		gateways:   map[string]time.Time{},
+	}
+	if m.gatewayTTL <= 0 {
+		m.gatewayTTL = _defaultGatewayTTL
+	}
+	knownGateways := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "control",
+		Name:      "known_gateways",
+		Help:      "The number of the gateways requested the control service within the gateway ttl",
+	}, func() float64 { return float64(m.knownGateways()) })
+	global := cmd.GetGlobalFlags()
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   "go",
+		Subsystem:   "control",
+		Name:        "build_info",
+		Help:        "The build information of the control service, the value is always 1",
+		ConstLabels: prometheus.Labels{"version": global.Version, "built": global.Built, "goversion": runtime.Version()},
+	})
+	buildInfo.Set(1)
+	for _, c := range []prometheus.Collector{
+		m.requests, m.payloadBytes, m.storage, m.webhooks, knownGateways, buildInfo,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	} {
+		if err := r.Register(c); err != nil {
+			// the Go and process collectors are registered into prometheus.DefaultRegisterer already
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// Instrument records the requests of the api by the gateway of the request and the class of the response,
+// eg: 2xx, not_modified, and the size of the 200 responses.
+func (m *Metrics) Instrument(api string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+		gateway := r.URL.Query().Get("gateway")
+		m.requests.WithLabelValues(api, gateway, responseClass(rw.status)).Inc()
+		if rw.status == http.StatusOK {
+			m.payloadBytes.WithLabelValues(api).Observe(float64(rw.bytes))
+		}
+		if gateway != "" {
+			m.lock.Lock()
+			m.gateways[gateway] = m.now()
+			m.lock.Unlock()
+		}
+	})
+}
+
+// ObserveStorage records the latency of the storage operation started at start, eg:
+// defer func() { m.ObserveStorage(control.StorageRead, start, err) }().
+func (m *Metrics) ObserveStorage(op string, start time.Time, err error) {
+	m.storage.WithLabelValues(op, result(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveWebhook records the outcome of a webhook delivery.
+func (m *Metrics) ObserveWebhook(err error) {
+	m.webhooks.WithLabelValues(result(err)).Inc()
+}
+
+// Mount serves the metrics of g at -metrics.path of mux, eg: the kratos HTTP server.
+func (m *Metrics) Mount(mux interface{ Handle(string, http.Handler) }, g prometheus.Gatherer) {
+	path := flags.metricsPath
+	if path == "" {
+		path = _defaultMetricsPath
+	}
+	mux.Handle(path, promhttp.HandlerFor(g, promhttp.HandlerOpts{}))
+}
+
+// knownGateways returns the number of the gateways requested within the ttl, the others are forgotten.
+func (m *Metrics) knownGateways() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	now := m.now()
+	for gateway, last := range m.gateways {
+		if now.Sub(last) > m.gatewayTTL {
+			delete(m.gateways, gateway)
+		}
+	}
+	return len(m.gateways)
+}
+
+func responseClass(status int) string {
+	if status == http.StatusNotModified {
+		return _classNotModified
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
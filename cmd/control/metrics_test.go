@@ -0,0 +1,88 @@
+package control
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m, err := NewMetrics(registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	m.now = func() time.Time { return now }
+
+	release := m.Instrument(APIRelease, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte(`{"name":"gw-a"}`))
+	}))
+	features := m.Instrument(APIFeatures, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	serve := func(h http.Handler, gateway string, notModified bool) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/control/gateway/release?gateway="+gateway, nil)
+		if notModified {
+			req.Header.Set("If-None-Match", `"v1"`)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve(release, "gw-a", false)
+	serve(release, "gw-a", true)
+	serve(features, "gw-b", false)
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues(APIRelease, "gw-a", "2xx")); got != 1 {
+		t.Fatalf("release 2xx: %v", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues(APIRelease, "gw-a", "not_modified")); got != 1 {
+		t.Fatalf("release not_modified: %v", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues(APIFeatures, "gw-b", "5xx")); got != 1 {
+		t.Fatalf("features 5xx: %v", got)
+	}
+	if got := testutil.CollectAndCount(m.payloadBytes); got != 1 {
+		t.Fatalf("only the 200 responses are sized: %d", got)
+	}
+	if got := m.knownGateways(); got != 2 {
+		t.Fatalf("known gateways: %d", got)
+	}
+	now = now.Add(m.gatewayTTL / 2)
+	serve(release, "gw-a", true)
+	now = now.Add(m.gatewayTTL/2 + time.Second)
+	if got := m.knownGateways(); got != 1 {
+		t.Fatalf("gw-b expired: %d", got)
+	}
+
+	m.ObserveStorage(StorageRead, time.Now(), nil)
+	m.ObserveStorage(StorageWrite, time.Now(), errors.New("conflict"))
+	m.ObserveWebhook(nil)
+	m.ObserveWebhook(errors.New("timeout"))
+	if got := testutil.CollectAndCount(m.storage); got != 2 {
+		t.Fatalf("storage: %d", got)
+	}
+	if got := testutil.ToFloat64(m.webhooks.WithLabelValues("failure")); got != 1 {
+		t.Fatalf("webhook failure: %v", got)
+	}
+
+	mux := http.NewServeMux()
+	m.Mount(mux, registry)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := w.Body.String()
+	for _, name := range []string{"go_control_build_info", "go_control_known_gateways 1", "go_goroutines", "process_"} {
+		if !strings.Contains(body, name) {
+			t.Fatalf("%s not exposed: %s", name, body)
+		}
+	}
+}
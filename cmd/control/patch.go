@@ -0,0 +1,115 @@
+package control
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-family/goddess/config"
+)
+
+func newPatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "patch",
+		Short: "patch the config or the features of a gateway",
+		Long: `patch the config or the features of a gateway by a RFC 7386 JSON Merge Patch or a RFC 6902 JSON Patch,
+the control service applies the patch to the current version and releases the validated result as a new version.
+The patch is based on the ETag of the current version, it is retried on the conflicts with the concurrent edits
+unless -if-match is set.`,
+		Example: `  goddess control patch --ctrl.service http://127.0.0.1:8000 --gateway my-gateway --patch timeout.json`,
+		Args:    cobra.NoArgs,
+		Run: func(*cobra.Command, []string) {
+			result, err := runPatch()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to patch %s: %v\n", flags.gateway, err)
+				os.Exit(1)
+			}
+			fmt.Printf("patched %s: version %s, etag %s\n", flags.gateway, result.Version, result.ETag)
+		},
+	}
+	flags.addPatchFlags(cmd)
+	return cmd
+}
+
+// errConflict is returned if the version the patch is based on is not the current version.
+var errConflict = errors.New("the config was changed by a concurrent edit")
+
+type patchResult struct {
+	Version string `json:"version"`
+	ETag    string `json:"-"`
+}
+
+func runPatch() (*patchResult, error) {
+	var (
+		body []byte
+		err  error
+	)
+	if flags.patch == "-" {
+		body, err = io.ReadAll(os.Stdin)
+	} else {
+		body, err = os.ReadFile(flags.patch)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	contentType := config.MergePatchContentType
+	if flags.jsonPatch {
+		contentType = config.JSONPatchContentType
+	}
+	resource := "config"
+	if flags.features {
+		resource = "features"
+	}
+	return c.patch(flags.gateway, resource, contentType, body, flags.ifMatch, flags.retries)
+}
+
+// patch sends the patch based on the ETag, the current ETag is fetched if empty. The conflicts are retried on
+// the current ETag returned with the 412 if the ETag was not given.
+func (c *client) patch(gateway, resource, contentType string, body []byte, etag string, retries int) (*patchResult, error) {
+	path := fmt.Sprintf("/v1/control/gateway/%s/%s", url.PathEscape(gateway), resource)
+	retry := etag == ""
+	if etag == "" {
+		resp, b, err := c.do(http.MethodGet, path, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, statusError(resp, b)
+		}
+		if etag = resp.Header.Get("ETag"); etag == "" {
+			return nil, fmt.Errorf("the control service returned no ETag of the %s", resource)
+		}
+	}
+	for attempt := 0; ; attempt++ {
+		resp, b, err := c.do(http.MethodPatch, path, http.Header{"Content-Type": {contentType}, "If-Match": {etag}}, body)
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			result := &patchResult{ETag: resp.Header.Get("ETag")}
+			if err := json.Unmarshal(b, result); err != nil {
+				return nil, fmt.Errorf("invalid response of the control service: %w", err)
+			}
+			return result, nil
+		case http.StatusPreconditionFailed:
+			current := resp.Header.Get("ETag")
+			if !retry || attempt >= retries || current == "" {
+				return nil, fmt.Errorf("%w: based on %s but the current is %s", errConflict, etag, current)
+			}
+			etag = current
+			continue
+		}
+		return nil, statusError(resp, b)
+	}
+}
@@ -0,0 +1,80 @@
+package control
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+)
+
+// newControlService serves the config of the gateway, the version is changed by a concurrent edit before the
+// first patch.
+func newControlService(t *testing.T) *httptest.Server {
+	doc := []byte(`{"name":"my-gateway","endpoints":[{"path":"/api","timeout":"1s"}]}`)
+	version := 1
+	etag := func() string { return `"v` + strconv.Itoa(version) + `"` }
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/control/gateway/my-gateway/config" || r.Header.Get("Authorization") != "Bearer write-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("ETag", etag())
+			w.Write(doc)
+		case http.MethodPatch:
+			if version == 1 {
+				// edited by another client
+				version++
+			}
+			if r.Header.Get("If-Match") != etag() {
+				w.Header().Set("ETag", etag())
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			patch, _ := io.ReadAll(r.Body)
+			patched, err := config.ApplyPatch(doc, patch, r.Header.Get("Content-Type"))
+			if err == nil {
+				err = config.CheckConfig(patched)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+				return
+			}
+			doc = patched
+			version++
+			w.Header().Set("ETag", etag())
+			w.Write([]byte(`{"version":"v` + strconv.Itoa(version) + `"}`))
+		}
+	}))
+}
+
+func TestPatch(t *testing.T) {
+	srv := newControlService(t)
+	defer srv.Close()
+	c := &client{service: srv.URL, token: "write-token", client: http.DefaultClient}
+	result, err := c.patch("my-gateway", "config", config.MergePatchContentType, []byte(`{"endpoints":[{"path":"/api","timeout":"5s"}]}`), "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != "v3" || result.ETag != `"v3"` {
+		t.Fatalf("want the patch retried on the current version but got %+v", result)
+	}
+
+	// the conflict is returned if the ETag is given
+	if _, err := c.patch("my-gateway", "config", config.MergePatchContentType, []byte(`{}`), `"v1"`, 3); !errors.Is(err, errConflict) {
+		t.Fatalf("want the conflict but got %v", err)
+	}
+	// the invalid result is rejected by the control service
+	if _, err := c.patch("my-gateway", "config", config.MergePatchContentType, []byte(`{"endpoints":[{"path":"/api","timeout":"soon"}]}`), "", 3); err == nil {
+		t.Fatal("want the invalid config rejected")
+	}
+	c.token = "read-token"
+	if _, err := c.patch("my-gateway", "config", config.MergePatchContentType, []byte(`{}`), "", 3); err == nil {
+		t.Fatal("want the read token rejected")
+	}
+}
@@ -0,0 +1,71 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-family/goddess/config"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func newCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "check the gateway config files",
+		Long:  "check the gateway config and the priority configs, report every invalid or unknown field with its line and column",
+		Run:   check,
+	}
+}
+
+func check(_ *cobra.Command, _ []string) {
+	failed := false
+	report := func(path string, err error) {
+		if err == nil {
+			fmt.Printf("%s: ok\n", path)
+			return
+		}
+		failed = true
+		fmt.Printf("%s:\n%v\n", path, err)
+	}
+	gateway := &configv1.Gateway{}
+	report(flags.proxyConfig, checkGateway(flags.proxyConfig, gateway))
+	if flags.priorityConfigDir != "" {
+		paths, err := filepath.Glob(filepath.Join(flags.priorityConfigDir, "*.yaml"))
+		if err != nil {
+			report(flags.priorityConfigDir, err)
+		}
+		for _, path := range paths {
+			report(path, checkPriorityConfig(path, gateway.Services))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// checkGateway validates the gateway config and the service references of its endpoints, out is the config decoded.
+func checkGateway(path string, out *configv1.Gateway) error {
+	if err := config.CheckFile(path, out); err != nil {
+		return err
+	}
+	if err := config.DecodeFile(path, out); err != nil {
+		return err
+	}
+	return config.ExpandServices(proto.Clone(out).(*configv1.Gateway))
+}
+
+// checkPriorityConfig validates the priority config and the references of its endpoints to the gateway services.
+func checkPriorityConfig(path string, services []*configv1.Service) error {
+	if err := config.CheckFile(path, &configv1.PriorityConfig{}); err != nil {
+		return err
+	}
+	pc := &configv1.PriorityConfig{}
+	if err := config.DecodeFile(path, pc); err != nil {
+		return err
+	}
+	return config.ExpandServices(&configv1.Gateway{Services: services, Endpoints: pc.Endpoints})
+}
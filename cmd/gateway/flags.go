@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -12,20 +13,140 @@ var flags Flags
 
 type Flags struct {
 	*cmd.GlobalFlags
-	ctrlName          string
-	ctrlService       string
-	proxyAddrs        []string
-	proxyConfig       string
-	priorityConfigDir string
-	withDebug         bool
+	ctrlName             string
+	ctrlService          string
+	ctrlSnapshotDir      string
+	ctrlToken            string
+	ctrlTokenFile        string
+	ctrlTLSCert          string
+	ctrlTLSKey           string
+	ctrlTLSCA            string
+	proxyAddrs           []string
+	proxyConfig          string
+	priorityConfigDir    string
+	withDebug            bool
+	tlsAddrs             []string
+	tlsCert              string
+	tlsKey               string
+	tlsClientCA          string
+	tlsClientAuth        string
+	http3                bool
+	http3IdleTimeout     time.Duration
+	http3MaxStreams      int64
+	acmeDomains          []string
+	acmeDirectory        string
+	acmeEmail            string
+	acmeCacheDir         string
+	auditFile            string
+	auditMaxSize         int64
+	auditMaxBackups      int
+	auditWebhook         string
+	logStreams           []string
+	grpcHealth           bool
+	readyPath            string
+	degradedStatus       int
+	loadBBRInflight      float64
+	loadBufferBudget     float64
+	loadGoroutines       int
+	loadProbeLatency     time.Duration
+	loadNotReadyScore    float64
+	waitForConfig        bool
+	waitForConfigTimeout time.Duration
+	k8sIngress           bool
+	k8sNamespace         string
+	k8sIngressClass      string
+	k8sBaseConfig        string
+	k8sClusterDomain     string
+	rollback             bool
+	rollbackWindow       time.Duration
+	rollbackThreshold    float64
+	rollbackMinErrorRate float64
+	rollbackMinRequests  int64
+	checkpointFile       string
+	checkpointInterval   time.Duration
+	checkpointCounters   []string
+	checkpointMaxSize    int64
+	checkpointMaxAge     time.Duration
+	upstreamConnMetrics  string
+	watchdogInterval     time.Duration
+	watchdogSoft         watchdogLimits
+	watchdogHard         watchdogLimits
+	watchdogShed         float64
+	watchdogNoOptional   bool
+	watchdogHardAction   string
+}
+
+type watchdogLimits struct {
+	goroutines int
+	heap       int64
+	fds        int
 }
 
 func (f *Flags) addFlags(c *cobra.Command) {
 	f.GlobalFlags = cmd.GetGlobalFlags()
 	c.PersistentFlags().StringVar(&f.ctrlName, "ctrl.name", os.Getenv("ADVERTISE_NAME"), "control gateway name, eg: gateway")
 	c.PersistentFlags().StringVar(&f.ctrlService, "ctrl.service", "", "control service host, eg: http://127.0.0.1:8000")
+	c.PersistentFlags().StringVar(&f.ctrlSnapshotDir, "ctrl.snapshot", "", "control service snapshot directory used to boot when control service is unavailable, eg: ./snapshot")
+	c.PersistentFlags().StringVar(&f.ctrlToken, "ctrl.token", os.Getenv("CTRL_TOKEN"), "control service bearer token read-scoped to the gateway name")
+	c.PersistentFlags().StringVar(&f.ctrlTokenFile, "ctrl.token-file", "", "control service bearer token file, read on each poll, takes precedence over -ctrl.token")
+	c.PersistentFlags().StringVar(&f.ctrlTLSCert, "ctrl.tls-cert", "", "control service client certificate file, re-read periodically so the rotated certificate is used without restarting")
+	c.PersistentFlags().StringVar(&f.ctrlTLSKey, "ctrl.tls-key", "", "control service client key file, re-read with -ctrl.tls-cert")
+	c.PersistentFlags().StringVar(&f.ctrlTLSCA, "ctrl.tls-ca", "", "control service CA file, the system roots if empty")
 	c.PersistentFlags().StringVar(&f.proxyConfig, "conf", "./cmd/gateway/config.yaml", "config path, eg: -conf config.yaml")
 	c.PersistentFlags().StringVar(&f.priorityConfigDir, "conf.priority", "", "priority config directory, eg: -conf.priority ./canary")
 	c.PersistentFlags().BoolVar(&f.withDebug, "debug", false, "enable debug handlers")
 	c.PersistentFlags().StringSliceVar(&f.proxyAddrs, "addr", []string{"0.0.0.0:8080"}, "proxy address, eg: -addr 0.0.0.0:8080")
+	c.PersistentFlags().StringSliceVar(&f.tlsAddrs, "tls.addr", nil, "tls proxy address, eg: -tls.addr 0.0.0.0:8443")
+	c.PersistentFlags().StringVar(&f.tlsCert, "tls.cert", "", "tls certificate file, used when acme is disabled")
+	c.PersistentFlags().StringVar(&f.tlsKey, "tls.key", "", "tls private key file, used when acme is disabled")
+	c.PersistentFlags().StringVar(&f.tlsClientCA, "tls.client-ca", "", "CA bundle file verifying the client certificates of the tls proxy, enables mTLS")
+	c.PersistentFlags().StringVar(&f.tlsClientAuth, "tls.client-auth", "request", "client certificate mode of mTLS, request verifies the certificate if sent, require rejects the connections without it")
+	c.PersistentFlags().BoolVar(&f.http3, "http3", false, "serve HTTP/3 over QUIC on the UDP ports of the tls proxy addresses, advertised by Alt-Svc")
+	c.PersistentFlags().DurationVar(&f.http3IdleTimeout, "http3.idle-timeout", 30*time.Second, "time the idle QUIC connections are closed after")
+	c.PersistentFlags().Int64Var(&f.http3MaxStreams, "http3.max-streams", 100, "max concurrent request streams of a QUIC connection")
+	c.PersistentFlags().StringSliceVar(&f.acmeDomains, "acme.domains", nil, "acme certificate domains, enable acme for the tls proxy, eg: -acme.domains api.example.com")
+	c.PersistentFlags().StringVar(&f.acmeDirectory, "acme.directory", "", "acme directory url, default is Let's Encrypt")
+	c.PersistentFlags().StringVar(&f.acmeEmail, "acme.email", "", "acme account contact email")
+	c.PersistentFlags().StringVar(&f.acmeCacheDir, "acme.cache", "./acme", "acme account key and certificates directory")
+	c.PersistentFlags().StringVar(&f.auditFile, "audit.file", "", "append-only audit log file of the config and admin actions, eg: -audit.file ./audit.jsonl")
+	c.PersistentFlags().Int64Var(&f.auditMaxSize, "audit.max-size", 100, "max size in megabytes of the audit log file before it is rotated")
+	c.PersistentFlags().IntVar(&f.auditMaxBackups, "audit.max-backups", 10, "max number of the rotated audit log files to keep")
+	c.PersistentFlags().StringVar(&f.auditWebhook, "audit.webhook", "", "webhook url receiving the audit records")
+	c.PersistentFlags().StringArrayVar(&f.logStreams, "log.stream", nil, "sink of the access, error, audit or debug log stream, the others are written to stdout, eg: -log.stream access:output=./access.log,format=json,level=info,max-size=100,max-backups=10")
+	c.PersistentFlags().BoolVar(&f.k8sIngress, "k8s.ingress", false, "translate the kubernetes ingresses into the config file, the in-cluster service account is used")
+	c.PersistentFlags().StringVar(&f.k8sNamespace, "k8s.namespace", "", "namespace of the watched ingresses and services, all the namespaces if empty")
+	c.PersistentFlags().StringVar(&f.k8sIngressClass, "k8s.ingress-class", "goddess", "ingress class of the translated ingresses, all the ingresses if empty")
+	c.PersistentFlags().StringVar(&f.k8sBaseConfig, "k8s.base", "", "config file the translated endpoints are appended to, eg: -k8s.base base.yaml")
+	c.PersistentFlags().StringVar(&f.k8sClusterDomain, "k8s.cluster-domain", "cluster.local", "cluster domain of the service DNS names")
+	c.PersistentFlags().BoolVar(&f.grpcHealth, "health.grpc", false, "serve grpc.health.v1.Health of the gateway itself on the proxy listeners instead of routing it to the upstreams")
+	c.PersistentFlags().StringVar(&f.readyPath, "health.ready-path", "", "path of the readiness of the gateway itself on the proxy listeners, eg: /readyz, disabled if empty")
+	c.PersistentFlags().IntVar(&f.degradedStatus, "health.degraded-status", 200, "status code of the readiness once degraded, 200 with the Degraded header or 429")
+	c.PersistentFlags().Float64Var(&f.loadBBRInflight, "health.bbr-inflight", 0.9, "ratio of the inflight requests to the bbr limit the gateway is degraded at, 0 ignores it")
+	c.PersistentFlags().Float64Var(&f.loadBufferBudget, "health.buffer-budget", 0.8, "ratio of the buffered request bytes to the budget the gateway is degraded at, 0 ignores it")
+	c.PersistentFlags().IntVar(&f.loadGoroutines, "health.goroutines", 50000, "number of the goroutines the gateway is degraded at, 0 ignores it")
+	c.PersistentFlags().DurationVar(&f.loadProbeLatency, "health.probe-latency", 100*time.Millisecond, "delay of the scheduling probe the gateway is degraded at, 0 ignores it")
+	c.PersistentFlags().Float64Var(&f.loadNotReadyScore, "health.not-ready-score", 0, "load score the gateway is not ready at, the score is the largest ratio of the signals to their thresholds, 0 never")
+	c.PersistentFlags().BoolVar(&f.waitForConfig, "wait-for-config", false, "start the listeners after the first config is applied, the requests are replied 503 until then otherwise")
+	c.PersistentFlags().BoolVar(&f.rollback, "rollback", false, "roll back to the previous config if the 5xx and upstream connect errors spike after a config update")
+	c.PersistentFlags().DurationVar(&f.rollbackWindow, "rollback.window", time.Minute, "time the updated config is observed, and the time of the baseline before the update")
+	c.PersistentFlags().Float64Var(&f.rollbackThreshold, "rollback.threshold", 2, "ratio of the error rate after the update to the baseline the config is rolled back over")
+	c.PersistentFlags().Float64Var(&f.rollbackMinErrorRate, "rollback.min-error-rate", 0.05, "error rate below which the config is never rolled back")
+	c.PersistentFlags().Int64Var(&f.rollbackMinRequests, "rollback.min-requests", 100, "number of the requests needed to judge the error rate")
+	c.PersistentFlags().StringVar(&f.checkpointFile, "metrics.checkpoint", "", "file the counters are checkpointed to and restored from on start, so they continue across the restarts, disabled if empty")
+	c.PersistentFlags().DurationVar(&f.checkpointInterval, "metrics.checkpoint-interval", time.Minute, "interval the counters are checkpointed at")
+	c.PersistentFlags().StringSliceVar(&f.checkpointCounters, "metrics.checkpoint-counters", nil, "names of the checkpointed counters, all the supported ones if empty, eg: -metrics.checkpoint-counters go_gateway_requests_code_total")
+	c.PersistentFlags().Int64Var(&f.checkpointMaxSize, "metrics.checkpoint-max-size", 8, "max size in megabytes of the checkpoint file, the checkpoint is not written over it")
+	c.PersistentFlags().DurationVar(&f.checkpointMaxAge, "metrics.checkpoint-max-age", 0, "max age of the checkpoint restored on start, unlimited if 0")
+	c.PersistentFlags().StringVar(&f.upstreamConnMetrics, "metrics.upstream-conn", "service", "labels of the upstream connection metrics: service, node adds the node address, off disables the tracing of the upstream connections")
+	c.PersistentFlags().DurationVar(&f.watchdogInterval, "watchdog.interval", 5*time.Second, "interval the watchdog samples the goroutines, the heap in use and the open file descriptors at")
+	c.PersistentFlags().IntVar(&f.watchdogSoft.goroutines, "watchdog.soft-goroutines", 0, "number of the goroutines the gateway enters the degraded mode at, 0 ignores it")
+	c.PersistentFlags().Int64Var(&f.watchdogSoft.heap, "watchdog.soft-heap", 0, "heap in use in megabytes the gateway enters the degraded mode at, 0 ignores it")
+	c.PersistentFlags().IntVar(&f.watchdogSoft.fds, "watchdog.soft-fds", 0, "number of the open file descriptors the gateway enters the degraded mode at, 0 ignores it")
+	c.PersistentFlags().IntVar(&f.watchdogHard.goroutines, "watchdog.hard-goroutines", 0, "number of the goroutines the hard action is taken at, 0 ignores it")
+	c.PersistentFlags().Int64Var(&f.watchdogHard.heap, "watchdog.hard-heap", 0, "heap in use in megabytes the hard action is taken at, 0 ignores it")
+	c.PersistentFlags().IntVar(&f.watchdogHard.fds, "watchdog.hard-fds", 0, "number of the open file descriptors the hard action is taken at, 0 ignores it")
+	c.PersistentFlags().Float64Var(&f.watchdogShed, "watchdog.shed-fraction", 0, "fraction of the requests replied 503 in the degraded mode")
+	c.PersistentFlags().BoolVar(&f.watchdogNoOptional, "watchdog.disable-optional", false, "disable the upstream body capture, the request capture and the stream recording in the degraded mode")
+	c.PersistentFlags().StringVar(&f.watchdogHardAction, "watchdog.hard-action", "none", "action at the hard limits: none logs only, exit stops gracefully and exits with 1, restart stops gracefully and executes the gateway again")
+	c.PersistentFlags().DurationVar(&f.waitForConfigTimeout, "wait-for-config.timeout", 30*time.Second, "max time waiting for the first config, the gateway exits if exceeded")
 }
@@ -3,21 +3,17 @@ package gateway
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
 
 	_ "net/http/pprof"
 
-	_ "github.com/aide-family/goddess/discovery/consul"
-	_ "github.com/aide-family/goddess/discovery/etcd"
-	_ "github.com/aide-family/goddess/middleware/bbr"
-	_ "github.com/aide-family/goddess/middleware/cors"
-	_ "github.com/aide-family/goddess/middleware/jwt"
-	_ "github.com/aide-family/goddess/middleware/logging"
-	_ "github.com/aide-family/goddess/middleware/namespace"
-	_ "github.com/aide-family/goddess/middleware/rewrite"
-	_ "github.com/aide-family/goddess/middleware/streamrecorder"
-	_ "github.com/aide-family/goddess/middleware/tracing"
-	_ "github.com/aide-family/goddess/middleware/transcoder"
 	_ "go.uber.org/automaxprocs"
 
 	"github.com/aide-family/magicbox/hello"
@@ -26,18 +22,26 @@ import (
 	"github.com/go-kratos/kratos/v2/transport"
 	"github.com/spf13/cobra"
 
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/checkpoint"
 	"github.com/aide-family/goddess/client"
 	"github.com/aide-family/goddess/cmd"
 	"github.com/aide-family/goddess/config"
 	configLoader "github.com/aide-family/goddess/config/config-loader"
-	"github.com/aide-family/goddess/discovery"
-	"github.com/aide-family/goddess/middleware"
-	"github.com/aide-family/goddess/middleware/circuitbreaker"
+	k8sloader "github.com/aide-family/goddess/config/k8s-loader"
+	"github.com/aide-family/goddess/gateway"
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/middleware/bbr"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/aide-family/goddess/proxy"
 	"github.com/aide-family/goddess/proxy/debug"
 	"github.com/aide-family/goddess/server"
+	"github.com/aide-family/goddess/tlsreload"
+	"github.com/aide-family/goddess/watchdog"
 )
 
+const _serviceHealthInterval = 5 * time.Second
+
 func NewCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "gateway",
@@ -46,17 +50,98 @@ func NewCmd() *cobra.Command {
 		Run:   run,
 	}
 	flags.addFlags(cmd)
+	cmd.AddCommand(newCheckCmd(), newPromoteCmd())
 	return cmd
 }
 
 func run(_ *cobra.Command, _ []string) {
 	ctx := context.Background()
+	closeLogs, err := logs.Setup(flags.logStreams)
+	if err != nil {
+		log.Fatalf("failed to setup log streams: %v", err)
+	}
+	defer closeLogs()
+	closeAudit, err := setupAudit()
+	if err != nil {
+		log.Fatalf("failed to setup audit log: %v", err)
+	}
+	defer closeAudit()
+	stopCheckpoint, err := setupCheckpoint()
+	if err != nil {
+		log.Fatalf("failed to setup counter checkpoint: %v", err)
+	}
+	defer stopCheckpoint()
+	connMetrics, err := client.ParseConnMetrics(flags.upstreamConnMetrics)
+	if err != nil {
+		log.Fatalf("failed to setup upstream connection metrics: %v", err)
+	}
+	client.SetConnMetrics(connMetrics)
+	// the servers are stopped by the hard action of the watchdog, the process exits or restarts once they are
+	var app *kratos.App
+	var hardLimited atomic.Bool
+	hardAction, err := watchdog.ParseHardAction(flags.watchdogHardAction)
+	if err != nil {
+		log.Fatalf("failed to setup watchdog: %v", err)
+	}
+	wd, err := watchdog.New(watchdog.Config{
+		Interval:        flags.watchdogInterval,
+		Soft:            flags.watchdogSoft.limits(),
+		Hard:            flags.watchdogHard.limits(),
+		ShedFraction:    flags.watchdogShed,
+		DisableOptional: flags.watchdogNoOptional,
+		OnHardLimit: func(watchdog.Readings) {
+			if hardAction == watchdog.HardActionNone || hardLimited.Swap(true) {
+				return
+			}
+			if err := app.Stop(); err != nil {
+				log.Errorf("failed to stop the servers at the watchdog hard limits: %v", err)
+			}
+		},
+	})
+	if err != nil {
+		log.Fatalf("failed to setup watchdog: %v", err)
+	}
+	if flags.degradedStatus != http.StatusOK && flags.degradedStatus != http.StatusTooManyRequests {
+		log.Fatalf("invalid --health.degraded-status %d, must be 200 or 429", flags.degradedStatus)
+	}
+	health := server.NewHealth(
+		server.WithGRPC(flags.grpcHealth),
+		server.WithReadyPath(flags.readyPath),
+		server.WithDegradedStatus(flags.degradedStatus),
+	)
+	// the proxy is sampled once the monitor runs after it is created
+	var p *proxy.Proxy
+	loadMonitor := server.NewLoadMonitor(server.LoadThresholds{
+		BBRInflight:   flags.loadBBRInflight,
+		BufferBudget:  flags.loadBufferBudget,
+		Goroutines:    flags.loadGoroutines,
+		ProbeLatency:  flags.loadProbeLatency,
+		NotReadyScore: flags.loadNotReadyScore,
+	}, func(s *server.LoadSignals) {
+		s.BBRInflight = bbr.Pressure()
+		s.BufferBudget = p.BufferBudgetUsage()
+	}, health.SetLoad)
 	var ctrlLoader *configLoader.CtrlConfigLoader
 	if flags.ctrlService != "" {
 		log.Infof("setup control service to: %q", flags.ctrlService)
-		ctrlLoader = configLoader.New(flags.ctrlName, flags.ctrlService, flags.proxyConfig, flags.priorityConfigDir)
+		ctrlTLS, err := tlsreload.ClientConfig(tlsreload.Config{CertFile: flags.ctrlTLSCert, KeyFile: flags.ctrlTLSKey, CAFile: flags.ctrlTLSCA})
+		if err != nil {
+			log.Fatalf("failed to load the control service TLS config: %v", err)
+		}
+		ctrlLoader = configLoader.New(flags.ctrlName, flags.ctrlService, flags.proxyConfig, flags.priorityConfigDir,
+			configLoader.WithSnapshotDir(flags.ctrlSnapshotDir),
+			configLoader.WithToken(flags.ctrlToken),
+			configLoader.WithTokenFile(flags.ctrlTokenFile),
+			configLoader.WithTLS(ctrlTLS),
+			configLoader.WithLoadReport(func() (float64, string) {
+				report := loadMonitor.Report()
+				return report.Score, report.State.String()
+			}))
 		if err := ctrlLoader.Load(ctx); err != nil {
-			log.Errorf("failed to do initial load from control service: %v, using local config instead", err)
+			log.Errorf("failed to do initial load from control service: %v", err)
+			if err := ctrlLoader.LoadSnapshot(); err != nil {
+				log.Errorf("failed to boot from snapshot: %v, using local config instead", err)
+			}
 		}
 		if err := ctrlLoader.LoadFeatures(ctx); err != nil {
 			log.Errorf("failed to do initial feature load from control service: %v, using default value instead", err)
@@ -64,67 +149,150 @@ func run(_ *cobra.Command, _ []string) {
 		go ctrlLoader.Run(ctx)
 	}
 
+	var k8sLoader *k8sloader.K8sConfigLoader
+	if flags.k8sIngress {
+		if ctrlLoader != nil {
+			log.Fatalf("the control service and the kubernetes ingresses cannot be used together")
+		}
+		k8sLoader, err = k8sloader.New(flags.proxyConfig,
+			k8sloader.WithNamespace(flags.k8sNamespace),
+			k8sloader.WithIngressClass(flags.k8sIngressClass),
+			k8sloader.WithBaseConfig(flags.k8sBaseConfig),
+			k8sloader.WithClusterDomain(flags.k8sClusterDomain))
+		if err != nil {
+			log.Fatalf("failed to create kubernetes config loader: %v", err)
+		}
+		if err := k8sLoader.Load(ctx); err != nil {
+			log.Errorf("failed to do initial load from kubernetes: %v, using local config instead", err)
+		}
+		go k8sLoader.Run(ctx)
+		defer k8sLoader.Close()
+	}
+
 	confLoader, err := config.NewFileLoader(flags.proxyConfig, flags.priorityConfigDir)
 	if err != nil {
 		log.Fatalf("failed to create config file loader: %v", err)
 	}
-	defer confLoader.Close()
 	bc, err := confLoader.Load(context.Background())
 	if err != nil {
 		log.Fatalf("failed to load config: %v", err)
 	}
 
-	discovery, err := discovery.Create(bc.Discovery)
-	if err != nil {
-		log.Fatalf("failed to create discovery: %v, using default discovery instead", err)
+	var proxyOpts []proxy.Option
+	if flags.rollback {
+		proxyOpts = append(proxyOpts, proxy.WithRollback(proxy.RollbackConfig{
+			Window:       flags.rollbackWindow,
+			Threshold:    flags.rollbackThreshold,
+			MinErrorRate: flags.rollbackMinErrorRate,
+			MinRequests:  flags.rollbackMinRequests,
+			OnRollback: func(bad *configv1.Gateway) {
+				config.MarkBad(bad)
+				if err := audit.Write(&audit.Record{Actor: audit.ActorSystem, Action: "config.rollback", Target: flags.proxyConfig, Before: bad.Version + "/" + gateway.ConfigHash(confLoader, bad)}); err != nil {
+					log.Errorf("failed to write audit record of config rollback: %v", err)
+				}
+			},
+		}))
 	}
-	clientFactory := client.NewFactory(discovery)
-	p, err := proxy.New(clientFactory, middleware.Create)
+	applied := ""
+	g, err := gateway.New(ctx, gateway.Config{
+		Gateway:      bc,
+		Loader:       confLoader,
+		ReloadSource: config.ReloadSourceFile,
+		ProxyOptions: proxyOpts,
+		// the gateway replies 503 until a valid config is reloaded, it exits on the invalid config otherwise
+		AllowInvalidConfig: flags.waitForConfig,
+		BeforeReload: func(bc *configv1.Gateway) error {
+			next := bc.Version + "/" + gateway.ConfigHash(confLoader, bc)
+			if err := audit.Write(&audit.Record{Actor: audit.ActorSystem, Action: "config.reload", Target: flags.proxyConfig, Before: applied, After: next}); err != nil {
+				log.Errorf("failed to write audit record of config reload: %v", err)
+				return err
+			}
+			return nil
+		},
+		AfterReload: func(bc *configv1.Gateway) {
+			applied = bc.Version + "/" + gateway.ConfigHash(confLoader, bc)
+			if ctrlLoader != nil {
+				ctrlLoader.ReportApplied(bc.Version, confLoader.MainHash(bc))
+			}
+			health.SetReady(true)
+		},
+	})
 	if err != nil {
-		log.Fatalf("failed to new proxy: %v", err)
-	}
-
-	buildContext := client.NewBuildContext(bc)
-	circuitbreaker.Init(buildContext, clientFactory)
-	if err := p.Update(buildContext, bc); err != nil {
-		log.Fatalf("failed to update service config: %v", err)
+		log.Fatalf("failed to create gateway: %v", err)
 	}
-	reloader := func() error {
-		bc, err := confLoader.Load(context.Background())
-		if err != nil {
-			log.Errorf("failed to load config: %v", err)
-			return err
-		}
-		buildContext := client.NewBuildContext(bc)
-		circuitbreaker.SetBuildContext(buildContext)
-		if err := p.Update(buildContext, bc); err != nil {
-			log.Errorf("failed to update service config: %v", err)
-			return err
+	p = g.Proxy()
+	if p.Configured() {
+		applied = bc.Version + "/" + gateway.ConfigHash(confLoader, bc)
+		if ctrlLoader != nil {
+			ctrlLoader.ReportApplied(bc.Version, confLoader.MainHash(bc))
 		}
-		log.Infof("config reloaded")
-		return nil
 	}
-	confLoader.Watch(reloader)
 
-	var serverHandler http.Handler = p
+	// only the proxied requests are shed, the debug and the health requests are kept
+	var serverHandler http.Handler = wd.Handler(g)
 	if flags.withDebug {
-		debug.Register("proxy", p)
 		debug.Register("config", confLoader)
 		if ctrlLoader != nil {
 			debug.Register("ctrl", ctrlLoader)
 		}
-		serverHandler = debug.MashupWithDebugHandler(p)
+		if k8sLoader != nil {
+			debug.Register("k8s", k8sLoader)
+		}
+		serverHandler = g.DebugMashup(serverHandler)
 	}
-	servers := make([]transport.Server, 0, len(flags.proxyAddrs))
+	healthCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go loadMonitor.Run(healthCtx)
+	if flags.grpcHealth || flags.readyPath != "" {
+		// ready once the first config is applied, the gateway keeps serving with the last config if the reload fails
+		health.SetReady(p.Configured())
+		go health.WatchServices(healthCtx, _serviceHealthInterval, p.ServiceHealth)
+		serverHandler = health.Handler(serverHandler)
+	}
+	tlsConfig, plaintextHandler, err := setupTLS(serverHandler)
+	if err != nil {
+		// the plaintext proxies keep serving without the tls proxies.
+		log.Errorf("failed to setup tls proxy: %v", err)
+		plaintextHandler = serverHandler
+	}
+	servers := make([]transport.Server, 0, len(flags.proxyAddrs)+len(flags.tlsAddrs))
 	for _, addr := range flags.proxyAddrs {
-		servers = append(servers, server.NewProxy(serverHandler, addr))
+		servers = append(servers, server.NewProxy(plaintextHandler, addr))
+	}
+	if tlsConfig != nil {
+		for _, addr := range flags.tlsAddrs {
+			if !flags.http3 {
+				servers = append(servers, server.NewTLSProxy(serverHandler, addr, tlsConfig))
+				continue
+			}
+			// the QUIC listener shares the address and the tls config of the tls proxy
+			http3Proxy := server.NewHTTP3Proxy(serverHandler, addr, tlsConfig, &server.HTTP3Config{
+				IdleTimeout: flags.http3IdleTimeout,
+				MaxStreams:  flags.http3MaxStreams,
+			})
+			handler, err := http3Proxy.AltSvcHandler(serverHandler)
+			if err != nil {
+				log.Fatalf("invalid tls proxy address %s: %v", addr, err)
+			}
+			servers = append(servers, server.NewTLSProxy(handler, addr, tlsConfig), http3Proxy)
+		}
 	}
-	app := kratos.New(
+	servers = append(servers, g.Passthroughs()...)
+	app = kratos.New(
 		kratos.Name(bc.Name),
 		kratos.Context(ctx),
 		kratos.Server(
 			servers...,
 		),
+		kratos.BeforeStop(func(context.Context) error {
+			health.Shutdown()
+			return nil
+		}),
+		kratos.AfterStop(func(ctx context.Context) error {
+			// the listeners are stopped, the requests still in flight are cut off by the shutdown timeout
+			g.Shutdown(ctx)
+			return nil
+		}),
 	)
 	globalFlags := cmd.GetGlobalFlags()
 	envOpts := []hello.Option{
@@ -136,7 +304,146 @@ func run(_ *cobra.Command, _ []string) {
 	}
 	hello.SetEnvWithOption(envOpts...)
 	hello.Hello()
+	if flags.waitForConfig {
+		waitCtx, cancel := context.WithTimeout(ctx, flags.waitForConfigTimeout)
+		err := p.WaitConfigured(waitCtx)
+		cancel()
+		if err != nil {
+			log.Fatalf("the config is not applied within %s: %v", flags.waitForConfigTimeout, err)
+		}
+	}
+	// the app is set before the watchdog runs
+	go wd.Run(healthCtx)
 	if err := app.Run(); err != nil {
 		log.Errorf("failed to run servers: %v", err)
 	}
+	if hardLimited.Load() {
+		log.Errorf("the servers are stopped at the watchdog hard limits, %s", flags.watchdogHardAction)
+		// the deferred funcs are skipped by the exit and the exec
+		stopCheckpoint()
+		closeAudit()
+		closeLogs()
+		if hardAction == watchdog.HardActionRestart {
+			err := watchdog.Restart()
+			fmt.Fprintf(os.Stderr, "failed to restart the gateway: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func (l watchdogLimits) limits() watchdog.Limits {
+	return watchdog.Limits{Goroutines: l.goroutines, HeapInuse: uint64(max(l.heap, 0)) << 20, OpenFDs: l.fds}
+}
+
+// setupCheckpoint restores the counters from the checkpoint and checkpoints them until the returned func is called.
+func setupCheckpoint() (func(), error) {
+	if flags.checkpointFile == "" {
+		return func() {}, nil
+	}
+	cp, err := checkpoint.New(checkpoint.Config{
+		File:     flags.checkpointFile,
+		Interval: flags.checkpointInterval,
+		Counters: flags.checkpointCounters,
+		MaxBytes: flags.checkpointMaxSize << 20,
+		MaxAge:   flags.checkpointMaxAge,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := cp.Restore(); err != nil {
+		// the counters start from zero, the checkpoint is overwritten by the next snapshot
+		log.Errorf("failed to restore the counters from the checkpoint: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cp.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
+}
+
+// setupAudit sets the sinks of the audit records and returns the closer of the sinks.
+func setupAudit() (func(), error) {
+	var sinks []audit.Sink
+	var closers []io.Closer
+	if flags.auditFile != "" {
+		sink, err := audit.NewFileSink(flags.auditFile, flags.auditMaxSize<<20, flags.auditMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks, closers = append(sinks, sink), append(closers, sink)
+	}
+	if flags.auditWebhook != "" {
+		sink := audit.NewWebhookSink(flags.auditWebhook)
+		sinks, closers = append(sinks, sink), append(closers, sink)
+	}
+	audit.SetSinks(sinks...)
+	return func() {
+		audit.SetSinks()
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}, nil
+}
+
+// setupTLS returns the tls config of the tls proxies and the handler of the plaintext proxies,
+// the plaintext proxies serve the ACME HTTP-01 challenge if acme is enabled.
+func setupTLS(handler http.Handler) (*tls.Config, http.Handler, error) {
+	if len(flags.tlsAddrs) == 0 {
+		return nil, handler, nil
+	}
+	if len(flags.acmeDomains) > 0 {
+		acme, err := server.NewACME(&server.ACMEConfig{
+			Domains:      flags.acmeDomains,
+			DirectoryURL: flags.acmeDirectory,
+			Email:        flags.acmeEmail,
+			CacheDir:     flags.acmeCacheDir,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig := acme.TLSConfig()
+		if err := setupClientAuth(tlsConfig); err != nil {
+			return nil, nil, err
+		}
+		return tlsConfig, acme.HTTPHandler(handler), nil
+	}
+	cert, err := tls.LoadX509KeyPair(flags.tlsCert, flags.tlsKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if err := setupClientAuth(tlsConfig); err != nil {
+		return nil, nil, err
+	}
+	return tlsConfig, handler, nil
+}
+
+// setupClientAuth verifies the client certificates by the CA bundle of -tls.client-ca if set.
+func setupClientAuth(tlsConfig *tls.Config) error {
+	if flags.tlsClientCA == "" {
+		return nil
+	}
+	pem, err := os.ReadFile(flags.tlsClientCA)
+	if err != nil {
+		return err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificate found in %s", flags.tlsClientCA)
+	}
+	tlsConfig.ClientCAs = pool
+	switch flags.tlsClientAuth {
+	case "request":
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case "require":
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return fmt.Errorf("invalid -tls.client-auth %q, must be request or require", flags.tlsClientAuth)
+	}
+	return nil
 }
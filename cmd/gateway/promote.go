@@ -0,0 +1,29 @@
+package gateway
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-family/goddess/config"
+)
+
+func newPromoteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "promote <name>",
+		Short: "promote a priority config into the gateway config",
+		Long:  "merge the named priority config into the gateway config atomically and remove the priority config, the name is the file name without the .yaml extension or the name of the priority config",
+		Args:  cobra.ExactArgs(1),
+		Run:   promote,
+	}
+}
+
+func promote(_ *cobra.Command, args []string) {
+	overlay, err := config.Promote(flags.proxyConfig, flags.priorityConfigDir, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to promote %s: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	fmt.Printf("promoted %s (%s) into %s: %d endpoints\n", overlay.File, overlay.Version, flags.proxyConfig, len(overlay.Endpoints))
+}
@@ -25,7 +25,7 @@ type GlobalFlags struct {
 
 func (g *GlobalFlags) addFlags(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(&g.Namespace, "namespace", "n", "moon", "The namespace of the service")
-	cmd.PersistentFlags().StringVar(&g.LogFormat, "log-format", "TEXT", "The format of the log")
+	cmd.PersistentFlags().StringVar(&g.LogFormat, "log-format", "TEXT", "The format of the log, TEXT or JSON")
 	cmd.PersistentFlags().StringVar(&g.LogLevel, "log-level", "DEBUG", "The level of the log")
 }
 
@@ -6,19 +6,25 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/go-kratos/feature"
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/features"
 	"github.com/go-kratos/kratos/v2/log"
-	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 	"golang.org/x/exp/rand"
 	"sigs.k8s.io/yaml"
@@ -26,7 +32,8 @@ import (
 
 var errNotModified = errors.New("config not modified")
 
-var priorityConfigFeature = feature.MustRegister("gw:PriorityConfig", false)
+// _tracer traces the polls of the control service with the tracer provider of the tracing middleware.
+var _tracer = otel.Tracer("gateway")
 
 type CtrlConfigLoader struct {
 	ctrlService          []string
@@ -39,25 +46,48 @@ type CtrlConfigLoader struct {
 	advertiseName string
 	advertiseAddr string
 
-	lastVersion         atomic.String
-	lastPriorityVersion atomic.Pointer[map[string]string]
+	token     string
+	tokenFile string
+	client    *http.Client
+
+	loadReport func() (score float64, state string)
+
+	lastVersion          atomic.String
+	lastPriorityVersion  atomic.Pointer[map[string]string]
+	lastFragmentsVersion atomic.String
+	// the config published by the control service and the config applied by the gateway
+	published atomic.Pointer[configPair]
+	applied   atomic.Pointer[configPair]
+
+	snapshotDir  string
+	snapshotLock sync.Mutex
+	snapshot     snapshotState
 }
 
 type LoadResponse struct {
 	Config          string                `json:"config"`
 	Version         string                `json:"version"`
 	PriorityConfigs []*PriorityConfigItem `json:"priorityConfigs"`
+	// Fragments are the option fragments referenced by the configs of the gateway, the release is served again
+	// once any of them changes even if the config version is the same.
+	Fragments []*config.Fragment `json:"fragments,omitempty"`
+	// Hash is the config.Hash of the config computed by the control service at publish time, the fragments
+	// resolved, it is compared with the hash of the applied config to detect the drift.
+	Hash string `json:"hash,omitempty"`
 }
 
 type PriorityConfigItem struct {
 	Key     string `json:"key"`
 	Config  string `json:"config"`
 	Version string `json:"version"`
+	// Rollout overrides the rollout of the priority config, eg: {"rampDuration":"3600s","hashKey":"header:X-User-Id"}.
+	Rollout json.RawMessage `json:"rollout,omitempty"`
 }
 
 type LoadFeatureResponse struct {
-	Gateway  string          `json:"gateway"`
-	Features map[string]bool `json:"features"`
+	Gateway string `json:"gateway"`
+	// Features is the values of the features, the values are validated by the declared types.
+	Features map[string]json.RawMessage `json:"features"`
 }
 
 func prepareCtrlService(in string) []string {
@@ -80,17 +110,29 @@ func prepareCtrlService(in string) []string {
 	return out
 }
 
-func New(name, rawCtrlService, dstPath, dstPriorityConfigDir string) *CtrlConfigLoader {
+func New(name, rawCtrlService, dstPath, dstPriorityConfigDir string, opts ...Option) *CtrlConfigLoader {
 	cl := &CtrlConfigLoader{
 		ctrlService:          prepareCtrlService(rawCtrlService),
 		dstPath:              dstPath,
 		dstPriorityConfigDir: dstPriorityConfigDir,
+		client:               http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(cl)
 	}
 	cl.advertiseName = name
 	cl.advertiseAddr = cl.getAdvertiseAddr()
 	return cl
 }
 
+// WithLoadReport reports the load score and state of the gateway with every poll of the release,
+// so the control service knows the overloaded instances.
+func WithLoadReport(report func() (score float64, state string)) Option {
+	return func(c *CtrlConfigLoader) {
+		c.loadReport = report
+	}
+}
+
 func (c *CtrlConfigLoader) choseCtrlService() string {
 	if c.nextCtrlService {
 		c.ctrlServiceIdx = (c.ctrlServiceIdx + 1) % len(c.ctrlService)
@@ -111,15 +153,22 @@ func (c *CtrlConfigLoader) urlfor(upath string, params url.Values) (string, erro
 }
 
 func (c *CtrlConfigLoader) Load(ctx context.Context) (err error) {
+	ctx, span := _tracer.Start(ctx, "ctrl load", trace.WithAttributes(
+		attribute.String("gateway.name", c.advertiseName),
+		attribute.String("config.last_version", c.lastVersion.Load()),
+	))
 	defer func() {
 		if err != nil {
 			c.nextCtrlService = true
+			config.ObserveReload(config.ReloadSourceCtrl, "", "", err)
 		}
+		endSpan(span, err)
 	}()
 
 	cfgBytes, err := c.load(ctx)
 	if err != nil {
 		if err == errNotModified {
+			span.SetAttributes(attribute.Bool("config.not_modified", true))
 			log.Infof("Skip loading config, %q-%q config is up to date: %q", c.advertiseName, c.advertiseAddr, c.lastVersion.String())
 			return nil
 		}
@@ -130,26 +179,84 @@ func (c *CtrlConfigLoader) Load(ctx context.Context) (err error) {
 	if err := json.Unmarshal(cfgBytes, &resp); err != nil {
 		return err
 	}
-
-	// write main config
-	yamlBytes, err := yaml.JSONToYAML([]byte(resp.Config))
+	span.SetAttributes(
+		attribute.String("config.version", resp.Version),
+		attribute.Bool("config.rolled_back", c.rolledBack(resp)),
+		attribute.Int("config.bytes", len(resp.Config)),
+		attribute.Int("config.priority_configs", len(resp.PriorityConfigs)),
+		attribute.Int("config.fragments", len(resp.Fragments)),
+	)
+	applied, err := c.applyRelease(resp)
 	if err != nil {
 		return err
 	}
-	tmpPath := fmt.Sprintf("%s.%s.tmp", c.dstPath, uuid.New().String())
-	if err := os.WriteFile(tmpPath, yamlBytes, 0o644); err != nil {
-		return err
+	c.snapshotLock.Lock()
+	changed := true
+	if applied {
+		c.snapshot.apply(cfgBytes, resp.Version)
+	} else {
+		changed = c.snapshot.rollBack(resp.Version)
 	}
-	if err := os.Rename(tmpPath, c.dstPath); err != nil {
-		return err
+	c.snapshotLock.Unlock()
+	if !changed {
+		return nil
+	}
+	if err := c.saveSnapshot(); err != nil {
+		log.Warnf("Failed to save snapshot, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
+	}
+	return nil
+}
+
+// rolledBack reports whether the release is the one rolled back by the gateway, the bad version resolved with
+// other fragments is another config.
+func (c *CtrlConfigLoader) rolledBack(resp *LoadResponse) bool {
+	if !config.IsBadVersion(resp.Version) {
+		return false
+	}
+	published := c.published.Load()
+	return published == nil || published.version != resp.Version || published.fragments == config.FragmentsVersion(resp.Fragments)
+}
+
+// applyRelease writes the configs of the release, applied is false if the release is skipped since it was rolled
+// back by the gateway.
+func (c *CtrlConfigLoader) applyRelease(resp *LoadResponse) (applied bool, err error) {
+	if c.rolledBack(resp) {
+		// the version was rolled back by the gateway, wait for a newer one
+		log.Warnf("Skip applying config version %q rolled back by the gateway, %q-%q", resp.Version, c.advertiseName, c.advertiseAddr)
+		c.lastVersion.Store(resp.Version)
+		return false, nil
+	}
+	// the references of the fragments are resolved before anything is written, the release is rejected as a whole
+	configJSON, err := config.ResolveFragments([]byte(resp.Config), resp.Fragments)
+	if err != nil {
+		return false, fmt.Errorf("config version %q: %w", resp.Version, err)
+	}
+	// write main config
+	yamlBytes, err := yaml.JSONToYAML(configJSON)
+	if err != nil {
+		return false, err
 	}
+	if err := writeFileAtomic(c.dstPath, yamlBytes); err != nil {
+		return false, err
+	}
+	fragmentsVersion := config.FragmentsVersion(resp.Fragments)
+	fragmentsChanged := c.lastFragmentsVersion.Load() != fragmentsVersion
 	c.lastVersion.Store(resp.Version)
+	c.published.Store(&configPair{version: resp.Version, hash: resp.Hash, fragments: fragmentsVersion})
+	hash := resp.Hash
+	if hash == "" {
+		hash = config.HashJSON(configJSON)
+	}
+	config.ObserveReload(config.ReloadSourceCtrl, resp.Version, hash, nil)
 
 	// write priority configs
-	if err := c.writePriorityConfigs(resp); err != nil {
+	if err := c.writePriorityConfigs(resp, fragmentsChanged); err != nil {
+		// the priority configs are resolved with the new fragments again by the next release
 		log.Warnf("Failed to write priority configs, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
+		return true, nil
 	}
-	return nil
+	c.lastFragmentsVersion.Store(fragmentsVersion)
+	return true, nil
 }
 
 func (c *CtrlConfigLoader) cleanUpPriorityConfigs(versions map[string]string) {
@@ -176,13 +283,33 @@ func (c *CtrlConfigLoader) cleanUpPriorityConfigs(versions map[string]string) {
 	}
 }
 
-func (c *CtrlConfigLoader) writePriorityConfigs(resp *LoadResponse) error {
+func (c *CtrlConfigLoader) writePriorityConfigs(resp *LoadResponse, fragmentsChanged bool) error {
 	if c.dstPriorityConfigDir == "" {
 		return nil
 	}
 	versions := make(map[string]string, len(resp.PriorityConfigs))
+	var last map[string]string
+	if v := c.lastPriorityVersion.Load(); v != nil {
+		last = *v
+	}
 	for _, item := range resp.PriorityConfigs {
-		yamlBytes, err := yaml.JSONToYAML([]byte(item.Config))
+		dstName := path.Join(c.dstPriorityConfigDir, fmt.Sprintf("%s.yaml", item.Key))
+		// the unchanged priority configs are not rewritten, the ttl and the rollout start from the time they were written
+		if item.Version != "" && last[item.Key] == item.Version && !fragmentsChanged {
+			if _, err := os.Stat(dstName); err == nil {
+				versions[item.Key] = item.Version
+				continue
+			}
+		}
+		configJSON, err := config.ResolveFragments([]byte(item.Config), resp.Fragments)
+		if err != nil {
+			return fmt.Errorf("priority config %q: %w", item.Key, err)
+		}
+		configJSON, err = withRollout(configJSON, item.Rollout)
+		if err != nil {
+			return err
+		}
+		yamlBytes, err := yaml.JSONToYAML(configJSON)
 		if err != nil {
 			return err
 		}
@@ -190,7 +317,6 @@ func (c *CtrlConfigLoader) writePriorityConfigs(resp *LoadResponse) error {
 		if err := os.WriteFile(tmpPath, yamlBytes, 0o644); err != nil {
 			return err
 		}
-		dstName := path.Join(c.dstPriorityConfigDir, fmt.Sprintf("%s.yaml", item.Key))
 		if err := os.Rename(tmpPath, dstName); err != nil {
 			return err
 		}
@@ -201,8 +327,21 @@ func (c *CtrlConfigLoader) writePriorityConfigs(resp *LoadResponse) error {
 	return nil
 }
 
+// withRollout sets the rollout of the item into the priority config.
+func withRollout(configJSON []byte, rollout json.RawMessage) ([]byte, error) {
+	if len(rollout) == 0 {
+		return configJSON, nil
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(configJSON, &fields); err != nil {
+		return nil, err
+	}
+	fields["rollout"] = rollout
+	return json.Marshal(fields)
+}
+
 func (c *CtrlConfigLoader) encodeLastPriorityVersion(dst url.Values) {
-	if !priorityConfigFeature.Enabled() {
+	if !features.PriorityConfig.Enabled() {
 		return
 	}
 	dst.Set("supportPriorityConfig", "1")
@@ -216,7 +355,9 @@ func (c *CtrlConfigLoader) encodeLastPriorityVersion(dst url.Values) {
 	}
 }
 
-func (c *CtrlConfigLoader) LoadFeatures(ctx context.Context) error {
+func (c *CtrlConfigLoader) LoadFeatures(ctx context.Context) (err error) {
+	ctx, span := _tracer.Start(ctx, "ctrl load features", trace.WithAttributes(attribute.String("gateway.name", c.advertiseName)))
+	defer func() { endSpan(span, err) }()
 	featureBytes, err := c.loadFeatures(ctx)
 	if err != nil {
 		return err
@@ -225,8 +366,18 @@ func (c *CtrlConfigLoader) LoadFeatures(ctx context.Context) error {
 	if err := json.Unmarshal(featureBytes, &resp); err != nil {
 		return err
 	}
-	for featureName, enabled := range resp.Features {
-		feature.SetEnabled(featureName, enabled)
+	span.SetAttributes(attribute.Int("features.count", len(resp.Features)))
+	if err := features.Apply(resp.Features); err != nil {
+		return err
+	}
+	c.snapshotLock.Lock()
+	changed := string(c.snapshot.features) != string(featureBytes)
+	c.snapshot.features = featureBytes
+	c.snapshotLock.Unlock()
+	if changed {
+		if err := c.saveSnapshot(); err != nil {
+			log.Warnf("Failed to save snapshot, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
+		}
 	}
 	return nil
 }
@@ -294,7 +445,16 @@ func (c *CtrlConfigLoader) load(ctx context.Context) ([]byte, error) {
 	params.Set("gateway", c.advertiseName)
 	params.Set("ip_addr", c.advertiseAddr)
 	params.Set("last_version", c.lastVersion.Load())
+	if v := c.lastFragmentsVersion.Load(); v != "" {
+		params.Set("last_fragments_version", v)
+	}
 	c.encodeLastPriorityVersion(params)
+	c.encodeApplied(params)
+	if c.loadReport != nil {
+		score, state := c.loadReport()
+		params.Set("load_score", strconv.FormatFloat(score, 'f', 2, 64))
+		params.Set("load_state", state)
+	}
 	log.Infof("%s is requesting config from %s with params: %+v", c.advertiseName, c.ctrlService, params)
 	api, err := c.urlfor("/v1/control/gateway/release", params)
 	if err != nil {
@@ -304,23 +464,11 @@ func (c *CtrlConfigLoader) load(ctx context.Context) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("ctrl.service", req.URL.Scheme+"://"+req.URL.Host))
+	if err := c.authorize(req); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotModified {
-		return nil, errNotModified
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid status code: %d", resp.StatusCode)
-	}
-	out, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+	return c.do(req, _ctrlAPIRelease)
 }
 
 func (c *CtrlConfigLoader) loadFeatures(ctx context.Context) ([]byte, error) {
@@ -336,35 +484,21 @@ func (c *CtrlConfigLoader) loadFeatures(ctx context.Context) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("ctrl.service", req.URL.Scheme+"://"+req.URL.Host))
+	if err := c.authorize(req); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotModified {
-		return nil, errNotModified
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid status code: %d", resp.StatusCode)
-	}
-	out, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+	return c.do(req, _ctrlAPIFeatures)
 }
 
 func (c *CtrlConfigLoader) Run(ctx context.Context) {
 	ctx, cancel := context.WithCancel(ctx)
 	c.cancel = cancel
 	for {
-		if err := c.Load(ctx); err != nil {
+		if err := c.poll(ctx); err != nil {
 			log.Warnf("Failed to load config, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
 			continue
 		}
-		if err := c.LoadFeatures(ctx); err != nil {
-			log.Warnf("Failed to load gateway features, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
-		}
 		select {
 		case <-ctx.Done():
 			return
@@ -373,6 +507,27 @@ func (c *CtrlConfigLoader) Run(ctx context.Context) {
 	}
 }
 
+// poll loads the config and the features in a span, the error of loading the config is returned.
+func (c *CtrlConfigLoader) poll(ctx context.Context) (err error) {
+	ctx, span := _tracer.Start(ctx, "ctrl poll", trace.WithAttributes(attribute.String("gateway.name", c.advertiseName)))
+	defer func() { endSpan(span, err) }()
+	if err := c.Load(ctx); err != nil {
+		return err
+	}
+	if err := c.LoadFeatures(ctx); err != nil {
+		log.Warnf("Failed to load gateway features, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
+	}
+	return nil
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 type InspectCtrlConfigLoader struct {
 	CtrlService     []string `json:"ctrl_service"`
 	CtrlServiceIdx  int      `json:"ctrl_service_idx"`
@@ -401,6 +556,12 @@ func (c *CtrlConfigLoader) DebugHandler() http.Handler {
 			rw.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
+		if err := audit.WriteRequest(r, &audit.Record{Action: "ctrl.load", Target: c.dstPath}); err != nil {
+			log.Errorf("Failed to write audit record of control service load: %+v", err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			rw.Write([]byte("failed to write audit record"))
+			return
+		}
 		if err := c.Load(context.Background()); err != nil {
 			rw.WriteHeader(http.StatusInternalServerError)
 			rw.Write([]byte(err.Error()))
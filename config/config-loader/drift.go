@@ -0,0 +1,54 @@
+package ctrlloader
+
+import (
+	"net/url"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _metricConfigDrift = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "config_drift",
+	Help:      "1 if the applied config has the version but not the hash published by the control service",
+})
+
+func init() {
+	metrics.Register(_metricConfigDrift)
+}
+
+// configPair is the version and the hash of a config.
+type configPair struct {
+	version string
+	hash    string
+	// the version of the fragments the published config was resolved with
+	fragments string
+}
+
+// ReportApplied records the version and the hash of the config applied by the gateway, they are reported to the
+// control service with the next poll so it can flag the drift of the gateway. A drift is also detected here if
+// the version is the one published by the control service but the hash is not.
+func (c *CtrlConfigLoader) ReportApplied(version, hash string) {
+	c.applied.Store(&configPair{version: version, hash: hash})
+	published := c.published.Load()
+	if published == nil || published.hash == "" || published.version != version {
+		_metricConfigDrift.Set(0)
+		return
+	}
+	if published.hash != hash {
+		log.Warnf("Config drift of %q-%q, version %q is published with hash %s but applied with hash %s",
+			c.advertiseName, c.advertiseAddr, version, published.hash, hash)
+		_metricConfigDrift.Set(1)
+		return
+	}
+	_metricConfigDrift.Set(0)
+}
+
+func (c *CtrlConfigLoader) encodeApplied(params url.Values) {
+	if applied := c.applied.Load(); applied != nil {
+		params.Set("applied_version", applied.version)
+		params.Set("applied_hash", applied.hash)
+	}
+}
@@ -0,0 +1,51 @@
+package ctrlloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestConfigDrift(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"test"}`, Version: "v1", Hash: "published"})
+	}))
+	defer srv.Close()
+
+	c := New("test", srv.URL, filepath.Join(t.TempDir(), "config.yaml"), "")
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if query.Has("applied_version") {
+		t.Fatalf("want nothing applied reported but got %v", query)
+	}
+
+	c.ReportApplied("v1", "published")
+	if got := testutil.ToFloat64(_metricConfigDrift); got != 0 {
+		t.Fatalf("want no drift but got %v", got)
+	}
+	c.ReportApplied("v1", "other")
+	if got := testutil.ToFloat64(_metricConfigDrift); got != 1 {
+		t.Fatalf("want the drift detected but got %v", got)
+	}
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("applied_version") != "v1" || query.Get("applied_hash") != "other" {
+		t.Fatalf("want the applied config reported but got %v", query)
+	}
+
+	// another version is not a drift, eg: a local change
+	c.ReportApplied("v0", "other")
+	if got := testutil.ToFloat64(_metricConfigDrift); got != 0 {
+		t.Fatalf("want no drift of another version but got %v", got)
+	}
+}
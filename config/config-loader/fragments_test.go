@@ -0,0 +1,95 @@
+package ctrlloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestFragments(t *testing.T) {
+	dir := t.TempDir()
+	fragments := []*config.Fragment{{Name: "origins", Value: json.RawMessage(`[".example.com"]`), Version: "1"}}
+	var lastFragmentsVersion string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastFragmentsVersion = r.URL.Query().Get("last_fragments_version")
+		_ = json.NewEncoder(w).Encode(&LoadResponse{
+			Config:    `{"name":"test","middlewares":[{"name":"cors","options":{"allowOrigins":{"$ref":"fragments/origins"}}}]}`,
+			Version:   "v1",
+			Fragments: fragments,
+		})
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(dir, "config.yaml")
+	c := New("test", srv.URL, dst, "")
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), ".example.com") || strings.Contains(string(data), "$ref") {
+		t.Fatalf("want the reference resolved but got %s", data)
+	}
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lastFragmentsVersion != config.FragmentsVersion(fragments) {
+		t.Fatalf("want the version of the applied fragments sent but got %q", lastFragmentsVersion)
+	}
+
+	// the release of an unresolvable reference is rejected and the config is kept
+	fragments = nil
+	if err := c.Load(context.Background()); err == nil || !strings.Contains(err.Error(), `gateway middleware "cors"`) {
+		t.Fatalf("want the unresolved reference rejected but got %v", err)
+	}
+	if kept, _ := os.ReadFile(dst); string(kept) != string(data) {
+		t.Fatalf("want the config kept but got %s", kept)
+	}
+}
+
+func TestFragmentsRollback(t *testing.T) {
+	const version = "fragments-rollback-v1"
+	fragments := []*config.Fragment{{Name: "origins", Value: json.RawMessage(`[".example.com"]`), Version: "1"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&LoadResponse{
+			Config:    `{"name":"test","middlewares":[{"name":"cors","options":{"allowOrigins":{"$ref":"fragments/origins"}}}]}`,
+			Version:   version,
+			Fragments: fragments,
+		})
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "config.yaml")
+	c := New("test", srv.URL, dst, "")
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	config.MarkBad(&configv1.Gateway{Name: "test", Version: version})
+	if err := os.Remove(dst); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Fatalf("want the rolled back release skipped but got %v", err)
+	}
+	// the same version with the fixed fragments is another config
+	fragments = []*config.Fragment{{Name: "origins", Value: json.RawMessage(`[".example.org"]`), Version: "2"}}
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || !strings.Contains(string(data), ".example.org") {
+		t.Fatalf("want the release with the new fragments applied but got %s %v", data, err)
+	}
+}
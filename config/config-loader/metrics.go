@@ -0,0 +1,83 @@
+package ctrlloader
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_ctrlAPIRelease  = "release"
+	_ctrlAPIFeatures = "features"
+
+	// the class of the requests failed before the response, eg: the connection is refused
+	_ctrlClassError = "error"
+	// the class of the releases not modified since the last version
+	_ctrlClassNotModified = "not_modified"
+)
+
+var (
+	_metricCtrlRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "ctrl_requests_total",
+		Help:      "The total number of the requests to the control service by the api and the response class",
+	}, []string{"api", "class"})
+	_metricCtrlRequestSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "ctrl_request_duration_seconds",
+		Help:      "The duration of the requests to the control service, including reading the response body",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"api"})
+	_metricCtrlResponseBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "ctrl_response_bytes",
+		Help:      "The size of the payloads returned by the control service",
+		Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 8),
+	}, []string{"api"})
+)
+
+func init() {
+	metrics.Register(_metricCtrlRequests, _metricCtrlRequestSeconds, _metricCtrlResponseBytes)
+}
+
+// ctrlResponseClass returns the class of the response status, eg: 2xx, not_modified.
+func ctrlResponseClass(code int) string {
+	if code == http.StatusNotModified {
+		return _ctrlClassNotModified
+	}
+	return strconv.Itoa(code/100) + "xx"
+}
+
+// do sends the request of the api to the control service and returns the body of the 200 response, the
+// request is recorded into the metrics of the control service requests.
+func (c *CtrlConfigLoader) do(req *http.Request, api string) ([]byte, error) {
+	startAt := time.Now()
+	class := _ctrlClassError
+	defer func() {
+		_metricCtrlRequests.WithLabelValues(api, class).Inc()
+		_metricCtrlRequestSeconds.WithLabelValues(api).Observe(time.Since(startAt).Seconds())
+	}()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	class = ctrlResponseClass(resp.StatusCode)
+	if err := c.checkStatus(resp); err != nil {
+		return nil, err
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		class = _ctrlClassError
+		return nil, err
+	}
+	_metricCtrlResponseBytes.WithLabelValues(api).Observe(float64(len(out)))
+	return out, nil
+}
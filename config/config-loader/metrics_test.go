@@ -0,0 +1,115 @@
+package ctrlloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestCtrlMetrics(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/control/gateway/release":
+			_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"metrics"}`, Version: "v1"})
+		case "/v1/control/gateway/features":
+			_ = json.NewEncoder(w).Encode(&LoadFeatureResponse{Gateway: "test"})
+		}
+	}))
+	c := New("test", srv.URL, filepath.Join(t.TempDir(), "config.yaml"), "")
+	counter := func(api, class string) float64 {
+		return testutil.ToFloat64(_metricCtrlRequests.WithLabelValues(api, class))
+	}
+	before := map[string]float64{}
+	for _, class := range []string{"2xx", _ctrlClassNotModified, "5xx", _ctrlClassError} {
+		before[class] = counter(_ctrlAPIRelease, class)
+	}
+	beforeFeatures := counter(_ctrlAPIFeatures, "2xx")
+	count := func(h *prometheus.HistogramVec, api string) uint64 {
+		m := &dto.Metric{}
+		if err := h.WithLabelValues(api).(prometheus.Histogram).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		return m.GetHistogram().GetSampleCount()
+	}
+	sizes, durations := count(_metricCtrlResponseBytes, _ctrlAPIRelease), count(_metricCtrlRequestSeconds, _ctrlAPIRelease)
+
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the config info carries the same hash as the logs and the audit records
+	if got, want := configInfoHash(t, config.ReloadSourceCtrl), config.HashJSON([]byte(`{"name":"metrics"}`)); got != want {
+		t.Fatalf("want the config info hash %s but got %s", want, got)
+	}
+	if err := c.LoadFeatures(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusNotModified
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status = http.StatusInternalServerError
+	if err := c.Load(context.Background()); err == nil {
+		t.Fatal("want error of the 500 response")
+	}
+	srv.Close()
+	if err := c.Load(context.Background()); err == nil {
+		t.Fatal("want error when the control service is down")
+	}
+
+	for _, class := range []string{"2xx", _ctrlClassNotModified, "5xx", _ctrlClassError} {
+		if got := counter(_ctrlAPIRelease, class) - before[class]; got != 1 {
+			t.Fatalf("want one release request of class %s but got %v", class, got)
+		}
+	}
+	if got := counter(_ctrlAPIFeatures, "2xx") - beforeFeatures; got != 1 {
+		t.Fatalf("want one features request but got %v", got)
+	}
+	// the payload size is only observed for the 200 response, the duration for every request
+	if got := count(_metricCtrlResponseBytes, _ctrlAPIRelease) - sizes; got != 1 {
+		t.Fatalf("want one payload size of the release observed but got %d", got)
+	}
+	if got := count(_metricCtrlRequestSeconds, _ctrlAPIRelease) - durations; got != 4 {
+		t.Fatalf("want the durations of all the release requests observed but got %d", got)
+	}
+}
+
+func configInfoHash(t *testing.T, source string) string {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	if err := metrics.RegisterInto(registry); err != nil {
+		t.Fatal(err)
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "go_gateway_config_info" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["source"] == source {
+				return labels["hash"]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package ctrlloader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWritePriorityConfigs(t *testing.T) {
+	dir := t.TempDir()
+	c := New("test", "http://127.0.0.1:1", filepath.Join(dir, "config.yaml"), dir)
+	resp := &LoadResponse{PriorityConfigs: []*PriorityConfigItem{{Key: "canary", Config: `{"ttl":"1h"}`, Version: "1"}}}
+	if err := c.writePriorityConfigs(resp, false); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "canary.yaml")
+	written := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(name, written, written); err != nil {
+		t.Fatal(err)
+	}
+	modTime := func() time.Time {
+		t.Helper()
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.ModTime()
+	}
+
+	// the ttl and the rollout of the unchanged version keep counting from the time it was first written
+	if err := c.writePriorityConfigs(resp, false); err != nil {
+		t.Fatal(err)
+	}
+	if !modTime().Equal(written) {
+		t.Fatalf("want the unchanged priority config kept but it is written at %s", modTime())
+	}
+	// the new version and the changed fragments restart them
+	if err := c.writePriorityConfigs(resp, true); err != nil {
+		t.Fatal(err)
+	}
+	if modTime().Equal(written) {
+		t.Fatal("want the priority config rewritten for the changed fragments")
+	}
+}
@@ -0,0 +1,280 @@
+package ctrlloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/features"
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	snapshotManifest = "manifest.json"
+	snapshotRelease  = "release.json"
+	snapshotFeatures = "features.json"
+)
+
+var _metricSnapshotBootAge = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "ctrl_snapshot_boot_age_seconds",
+	Help:      "Age of the snapshot the gateway booted from, 0 if not booted from snapshot",
+})
+
+func init() {
+	metrics.Register(_metricSnapshotBootAge)
+}
+
+// Option is control config loader option.
+type Option func(*CtrlConfigLoader)
+
+// WithSnapshotDir sets the directory of the startup snapshot, snapshot is disabled if empty.
+func WithSnapshotDir(dir string) Option {
+	return func(c *CtrlConfigLoader) {
+		c.snapshotDir = dir
+	}
+}
+
+// SnapshotManifest describes a snapshot generation, it is replaced atomically on each write.
+type SnapshotManifest struct {
+	Generation string            `json:"generation"`
+	Version    string            `json:"version"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Files      map[string]string `json:"files"`
+	// RolledBack is the versions rolled back by the gateway, they are not applied again after the restart.
+	RolledBack []string `json:"rolledBack,omitempty"`
+	Digest     string   `json:"digest"`
+}
+
+func (m *SnapshotManifest) digest() string {
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%d\n", m.Generation, m.Version, m.CreatedAt.UnixNano())
+	for _, name := range names {
+		fmt.Fprintf(h, "%s=%s\n", name, m.Files[name])
+	}
+	// the manifests written before the rolled back versions are recorded keep their digests
+	if len(m.RolledBack) > 0 {
+		fmt.Fprintf(h, "rolledBack=%s\n", strings.Join(m.RolledBack, ","))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sha256hex(in []byte) string {
+	sum := sha256.Sum256(in)
+	return hex.EncodeToString(sum[:])
+}
+
+// snapshotState is the last applied release and features.
+type snapshotState struct {
+	release  []byte
+	version  string
+	features []byte
+	// the release applied before the current one, it replaces the current one once it is rolled back
+	previous        []byte
+	previousVersion string
+	rolledBack      []string
+}
+
+func (s *snapshotState) apply(release []byte, version string) {
+	if version != s.version {
+		s.previous, s.previousVersion = s.release, s.version
+	}
+	s.release, s.version = release, version
+	// the rolled back versions are older than the applied one
+	s.rolledBack = nil
+}
+
+// rollBack records the version rolled back by the gateway, the previous release is restored if the version is
+// the current one. It returns whether the state is changed.
+func (s *snapshotState) rollBack(version string) bool {
+	if slices.Contains(s.rolledBack, version) {
+		return false
+	}
+	s.rolledBack = append(s.rolledBack, version)
+	if s.version == version && s.previous != nil {
+		s.release, s.version = s.previous, s.previousVersion
+		s.previous, s.previousVersion = nil, ""
+	}
+	return true
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	tmpPath := fmt.Sprintf("%s.%s.tmp", name, uuid.New().String())
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, name); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// saveSnapshot writes the state into a new generation directory and commits it by replacing the manifest.
+func (c *CtrlConfigLoader) saveSnapshot() error {
+	if c.snapshotDir == "" {
+		return nil
+	}
+	c.snapshotLock.Lock()
+	defer c.snapshotLock.Unlock()
+	if c.snapshot.release == nil {
+		return nil
+	}
+	manifest := &SnapshotManifest{
+		Generation: uuid.New().String(),
+		Version:    c.snapshot.version,
+		CreatedAt:  time.Now(),
+		Files:      map[string]string{},
+		RolledBack: c.snapshot.rolledBack,
+	}
+	genDir := filepath.Join(c.snapshotDir, manifest.Generation)
+	if err := os.MkdirAll(genDir, 0o755); err != nil {
+		return err
+	}
+	files := map[string][]byte{snapshotRelease: c.snapshot.release}
+	if c.snapshot.features != nil {
+		files[snapshotFeatures] = c.snapshot.features
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(genDir, name), data, 0o644); err != nil {
+			_ = os.RemoveAll(genDir)
+			return err
+		}
+		manifest.Files[name] = sha256hex(data)
+	}
+	manifest.Digest = manifest.digest()
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		_ = os.RemoveAll(genDir)
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(c.snapshotDir, snapshotManifest), b); err != nil {
+		_ = os.RemoveAll(genDir)
+		return err
+	}
+	c.cleanUpSnapshots(manifest.Generation)
+	return nil
+}
+
+func (c *CtrlConfigLoader) cleanUpSnapshots(current string) {
+	entrys, err := os.ReadDir(c.snapshotDir)
+	if err != nil {
+		log.Warnf("Failed to read snapshot dir, %q-%q, %+v", c.advertiseName, c.advertiseAddr, err)
+		return
+	}
+	for _, e := range entrys {
+		if e.Name() == current || !isSnapshotEntry(e) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(c.snapshotDir, e.Name())); err != nil {
+			log.Warnf("Failed to remove expired snapshot %s, %q-%q, %+v", e.Name(), c.advertiseName, c.advertiseAddr, err)
+		}
+	}
+}
+
+// isSnapshotEntry reports whether the entry of the snapshot dir is written by saveSnapshot: a generation
+// directory or a temporary manifest, the other entries are kept even if the dir is shared.
+func isSnapshotEntry(e os.DirEntry) bool {
+	if e.IsDir() {
+		return isUUID(e.Name())
+	}
+	name, ok := strings.CutPrefix(e.Name(), snapshotManifest+".")
+	if !ok {
+		return false
+	}
+	name, ok = strings.CutSuffix(name, ".tmp")
+	return ok && isUUID(name)
+}
+
+func isUUID(s string) bool {
+	_, err := uuid.Parse(s)
+	return err == nil && len(s) == 36
+}
+
+func (c *CtrlConfigLoader) readSnapshot() (*SnapshotManifest, map[string][]byte, error) {
+	b, err := os.ReadFile(filepath.Join(c.snapshotDir, snapshotManifest))
+	if err != nil {
+		return nil, nil, err
+	}
+	manifest := &SnapshotManifest{}
+	if err := json.Unmarshal(b, manifest); err != nil {
+		return nil, nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+	if manifest.Generation == "" || manifest.Digest != manifest.digest() {
+		return nil, nil, errors.New("snapshot manifest digest mismatch")
+	}
+	if _, ok := manifest.Files[snapshotRelease]; !ok {
+		return nil, nil, errors.New("snapshot release is missing")
+	}
+	files := make(map[string][]byte, len(manifest.Files))
+	for name, sum := range manifest.Files {
+		data, err := os.ReadFile(filepath.Join(c.snapshotDir, manifest.Generation, name))
+		if err != nil {
+			return nil, nil, err
+		}
+		if sha256hex(data) != sum {
+			return nil, nil, fmt.Errorf("snapshot file %s checksum mismatch", name)
+		}
+		files[name] = data
+	}
+	return manifest, files, nil
+}
+
+// LoadSnapshot applies the last snapshot, it is used at startup when the control service is unavailable.
+func (c *CtrlConfigLoader) LoadSnapshot() error {
+	if c.snapshotDir == "" {
+		return errors.New("snapshot is disabled")
+	}
+	manifest, files, err := c.readSnapshot()
+	if err != nil {
+		return err
+	}
+	resp := &LoadResponse{}
+	if err := json.Unmarshal(files[snapshotRelease], resp); err != nil {
+		return err
+	}
+	for _, version := range manifest.RolledBack {
+		config.MarkBadVersion(version)
+	}
+	applied, err := c.applyRelease(resp)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return fmt.Errorf("snapshot release %q was rolled back by the gateway", resp.Version)
+	}
+	if data, ok := files[snapshotFeatures]; ok {
+		featureResp := &LoadFeatureResponse{}
+		if err := json.Unmarshal(data, featureResp); err != nil {
+			return err
+		}
+		if err := features.Apply(featureResp.Features); err != nil {
+			return err
+		}
+	}
+	c.snapshotLock.Lock()
+	c.snapshot = snapshotState{release: files[snapshotRelease], version: resp.Version, features: files[snapshotFeatures], rolledBack: manifest.RolledBack}
+	c.snapshotLock.Unlock()
+	age := time.Since(manifest.CreatedAt)
+	_metricSnapshotBootAge.Set(age.Seconds())
+	log.Warnf("Booted from snapshot, %q-%q, version: %q, generation: %s, age: %s", c.advertiseName, c.advertiseAddr, manifest.Version, manifest.Generation, age)
+	return nil
+}
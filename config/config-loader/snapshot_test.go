@@ -0,0 +1,152 @@
+package ctrlloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/control/gateway/release":
+			_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"snapshot"}`, Version: "v1"})
+		case "/v1/control/gateway/features":
+			_ = json.NewEncoder(w).Encode(&LoadFeatureResponse{Gateway: "test", Features: map[string]json.RawMessage{"gw:PriorityConfig": json.RawMessage("true")}})
+		}
+	}))
+	confPath := filepath.Join(dir, "config.yaml")
+	snapshotDir := filepath.Join(dir, "snapshot")
+	c := New("test", srv.URL, confPath, "", WithSnapshotDir(snapshotDir))
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the entries not written by the snapshot are kept by the cleanup
+	kept := []string{filepath.Join(snapshotDir, "backup"), filepath.Join(snapshotDir, "notes.tmp")}
+	if err := os.Mkdir(kept[0], 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kept[1], nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadFeatures(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	for _, name := range kept {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatalf("want %s kept but got %v", name, err)
+		}
+	}
+	entries, err := os.ReadDir(snapshotDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("want the last generation, the manifest and the kept entries but got %d entries", len(entries))
+	}
+
+	// boot with the control service down and the local config lost
+	if err := os.Remove(confPath); err != nil {
+		t.Fatal(err)
+	}
+	booted := New("test", srv.URL, confPath, "", WithSnapshotDir(snapshotDir))
+	if err := booted.Load(context.Background()); err == nil {
+		t.Fatal("want error when control service is down")
+	}
+	if err := booted.LoadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(confPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "name: snapshot\n" {
+		t.Fatalf("want config restored from snapshot but got: %q", b)
+	}
+	if booted.lastVersion.Load() != "v1" {
+		t.Fatalf("want version restored from snapshot but got: %q", booted.lastVersion.Load())
+	}
+
+	// corrupted snapshot must not be applied
+	manifest, _, err := booted.readSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := filepath.Join(snapshotDir, manifest.Generation, snapshotRelease)
+	if err := os.WriteFile(release, []byte(`{"config":"{}","version":"v2"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := booted.LoadSnapshot(); err == nil {
+		t.Fatal("want error for corrupted snapshot")
+	}
+}
+
+func TestSnapshotRollback(t *testing.T) {
+	dir := t.TempDir()
+	version := "snapshot-rollback-v1"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"` + version + `"}`, Version: version})
+	}))
+	confPath := filepath.Join(dir, "config.yaml")
+	snapshotDir := filepath.Join(dir, "snapshot")
+	c := New("test", srv.URL, confPath, "", WithSnapshotDir(snapshotDir))
+	for _, v := range []string{"snapshot-rollback-v1", "snapshot-rollback-v2"} {
+		version = v
+		if err := c.Load(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the gateway rolls back v2, the snapshot is restored to v1 once the release is served again
+	config.MarkBad(&configv1.Gateway{Name: "test", Version: version})
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	manifest, _, err := c.readSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != "snapshot-rollback-v1" || len(manifest.RolledBack) != 1 || manifest.RolledBack[0] != version {
+		t.Fatalf("want the snapshot of v1 with v2 rolled back but got %+v", manifest)
+	}
+	booted := New("test", srv.URL, filepath.Join(dir, "booted.yaml"), "", WithSnapshotDir(snapshotDir))
+	if err := booted.LoadSnapshot(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "booted.yaml")); err != nil || string(b) != "name: snapshot-rollback-v1\n" {
+		t.Fatalf("want the config of v1 restored from snapshot but got %q %v", b, err)
+	}
+
+	// the rolled back release without a previous one is kept but not booted
+	dir = t.TempDir()
+	version = "snapshot-rollback-v3"
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"` + version + `"}`, Version: version})
+	}))
+	defer srv.Close()
+	snapshotDir = filepath.Join(dir, "snapshot")
+	c = New("test", srv.URL, filepath.Join(dir, "config.yaml"), "", WithSnapshotDir(snapshotDir))
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	config.MarkBad(&configv1.Gateway{Name: "test", Version: version})
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	booted = New("test", srv.URL, filepath.Join(dir, "booted.yaml"), "", WithSnapshotDir(snapshotDir))
+	if manifest, _, err = booted.readSnapshot(); err != nil || len(manifest.RolledBack) != 1 || manifest.RolledBack[0] != version {
+		t.Fatalf("want the rolled back version recorded but got %+v %v", manifest, err)
+	}
+	if err := booted.LoadSnapshot(); err == nil {
+		t.Fatal("want error for the rolled back snapshot release")
+	}
+}
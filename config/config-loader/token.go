@@ -0,0 +1,68 @@
+package ctrlloader
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// WithToken sets the bearer token of the requests to the control service, the token is expected to be
+// read-scoped to the gateway name.
+func WithToken(token string) Option {
+	return func(c *CtrlConfigLoader) {
+		c.token = token
+	}
+}
+
+// WithTokenFile sets the file of the bearer token, the file is read on each poll so the rotated token
+// is used without restarting the gateway. It takes precedence over WithToken.
+func WithTokenFile(path string) Option {
+	return func(c *CtrlConfigLoader) {
+		c.tokenFile = path
+	}
+}
+
+// WithTLS sets the TLS config of the requests to the control service, eg: the client certificate served by
+// tlsreload.ClientConfig. The default transport is used if nil.
+func WithTLS(cfg *tls.Config) Option {
+	return func(c *CtrlConfigLoader) {
+		if cfg == nil {
+			return
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = cfg
+		c.client = &http.Client{Transport: transport}
+	}
+}
+
+func (c *CtrlConfigLoader) authorize(req *http.Request) error {
+	token := c.token
+	if c.tokenFile != "" {
+		b, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return fmt.Errorf("failed to read control service token: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return nil
+}
+
+// checkStatus returns the error of the non-200 response, 304 is errNotModified.
+func (c *CtrlConfigLoader) checkStatus(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotModified:
+		return errNotModified
+	case http.StatusUnauthorized:
+		return fmt.Errorf("control service rejected the token of gateway %q: invalid status code: %d", c.advertiseName, resp.StatusCode)
+	case http.StatusForbidden:
+		return fmt.Errorf("the token is not scoped to read gateway %q: invalid status code: %d", c.advertiseName, resp.StatusCode)
+	}
+	return fmt.Errorf("invalid status code: %d", resp.StatusCode)
+}
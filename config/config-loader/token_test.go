@@ -0,0 +1,49 @@
+package ctrlloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte("team-a-gw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the token is read-scoped to the gateway test
+		if r.Header.Get("Authorization") != "Bearer team-a-gw" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("gateway") != "test" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(&LoadResponse{Config: `{"name":"test"}`, Version: "v1"})
+	}))
+	defer srv.Close()
+
+	c := New("test", srv.URL, filepath.Join(dir, "config.yaml"), "", WithToken("ignored"), WithTokenFile(tokenFile))
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	other := New("other", srv.URL, filepath.Join(dir, "other.yaml"), "", WithTokenFile(tokenFile))
+	if err := other.Load(context.Background()); err == nil || !strings.Contains(err.Error(), "not scoped") {
+		t.Fatalf("want the out of scope error but got %v", err)
+	}
+	// the rotated token is read on the next poll
+	if err := os.WriteFile(tokenFile, []byte("revoked"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load(context.Background()); err == nil || !strings.Contains(err.Error(), "rejected the token") {
+		t.Fatalf("want the rejected token error but got %v", err)
+	}
+}
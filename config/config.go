@@ -13,12 +13,13 @@ import (
 	"path/filepath"
 	"reflect"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/aide-family/goddess/audit"
 	configv1 "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/go-kratos/kratos/v2/log"
 	"google.golang.org/protobuf/encoding/protojson"
-	"sigs.k8s.io/yaml"
 )
 
 type OnChange func() error
@@ -37,6 +38,17 @@ type FileLoader struct {
 	watchCancel        context.CancelFunc
 	lock               sync.RWMutex
 	onChangeHandlers   []OnChange
+
+	overlayLock sync.Mutex
+	// the priority configs of the last load.
+	overlays []*PriorityOverlay
+	// the config of the last load and the hash of its main config.
+	loaded atomic.Pointer[loadedConfig]
+}
+
+type loadedConfig struct {
+	config *configv1.Gateway
+	hash   string
 }
 
 var _jsonOptions = &protojson.UnmarshalOptions{DiscardUnknown: true}
@@ -124,20 +136,32 @@ func (f *FileLoader) Load(_ context.Context) (*configv1.Gateway, error) {
 		return nil, err
 	}
 
-	jsonData, err := yaml.YAMLToJSON(configData)
-	if err != nil {
-		return nil, err
-	}
 	out := &configv1.Gateway{}
-	if err := _jsonOptions.Unmarshal(jsonData, out); err != nil {
+	if err := decodeYAML(f.confPath, configData, out); err != nil {
 		return nil, err
 	}
+	hash := Hash(out)
 	if err := f.mergePriorityConfig(out); err != nil {
 		log.Warnf("failed to merge priority config: %+v", err)
 	}
+	// the priority endpoints may reference the services as well
+	if err := ExpandServices(out); err != nil {
+		return nil, fmt.Errorf("%s: %w", f.confPath, err)
+	}
+	f.loaded.Store(&loadedConfig{config: out, hash: hash})
 	return out, nil
 }
 
+// MainHash returns the Hash of the main config c was loaded from, before the priority configs are merged and
+// the services are expanded, so it equals the hash of the config published by the control service. It is
+// empty if c is not the last config loaded.
+func (f *FileLoader) MainHash(c *configv1.Gateway) string {
+	if loaded := f.loaded.Load(); loaded != nil && loaded.config == c {
+		return loaded.hash
+	}
+	return ""
+}
+
 func (f *FileLoader) mergePriorityConfig(dst *configv1.Gateway) error {
 	if f.priorityDirectory == "" {
 		return nil
@@ -147,6 +171,13 @@ func (f *FileLoader) mergePriorityConfig(dst *configv1.Gateway) error {
 		return err
 	}
 	replaceOrPrependEndpoint := MakeReplaceOrPrependEndpointFn(dst.Endpoints)
+	overlays := []*PriorityOverlay{}
+	defer func() {
+		observePriorityOverlays(overlays)
+		f.overlayLock.Lock()
+		f.overlays = overlays
+		f.overlayLock.Unlock()
+	}()
 	for _, e := range entrys {
 		if e.IsDir() {
 			continue
@@ -155,12 +186,26 @@ func (f *FileLoader) mergePriorityConfig(dst *configv1.Gateway) error {
 			continue
 		}
 		cfgPath := filepath.Join(f.priorityDirectory, e.Name())
+		info, err := e.Info()
+		if err != nil {
+			log.Warnf("failed to stat priority config: %s: %+v, skip merge this file", cfgPath, err)
+			continue
+		}
 		pCfg, err := f.parsePriorityConfig(cfgPath)
 		if err != nil {
 			log.Warnf("failed to parse priority config: %s: %+v, skip merge this file", cfgPath, err)
 			continue
 		}
+		overlay := newPriorityOverlay(cfgPath, info.ModTime(), pCfg)
+		overlays = append(overlays, overlay)
+		if overlay.Expired {
+			log.Warnf("priority config %s is expired at %s, skip merge this file", cfgPath, overlay.ExpireAt)
+			continue
+		}
 		for _, e := range pCfg.Endpoints {
+			if pCfg.Rollout != nil {
+				e = withPriorityRollout(dst.Endpoints, overlay, info.ModTime(), pCfg.Rollout, e)
+			}
 			dst.Endpoints = replaceOrPrependEndpoint(dst.Endpoints, e)
 		}
 		log.Infof("succeeded to merge priority config: %s, %d endpoints effected", cfgPath, len(pCfg.Endpoints))
@@ -173,12 +218,8 @@ func (f *FileLoader) parsePriorityConfig(cfgPath string) (*configv1.PriorityConf
 	if err != nil {
 		return nil, err
 	}
-	jsonData, err := yaml.YAMLToJSON(configData)
-	if err != nil {
-		return nil, err
-	}
 	out := &configv1.PriorityConfig{}
-	if err := _jsonOptions.Unmarshal(jsonData, out); err != nil {
+	if err := decodeYAML(cfgPath, configData, out); err != nil {
 		return nil, err
 	}
 	return out, nil
@@ -238,6 +279,13 @@ func (f *FileLoader) watchproc(ctx context.Context) {
 				log.Errorf("watch config file error: %+v", err)
 				return
 			}
+			if expiry := f.nextExpiry(); !expiry.IsZero() && time.Now().After(expiry) {
+				log.Infof("priority config expired at %s, reload config", expiry)
+				if err := f.executeLoader(); err != nil {
+					log.Errorf("execute config loader error on priority config expiry: %+v", err)
+				}
+				return
+			}
 			if sha256hex != f.confSHA256 || !reflect.DeepEqual(pfHash, f.priorityConfigHash) {
 				log.Infof("config file changed, reload config, last sha256: %s, new sha256: %s, last pfHash: %+v, new pfHash: %+v", f.confSHA256, sha256hex, f.priorityConfigHash, pfHash)
 				if err := f.executeLoader(); err != nil {
@@ -252,15 +300,39 @@ func (f *FileLoader) watchproc(ctx context.Context) {
 	}
 }
 
+func (f *FileLoader) nextExpiry() time.Time {
+	f.overlayLock.Lock()
+	defer f.overlayLock.Unlock()
+	return nextExpiry(f.overlays)
+}
+
+// Overlays returns the priority configs of the last load with their remaining ttl.
+func (f *FileLoader) Overlays() []*PriorityOverlay {
+	f.overlayLock.Lock()
+	defer f.overlayLock.Unlock()
+	now := time.Now()
+	out := make([]*PriorityOverlay, 0, len(f.overlays))
+	for _, o := range f.overlays {
+		out = append(out, o.withRemaining(now))
+	}
+	return out
+}
+
+// Promote merges the named priority config into the config file and removes the priority config.
+func (f *FileLoader) Promote(name string) (*PriorityOverlay, error) {
+	return Promote(f.confPath, f.priorityDirectory, name)
+}
+
 func (f *FileLoader) Close() {
 	f.watchCancel()
 }
 
 type InspectFileLoader struct {
-	ConfPath           string            `json:"confPath"`
-	ConfSHA256         string            `json:"confSha256"`
-	PriorityConfigHash map[string]string `json:"priorityConfigHash"`
-	OnChangeHandlers   int64             `json:"onChangeHandlers"`
+	ConfPath           string             `json:"confPath"`
+	ConfSHA256         string             `json:"confSha256"`
+	PriorityConfigHash map[string]string  `json:"priorityConfigHash"`
+	OnChangeHandlers   int64              `json:"onChangeHandlers"`
+	Overlays           []*PriorityOverlay `json:"overlays"`
 }
 
 func (f *FileLoader) DebugHandler() http.Handler {
@@ -271,6 +343,7 @@ func (f *FileLoader) DebugHandler() http.Handler {
 			ConfSHA256:         f.confSHA256,
 			PriorityConfigHash: f.priorityConfigHash,
 			OnChangeHandlers:   int64(len(f.onChangeHandlers)),
+			Overlays:           f.Overlays(),
 		}
 		rw.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(rw).Encode(out)
@@ -286,6 +359,27 @@ func (f *FileLoader) DebugHandler() http.Handler {
 		b, _ := protojson.Marshal(out)
 		_, _ = rw.Write(b)
 	})
+	debugMux.HandleFunc("/debug/config/promote", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		name := r.FormValue("name")
+		if err := audit.WriteRequest(r, &audit.Record{Action: "config.promote", Target: name}); err != nil {
+			log.Errorf("failed to write audit record of promoting %s: %+v", name, err)
+			rw.WriteHeader(http.StatusInternalServerError)
+			_, _ = rw.Write([]byte("failed to write audit record"))
+			return
+		}
+		overlay, err := f.Promote(name)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(err.Error()))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(overlay)
+	})
 	debugMux.HandleFunc("/debug/config/version", func(rw http.ResponseWriter, r *http.Request) {
 		out, err := f.Load(context.Background())
 		if err != nil {
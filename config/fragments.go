@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	_fragmentRefKey    = "$ref"
+	_fragmentRefPrefix = "fragments/"
+	// the fragments referencing the others are resolved up to the depth, eg: to break the cycles
+	_maxFragmentDepth = 8
+)
+
+// Fragment is a named value of the middleware options managed centrally by the control service, eg: the list of
+// the CORS origins shared by many gateways.
+type Fragment struct {
+	Name    string          `json:"name"`
+	Value   json.RawMessage `json:"value"`
+	Version string          `json:"version"`
+}
+
+// FragmentsVersion returns the digest of the versions of the fragments, a change of any fragment changes it.
+func FragmentsVersion(fragments []*Fragment) string {
+	if len(fragments) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(fragments))
+	for _, f := range fragments {
+		pairs = append(pairs, f.Name+"="+f.Version)
+	}
+	sort.Strings(pairs)
+	sum := sha256.Sum256([]byte(strings.Join(pairs, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ResolveFragments replaces the references of the fragments in the options of the middlewares of the gateway,
+// the host groups and the endpoints of the JSON config, it works on the priority configs too. A reference is an
+// object of the $ref key, eg: {"allowOrigins": {"$ref": "fragments/cors-origins"}}, it is replaced by the value
+// of the fragment; the other keys of the object are merged over the fragment, which must be an object then.
+//
+// The config is returned unchanged if it has no references, an unresolvable reference fails with the endpoint
+// and the middleware referencing it.
+func ResolveFragments(data []byte, fragments []*Fragment) ([]byte, error) {
+	if !bytes.Contains(data, []byte(`"`+_fragmentRefKey+`"`)) {
+		return data, nil
+	}
+	byName := make(map[string]json.RawMessage, len(fragments))
+	for _, f := range fragments {
+		byName[f.Name] = f.Value
+	}
+	doc := map[string]any{}
+	if err := decodeJSON(data, &doc); err != nil {
+		return nil, err
+	}
+	r := &fragmentResolver{fragments: byName}
+	if err := r.middlewares("gateway", doc["middlewares"]); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"hostGroups", "host_groups"} {
+		groups, _ := doc[key].([]any)
+		for _, group := range groups {
+			g, _ := group.(map[string]any)
+			if err := r.middlewares(fmt.Sprintf("host group %q", g["name"]), g["middlewares"]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	endpoints, _ := doc["endpoints"].([]any)
+	for _, endpoint := range endpoints {
+		e, _ := endpoint.(map[string]any)
+		name := strings.TrimSpace(fmt.Sprintf("%v %v", valueOr(e["method"], ""), valueOr(e["path"], "")))
+		if err := r.middlewares(fmt.Sprintf("endpoint %q", name), e["middlewares"]); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(doc)
+}
+
+func valueOr(v any, def string) any {
+	if v == nil {
+		return def
+	}
+	return v
+}
+
+func decodeJSON(data []byte, v any) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	// the numbers are kept as they are, eg: the large integers
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+type fragmentResolver struct {
+	fragments map[string]json.RawMessage
+}
+
+func (r *fragmentResolver) middlewares(owner string, v any) error {
+	middlewares, _ := v.([]any)
+	for _, middleware := range middlewares {
+		m, _ := middleware.(map[string]any)
+		if m == nil || m["options"] == nil {
+			continue
+		}
+		options, err := r.resolve(m["options"], 0)
+		if err != nil {
+			return fmt.Errorf("%s middleware %q: %w", owner, valueOr(m["name"], ""), err)
+		}
+		m["options"] = options
+	}
+	return nil
+}
+
+func (r *fragmentResolver) resolve(v any, depth int) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		ref, ok := v[_fragmentRefKey]
+		if !ok {
+			for k, item := range v {
+				resolved, err := r.resolve(item, depth)
+				if err != nil {
+					return nil, err
+				}
+				v[k] = resolved
+			}
+			return v, nil
+		}
+		value, err := r.fragment(ref, depth)
+		if err != nil {
+			return nil, err
+		}
+		if len(v) == 1 {
+			return value, nil
+		}
+		base, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("fragment %v is merged with the other keys but is not an object", ref)
+		}
+		for k, item := range v {
+			if k == _fragmentRefKey {
+				continue
+			}
+			resolved, err := r.resolve(item, depth)
+			if err != nil {
+				return nil, err
+			}
+			base[k] = resolved
+		}
+		return base, nil
+	case []any:
+		for i, item := range v {
+			resolved, err := r.resolve(item, depth)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = resolved
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+// fragment returns the decoded value of the reference, the references in it are resolved.
+func (r *fragmentResolver) fragment(ref any, depth int) (any, error) {
+	s, _ := ref.(string)
+	name, ok := strings.CutPrefix(s, _fragmentRefPrefix)
+	if !ok || name == "" {
+		return nil, fmt.Errorf("invalid reference %v, want %s<name>", ref, _fragmentRefPrefix)
+	}
+	if depth >= _maxFragmentDepth {
+		return nil, fmt.Errorf("reference %s is nested deeper than %d", s, _maxFragmentDepth)
+	}
+	raw, ok := r.fragments[name]
+	if !ok {
+		return nil, fmt.Errorf("unresolved reference %s", s)
+	}
+	var value any
+	if err := decodeJSON(raw, &value); err != nil {
+		return nil, fmt.Errorf("invalid fragment %s: %w", name, err)
+	}
+	return r.resolve(value, depth+1)
+}
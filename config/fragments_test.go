@@ -0,0 +1,76 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestResolveFragments(t *testing.T) {
+	fragments := []*Fragment{
+		{Name: "cors-origins", Value: json.RawMessage(`[".example.com",".example.org"]`), Version: "1"},
+		{Name: "cors", Value: json.RawMessage(`{"allowOrigins":{"$ref":"fragments/cors-origins"},"maxAge":"600s"}`), Version: "1"},
+	}
+	data := []byte(`{
+		"name": "helloworld",
+		"middlewares": [{"name": "cors", "options": {"@type": "type.googleapis.com/gateway.middleware.cors.v1.Cors", "allowOrigins": {"$ref": "fragments/cors-origins"}}}],
+		"endpoints": [{
+			"path": "/api/*",
+			"middlewares": [{"name": "cors", "options": {"$ref": "fragments/cors", "@type": "type.googleapis.com/gateway.middleware.cors.v1.Cors", "maxAge": "60s"}}]
+		}]
+	}`)
+	resolved, err := ResolveFragments(data, fragments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Middlewares []struct {
+			Options map[string]any `json:"options"`
+		} `json:"middlewares"`
+		Endpoints []struct {
+			Middlewares []struct {
+				Options map[string]any `json:"options"`
+			} `json:"middlewares"`
+		} `json:"endpoints"`
+	}
+	if err := json.Unmarshal(resolved, &got); err != nil {
+		t.Fatal(err)
+	}
+	if origins, _ := got.Middlewares[0].Options["allowOrigins"].([]any); len(origins) != 2 {
+		t.Fatalf("want the origins of the fragment but got %v", got.Middlewares[0].Options)
+	}
+	options := got.Endpoints[0].Middlewares[0].Options
+	if origins, _ := options["allowOrigins"].([]any); len(origins) != 2 || options["maxAge"] != "60s" {
+		t.Fatalf("want the nested fragment merged under the endpoint options but got %v", options)
+	}
+
+	// the configs without the references are returned as they are
+	if out, err := ResolveFragments([]byte(`{"name":"helloworld"}`), nil); err != nil || string(out) != `{"name":"helloworld"}` {
+		t.Fatalf("want the config unchanged but got %s %v", out, err)
+	}
+}
+
+func TestResolveFragmentsUnresolved(t *testing.T) {
+	data := []byte(`{"endpoints":[{"method":"GET","path":"/api/*","middlewares":[{"name":"cors","options":{"allowOrigins":{"$ref":"fragments/missing"}}}]}]}`)
+	_, err := ResolveFragments(data, nil)
+	if err == nil || !strings.Contains(err.Error(), `endpoint "GET /api/*" middleware "cors"`) || !strings.Contains(err.Error(), "fragments/missing") {
+		t.Fatalf("want the endpoint and the middleware of the unresolved reference but got %v", err)
+	}
+	cyclic := []*Fragment{{Name: "a", Value: json.RawMessage(`{"$ref":"fragments/a"}`)}}
+	data = []byte(`{"middlewares":[{"name":"cors","options":{"$ref":"fragments/a"}}]}`)
+	if _, err := ResolveFragments(data, cyclic); err == nil || !strings.Contains(err.Error(), "deeper") {
+		t.Fatalf("want the cyclic reference rejected but got %v", err)
+	}
+}
+
+func TestFragmentsVersion(t *testing.T) {
+	a := []*Fragment{{Name: "a", Version: "1"}, {Name: "b", Version: "1"}}
+	b := []*Fragment{{Name: "b", Version: "1"}, {Name: "a", Version: "1"}}
+	if FragmentsVersion(a) != FragmentsVersion(b) {
+		t.Fatal("want the version independent of the order of the fragments")
+	}
+	b[0].Version = "2"
+	if FragmentsVersion(a) == FragmentsVersion(b) {
+		t.Fatal("want the version changed with the fragments")
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Hash returns the canonical hash of the config, the sha256 of the canonical JSON of its proto JSON mapping:
+// the proto field names, the unset fields omitted, the object keys sorted, no insignificant whitespace and no
+// HTML escaping. Unlike the digest of the rollback it does not depend on the binary encoding of the options, so
+// the control service computes the same hash when publishing the config as the gateway running it.
+func Hash(c *configv1.Gateway) string {
+	b, err := canonicalJSON(c)
+	if err != nil {
+		return ""
+	}
+	return sha256sum(b)
+}
+
+// HashJSON returns the Hash of the config in JSON, empty if it is not a valid config.
+func HashJSON(b []byte) string {
+	c := &configv1.Gateway{}
+	if err := _jsonOptions.Unmarshal(b, c); err != nil {
+		return ""
+	}
+	return Hash(c)
+}
+
+func canonicalJSON(c *configv1.Gateway) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var v any
+	if err := decodeJSON(b, &v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestHash(t *testing.T) {
+	const published = `{"name":"test","version":"v1","endpoints":[{"path":"/api/*","method":"GET","timeout":"1s",` +
+		`"backends":[{"target":"127.0.0.1:8000"}]}]}`
+	c := &configv1.Gateway{}
+	if err := protojson.Unmarshal([]byte(published), c); err != nil {
+		t.Fatal(err)
+	}
+	hash := Hash(c)
+	if hash == "" || Hash(c) != hash {
+		t.Fatalf("want a stable hash but got %q", hash)
+	}
+
+	// the gateway loads the YAML of the same config, the keys in another order
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "version: v1\nname: test\nendpoints:\n- method: GET\n  path: /api/*\n  timeout: 1s\n  backends:\n  - target: 127.0.0.1:8000\n"
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	l, err := NewFileLoader(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := l.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.MainHash(loaded); got != hash {
+		t.Fatalf("want the hash of the published config %s but got %s", hash, got)
+	}
+	if l.MainHash(c) != "" {
+		t.Fatal("want no hash of a config not loaded")
+	}
+
+	c.Endpoints[0].Timeout.Seconds = 2
+	if Hash(c) == hash {
+		t.Fatal("want the hash changed with the config")
+	}
+}
@@ -0,0 +1,482 @@
+// Package k8sloader is a config loader translating the Kubernetes ingresses into the gateway config.
+package k8sloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+
+	"github.com/aide-family/goddess/config"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	_serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	_component         = "goddess"
+	_debounce          = time.Second
+	_resync            = 5 * time.Minute
+	_retryInterval     = 5 * time.Second
+)
+
+var _metricSkippedRoutes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "k8s_skipped_routes",
+	Help:      "The number of the ingress routes skipped by the last translation",
+}, []string{"ingress"})
+
+func init() {
+	metrics.Register(_metricSkippedRoutes)
+}
+
+type Option func(*K8sConfigLoader)
+
+// WithNamespace watches the ingresses and services of the namespace only.
+func WithNamespace(namespace string) Option {
+	return func(l *K8sConfigLoader) {
+		l.namespace = namespace
+	}
+}
+
+// WithIngressClass selects the ingresses by the class.
+func WithIngressClass(class string) Option {
+	return func(l *K8sConfigLoader) {
+		l.translator.IngressClass = class
+	}
+}
+
+// WithClusterDomain sets the domain of the service DNS names.
+func WithClusterDomain(domain string) Option {
+	return func(l *K8sConfigLoader) {
+		l.translator.ClusterDomain = domain
+	}
+}
+
+// WithBaseConfig sets the config file the translated endpoints are appended to,
+// it holds the gateway settings such as the middlewares.
+func WithBaseConfig(path string) Option {
+	return func(l *K8sConfigLoader) {
+		l.basePath = path
+	}
+}
+
+// WithAPIServer sets the api server and the token instead of the in-cluster config.
+func WithAPIServer(apiServer, token string, client *http.Client) Option {
+	return func(l *K8sConfigLoader) {
+		l.apiServer = apiServer
+		l.token = func() (string, error) { return token, nil }
+		l.client = client
+	}
+}
+
+// K8sConfigLoader watches the ingresses and writes the translated config to the config file,
+// the file loader reloads it with the same checks as the local changes.
+type K8sConfigLoader struct {
+	apiServer  string
+	token      func() (string, error)
+	client     *http.Client
+	namespace  string
+	basePath   string
+	dstPath    string
+	translator Translator
+	cancel     context.CancelFunc
+
+	lock      sync.Mutex
+	digest    string
+	lastSync  time.Time
+	ingresses int
+	endpoints int
+	skipped   []string
+	recorded  map[string]struct{}
+	versions  map[string]string
+	lastErr   error
+}
+
+// New returns the loader with the in-cluster config of the service account.
+func New(dstPath string, opts ...Option) (*K8sConfigLoader, error) {
+	l := &K8sConfigLoader{
+		dstPath:  dstPath,
+		recorded: map[string]struct{}{},
+		versions: map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.basePath != "" && samePath(l.basePath, dstPath) {
+		// the translated endpoints would be appended to the ones written last time
+		return nil, fmt.Errorf("the base config %s cannot be the translated config", l.basePath)
+	}
+	if l.apiServer == "" {
+		if err := l.inCluster(); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *K8sConfigLoader) inCluster() error {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return errors.New("not running in kubernetes, KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT are required")
+	}
+	ca, err := os.ReadFile(path.Join(_serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return errors.New("failed to load the service account ca")
+	}
+	l.apiServer = "https://" + net.JoinHostPort(host, port)
+	// the token is rotated by the kubelet
+	l.token = func() (string, error) {
+		b, err := os.ReadFile(path.Join(_serviceAccountDir, "token"))
+		return strings.TrimSpace(string(b)), err
+	}
+	l.client = &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+	return nil
+}
+
+func (l *K8sConfigLoader) resourcePath(group, resource string) string {
+	prefix := "/api/v1"
+	if group != "" {
+		prefix = "/apis/" + group
+	}
+	if l.namespace == "" {
+		return prefix + "/" + resource
+	}
+	return prefix + "/namespaces/" + l.namespace + "/" + resource
+}
+
+func (l *K8sConfigLoader) do(ctx context.Context, method, upath string, params url.Values, body io.Reader) (*http.Response, error) {
+	u, err := url.Parse(l.apiServer)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, upath)
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	token, err := l.token()
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: unexpected status code %d: %s", method, upath, resp.StatusCode, bytes.TrimSpace(b))
+	}
+	return resp, nil
+}
+
+func (l *K8sConfigLoader) list(ctx context.Context, upath string, out any) error {
+	resp, err := l.do(ctx, http.MethodGet, upath, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Load lists the ingresses and services and writes the translated config if it is changed.
+func (l *K8sConfigLoader) Load(ctx context.Context) (err error) {
+	defer func() {
+		l.lock.Lock()
+		l.lastErr = err
+		l.lock.Unlock()
+		if err != nil {
+			config.ObserveReload(config.ReloadSourceK8s, "", "", err)
+		}
+	}()
+	ingresses := &IngressList{}
+	if err := l.list(ctx, l.resourcePath("networking.k8s.io/v1", "ingresses"), ingresses); err != nil {
+		return err
+	}
+	services := &ServiceList{}
+	if err := l.list(ctx, l.resourcePath("", "services"), services); err != nil {
+		return err
+	}
+	base := &configv1.Gateway{Name: _component}
+	if l.basePath != "" {
+		base = &configv1.Gateway{}
+		if err := config.DecodeFile(l.basePath, base); err != nil {
+			return err
+		}
+	}
+	gw, routeErrs := l.translator.Translate(base, ingresses.Items, services.Items)
+	l.reportSkipped(ctx, routeErrs)
+
+	jsonBytes, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(gw)
+	if err != nil {
+		return err
+	}
+	yamlBytes, err := yaml.JSONToYAML(jsonBytes)
+	if err != nil {
+		return err
+	}
+	digest := sha256hex(yamlBytes)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.versions["ingresses"] = ingresses.Metadata.ResourceVersion
+	l.versions["services"] = services.Metadata.ResourceVersion
+	l.lastSync = time.Now()
+	l.ingresses = len(ingresses.Items)
+	l.endpoints = len(gw.Endpoints)
+	if digest == l.digest {
+		return nil
+	}
+	if err := writeFileAtomic(l.dstPath, yamlBytes); err != nil {
+		return err
+	}
+	l.digest = digest
+	config.ObserveReload(config.ReloadSourceK8s, gw.Version, config.Hash(gw), nil)
+	log.Infof("translated %d ingresses into %d endpoints, %d routes skipped", len(ingresses.Items), len(gw.Endpoints), len(routeErrs))
+	return nil
+}
+
+// reportSkipped records a warning event on the ingress for each skipped route, the same error is recorded once.
+func (l *K8sConfigLoader) reportSkipped(ctx context.Context, errs []*RouteError) {
+	skipped := make([]string, 0, len(errs))
+	counts := map[string]int{}
+	var events []*RouteError
+	l.lock.Lock()
+	recorded := make(map[string]struct{}, len(errs))
+	for _, e := range errs {
+		msg := e.Error()
+		skipped = append(skipped, msg)
+		counts[e.Ingress.Metadata.Namespace+"/"+e.Ingress.Metadata.Name]++
+		key := e.Ingress.Metadata.UID + "/" + e.Ingress.Metadata.ResourceVersion + "/" + msg
+		recorded[key] = struct{}{}
+		if _, ok := l.recorded[key]; !ok {
+			events = append(events, e)
+		}
+	}
+	l.recorded = recorded
+	l.skipped = skipped
+	l.lock.Unlock()
+
+	_metricSkippedRoutes.Reset()
+	for ingress, n := range counts {
+		_metricSkippedRoutes.WithLabelValues(ingress).Set(float64(n))
+	}
+	for _, e := range events {
+		log.Warnf("Skip the route failed to translate: %v", e)
+		if err := l.recordEvent(ctx, e); err != nil {
+			log.Warnf("Failed to record event of the skipped route: %v", err)
+		}
+	}
+}
+
+func (l *K8sConfigLoader) recordEvent(ctx context.Context, e *RouteError) error {
+	now := time.Now()
+	ing := e.Ingress.Metadata
+	event := &Event{
+		Metadata: ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", ing.Name, now.UnixNano()),
+			Namespace: ing.Namespace,
+		},
+		InvolvedObject: ObjectReference{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+			Namespace:  ing.Namespace,
+			Name:       ing.Name,
+			UID:        ing.UID,
+		},
+		Reason:         "RouteSkipped",
+		Message:        e.message(),
+		Type:           "Warning",
+		Source:         EventSource{Component: _component},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	resp, err := l.do(ctx, http.MethodPost, "/api/v1/namespaces/"+ing.Namespace+"/events", nil, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// watch blocks until the resources are changed since the version.
+func (l *K8sConfigLoader) watch(ctx context.Context, upath, version string) error {
+	params := url.Values{}
+	params.Set("watch", "1")
+	params.Set("resourceVersion", version)
+	resp, err := l.do(ctx, http.MethodGet, upath, params, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		event := &WatchEvent{}
+		if err := decoder.Decode(event); err != nil {
+			return err
+		}
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			return nil
+		case "ERROR":
+			// eg: the version is too old, the resources are listed again
+			return errors.New("watch error event")
+		}
+	}
+}
+
+// Run watches the ingresses and services, the config is translated again after the changes are settled.
+func (l *K8sConfigLoader) Run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	l.cancel = cancel
+	for {
+		if err := l.Load(ctx); err != nil {
+			log.Warnf("Failed to load config from kubernetes: %+v", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(_retryInterval):
+			}
+			continue
+		}
+		l.waitChanges(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(_debounce):
+		}
+	}
+}
+
+func (l *K8sConfigLoader) waitChanges(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, _resync)
+	defer cancel()
+	l.lock.Lock()
+	versions := map[string]string{
+		l.resourcePath("networking.k8s.io/v1", "ingresses"): l.versions["ingresses"],
+		l.resourcePath("", "services"):                      l.versions["services"],
+	}
+	l.lock.Unlock()
+	changed := make(chan struct{}, len(versions))
+	for upath, version := range versions {
+		go func() {
+			if err := l.watch(ctx, upath, version); err != nil && ctx.Err() == nil {
+				log.Warnf("Failed to watch %s: %+v", upath, err)
+			}
+			changed <- struct{}{}
+		}()
+	}
+	select {
+	case <-ctx.Done():
+	case <-changed:
+	}
+}
+
+func (l *K8sConfigLoader) Close() {
+	if l.cancel != nil {
+		l.cancel()
+	}
+}
+
+type InspectK8sConfigLoader struct {
+	APIServer    string    `json:"api_server"`
+	Namespace    string    `json:"namespace,omitempty"`
+	IngressClass string    `json:"ingress_class,omitempty"`
+	DstPath      string    `json:"dst_path"`
+	Digest       string    `json:"digest"`
+	LastSync     time.Time `json:"last_sync"`
+	LastError    string    `json:"last_error,omitempty"`
+	Ingresses    int       `json:"ingresses"`
+	Endpoints    int       `json:"endpoints"`
+	Skipped      []string  `json:"skipped,omitempty"`
+}
+
+func (l *K8sConfigLoader) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	debugMux.HandleFunc("/debug/k8s/inspect", func(rw http.ResponseWriter, r *http.Request) {
+		l.lock.Lock()
+		out := &InspectK8sConfigLoader{
+			APIServer:    l.apiServer,
+			Namespace:    l.namespace,
+			IngressClass: l.translator.IngressClass,
+			DstPath:      l.dstPath,
+			Digest:       l.digest,
+			LastSync:     l.lastSync,
+			Ingresses:    l.ingresses,
+			Endpoints:    l.endpoints,
+			Skipped:      l.skipped,
+		}
+		if l.lastErr != nil {
+			out.LastError = l.lastErr.Error()
+		}
+		l.lock.Unlock()
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(out)
+	})
+	return debugMux
+}
+
+func sha256hex(in []byte) string {
+	sum := sha256.Sum256(in)
+	return hex.EncodeToString(sum[:])
+}
+
+func samePath(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && a == b
+}
+
+func writeFileAtomic(name string, data []byte) error {
+	tmpPath := name + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, name); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
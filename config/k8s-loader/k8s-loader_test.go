@@ -0,0 +1,148 @@
+package k8sloader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	discoveryv1 "github.com/aide-family/goddess/pkg/discovery/v1"
+)
+
+const _ingresses = `{
+  "metadata": {"resourceVersion": "10"},
+  "items": [{
+    "metadata": {
+      "name": "web", "namespace": "default", "uid": "u1", "resourceVersion": "3",
+      "annotations": {
+        "goddess.aide-family.io/timeout": "3s",
+        "goddess.aide-family.io/retry-attempts": "2",
+        "goddess.aide-family.io/retry-status-codes": "502,503-504"
+      }
+    },
+    "spec": {
+      "ingressClassName": "goddess",
+      "rules": [{
+        "host": "api.example.com",
+        "http": {"paths": [
+          {"path": "/beta", "pathType": "Prefix", "backend": {"service": {"name": "beta", "port": {"name": "http"}}}},
+          {"path": "/healthz", "pathType": "Exact", "backend": {"service": {"name": "beta", "port": {"number": 8080}}}},
+          {"path": "/missing", "pathType": "Prefix", "backend": {"service": {"name": "missing", "port": {"number": 80}}}}
+        ]}
+      }],
+      "defaultBackend": {"service": {"name": "web", "port": {"number": 80}}}
+    }
+  }, {
+    "metadata": {"name": "bad", "namespace": "default", "uid": "u2", "annotations": {"goddess.aide-family.io/timeout": "soon"}},
+    "spec": {"ingressClassName": "goddess", "defaultBackend": {"service": {"name": "web", "port": {"number": 80}}}}
+  }, {
+    "metadata": {"name": "other", "namespace": "default"},
+    "spec": {"ingressClassName": "nginx", "defaultBackend": {"service": {"name": "web", "port": {"number": 80}}}}
+  }]
+}`
+
+const _services = `{
+  "metadata": {"resourceVersion": "20"},
+  "items": [
+    {"metadata": {"name": "beta", "namespace": "default"}, "spec": {"ports": [{"name": "http", "port": 8000}]}},
+    {"metadata": {"name": "web", "namespace": "default"}, "spec": {"ports": [{"port": 80}]}}
+  ]
+}`
+
+func TestTranslate(t *testing.T) {
+	ingresses, services := &IngressList{}, &ServiceList{}
+	if err := json.Unmarshal([]byte(_ingresses), ingresses); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(_services), services); err != nil {
+		t.Fatal(err)
+	}
+	translator := &Translator{IngressClass: "goddess"}
+	gw, errs := translator.Translate(&configv1.Gateway{Name: "base"}, ingresses.Items, services.Items)
+	if len(errs) != 2 || errs[0].Path != "/missing" || errs[1].Ingress.Metadata.Name != "bad" {
+		t.Fatalf("want the failed routes skipped only: %v", errs)
+	}
+	var got []string
+	for _, e := range gw.Endpoints {
+		got = append(got, e.Host+e.Path+" "+e.Backends[0].Target)
+	}
+	want := []string{
+		"api.example.com/healthz beta.default.svc.cluster.local:8080",
+		"api.example.com/beta/* beta.default.svc.cluster.local:8000",
+		"api.example.com/beta beta.default.svc.cluster.local:8000",
+		"/* web.default.svc.cluster.local:80",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected endpoints:\n%s", strings.Join(got, "\n"))
+	}
+	e := gw.Endpoints[0]
+	if e.Timeout.AsDuration().String() != "3s" || e.Retry.Attempts != 2 || len(e.Retry.Conditions) != 2 || e.Metadata["service"] != "beta" {
+		t.Fatalf("unexpected endpoint settings: %+v", e)
+	}
+
+	// the backends are resolved by the discovery of the base config
+	gw, _ = translator.Translate(&configv1.Gateway{Name: "base", Discovery: &discoveryv1.Discovery{Name: "consul"}}, ingresses.Items, services.Items)
+	for _, e := range gw.Endpoints {
+		if want := "discovery:///" + e.Metadata["service"]; e.Backends[0].Target != want {
+			t.Fatalf("want the backend %s but got %s", want, e.Backends[0].Target)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		events []*Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/apis/networking.k8s.io/v1/namespaces/default/ingresses":
+			w.Write([]byte(_ingresses))
+		case r.URL.Path == "/api/v1/namespaces/default/services":
+			w.Write([]byte(_services))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces/default/events":
+			event := &Event{}
+			json.NewDecoder(r.Body).Decode(event)
+			lock.Lock()
+			events = append(events, event)
+			lock.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	dst := filepath.Join(t.TempDir(), "config.yaml")
+	if _, err := New(dst, WithAPIServer(srv.URL, "token", srv.Client()), WithBaseConfig(dst)); err == nil {
+		t.Fatal("want the base config written over rejected")
+	}
+	l, err := New(dst, WithAPIServer(srv.URL, "token", srv.Client()), WithNamespace("default"), WithIngressClass("goddess"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := l.Load(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gw := &configv1.Gateway{}
+	if err := config.DecodeFile(dst, gw); err != nil {
+		t.Fatal(err)
+	}
+	if len(gw.Endpoints) != 4 {
+		t.Fatalf("want the translated endpoints written but got %d", len(gw.Endpoints))
+	}
+	// the skipped routes are recorded once
+	if len(events) != 2 || events[0].InvolvedObject.Name != "web" || events[0].Reason != "RouteSkipped" {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+}
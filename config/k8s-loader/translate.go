@@ -0,0 +1,281 @@
+package k8sloader
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	_annotationPrefix = "goddess.aide-family.io/"
+	// the ingress class of the legacy annotation
+	_annotationIngressClass = "kubernetes.io/ingress.class"
+
+	AnnotationTimeout          = _annotationPrefix + "timeout"
+	AnnotationMethod           = _annotationPrefix + "method"
+	AnnotationProtocol         = _annotationPrefix + "protocol"
+	AnnotationStream           = _annotationPrefix + "stream"
+	AnnotationRetryAttempts    = _annotationPrefix + "retry-attempts"
+	AnnotationRetryPerTry      = _annotationPrefix + "retry-per-try-timeout"
+	AnnotationRetryStatusCodes = _annotationPrefix + "retry-status-codes"
+)
+
+// RouteError is a route of the ingress skipped by the translation.
+type RouteError struct {
+	Ingress *Ingress
+	Host    string
+	Path    string
+	Err     error
+}
+
+func (e *RouteError) Error() string {
+	return fmt.Sprintf("ingress %s/%s: %s", e.Ingress.Metadata.Namespace, e.Ingress.Metadata.Name, e.message())
+}
+
+func (e *RouteError) message() string {
+	if e.Path == "" {
+		// all the routes of the ingress are skipped
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("route %s%s: %v", e.Host, e.Path, e.Err)
+}
+
+// Translator translates the ingresses into the gateway endpoints.
+type Translator struct {
+	// IngressClass selects the ingresses by the class, all the ingresses are selected if it is empty.
+	IngressClass string
+	// ClusterDomain is the domain of the service DNS names, eg: cluster.local.
+	ClusterDomain string
+}
+
+// Translate appends the endpoints of the ingresses to the base config,
+// the routes failed to translate are skipped and returned as the errors.
+func (t *Translator) Translate(base *configv1.Gateway, ingresses []*Ingress, services []*Service) (*configv1.Gateway, []*RouteError) {
+	out := proto.Clone(base).(*configv1.Gateway)
+	ports := make(map[string]*Service, len(services))
+	for _, svc := range services {
+		ports[svc.Metadata.Namespace+"/"+svc.Metadata.Name] = svc
+	}
+	// the backends are resolved by the discovery of the base config instead of the cluster DNS
+	discovery := base.GetDiscovery().GetName() != ""
+	var (
+		endpoints []*configv1.Endpoint
+		errs      []*RouteError
+	)
+	for _, ing := range ingresses {
+		if !t.selected(ing) {
+			continue
+		}
+		template, err := endpointTemplate(ing.Metadata.Annotations)
+		if err != nil {
+			// the annotations apply to all the routes of the ingress
+			errs = append(errs, &RouteError{Ingress: ing, Err: err})
+			continue
+		}
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				translated, err := t.translatePath(ing, template, rule.Host, p.Path, p.PathType, p.Backend, ports, discovery)
+				if err != nil {
+					errs = append(errs, &RouteError{Ingress: ing, Host: rule.Host, Path: p.Path, Err: err})
+					continue
+				}
+				endpoints = append(endpoints, translated...)
+			}
+		}
+		if ing.Spec.DefaultBackend != nil {
+			translated, err := t.translatePath(ing, template, "", "/", "Prefix", *ing.Spec.DefaultBackend, ports, discovery)
+			if err != nil {
+				errs = append(errs, &RouteError{Ingress: ing, Path: "/", Err: err})
+			} else {
+				endpoints = append(endpoints, translated...)
+			}
+		}
+	}
+	sortEndpoints(endpoints)
+	out.Endpoints = append(out.Endpoints, endpoints...)
+	return out, errs
+}
+
+func (t *Translator) selected(ing *Ingress) bool {
+	if t.IngressClass == "" {
+		return true
+	}
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName == t.IngressClass
+	}
+	return ing.Metadata.Annotations[_annotationIngressClass] == t.IngressClass
+}
+
+func (t *Translator) translatePath(ing *Ingress, template *configv1.Endpoint, host, path, pathType string, backend IngressBackend, ports map[string]*Service, discovery bool) ([]*configv1.Endpoint, error) {
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, "{}*") {
+		return nil, fmt.Errorf("unsupported path %q", path)
+	}
+	target, err := t.backendTarget(ing.Metadata.Namespace, backend, ports)
+	if err != nil {
+		return nil, err
+	}
+	if discovery {
+		// the instances and their ports are registered by the service itself
+		target = "discovery:///" + backend.Service.Name
+	}
+	var paths []string
+	switch pathType {
+	case "Exact":
+		paths = []string{path}
+	case "Prefix", "ImplementationSpecific":
+		// the prefix matches by the path elements, /foo matches /foo and /foo/bar but not /foobar
+		trimmed := strings.TrimRight(path, "/")
+		if trimmed == "" {
+			paths = []string{"/*"}
+		} else {
+			paths = []string{trimmed, trimmed + "/*"}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported path type %q", pathType)
+	}
+	out := make([]*configv1.Endpoint, 0, len(paths))
+	for _, p := range paths {
+		e := proto.Clone(template).(*configv1.Endpoint)
+		e.Path = p
+		e.Host = host
+		e.Backends = []*configv1.Backend{{Target: target}}
+		if e.Metadata == nil {
+			e.Metadata = map[string]string{}
+		}
+		e.Metadata["service"] = backend.Service.Name
+		e.Metadata["ingress"] = ing.Metadata.Namespace + "/" + ing.Metadata.Name
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// backendTarget resolves the backend to the cluster DNS name of the service.
+func (t *Translator) backendTarget(namespace string, backend IngressBackend, ports map[string]*Service) (string, error) {
+	if backend.Service == nil {
+		return "", fmt.Errorf("only service backends are supported")
+	}
+	svc, ok := ports[namespace+"/"+backend.Service.Name]
+	if !ok {
+		return "", fmt.Errorf("service %s/%s not found", namespace, backend.Service.Name)
+	}
+	port := backend.Service.Port.Number
+	if name := backend.Service.Port.Name; name != "" {
+		for _, p := range svc.Spec.Ports {
+			if p.Name == name {
+				port = p.Port
+			}
+		}
+		if port == 0 {
+			return "", fmt.Errorf("port %q of service %s/%s not found", name, namespace, backend.Service.Name)
+		}
+	}
+	if port == 0 {
+		return "", fmt.Errorf("port of service %s/%s is required", namespace, backend.Service.Name)
+	}
+	domain := t.ClusterDomain
+	if domain == "" {
+		domain = "cluster.local"
+	}
+	return fmt.Sprintf("%s.%s.svc.%s:%d", backend.Service.Name, namespace, domain, port), nil
+}
+
+// endpointTemplate returns the endpoint with the settings of the annotations.
+func endpointTemplate(annotations map[string]string) (*configv1.Endpoint, error) {
+	e := &configv1.Endpoint{Protocol: configv1.Protocol_HTTP}
+	if v := annotations[AnnotationProtocol]; v != "" {
+		protocol, ok := configv1.Protocol_value[strings.ToUpper(v)]
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: %q", AnnotationProtocol, v)
+		}
+		e.Protocol = configv1.Protocol(protocol)
+	}
+	e.Method = annotations[AnnotationMethod]
+	if v := annotations[AnnotationTimeout]; v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AnnotationTimeout, err)
+		}
+		e.Timeout = durationpb.New(d)
+	}
+	if v := annotations[AnnotationStream]; v != "" {
+		stream, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AnnotationStream, err)
+		}
+		e.Stream = stream
+	}
+	retry, err := retryFromAnnotations(annotations)
+	if err != nil {
+		return nil, err
+	}
+	if retry != nil && e.Stream {
+		return nil, fmt.Errorf("retry is not supported by stream endpoints")
+	}
+	e.Retry = retry
+	return e, nil
+}
+
+func retryFromAnnotations(annotations map[string]string) (*configv1.Retry, error) {
+	attempts, perTry, codes := annotations[AnnotationRetryAttempts], annotations[AnnotationRetryPerTry], annotations[AnnotationRetryStatusCodes]
+	if attempts == "" && perTry == "" && codes == "" {
+		return nil, nil
+	}
+	retry := &configv1.Retry{}
+	if attempts != "" {
+		n, err := strconv.ParseUint(attempts, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AnnotationRetryAttempts, err)
+		}
+		retry.Attempts = uint32(n)
+	}
+	if perTry != "" {
+		d, err := time.ParseDuration(perTry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", AnnotationRetryPerTry, err)
+		}
+		retry.PerTryTimeout = durationpb.New(d)
+	}
+	for _, code := range strings.Split(codes, ",") {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		for _, part := range strings.SplitN(code, "-", 2) {
+			if _, err := strconv.ParseUint(part, 10, 16); err != nil {
+				return nil, fmt.Errorf("invalid %s: %q", AnnotationRetryStatusCodes, code)
+			}
+		}
+		retry.Conditions = append(retry.Conditions, &configv1.Condition{
+			Condition: &configv1.Condition_ByStatusCode{ByStatusCode: code},
+		})
+	}
+	return retry, nil
+}
+
+// sortEndpoints orders the endpoints so the routes with a host and the longer paths match first,
+// the exact paths precede the prefixes of the same length.
+func sortEndpoints(in []*configv1.Endpoint) {
+	sort.SliceStable(in, func(i, j int) bool {
+		a, b := in[i], in[j]
+		if (a.Host == "") != (b.Host == "") {
+			return a.Host != ""
+		}
+		pa, pb := strings.TrimSuffix(a.Path, "*"), strings.TrimSuffix(b.Path, "*")
+		if len(pa) != len(pb) {
+			return len(pa) > len(pb)
+		}
+		return !strings.HasSuffix(a.Path, "*") && strings.HasSuffix(b.Path, "*")
+	})
+}
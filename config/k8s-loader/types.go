@@ -0,0 +1,114 @@
+package k8sloader
+
+import "time"
+
+// The subset of the Kubernetes resources used by the translation,
+// see https://kubernetes.io/docs/reference/kubernetes-api/service-resources/ingress-v1/
+
+type ObjectMeta struct {
+	Name            string            `json:"name"`
+	Namespace       string            `json:"namespace,omitempty"`
+	UID             string            `json:"uid,omitempty"`
+	ResourceVersion string            `json:"resourceVersion,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+type ListMeta struct {
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type Ingress struct {
+	Kind       string      `json:"kind,omitempty"`
+	APIVersion string      `json:"apiVersion,omitempty"`
+	Metadata   ObjectMeta  `json:"metadata"`
+	Spec       IngressSpec `json:"spec"`
+}
+
+type IngressSpec struct {
+	IngressClassName *string         `json:"ingressClassName,omitempty"`
+	DefaultBackend   *IngressBackend `json:"defaultBackend,omitempty"`
+	Rules            []IngressRule   `json:"rules,omitempty"`
+}
+
+type IngressRule struct {
+	Host string                `json:"host,omitempty"`
+	HTTP *HTTPIngressRuleValue `json:"http,omitempty"`
+}
+
+type HTTPIngressRuleValue struct {
+	Paths []HTTPIngressPath `json:"paths"`
+}
+
+type HTTPIngressPath struct {
+	Path     string         `json:"path,omitempty"`
+	PathType string         `json:"pathType"`
+	Backend  IngressBackend `json:"backend"`
+}
+
+type IngressBackend struct {
+	Service *IngressServiceBackend `json:"service,omitempty"`
+}
+
+type IngressServiceBackend struct {
+	Name string             `json:"name"`
+	Port ServiceBackendPort `json:"port"`
+}
+
+type ServiceBackendPort struct {
+	Name   string `json:"name,omitempty"`
+	Number int32  `json:"number,omitempty"`
+}
+
+type IngressList struct {
+	Metadata ListMeta   `json:"metadata"`
+	Items    []*Ingress `json:"items"`
+}
+
+type Service struct {
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     ServiceSpec `json:"spec"`
+}
+
+type ServiceSpec struct {
+	Ports []ServicePort `json:"ports,omitempty"`
+}
+
+type ServicePort struct {
+	Name string `json:"name,omitempty"`
+	Port int32  `json:"port"`
+}
+
+type ServiceList struct {
+	Metadata ListMeta   `json:"metadata"`
+	Items    []*Service `json:"items"`
+}
+
+// WatchEvent is an event of the watch stream, the object is decoded lazily.
+type WatchEvent struct {
+	Type string `json:"type"`
+}
+
+type ObjectReference struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+}
+
+type EventSource struct {
+	Component string `json:"component,omitempty"`
+}
+
+// Event is a core/v1 event recorded on the ingress whose route is skipped.
+type Event struct {
+	Metadata       ObjectMeta      `json:"metadata"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Type           string          `json:"type"`
+	Source         EventSource     `json:"source"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+	Count          int32           `json:"count"`
+}
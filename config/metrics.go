@@ -0,0 +1,57 @@
+package config
+
+import (
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ReloadSourceFile is the reload source of the local config file.
+	ReloadSourceFile = "file"
+	// ReloadSourceCtrl is the reload source of the control service.
+	ReloadSourceCtrl = "ctrl"
+	// ReloadSourceK8s is the reload source of the Kubernetes ingresses.
+	ReloadSourceK8s = "k8s"
+)
+
+var (
+	_metricReloadTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_reload_total",
+		Help:      "The total number of config reload attempts",
+	}, []string{"source", "result"})
+	_metricConfigInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_info",
+		Help:      "The version and hash of the currently applied config",
+	}, []string{"source", "version", "hash"})
+	_metricLastReloadSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_last_reload_success_timestamp_seconds",
+		Help:      "Timestamp of the last successful config reload",
+	}, []string{"source"})
+)
+
+func init() {
+	metrics.Register(_metricReloadTotal)
+	metrics.Register(_metricConfigInfo)
+	metrics.Register(_metricLastReloadSuccess)
+}
+
+// ObserveReload records the result of a config reload from the source, hash is the Hash of the config so the
+// config_info of the sources, the logs and the audit records agree on the same config.
+func ObserveReload(source, version, hash string, err error) {
+	if err != nil {
+		_metricReloadTotal.WithLabelValues(source, "failure").Inc()
+		return
+	}
+	_metricReloadTotal.WithLabelValues(source, "success").Inc()
+	_metricLastReloadSuccess.WithLabelValues(source).Set(float64(time.Now().Unix()))
+	_metricConfigInfo.DeletePartialMatch(prometheus.Labels{"source": source})
+	_metricConfigInfo.WithLabelValues(source, version, hash).Set(1)
+}
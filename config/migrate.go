@@ -0,0 +1,222 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Migration transforms the gateway config of a schema version into the next version. The transforms work on
+// the YAML document instead of the protos, so the fields removed or reshaped by the new schema are still
+// readable and the comments of the document are kept.
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Transform   func(doc *MigrationDoc)
+}
+
+var _migrations = struct {
+	lock   sync.RWMutex
+	byFrom map[string]*Migration
+}{byFrom: map[string]*Migration{}}
+
+// RegisterMigration registers the migration of a schema version, it panics if the version has one already.
+func RegisterMigration(m Migration) {
+	_migrations.lock.Lock()
+	defer _migrations.lock.Unlock()
+	if _, ok := _migrations.byFrom[m.From]; ok {
+		panic(fmt.Sprintf("config migration from %s is already registered", m.From))
+	}
+	_migrations.byFrom[m.From] = &m
+}
+
+// LatestSchemaVersion returns the schema version reached by applying all the migrations from v1.
+func LatestSchemaVersion() string {
+	_migrations.lock.RLock()
+	defer _migrations.lock.RUnlock()
+	version := "v1"
+	for i := 0; i <= len(_migrations.byFrom); i++ {
+		m, ok := _migrations.byFrom[version]
+		if !ok {
+			break
+		}
+		version = m.To
+	}
+	return version
+}
+
+func migrationPath(from, to string) ([]*Migration, error) {
+	_migrations.lock.RLock()
+	defer _migrations.lock.RUnlock()
+	var path []*Migration
+	for version := from; version != to; {
+		m, ok := _migrations.byFrom[version]
+		if !ok || len(path) > len(_migrations.byFrom) {
+			return nil, fmt.Errorf("no config migration from %s to %s", from, to)
+		}
+		path = append(path, m)
+		version = m.To
+	}
+	return path, nil
+}
+
+// MigrationNote is a change made by a migration or a field left to migrate by hand.
+type MigrationNote struct {
+	Version string `json:"version"`
+	Line    int    `json:"line"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (n *MigrationNote) String() string {
+	return fmt.Sprintf("[%s] line %d: %s: %s", n.Version, n.Line, n.Path, n.Message)
+}
+
+// MigrationReport is the result of a migration.
+type MigrationReport struct {
+	Changes []*MigrationNote `json:"changes"`
+	// Manual is the fields not migrated automatically, which are left as they are.
+	Manual []*MigrationNote `json:"manual"`
+	// Errors is the errors of the migrated config reported by the same checks as loading the config.
+	Errors ConfigErrors `json:"errors"`
+}
+
+// MigrationDoc is the YAML document of the gateway config passed to the transforms.
+type MigrationDoc struct {
+	root    *yamlv3.Node
+	version string
+	report  *MigrationReport
+}
+
+// Root returns the mapping node of the gateway config.
+func (d *MigrationDoc) Root() *yamlv3.Node { return d.root }
+
+// Changef records a change made by the transform.
+func (d *MigrationDoc) Changef(n *yamlv3.Node, path, format string, args ...any) {
+	d.report.Changes = append(d.report.Changes, d.note(n, path, format, args...))
+}
+
+// Manualf records a field the transform is not able to migrate.
+func (d *MigrationDoc) Manualf(n *yamlv3.Node, path, format string, args ...any) {
+	d.report.Manual = append(d.report.Manual, d.note(n, path, format, args...))
+}
+
+func (d *MigrationDoc) note(n *yamlv3.Node, path, format string, args ...any) *MigrationNote {
+	note := &MigrationNote{Version: d.version, Path: path, Message: fmt.Sprintf(format, args...)}
+	if n != nil {
+		note.Line = n.Line
+	}
+	return note
+}
+
+// MappingGet returns the key and the value nodes of the field of the mapping node, the key is matched by
+// the JSON or the proto name, eg: dnsCacheTtl or dns_cache_ttl.
+func MappingGet(m *yamlv3.Node, name string) (key, value *yamlv3.Node) {
+	if m == nil || m.Kind != yamlv3.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if fieldNameEqual(m.Content[i].Value, name) {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// MappingDelete removes the field of the mapping node.
+func MappingDelete(m *yamlv3.Node, name string) {
+	if m == nil || m.Kind != yamlv3.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if fieldNameEqual(m.Content[i].Value, name) {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// MappingSet sets the field of the mapping node, the field is appended if it does not exist.
+func MappingSet(m *yamlv3.Node, name string, value *yamlv3.Node) {
+	if _, v := MappingGet(m, name); v != nil {
+		*v = *value
+		return
+	}
+	m.Content = append(m.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: name}, value)
+}
+
+func fieldNameEqual(key, name string) bool {
+	return strings.ReplaceAll(strings.ToLower(key), "_", "") == strings.ReplaceAll(strings.ToLower(name), "_", "")
+}
+
+// Migrate migrates the YAML gateway config from the schema version to the other, the migrated config is
+// checked with the same checks as `gateway check` and the errors are returned in the report.
+func Migrate(data []byte, from, to string) ([]byte, *MigrationReport, error) {
+	path, err := migrationPath(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yamlv3.MappingNode {
+		return nil, nil, fmt.Errorf("expected the gateway config object")
+	}
+	report := &MigrationReport{}
+	for _, m := range path {
+		m.Transform(&MigrationDoc{root: doc.Content[0], version: m.To, report: report})
+	}
+	sort.SliceStable(report.Manual, func(i, j int) bool { return report.Manual[i].Line < report.Manual[j].Line })
+	var out bytes.Buffer
+	encoder := yamlv3.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	report.Errors, err = ValidateYAML(out.Bytes(), &configv1.Gateway{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.Bytes(), report, nil
+}
+
+func init() {
+	RegisterMigration(Migration{
+		From:        "v1",
+		To:          "v1.1",
+		Description: "replaces dns_cache_ttl with resolver and reports the deprecated hosts",
+		Transform:   migrateV1_1,
+	})
+}
+
+func migrateV1_1(doc *MigrationDoc) {
+	root := doc.Root()
+	if key, ttl := MappingGet(root, "dns_cache_ttl"); ttl != nil {
+		if _, resolver := MappingGet(root, "resolver"); resolver != nil {
+			doc.Changef(key, key.Value, "removed, it is overridden by resolver")
+			MappingDelete(root, "dns_cache_ttl")
+		} else {
+			doc.Changef(key, key.Value, "moved to resolver.min_ttl and resolver.max_ttl")
+			// the system lookups were cached for the ttl regardless of the records, the field is replaced in
+			// place to keep its comments
+			key.Value = "resolver"
+			*ttl = yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map", Content: []*yamlv3.Node{
+				{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: "min_ttl"}, {Kind: yamlv3.ScalarNode, Tag: "!!str", Value: ttl.Value},
+				{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: "max_ttl"}, {Kind: yamlv3.ScalarNode, Tag: "!!str", Value: ttl.Value},
+			}}
+		}
+	}
+	if key, hosts := MappingGet(root, "hosts"); hosts != nil && len(hosts.Content) > 0 {
+		doc.Manualf(key, key.Value, "deprecated and ignored, set host of the endpoints or use host_groups")
+	}
+}
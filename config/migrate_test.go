@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+)
+
+func TestMigrateV1_1(t *testing.T) {
+	data := []byte(`name: demo
+# cache the lookups
+dns_cache_ttl: 30s
+hosts: [a.example.com]
+endpoints:
+  - path: /api/*
+    protocol: HTTP
+`)
+	out, report, err := Migrate(data, "v1", LatestSchemaVersion())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Line != 3 || report.Changes[0].Path != "dns_cache_ttl" {
+		t.Fatalf("unexpected changes %v", report.Changes)
+	}
+	if len(report.Manual) != 1 || report.Manual[0].Path != "hosts" {
+		t.Fatalf("want the hosts to migrate by hand but got %v", report.Manual)
+	}
+	if len(report.Errors) > 0 {
+		t.Fatalf("want the migrated config valid but got %v", report.Errors)
+	}
+	if !strings.Contains(string(out), "# cache the lookups\nresolver:") {
+		t.Fatalf("want the comment kept but got:\n%s", out)
+	}
+	jsonData, err := yaml.YAMLToJSON(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &configv1.Gateway{}
+	if err := protojson.Unmarshal(jsonData, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.DnsCacheTtl != nil || c.Resolver.GetMinTtl().AsDuration().String() != "30s" || c.Resolver.GetMaxTtl().AsDuration().String() != "30s" {
+		t.Fatalf("unexpected resolver %v", c.Resolver)
+	}
+
+	// the resolver takes precedence over the ttl
+	out, _, err = Migrate([]byte("dnsCacheTtl: 30s\nresolver:\n  max_ttl: 1m\n"), "v1", "v1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "resolver:\n  max_ttl: 1m\n" {
+		t.Fatalf("unexpected config:\n%s", out)
+	}
+
+	if _, _, err := Migrate(data, "v1.1", "v1"); err == nil {
+		t.Fatal("want the unknown migration rejected")
+	}
+}
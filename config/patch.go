@@ -0,0 +1,380 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	// MergePatchContentType is the media type of the RFC 7386 JSON Merge Patch.
+	MergePatchContentType = "application/merge-patch+json"
+	// JSONPatchContentType is the media type of the RFC 6902 JSON Patch.
+	JSONPatchContentType = "application/json-patch+json"
+)
+
+// ErrPatchTest is returned if a test operation of the JSON Patch fails.
+var ErrPatchTest = errors.New("json patch test failed")
+
+// ApplyPatch applies the patch of the media type to the JSON document, the control service applies the
+// patches of the configs and the features by it before validating the result by CheckConfig.
+func ApplyPatch(doc, patch []byte, contentType string) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	switch mediaType {
+	case MergePatchContentType:
+		return MergePatch(doc, patch)
+	case JSONPatchContentType:
+		return JSONPatch(doc, patch)
+	}
+	return nil, fmt.Errorf("unsupported patch content type %q, expected %s or %s", mediaType, MergePatchContentType, JSONPatchContentType)
+}
+
+// MergePatch applies the RFC 7386 JSON Merge Patch to the JSON document: the members of the patch object
+// replace the members of the document, recursively for the objects, and the null members remove them. The
+// arrays are replaced as a whole, eg: adding an origin sends the full list of the origins.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	var target, p any
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if err := decodeJSON(doc, &target); err != nil {
+			return nil, fmt.Errorf("invalid document: %w", err)
+		}
+	}
+	if err := decodeJSON(patch, &p); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return json.Marshal(mergePatch(target, p))
+}
+
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
+
+// jsonPatchOperation is an operation of the RFC 6902 JSON Patch.
+type jsonPatchOperation struct {
+	Op    string           `json:"op"`
+	Path  string           `json:"path"`
+	From  string           `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// JSONPatch applies the RFC 6902 JSON Patch to the JSON document, the operations are applied in order and
+// the document is unchanged if any fails.
+func JSONPatch(doc, patch []byte) ([]byte, error) {
+	var ops []jsonPatchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid json patch: %w", err)
+	}
+	var root any
+	if err := decodeJSON(doc, &root); err != nil {
+		return nil, fmt.Errorf("invalid document: %w", err)
+	}
+	for i, op := range ops {
+		var err error
+		if root, err = applyOperation(root, op); err != nil {
+			return nil, fmt.Errorf("operation %d %s %s: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+func applyOperation(root any, op jsonPatchOperation) (any, error) {
+	value := func() (any, error) {
+		if op.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		var v any
+		return v, decodeJSON(*op.Value, &v)
+	}
+	switch op.Op {
+	case "add":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, op.Path, v)
+	case "remove":
+		root, _, err := pointerRemove(root, op.Path)
+		return root, err
+	case "replace":
+		v, err := value()
+		if err != nil {
+			return nil, err
+		}
+		if root, _, err = pointerRemove(root, op.Path); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, op.Path, v)
+	case "move":
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, errors.New("cannot move a value into itself")
+		}
+		root, v, err := pointerRemove(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, op.Path, v)
+	case "copy":
+		v, err := pointerGet(root, op.From)
+		if err != nil {
+			return nil, err
+		}
+		// the copy must not share the objects and the arrays with the source
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var copied any
+		if err := decodeJSON(b, &copied); err != nil {
+			return nil, err
+		}
+		return pointerAdd(root, op.Path, copied)
+	case "test":
+		want, err := value()
+		if err != nil {
+			return nil, err
+		}
+		got, err := pointerGet(root, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonEqual(got, want) {
+			return nil, ErrPatchTest
+		}
+		return root, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// parsePointer returns the reference tokens of the RFC 6901 JSON Pointer.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func arrayIndex(token string, n int, appending bool) (int, error) {
+	if appending && token == "-" {
+		return n, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := n - 1
+	if appending {
+		max = n
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+// pointerParent returns the container of the last token of the pointer.
+func pointerParent(root any, pointer string) (any, string, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tokens) == 0 {
+		return nil, "", nil
+	}
+	parent, err := walkPointer(root, tokens[:len(tokens)-1])
+	return parent, tokens[len(tokens)-1], err
+}
+
+func walkPointer(v any, tokens []string) (any, error) {
+	for _, t := range tokens {
+		switch c := v.(type) {
+		case map[string]any:
+			next, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", t)
+			}
+			v = next
+		case []any:
+			i, err := arrayIndex(t, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			v = c[i]
+		default:
+			return nil, fmt.Errorf("cannot index %q of a scalar", t)
+		}
+	}
+	return v, nil
+}
+
+func pointerGet(root any, pointer string) (any, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	return walkPointer(root, tokens)
+}
+
+// setChild replaces the container of the token in its parent, the arrays are reallocated by the inserts.
+func setChild(root any, pointer string, child any) (any, error) {
+	tokens, _ := parsePointer(pointer)
+	if len(tokens) <= 1 {
+		return child, nil
+	}
+	grand, err := walkPointer(root, tokens[:len(tokens)-2])
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-2]
+	switch g := grand.(type) {
+	case map[string]any:
+		g[last] = child
+	case []any:
+		i, err := arrayIndex(last, len(g), false)
+		if err != nil {
+			return nil, err
+		}
+		g[i] = child
+	}
+	return root, nil
+}
+
+func pointerAdd(root any, pointer string, value any) (any, error) {
+	parent, token, err := pointerParent(root, pointer)
+	if err != nil {
+		return nil, err
+	}
+	if pointer == "" {
+		return value, nil
+	}
+	switch p := parent.(type) {
+	case map[string]any:
+		p[token] = value
+		return root, nil
+	case []any:
+		i, err := arrayIndex(token, len(p), true)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p[:i], append([]any{value}, p[i:]...)...)
+		return setChild(root, pointer, p)
+	}
+	return nil, fmt.Errorf("cannot add %q to a scalar", token)
+}
+
+func pointerRemove(root any, pointer string) (any, any, error) {
+	parent, token, err := pointerParent(root, pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pointer == "" {
+		return nil, root, nil
+	}
+	switch p := parent.(type) {
+	case map[string]any:
+		v, ok := p[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("member %q not found", token)
+		}
+		delete(p, token)
+		return root, v, nil
+	case []any:
+		i, err := arrayIndex(token, len(p), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := p[i]
+		p = append(p[:i:i], p[i+1:]...)
+		root, err = setChild(root, pointer, p)
+		return root, v, err
+	}
+	return nil, nil, fmt.Errorf("cannot remove %q of a scalar", token)
+}
+
+// jsonEqual compares the decoded JSON values, the numbers are compared by their values.
+func jsonEqual(a, b any) bool {
+	if na, ok := a.(json.Number); ok {
+		nb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fa, erra := na.Float64()
+		fb, errb := nb.Float64()
+		return erra == nil && errb == nil && fa == fb
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !jsonEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// CheckConfig validates the JSON or YAML gateway config with the checks of `gateway check`, eg: the result of
+// a patch before the control service releases it as a new version. The unknown fields are errors.
+func CheckConfig(data []byte) error {
+	c := &configv1.Gateway{}
+	errs, err := ValidateYAML(data, c)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if err := decodeYAML("config", data, c); err != nil {
+		return err
+	}
+	return ExpandServices(proto.Clone(c).(*configv1.Gateway))
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func jsonEq(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Fatal(err)
+	}
+	gb, _ := json.Marshal(g)
+	wb, _ := json.Marshal(w)
+	if string(gb) != string(wb) {
+		t.Fatalf("want %s but got %s", wb, gb)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// the examples of the appendix A of RFC 7386
+	for _, c := range []struct{ doc, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		got, err := MergePatch([]byte(c.doc), []byte(c.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonEq(t, got, c.want)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	doc := `{"name":"gw","endpoints":[{"path":"/a","timeout":"1s"},{"path":"/b"}],"big":12345678901234567890}`
+	got, err := JSONPatch([]byte(doc), []byte(`[
+		{"op":"test","path":"/endpoints/0/path","value":"/a"},
+		{"op":"replace","path":"/endpoints/0/timeout","value":"5s"},
+		{"op":"add","path":"/endpoints/-","value":{"path":"/c"}},
+		{"op":"add","path":"/endpoints/1","value":{"path":"/a~1b"}},
+		{"op":"remove","path":"/endpoints/2"},
+		{"op":"copy","from":"/name","path":"/alias"},
+		{"op":"move","from":"/alias","path":"/renamed"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonEq(t, got, `{"name":"gw","renamed":"gw","endpoints":[{"path":"/a","timeout":"5s"},{"path":"/a~1b"},{"path":"/c"}],"big":12345678901234567890}`)
+
+	for _, patch := range []string{
+		`[{"op":"test","path":"/name","value":"other"}]`,
+		`[{"op":"remove","path":"/missing"}]`,
+		`[{"op":"replace","path":"/endpoints/5","value":{}}]`,
+		`[{"op":"move","from":"/endpoints","path":"/endpoints/0"}]`,
+		`[{"op":"unknown","path":"/name"}]`,
+	} {
+		if _, err := JSONPatch([]byte(doc), []byte(patch)); err == nil {
+			t.Fatalf("want the patch %s rejected", patch)
+		}
+	}
+	if _, err := JSONPatch([]byte(doc), []byte(`[{"op":"test","path":"/name","value":"other"}]`)); !errors.Is(err, ErrPatchTest) {
+		t.Fatalf("want the failed test but got %v", err)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	got, err := ApplyPatch([]byte(`{"name":"gw"}`), []byte(`{"version":"v2"}`), MergePatchContentType+"; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonEq(t, got, `{"name":"gw","version":"v2"}`)
+	if _, err := ApplyPatch([]byte(`{}`), []byte(`{}`), "application/json"); err == nil {
+		t.Fatal("want the unsupported content type rejected")
+	}
+	if err := CheckConfig(got); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckConfig([]byte(`{"name":"gw","endpoints":[{"path":"/a","timeout":"soon"}]}`)); err == nil {
+		t.Fatal("want the invalid config rejected")
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sigs.k8s.io/yaml"
+)
+
+var _metricPriorityConfigExpired = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "priority_config_expired",
+	Help:      "The priority configs which are present but no longer applied since the ttl has elapsed",
+}, []string{"file", "name"})
+
+func init() {
+	metrics.Register(_metricPriorityConfigExpired)
+}
+
+// PriorityOverlay is a priority config merged into the gateway config.
+type PriorityOverlay struct {
+	File      string    `json:"file"`
+	Name      string    `json:"name"`
+	Version   string    `json:"version"`
+	Endpoints []string  `json:"endpoints"`
+	ExpireAt  time.Time `json:"expireAt,omitempty"`
+	// Remaining is the remaining ttl, empty if the priority config has no ttl.
+	Remaining string `json:"remaining,omitempty"`
+	Expired   bool   `json:"expired"`
+}
+
+func newPriorityOverlay(cfgPath string, modTime time.Time, pCfg *configv1.PriorityConfig) *PriorityOverlay {
+	overlay := &PriorityOverlay{
+		File:    filepath.Base(cfgPath),
+		Name:    pCfg.Name,
+		Version: pCfg.Version,
+	}
+	for _, e := range pCfg.Endpoints {
+		overlay.Endpoints = append(overlay.Endpoints, strings.TrimSpace(e.Method+" "+e.Path))
+	}
+	if pCfg.Ttl != nil {
+		overlay.ExpireAt = modTime.Add(pCfg.Ttl.AsDuration())
+		overlay.Expired = !time.Now().Before(overlay.ExpireAt)
+	}
+	return overlay
+}
+
+// withPriorityRollout returns the endpoint of the priority config carrying the rollout and the endpoint it replaces,
+// the rollout starts at the time the file was written if the start time is not set.
+func withPriorityRollout(endpoints []*configv1.Endpoint, overlay *PriorityOverlay, modTime time.Time, rollout *configv1.Rollout, e *configv1.Endpoint) *configv1.Endpoint {
+	rollout = proto.Clone(rollout).(*configv1.Rollout)
+	if rollout.StartTime == nil {
+		rollout.StartTime = timestamppb.New(modTime)
+	}
+	name := overlay.Name
+	if name == "" {
+		name = strings.TrimSuffix(overlay.File, ".yaml")
+	}
+	e = proto.Clone(e).(*configv1.Endpoint)
+	e.PriorityRollout = &configv1.PriorityRollout{Name: name, Rollout: rollout}
+	for _, baseline := range endpoints {
+		if baseline.Method == e.Method && baseline.Path == e.Path {
+			e.PriorityRollout.Baseline = baseline
+			break
+		}
+	}
+	return e
+}
+
+func (o *PriorityOverlay) withRemaining(now time.Time) *PriorityOverlay {
+	out := *o
+	if !o.ExpireAt.IsZero() && !o.Expired {
+		out.Remaining = o.ExpireAt.Sub(now).Truncate(time.Second).String()
+	}
+	return &out
+}
+
+// nextExpiry returns the earliest expiry of the applied overlays.
+func nextExpiry(overlays []*PriorityOverlay) time.Time {
+	var next time.Time
+	for _, o := range overlays {
+		if o.ExpireAt.IsZero() || o.Expired {
+			continue
+		}
+		if next.IsZero() || o.ExpireAt.Before(next) {
+			next = o.ExpireAt
+		}
+	}
+	return next
+}
+
+func observePriorityOverlays(overlays []*PriorityOverlay) {
+	_metricPriorityConfigExpired.Reset()
+	for _, o := range overlays {
+		if o.Expired {
+			_metricPriorityConfigExpired.WithLabelValues(o.File, o.Name).Set(1)
+		}
+	}
+}
+
+// Promote merges the named priority config into the gateway config and removes the priority config,
+// the name is the file name without the .yaml extension or the name of the priority config.
+func Promote(confPath, priorityDirectory, name string) (*PriorityOverlay, error) {
+	cfgPath, pCfg, err := findPriorityConfig(priorityDirectory, name)
+	if err != nil {
+		return nil, err
+	}
+	configData, err := os.ReadFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+	base := &configv1.Gateway{}
+	if err := decodeYAML(confPath, configData, base); err != nil {
+		return nil, err
+	}
+	replaceOrPrependEndpoint := MakeReplaceOrPrependEndpointFn(base.Endpoints)
+	for _, e := range pCfg.Endpoints {
+		base.Endpoints = replaceOrPrependEndpoint(base.Endpoints, e)
+	}
+	jsonData, err := protojson.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	yamlData, err := yaml.JSONToYAML(jsonData)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(confPath, yamlData); err != nil {
+		return nil, err
+	}
+	// the overlay is removed after the base config is written, so the endpoints are never missing.
+	if err := os.Remove(cfgPath); err != nil {
+		return nil, fmt.Errorf("promoted %s but failed to remove it: %w", cfgPath, err)
+	}
+	return newPriorityOverlay(cfgPath, time.Now(), pCfg), nil
+}
+
+func findPriorityConfig(priorityDirectory, name string) (string, *configv1.PriorityConfig, error) {
+	if priorityDirectory == "" {
+		return "", nil, fmt.Errorf("priority config directory is not set")
+	}
+	paths, err := filepath.Glob(filepath.Join(priorityDirectory, "*.yaml"))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, cfgPath := range paths {
+		configData, err := os.ReadFile(cfgPath)
+		if err != nil {
+			return "", nil, err
+		}
+		pCfg := &configv1.PriorityConfig{}
+		if err := decodeYAML(cfgPath, configData, pCfg); err != nil {
+			return "", nil, err
+		}
+		if strings.TrimSuffix(filepath.Base(cfgPath), ".yaml") == name || pCfg.Name == name {
+			return cfgPath, pCfg, nil
+		}
+	}
+	return "", nil, fmt.Errorf("priority config %q not found in %s", name, priorityDirectory)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil {
+		if err := os.Chmod(tmp.Name(), info.Mode()); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const _testBaseConfig = `name: helloworld
+endpoints:
+  - path: /helloworld/*
+    protocol: HTTP
+    backends:
+      - target: 127.0.0.1:8000
+`
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPriorityConfigTTL(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "config.yaml")
+	priorityDir := filepath.Join(dir, "priority")
+	writeTestFile(t, confPath, _testBaseConfig)
+	fl, err := NewFileLoader(confPath, priorityDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fl.Close()
+	writeTestFile(t, filepath.Join(priorityDir, "canary.yaml"), `name: canary
+version: v2
+ttl: 3600s
+endpoints:
+  - path: /canary/*
+    backends:
+      - target: 127.0.0.1:8001
+`)
+	expiredPath := filepath.Join(priorityDir, "forgotten.yaml")
+	writeTestFile(t, expiredPath, `name: forgotten
+ttl: 60s
+endpoints:
+  - path: /forgotten/*
+    backends:
+      - target: 127.0.0.1:8002
+`)
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(expiredPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := fl.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, e := range c.Endpoints {
+		paths = append(paths, e.Path)
+	}
+	if len(paths) != 2 || paths[0] != "/canary/*" || paths[1] != "/helloworld/*" {
+		t.Fatalf("unexpected endpoints: %v", paths)
+	}
+	overlays := fl.Overlays()
+	if len(overlays) != 2 {
+		t.Fatalf("unexpected overlays: %+v", overlays)
+	}
+	canary, forgotten := overlays[0], overlays[1]
+	if canary.Expired || canary.Remaining == "" || len(canary.Endpoints) != 1 || canary.Endpoints[0] != "/canary/*" {
+		t.Fatalf("unexpected canary overlay: %+v", canary)
+	}
+	if !forgotten.Expired || forgotten.Remaining != "" {
+		t.Fatalf("unexpected expired overlay: %+v", forgotten)
+	}
+}
+
+func TestPromote(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "config.yaml")
+	priorityDir := filepath.Join(dir, "priority")
+	if err := os.MkdirAll(priorityDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, confPath, _testBaseConfig)
+	overlayPath := filepath.Join(priorityDir, "canary.yaml")
+	writeTestFile(t, overlayPath, `name: canary
+endpoints:
+  - path: /helloworld/*
+    protocol: HTTP
+    backends:
+      - target: 127.0.0.1:8001
+`)
+	if _, err := Promote(confPath, priorityDir, "missing"); err == nil {
+		t.Fatal("want error with missing priority config")
+	}
+	overlay, err := Promote(confPath, priorityDir, "canary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overlay.File != "canary.yaml" {
+		t.Fatalf("unexpected overlay: %+v", overlay)
+	}
+	if _, err := os.Stat(overlayPath); !os.IsNotExist(err) {
+		t.Fatalf("want overlay removed but got: %v", err)
+	}
+	data, err := os.ReadFile(confPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &configv1.Gateway{}
+	if err := decodeYAML(confPath, data, c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "helloworld" || len(c.Endpoints) != 1 || c.Endpoints[0].Backends[0].Target != "127.0.0.1:8001" {
+		t.Fatalf("unexpected promoted config: %+v", c)
+	}
+}
+
+func TestPriorityConfigRollout(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "config.yaml")
+	priorityDir := filepath.Join(dir, "priority")
+	writeTestFile(t, confPath, _testBaseConfig)
+	fl, err := NewFileLoader(confPath, priorityDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fl.Close()
+	rolloutPath := filepath.Join(priorityDir, "canary.yaml")
+	writeTestFile(t, rolloutPath, `name: canary
+rollout:
+  ramp_duration: 3600s
+  hash_key: header:X-User-Id
+endpoints:
+  - path: /helloworld/*
+    protocol: HTTP
+    backends:
+      - target: 127.0.0.1:8001
+`)
+	info, err := os.Stat(rolloutPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := fl.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Endpoints) != 1 {
+		t.Fatalf("want the endpoint replaced but got %v", c.Endpoints)
+	}
+	r := c.Endpoints[0].PriorityRollout
+	if r == nil || r.Name != "canary" || r.Rollout.HashKey != "header:X-User-Id" || !r.Rollout.StartTime.AsTime().Equal(info.ModTime()) {
+		t.Fatalf("unexpected rollout: %v", r)
+	}
+	if r.Baseline.GetBackends()[0].GetTarget() != "127.0.0.1:8000" {
+		t.Fatalf("want the replaced endpoint kept as the baseline but got %v", r.Baseline)
+	}
+}
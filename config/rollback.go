@@ -0,0 +1,55 @@
+package config
+
+import (
+	"sync"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// the versions and digests of the configs rolled back by the gateway
+var _badConfigs sync.Map
+
+// MarkBad marks the config rolled back for the error spike it caused, the config is not applied again by the
+// loaders until a newer one is published.
+func MarkBad(c *configv1.Gateway) {
+	if c.Version != "" {
+		_badConfigs.Store("version:"+c.Version, struct{}{})
+	}
+	_badConfigs.Store("digest:"+digest(c), struct{}{})
+}
+
+// MarkBadVersion marks the config version rolled back, eg: the rollback recorded by the snapshot of the control
+// service loader before the restart.
+func MarkBadVersion(version string) {
+	if version != "" {
+		_badConfigs.Store("version:"+version, struct{}{})
+	}
+}
+
+// IsBad returns whether the content of the config is marked bad, the version is ignored since the edited config
+// files often keep it, eg: a fixed config is applied even if its version is the same as the rolled back one.
+func IsBad(c *configv1.Gateway) bool {
+	_, ok := _badConfigs.Load("digest:" + digest(c))
+	return ok
+}
+
+// IsBadVersion returns whether the config version is marked bad, the versions of the control service are unique
+// per release.
+func IsBadVersion(version string) bool {
+	if version == "" {
+		return false
+	}
+	_, ok := _badConfigs.Load("version:" + version)
+	return ok
+}
+
+// digest returns the sha256 of the deterministic encoding of the config, the key of the rolled back configs only,
+// the configs are identified by Hash elsewhere.
+func digest(c *configv1.Gateway) string {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return sha256sum(b)
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestMarkBad(t *testing.T) {
+	newConfig := func(target string) *configv1.Gateway {
+		return &configv1.Gateway{Name: "test", Version: "rollback-v1", Endpoints: []*configv1.Endpoint{{
+			Path:     "/api/*",
+			Method:   "GET",
+			Backends: []*configv1.Backend{{Target: target}},
+		}}}
+	}
+	bad := newConfig("127.0.0.1:8000")
+	MarkBad(bad)
+	if !IsBad(newConfig("127.0.0.1:8000")) {
+		t.Fatal("want the config with the same content bad")
+	}
+	// the fixed config file keeps the version
+	if IsBad(newConfig("127.0.0.1:9000")) {
+		t.Fatal("want the fixed config with the same version applied")
+	}
+	if !IsBadVersion(bad.Version) || IsBadVersion("") {
+		t.Fatal("want the version of the control service marked bad")
+	}
+}
@@ -0,0 +1,360 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigError is an error of a node in the YAML config.
+type ConfigError struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	// Unknown is the field not defined in the schema, which is discarded on loading.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("line %d column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// ConfigErrors are all the errors of the YAML config.
+type ConfigErrors []*ConfigError
+
+func (es ConfigErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Split returns the unknown fields and the other errors.
+func (es ConfigErrors) Split() (unknown, invalid ConfigErrors) {
+	for _, e := range es {
+		if e.Unknown {
+			unknown = append(unknown, e)
+		} else {
+			invalid = append(invalid, e)
+		}
+	}
+	return unknown, invalid
+}
+
+// ValidateYAML checks the YAML against the schema of msg and reports every offending node with its position.
+func ValidateYAML(data []byte, msg proto.Message) (ConfigErrors, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	v := &yamlValidator{}
+	if len(doc.Content) > 0 {
+		v.message(doc.Content[0], msg.ProtoReflect().Descriptor(), "")
+	}
+	return v.errs, nil
+}
+
+type yamlValidator struct {
+	errs ConfigErrors
+}
+
+func (v *yamlValidator) errorf(n *yamlv3.Node, path string, format string, args ...any) {
+	v.errs = append(v.errs, &ConfigError{Line: n.Line, Column: n.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func resolveAlias(n *yamlv3.Node) *yamlv3.Node {
+	for n.Kind == yamlv3.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+func isNull(n *yamlv3.Node) bool {
+	return n.Kind == yamlv3.ScalarNode && n.Tag == "!!null"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func lookupField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByJSONName(name); fd != nil {
+		return fd
+	}
+	return md.Fields().ByTextName(name)
+}
+
+func (v *yamlValidator) message(n *yamlv3.Node, md protoreflect.MessageDescriptor, path string) {
+	n = resolveAlias(n)
+	if isNull(n) {
+		return
+	}
+	if v.wellKnown(n, md, path) {
+		return
+	}
+	if n.Kind != yamlv3.MappingNode {
+		v.errorf(n, path, "expected %s object", md.Name())
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Value == "<<" {
+			v.message(value, md, path)
+			continue
+		}
+		fieldPath := joinPath(path, key.Value)
+		fd := lookupField(md, key.Value)
+		if fd == nil {
+			v.errs = append(v.errs, &ConfigError{
+				Line:    key.Line,
+				Column:  key.Column,
+				Path:    fieldPath,
+				Message: fmt.Sprintf("unknown field %q of %s", key.Value, md.FullName()),
+				Unknown: true,
+			})
+			continue
+		}
+		v.field(value, fd, fieldPath)
+	}
+}
+
+func (v *yamlValidator) field(n *yamlv3.Node, fd protoreflect.FieldDescriptor, path string) {
+	n = resolveAlias(n)
+	if isNull(n) {
+		return
+	}
+	switch {
+	case fd.IsMap():
+		if n.Kind != yamlv3.MappingNode {
+			v.errorf(n, path, "expected map")
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v.singular(n.Content[i+1], fd.MapValue(), joinPath(path, n.Content[i].Value))
+		}
+	case fd.IsList():
+		if n.Kind != yamlv3.SequenceNode {
+			v.errorf(n, path, "expected list")
+			return
+		}
+		for i, item := range n.Content {
+			v.singular(item, fd, fmt.Sprintf("%s[%d]", path, i))
+		}
+	default:
+		v.singular(n, fd, path)
+	}
+}
+
+func (v *yamlValidator) singular(n *yamlv3.Node, fd protoreflect.FieldDescriptor, path string) {
+	n = resolveAlias(n)
+	if isNull(n) {
+		return
+	}
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		v.message(n, fd.Message(), path)
+		return
+	}
+	if n.Kind != yamlv3.ScalarNode {
+		v.errorf(n, path, "expected %s", fd.Kind())
+		return
+	}
+	if err := checkScalar(n.Value, fd); err != nil {
+		v.errorf(n, path, "%v", err)
+	}
+}
+
+var _durationPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?s$`)
+
+// wellKnown checks the well known types which have the special JSON mapping.
+func (v *yamlValidator) wellKnown(n *yamlv3.Node, md protoreflect.MessageDescriptor, path string) bool {
+	switch md.FullName() {
+	case "google.protobuf.Duration":
+		if n.Kind != yamlv3.ScalarNode || !_durationPattern.MatchString(n.Value) {
+			v.errorf(n, path, "invalid duration %q, expected seconds with the s suffix, eg: 1.5s", n.Value)
+		}
+		return true
+	case "google.protobuf.Timestamp", "google.protobuf.FieldMask":
+		if n.Kind != yamlv3.ScalarNode {
+			v.errorf(n, path, "expected string")
+		}
+		return true
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue", "google.protobuf.Empty":
+		return true
+	case "google.protobuf.Any":
+		v.any(n, path)
+		return true
+	}
+	if md.ParentFile() != nil && md.ParentFile().Package() == "google.protobuf" && strings.HasSuffix(string(md.Name()), "Value") {
+		// wrappers are represented as the wrapped scalar.
+		if n.Kind != yamlv3.ScalarNode {
+			v.errorf(n, path, "expected %s", md.Name())
+			return true
+		}
+		if err := checkScalar(n.Value, md.Fields().ByName("value")); err != nil {
+			v.errorf(n, path, "%v", err)
+		}
+		return true
+	}
+	return false
+}
+
+// any checks the fields of the resolved type, such as the options of the middlewares.
+func (v *yamlValidator) any(n *yamlv3.Node, path string) {
+	if n.Kind != yamlv3.MappingNode {
+		v.errorf(n, path, "expected object with @type")
+		return
+	}
+	var typeURL *yamlv3.Node
+	rest := &yamlv3.Node{Kind: yamlv3.MappingNode, Line: n.Line, Column: n.Column}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "@type" {
+			typeURL = n.Content[i+1]
+			continue
+		}
+		rest.Content = append(rest.Content, n.Content[i], n.Content[i+1])
+	}
+	if typeURL == nil {
+		v.errorf(n, path, "missing @type")
+		return
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByURL(typeURL.Value)
+	if err != nil {
+		v.errorf(typeURL, joinPath(path, "@type"), "unknown type %q", typeURL.Value)
+		return
+	}
+	md := mt.Descriptor()
+	if value := lookupAnyValue(rest); value != nil && isWellKnownJSON(md) {
+		v.message(value, md, joinPath(path, "value"))
+		return
+	}
+	v.message(rest, md, path)
+}
+
+func lookupAnyValue(n *yamlv3.Node) *yamlv3.Node {
+	if len(n.Content) == 2 && n.Content[0].Value == "value" {
+		return n.Content[1]
+	}
+	return nil
+}
+
+func isWellKnownJSON(md protoreflect.MessageDescriptor) bool {
+	return md.ParentFile() != nil && md.ParentFile().Package() == "google.protobuf"
+}
+
+func checkScalar(s string, fd protoreflect.FieldDescriptor) error {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		switch strings.ToLower(s) {
+		case "y", "yes", "on", "n", "no", "off":
+			// YAML 1.1 booleans
+			return nil
+		}
+		if _, err := strconv.ParseBool(s); err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		if _, err := strconv.ParseInt(s, 0, 32); err != nil {
+			return fmt.Errorf("invalid int32 %q", s)
+		}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		if _, err := strconv.ParseInt(s, 0, 64); err != nil {
+			return fmt.Errorf("invalid int64 %q", s)
+		}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		if _, err := strconv.ParseUint(s, 0, 32); err != nil {
+			return fmt.Errorf("invalid uint32 %q", s)
+		}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		if _, err := strconv.ParseUint(s, 0, 64); err != nil {
+			return fmt.Errorf("invalid uint64 %q", s)
+		}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		switch s {
+		case "NaN", "Infinity", "-Infinity", ".nan", ".inf", "-.inf":
+			return nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+	case protoreflect.BytesKind:
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+			if _, err := base64.URLEncoding.DecodeString(s); err != nil {
+				return fmt.Errorf("invalid base64 bytes")
+			}
+		}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		if values.ByName(protoreflect.Name(s)) != nil {
+			return nil
+		}
+		if n, err := strconv.ParseInt(s, 10, 32); err == nil && values.ByNumber(protoreflect.EnumNumber(n)) != nil {
+			return nil
+		}
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return fmt.Errorf("invalid %s %q, expected one of %s", fd.Enum().Name(), s, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// CheckFile validates the YAML config file, the unknown fields are reported as errors.
+func CheckFile(path string, msg proto.Message) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	errs, err := ValidateYAML(data, msg)
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DecodeFile unmarshals the YAML config file into out with the same checks as loading the config.
+func DecodeFile(path string, out proto.Message) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return decodeYAML(path, data, out)
+}
+
+// decodeYAML unmarshals the YAML config into out, all the invalid nodes are reported at once.
+func decodeYAML(path string, data []byte, out proto.Message) error {
+	errs, err := ValidateYAML(data, out)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	unknown, invalid := errs.Split()
+	for _, e := range unknown {
+		log.Warnf("%s: %s, the field is discarded", path, e.Error())
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%s:\n%w", path, invalid)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return err
+	}
+	return _jsonOptions.Unmarshal(jsonData, out)
+}
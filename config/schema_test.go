@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestValidateYAML(t *testing.T) {
+	data := []byte(`name: helloworld
+middlewars:
+  - name: cors
+endpoints:
+  - path: /helloworld/*
+    protocol: HTTPS
+    timeout: 1
+    backends:
+      - target: 127.0.0.1:8000
+        weight: heavy
+  - path: /ws
+    stream: true
+    timeout: 10s
+`)
+	errs, err := ValidateYAML(data, &configv1.Gateway{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		line    int
+		path    string
+		unknown bool
+	}{
+		{2, "middlewars", true},
+		{6, "endpoints[0].protocol", false},
+		{7, "endpoints[0].timeout", false},
+		{10, "endpoints[0].backends[0].weight", false},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("want %d errors but got: %v", len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Line != w.line || errs[i].Path != w.path || errs[i].Unknown != w.unknown {
+			t.Fatalf("want error at line %d %s but got: %v", w.line, w.path, errs[i])
+		}
+	}
+	unknown, invalid := errs.Split()
+	if len(unknown) != 1 || len(invalid) != 3 {
+		t.Fatalf("unexpected split: %v %v", unknown, invalid)
+	}
+	if !strings.Contains(invalid.Error(), "line 6 column 15: endpoints[0].protocol") {
+		t.Fatalf("unexpected message: %s", invalid.Error())
+	}
+	if _, err := ValidateYAML([]byte("name: [a"), &configv1.Gateway{}); err == nil {
+		t.Fatal("want syntax error")
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ExpandServices copies the fields of the services into the endpoints referencing them, the fields set by an
+// endpoint take precedence, so the proxy only sees the full endpoints. The endpoints keep the service name.
+// All the invalid services and the references to the unknown services are reported at once.
+func ExpandServices(c *configv1.Gateway) error {
+	var errs []error
+	services := make(map[string]*configv1.Service, len(c.Services))
+	for i, s := range c.Services {
+		if s.Name == "" {
+			errs = append(errs, fmt.Errorf("services[%d]: name is required", i))
+			continue
+		}
+		if _, ok := services[s.Name]; ok {
+			errs = append(errs, fmt.Errorf("services[%d]: duplicate service %q", i, s.Name))
+			continue
+		}
+		services[s.Name] = s
+	}
+	for i, e := range c.Endpoints {
+		if e.Service == "" {
+			continue
+		}
+		s, ok := services[e.Service]
+		if !ok {
+			errs = append(errs, fmt.Errorf("endpoints[%d] %s %s: unknown service %q", i, e.Method, e.Path, e.Service))
+			continue
+		}
+		expandService(e, s)
+	}
+	return errors.Join(errs...)
+}
+
+func expandService(e *configv1.Endpoint, s *configv1.Service) {
+	if e.Protocol == configv1.Protocol_UNSPECIFIED {
+		e.Protocol = s.Protocol
+	}
+	if len(e.Backends) == 0 {
+		for _, b := range s.Backends {
+			e.Backends = append(e.Backends, proto.Clone(b).(*configv1.Backend))
+		}
+	}
+	if e.Timeout == nil && s.Timeout != nil {
+		e.Timeout = proto.Clone(s.Timeout).(*durationpb.Duration)
+	}
+	if e.Retry == nil && s.Retry != nil {
+		e.Retry = proto.Clone(s.Retry).(*configv1.Retry)
+	}
+	if e.UpstreamProtocol == configv1.UpstreamProtocol_AUTO {
+		e.UpstreamProtocol = s.UpstreamProtocol
+	}
+	if e.UpstreamPathPrefix == "" {
+		e.UpstreamPathPrefix = s.UpstreamPathPrefix
+	}
+	for k, v := range s.Metadata {
+		if _, ok := e.Metadata[k]; ok {
+			continue
+		}
+		if e.Metadata == nil {
+			e.Metadata = map[string]string{}
+		}
+		e.Metadata[k] = v
+	}
+	e.Middlewares = mergeServiceMiddlewares(s.Middlewares, e.Middlewares)
+}
+
+// mergeServiceMiddlewares keeps the order of the service middlewares, the endpoint middleware with the same name
+// replaces the service one in place and the others are appended.
+func mergeServiceMiddlewares(service, endpoint []*configv1.Middleware) []*configv1.Middleware {
+	if len(service) == 0 {
+		return endpoint
+	}
+	overrides := make(map[string]*configv1.Middleware, len(endpoint))
+	for _, m := range endpoint {
+		overrides[m.Name] = m
+	}
+	out := make([]*configv1.Middleware, 0, len(service)+len(endpoint))
+	replaced := map[string]bool{}
+	for _, m := range service {
+		if o, ok := overrides[m.Name]; ok {
+			out = append(out, o)
+			replaced[m.Name] = true
+			continue
+		}
+		out = append(out, proto.Clone(m).(*configv1.Middleware))
+	}
+	for _, m := range endpoint {
+		if !replaced[m.Name] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const _testServicesConfig = `name: services
+services:
+  - name: users
+    protocol: HTTP
+    timeout: 3s
+    backends:
+      - target: 127.0.0.1:8000
+    metadata:
+      service: users
+      team: identity
+    middlewares:
+      - name: logging
+      - name: ratelimit
+endpoints:
+  - path: /users
+    method: GET
+    service: users
+  - path: /users
+    method: POST
+    service: users
+    timeout: 10s
+    metadata:
+      team: admin
+    middlewares:
+      - name: ratelimit
+        options:
+          '@type': type.googleapis.com/google.protobuf.Duration
+          value: 1s
+      - name: cors
+`
+
+func TestExpandServices(t *testing.T) {
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "config.yaml")
+	writeTestFile(t, confPath, _testServicesConfig)
+	fl, err := NewFileLoader(confPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fl.Close()
+	c, err := fl.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	get, post := c.Endpoints[0], c.Endpoints[1]
+	if get.Service != "users" || len(get.Backends) != 1 || get.Backends[0].Target != "127.0.0.1:8000" ||
+		get.Timeout.AsDuration() != 3*time.Second || get.Metadata["team"] != "identity" {
+		t.Fatalf("want the service fields copied but got %+v", get)
+	}
+	if post.Timeout.AsDuration() != 10*time.Second || post.Metadata["team"] != "admin" || post.Metadata["service"] != "users" {
+		t.Fatalf("want the endpoint fields taking precedence but got %+v", post)
+	}
+	names := []string{}
+	for _, m := range post.Middlewares {
+		names = append(names, m.Name)
+	}
+	if strings.Join(names, ",") != "logging,ratelimit,cors" || post.Middlewares[1].Options == nil {
+		t.Fatalf("want the service middleware replaced by the endpoint one in place but got %v", names)
+	}
+	get.Backends[0].Target = "changed"
+	if post.Backends[0].Target != "127.0.0.1:8000" {
+		t.Fatal("want the backends of the endpoints copied")
+	}
+
+	writeTestFile(t, confPath, strings.Replace(_testServicesConfig, "    service: users\n    timeout", "    service: orders\n    timeout", 1))
+	_, err = fl.Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `endpoints[1] POST /users: unknown service "orders"`) {
+		t.Fatalf("want the unknown service reported with the endpoint but got %v", err)
+	}
+}
@@ -2,6 +2,8 @@
 package consul
 
 import (
+	"net/http"
+
 	"github.com/go-kratos/kratos/contrib/registry/consul/v2"
 	"github.com/go-kratos/kratos/v2/registry"
 	"github.com/hashicorp/consul/api"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/aide-family/goddess/discovery"
 	discoveryV1 "github.com/aide-family/goddess/pkg/discovery/v1"
+	"github.com/aide-family/goddess/tlsreload"
 )
 
 func init() {
@@ -24,6 +27,22 @@ func New(discoveryConfig *discoveryV1.Discovery) (registry.Discovery, error) {
 	c := api.DefaultConfig()
 	c.Address = options.Address
 	c.Token = options.Token
+	tlsConfig, err := tlsreload.ClientConfig(tlsreload.Config{
+		CertFile:   options.GetTls().GetCertFile(),
+		KeyFile:    options.GetTls().GetKeyFile(),
+		CAFile:     options.GetTls().GetCaFile(),
+		ServerName: options.GetTls().GetServerName(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		// the client certificate is served by the reloading provider instead of the files of api.TLSConfig
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		c.Scheme = "https"
+		c.HttpClient = &http.Client{Transport: transport}
+	}
 	client, err := api.NewClient(c)
 	if err != nil {
 		return nil, err
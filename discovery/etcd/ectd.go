@@ -11,6 +11,7 @@ import (
 
 	"github.com/aide-family/goddess/discovery"
 	discoveryV1 "github.com/aide-family/goddess/pkg/discovery/v1"
+	"github.com/aide-family/goddess/tlsreload"
 )
 
 func init() {
@@ -22,11 +23,21 @@ func New(discoveryConfig *discoveryV1.Discovery) (registry.Discovery, error) {
 	if err := anypb.UnmarshalTo(discoveryConfig.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
 		return nil, err
 	}
+	tlsConfig, err := tlsreload.ClientConfig(tlsreload.Config{
+		CertFile:   options.GetTls().GetCertFile(),
+		KeyFile:    options.GetTls().GetKeyFile(),
+		CAFile:     options.GetTls().GetCaFile(),
+		ServerName: options.GetTls().GetServerName(),
+	})
+	if err != nil {
+		return nil, err
+	}
 	client, err := clientV3.New(clientV3.Config{
 		Endpoints:   strutil.SplitSkipEmpty(options.Endpoints, ","),
 		Username:    options.Username,
 		Password:    options.Password,
 		DialTimeout: options.DialTimeout.AsDuration(),
+		TLS:         tlsConfig,
 	})
 	if err != nil {
 		return nil, err
@@ -2,15 +2,23 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/go-kratos/kratos/v2/registry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	discoveryv1 "github.com/aide-family/goddess/pkg/discovery/v1"
 )
 
 var globalRegistry = NewRegistry()
 
+// _tracer traces the creation of the discovery with the tracer provider of the tracing middleware.
+var _tracer = otel.Tracer("gateway")
+
 type Factory func(discoveryConfig *discoveryv1.Discovery) (registry.Discovery, error)
 
 // Registry is the interface for callers to get registered discovery.
@@ -34,10 +42,20 @@ func (d *discoveryRegistry) Register(name string, factory Factory) {
 	d.discovery[name] = factory
 }
 
-func (d *discoveryRegistry) Create(discoveryConfig *discoveryv1.Discovery) (registry.Discovery, error) {
+func (d *discoveryRegistry) Create(discoveryConfig *discoveryv1.Discovery) (_ registry.Discovery, err error) {
 	if discoveryConfig == nil {
 		return nil, nil
 	}
+	_, span := _tracer.Start(context.Background(), "discovery create", trace.WithAttributes(
+		attribute.String("discovery.name", discoveryConfig.Name),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 	if discoveryConfig.Required && discoveryConfig.Name == "" {
 		return nil, fmt.Errorf("discovery is required")
 	}
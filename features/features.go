@@ -0,0 +1,129 @@
+// Package features is the registry of the feature flags consumed by the gateway, the values are
+// fetched from the control service.
+package features
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _metricFeatureEnabled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "feature_enabled",
+	Help:      "Whether the feature is enabled, 1 for enabled",
+}, []string{"feature"})
+
+func init() {
+	metrics.Register(_metricFeatureEnabled)
+}
+
+// The features recognized by the gateway.
+var (
+	Retry          = Register("gw:Retry", "retries the failed attempts by the retry policies of the endpoints", true)
+	PriorityConfig = Register("gw:PriorityConfig", "loads the priority configs from the control service", false)
+	// RolloutAbort drops the rollouts of the priority configs to 0% without republishing them.
+	RolloutAbort = Register("gw:PriorityRolloutAbort", "serves all the requests by the baselines of the priority config rollouts", false)
+)
+
+var (
+	lock  sync.RWMutex
+	flags = map[string]*Flag{}
+)
+
+// Flag is a boolean feature flag.
+type Flag struct {
+	name        string
+	description string
+	def         bool
+	enabled     atomic.Bool
+}
+
+// Register declares the feature flag with its default value, it panics if the name is registered.
+func Register(name, description string, def bool) *Flag {
+	lock.Lock()
+	defer lock.Unlock()
+	if _, ok := flags[name]; ok {
+		panic(fmt.Sprintf("features: duplicate feature: %s", name))
+	}
+	f := &Flag{name: name, description: description, def: def}
+	f.set(def)
+	flags[name] = f
+	return f
+}
+
+// Name returns the name of the feature.
+func (f *Flag) Name() string {
+	return f.name
+}
+
+// Enabled reports whether the feature is enabled.
+func (f *Flag) Enabled() bool {
+	return f.enabled.Load()
+}
+
+func (f *Flag) set(enabled bool) {
+	f.enabled.Store(enabled)
+	value := 0.0
+	if enabled {
+		value = 1
+	}
+	_metricFeatureEnabled.WithLabelValues(f.name).Set(value)
+}
+
+// Lookup returns the feature flag of the name.
+func Lookup(name string) (*Flag, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	f, ok := flags[name]
+	return f, ok
+}
+
+// Apply sets the values of the features, the unknown features are warned and skipped.
+// No value is applied if any of the values is not a boolean. The features not given keep their values.
+func Apply(values map[string]json.RawMessage) error {
+	parsed := make(map[*Flag]bool, len(values))
+	for name, raw := range values {
+		f, ok := Lookup(name)
+		if !ok {
+			log.Warnf("Skip unknown feature: %s", name)
+			continue
+		}
+		var enabled bool
+		if err := json.Unmarshal(raw, &enabled); err != nil {
+			return fmt.Errorf("features: invalid value of %s: %s", name, raw)
+		}
+		parsed[f] = enabled
+	}
+	for f, enabled := range parsed {
+		f.set(enabled)
+	}
+	return nil
+}
+
+// Status is the current value of a feature.
+type Status struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Statuses returns the status of all the features sorted by name.
+func Statuses() []*Status {
+	lock.RLock()
+	defer lock.RUnlock()
+	out := make([]*Status, 0, len(flags))
+	for _, f := range flags {
+		out = append(out, &Status{Name: f.name, Description: f.description, Default: f.def, Enabled: f.Enabled()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
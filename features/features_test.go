@@ -0,0 +1,46 @@
+package features
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	f := Register("test:Apply", "the feature of the test", true)
+	defer func() {
+		lock.Lock()
+		delete(flags, f.name)
+		lock.Unlock()
+	}()
+	if !f.Enabled() {
+		t.Fatal("want the default value")
+	}
+	if err := Apply(map[string]json.RawMessage{"test:Apply": json.RawMessage("false"), "test:Unknown": json.RawMessage("true")}); err != nil {
+		t.Fatal(err)
+	}
+	if f.Enabled() {
+		t.Fatal("want the feature disabled")
+	}
+	// no value is applied if any is invalid
+	if err := Apply(map[string]json.RawMessage{"test:Apply": json.RawMessage("true"), "gw:Retry": json.RawMessage(`"yes"`)}); err == nil {
+		t.Fatal("want the mismatched type rejected")
+	}
+	if f.Enabled() || !Retry.Enabled() {
+		t.Fatal("want the values kept")
+	}
+	var found *Status
+	for _, s := range Statuses() {
+		if s.Name == "test:Apply" {
+			found = s
+		}
+	}
+	if found == nil || !found.Default || found.Enabled {
+		t.Fatalf("unexpected status: %+v", found)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want the duplicate feature panic")
+		}
+	}()
+	Register("test:Apply", "", false)
+}
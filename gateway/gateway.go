@@ -0,0 +1,361 @@
+// Package gateway embeds the goddess proxy in a Go program, the program owns the listeners and the lifecycle.
+//
+//	g, err := gateway.New(ctx, gateway.Config{Gateway: c})
+//	if err != nil {
+//		return err
+//	}
+//	mux.Handle("/", g)
+//	defer g.Shutdown(ctx)
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	// the discoveries and the middlewares registered by their packages, the programs embedding the gateway
+	// and gatewaytest get all of them by importing this package
+	_ "github.com/aide-family/goddess/discovery/consul"
+	_ "github.com/aide-family/goddess/discovery/etcd"
+	_ "github.com/aide-family/goddess/middleware/bbr"
+	_ "github.com/aide-family/goddess/middleware/bodyrouter"
+	_ "github.com/aide-family/goddess/middleware/codec"
+	_ "github.com/aide-family/goddess/middleware/collapse"
+	_ "github.com/aide-family/goddess/middleware/cors"
+	_ "github.com/aide-family/goddess/middleware/identity"
+	_ "github.com/aide-family/goddess/middleware/logging"
+	_ "github.com/aide-family/goddess/middleware/namespace"
+	_ "github.com/aide-family/goddess/middleware/rewrite"
+	_ "github.com/aide-family/goddess/middleware/sigv4"
+	_ "github.com/aide-family/goddess/middleware/streamrecorder"
+	_ "github.com/aide-family/goddess/middleware/tracing"
+	_ "github.com/aide-family/goddess/middleware/transcoder"
+	_ "github.com/aide-family/goddess/middleware/transform"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/transport"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/discovery"
+	"github.com/aide-family/goddess/features"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/circuitbreaker"
+	"github.com/aide-family/goddess/middleware/jwt"
+	"github.com/aide-family/goddess/middleware/quota"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/proxy"
+	"github.com/aide-family/goddess/proxy/debug"
+	"github.com/aide-family/goddess/server"
+)
+
+// the interval the in-flight requests are checked at by Shutdown
+const _shutdownPollInterval = 100 * time.Millisecond
+
+// Config is the config of an embedded gateway, only one of Gateway and Loader is needed.
+type Config struct {
+	// Gateway is the initial config, loaded by Loader if nil.
+	Gateway *configv1.Gateway
+	// Loader reloads the gateway on every change it notifies, it is closed by Shutdown.
+	Loader config.ConfigLoader
+	// ReloadSource is the source label of the reloads by Loader, default is config.ReloadSourceFile.
+	ReloadSource string
+	// Discovery resolves the services of the backends, created by the discovery of the initial config if nil.
+	Discovery registry.Discovery
+	// MiddlewareFactory creates the middlewares of the endpoints, default is middleware.Create.
+	MiddlewareFactory middleware.FactoryV2
+	// Observable observes the requests in addition to the default metrics.
+	Observable proxy.Observable
+	// ProxyOptions are the other options of the proxy.
+	ProxyOptions []proxy.Option
+	// BeforeReload is called with the config loaded by Loader before it is applied, eg: to write an audit record,
+	// the reload is aborted if it returns an error.
+	BeforeReload func(*configv1.Gateway) error
+	// AfterReload is called with the config loaded by Loader once it is applied.
+	AfterReload func(*configv1.Gateway)
+	// AllowInvalidConfig keeps the gateway replying 503 for an invalid initial config until a valid config is
+	// reloaded, New returns the error of the config otherwise.
+	AllowInvalidConfig bool
+}
+
+// Gateway is an embedded gateway, it serves the requests as a http.Handler.
+type Gateway struct {
+	proxy        *proxy.Proxy
+	loader       config.ConfigLoader
+	source       string
+	passthroughs map[string]*server.PassthroughServer
+	beforeReload func(*configv1.Gateway) error
+	afterReload  func(*configv1.Gateway)
+	// the debug handlers of the gateway, the paths not registered on it are served by the global ones
+	debug *debug.Service
+
+	// the reloads are applied one by one
+	reloadLock sync.Mutex
+	debugOnce  sync.Once
+	closeOnce  sync.Once
+}
+
+var _ http.Handler = (*Gateway)(nil)
+
+// New creates the gateway with the initial config. An invalid initial config fails New unless
+// Config.AllowInvalidConfig is set, then it is logged and the gateway replies 503 until a valid config is
+// reloaded, see Proxy().Configured.
+func New(ctx context.Context, c Config) (*Gateway, error) {
+	bc := c.Gateway
+	if bc == nil {
+		if c.Loader == nil {
+			return nil, errors.New("gateway: either the config or the config loader is required")
+		}
+		var err error
+		if bc, err = c.Loader.Load(ctx); err != nil {
+			return nil, fmt.Errorf("gateway: failed to load config: %w", err)
+		}
+	}
+	d := c.Discovery
+	if d == nil {
+		var err error
+		if d, err = discovery.Create(bc.Discovery); err != nil {
+			return nil, fmt.Errorf("gateway: failed to create discovery: %w", err)
+		}
+	}
+	middlewareFactory := c.MiddlewareFactory
+	if middlewareFactory == nil {
+		middlewareFactory = middleware.Create
+	}
+	proxyOpts := c.ProxyOptions
+	if c.Observable != nil {
+		proxyOpts = append(proxyOpts[:len(proxyOpts):len(proxyOpts)], proxy.WithObservable(c.Observable))
+	}
+	clientFactory := client.NewFactory(d)
+	p, err := proxy.New(clientFactory, middlewareFactory, proxyOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: failed to new proxy: %w", err)
+	}
+	passthroughs, err := newPassthroughs(bc, d)
+	if err != nil {
+		return nil, fmt.Errorf("gateway: failed to create passthrough listeners: %w", err)
+	}
+	g := &Gateway{
+		proxy:        p,
+		loader:       c.Loader,
+		source:       c.ReloadSource,
+		passthroughs: passthroughs,
+		beforeReload: c.BeforeReload,
+		afterReload:  c.AfterReload,
+		debug:        debug.NewService(),
+	}
+	if g.source == "" {
+		g.source = config.ReloadSourceFile
+	}
+	buildContext := client.NewBuildContext(bc)
+	circuitbreaker.Init(buildContext, clientFactory)
+	if err := g.update(buildContext, bc); err != nil {
+		if !c.AllowInvalidConfig {
+			return nil, fmt.Errorf("gateway: failed to update config: %w", err)
+		}
+		// the proxy replies 503 and stays unready until a valid config is loaded by the watcher
+		log.Errorf("failed to update service config: %v, serving 503 until a valid config is loaded", err)
+		config.ObserveReload(g.source, "", "", err)
+	} else {
+		hash := g.configHash(bc)
+		config.ObserveReload(g.source, bc.Version, hash, nil)
+		log.Infof("config applied: version %q hash %s", bc.Version, hash)
+	}
+	if g.loader != nil {
+		g.loader.Watch(g.reloadFromLoader)
+	}
+	return g, nil
+}
+
+// ServeHTTP serves the request by the current config.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	g.proxy.ServeHTTP(w, req)
+}
+
+// Proxy returns the proxy of the gateway, eg: to check if it is configured or the health of the services.
+func (g *Gateway) Proxy() *proxy.Proxy {
+	return g.proxy
+}
+
+// Passthroughs returns the servers of the passthrough listeners of the initial config, they are started and
+// stopped by the program, the listeners are not added or removed by a reload.
+func (g *Gateway) Passthroughs() []transport.Server {
+	names := make([]string, 0, len(g.passthroughs))
+	for name := range g.passthroughs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]transport.Server, 0, len(names))
+	for _, name := range names {
+		out = append(out, g.passthroughs[name])
+	}
+	return out
+}
+
+// Reload applies the config, the gateway keeps serving the previous config if it fails.
+func (g *Gateway) Reload(c *configv1.Gateway) error {
+	g.reloadLock.Lock()
+	defer g.reloadLock.Unlock()
+	for _, l := range c.Listeners {
+		if err := server.CheckListener(l); err != nil {
+			return err
+		}
+	}
+	buildContext := client.NewBuildContext(c)
+	circuitbreaker.SetBuildContext(buildContext)
+	if err := g.update(buildContext, c); err != nil {
+		return err
+	}
+	g.updatePassthroughs(c)
+	return nil
+}
+
+// update applies the config with the services expanded into the endpoints, c is not modified.
+func (g *Gateway) update(buildContext *client.BuildContext, c *configv1.Gateway) error {
+	if len(c.Services) > 0 {
+		c = proto.Clone(c).(*configv1.Gateway)
+		if err := config.ExpandServices(c); err != nil {
+			return err
+		}
+	}
+	return g.proxy.Update(buildContext, c)
+}
+
+func (g *Gateway) reloadFromLoader() (err error) {
+	defer func() {
+		if err != nil {
+			config.ObserveReload(g.source, "", "", err)
+		}
+	}()
+	c, err := g.loader.Load(context.Background())
+	if err != nil {
+		log.Errorf("failed to load config: %v", err)
+		return err
+	}
+	if config.IsBad(c) {
+		log.Warnf("skip reloading config %q hash %s rolled back for the error spike", c.Version, g.configHash(c))
+		return nil
+	}
+	if g.beforeReload != nil {
+		if err := g.beforeReload(c); err != nil {
+			return err
+		}
+	}
+	if err := g.Reload(c); err != nil {
+		log.Errorf("failed to update service config: %v", err)
+		return err
+	}
+	hash := g.configHash(c)
+	config.ObserveReload(g.source, c.Version, hash, nil)
+	if g.afterReload != nil {
+		g.afterReload(c)
+	}
+	log.Infof("config reloaded: version %q hash %s", c.Version, hash)
+	return nil
+}
+
+// mainHasher is implemented by the loaders knowing the hash of the config as published, eg: config.FileLoader.
+type mainHasher interface {
+	MainHash(*configv1.Gateway) string
+}
+
+// ConfigHash returns the hash of the config as published, which is compared with the hash of the control
+// service to detect the drift, or config.Hash of the config if the loader does not know it. It identifies the
+// config in the logs, the metrics and the audit records.
+func ConfigHash(loader config.ConfigLoader, c *configv1.Gateway) string {
+	if h, ok := loader.(mainHasher); ok {
+		if hash := h.MainHash(c); hash != "" {
+			return hash
+		}
+	}
+	return config.Hash(c)
+}
+
+func (g *Gateway) configHash(c *configv1.Gateway) string {
+	return ConfigHash(g.loader, c)
+}
+
+// DebugHandler returns the handler serving /debug and /statusz of the gateway in front of it, the debug
+// handlers of the proxy and the middlewares are registered on the first call. The handlers are of this gateway,
+// the paths registered globally, eg: by debug.Register of the program, are served as well.
+func (g *Gateway) DebugHandler() http.Handler {
+	return g.DebugMashup(g)
+}
+
+func (g *Gateway) registerDebug() {
+	g.debug.Register("proxy", g.proxy)
+	g.debug.Register("admin", g.proxy.Drains())
+	g.debug.Register("jwt", jwt.RevocationDebugger)
+	g.debug.Register("quota", quota.QuotaDebugger)
+	g.debug.Register("audit", audit.Debugger)
+	g.debug.RegisterStatus("features", func() any { return features.Statuses() })
+	g.debug.RegisterStatus("priority_rollouts", func() any { return g.proxy.Rollouts() })
+	g.debug.RegisterStatus("config_rollback", func() any { return g.proxy.RollbackStatus() })
+	g.debug.RegisterStatus("routes_summary", func() any { return g.proxy.RoutesSummary() })
+}
+
+// DebugMashup returns the handler serving /debug and /statusz of the gateway and the other paths by origin, eg:
+// the gateway wrapped by the load shedding which is not expected to reject the debug requests.
+func (g *Gateway) DebugMashup(origin http.Handler) http.Handler {
+	g.debugOnce.Do(g.registerDebug)
+	return g.debug.Mashup(origin)
+}
+
+// Shutdown stops the reloads and waits for the requests in flight until ctx is done, the requests still in
+// flight then are logged. The listeners serving the gateway are stopped by the program before.
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	g.closeOnce.Do(func() {
+		if g.loader != nil {
+			g.loader.Close()
+		}
+	})
+	ticker := time.NewTicker(_shutdownPollInterval)
+	defer ticker.Stop()
+	for len(g.proxy.Inflight()) > 0 {
+		select {
+		case <-ctx.Done():
+			// the requests still in flight are cut off
+			g.proxy.LogInflight("shutdown")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// newPassthroughs creates the passthrough servers of the listeners, the listeners are not added or removed by a reload.
+func newPassthroughs(bc *configv1.Gateway, discovery registry.Discovery) (map[string]*server.PassthroughServer, error) {
+	out := make(map[string]*server.PassthroughServer, len(bc.Listeners))
+	for _, l := range bc.Listeners {
+		if _, ok := out[l.Name]; ok {
+			return nil, fmt.Errorf("duplicate listener: %s", l.Name)
+		}
+		s, err := server.NewPassthrough(l, discovery)
+		if err != nil {
+			return nil, err
+		}
+		out[l.Name] = s
+	}
+	return out, nil
+}
+
+// updatePassthroughs updates the routes of the passthrough servers, the listeners are checked before.
+func (g *Gateway) updatePassthroughs(bc *configv1.Gateway) {
+	for _, l := range bc.Listeners {
+		s, ok := g.passthroughs[l.Name]
+		if !ok {
+			log.Warnf("the listener %s is added, it takes effect after restart", l.Name)
+			continue
+		}
+		if err := s.Update(l); err != nil {
+			log.Errorf("failed to update listener %s: %v", l.Name, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/gateway"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func newUpstream(t *testing.T, body string) *httptest.Server {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func newConfig(version string, upstream *httptest.Server) *configv1.Gateway {
+	return &configv1.Gateway{
+		Name:    "test",
+		Version: version,
+		Endpoints: []*configv1.Endpoint{{
+			Path:     "/echo/*",
+			Protocol: configv1.Protocol_HTTP,
+			Backends: []*configv1.Backend{{Target: strings.TrimPrefix(upstream.URL, "http://")}},
+		}},
+	}
+}
+
+func get(t *testing.T, h http.Handler, path string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code, w.Body.String()
+}
+
+// fakeLoader loads the config set and notifies the change on Set.
+type fakeLoader struct {
+	lock     sync.Mutex
+	config   *configv1.Gateway
+	onChange config.OnChange
+	closed   bool
+}
+
+func (l *fakeLoader) Load(context.Context) (*configv1.Gateway, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.config, nil
+}
+
+func (l *fakeLoader) Watch(fn config.OnChange) {
+	l.onChange = fn
+}
+
+func (l *fakeLoader) Close() {
+	l.closed = true
+}
+
+func (l *fakeLoader) Set(c *configv1.Gateway) error {
+	l.lock.Lock()
+	l.config = c
+	l.lock.Unlock()
+	return l.onChange()
+}
+
+func TestGateway(t *testing.T) {
+	v1, v2 := newUpstream(t, "v1"), newUpstream(t, "v2")
+	g, err := gateway.New(context.Background(), gateway.Config{Gateway: newConfig("v1", v1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, body := get(t, g, "/echo/hello"); code != http.StatusOK || body != "v1" {
+		t.Fatalf("want 200 v1 but got %d %s", code, body)
+	}
+	if err := g.Reload(newConfig("v2", v2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v2" {
+		t.Fatalf("want v2 after reload but got %s", body)
+	}
+	invalid := newConfig("invalid", v1)
+	invalid.Endpoints[0].StreamLimits = &configv1.StreamLimits{MaxStreams: 1}
+	if err := g.Reload(invalid); err == nil {
+		t.Fatal("want the invalid config rejected")
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v2" {
+		t.Fatalf("want the previous config kept but got %s", body)
+	}
+	if code, _ := get(t, g.DebugHandler(), "/debug/ping"); code != http.StatusOK {
+		t.Fatalf("want the debug handlers served but got %d", code)
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGatewayLoader(t *testing.T) {
+	v1, v2 := newUpstream(t, "v1"), newUpstream(t, "v2")
+	loader := &fakeLoader{config: newConfig("v1", v1)}
+	var applied []string
+	g, err := gateway.New(context.Background(), gateway.Config{
+		Loader: loader,
+		BeforeReload: func(c *configv1.Gateway) error {
+			if c.Version == "rejected" {
+				return errors.New("rejected")
+			}
+			return nil
+		},
+		AfterReload: func(c *configv1.Gateway) {
+			applied = append(applied, c.Version)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v1" {
+		t.Fatalf("want the config of the loader but got %s", body)
+	}
+	if err := loader.Set(newConfig("v2", v2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Set(newConfig("rejected", v1)); err == nil {
+		t.Fatal("want the reload aborted by the hook")
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v2" {
+		t.Fatalf("want v2 after reload but got %s", body)
+	}
+	if len(applied) != 1 || applied[0] != "v2" {
+		t.Fatalf("want v2 applied but got %v", applied)
+	}
+	if err := g.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !loader.closed {
+		t.Fatal("want the loader closed by shutdown")
+	}
+}
+
+func TestGatewayConfigRequired(t *testing.T) {
+	if _, err := gateway.New(context.Background(), gateway.Config{}); err == nil {
+		t.Fatal("want an error without the config and the loader")
+	}
+	invalid := newConfig("invalid", newUpstream(t, "v1"))
+	invalid.Endpoints[0].StreamLimits = &configv1.StreamLimits{MaxStreams: 1}
+	if _, err := gateway.New(context.Background(), gateway.Config{Gateway: invalid}); err == nil {
+		t.Fatal("want the invalid initial config rejected")
+	}
+	g, err := gateway.New(context.Background(), gateway.Config{Gateway: invalid, AllowInvalidConfig: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := get(t, g, "/echo/hello"); code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503 until a valid config is reloaded but got %d", code)
+	}
+}
+
+func TestGatewayServices(t *testing.T) {
+	v1, v2 := newUpstream(t, "v1"), newUpstream(t, "v2")
+	withService := func(upstream *httptest.Server) *configv1.Gateway {
+		return &configv1.Gateway{
+			Name: "test",
+			Services: []*configv1.Service{{
+				Name:     "echo",
+				Protocol: configv1.Protocol_HTTP,
+				Backends: []*configv1.Backend{{Target: strings.TrimPrefix(upstream.URL, "http://")}},
+			}},
+			Endpoints: []*configv1.Endpoint{{Path: "/echo/*", Service: "echo"}},
+		}
+	}
+	c := withService(v1)
+	g, err := gateway.New(context.Background(), gateway.Config{Gateway: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v1" {
+		t.Fatalf("want the endpoint expanded by the service but got %s", body)
+	}
+	if len(c.Endpoints[0].Backends) != 0 {
+		t.Fatal("want the config of the caller not modified")
+	}
+	if err := g.Reload(withService(v2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, body := get(t, g, "/echo/hello"); body != "v2" {
+		t.Fatalf("want the reloaded endpoint expanded by the service but got %s", body)
+	}
+	unknown := withService(v2)
+	unknown.Endpoints[0].Service = "unknown"
+	if err := g.Reload(unknown); err == nil {
+		t.Fatal("want the unknown service rejected")
+	}
+}
+
+func TestGatewayDebugHandler(t *testing.T) {
+	upstream := newUpstream(t, "v1")
+	newGateway := func(version string) http.Handler {
+		c := newConfig(version, upstream)
+		c.Endpoints[0].Path = "/" + version + "/*"
+		g, err := gateway.New(context.Background(), gateway.Config{Gateway: c})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return g.DebugHandler()
+	}
+	a, b := newGateway("a"), newGateway("b")
+	// the debug handlers of each gateway are its own, the global ones are served as well
+	for version, h := range map[string]http.Handler{"a": a, "b": b} {
+		if code, body := get(t, h, "/debug/proxy/router/inspect"); code != http.StatusOK || !strings.Contains(body, "/"+version+"/") {
+			t.Fatalf("want the routes of gateway %s but got %d %s", version, code, body)
+		}
+		if code, _ := get(t, h, "/debug/ping"); code != http.StatusOK {
+			t.Fatalf("want the global debug handlers served but got %d", code)
+		}
+		if code, body := get(t, h, "/statusz"); code != http.StatusOK || !strings.Contains(body, "routes_summary") {
+			t.Fatalf("want the status sections served but got %d %s", code, body)
+		}
+	}
+}
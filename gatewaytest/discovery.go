@@ -0,0 +1,105 @@
+package gatewaytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// Discovery is an in-memory discovery, the instances of a service are updated by Set.
+type Discovery struct {
+	lock     sync.Mutex
+	services map[string][]*registry.ServiceInstance
+	watchers map[string][]*watcher
+}
+
+var _ registry.Discovery = (*Discovery)(nil)
+
+// NewDiscovery returns an empty in-memory discovery.
+func NewDiscovery() *Discovery {
+	return &Discovery{
+		services: map[string][]*registry.ServiceInstance{},
+		watchers: map[string][]*watcher{},
+	}
+}
+
+// Set replaces the endpoints of the service and notifies the watchers, eg: Set("echo", "http://127.0.0.1:8000").
+func (d *Discovery) Set(name string, endpoints ...string) {
+	instances := make([]*registry.ServiceInstance, 0, len(endpoints))
+	for i, endpoint := range endpoints {
+		instances = append(instances, &registry.ServiceInstance{
+			ID:        fmt.Sprintf("%s-%d", name, i),
+			Name:      name,
+			Endpoints: []string{endpoint},
+		})
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.services[name] = instances
+	for _, w := range d.watchers[name] {
+		w.notify()
+	}
+}
+
+func (d *Discovery) GetService(_ context.Context, name string) ([]*registry.ServiceInstance, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.services[name], nil
+}
+
+func (d *Discovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{
+		ctx:     ctx,
+		cancel:  cancel,
+		name:    name,
+		d:       d,
+		changed: make(chan struct{}, 1),
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.watchers[name] = append(d.watchers[name], w)
+	// the first call of Next returns the current instances
+	w.notify()
+	return w, nil
+}
+
+type watcher struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	name    string
+	d       *Discovery
+	changed chan struct{}
+}
+
+func (w *watcher) notify() {
+	select {
+	case w.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-w.changed:
+	}
+	return w.d.GetService(w.ctx, w.name)
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	w.d.lock.Lock()
+	defer w.d.lock.Unlock()
+	watchers := w.d.watchers[w.name]
+	for i, item := range watchers {
+		if item == w {
+			w.d.watchers[w.name] = append(watchers[:i], watchers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package gatewaytest runs an in-process gateway for integration tests.
+package gatewaytest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/config"
+	"github.com/aide-family/goddess/gateway"
+	"github.com/aide-family/goddess/middleware"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/proxy"
+	"github.com/go-kratos/kratos/v2/registry"
+)
+
+// Option is gateway test option.
+type Option func(*options)
+
+type options struct {
+	discovery         registry.Discovery
+	loader            config.ConfigLoader
+	middlewareFactory middleware.FactoryV2
+	proxyOptions      []proxy.Option
+}
+
+// WithDiscovery sets the discovery used by the endpoints, eg: NewDiscovery().
+// the discovery of the config is used if not set.
+func WithDiscovery(d registry.Discovery) Option {
+	return func(o *options) {
+		o.discovery = d
+	}
+}
+
+// WithConfigLoader reloads the gateway when the loader notifies a change,
+// it is used to test the gateway against a control service or a fake loader.
+func WithConfigLoader(l config.ConfigLoader) Option {
+	return func(o *options) {
+		o.loader = l
+	}
+}
+
+// WithMiddlewareFactory sets the middleware factory, default is middleware.Create.
+func WithMiddlewareFactory(f middleware.FactoryV2) Option {
+	return func(o *options) {
+		o.middlewareFactory = f
+	}
+}
+
+// WithProxyOptions sets the proxy options.
+func WithProxyOptions(opts ...proxy.Option) Option {
+	return func(o *options) {
+		o.proxyOptions = append(o.proxyOptions, opts...)
+	}
+}
+
+// Gateway is an in-process gateway served on a httptest server.
+type Gateway struct {
+	// URL is the base URL of the gateway, eg: http://127.0.0.1:51234
+	URL string
+
+	t       testing.TB
+	gateway *gateway.Gateway
+	server  *httptest.Server
+	once    sync.Once
+}
+
+// New starts a gateway with the config, the gateway is closed on the cleanup of the test.
+func New(t testing.TB, c *configv1.Gateway, opts ...Option) *Gateway {
+	t.Helper()
+	o := &options{middlewareFactory: middleware.Create}
+	for _, opt := range opts {
+		opt(o)
+	}
+	gw, err := gateway.New(context.Background(), gateway.Config{
+		Gateway:           c,
+		Loader:            o.loader,
+		Discovery:         o.discovery,
+		MiddlewareFactory: o.middlewareFactory,
+		ProxyOptions:      o.proxyOptions,
+	})
+	if err != nil {
+		t.Fatalf("failed to create gateway: %v", err)
+	}
+	g := &Gateway{t: t, gateway: gw}
+	g.server = httptest.NewServer(gw)
+	g.URL = g.server.URL
+	t.Cleanup(g.Close)
+	return g
+}
+
+// Handler returns the gateway handler without the httptest server.
+func (g *Gateway) Handler() http.Handler {
+	return g.gateway
+}
+
+// Client returns the http client of the httptest server.
+func (g *Gateway) Client() *http.Client {
+	return g.server.Client()
+}
+
+// Reload applies the new config, the previous router is closed gracefully.
+func (g *Gateway) Reload(c *configv1.Gateway) error {
+	return g.gateway.Reload(c)
+}
+
+// Do sends the request to the gateway, the URL of the request must be based on the gateway URL.
+func (g *Gateway) Do(req *http.Request) *http.Response {
+	g.t.Helper()
+	resp, err := g.Client().Do(req)
+	if err != nil {
+		g.t.Fatalf("failed to send request: %v", err)
+	}
+	return resp
+}
+
+// Get sends a GET request to the path of the gateway.
+func (g *Gateway) Get(path string) *http.Response {
+	g.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, g.URL+"/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		g.t.Fatalf("failed to new request: %v", err)
+	}
+	return g.Do(req)
+}
+
+// Close shuts down the httptest server and the config loader.
+func (g *Gateway) Close() {
+	g.once.Do(func() {
+		g.server.Close()
+		g.gateway.Shutdown(context.Background())
+	})
+}
+
+// Backend returns a direct backend of the upstream server.
+func Backend(upstream *httptest.Server) *configv1.Backend {
+	return &configv1.Backend{Target: strings.TrimPrefix(strings.TrimPrefix(upstream.URL, "http://"), "https://")}
+}
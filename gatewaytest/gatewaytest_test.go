@@ -0,0 +1,76 @@
+package gatewaytest_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aide-family/goddess/gatewaytest"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func newUpstream(t *testing.T, body string) *httptest.Server {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestGateway(t *testing.T) {
+	v1, v2 := newUpstream(t, "v1"), newUpstream(t, "v2")
+	endpoint := func(upstream *httptest.Server) *configv1.Endpoint {
+		return &configv1.Endpoint{
+			Path:     "/echo/*",
+			Protocol: configv1.Protocol_HTTP,
+			Backends: []*configv1.Backend{gatewaytest.Backend(upstream)},
+		}
+	}
+	g := gatewaytest.New(t, &configv1.Gateway{
+		Name:      "test",
+		Endpoints: []*configv1.Endpoint{endpoint(v1)},
+	})
+	if body := readBody(t, g.Get("/echo/hello")); body != "v1" {
+		t.Fatalf("want v1 but got: %s", body)
+	}
+	if resp := g.Get("/unknown"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("want 404 but got: %d", resp.StatusCode)
+	}
+
+	if err := g.Reload(&configv1.Gateway{
+		Name:      "test",
+		Endpoints: []*configv1.Endpoint{endpoint(v2)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(t, g.Get("/echo/hello")); body != "v2" {
+		t.Fatalf("want v2 after reload but got: %s", body)
+	}
+}
+
+func TestGatewayWithDiscovery(t *testing.T) {
+	upstream := newUpstream(t, "discovered")
+	d := gatewaytest.NewDiscovery()
+	d.Set("gatewaytest.discovery", upstream.URL)
+	g := gatewaytest.New(t, &configv1.Gateway{
+		Name: "test",
+		Endpoints: []*configv1.Endpoint{{
+			Path:     "/discovery",
+			Protocol: configv1.Protocol_HTTP,
+			Backends: []*configv1.Backend{{Target: "discovery:///gatewaytest.discovery"}},
+		}},
+	}, gatewaytest.WithDiscovery(d))
+	if body := readBody(t, g.Get("/discovery")); body != "discovered" {
+		t.Fatalf("want discovered but got: %s", body)
+	}
+}
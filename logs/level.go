@@ -0,0 +1,60 @@
+package logs
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// ForceKey marks the logs written regardless of the levels, eg: the verbose logs of the log targets.
+const ForceKey = "log_target"
+
+// _globalLevel is the min level of the global logger, adjustable at runtime.
+var _globalLevel = newLevel(log.LevelInfo)
+
+// SetLevel sets the min level of the global logger created by NewGlobalLogger.
+func SetLevel(level log.Level) {
+	_globalLevel.Store(int32(level))
+}
+
+// GetLevel returns the min level of the global logger.
+func GetLevel() log.Level {
+	return log.Level(_globalLevel.Load())
+}
+
+// NewGlobalLogger returns the logger like NewLogger but filtered by the global level, the level is set to
+// the level and is adjustable by SetLevel afterwards.
+func NewGlobalLogger(w io.Writer, format string, level log.Level) log.Logger {
+	SetLevel(level)
+	return &levelFilter{logger: newFormatLogger(w, format), level: _globalLevel}
+}
+
+func newLevel(level log.Level) *atomic.Int32 {
+	l := &atomic.Int32{}
+	l.Store(int32(level))
+	return l
+}
+
+// levelFilter drops the logs below the level except the forced ones.
+type levelFilter struct {
+	logger log.Logger
+	level  *atomic.Int32
+}
+
+func (f *levelFilter) Log(level log.Level, keyvals ...any) error {
+	if level < log.Level(f.level.Load()) && !forced(keyvals) {
+		return nil
+	}
+	return f.logger.Log(level, keyvals...)
+}
+
+// forced reports whether the keys contain ForceKey, the keys prefixed by log.With are scanned as well.
+func forced(keyvals []any) bool {
+	for i := 0; i < len(keyvals); i += 2 {
+		if keyvals[i] == ForceKey {
+			return true
+		}
+	}
+	return false
+}
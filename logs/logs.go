@@ -0,0 +1,203 @@
+// Package logs routes the gateway logs to the named streams, each stream is written to its own sink
+// or to the global logger if it is not configured.
+package logs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// Stream is a named log stream, it is a log.Logger writing to the configured sink or the global logger.
+type Stream struct {
+	name   string
+	logger atomic.Pointer[streamLogger]
+}
+
+type streamLogger struct {
+	log.Logger
+}
+
+var (
+	// Access is the stream of the access logs written by the logging middleware.
+	Access = &Stream{name: "access"}
+	// Error is the stream of the errors of proxying the requests and the middlewares.
+	Error = &Stream{name: "error"}
+	// Audit is the stream of the audit records of the config and admin actions.
+	Audit = &Stream{name: "audit"}
+	// Debug is the stream of the diagnostics, such as the slow request exemplars.
+	Debug = &Stream{name: "debug"}
+
+	_streams = []*Stream{Access, Error, Audit, Debug}
+)
+
+// Name returns the name of the stream.
+func (s *Stream) Name() string {
+	return s.name
+}
+
+// Configured reports whether the stream is written to its own sink instead of the global logger.
+func (s *Stream) Configured() bool {
+	return s.logger.Load() != nil
+}
+
+// Log implements log.Logger.
+func (s *Stream) Log(level log.Level, keyvals ...any) error {
+	if l := s.logger.Load(); l != nil {
+		return l.Log(level, keyvals...)
+	}
+	return log.GetLogger().Log(level, keyvals...)
+}
+
+// Config is the sink of a stream.
+type Config struct {
+	// Output is stdout, stderr or a file path, the file is rotated when it exceeds MaxSize.
+	Output string
+	// Level is the min level of the written logs.
+	Level log.Level
+	// Format is text or json.
+	Format string
+	// MaxSize is the max size in bytes of the file before it is rotated.
+	MaxSize int64
+	// MaxBackups is the max number of the rotated files to keep.
+	MaxBackups int
+}
+
+const (
+	_defaultMaxSize    = 100 << 20
+	_defaultMaxBackups = 10
+)
+
+// ParseStream parses the stream config in the form of name:key=value,..., eg:
+// access:output=/var/log/gateway/access.log,format=json,level=info,max-size=100,max-backups=10
+// The max-size is in megabytes, the stream is written to stdout in text if the keys are omitted.
+func ParseStream(spec string) (*Stream, *Config, error) {
+	name, options, _ := strings.Cut(spec, ":")
+	stream := lookup(strings.TrimSpace(name))
+	if stream == nil {
+		return nil, nil, fmt.Errorf("unknown log stream %q, must be one of access, error, audit and debug", name)
+	}
+	c := &Config{Output: "stdout", Level: log.LevelInfo, Format: "text", MaxSize: _defaultMaxSize, MaxBackups: _defaultMaxBackups}
+	if stream == Debug {
+		c.Level = log.LevelDebug
+	}
+	if options == "" {
+		return stream, c, nil
+	}
+	for _, option := range strings.Split(options, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok || value == "" {
+			return nil, nil, fmt.Errorf("invalid option %q of log stream %s", option, stream.name)
+		}
+		switch strings.TrimSpace(key) {
+		case "output":
+			c.Output = value
+		case "level":
+			level := log.ParseLevel(value)
+			if !strings.EqualFold(level.String(), value) {
+				return nil, nil, fmt.Errorf("invalid level %q of log stream %s", value, stream.name)
+			}
+			c.Level = level
+		case "format":
+			c.Format = strings.ToLower(value)
+			if c.Format != "text" && c.Format != "json" {
+				return nil, nil, fmt.Errorf("invalid format %q of log stream %s, must be text or json", value, stream.name)
+			}
+		case "max-size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || n <= 0 {
+				return nil, nil, fmt.Errorf("invalid max-size %q of log stream %s", value, stream.name)
+			}
+			c.MaxSize = n << 20
+		case "max-backups":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, nil, fmt.Errorf("invalid max-backups %q of log stream %s", value, stream.name)
+			}
+			c.MaxBackups = n
+		default:
+			return nil, nil, fmt.Errorf("unknown option %q of log stream %s", key, stream.name)
+		}
+	}
+	return stream, c, nil
+}
+
+func lookup(name string) *Stream {
+	for _, s := range _streams {
+		if s.name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Setup writes the streams in the specs to their sinks, the other streams keep writing to the global logger.
+// The streams sharing an output share the file, the returned func restores the streams and closes the files.
+func Setup(specs []string) (func(), error) {
+	configs := map[*Stream]*Config{}
+	for _, spec := range specs {
+		stream, c, err := ParseStream(spec)
+		if err != nil {
+			return nil, err
+		}
+		configs[stream] = c
+	}
+	files := map[string]*rotateFile{}
+	closeFiles := func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}
+	loggers := map[*Stream]log.Logger{}
+	for stream, c := range configs {
+		var w io.Writer
+		switch c.Output {
+		case "stdout":
+			w = os.Stdout
+		case "stderr":
+			w = os.Stderr
+		default:
+			f, ok := files[c.Output]
+			if !ok {
+				var err error
+				if f, err = openRotateFile(c.Output, c.MaxSize, c.MaxBackups); err != nil {
+					closeFiles()
+					return nil, fmt.Errorf("failed to open log stream %s: %w", stream.name, err)
+				}
+				files[c.Output] = f
+			}
+			w = f
+		}
+		loggers[stream] = NewLogger(w, c.Format, c.Level)
+	}
+	for stream, l := range loggers {
+		stream.logger.Store(&streamLogger{log.With(l, "stream", stream.name)})
+	}
+	return func() {
+		for stream := range loggers {
+			stream.logger.Store(nil)
+		}
+		closeFiles()
+	}, nil
+}
+
+// NewLogger returns the logger writing the logs at or above the level in the format, text or json,
+// with the timestamp of each log. The logs with ForceKey are written at any level.
+func NewLogger(w io.Writer, format string, level log.Level) log.Logger {
+	return &levelFilter{logger: newFormatLogger(w, format), level: newLevel(level)}
+}
+
+func newFormatLogger(w io.Writer, format string) log.Logger {
+	var l log.Logger
+	if strings.EqualFold(format, "json") {
+		l = newJSONLogger(w)
+	} else {
+		l = log.NewStdLogger(w)
+	}
+	return log.With(l, "ts", log.DefaultTimestamp)
+}
@@ -0,0 +1,126 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestParseStream(t *testing.T) {
+	stream, c, err := ParseStream("access:output=/var/log/access.log,format=JSON,level=warn,max-size=10,max-backups=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream != Access || c.Output != "/var/log/access.log" || c.Format != "json" || c.Level != log.LevelWarn ||
+		c.MaxSize != 10<<20 || c.MaxBackups != 0 {
+		t.Fatalf("unexpected config of %s: %+v", stream.Name(), c)
+	}
+	if _, c, _ := ParseStream("debug"); c.Output != "stdout" || c.Level != log.LevelDebug {
+		t.Fatalf("want the debug stream written to stdout at debug level but got %+v", c)
+	}
+	for _, spec := range []string{
+		"trace:output=stdout",
+		"access:output",
+		"access:level=verbose",
+		"access:format=xml",
+		"access:max-size=0",
+		"access:rotate=daily",
+	} {
+		if _, _, err := ParseStream(spec); err == nil {
+			t.Fatalf("want %q rejected", spec)
+		}
+	}
+}
+
+func TestSetup(t *testing.T) {
+	global := &bytes.Buffer{}
+	defaultLogger := log.GetLogger()
+	log.SetLogger(log.NewStdLogger(global))
+	defer log.SetLogger(defaultLogger)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gateway.log")
+	closeLogs, err := Setup([]string{
+		"access:output=" + path + ",format=json",
+		"error:output=" + path + ",level=error",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Access.Configured() || Audit.Configured() {
+		t.Fatal("want only the streams in the specs configured")
+	}
+	log.NewHelper(Access).Infow("path", "/users", "error", errors.New("upstream reset"))
+	log.NewHelper(Error).Warn("filtered")
+	log.NewHelper(Error).Error("failed")
+	log.NewHelper(Audit).Info("audit record")
+	closeLogs()
+
+	if Access.Configured() {
+		t.Fatal("want the streams restored once closed")
+	}
+	if !strings.Contains(global.String(), "audit record") {
+		t.Fatalf("want the unconfigured stream written to the global logger but got %q", global.String())
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want the access and error logs in the shared file but got %q", b)
+	}
+	access := map[string]string{}
+	if err := json.Unmarshal([]byte(lines[0]), &access); err != nil {
+		t.Fatal(err)
+	}
+	if access["level"] != "INFO" || access["stream"] != "access" || access["path"] != "/users" ||
+		access["error"] != "upstream reset" || access["ts"] == "" {
+		t.Fatalf("unexpected access log: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], "stream=error") || !strings.Contains(lines[1], "failed") {
+		t.Fatalf("unexpected error log: %s", lines[1])
+	}
+}
+
+func TestRotateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	f, err := openRotateFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if b, _ := os.ReadFile(path); string(b) != "third\n" {
+		t.Fatalf("want the file rotated but got %q", b)
+	}
+	if b, _ := os.ReadFile(path + ".1"); string(b) != "second\n" {
+		t.Fatalf("want the only backup kept but got %q", b)
+	}
+}
+
+func TestGlobalLevel(t *testing.T) {
+	defer SetLevel(GetLevel())
+	out := &bytes.Buffer{}
+	logger := log.With(NewGlobalLogger(out, "text", log.LevelWarn), "caller", "test")
+	log.NewHelper(logger).Info("dropped")
+	logger.Log(log.LevelDebug, ForceKey, "t1", "msg", "forced")
+	if got := out.String(); strings.Contains(got, "dropped") || !strings.Contains(got, "forced") {
+		t.Fatalf("want only the forced log written but got %q", got)
+	}
+	SetLevel(log.LevelInfo)
+	log.NewHelper(logger).Info("written")
+	if !strings.Contains(out.String(), "written") {
+		t.Fatalf("want the level adjusted at runtime but got %q", out.String())
+	}
+}
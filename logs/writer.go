@@ -0,0 +1,136 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// jsonLogger writes each log as a JSON object in a line, the values are written as strings
+// if they are errors, fmt.Stringers or not JSON marshalable.
+type jsonLogger struct {
+	lock sync.Mutex
+	w    io.Writer
+	buf  bytes.Buffer
+}
+
+func newJSONLogger(w io.Writer) *jsonLogger {
+	return &jsonLogger{w: w}
+}
+
+// Log implements log.Logger.
+func (l *jsonLogger) Log(level log.Level, keyvals ...any) error {
+	if len(keyvals)%2 != 0 {
+		keyvals = append(keyvals, "KEYVALS UNPAIRED")
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.buf.Reset()
+	l.buf.WriteString(`{"level":`)
+	writeJSON(&l.buf, level.String())
+	for i := 0; i < len(keyvals); i += 2 {
+		l.buf.WriteByte(',')
+		writeJSON(&l.buf, fmt.Sprint(keyvals[i]))
+		l.buf.WriteByte(':')
+		writeJSON(&l.buf, keyvals[i+1])
+	}
+	l.buf.WriteString("}\n")
+	_, err := l.w.Write(l.buf.Bytes())
+	return err
+}
+
+func writeJSON(buf *bytes.Buffer, v any) {
+	switch x := v.(type) {
+	case error:
+		v = x.Error()
+	case fmt.Stringer:
+		v = x.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
+
+// rotateFile appends to the file, it is rotated to path.1, path.2 ... when it exceeds the max size.
+type rotateFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+func openRotateFile(path string, maxSize int64, maxBackups int) (*rotateFile, error) {
+	f := &rotateFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotateFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+func (f *rotateFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+func (f *rotateFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if f.maxBackups > 0 {
+		_ = os.Remove(f.backup(f.maxBackups))
+		for i := f.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(f.path, f.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Write implements io.Writer, the logs are not synced to keep the access logs cheap.
+func (f *rotateFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotateFile) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.file.Close()
+}
@@ -6,14 +6,14 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"github.com/aide-family/magicbox/log"
-	"github.com/aide-family/magicbox/log/stdio"
 	klog "github.com/go-kratos/kratos/v2/log"
 
 	"github.com/aide-family/goddess/cmd"
+	"github.com/aide-family/goddess/cmd/config"
+	"github.com/aide-family/goddess/cmd/control"
 	"github.com/aide-family/goddess/cmd/gateway"
 	"github.com/aide-family/goddess/cmd/version"
-	"github.com/aide-family/goddess/pkg/merr"
+	"github.com/aide-family/goddess/logs"
 )
 
 var (
@@ -44,19 +44,12 @@ func main() {
 	children := []*cobra.Command{
 		version.NewCmd(),
 		gateway.NewCmd(),
+		config.NewCmd(),
+		control.NewCmd(),
 	}
 	cmd.Execute(cmd.NewCmd(), children...)
 }
 
 func init() {
-	logger, err := log.NewLogger(stdio.LoggerDriver())
-	if err != nil {
-		panic(merr.ErrorInternal("new logger failed with error: %v", err).WithCause(err))
-	}
-	logger = klog.With(logger,
-		"ts", klog.DefaultTimestamp,
-	)
-	filterLogger := klog.NewFilter(logger, klog.FilterLevel(klog.LevelInfo))
-	helper := klog.NewHelper(filterLogger)
-	klog.SetLogger(helper.Logger())
+	klog.SetLogger(logs.NewGlobalLogger(os.Stdout, "text", klog.LevelInfo))
 }
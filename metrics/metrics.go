@@ -0,0 +1,40 @@
+// Package metrics collects the prometheus collectors of the gateway packages, they are registered into the
+// registerer of the proxy, see proxy.WithRegisterer, instead of the global registry on init.
+package metrics
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	_collectorsLock sync.Mutex
+	_collectors     []prometheus.Collector
+)
+
+// Register adds the collectors of a package, eg: in its init, they are registered by RegisterInto.
+func Register(cs ...prometheus.Collector) {
+	_collectorsLock.Lock()
+	defer _collectorsLock.Unlock()
+	_collectors = append(_collectors, cs...)
+}
+
+// RegisterInto registers the added collectors into r, registering them more than once is a no-op.
+//
+// The collectors are shared by the registerers, eg: a counter of the admission counts the requests of all the
+// proxies, unlike the proxy core metrics which are built per registerer.
+func RegisterInto(r prometheus.Registerer) error {
+	_collectorsLock.Lock()
+	defer _collectorsLock.Unlock()
+	for _, c := range _collectors {
+		if err := r.Register(c); err != nil {
+			var are prometheus.AlreadyRegisteredError
+			if !errors.As(err, &are) {
+				return err
+			}
+		}
+	}
+	return nil
+}
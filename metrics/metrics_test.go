@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegisterInto(t *testing.T) {
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_register_into_total", Help: "test"})
+	Register(counter)
+	counter.Inc()
+	a, b := prometheus.NewRegistry(), prometheus.NewRegistry()
+	for _, r := range []*prometheus.Registry{a, b, a} {
+		// registering into the same registerer again is a no-op
+		if err := RegisterInto(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range []*prometheus.Registry{a, b} {
+		if got, err := testutil.GatherAndCount(r, "test_register_into_total"); err != nil || got != 1 {
+			t.Fatalf("want the collector registered into every registerer but got %d, %v", got, err)
+		}
+	}
+	// a collector conflicting with the registered ones is reported
+	Register(prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_register_into_total", Help: "conflict"}))
+	defer func() { _collectors = _collectors[:len(_collectors)-1] }()
+	if err := RegisterInto(prometheus.NewRegistry()); err == nil {
+		t.Fatal("want the conflict reported")
+	}
+}
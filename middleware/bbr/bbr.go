@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
@@ -13,13 +14,17 @@ import (
 
 var _nopBody = io.NopCloser(&bytes.Buffer{})
 
+// _limiters is the limiters of the middlewares not closed, they are read by Pressure.
+var _limiters sync.Map
+
 func init() {
-	middleware.Register("bbr", Middleware)
+	middleware.RegisterV2("bbr", Middleware)
 }
 
-func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
 	limiter := bbr.NewLimiter() // use default settings
-	return func(next http.RoundTripper) http.RoundTripper {
+	_limiters.Store(limiter, struct{}{})
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
 		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
 			done, err := limiter.Allow()
 			if err != nil {
@@ -33,5 +38,26 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			done(ratelimit.DoneInfo{Err: err})
 			return resp, err
 		})
-	}, nil
+	}, closer(func() error {
+		_limiters.Delete(limiter)
+		return nil
+	})), nil
+}
+
+type closer func() error
+
+func (c closer) Close() error { return c() }
+
+// Pressure returns the largest ratio of the inflight requests to the max inflight estimated by the limiters,
+// the limiters without the estimation yet are skipped.
+func Pressure() float64 {
+	pressure := 0.0
+	_limiters.Range(func(key, _ any) bool {
+		stat := key.(*bbr.BBR).Stat()
+		if stat.MaxInFlight > 0 {
+			pressure = max(pressure, float64(stat.InFlight)/float64(stat.MaxInFlight))
+		}
+		return true
+	})
+	return pressure
 }
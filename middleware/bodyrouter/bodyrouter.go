@@ -0,0 +1,301 @@
+// Package bodyrouter is a middleware that selects the upstream nodes by a field of the JSON request body.
+package bodyrouter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/bodyrouter/v1"
+	"github.com/go-kratos/kratos/v2/selector"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const _defaultMaxBodyBytes = 64 << 10
+
+func init() {
+	middleware.Register("bodyrouter", Middleware)
+}
+
+// _routed is the request value of the matched route, the body is inspected once for all the attempts.
+var _routed = middleware.DefineKey[*route]("bodyrouter.route", middleware.Hidden())
+
+type route struct {
+	values   map[string]struct{}
+	regex    *regexp.Regexp
+	metadata map[string]string
+}
+
+func (r *route) match(value string) bool {
+	if _, ok := r.values[value]; ok {
+		return true
+	}
+	return r.regex != nil && r.regex.MatchString(value)
+}
+
+// filter selects the nodes whose metadata contains all the pairs of the route.
+func (r *route) filter(_ context.Context, nodes []selector.Node) []selector.Node {
+	out := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		md := n.Metadata()
+		matched := true
+		for k, v := range r.metadata {
+			if md[k] != v {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+type router struct {
+	path         []segment
+	contentTypes []string
+	maxBodyBytes int64
+	routes       []*route
+}
+
+func newRouter(options *v1.BodyRouter) (*router, error) {
+	path, err := parsePath(options.Path)
+	if err != nil {
+		return nil, err
+	}
+	r := &router{path: path, maxBodyBytes: options.MaxBodyBytes, contentTypes: []string{"application/json"}}
+	if r.maxBodyBytes <= 0 {
+		r.maxBodyBytes = _defaultMaxBodyBytes
+	}
+	if len(options.ContentTypes) > 0 {
+		r.contentTypes = r.contentTypes[:0]
+		for _, ct := range options.ContentTypes {
+			r.contentTypes = append(r.contentTypes, strings.ToLower(ct))
+		}
+	}
+	if len(options.Routes) == 0 {
+		return nil, errors.New("bodyrouter: routes are required")
+	}
+	for i, rc := range options.Routes {
+		if len(rc.Values) == 0 && rc.Regex == "" {
+			return nil, fmt.Errorf("bodyrouter: route %d: values or regex is required", i)
+		}
+		rt := &route{values: make(map[string]struct{}, len(rc.Values)), metadata: rc.NodeMetadata}
+		for _, v := range rc.Values {
+			rt.values[v] = struct{}{}
+		}
+		if rc.Regex != "" {
+			if rt.regex, err = regexp.Compile(rc.Regex); err != nil {
+				return nil, fmt.Errorf("bodyrouter: route %d: %w", i, err)
+			}
+		}
+		r.routes = append(r.routes, rt)
+	}
+	return r, nil
+}
+
+func (r *router) acceptContentType(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, ct := range r.contentTypes {
+		if ct == mediaType || (strings.HasSuffix(ct, "/*") && strings.HasPrefix(mediaType, ct[:len(ct)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// peek returns the first max_body_bytes of the body, the body buffered by the proxy is shared by GetBody.
+func (r *router) peek(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(io.LimitReader(body, r.maxBodyBytes))
+	}
+	data, err := io.ReadAll(io.LimitReader(req.Body, r.maxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+	req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), req.Body), Closer: req.Body}
+	return data, nil
+}
+
+// route returns the matched route of the request, nil if the body is not inspected or none matches.
+func (r *router) route(req *http.Request) (*route, error) {
+	if req.Body == nil || req.Body == http.NoBody || !r.acceptContentType(req.Header) {
+		return nil, nil
+	}
+	data, err := r.peek(req)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := lookup(data, r.path)
+	if !ok {
+		return nil, nil
+	}
+	for _, rt := range r.routes {
+		if rt.match(value) {
+			return rt, nil
+		}
+	}
+	return nil, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Middleware is a body router middleware.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.BodyRouter{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	r, err := newRouter(options)
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			opts, ok := middleware.FromRequestContext(req.Context())
+			if !ok || opts.Endpoint.Stream {
+				return next.RoundTrip(req)
+			}
+			if _, routed := _routed.Get(opts); routed {
+				return next.RoundTrip(req)
+			}
+			rt, err := r.route(req)
+			if err != nil {
+				return nil, err
+			}
+			_routed.Set(opts, rt)
+			if rt != nil {
+				middleware.WithSelectorFitler(req.Context(), rt.filter)
+			}
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
+
+// segment is a map key or an array index of the path.
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parsePath parses the JSONPath-ish path, eg: "$.method" or "$.params[0].kind".
+func parsePath(in string) ([]segment, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(in, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("bodyrouter: invalid path: %q", in)
+	}
+	var out []segment
+	for _, part := range strings.Split(p, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			out = append(out, segment{key: key})
+		}
+		for rest != "" {
+			idx, after, ok := strings.Cut(rest, "]")
+			n, err := strconv.Atoi(idx)
+			if !ok || err != nil || n < 0 || (after != "" && after[0] != '[') {
+				return nil, fmt.Errorf("bodyrouter: invalid path: %q", in)
+			}
+			out = append(out, segment{index: n, isIdx: true})
+			rest = strings.TrimPrefix(after, "[")
+		}
+		if key == "" && !strings.Contains(part, "[") {
+			return nil, fmt.Errorf("bodyrouter: invalid path: %q", in)
+		}
+	}
+	return out, nil
+}
+
+// lookup scans the JSON body for the scalar value of the path, the body may be truncated after the value.
+// The numbers and booleans are returned in their JSON form.
+func lookup(data []byte, path []segment) (string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for _, seg := range path {
+		tok, err := dec.Token()
+		if err != nil {
+			return "", false
+		}
+		switch tok {
+		case json.Delim('{'):
+			if seg.isIdx {
+				return "", false
+			}
+			found := false
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return "", false
+				}
+				if key == seg.key {
+					found = true
+					break
+				}
+				if err := skip(dec); err != nil {
+					return "", false
+				}
+			}
+			if !found {
+				return "", false
+			}
+		case json.Delim('['):
+			if !seg.isIdx {
+				return "", false
+			}
+			for i := 0; i < seg.index; i++ {
+				if !dec.More() || skip(dec) != nil {
+					return "", false
+				}
+			}
+			if !dec.More() {
+				return "", false
+			}
+		default:
+			return "", false
+		}
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return "", false
+	}
+	switch v := tok.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func skip(dec *json.Decoder) error {
+	var raw json.RawMessage
+	return dec.Decode(&raw)
+}
@@ -0,0 +1,117 @@
+package bodyrouter
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/bodyrouter/v1"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/go-kratos/kratos/v2/selector"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		body  string
+		path  string
+		want  string
+		found bool
+	}{
+		{body: `{"id":1,"method":"orders.create","params":{}}`, path: "$.method", want: "orders.create", found: true},
+		{body: `{"params":[{"kind":"a"},{"kind":"b"}]}`, path: "params[1].kind", want: "b", found: true},
+		{body: `{"version":2,"dry":true}`, path: "version", want: "2", found: true},
+		{body: `{"version":2,"dry":true}`, path: "dry", want: "true", found: true},
+		// the body is truncated after the value
+		{body: `{"method":"orders.create","params":{"items":[1,2`, path: "method", want: "orders.create", found: true},
+		{body: `{"params":{"items":[1,2`, path: "method"},
+		{body: `{"method":{"name":"orders.create"}}`, path: "method"},
+		{body: `[{"method":"orders.create"}]`, path: "method"},
+		{body: `not json`, path: "method"},
+	}
+	for _, test := range tests {
+		path, err := parsePath(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, found := lookup([]byte(test.body), path); got != test.want || found != test.found {
+			t.Errorf("lookup(%s, %s) = %q %v, want %q %v", test.body, test.path, got, found, test.want, test.found)
+		}
+	}
+	for _, path := range []string{"", "$", "a[x]", "a[0]b", "a..b"} {
+		if _, err := parsePath(path); err == nil {
+			t.Errorf("want the path %q rejected", path)
+		}
+	}
+}
+
+func TestBodyRouter(t *testing.T) {
+	opts, err := anypb.New(&v1.BodyRouter{
+		Path: "$.method",
+		Routes: []*v1.Route{
+			{Values: []string{"orders.create", "orders.cancel"}, NodeMetadata: map[string]string{"pool": "orders"}},
+			{Regex: `^users\.`, NodeMetadata: map[string]string{"pool": "users"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "bodyrouter", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []selector.Node{
+		selector.NewNode("http", "10.0.0.1:80", &registry.ServiceInstance{Metadata: map[string]string{"pool": "orders"}}),
+		selector.NewNode("http", "10.0.0.2:80", &registry.ServiceInstance{Metadata: map[string]string{"pool": "users"}}),
+	}
+	do := func(endpoint *config.Endpoint, contentType, body string) ([]selector.Node, string) {
+		data := []byte(body)
+		req := httptest.NewRequest(http.MethodPost, "/api", bytes.NewReader(data))
+		req.Header.Set("Content-Type", contentType)
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+		reqOpts := middleware.NewRequestOptions(endpoint)
+		req = req.WithContext(middleware.NewRequestContext(context.Background(), reqOpts))
+		var upstream string
+		_, err := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			b, _ := io.ReadAll(req.Body)
+			upstream = string(b)
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+		})).RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		selected := nodes
+		for _, filter := range reqOpts.Filters {
+			selected = filter(context.Background(), selected)
+		}
+		return selected, upstream
+	}
+
+	endpoint := &config.Endpoint{Path: "/api"}
+	body := `{"method":"orders.create","params":{}}`
+	if selected, upstream := do(endpoint, "application/json; charset=utf-8", body); len(selected) != 1 || selected[0].Address() != "10.0.0.1:80" || upstream != body {
+		t.Fatalf("want routed to the orders pool with the body intact but got %v %q", selected, upstream)
+	}
+	if selected, _ := do(endpoint, "application/json", `{"method":"users.get"}`); len(selected) != 1 || selected[0].Address() != "10.0.0.2:80" {
+		t.Fatalf("want routed to the users pool but got %v", selected)
+	}
+	if selected, _ := do(endpoint, "application/json", `{"method":"other"}`); len(selected) != 2 {
+		t.Fatalf("want all nodes for the unmatched method but got %v", selected)
+	}
+	if selected, _ := do(endpoint, "text/plain", body); len(selected) != 2 {
+		t.Fatalf("want the other content types not inspected but got %v", selected)
+	}
+	if selected, _ := do(&config.Endpoint{Path: "/api", Stream: true}, "application/json", body); len(selected) != 2 {
+		t.Fatalf("want the streams not inspected but got %v", selected)
+	}
+
+	if _, err := newRouter(&v1.BodyRouter{Path: "method"}); err == nil || !strings.Contains(err.Error(), "routes") {
+		t.Fatalf("want the config without routes rejected but got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/metrics"
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	v1 "github.com/aide-family/goddess/pkg/middleware/circuitbreaker/v1"
@@ -26,7 +27,7 @@ var clientBuildContext atomic.Pointer[client.BuildContext]
 
 func init() {
 	clientBuildContext.Store(client.EmptyBuildContext())
-	prometheus.MustRegister(_metricDeniedTotal)
+	metrics.Register(_metricDeniedTotal)
 }
 
 func Init(buildContext *client.BuildContext, clientFactory client.Factory) {
@@ -177,6 +178,10 @@ func New(factory client.Factory) middleware.FactoryV2 {
 					return onBreakHandler.RoundTrip(req)
 				}
 				resp, err := next.RoundTrip(req)
+				if source, _ := middleware.ResponseSource.FromContext(req.Context()); source != "" {
+					// replied by the gateway instead of the upstream, eg: rejected by the stream limits
+					return resp, err
+				}
 				if err != nil {
 					breaker.MarkFailed()
 					return nil, err
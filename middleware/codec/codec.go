@@ -0,0 +1,274 @@
+// Package codec is a middleware that translates the bodies of requests and responses between encodings, eg:
+// the JSON of the clients to the MessagePack of the upstream.
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/codec/v1"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	_name                = "codec"
+	_defaultMaxBodyBytes = 1 << 20
+)
+
+// the reason codes of the rejected requests
+const (
+	rejectionUnsupportedMediaType = "unsupported_media_type"
+	rejectionBodyTooLarge         = "body_too_large"
+	rejectionTranslationFailed    = "translation_failed"
+)
+
+// Codec translates the bodies of an encoding to and from JSON, the bodies are translated between two encodings
+// through JSON.
+type Codec interface {
+	// ContentType is the Content-Type of the bodies encoded by FromJSON.
+	ContentType() string
+	// Match reports whether the bodies of the media type are in the encoding.
+	Match(mediaType string) bool
+	ToJSON(data []byte) ([]byte, error)
+	FromJSON(data []byte) ([]byte, error)
+}
+
+// Resolver resolves the protobuf messages by the full names.
+type Resolver func(name protoreflect.FullName) (protoreflect.MessageType, error)
+
+// Encoding is the encoding of the config a codec is created for.
+type Encoding struct {
+	Name string
+	// Message is the full name of the protobuf message, empty if not configured.
+	Message string
+	// Resolver resolves the messages of the descriptor set of the config, or the messages linked into the gateway.
+	Resolver Resolver
+}
+
+// Factory creates the codec of the encoding.
+type Factory func(e *Encoding) (Codec, error)
+
+var codecs = map[string]Factory{}
+
+// Register registers the codec of the encoding name, eg: in the init of the package of a custom encoding. The
+// names are case-insensitive and the codec registered later replaces the former of the same name.
+func Register(name string, factory Factory) {
+	codecs[strings.ToLower(name)] = factory
+}
+
+func init() {
+	middleware.Register(_name, Middleware, middleware.Shareable())
+	Register("json", func(*Encoding) (Codec, error) { return jsonCodec{}, nil })
+	Register("msgpack", newMsgpack)
+	Register("protobuf", newProtobuf)
+}
+
+func newCodec(in *v1.Encoding, resolver Resolver) (Codec, error) {
+	if in == nil || in.Name == "" {
+		return nil, errors.New("encoding name is required")
+	}
+	factory, ok := codecs[strings.ToLower(in.Name)]
+	if !ok {
+		return nil, fmt.Errorf("encoding %q is not registered", in.Name)
+	}
+	return factory(&Encoding{Name: in.Name, Message: in.Message, Resolver: resolver})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (jsonCodec) Match(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func (jsonCodec) ToJSON(data []byte) ([]byte, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("json: invalid data")
+	}
+	return data, nil
+}
+
+func (c jsonCodec) FromJSON(data []byte) ([]byte, error) {
+	return c.ToJSON(data)
+}
+
+type translation struct {
+	from, to         Codec
+	fromName, toName string
+}
+
+func newTranslation(in *v1.Translation, resolver Resolver) (*translation, error) {
+	if in == nil {
+		return nil, nil
+	}
+	from, err := newCodec(in.From, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	to, err := newCodec(in.To, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+	return &translation{from: from, to: to, fromName: in.From.Name, toName: in.To.Name}, nil
+}
+
+// translate returns the body translated, the empty body is kept, eg: the responses of HEAD.
+func (t *translation) translate(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	doc, err := t.from.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", t.fromName, err)
+	}
+	out, err := t.to.FromJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", t.toName, err)
+	}
+	return out, nil
+}
+
+func mediaType(header http.Header) string {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType
+}
+
+func encoded(header http.Header) bool {
+	encoding := header.Get("Content-Encoding")
+	return encoding != "" && encoding != "identity"
+}
+
+// readBody reads the body up to max bytes, false if the body is larger.
+func readBody(in io.ReadCloser, max int64) ([]byte, bool, error) {
+	defer in.Close()
+	data, err := io.ReadAll(io.LimitReader(in, max+1))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, int64(len(data)) <= max, nil
+}
+
+func reject(req *http.Request, code int, reason string, err error) (*http.Response, error) {
+	return middleware.Reject(req, _name, reason, kerrors.New(code, "CODEC_"+strings.ToUpper(reason), err.Error()))
+}
+
+func newBadGatewayResponse(req *http.Request, err error) *http.Response {
+	middleware.MarkResponseSource(req, _name)
+	body, _ := json.Marshal(kerrors.New(http.StatusBadGateway, "CODEC_FAILED", err.Error()))
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode:    http.StatusBadGateway,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// Middleware is a body codec, the request bodies of the clients not in the encoding are rejected by 415 and the
+// response bodies of the upstream not in the encoding are passed through, eg: the errors of the upstream in JSON.
+// The bodies of the stream endpoints and the bodies larger than max_body_bytes are refused.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Codec{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	resolver := Resolver(resolveLinked)
+	if options.DescriptorSet != "" {
+		var err error
+		if resolver, err = newDescriptorResolver(options.DescriptorSet); err != nil {
+			return nil, err
+		}
+	}
+	requestTranslation, err := newTranslation(options.Request, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("request: %w", err)
+	}
+	responseTranslation, err := newTranslation(options.Response, resolver)
+	if err != nil {
+		return nil, fmt.Errorf("response: %w", err)
+	}
+	maxBodyBytes := options.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = _defaultMaxBodyBytes
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			endpoint, _ := middleware.EndpointFromContext(req.Context())
+			stream := endpoint != nil && endpoint.Stream
+			if requestTranslation != nil && req.Body != nil && req.Body != http.NoBody {
+				if stream {
+					return reject(req, http.StatusUnsupportedMediaType, rejectionUnsupportedMediaType, errors.New("the bodies of the stream endpoints are not translated"))
+				}
+				if mt := mediaType(req.Header); !requestTranslation.from.Match(mt) || encoded(req.Header) {
+					return reject(req, http.StatusUnsupportedMediaType, rejectionUnsupportedMediaType,
+						fmt.Errorf("the body of %q is not %s", req.Header.Get("Content-Type"), requestTranslation.fromName))
+				}
+				data, ok, err := readBody(req.Body, maxBodyBytes)
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return reject(req, http.StatusRequestEntityTooLarge, rejectionBodyTooLarge, fmt.Errorf("the body is larger than %d bytes", maxBodyBytes))
+				}
+				out, err := requestTranslation.translate(data)
+				if err != nil {
+					return reject(req, http.StatusUnsupportedMediaType, rejectionTranslationFailed, err)
+				}
+				req.Body = io.NopCloser(bytes.NewReader(out))
+				req.ContentLength = int64(len(out))
+				req.Header.Set("Content-Type", requestTranslation.to.ContentType())
+				req.Header.Set("Content-Length", strconv.Itoa(len(out)))
+			}
+			if responseTranslation != nil {
+				req.Header.Set("Accept", responseTranslation.from.ContentType())
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if responseTranslation == nil || resp.Body == nil || resp.Body == http.NoBody || !responseTranslation.from.Match(mediaType(resp.Header)) {
+				return resp, nil
+			}
+			if stream || encoded(resp.Header) {
+				resp.Body.Close()
+				return newBadGatewayResponse(req, fmt.Errorf("the streamed or compressed body of %s is not translated", responseTranslation.fromName)), nil
+			}
+			data, ok, err := readBody(resp.Body, maxBodyBytes)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return newBadGatewayResponse(req, fmt.Errorf("the body is larger than %d bytes", maxBodyBytes)), nil
+			}
+			out, err := responseTranslation.translate(data)
+			if err != nil {
+				return newBadGatewayResponse(req, err), nil
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(out))
+			resp.ContentLength = int64(len(out))
+			resp.Header.Set("Content-Type", responseTranslation.to.ContentType())
+			if resp.Header.Get("Content-Length") != "" {
+				resp.Header.Set("Content-Length", strconv.Itoa(len(out)))
+			}
+			return resp, nil
+		})
+	}, nil
+}
@@ -0,0 +1,232 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/codec/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Codec) middleware.Middleware {
+	t.Helper()
+	opts, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "codec", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func translate(from, to string) *v1.Translation {
+	return &v1.Translation{From: &v1.Encoding{Name: from}, To: &v1.Encoding{Name: to}}
+}
+
+func response(contentType string, body []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{contentType}, "Content-Length": []string{strconv.Itoa(len(body))}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestMsgpackUpstream(t *testing.T) {
+	m := newMiddleware(t, &v1.Codec{Request: translate("json", "msgpack"), Response: translate("msgpack", "json")})
+	// {"id":7,"ok":true}
+	upstreamBody, _ := hex.DecodeString("82a2696407a26f6bc3")
+	var received []byte
+	rt := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		received, _ = io.ReadAll(req.Body)
+		if req.Header.Get("Content-Type") != "application/msgpack" || req.Header.Get("Accept") != "application/msgpack" {
+			t.Fatalf("want the msgpack request but got %v", req.Header)
+		}
+		if req.ContentLength != int64(len(received)) || req.Header.Get("Content-Length") != strconv.Itoa(len(received)) {
+			t.Fatalf("want content length %d but got %d", len(received), req.ContentLength)
+		}
+		return response("application/x-msgpack", upstreamBody), nil
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(`{"sku":"a-1","qty":2}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hex.EncodeToString(received); got != "82a3736b75a3612d31a371747902" {
+		t.Fatalf("want the msgpack body but got %s", got)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != `{"id":7,"ok":true}` || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("want the json response but got %s %v", body, resp.Header)
+	}
+	if resp.ContentLength != int64(len(body)) || resp.Header.Get("Content-Length") != strconv.Itoa(len(body)) {
+		t.Fatalf("want content length %d but got %d", len(body), resp.ContentLength)
+	}
+}
+
+func TestRefused(t *testing.T) {
+	m := newMiddleware(t, &v1.Codec{Request: translate("json", "msgpack"), Response: translate("msgpack", "json"), MaxBodyBytes: 16})
+	upstream := func(resp *http.Response) http.RoundTripper {
+		return m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return resp, nil
+		}))
+	}
+	do := func(rt http.RoundTripper, contentType, body string, endpoint *config.Endpoint) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		if endpoint != nil {
+			req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	ok := func() http.RoundTripper { return upstream(response("application/msgpack", []byte{0xc0})) }
+	for _, tc := range []struct {
+		name        string
+		rt          http.RoundTripper
+		contentType string
+		body        string
+		endpoint    *config.Endpoint
+		want        int
+	}{
+		{name: "media type", rt: ok(), contentType: "text/plain", body: `{}`, want: http.StatusUnsupportedMediaType},
+		{name: "invalid json", rt: ok(), contentType: "application/json", body: `{"a":`, want: http.StatusUnsupportedMediaType},
+		{name: "too large", rt: ok(), contentType: "application/json", body: `{"sku":"0123456789"}`, want: http.StatusRequestEntityTooLarge},
+		{name: "stream", rt: ok(), contentType: "application/json", body: `{}`, endpoint: &config.Endpoint{Stream: true}, want: http.StatusUnsupportedMediaType},
+		{name: "invalid upstream", rt: upstream(response("application/msgpack", []byte{0xc1})), contentType: "application/json", body: `{}`, want: http.StatusBadGateway},
+		{name: "large upstream", rt: upstream(response("application/msgpack", bytes.Repeat([]byte{0xc0}, 17))), contentType: "application/json", body: `{}`, want: http.StatusBadGateway},
+		{name: "upstream error in json", rt: upstream(&http.Response{StatusCode: http.StatusNotFound, Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(bytes.NewBufferString(`{}`))}), contentType: "application/json", body: `{}`, want: http.StatusNotFound},
+		{name: "translated", rt: ok(), contentType: "application/json", body: `{}`, want: http.StatusOK},
+	} {
+		if resp := do(tc.rt, tc.contentType, tc.body, tc.endpoint); resp.StatusCode != tc.want {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("%s: want %d but got %d %s", tc.name, tc.want, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	for _, options := range []*v1.Codec{
+		{Request: translate("json", "yaml")},
+		{Request: &v1.Translation{From: &v1.Encoding{Name: "json"}}},
+		{Response: translate("protobuf", "json")},
+		{Response: &v1.Translation{From: &v1.Encoding{Name: "protobuf", Message: "codectest.v1.Missing"}, To: &v1.Encoding{Name: "json"}}},
+		{DescriptorSet: filepath.Join(t.TempDir(), "missing.pb")},
+	} {
+		opts, _ := anypb.New(options)
+		if _, err := Middleware(&config.Middleware{Name: "codec", Options: opts}); err == nil {
+			t.Fatalf("want error of %v", options)
+		}
+	}
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, label descriptorpb.FieldDescriptorProto_Label, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(), Label: label.Enum()}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	optional, repeated := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL, descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("codectest/v1/order.proto"),
+		Package: proto.String("codectest.v1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("Order"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, optional, ""),
+				field("sku", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+				field("payload", 3, descriptorpb.FieldDescriptorProto_TYPE_BYTES, optional, ""),
+				field("items", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, repeated, ".codectest.v1.Order.Item"),
+			},
+			NestedType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, optional, ""),
+					field("price", 2, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, optional, ""),
+					field("count", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32, optional, ""),
+				},
+			}},
+		}},
+	}}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "order.pb")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProtobufClient(t *testing.T) {
+	order := &v1.Encoding{Name: "protobuf", Message: "codectest.v1.Order"}
+	m := newMiddleware(t, &v1.Codec{
+		DescriptorSet: writeDescriptorSet(t),
+		Request:       &v1.Translation{From: order, To: &v1.Encoding{Name: "json"}},
+		Response:      &v1.Translation{From: &v1.Encoding{Name: "json"}, To: order},
+	})
+	var received string
+	rt := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		received = string(b)
+		// the strings of the 64-bit integers and the numbers are both accepted
+		return response("application/json", []byte(received)), nil
+	}))
+
+	resolver, err := newDescriptorResolver(writeDescriptorSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := newProtobuf(&Encoding{Name: "protobuf", Message: order.Message, Resolver: resolver})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the nested messages, the 64-bit integer beyond the float precision and the binary field
+	want := `{"id":"9007199254740993","sku":"42","payload":"AP8Q","items":[{"name":"a","price":9.5,"count":2},{"name":"b"}]}`
+	body, err := c.FromJSON([]byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received != want {
+		t.Fatalf("want %s but got %s", want, received)
+	}
+	// the order of the fields of the dynamic messages on the wire is not stable, the documents are compared
+	out, _ := io.ReadAll(resp.Body)
+	if doc, err := c.ToJSON(out); err != nil || string(doc) != want || resp.Header.Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("want the protobuf response but got %x %v", out, resp.Header)
+	}
+
+	if _, err := c.FromJSON([]byte(`{"id":1,"count":1}`)); err == nil {
+		t.Fatal("want the unknown field rejected")
+	}
+	if doc, err := c.FromJSON([]byte(`{"id":12}`)); err != nil || len(doc) == 0 {
+		t.Fatalf("want the number of the 64-bit integer accepted but got %v", err)
+	}
+}
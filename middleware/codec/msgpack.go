@@ -0,0 +1,395 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// the nesting of the arrays and the maps the MessagePack bodies are decoded to
+const _maxMsgpackDepth = 1000
+
+var errMsgpackTruncated = errors.New("msgpack: unexpected end of data")
+
+// msgpackCodec translates MessagePack, the JSON numbers are the integers if they are integral and the floats
+// otherwise, the JSON strings are always the str values and the bin values are the base64 strings of JSON.
+type msgpackCodec struct{}
+
+func newMsgpack(*Encoding) (Codec, error) {
+	return msgpackCodec{}, nil
+}
+
+func (msgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackCodec) Match(mediaType string) bool {
+	switch mediaType {
+	case "application/msgpack", "application/x-msgpack", "application/vnd.msgpack":
+		return true
+	}
+	return false
+}
+
+func (msgpackCodec) FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	out, err := appendJSONValue(nil, dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("json: invalid data after the top-level value")
+	}
+	return out, nil
+}
+
+// appendJSONValue appends the MessagePack of the next JSON value of dec, the keys of the objects keep their order.
+func appendJSONValue(b []byte, dec *json.Decoder) ([]byte, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		var items []byte
+		n := 0
+		for dec.More() {
+			if v == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				items = appendMsgpackString(items, key.(string))
+			}
+			if items, err = appendJSONValue(items, dec); err != nil {
+				return nil, err
+			}
+			n++
+		}
+		// the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		if v == '{' {
+			b = appendMsgpackHeader(b, n, 0x80, 0xde, 0xdf)
+		} else {
+			b = appendMsgpackHeader(b, n, 0x90, 0xdc, 0xdd)
+		}
+		return append(b, items...), nil
+	case nil:
+		return append(b, 0xc0), nil
+	case bool:
+		if v {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case string:
+		return appendMsgpackString(b, v), nil
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendMsgpackInt(b, i), nil
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendMsgpackUint(b, u), nil
+		}
+		f, err := strconv.ParseFloat(string(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("json: number %s out of range", v)
+		}
+		b = append(b, 0xcb)
+		return binary.BigEndian.AppendUint64(b, math.Float64bits(f)), nil
+	}
+	return nil, fmt.Errorf("json: unexpected token %v", tok)
+}
+
+// appendMsgpackHeader appends the header of an array or a map of n items, fix is the type of the fix format of
+// less than 16 items.
+func appendMsgpackHeader(b []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, b16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, b32), uint32(n))
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(b, uint64(i))
+	case i >= -32:
+		return append(b, byte(i))
+	case i >= math.MinInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+}
+
+func appendMsgpackUint(b []byte, u uint64) []byte {
+	switch {
+	case u <= math.MaxInt8:
+		return append(b, byte(u))
+	case u <= math.MaxUint8:
+		return append(b, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), u)
+}
+
+func (msgpackCodec) ToJSON(data []byte) ([]byte, error) {
+	r := &msgpackReader{data: data}
+	r.enc = json.NewEncoder(&r.out)
+	r.enc.SetEscapeHTML(false)
+	if err := r.value(0); err != nil {
+		return nil, err
+	}
+	if r.off != len(r.data) {
+		return nil, errors.New("msgpack: invalid data after the top-level value")
+	}
+	return r.out.Bytes(), nil
+}
+
+// msgpackReader writes the JSON of the MessagePack data, the keys of the maps keep their order.
+type msgpackReader struct {
+	data []byte
+	off  int
+	out  bytes.Buffer
+	enc  *json.Encoder
+}
+
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.off < n {
+		return nil, errMsgpackTruncated
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b, nil
+}
+
+// uint reads the big endian unsigned integer of n bytes.
+func (r *msgpackReader) uint(n int) (uint64, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range b {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+// length reads the length of n bytes of a str, a bin, an array or a map.
+func (r *msgpackReader) length(n int) (int, error) {
+	u, err := r.uint(n)
+	return int(u), err
+}
+
+// writeString writes the JSON string of s, the encoder escapes it and appends a newline dropped here.
+func (r *msgpackReader) writeString(s string) {
+	r.enc.Encode(s)
+	r.out.Truncate(r.out.Len() - 1)
+}
+
+func (r *msgpackReader) value(depth int) error {
+	if depth > _maxMsgpackDepth {
+		return errors.New("msgpack: exceeded max depth")
+	}
+	typ, err := r.next(1)
+	if err != nil {
+		return err
+	}
+	t := typ[0]
+	switch {
+	case t <= 0x7f:
+		r.out.Write(strconv.AppendUint(r.out.AvailableBuffer(), uint64(t), 10))
+		return nil
+	case t >= 0xe0:
+		r.out.Write(strconv.AppendInt(r.out.AvailableBuffer(), int64(int8(t)), 10))
+		return nil
+	case t&0xf0 == 0x80:
+		return r.object(int(t&0x0f), depth)
+	case t&0xf0 == 0x90:
+		return r.array(int(t&0x0f), depth)
+	case t&0xe0 == 0xa0:
+		return r.str(int(t & 0x1f))
+	}
+	switch t {
+	case 0xc0:
+		r.out.WriteString("null")
+	case 0xc2:
+		r.out.WriteString("false")
+	case 0xc3:
+		r.out.WriteString("true")
+	case 0xc4, 0xc5, 0xc6:
+		n, err := r.length(1 << (t - 0xc4))
+		if err != nil {
+			return err
+		}
+		b, err := r.next(n)
+		if err != nil {
+			return err
+		}
+		r.writeString(base64.StdEncoding.EncodeToString(b))
+	case 0xca, 0xcb:
+		size := 4 << (t - 0xca)
+		u, err := r.uint(size)
+		if err != nil {
+			return err
+		}
+		f := math.Float64frombits(u)
+		if size == 4 {
+			f = float64(math.Float32frombits(uint32(u)))
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("msgpack: float %v is not supported by json", f)
+		}
+		r.out.Write(appendJSONFloat(r.out.AvailableBuffer(), f, size*8))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := r.uint(1 << (t - 0xcc))
+		if err != nil {
+			return err
+		}
+		r.out.Write(strconv.AppendUint(r.out.AvailableBuffer(), u, 10))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (t - 0xd0)
+		u, err := r.uint(size)
+		if err != nil {
+			return err
+		}
+		// sign extends the integer of size bytes
+		shift := 64 - size*8
+		r.out.Write(strconv.AppendInt(r.out.AvailableBuffer(), int64(u<<shift)>>shift, 10))
+	case 0xd9, 0xda, 0xdb:
+		n, err := r.length(1 << (t - 0xd9))
+		if err != nil {
+			return err
+		}
+		return r.str(n)
+	case 0xdc, 0xdd:
+		n, err := r.length(2 << (t - 0xdc))
+		if err != nil {
+			return err
+		}
+		return r.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := r.length(2 << (t - 0xde))
+		if err != nil {
+			return err
+		}
+		return r.object(n, depth)
+	default:
+		// the ext types and the never used 0xc1
+		return fmt.Errorf("msgpack: type 0x%02x is not supported", t)
+	}
+	return nil
+}
+
+// appendJSONFloat appends the float in the format of encoding/json, the exponent is used for the very small and
+// the very large ones only.
+func appendJSONFloat(b []byte, f float64, bits int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+		bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+func (r *msgpackReader) str(n int) error {
+	b, err := r.next(n)
+	if err != nil {
+		return err
+	}
+	r.writeString(string(b))
+	return nil
+}
+
+func (r *msgpackReader) array(n, depth int) error {
+	r.out.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			r.out.WriteByte(',')
+		}
+		if err := r.value(depth + 1); err != nil {
+			return err
+		}
+	}
+	r.out.WriteByte(']')
+	return nil
+}
+
+func (r *msgpackReader) object(n, depth int) error {
+	r.out.WriteByte('{')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			r.out.WriteByte(',')
+		}
+		if err := r.key(); err != nil {
+			return err
+		}
+		r.out.WriteByte(':')
+		if err := r.value(depth + 1); err != nil {
+			return err
+		}
+	}
+	r.out.WriteByte('}')
+	return nil
+}
+
+// key writes the key of a map, the str keys and the integer keys quoted.
+func (r *msgpackReader) key() error {
+	if r.off >= len(r.data) {
+		return errMsgpackTruncated
+	}
+	t := r.data[r.off]
+	switch {
+	case t&0xe0 == 0xa0 || (t >= 0xd9 && t <= 0xdb):
+		return r.value(0)
+	case t <= 0x7f || t >= 0xe0 || (t >= 0xcc && t <= 0xcf) || (t >= 0xd0 && t <= 0xd3):
+		r.out.WriteByte('"')
+		if err := r.value(0); err != nil {
+			return err
+		}
+		r.out.WriteByte('"')
+		return nil
+	}
+	return fmt.Errorf("msgpack: map key of type 0x%02x is not supported", t)
+}
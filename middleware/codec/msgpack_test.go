@@ -0,0 +1,78 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	c := msgpackCodec{}
+	for _, in := range []string{
+		`{"order":{"id":42,"items":[{"sku":"a-1","qty":2,"price":9.5},{"sku":"b-2","qty":1,"price":-0.25}],"tags":[]},"note":null,"paid":true}`,
+		// the numbers and the strings of numbers are kept apart
+		`{"int":123,"str":"123","neg":-129,"big":18446744073709551615,"min":-9223372036854775808,"float":1.5e-7}`,
+		`[[[[]]],{},"",false,"<escaped & \"quoted\">","日本"]`,
+		`"top-level string"`,
+	} {
+		packed, err := c.FromJSON([]byte(in))
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		out, err := c.ToJSON(packed)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if string(out) != in {
+			t.Fatalf("want %s but got %s", in, out)
+		}
+	}
+}
+
+func TestMsgpackFormat(t *testing.T) {
+	c := msgpackCodec{}
+	packed, err := c.FromJSON([]byte(`{"a":1,"b":"1","c":[true,null],"d":-1,"e":300,"f":0.5}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "86" + "a161" + "01" + "a162" + "a131" + "a163" + "92c3c0" + "a164" + "ff" + "a165" + "cd012c" + "a166" + "cb3fe0000000000000"
+	if got := hex.EncodeToString(packed); got != want {
+		t.Fatalf("want %s but got %s", want, got)
+	}
+}
+
+func TestMsgpackToJSON(t *testing.T) {
+	c := msgpackCodec{}
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		// the bin values are base64 strings
+		{in: "81a464617461c403010203", want: `{"data":"AQID"}`},
+		// the integer keys, int8, int16, uint32 and float32
+		{in: "8201d0f802d1fc18", want: `{"1":-8,"2":-1000}`},
+		{in: "92ce00010000ca3fc00000", want: `[65536,1.5]`},
+		{in: "dc0002a0a0", want: `["",""]`},
+	} {
+		data, _ := hex.DecodeString(tc.in)
+		out, err := c.ToJSON(data)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+		if string(out) != tc.want {
+			t.Fatalf("want %s but got %s", tc.want, out)
+		}
+	}
+	for _, in := range []string{
+		// truncated str, ext type, bin map key, trailing data and NaN
+		"a5616263", "d40100", "81c40161c0", "c0c0", "cb7ff8000000000000",
+	} {
+		data, _ := hex.DecodeString(in)
+		if _, err := c.ToJSON(data); err == nil {
+			t.Fatalf("%s: want error", in)
+		}
+	}
+	if _, err := c.ToJSON(append(bytes.Repeat([]byte{0x91}, _maxMsgpackDepth+2), 0xc0)); err == nil {
+		t.Fatal("want the max depth exceeded")
+	}
+}
@@ -0,0 +1,103 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// protobufCodec translates the protobuf messages of a type by the JSON mapping of protobuf, eg: the bytes fields
+// are the base64 strings and the 64-bit integers are the strings of JSON, both the strings and the numbers are
+// accepted from JSON.
+type protobufCodec struct {
+	message protoreflect.MessageType
+}
+
+func newProtobuf(e *Encoding) (Codec, error) {
+	if e.Message == "" {
+		return nil, errors.New("protobuf: message is required")
+	}
+	message, err := e.Resolver(protoreflect.FullName(e.Message))
+	if err != nil {
+		return nil, fmt.Errorf("protobuf: message %q: %w", e.Message, err)
+	}
+	return &protobufCodec{message: message}, nil
+}
+
+func (*protobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (*protobufCodec) Match(mediaType string) bool {
+	switch mediaType {
+	case "application/x-protobuf", "application/protobuf", "application/vnd.google.protobuf":
+		return true
+	}
+	return false
+}
+
+func (c *protobufCodec) ToJSON(data []byte) ([]byte, error) {
+	m := c.message.New().Interface()
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	doc, err := protojson.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomizes the spaces of the output on purpose, the translated bodies are kept stable
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *protobufCodec) FromJSON(data []byte) ([]byte, error) {
+	m := c.message.New().Interface()
+	if err := protojson.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(m)
+}
+
+func resolveLinked(name protoreflect.FullName) (protoreflect.MessageType, error) {
+	return protoregistry.GlobalTypes.FindMessageByName(name)
+}
+
+// newDescriptorResolver returns the resolver of the messages of the serialized FileDescriptorSet file.
+func newDescriptorResolver(path string) (Resolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("descriptor set %s: %w", path, err)
+	}
+	return func(name protoreflect.FullName) (protoreflect.MessageType, error) {
+		d, err := files.FindDescriptorByName(name)
+		if err != nil {
+			return nil, err
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a message", name)
+		}
+		return dynamicpb.NewMessageType(md), nil
+	}, nil
+}
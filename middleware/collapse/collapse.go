@@ -0,0 +1,263 @@
+// Package collapse is a middleware that coalesces the concurrent identical GET requests into one upstream request.
+package collapse
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/collapse/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	_defaultWaitTimeout      = 5 * time.Second
+	_defaultMaxResponseBytes = 1 << 20
+)
+
+var (
+	_metricCollapsedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "collapse_requests_total",
+		Help:      "The total number of the requests by the coalescing result",
+	}, []string{"path", "result"})
+	_metricCollapseWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "collapse_wait_seconds",
+		Help:      "The time the coalesced requests waited for the shared response",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+	}, []string{"path"})
+)
+
+func init() {
+	middleware.Register("collapse", Middleware)
+	metrics.Register(_metricCollapsedTotal, _metricCollapseWaitSeconds)
+}
+
+// sharedResponse is the response copied to the waiting requests.
+type sharedResponse struct {
+	statusCode int
+	proto      string
+	protoMajor int
+	protoMinor int
+	header     http.Header
+	trailer    http.Header
+	body       []byte
+}
+
+func (s *sharedResponse) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(s.statusCode),
+		StatusCode:    s.statusCode,
+		Proto:         s.proto,
+		ProtoMajor:    s.protoMajor,
+		ProtoMinor:    s.protoMinor,
+		Header:        s.header.Clone(),
+		Trailer:       s.trailer.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(s.body)),
+		ContentLength: int64(len(s.body)),
+		Request:       req,
+	}
+}
+
+// call is the upstream request in flight, shared is nil if the response is not shared.
+type call struct {
+	done   chan struct{}
+	shared *sharedResponse
+}
+
+type collapser struct {
+	waitTimeout        time.Duration
+	maxResponseBytes   int64
+	shareNon200        bool
+	keyHeaders         []string
+	allowAuthorization bool
+
+	lock  sync.Mutex
+	calls map[string]*call
+}
+
+func newCollapser(options *v1.Collapse) *collapser {
+	c := &collapser{
+		waitTimeout:        _defaultWaitTimeout,
+		maxResponseBytes:   options.MaxResponseBytes,
+		shareNon200:        options.ShareNon_200,
+		keyHeaders:         append(append([]string{}, _negotiationHeaders...), options.KeyHeaders...),
+		allowAuthorization: options.AllowAuthorization,
+		calls:              map[string]*call{},
+	}
+	if options.WaitTimeout != nil {
+		c.waitTimeout = options.WaitTimeout.AsDuration()
+	}
+	if c.maxResponseBytes <= 0 {
+		c.maxResponseBytes = _defaultMaxResponseBytes
+	}
+	return c
+}
+
+// _credentialHeaders are the request headers of the user credentials.
+var _credentialHeaders = []string{"Authorization", "Cookie"}
+
+// _negotiationHeaders are the request headers selecting the variant of the response, eg: the gzip body, they are
+// always part of the key so the requests are coalesced only with the ones negotiating the same variant.
+var _negotiationHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language"}
+
+// key returns the coalescing key of the request, false if the request is never coalesced.
+func (c *collapser) key(req *http.Request) (string, bool) {
+	if req.Method != http.MethodGet {
+		return "", false
+	}
+	var b strings.Builder
+	b.WriteString(req.Host)
+	b.WriteByte(' ')
+	b.WriteString(req.URL.RequestURI())
+	// the credentials select the per-user responses, the requests are coalesced only with the same ones
+	for _, h := range _credentialHeaders {
+		values := req.Header.Values(h)
+		if len(values) == 0 {
+			continue
+		}
+		if !c.allowAuthorization {
+			return "", false
+		}
+		b.WriteByte('\n')
+		b.WriteString(h)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(values, "; "))
+	}
+	for _, h := range c.keyHeaders {
+		b.WriteByte('\n')
+		b.WriteString(h)
+		b.WriteString(": ")
+		b.WriteString(strings.Join(req.Header.Values(h), ","))
+	}
+	return b.String(), true
+}
+
+// share reads the response of the leader into the shared response, the body of the response is restored
+// for the leader. nil is returned if the response is not shareable.
+func (c *collapser) share(resp *http.Response) (*sharedResponse, error) {
+	if resp.StatusCode != http.StatusOK && !c.shareNon200 {
+		return nil, nil
+	}
+	if resp.ContentLength > c.maxResponseBytes {
+		return nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxResponseBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > c.maxResponseBytes {
+		resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return nil, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	shared := &sharedResponse{
+		statusCode: resp.StatusCode,
+		proto:      resp.Proto,
+		protoMajor: resp.ProtoMajor,
+		protoMinor: resp.ProtoMinor,
+		header:     resp.Header.Clone(),
+		trailer:    resp.Trailer.Clone(),
+		body:       body,
+	}
+	// the cookies set for the leader are never handed to the other clients
+	shared.header.Del("Set-Cookie")
+	return shared, nil
+}
+
+func (c *collapser) roundTrip(next http.RoundTripper, req *http.Request, path string) (*http.Response, error) {
+	key, ok := c.key(req)
+	if !ok {
+		return next.RoundTrip(req)
+	}
+	c.lock.Lock()
+	if inflight, ok := c.calls[key]; ok {
+		c.lock.Unlock()
+		return c.wait(next, req, path, inflight)
+	}
+	leader := &call{done: make(chan struct{})}
+	c.calls[key] = leader
+	c.lock.Unlock()
+	_metricCollapsedTotal.WithLabelValues(path, "leader").Inc()
+
+	defer func() {
+		c.lock.Lock()
+		delete(c.calls, key)
+		c.lock.Unlock()
+		close(leader.done)
+	}()
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if leader.shared, err = c.share(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// wait waits for the response of the leader, the request is sent upstream by itself if the response is not
+// shared or not received in time.
+func (c *collapser) wait(next http.RoundTripper, req *http.Request, path string, leader *call) (*http.Response, error) {
+	start := time.Now()
+	timer := time.NewTimer(c.waitTimeout)
+	defer timer.Stop()
+	select {
+	case <-leader.done:
+		_metricCollapseWaitSeconds.WithLabelValues(path).Observe(time.Since(start).Seconds())
+		if leader.shared == nil {
+			_metricCollapsedTotal.WithLabelValues(path, "not_shared").Inc()
+			return next.RoundTrip(req)
+		}
+		_metricCollapsedTotal.WithLabelValues(path, "collapsed").Inc()
+		return leader.shared.response(req), nil
+	case <-timer.C:
+		_metricCollapseWaitSeconds.WithLabelValues(path).Observe(time.Since(start).Seconds())
+		_metricCollapsedTotal.WithLabelValues(path, "timeout").Inc()
+		return next.RoundTrip(req)
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Middleware is a request coalescing middleware.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Collapse{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	collapser := newCollapser(options)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			e, ok := middleware.EndpointFromContext(req.Context())
+			if ok && e.Stream {
+				return next.RoundTrip(req)
+			}
+			path := ""
+			if ok {
+				path = e.Path
+			}
+			return collapser.roundTrip(next, req, path)
+		})
+	}, nil
+}
@@ -0,0 +1,246 @@
+package collapse
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/collapse/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestMiddleware(t *testing.T, options *v1.Collapse) middleware.Middleware {
+	opts, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "collapse", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestCollapse(t *testing.T) {
+	var upstream atomic.Int32
+	release := make(chan struct{})
+	status, body := http.StatusOK, "popular"
+	tripper := newTestMiddleware(t, &v1.Collapse{})(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream.Add(1)
+		<-release
+		return &http.Response{StatusCode: status, Header: http.Header{"X-Upstream": {"1"}}, Body: io.NopCloser(strings.NewReader(body))}, nil
+	}))
+	do := func(n int, prepare func(*http.Request)) []string {
+		var wg sync.WaitGroup
+		out := make([]string, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodGet, "/resource?id=1", nil)
+				if prepare != nil {
+					prepare(req)
+				}
+				resp, err := tripper.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				b, _ := io.ReadAll(resp.Body)
+				out[i] = resp.Header.Get("X-Upstream") + ":" + string(b)
+			}()
+		}
+		// the requests wait for the first one
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		release = make(chan struct{})
+		return out
+	}
+
+	got := do(10, nil)
+	if upstream.Load() != 1 {
+		t.Fatalf("want one upstream request but got %d", upstream.Load())
+	}
+	for _, resp := range got {
+		if resp != "1:popular" {
+			t.Fatalf("want the response shared but got %q", resp)
+		}
+	}
+
+	// the requests with Authorization are not coalesced by default
+	upstream.Store(0)
+	do(3, func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") })
+	if upstream.Load() != 3 {
+		t.Fatalf("want the authorized requests sent upstream but got %d", upstream.Load())
+	}
+
+	// the non 200 responses are not shared by default
+	upstream.Store(0)
+	status = http.StatusServiceUnavailable
+	do(3, nil)
+	if upstream.Load() != 3 {
+		t.Fatalf("want the non 200 responses not shared but got %d", upstream.Load())
+	}
+}
+
+func TestCollapseLimits(t *testing.T) {
+	var upstream atomic.Int32
+	tripper := newTestMiddleware(t, &v1.Collapse{MaxResponseBytes: 4, WaitTimeout: durationpb.New(10 * time.Millisecond)})(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream.Add(1)
+		if req.URL.Query().Get("slow") != "" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("too large"))}, nil
+	}))
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := tripper.RoundTrip(httptest.NewRequest(http.MethodGet, "/resource?slow=1", nil))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			// the response of the leader is intact
+			if b, _ := io.ReadAll(resp.Body); string(b) != "too large" {
+				t.Errorf("unexpected body: %q", b)
+			}
+		}()
+	}
+	wg.Wait()
+	// the waiting requests time out and the large responses are not shared
+	if upstream.Load() != 3 {
+		t.Fatalf("want all the requests sent upstream but got %d", upstream.Load())
+	}
+}
+
+func TestCollapseCredentials(t *testing.T) {
+	var upstream atomic.Int32
+	release := make(chan struct{})
+	tripper := newTestMiddleware(t, &v1.Collapse{AllowAuthorization: true})(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream.Add(1)
+		<-release
+		user := req.Header.Get("Cookie")
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Set-Cookie": {user + "-refreshed"}}, Body: io.NopCloser(strings.NewReader(user))}, nil
+	}))
+	var wg sync.WaitGroup
+	type result struct{ body, setCookie string }
+	results := make(map[string][]result)
+	var lock sync.Mutex
+	for _, cookie := range []string{"session=alice", "session=bob"} {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+				req.Header.Set("Cookie", cookie)
+				resp, err := tripper.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				b, _ := io.ReadAll(resp.Body)
+				lock.Lock()
+				results[cookie] = append(results[cookie], result{body: string(b), setCookie: resp.Header.Get("Set-Cookie")})
+				lock.Unlock()
+			}()
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	// one upstream request for each user
+	if upstream.Load() != 2 {
+		t.Fatalf("want the requests coalesced by the cookie but got %d upstream requests", upstream.Load())
+	}
+	for cookie, got := range results {
+		leaders := 0
+		for _, r := range got {
+			if r.body != cookie {
+				t.Fatalf("want the response of %s but got %q", cookie, r.body)
+			}
+			switch r.setCookie {
+			case cookie + "-refreshed":
+				leaders++
+			case "":
+			default:
+				t.Fatalf("want no cookie of the other users but got %q", r.setCookie)
+			}
+		}
+		if leaders != 1 {
+			t.Fatalf("want Set-Cookie for the leader only but got %d", leaders)
+		}
+	}
+
+	// the requests with cookies are not coalesced by default
+	upstream.Store(0)
+	tripper = newTestMiddleware(t, &v1.Collapse{})(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}))
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+			req.Header.Set("Cookie", "session=alice")
+			if _, err := tripper.RoundTrip(req); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if upstream.Load() != 3 {
+		t.Fatalf("want the requests with cookies sent upstream but got %d", upstream.Load())
+	}
+}
+
+func TestCollapseNegotiation(t *testing.T) {
+	var upstream atomic.Int32
+	release := make(chan struct{})
+	tripper := newTestMiddleware(t, &v1.Collapse{})(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream.Add(1)
+		<-release
+		header := http.Header{"Vary": {"Accept-Encoding"}}
+		encoding := "identity"
+		if strings.Contains(req.Header.Get("Accept-Encoding"), "gzip") {
+			encoding = "gzip"
+			header.Set("Content-Encoding", encoding)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(encoding))}, nil
+	}))
+	var wg sync.WaitGroup
+	for _, encoding := range []string{"gzip", "identity"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			resp, err := tripper.RoundTrip(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if b, _ := io.ReadAll(resp.Body); string(b) != encoding {
+				t.Errorf("want the %s variant but got %q", encoding, b)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if upstream.Load() != 2 {
+		t.Fatalf("want the requests of the different encodings not coalesced but got %d upstream requests", upstream.Load())
+	}
+}
@@ -43,7 +43,7 @@ const (
 )
 
 func init() {
-	middleware.Register("cors", Middleware)
+	middleware.Register("cors", Middleware, middleware.Shareable())
 }
 
 func isOriginAllowed(origin string, allowOriginHosts []string) bool {
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aide-family/goddess/metrics"
+	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultMonitorHeader = "X-Auth-Monitor"
+	monitorHeaderValue   = "would-block"
+)
+
+var _metricWouldBlockTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "would_block_total",
+	Help:      "The total number of requests the auth middlewares in the monitor mode would have blocked",
+}, []string{"middleware", "route", "reason"})
+
+func init() {
+	metrics.Register(_metricWouldBlockTotal)
+}
+
+// Enforcement decides what the auth middlewares do with the requests failing the validation,
+// they are rejected in the enforce mode, and recorded but let through in the monitor mode.
+type Enforcement struct {
+	name    string
+	monitor bool
+	header  string
+	// the headers of the identity the middleware sets, removed from the requests let through
+	identityHeaders []string
+}
+
+// NewEnforcement returns the enforcement of the middleware config.
+func NewEnforcement(c *configv1.Middleware) *Enforcement {
+	header := c.MonitorHeader
+	if header == "" {
+		header = defaultMonitorHeader
+	}
+	return &Enforcement{
+		name:    c.Name,
+		monitor: c.EnforcementMode == configv1.EnforcementMode_MONITOR,
+		header:  header,
+	}
+}
+
+// WithIdentityHeaders sets the request headers of the identity set by the middleware once the validation
+// passes, eg: X-User-ID, they are removed from the requests let through in the monitor mode so the values sent
+// by the clients never reach the upstream.
+func (e *Enforcement) WithIdentityHeaders(headers ...string) *Enforcement {
+	e.identityHeaders = headers
+	return e
+}
+
+// Reject handles the request failing the validation for the reason code, the request is rejected by Reject
+// in the enforce mode, otherwise it is passed to next.
+func (e *Enforcement) Reject(next http.RoundTripper, req *http.Request, reason string, err error) (*http.Response, error) {
+	if !e.monitor {
+		return Reject(req, e.name, reason, err)
+	}
+	route := ""
+	if labels, ok := MetricsLabelsFromContext(req.Context()); ok {
+		route = labels.Path()
+	}
+	_metricWouldBlockTotal.WithLabelValues(e.name, route, reason).Inc()
+	LOG.Warnw(log.DefaultMessageKey, "Request would be blocked by the middleware in the monitor mode",
+		"reason", reason, "middleware", e.name, "route", route, "method", req.Method, "path", req.URL.Path, "error", err)
+	for _, h := range e.identityHeaders {
+		req.Header.Del(h)
+	}
+	resp, rerr := next.RoundTrip(req)
+	if resp != nil {
+		if resp.Header == nil {
+			resp.Header = http.Header{}
+		}
+		resp.Header.Set(e.header, monitorHeaderValue)
+	}
+	return resp, rerr
+}
@@ -0,0 +1,161 @@
+// Package identity is a middleware that sends a short-lived JWT minted by the gateway to the upstream, so the
+// upstream verifies the request came through the gateway and the route and the consumer it represents rather
+// than trusting the headers of the client. The upstreams verify the tokens by the verify package.
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/identity/verify"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/identity/v1"
+)
+
+const _defaultTTL = time.Minute
+
+func init() {
+	middleware.Register("identity", Middleware, middleware.Shareable())
+}
+
+// Middleware mints the token once the upstream node is selected, so the consumer set by the auth middlewares
+// is covered whatever their order, and every attempt of the retries has a fresh token. The value of the header
+// sent by the client is always dropped.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Identity{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	key, err := newKeySource(options.Key)
+	if err != nil {
+		return nil, err
+	}
+	m := &minter{
+		key:      key,
+		header:   http.CanonicalHeaderKey(options.Header),
+		issuer:   options.Issuer,
+		audience: options.Audience,
+		ttl:      options.Ttl.AsDuration(),
+		scopes:   options.Scopes,
+		instance: options.Instance,
+	}
+	if m.header == "" {
+		m.header = verify.DefaultHeader
+	}
+	if m.issuer == "" {
+		m.issuer = verify.DefaultIssuer
+	}
+	if m.ttl <= 0 {
+		m.ttl = _defaultTTL
+	}
+	if m.instance == "" {
+		m.instance, _ = os.Hostname()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Del(m.header)
+			if _, ok := middleware.FromRequestContext(req.Context()); !ok {
+				if err := m.sign(req); err != nil {
+					return nil, err
+				}
+				return next.RoundTrip(req)
+			}
+			middleware.WithUpstreamRequestHook(req.Context(), m.sign)
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
+
+// minter mints the tokens, the claims of the routes are encoded once and the per request claims are appended.
+type minter struct {
+	key      keySource
+	header   string
+	issuer   string
+	audience string
+	ttl      time.Duration
+	scopes   []string
+	instance string
+
+	// the encoded claims of the routes without the closing brace
+	templates sync.Map
+}
+
+// template returns the encoded static claims of the route.
+func (m *minter) template(route string) []byte {
+	if t, ok := m.templates.Load(route); ok {
+		return t.([]byte)
+	}
+	claims := struct {
+		Issuer   string   `json:"iss"`
+		Audience string   `json:"aud,omitempty"`
+		Gateway  string   `json:"gw,omitempty"`
+		Route    string   `json:"route,omitempty"`
+		Scopes   []string `json:"scope,omitempty"`
+	}{m.issuer, m.audience, m.instance, route, m.scopes}
+	b, _ := json.Marshal(claims)
+	t := b[:len(b)-1]
+	m.templates.Store(route, t)
+	return t
+}
+
+func (m *minter) sign(req *http.Request) error {
+	route, consumer := "", ""
+	if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+		if reqOpts.Endpoint != nil {
+			route = reqOpts.Endpoint.Path
+		}
+		consumer, _ = middleware.ConsumerID.Get(reqOpts)
+	}
+	token, err := m.mint(route, consumer, time.Now())
+	if err != nil {
+		return err
+	}
+	req.Header.Set(m.header, token)
+	return nil
+}
+
+func (m *minter) mint(route, consumer string, now time.Time) (string, error) {
+	s := m.key.signer()
+	template := m.template(route)
+	payload := make([]byte, 0, len(template)+128)
+	payload = append(payload, template...)
+	if consumer != "" {
+		encoded, err := json.Marshal(consumer)
+		if err != nil {
+			return "", err
+		}
+		payload = append(payload, `,"consumer":`...)
+		payload = append(payload, encoded...)
+	}
+	payload = append(payload, `,"iat":`...)
+	payload = strconv.AppendInt(payload, now.Unix(), 10)
+	payload = append(payload, `,"exp":`...)
+	payload = strconv.AppendInt(payload, now.Add(m.ttl).Unix(), 10)
+	var jti [16]byte
+	if _, err := rand.Read(jti[:]); err != nil {
+		return "", err
+	}
+	payload = append(payload, `,"jti":"`...)
+	payload = hex.AppendEncode(payload, jti[:])
+	payload = append(payload, `"}`...)
+
+	signingString := s.header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig, err := s.method.Sign(signingString, s.key)
+	if err != nil {
+		return "", err
+	}
+	return signingString + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
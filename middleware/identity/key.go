@@ -0,0 +1,212 @@
+package identity
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aide-family/goddess/middleware/identity/verify"
+	v1 "github.com/aide-family/goddess/pkg/middleware/identity/v1"
+)
+
+// the interval the key files are re-read
+const _reloadInterval = 30 * time.Second
+
+var _metricKeyReloadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "identity_key_reload_failures_total",
+	Help:      "The total number of the failed reloads of the identity signing key files, the previous key is kept",
+}, []string{"file"})
+
+func init() {
+	metrics.Register(_metricKeyReloadFailures)
+}
+
+// signer is a loaded signing key, the encoded JOSE header is cached.
+type signer struct {
+	method jwtv5.SigningMethod
+	key    any
+	kid    string
+	header string
+}
+
+func newSigner(key any, kid string) (*signer, error) {
+	var method jwtv5.SigningMethod
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return nil, errors.New("identity: empty secret")
+		}
+		method = jwtv5.SigningMethodHS256
+	case *rsa.PrivateKey:
+		method = jwtv5.SigningMethodRS256
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			method = jwtv5.SigningMethodES256
+		case elliptic.P384():
+			method = jwtv5.SigningMethodES384
+		default:
+			return nil, fmt.Errorf("identity: unsupported curve %s", k.Curve.Params().Name)
+		}
+	case ed25519.PrivateKey:
+		method = jwtv5.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("identity: unsupported key type %T", key)
+	}
+	if kid == "" {
+		var err error
+		if kid, err = verify.Thumbprint(key); err != nil {
+			return nil, err
+		}
+	}
+	header, err := json.Marshal(map[string]string{"alg": method.Alg(), "kid": kid, "typ": "JWT"})
+	if err != nil {
+		return nil, err
+	}
+	return &signer{method: method, key: key, kid: kid, header: base64.RawURLEncoding.EncodeToString(header)}, nil
+}
+
+// parseKey parses the PEM private key, or returns the content as the HMAC secret if it is not PEM.
+func parseKey(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		if bytes.Contains(data, []byte("-----BEGIN")) {
+			return nil, errors.New("invalid PEM block")
+		}
+		return bytes.TrimSpace(data), nil
+	}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// keySource returns the current signer.
+type keySource interface {
+	signer() *signer
+}
+
+type staticKey struct {
+	s *signer
+}
+
+func (k staticKey) signer() *signer {
+	return k.s
+}
+
+func newKeySource(c *v1.SigningKey) (keySource, error) {
+	switch key := c.GetKey().(type) {
+	case *v1.SigningKey_Secret:
+		s, err := newSigner([]byte(key.Secret), c.Kid)
+		if err != nil {
+			return nil, err
+		}
+		return staticKey{s: s}, nil
+	case *v1.SigningKey_File:
+		return getKeyFile(key.File, c.Kid)
+	}
+	return nil, errors.New("identity: the signing key is required")
+}
+
+var (
+	_keyFilesLock sync.Mutex
+	_keyFiles     = map[[2]string]*keyFile{}
+)
+
+// getKeyFile returns the key file shared by the middlewares, the file is re-read for the lifetime of the process
+// so the rebuilt middlewares of the reloads share it. The error of the first load is returned.
+func getKeyFile(path, kid string) (*keyFile, error) {
+	_keyFilesLock.Lock()
+	defer _keyFilesLock.Unlock()
+	id := [2]string{path, kid}
+	if f, ok := _keyFiles[id]; ok {
+		return f, nil
+	}
+	f := &keyFile{path: path, kid: kid}
+	if _, err := f.reload(); err != nil {
+		return nil, err
+	}
+	go f.run(_reloadInterval)
+	_keyFiles[id] = f
+	return f, nil
+}
+
+// keyFile holds the signer of the key file, the previous key is kept if the file is invalid.
+type keyFile struct {
+	path string
+	kid  string
+
+	// the reloads are applied one by one
+	lock    sync.Mutex
+	data    []byte
+	current atomic.Pointer[signer]
+}
+
+func (f *keyFile) signer() *signer {
+	return f.current.Load()
+}
+
+func (f *keyFile) reload() (changed bool, err error) {
+	defer func() {
+		if err != nil {
+			_metricKeyReloadFailures.WithLabelValues(f.path).Inc()
+		}
+	}()
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(data, f.data) {
+		return false, nil
+	}
+	key, err := parseKey(data)
+	if err != nil {
+		return false, fmt.Errorf("identity: invalid key file %s: %w", f.path, err)
+	}
+	s, err := newSigner(key, f.kid)
+	if err != nil {
+		return false, fmt.Errorf("identity: invalid key file %s: %w", f.path, err)
+	}
+	f.data = data
+	f.current.Store(s)
+	return true, nil
+}
+
+func (f *keyFile) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		changed, err := f.reload()
+		if err != nil {
+			log.Errorf("Failed to reload the identity key %s, keeping the key %s: %v", f.path, f.signer().kid, err)
+			continue
+		}
+		if changed {
+			log.Infof("Reloaded the identity key %s, the tokens are signed by the key %s", f.path, f.signer().kid)
+		}
+	}
+}
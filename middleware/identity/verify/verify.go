@@ -0,0 +1,284 @@
+// Package verify verifies the identity tokens minted by the identity middleware of the gateway, it is imported by
+// the upstream services and depends on nothing of the gateway.
+//
+//	v := verify.New(verify.WithKey("", publicKey), verify.WithAudience("orders"))
+//	http.Handle("/", v.Handler(mux))
+//
+// The handler replies 401 to the requests without a valid token and the claims of the others are read by
+// FromContext.
+package verify
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultHeader is the default header of the identity tokens.
+	DefaultHeader = "X-Gateway-Identity"
+	// DefaultIssuer is the default iss claim of the identity tokens.
+	DefaultIssuer = "goddess"
+)
+
+var (
+	// ErrMissingToken is returned if the request has no identity token.
+	ErrMissingToken = errors.New("verify: missing identity token")
+	// ErrUnknownKey is returned if the kid of the token is not a key of the verifier.
+	ErrUnknownKey = errors.New("verify: unknown key")
+)
+
+// Claims are the claims of the identity tokens.
+type Claims struct {
+	// Gateway is the gateway instance minting the token.
+	Gateway string `json:"gw,omitempty"`
+	// Route is the path of the endpoint of the request.
+	Route string `json:"route,omitempty"`
+	// Consumer is the authenticated consumer of the request, eg: set by the jwt middleware, empty if anonymous.
+	Consumer string   `json:"consumer,omitempty"`
+	Scopes   []string `json:"scope,omitempty"`
+	jwtv5.RegisteredClaims
+}
+
+// HasScope reports whether the token has the scope.
+func (c *Claims) HasScope(scope string) bool {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Option is the option of the verifier.
+type Option func(*Verifier)
+
+// WithKey adds the verification key, the public key of the signing key or the HMAC secret as []byte, the kid is
+// the thumbprint of the key if empty, see Thumbprint.
+func WithKey(kid string, key any) Option {
+	return func(v *Verifier) {
+		if err := v.AddKey(kid, key); err != nil {
+			v.err = errors.Join(v.err, err)
+		}
+	}
+}
+
+// WithHeader sets the header of the tokens, default is DefaultHeader.
+func WithHeader(header string) Option {
+	return func(v *Verifier) {
+		v.header = header
+	}
+}
+
+// WithIssuer sets the expected iss claim, default is DefaultIssuer.
+func WithIssuer(issuer string) Option {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience sets the expected aud claim, not checked if empty.
+func WithAudience(audience string) Option {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// WithLeeway sets the leeway of the exp and iat claims for the clock skew, default is 5 seconds.
+func WithLeeway(leeway time.Duration) Option {
+	return func(v *Verifier) {
+		v.leeway = leeway
+	}
+}
+
+// Verifier verifies the identity tokens by the kid of the tokens, the keys are added and removed at runtime to
+// rotate them.
+type Verifier struct {
+	header   string
+	issuer   string
+	audience string
+	leeway   time.Duration
+	err      error
+
+	lock sync.RWMutex
+	keys map[string]any
+}
+
+// New creates the verifier, the error of the invalid keys is returned by Err.
+func New(opts ...Option) *Verifier {
+	v := &Verifier{
+		header: DefaultHeader,
+		issuer: DefaultIssuer,
+		leeway: 5 * time.Second,
+		keys:   map[string]any{},
+	}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+// Err returns the error of the keys of the options.
+func (v *Verifier) Err() error {
+	return v.err
+}
+
+// AddKey adds the verification key, the kid is the thumbprint of the key if empty.
+func (v *Verifier) AddKey(kid string, key any) error {
+	if _, err := methodsOf(key); err != nil {
+		return err
+	}
+	if kid == "" {
+		var err error
+		if kid, err = Thumbprint(key); err != nil {
+			return err
+		}
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	v.keys[kid] = key
+	return nil
+}
+
+// RemoveKey removes the verification key, eg: once the rotated key is retired.
+func (v *Verifier) RemoveKey(kid string) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	delete(v.keys, kid)
+}
+
+// Verify verifies the token and returns its claims.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	opts := []jwtv5.ParserOption{jwtv5.WithIssuer(v.issuer), jwtv5.WithLeeway(v.leeway), jwtv5.WithExpirationRequired(), jwtv5.WithIssuedAt()}
+	if v.audience != "" {
+		opts = append(opts, jwtv5.WithAudience(v.audience))
+	}
+	claims := &Claims{}
+	if _, err := jwtv5.ParseWithClaims(token, claims, v.keyFunc, opts...); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyRequest verifies the token of the request.
+func (v *Verifier) VerifyRequest(req *http.Request) (*Claims, error) {
+	token := req.Header.Get(v.header)
+	if token == "" {
+		return nil, ErrMissingToken
+	}
+	return v.Verify(token)
+}
+
+// Handler replies 401 to the requests without a valid token, the claims of the others are read by FromContext.
+func (v *Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		claims, err := v.VerifyRequest(req)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, req.WithContext(NewContext(req.Context(), claims)))
+	})
+}
+
+func (v *Verifier) keyFunc(token *jwtv5.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	v.lock.RLock()
+	key, ok := v.keys[kid]
+	v.lock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	// the algorithm must be of the type of the key, eg: a public key is never used as an HMAC secret
+	methods, err := methodsOf(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range methods {
+		if m == token.Method.Alg() {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("verify: algorithm %s does not match the key %q", token.Method.Alg(), kid)
+}
+
+// methodsOf returns the algorithms of the verification key.
+func methodsOf(key any) ([]string, error) {
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return nil, errors.New("verify: empty secret")
+		}
+		return []string{"HS256", "HS384", "HS512"}, nil
+	case *rsa.PublicKey:
+		return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, nil
+	case *ecdsa.PublicKey:
+		return []string{"ES256", "ES384", "ES512"}, nil
+	case ed25519.PublicKey:
+		return []string{"EdDSA"}, nil
+	}
+	return nil, fmt.Errorf("verify: unsupported key type %T", key)
+}
+
+// Thumbprint returns the default kid of the key, the first 16 hex digits of the sha256 of the PKIX public key,
+// or of the secret. The private keys are thumbprinted by their public keys, so the gateway and the upstreams
+// derive the same kid.
+func Thumbprint(key any) (string, error) {
+	var data []byte
+	switch k := key.(type) {
+	case []byte:
+		data = k
+	case crypto.Signer:
+		return Thumbprint(k.Public())
+	default:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			return "", fmt.Errorf("verify: %w", err)
+		}
+		data = der
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// ParsePublicKeyPEM parses the PEM public key or certificate of the signing key.
+func ParsePublicKeyPEM(data []byte) (any, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("verify: no PEM block found")
+	}
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+type contextKey struct{}
+
+// NewContext returns the context with the claims.
+func NewContext(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// FromContext returns the claims of the request verified by Handler.
+func FromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(*Claims)
+	return claims, ok
+}
@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"github.com/aide-family/goddess/middleware"
 	jwtv5 "github.com/golang-jwt/jwt/v5"
 )
 
@@ -15,3 +16,17 @@ type (
 		jwtv5.RegisteredClaims
 	}
 )
+
+// values returns the claims shared with the other middlewares.
+func (c *JwtClaims) values() middleware.Claims {
+	claims := middleware.Claims{
+		"userId":   c.UserID,
+		"username": c.Username,
+		"iss":      c.Issuer,
+		"sub":      c.Subject,
+	}
+	if c.ExpiresAt != nil {
+		claims["exp"] = c.ExpiresAt.Unix()
+	}
+	return claims
+}
@@ -2,28 +2,34 @@
 package jwt
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/aide-family/goddess/pkg/merr"
 	jwtv1 "github.com/aide-family/goddess/pkg/middleware/jwt/v1"
-	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
 	jwtv5 "github.com/golang-jwt/jwt/v5"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
+// the headers of the user of the validated token
+const (
+	_headerUserID   = "X-User-ID"
+	_headerUserName = "X-User-Name"
+)
+
 func init() {
-	middleware.Register("jwt", Middleware)
+	middleware.RegisterV2("jwt", Middleware, middleware.Shareable())
 }
 
-func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
 	options := &jwtv1.Jwt{}
 	if c.Options != nil {
 		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
@@ -37,43 +43,93 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 		jwtv5.WithValidMethods(options.Algorithms),
 		jwtv5.WithIssuer(options.Issuer),
 	}
-	return func(next http.RoundTripper) http.RoundTripper {
+	enforcement := middleware.NewEnforcement(c).WithIdentityHeaders(_headerUserID, _headerUserName)
+	var revocation RevocationStore
+	if options.Revocation != nil {
+		revocation = newRevocationStore(options.Revocation)
+		stores.add(revocation)
+	}
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
 		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			reject := func(reason string, err error) (*http.Response, error) {
+				return enforcement.Reject(next, req, reason, err)
+			}
 			auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
 			if len(auths) != 2 || !strings.EqualFold(auths[0], "Bearer") {
-				return newForbiddenResponse(merr.ErrorForbidden("invalid token 0"))
+				return reject(middleware.RejectionMissingToken, merr.ErrorForbidden("invalid token 0"))
 			}
 			jwtToken := auths[1]
 			token, err := jwtv5.ParseWithClaims(jwtToken, &JwtClaims{}, keyFunc, parserOptions...)
 			if err != nil {
-				return newForbiddenResponse(merr.ErrorForbidden("invalid token 1"))
+				return reject(parseErrorReason(err), merr.ErrorForbidden("invalid token 1"))
 			}
 			if !token.Valid {
-				return newForbiddenResponse(merr.ErrorForbidden("invalid token 2"))
+				return reject(middleware.RejectionTokenInvalid, merr.ErrorForbidden("invalid token 2"))
 			}
 			jwtClaims, ok := token.Claims.(*JwtClaims)
 			if !ok {
-				return newForbiddenResponse(merr.ErrorForbidden("invalid token 3"))
+				return reject(middleware.RejectionTokenInvalid, merr.ErrorForbidden("invalid token 3"))
+			}
+			if revocation != nil {
+				id := revocationID(jwtClaims, jwtToken)
+				revoked, err := revocation.IsRevoked(req.Context(), id)
+				if err != nil {
+					log.Errorf("Failed to check jwt revocation %s: %+v", id, err)
+					if options.Revocation.FailClosed {
+						return reject(middleware.RejectionRevocationUnavailable, merr.ErrorUnauthorized("token revocation unavailable"))
+					}
+				}
+				if revoked {
+					revokedRequestIncr(req)
+					return reject(middleware.RejectionTokenRevoked, merr.ErrorUnauthorized("token revoked"))
+				}
+			}
+			req.Header.Set(_headerUserID, strconv.FormatInt(jwtClaims.UserID, 10))
+			req.Header.Set(_headerUserName, jwtClaims.Username)
+			if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+				middleware.ConsumerID.Set(reqOpts, strconv.FormatInt(jwtClaims.UserID, 10))
+				middleware.ClaimsValue.Set(reqOpts, jwtClaims.values())
 			}
-			req.Header.Set("X-User-ID", strconv.FormatInt(jwtClaims.UserID, 10))
-			req.Header.Set("X-User-Name", jwtClaims.Username)
 
 			return next.RoundTrip(req)
 		})
-	}, nil
+	}, &revocationCloser{store: revocation}), nil
+}
+
+type revocationCloser struct {
+	once  sync.Once
+	store RevocationStore
+}
+
+func (c *revocationCloser) Close() (err error) {
+	if c.store == nil {
+		return nil
+	}
+	c.once.Do(func() {
+		stores.remove(c.store)
+		if closer, ok := c.store.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return
+}
+
+func revokedRequestIncr(req *http.Request) {
+	labels, ok := middleware.MetricsLabelsFromContext(req.Context())
+	if ok {
+		_metricRevokedTotal.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath()).Inc()
+	}
 }
 
-func newForbiddenResponse(err error) (*http.Response, error) {
-	kerr := errors.FromError(err)
-	body, err := json.Marshal(kerr)
-	if err != nil {
-		return nil, err
+// parseErrorReason returns the reason code of the error parsing the token.
+func parseErrorReason(err error) string {
+	switch {
+	case errors.Is(err, jwtv5.ErrTokenMalformed):
+		return middleware.RejectionTokenMalformed
+	case errors.Is(err, jwtv5.ErrTokenExpired):
+		return middleware.RejectionTokenExpired
+	case errors.Is(err, jwtv5.ErrTokenSignatureInvalid):
+		return middleware.RejectionTokenInvalidSignature
 	}
-	header := http.Header{}
-	header.Set("Content-Type", "application/json")
-	return &http.Response{
-		StatusCode: int(kerr.Code),
-		Header:     header,
-		Body:       io.NopCloser(bytes.NewReader(body)),
-	}, nil
+	return middleware.RejectionTokenInvalid
 }
@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/pkg/merr"
+	jwtv1 "github.com/aide-family/goddess/pkg/middleware/jwt/v1"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+func newExpiredToken(t *testing.T, secret string) string {
+	t.Helper()
+	claims := &JwtClaims{RegisteredClaims: jwtv5.RegisteredClaims{Issuer: "goddess", ExpiresAt: jwtv5.NewNumericDate(time.Now().Add(-time.Hour))}}
+	token, err := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestMiddleware(t *testing.T) {
+	c := middlewaretest.NewConfig("jwt", &jwtv1.Jwt{Secret: "secret", Issuer: "goddess", Algorithms: []string{"HS256"}})
+	m, err := Middleware(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &middlewaretest.RecordingTripper{}
+	do := func(authorization string) *http.Response {
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		req.Header.Set("Authorization", authorization)
+		resp, err := m.Process(next).RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for authorization, reason := range map[string]string{
+		"":                                       middleware.RejectionMissingToken,
+		"Bearer bad":                             middleware.RejectionTokenMalformed,
+		"Bearer " + newToken(t, "other", "1"):    middleware.RejectionTokenInvalidSignature,
+		"Bearer " + newExpiredToken(t, "secret"): middleware.RejectionTokenExpired,
+	} {
+		rejected := middlewaretest.AssertErrorResponse(t, do(authorization), http.StatusForbidden, merr.ClientError_FORBIDDEN.String())
+		if rejected.Metadata["rejection_reason"] != reason || rejected.Metadata["middleware"] != "jwt" {
+			t.Fatalf("want the rejection reason %s but got %v", reason, rejected.Metadata)
+		}
+	}
+	if next.Count() != 0 {
+		t.Fatalf("want the invalid tokens rejected but %d requests passed", next.Count())
+	}
+	if resp := do("Bearer " + newToken(t, "secret", "1")); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want the valid token passed but got %d", resp.StatusCode)
+	}
+	if req, _ := next.Last(); req.Header.Get("X-User-ID") != "0" {
+		t.Fatalf("want the user id forwarded but got %v", req.Header)
+	}
+}
+
+func TestEnforcementMode(t *testing.T) {
+	c := middlewaretest.NewConfig("jwt", &jwtv1.Jwt{Secret: "secret", Issuer: "goddess", Algorithms: []string{"HS256"}})
+	do := func(c *config.Middleware, token string) *http.Response {
+		m, err := Middleware(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := m.Process(&middlewaretest.RecordingTripper{}).RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	middlewaretest.AssertErrorResponse(t, do(c, "bad"), http.StatusForbidden, merr.ClientError_FORBIDDEN.String())
+	c.EnforcementMode = config.EnforcementMode_MONITOR
+	resp := do(c, "bad")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Auth-Monitor") != "would-block" {
+		t.Fatalf("want let through and marked in the monitor mode but got %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := do(c, newToken(t, "secret", "1")); resp.Header.Get("X-Auth-Monitor") != "" {
+		t.Fatalf("want the valid token unmarked but got %v", resp.Header)
+	}
+	// the identity sent by the client is not forwarded with the request let through
+	m, err := Middleware(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &middlewaretest.RecordingTripper{}
+	req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+	req.Header.Set("Authorization", "Bearer bad")
+	req.Header.Set("X-User-ID", "1")
+	req.Header.Set("X-User-Name", "admin")
+	if _, err := m.Process(next).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if forwarded, _ := next.Last(); forwarded.Header.Get("X-User-ID") != "" || forwarded.Header.Get("X-User-Name") != "" {
+		t.Fatalf("want the spoofed identity removed but got %v", forwarded.Header)
+	}
+	c.MonitorHeader = "X-Shadow"
+	if resp := do(c, "bad"); resp.Header.Get("X-Shadow") != "would-block" {
+		t.Fatalf("want the configured header but got %v", resp.Header)
+	}
+}
@@ -0,0 +1,244 @@
+package jwt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/metrics"
+	jwtv1 "github.com/aide-family/goddess/pkg/middleware/jwt/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	_defaultRedisKeyPrefix = "goddess:jwt:revoked:"
+	// _defaultRevocationTTL is used when the token has no expiration.
+	_defaultRevocationTTL = 24 * time.Hour
+)
+
+var _metricRevokedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "jwt_revoked_rejected_total",
+	Help:      "The total number of requests rejected by revoked jwt tokens",
+}, []string{"protocol", "method", "path", "service", "basePath"})
+
+func init() {
+	metrics.Register(_metricRevokedTotal)
+}
+
+// RevocationStore is the denylist of the revoked tokens.
+type RevocationStore interface {
+	Revoke(ctx context.Context, id string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// revocationID is the jti of the token, or the sha256 of the raw token if jti is absent.
+func revocationID(claims *JwtClaims, raw string) string {
+	if claims != nil && claims.ID != "" {
+		return claims.ID
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// revocationTTL is the remaining lifetime of the token.
+func revocationTTL(claims *JwtClaims) time.Duration {
+	if claims == nil || claims.ExpiresAt == nil {
+		return _defaultRevocationTTL
+	}
+	return time.Until(claims.ExpiresAt.Time)
+}
+
+// memoryStore is shared by all the jwt middlewares, so revocations survive config reloads.
+var _memoryStore = newMemoryStore()
+
+type memoryStore struct {
+	lock    sync.Mutex
+	entries map[string]time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: map[string]time.Time{}}
+}
+
+func (s *memoryStore) Revoke(_ context.Context, id string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	now := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, expireAt := range s.entries {
+		if now.After(expireAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.entries[id] = now.Add(ttl)
+	return nil
+}
+
+func (s *memoryStore) IsRevoked(_ context.Context, id string) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	expireAt, ok := s.entries[id]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expireAt) {
+		delete(s.entries, id)
+		return false, nil
+	}
+	return true, nil
+}
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func newRedisStore(in *jwtv1.Revocation_Redis) *redisStore {
+	prefix := in.KeyPrefix
+	if prefix == "" {
+		prefix = _defaultRedisKeyPrefix
+	}
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     in.Addr,
+			Password: in.Password,
+			DB:       int(in.Db),
+		}),
+		prefix: prefix,
+	}
+}
+
+func (s *redisStore) Revoke(ctx context.Context, id string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, s.prefix+id, 1, ttl).Err()
+}
+
+func (s *redisStore) IsRevoked(ctx context.Context, id string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+id).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+// stores is the active revocation stores, revocations from the debug handler are written to all of them.
+var stores = &storeSet{stores: map[RevocationStore]int{}}
+
+type storeSet struct {
+	lock   sync.RWMutex
+	stores map[RevocationStore]int
+}
+
+func (s *storeSet) add(store RevocationStore) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stores[store]++
+}
+
+func (s *storeSet) remove(store RevocationStore) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stores[store]--
+	if s.stores[store] <= 0 {
+		delete(s.stores, store)
+	}
+}
+
+func (s *storeSet) revoke(ctx context.Context, id string, ttl time.Duration) error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	if err := _memoryStore.Revoke(ctx, id, ttl); err != nil {
+		return err
+	}
+	for store := range s.stores {
+		if store == RevocationStore(_memoryStore) {
+			continue
+		}
+		if err := store.Revoke(ctx, id, ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newRevocationStore(in *jwtv1.Revocation) RevocationStore {
+	switch store := in.GetStore().(type) {
+	case *jwtv1.Revocation_Redis_:
+		return newRedisStore(store.Redis)
+	default:
+		return _memoryStore
+	}
+}
+
+type revocationDebugger struct{}
+
+// RevocationDebugger is the debug handler to revoke tokens, eg:
+// curl -XPOST /debug/jwt/revoke -d 'jti=xxx&ttl=1h' or -d 'token=xxx'
+var RevocationDebugger = revocationDebugger{}
+
+// DebugHandler implemented debug handler.
+func (revocationDebugger) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	debugMux.HandleFunc("/debug/jwt/revoke", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		id, ttl := r.FormValue("jti"), _defaultRevocationTTL
+		if raw := strings.TrimSpace(r.FormValue("token")); raw != "" {
+			claims := &JwtClaims{}
+			if _, _, err := jwtv5.NewParser().ParseUnverified(raw, claims); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			id, ttl = revocationID(claims, raw), revocationTTL(claims)
+		}
+		if id == "" {
+			http.Error(rw, "jti or token is required", http.StatusBadRequest)
+			return
+		}
+		if v := r.FormValue("ttl"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ttl = d
+		}
+		if err := audit.WriteRequest(r, &audit.Record{Action: "jwt.revoke", Target: id, After: ttl.String()}); err != nil {
+			log.Errorf("Failed to write audit record of jwt revocation %s: %+v", id, err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		if err := stores.revoke(r.Context(), id, ttl); err != nil {
+			log.Errorf("Failed to revoke jwt token %s: %+v", id, err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(map[string]interface{}{
+			"jti": id,
+			"ttl": ttl.String(),
+		})
+	})
+	return debugMux
+}
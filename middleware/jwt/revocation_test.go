@@ -0,0 +1,89 @@
+package jwt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	jwtv1 "github.com/aide-family/goddess/pkg/middleware/jwt/v1"
+	jwtv5 "github.com/golang-jwt/jwt/v5"
+)
+
+func newToken(t *testing.T, secret, id string) string {
+	t.Helper()
+	claims := &JwtClaims{
+		RegisteredClaims: jwtv5.RegisteredClaims{
+			ID:        id,
+			Issuer:    "goddess",
+			ExpiresAt: jwtv5.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwtv5.NewWithClaims(jwtv5.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestRevocation(t *testing.T) {
+	m, err := Middleware(middlewaretest.NewConfig("jwt", &jwtv1.Jwt{
+		Secret:     "secret",
+		Issuer:     "goddess",
+		Algorithms: []string{"HS256"},
+		Revocation: &jwtv1.Revocation{Store: &jwtv1.Revocation_Memory_{Memory: &jwtv1.Revocation_Memory{}}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	rt := m.Process(&middlewaretest.RecordingTripper{})
+	do := func(token string) int {
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	withID, withoutID := newToken(t, "secret", "jti-1"), newToken(t, "secret", "")
+	if code := do(withID); code != http.StatusOK {
+		t.Fatalf("want 200 before revocation but got %d", code)
+	}
+
+	handler := RevocationDebugger.DebugHandler()
+	for _, form := range []url.Values{{"jti": {"jti-1"}}, {"token": {withoutID}}} {
+		req := httptest.NewRequest(http.MethodPost, "/debug/jwt/revoke", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("revoke %v: %d %s", form, rec.Code, rec.Body.String())
+		}
+	}
+	if code := do(withID); code != http.StatusUnauthorized {
+		t.Fatalf("want 401 for revoked jti but got %d", code)
+	}
+	if code := do(withoutID); code != http.StatusUnauthorized {
+		t.Fatalf("want 401 for revoked token but got %d", code)
+	}
+	if code := do(newToken(t, "secret", "jti-2")); code != http.StatusOK {
+		t.Fatalf("want 200 for other token but got %d", code)
+	}
+}
+
+func TestMemoryStoreExpire(t *testing.T) {
+	s := newMemoryStore()
+	if err := s.Revoke(t.Context(), "a", time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if revoked, _ := s.IsRevoked(t.Context(), "a"); revoked {
+		t.Fatal("want revocation expired")
+	}
+}
@@ -5,13 +5,14 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aide-family/goddess/logs"
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/go-kratos/kratos/v2/log"
 )
 
 func init() {
-	middleware.Register("logging", Middleware)
+	middleware.Register("logging", Middleware, middleware.Shareable())
 }
 
 // Middleware is a logging middleware.
@@ -23,9 +24,11 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			level := log.LevelInfo
 			code := http.StatusBadGateway
 			errMsg := ""
+			timeoutSource := middleware.TimeoutSource("")
 			if err != nil {
 				level = log.LevelError
 				errMsg = err.Error()
+				timeoutSource = middleware.TimeoutSourceOf(req.Context(), err)
 			} else {
 				code = reply.StatusCode
 			}
@@ -41,7 +44,7 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				if ok {
 					go func() {
 						<-streamBody.CloseNotify()
-						log.Context(ctx).Log(level,
+						log.WithContext(ctx, logs.Access).Log(level,
 							"source", "accesslog",
 							"host", req.Host,
 							"method", req.Method,
@@ -50,19 +53,23 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 							"query", req.URL.RawQuery,
 							"code", code,
 							"error", errMsg,
+							"timeout_source", timeoutSource,
 							"latency", time.Since(startTime).Seconds(),
 							"backend", strings.Join(reqOpt.Backends, ","),
+							"upstream_addr", reqOpt.Upstream().Addr,
+							"attempts", reqOpt.Upstream().Attempts,
 							"backend_code", reqOpt.UpstreamStatusCode,
 							"backend_latency", reqOpt.UpstreamResponseTime,
 							"last_attempt", reqOpt.LastAttempt,
 							"stream", isStream,
 							"stream_body", streamBody,
+							"values", middleware.ExportValues(reqOpt),
 						)
 					}()
 					return reply, err
 				}
 			}
-			log.Context(ctx).Log(level,
+			log.WithContext(ctx, logs.Access).Log(level,
 				"source", "accesslog",
 				"host", req.Host,
 				"method", req.Method,
@@ -71,12 +78,16 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				"query", req.URL.RawQuery,
 				"code", code,
 				"error", errMsg,
+				"timeout_source", timeoutSource,
 				"latency", time.Since(startTime).Seconds(),
 				"backend", strings.Join(reqOpt.Backends, ","),
+				"upstream_addr", reqOpt.Upstream().Addr,
+				"attempts", reqOpt.Upstream().Attempts,
 				"backend_code", reqOpt.UpstreamStatusCode,
 				"backend_latency", reqOpt.UpstreamResponseTime,
 				"last_attempt", reqOpt.LastAttempt,
 				"stream", isStream,
+				"values", middleware.ExportValues(reqOpt),
 			)
 			return reply, err
 		})
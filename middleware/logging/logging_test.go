@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// accessLogger sends the values of the access logs to the channel.
+type accessLogger chan map[string]string
+
+func (l accessLogger) Log(level log.Level, keyvals ...any) error {
+	values := map[string]string{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if k, _ := keyvals[i].(string); k == "values" {
+			values, _ = keyvals[i+1].(map[string]string)
+		}
+	}
+	l <- values
+	return nil
+}
+
+type streamBody struct {
+	io.ReadCloser
+	closed chan bool
+}
+
+func (b *streamBody) CloseNotify() <-chan bool { return b.closed }
+
+func TestAccessLogValues(t *testing.T) {
+	logs := make(accessLogger, 1)
+	defaultLogger := log.GetLogger()
+	log.SetLogger(logs)
+	defer log.SetLogger(defaultLogger)
+
+	m, err := Middleware(&config.Middleware{Name: "logging"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := &streamBody{ReadCloser: io.NopCloser(strings.NewReader("")), closed: make(chan bool, 1)}
+	tripper := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+	}))
+	for _, stream := range []bool{false, true} {
+		opts := middleware.NewRequestOptions(&config.Endpoint{Path: "/users", Stream: stream})
+		middleware.RequestID.Set(opts, "req-1")
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), opts))
+		if _, err := tripper.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		if stream {
+			// the access log of the stream is written once the stream is closed
+			body.closed <- true
+		}
+		select {
+		case values := <-logs:
+			if values["request_id"] != "req-1" {
+				t.Fatalf("want the request values in the access log of stream %v but got %v", stream, values)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("want the access log of stream %v", stream)
+		}
+	}
+}
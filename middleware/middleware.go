@@ -30,6 +30,13 @@ type (
 	}
 )
 
+// AttemptHook is optionally implemented by the middlewares to set the attempt-scoped values of the request,
+// such as request signatures or unique ids. OnAttempt is called before every attempt with a fresh copy of the
+// request captured before the first attempt, attempt starts from 0.
+type AttemptHook interface {
+	OnAttempt(req *http.Request, attempt int) error
+}
+
 func wrapFactory(in Factory) FactoryV2 {
 	return func(m *configv1.Middleware) (MiddlewareV2, error) {
 		v, err := in(m)
@@ -0,0 +1,160 @@
+// Package middlewaretest provides utilities for testing the middlewares.
+package middlewaretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// NewConfig returns the config of the middleware with the options, it panics if the options fail to marshal.
+func NewConfig(name string, opts proto.Message) *config.Middleware {
+	c := &config.Middleware{Name: name}
+	if opts != nil {
+		options, err := anypb.New(opts)
+		if err != nil {
+			panic(err)
+		}
+		c.Options = options
+	}
+	return c
+}
+
+// Response is a scripted response of RecordingTripper.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+	// Err is returned instead of the response if set.
+	Err error
+}
+
+// RecordingTripper records the requests reaching it and replies the scripted responses in order,
+// the last response repeats once the others are used, 200 with an empty body if there is none.
+type RecordingTripper struct {
+	Responses []*Response
+
+	lock     sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	t.lock.Lock()
+	n := len(t.requests)
+	t.requests = append(t.requests, req)
+	t.bodies = append(t.bodies, body)
+	t.lock.Unlock()
+
+	scripted := &Response{StatusCode: http.StatusOK}
+	if len(t.Responses) > 0 {
+		scripted = t.Responses[min(n, len(t.Responses)-1)]
+	}
+	if scripted.Err != nil {
+		return nil, scripted.Err
+	}
+	header := scripted.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		StatusCode:    scripted.StatusCode,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(scripted.Body)),
+		ContentLength: int64(len(scripted.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Requests returns the recorded requests.
+func (t *RecordingTripper) Requests() []*http.Request {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return append([]*http.Request(nil), t.requests...)
+}
+
+// Count returns the number of the recorded requests.
+func (t *RecordingTripper) Count() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return len(t.requests)
+}
+
+// Last returns the last recorded request and its body, nil if there is none.
+func (t *RecordingTripper) Last() (*http.Request, []byte) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.requests) == 0 {
+		return nil, nil
+	}
+	return t.requests[len(t.requests)-1], t.bodies[len(t.bodies)-1]
+}
+
+// NewRequest returns the request carrying the request options of the endpoint as the proxy does,
+// the endpoint matches the method and the path of the request if it is nil.
+func NewRequest(method, target string, body io.Reader, endpoint *config.Endpoint) (*http.Request, *middleware.RequestOptions) {
+	req := httptest.NewRequest(method, target, body)
+	if endpoint == nil {
+		endpoint = &config.Endpoint{Path: req.URL.Path, Method: method, Protocol: config.Protocol_HTTP}
+	}
+	opts := middleware.NewRequestOptions(endpoint)
+	return req.WithContext(middleware.NewRequestContext(req.Context(), opts)), opts
+}
+
+// NewStreamRequest returns the request of the stream endpoint with the stream context initialized,
+// the hooks of the stream context are run by DoOnResponse and DoOnFinish of it.
+func NewStreamRequest(method, target string, body io.Reader, endpoint *config.Endpoint) (*http.Request, *middleware.RequestOptions, *middleware.MetaStreamContext) {
+	req, opts := NewRequest(method, target, body, endpoint)
+	if endpoint == nil {
+		opts.Endpoint.Stream = true
+	}
+	streamCtx := &middleware.MetaStreamContext{Request: req}
+	middleware.InitMetaStreamContext(opts, streamCtx)
+	return req, opts, streamCtx
+}
+
+// ErrorResponse is the JSON body of the requests rejected by the middlewares, eg: jwt and namespace.
+type ErrorResponse struct {
+	Code     int               `json:"code"`
+	Reason   string            `json:"reason"`
+	Message  string            `json:"message"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// AssertErrorResponse fails the test unless resp is the rejection of the status code and the reason.
+func AssertErrorResponse(t testing.TB, resp *http.Response, code int, reason string) *ErrorResponse {
+	t.Helper()
+	if resp == nil {
+		t.Fatalf("want the %d %s rejection but got no response", code, reason)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != code {
+		t.Fatalf("want status code %d but got %d", code, resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("want the json rejection but got content type %q", ct)
+	}
+	out := &ErrorResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatalf("failed to decode the rejection: %v", err)
+	}
+	if out.Code != code || out.Reason != reason {
+		t.Fatalf("want the rejection %d %s but got %d %s", code, reason, out.Code, out.Reason)
+	}
+	return out
+}
@@ -0,0 +1,219 @@
+package namespace
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	v1 "github.com/aide-family/goddess/pkg/middleware/namespace"
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	defaultQueueTimeout   = 100 * time.Millisecond
+	defaultBreakerSuccess = 0.6
+	defaultBreakerRequest = 20
+	defaultBreakerWindow  = 3 * time.Second
+)
+
+var (
+	_metricValidationInflight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "namespace_validation_inflight",
+		Help:      "The number of the validation API calls in flight",
+	}, []string{"api"})
+	_metricValidationMaxConcurrency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "namespace_validation_max_concurrency",
+		Help:      "The max concurrent validation API calls, 0 if unlimited",
+	}, []string{"api"})
+	_metricValidationWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "namespace_validation_wait_seconds",
+		Help:      "Time the requests waited for the validation API limits(sec).",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"api"})
+	_metricValidationLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "namespace_validation_limited_total",
+		Help:      "The total number of the validations not calling the API by the reason",
+	}, []string{"api", "reason"})
+	_metricValidationBreakerOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "namespace_validation_breaker_open",
+		Help:      "Whether the circuit breaker of the validation API rejected the last call",
+	}, []string{"api"})
+)
+
+func init() {
+	metrics.Register(
+		_metricValidationInflight,
+		_metricValidationMaxConcurrency,
+		_metricValidationWaitSeconds,
+		_metricValidationLimited,
+		_metricValidationBreakerOpen,
+	)
+}
+
+// errValidationUnavailable is returned when the validation API is not called for the limits or the breaker.
+var errValidationUnavailable = errors.ServiceUnavailable("NAMESPACE_VALIDATION_UNAVAILABLE", "namespace validation unavailable")
+
+// tokenBucket limits the rate of the validation API calls.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst uint32) *tokenBucket {
+	if burst == 0 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns the time to wait for it, false is returned without taking the token if
+// the wait exceeds the max wait.
+func (b *tokenBucket) reserve(now time.Time, maxWait time.Duration) (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait > maxWait {
+		return 0, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// limiter bounds the calls to the validation API.
+type limiter struct {
+	api          string
+	sem          chan struct{}
+	bucket       *tokenBucket
+	breaker      circuitbreaker.CircuitBreaker
+	queueTimeout time.Duration
+	failOpen     bool
+}
+
+func newLimiter(options *v1.ValidateApi) *limiter {
+	l := &limiter{
+		api:          apiLabel(options.Url),
+		queueTimeout: defaultQueueTimeout,
+		failOpen:     options.FailOpen,
+	}
+	if options.QueueTimeout != nil {
+		l.queueTimeout = options.QueueTimeout.AsDuration()
+	}
+	if options.MaxConcurrency > 0 {
+		l.sem = make(chan struct{}, options.MaxConcurrency)
+	}
+	_metricValidationMaxConcurrency.WithLabelValues(l.api).Set(float64(options.MaxConcurrency))
+	if options.RateLimit > 0 {
+		l.bucket = newTokenBucket(options.RateLimit, options.Burst)
+	}
+	if b := options.CircuitBreaker; b != nil {
+		success, request, window := b.Success, b.Request, defaultBreakerWindow
+		if success <= 0 {
+			success = defaultBreakerSuccess
+		}
+		if request <= 0 {
+			request = defaultBreakerRequest
+		}
+		if b.Window != nil {
+			window = b.Window.AsDuration()
+		}
+		l.breaker = sre.NewBreaker(sre.WithSuccess(success), sre.WithRequest(request), sre.WithWindow(window))
+	}
+	return l
+}
+
+// apiLabel is the validation API without the query which may carry secrets.
+func apiLabel(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return u.Host + u.Path
+}
+
+// do calls the validation API within the limits, call returns whether the API is available.
+func (l *limiter) do(ctx context.Context, call func(context.Context) (available bool, err error)) error {
+	if l.breaker != nil {
+		if err := l.breaker.Allow(); err != nil {
+			_metricValidationBreakerOpen.WithLabelValues(l.api).Set(1)
+			return l.limited("breaker_open")
+		}
+		_metricValidationBreakerOpen.WithLabelValues(l.api).Set(0)
+	}
+	start := time.Now()
+	deadline := start.Add(l.queueTimeout)
+	if l.bucket != nil {
+		wait, ok := l.bucket.reserve(start, l.queueTimeout)
+		if !ok {
+			return l.limited("rate_limit")
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			}
+		}
+	}
+	if l.sem != nil {
+		timer := time.NewTimer(time.Until(deadline))
+		select {
+		case l.sem <- struct{}{}:
+			timer.Stop()
+			defer func() { <-l.sem }()
+		case <-timer.C:
+			return l.limited("concurrency")
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+	_metricValidationWaitSeconds.WithLabelValues(l.api).Observe(time.Since(start).Seconds())
+	inflight := _metricValidationInflight.WithLabelValues(l.api)
+	inflight.Inc()
+	defer inflight.Dec()
+	available, err := call(ctx)
+	if l.breaker != nil {
+		if available {
+			l.breaker.MarkSuccess()
+		} else {
+			l.breaker.MarkFailed()
+		}
+	}
+	return err
+}
+
+func (l *limiter) limited(reason string) error {
+	_metricValidationLimited.WithLabelValues(l.api, reason).Inc()
+	if l.failOpen {
+		return nil
+	}
+	return errValidationUnavailable
+}
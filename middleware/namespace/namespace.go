@@ -4,7 +4,6 @@ package namespace
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
 	"slices"
@@ -55,6 +54,15 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			Timeout: timeout,
 		}
 	}
+	var validateAPI func(ctx context.Context, ns string) error
+	if httpClient != nil {
+		limiter := newLimiter(options.ValidateApi)
+		validateAPI = func(ctx context.Context, ns string) error {
+			return limiter.do(ctx, func(ctx context.Context) (bool, error) {
+				return validateNamespaceViaAPI(ctx, httpClient, ns, options.ValidateApi)
+			})
+		}
+	}
 
 	// Build whitelist map for fast lookup
 	whitelistMap := make(map[string]bool)
@@ -79,9 +87,7 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 		if httpClient == nil {
 			return nil, merr.ErrorInternal("api validation mode is specified but http client is not configured")
 		}
-		validationFunc = func(ctx context.Context, ns string) error {
-			return validateNamespaceViaAPI(ctx, httpClient, ns, options.ValidateApi)
-		}
+		validationFunc = validateAPI
 	default:
 		validationFunc = func(ctx context.Context, ns string) error {
 			if len(whitelistMap) > 0 {
@@ -90,7 +96,7 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				}
 			}
 			if httpClient != nil {
-				if err := validateNamespaceViaAPI(ctx, httpClient, ns, options.ValidateApi); err != nil {
+				if err := validateAPI(ctx, ns); err != nil {
 					return err
 				}
 			}
@@ -98,17 +104,25 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 		}
 	}
 
+	enforcement := middleware.NewEnforcement(c)
 	return func(next http.RoundTripper) http.RoundTripper {
 		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
 			namespace := req.Header.Get(namespaceKey)
 
 			if options.Required && namespace == "" {
-				return newForbiddenResponse(merr.ErrorForbidden("namespace is required"))
+				return enforcement.Reject(next, req, middleware.RejectionNamespaceRequired, merr.ErrorForbidden("namespace is required"))
 			}
 
 			if namespace != "" {
 				if err := validationFunc(req.Context(), namespace); err != nil {
-					return newForbiddenResponse(err)
+					reason := middleware.RejectionNamespaceNotAllowed
+					if errors.Reason(err) == errValidationUnavailable.Reason {
+						reason = middleware.RejectionNamespaceUnavailable
+					}
+					return enforcement.Reject(next, req, reason, err)
+				}
+				if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+					middleware.Namespace.Set(reqOpts, namespace)
 				}
 			}
 			return next.RoundTrip(req)
@@ -116,18 +130,19 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 	}, nil
 }
 
-// validateNamespaceViaAPI validates namespace by calling external API
-func validateNamespaceViaAPI(ctx context.Context, client *http.Client, namespace string, apiConfig *v1.ValidateApi) error {
+// validateNamespaceViaAPI validates namespace by calling external API,
+// it returns false if the API is unavailable, the transport errors and the 5xx responses.
+func validateNamespaceViaAPI(ctx context.Context, client *http.Client, namespace string, apiConfig *v1.ValidateApi) (bool, error) {
 	// Prepare request body
 	var body io.Reader
 	if apiConfig.BodyTemplate != "" {
 		tmpl, err := template.New("body").Parse(apiConfig.BodyTemplate)
 		if err != nil {
-			return merr.ErrorInternal("failed to parse body template: %v", err)
+			return true, merr.ErrorInternal("failed to parse body template: %v", err)
 		}
 		var buf bytes.Buffer
 		if err := tmpl.Execute(&buf, map[string]string{"namespace": namespace}); err != nil {
-			return merr.ErrorInternal("failed to execute body template: %v", err)
+			return true, merr.ErrorInternal("failed to execute body template: %v", err)
 		}
 		body = bytes.NewBuffer(buf.Bytes())
 	}
@@ -139,7 +154,7 @@ func validateNamespaceViaAPI(ctx context.Context, client *http.Client, namespace
 	}
 	req, err := http.NewRequestWithContext(ctx, method, apiConfig.Url, body)
 	if err != nil {
-		return merr.ErrorInternal("failed to create validation request: %v", err)
+		return true, merr.ErrorInternal("failed to create validation request: %v", err)
 	}
 
 	// Set headers
@@ -151,7 +166,7 @@ func validateNamespaceViaAPI(ctx context.Context, client *http.Client, namespace
 	// Make request
 	resp, err := client.Do(req)
 	if err != nil {
-		return merr.ErrorInternal("failed to validate namespace: %v", err)
+		return false, merr.ErrorInternal("failed to validate namespace: %v", err)
 	}
 	defer resp.Body.Close()
 
@@ -163,24 +178,8 @@ func validateNamespaceViaAPI(ctx context.Context, client *http.Client, namespace
 	isSuccess := slices.Contains(successCodes, int32(resp.StatusCode))
 
 	if !isSuccess {
-		return merr.ErrorForbidden("namespace validation failed: status code %d", resp.StatusCode)
+		return resp.StatusCode < http.StatusInternalServerError, merr.ErrorForbidden("namespace validation failed: status code %d", resp.StatusCode)
 	}
 
-	return nil
-}
-
-// newForbiddenResponse creates a forbidden HTTP response
-func newForbiddenResponse(err error) (*http.Response, error) {
-	kerr := errors.FromError(err)
-	body, err := json.Marshal(kerr)
-	if err != nil {
-		return nil, err
-	}
-	header := http.Header{}
-	header.Set("Content-Type", "application/json")
-	return &http.Response{
-		StatusCode: int(kerr.Code),
-		Header:     header,
-		Body:       io.NopCloser(bytes.NewReader(body)),
-	}, nil
+	return true, nil
 }
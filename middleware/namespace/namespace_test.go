@@ -0,0 +1,183 @@
+package namespace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	"github.com/aide-family/goddess/pkg/merr"
+	v1 "github.com/aide-family/goddess/pkg/middleware/namespace"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestWhitelist(t *testing.T) {
+	m, err := Middleware(middlewaretest.NewConfig("namespace", &v1.Namespace{
+		Required:          true,
+		ValidationMode:    modeWhitelist,
+		AllowedNamespaces: []string{"team-a"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &middlewaretest.RecordingTripper{}
+	do := func(namespace string) *http.Response {
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		if namespace != "" {
+			req.Header.Set(defaultNamespaceKey, namespace)
+		}
+		resp, err := m(next).RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	rejected := middlewaretest.AssertErrorResponse(t, do(""), http.StatusForbidden, merr.ClientError_FORBIDDEN.String())
+	if rejected.Message != "namespace is required" {
+		t.Fatalf("unexpected rejection %+v", rejected)
+	}
+	middlewaretest.AssertErrorResponse(t, do("team-b"), http.StatusForbidden, merr.ClientError_FORBIDDEN.String())
+	if resp := do("team-a"); resp.StatusCode != http.StatusOK || next.Count() != 1 {
+		t.Fatalf("want the allowed namespace passed but got %d", resp.StatusCode)
+	}
+	if ns, _ := middleware.Namespace.FromContext(next.Requests()[0].Context()); ns != "team-a" {
+		t.Fatalf("want the namespace shared with the other middlewares but got %q", ns)
+	}
+}
+
+func TestValidateAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+	m, err := Middleware(middlewaretest.NewConfig("namespace", &v1.Namespace{
+		ValidationMode: modeAPI,
+		ValidateApi:    &v1.ValidateApi{Url: srv.URL, Headers: map[string]string{"X-Token": "secret"}},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &middlewaretest.RecordingTripper{}
+	req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+	req.Header.Set(defaultNamespaceKey, "team-a")
+	resp, err := m(next).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || next.Count() != 1 {
+		t.Fatalf("want the namespace validated by the api but got %d", resp.StatusCode)
+	}
+}
+
+func TestDefaultMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	m, err := Middleware(middlewaretest.NewConfig("namespace", &v1.Namespace{
+		AllowedNamespaces: []string{"team-a"},
+		ValidateApi:       &v1.ValidateApi{Url: srv.URL},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := &middlewaretest.RecordingTripper{}
+	do := func(namespace string) *http.Response {
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		req.Header.Set(defaultNamespaceKey, namespace)
+		resp, err := m(next).RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	if resp := do("team-a"); resp.StatusCode != http.StatusOK || next.Count() != 1 {
+		t.Fatalf("want the whitelisted namespace passed but got %d", resp.StatusCode)
+	}
+	// the namespace out of the whitelist is forbidden even if the api accepts it
+	middlewaretest.AssertErrorResponse(t, do("team-b"), http.StatusForbidden, merr.ClientError_FORBIDDEN.String())
+	if next.Count() != 1 {
+		t.Fatal("want the namespace out of the whitelist not forwarded")
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(10, 2)
+	now := time.Unix(0, 0)
+	for i := 0; i < 2; i++ {
+		if wait, ok := b.reserve(now, 0); !ok || wait != 0 {
+			t.Fatalf("want the burst taken without waiting but got %s %v", wait, ok)
+		}
+	}
+	if _, ok := b.reserve(now, 50*time.Millisecond); ok {
+		t.Fatal("want the token not available in the max wait")
+	}
+	if wait, ok := b.reserve(now, 100*time.Millisecond); !ok || wait != 100*time.Millisecond {
+		t.Fatalf("want to wait for the next token but got %s %v", wait, ok)
+	}
+	if wait, ok := b.reserve(now.Add(time.Second), 0); !ok || wait != 0 {
+		t.Fatalf("want the tokens refilled but got %s %v", wait, ok)
+	}
+}
+
+func TestValidateAPILimits(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	newMiddleware := func(api *v1.ValidateApi) http.RoundTripper {
+		m, err := Middleware(middlewaretest.NewConfig("namespace", &v1.Namespace{ValidationMode: modeAPI, ValidateApi: api}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m(&middlewaretest.RecordingTripper{})
+	}
+	do := func(m http.RoundTripper) int {
+		req, _ := middlewaretest.NewRequest(http.MethodGet, "/foo", nil, nil)
+		req.Header.Set(defaultNamespaceKey, "team-a")
+		resp, err := m.RoundTrip(req)
+		if err != nil {
+			t.Error(err)
+			return 0
+		}
+		return resp.StatusCode
+	}
+
+	for _, failOpen := range []bool{false, true} {
+		m := newMiddleware(&v1.ValidateApi{Url: srv.URL, MaxConcurrency: 1, QueueTimeout: durationpb.New(20 * time.Millisecond), FailOpen: failOpen})
+		go do(m)
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		// the validation API is saturated by the blocked call
+		want := http.StatusServiceUnavailable
+		if failOpen {
+			want = http.StatusOK
+		}
+		if code := do(m); code != want {
+			t.Fatalf("want %d for the saturated validation with fail open %v but got %d", want, failOpen, code)
+		}
+		calls.Store(0)
+	}
+
+	// the dead validator fails fast once the breaker opens
+	m := newMiddleware(&v1.ValidateApi{Url: srv.URL + "?fail=1", CircuitBreaker: &v1.ValidateBreaker{Request: 5}})
+	for i := 0; i < 50; i++ {
+		do(m)
+	}
+	if n := calls.Load(); n >= 50 {
+		t.Fatalf("want the calls to the dead validator cut by the breaker but got %d", n)
+	}
+}
@@ -0,0 +1,234 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+
+	"github.com/aide-family/goddess/audit"
+	v1 "github.com/aide-family/goddess/pkg/middleware/quota/v1"
+)
+
+const _defaultExportInterval = time.Minute
+
+var _webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// exporter reports the usage of the consumers seen by the quota periodically.
+type exporter struct {
+	quota *quota
+	sink  func(ctx context.Context, usages []*Usage) error
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func newExporter(q *quota, in *v1.Export) *exporter {
+	if in == nil {
+		return nil
+	}
+	e := &exporter{
+		quota: q,
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	switch sink := in.GetSink().(type) {
+	case *v1.Export_Webhook:
+		e.sink = func(ctx context.Context, usages []*Usage) error { return postWebhook(ctx, sink.Webhook, usages) }
+	default:
+		e.sink = logUsages
+	}
+	interval := in.Interval.AsDuration()
+	if interval <= 0 {
+		interval = _defaultExportInterval
+	}
+	go e.run(interval)
+	return e
+}
+
+func (e *exporter) run(interval time.Duration) {
+	defer close(e.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			e.export()
+		case <-e.stop:
+			e.export()
+			return
+		}
+	}
+}
+
+// export reports the usage of the seen consumer windows, the past windows are reported for the last time.
+func (e *exporter) export() {
+	q := e.quota
+	current := q.windowOf(time.Now())
+	q.lock.Lock()
+	seen := make([]consumerWindow, 0, len(q.seen))
+	for cw := range q.seen {
+		seen = append(seen, cw)
+		if cw.window.id != current.id {
+			delete(q.seen, cw)
+		}
+	}
+	q.lock.Unlock()
+	if len(seen) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	usages := make([]*Usage, 0, len(seen))
+	for _, cw := range seen {
+		u, err := q.usage(ctx, cw.consumer, cw.window)
+		if err != nil {
+			log.Errorf("Failed to read quota usage %s of %s: %+v", q.name, cw.consumer, err)
+			continue
+		}
+		usages = append(usages, u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Consumer != usages[j].Consumer {
+			return usages[i].Consumer < usages[j].Consumer
+		}
+		return usages[i].Window < usages[j].Window
+	})
+	if err := e.sink(ctx, usages); err != nil {
+		log.Errorf("Failed to export quota usage %s: %+v", q.name, err)
+	}
+}
+
+func (e *exporter) close() {
+	close(e.stop)
+	<-e.done
+}
+
+func logUsages(_ context.Context, usages []*Usage) error {
+	for _, u := range usages {
+		log.Infof("quota usage: name=%s consumer=%s window=%s used=%d limit=%d", u.Name, u.Consumer, u.Window, u.Used, u.Limit)
+	}
+	return nil
+}
+
+func postWebhook(ctx context.Context, url string, usages []*Usage) error {
+	body, err := json.Marshal(usages)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := _webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected webhook status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// quotas is the active quotas, used by the debug handler.
+var quotas = &quotaSet{quotas: map[*quota]struct{}{}}
+
+type quotaSet struct {
+	lock   sync.RWMutex
+	quotas map[*quota]struct{}
+}
+
+func (s *quotaSet) add(q *quota) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.quotas[q] = struct{}{}
+}
+
+func (s *quotaSet) remove(q *quota) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.quotas, q)
+}
+
+func (s *quotaSet) get(name string) (*quota, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	for q := range s.quotas {
+		if q.name == name {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+type quotaDebugger struct{}
+
+// QuotaDebugger is the debug handler to inspect and reset the usage of a consumer, eg:
+// curl /debug/quota/usage?name=daily&consumer=xxx
+// curl -XPOST /debug/quota/reset -d 'name=daily&consumer=xxx'
+var QuotaDebugger = quotaDebugger{}
+
+// DebugHandler implemented debug handler.
+func (quotaDebugger) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	lookup := func(rw http.ResponseWriter, r *http.Request) (*quota, string, bool) {
+		name, consumer := r.FormValue("name"), r.FormValue("consumer")
+		if name == "" || consumer == "" {
+			http.Error(rw, "name and consumer are required", http.StatusBadRequest)
+			return nil, "", false
+		}
+		q, ok := quotas.get(name)
+		if !ok {
+			http.Error(rw, "quota not found", http.StatusNotFound)
+			return nil, "", false
+		}
+		return q, consumer, true
+	}
+	debugMux.HandleFunc("/debug/quota/usage", func(rw http.ResponseWriter, r *http.Request) {
+		q, consumer, ok := lookup(rw, r)
+		if !ok {
+			return
+		}
+		u, err := q.usage(r.Context(), consumer, q.windowOf(time.Now()))
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(u)
+	})
+	debugMux.HandleFunc("/debug/quota/reset", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q, consumer, ok := lookup(rw, r)
+		if !ok {
+			return
+		}
+		var before string
+		if u, err := q.usage(r.Context(), consumer, q.windowOf(time.Now())); err == nil {
+			before = strconv.FormatInt(u.Used, 10)
+		}
+		if err := audit.WriteRequest(r, &audit.Record{Action: "quota.reset", Target: q.name + "/" + consumer, Before: before, After: "0"}); err != nil {
+			log.Errorf("Failed to write audit record of quota reset %s of %s: %+v", q.name, consumer, err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		if err := q.reset(r.Context(), consumer); err != nil {
+			log.Errorf("Failed to reset quota %s of %s: %+v", q.name, consumer, err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Warnf("quota %s of %s reset", q.name, consumer)
+	})
+	return debugMux
+}
@@ -0,0 +1,359 @@
+package quota
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/pkg/merr"
+	v1 "github.com/aide-family/goddess/pkg/middleware/quota/v1"
+)
+
+var _metricQuotaRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "quota_rejected_total",
+	Help:      "The total number of requests rejected by exhausted quotas",
+}, []string{"protocol", "method", "path", "service", "basePath", "quota"})
+
+func init() {
+	middleware.RegisterV2("quota", Middleware)
+	metrics.Register(_metricQuotaRejected)
+}
+
+// Usage is the usage of a consumer in the quota window.
+type Usage struct {
+	Name      string    `json:"name"`
+	Consumer  string    `json:"consumer"`
+	Window    string    `json:"window"`
+	Used      int64     `json:"used"`
+	Limit     int64     `json:"limit"`
+	Remaining int64     `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+type windowState struct {
+	id    string
+	reset time.Time
+}
+
+type consumerWindow struct {
+	consumer string
+	window   windowState
+}
+
+type quota struct {
+	name       string
+	limit      int64
+	window     v1.Quota_Window
+	location   *time.Location
+	store      Store
+	prefix     string
+	consumer   func(*http.Request) string
+	failClosed bool
+	anonymous  bool
+
+	lock sync.Mutex
+	// consumer windows seen by this gateway, used to export the usage, nil if the export is not configured.
+	seen map[consumerWindow]struct{}
+	// the latest window tracked, the windows before the one preceding it are pruned.
+	current windowState
+}
+
+// windowOf returns the id of the window containing now and the start of the next window.
+func (q *quota) windowOf(now time.Time) windowState {
+	t := now.In(q.location)
+	if q.window == v1.Quota_MONTHLY {
+		return windowState{id: t.Format("2006-01"), reset: time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, q.location)}
+	}
+	return windowState{id: t.Format("2006-01-02"), reset: time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, q.location)}
+}
+
+func (q *quota) key(consumer string, w windowState) string {
+	return q.prefix + q.name + ":" + consumer + ":" + w.id
+}
+
+// counters are kept for a while after the window for the usage export.
+func (q *quota) expireAt(w windowState) time.Time {
+	return w.reset.Add(time.Hour)
+}
+
+func (q *quota) newUsage(consumer string, w windowState, used int64) *Usage {
+	remaining := q.limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &Usage{
+		Name:      q.name,
+		Consumer:  consumer,
+		Window:    w.id,
+		Used:      used,
+		Limit:     q.limit,
+		Remaining: remaining,
+		Reset:     w.reset,
+	}
+}
+
+func (q *quota) usage(ctx context.Context, consumer string, w windowState) (*Usage, error) {
+	used, err := q.store.Get(ctx, q.key(consumer, w))
+	if err != nil {
+		return nil, err
+	}
+	return q.newUsage(consumer, w, used), nil
+}
+
+func (q *quota) reset(ctx context.Context, consumer string) error {
+	return q.store.Reset(ctx, q.key(consumer, q.windowOf(time.Now())))
+}
+
+func (q *quota) track(consumer string, w windowState) {
+	if q.seen == nil {
+		return
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if w.id != q.current.id {
+		// the previous window is kept to be reported for the last time, the older ones have been reported
+		for cw := range q.seen {
+			if cw.window.id != q.current.id {
+				delete(q.seen, cw)
+			}
+		}
+		q.current = w
+	}
+	q.seen[consumerWindow{consumer: consumer, window: w}] = struct{}{}
+}
+
+// take counts the request, the request exceeding the limit is not counted.
+func (q *quota) take(ctx context.Context, consumer string) (*Usage, bool, error) {
+	w := q.windowOf(time.Now())
+	key := q.key(consumer, w)
+	used, err := q.store.IncrBy(ctx, key, 1, q.expireAt(w))
+	if err != nil {
+		return nil, false, err
+	}
+	q.track(consumer, w)
+	if used > q.limit {
+		if _, err := q.store.IncrBy(ctx, key, -1, q.expireAt(w)); err != nil {
+			log.Errorf("Failed to revert quota usage %s: %+v", key, err)
+		}
+		return q.newUsage(consumer, w, q.limit), false, nil
+	}
+	return q.newUsage(consumer, w, used), true, nil
+}
+
+func setQuotaHeaders(h http.Header, u *Usage) {
+	h.Set("X-Quota-Limit", strconv.FormatInt(u.Limit, 10))
+	h.Set("X-Quota-Remaining", strconv.FormatInt(u.Remaining, 10))
+	h.Set("X-Quota-Reset", strconv.FormatInt(u.Reset.Unix(), 10))
+}
+
+func newConsumer(options *v1.Quota) (func(*http.Request) string, error) {
+	switch consumer := options.GetConsumer().(type) {
+	case *v1.Quota_Header:
+		return func(req *http.Request) string { return req.Header.Get(consumer.Header) }, nil
+	case *v1.Quota_JwtClaim:
+		return func(req *http.Request) string { return unverifiedJWTClaim(req, consumer.JwtClaim) }, nil
+	case *v1.Quota_Metadata:
+		return func(req *http.Request) string {
+			reqOpt, ok := middleware.FromRequestContext(req.Context())
+			if !ok {
+				return ""
+			}
+			return reqOpt.Metadata[consumer.Metadata]
+		}, nil
+	case *v1.Quota_ConsumerId:
+		return func(req *http.Request) string {
+			consumer, _ := middleware.ConsumerID.FromContext(req.Context())
+			return consumer
+		}, nil
+	default:
+		return nil, errors.New("quota consumer is required")
+	}
+}
+
+// unverifiedJWTClaim reads a claim from the bearer token without verifying the signature,
+// the token is verified by the jwt middleware.
+func unverifiedJWTClaim(req *http.Request, claim string) string {
+	auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(auths) != 2 || !strings.EqualFold(auths[0], "Bearer") {
+		return ""
+	}
+	parts := strings.Split(auths[1], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := map[string]any{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	v, ok := claims[claim]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func newQuota(options *v1.Quota) (*quota, error) {
+	if options.Name == "" {
+		return nil, errors.New("quota name is required")
+	}
+	if options.Limit == 0 {
+		return nil, errors.New("quota limit is required")
+	}
+	consumer, err := newConsumer(options)
+	if err != nil {
+		return nil, err
+	}
+	location := time.UTC
+	if options.TimeZone != "" {
+		if location, err = time.LoadLocation(options.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	store, prefix := newStore(options)
+	q := &quota{
+		name:       options.Name,
+		limit:      int64(options.Limit),
+		window:     options.Window,
+		location:   location,
+		store:      store,
+		prefix:     prefix,
+		consumer:   consumer,
+		failClosed: options.FailClosed,
+		anonymous:  !options.RejectAnonymous,
+	}
+	if options.Export != nil {
+		q.seen = map[consumerWindow]struct{}{}
+	}
+	return q, nil
+}
+
+func newResponse(err *kerrors.Error, u *Usage) (*http.Response, error) {
+	body, jerr := json.Marshal(err)
+	if jerr != nil {
+		return nil, jerr
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	if u != nil {
+		setQuotaHeaders(header, u)
+	}
+	return &http.Response{
+		StatusCode: int(err.Code),
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
+
+func rejectedRequestIncr(req *http.Request, name string) {
+	labels, ok := middleware.MetricsLabelsFromContext(req.Context())
+	if ok {
+		_metricQuotaRejected.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), name).Inc()
+	}
+}
+
+// Middleware is a quota middleware.
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Quota{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	q, err := newQuota(options)
+	if err != nil {
+		return nil, err
+	}
+	quotas.add(q)
+	exporter := newExporter(q, options.Export)
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			consumer := q.consumer(req)
+			if consumer == "" {
+				if q.anonymous {
+					return next.RoundTrip(req)
+				}
+				return newResponse(merr.ErrorUnauthorized("quota consumer is required"), nil)
+			}
+			if opts, ok := middleware.FromRequestContext(req.Context()); ok && opts.Attempt > 0 {
+				// the request is counted once by its first attempt, the retries only report the usage
+				return q.retry(req, consumer, next)
+			}
+			u, ok, err := q.take(req.Context(), consumer)
+			if err != nil {
+				log.Errorf("Failed to take quota %s of %s: %+v", q.name, consumer, err)
+				if q.failClosed {
+					return newResponse(kerrors.ServiceUnavailable("QUOTA_UNAVAILABLE", "quota unavailable"), nil)
+				}
+				return next.RoundTrip(req)
+			}
+			if !ok {
+				rejectedRequestIncr(req, q.name)
+				return newResponse(merr.ErrorTooManyRequests("quota %s exhausted", q.name), u)
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			setQuotaHeaders(resp.Header, u)
+			return resp, nil
+		})
+	}, &quotaCloser{quota: q, exporter: exporter}), nil
+}
+
+// retry sends the retry of the request without taking the quota.
+func (q *quota) retry(req *http.Request, consumer string, next http.RoundTripper) (*http.Response, error) {
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	u, err := q.usage(req.Context(), consumer, q.windowOf(time.Now()))
+	if err != nil {
+		log.Errorf("Failed to get quota usage %s of %s: %+v", q.name, consumer, err)
+		return resp, nil
+	}
+	setQuotaHeaders(resp.Header, u)
+	return resp, nil
+}
+
+type quotaCloser struct {
+	once     sync.Once
+	quota    *quota
+	exporter *exporter
+}
+
+func (c *quotaCloser) Close() (err error) {
+	c.once.Do(func() {
+		quotas.remove(c.quota)
+		if c.exporter != nil {
+			c.exporter.close()
+		}
+		if closer, ok := c.quota.store.(io.Closer); ok {
+			err = closer.Close()
+		}
+	})
+	return
+}
@@ -0,0 +1,231 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/quota/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Quota) middleware.MiddlewareV2 {
+	t.Helper()
+	opts, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "quota", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestQuota(t *testing.T) {
+	m := newMiddleware(t, &v1.Quota{
+		Name:            "test-daily",
+		Consumer:        &v1.Quota_Header{Header: "X-API-Key"},
+		Limit:           2,
+		RejectAnonymous: true,
+	})
+	defer m.Close()
+	rt := m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	}))
+	do := func(key string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	for i, want := range []string{"1", "0"} {
+		resp := do("alice")
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Quota-Remaining") != want {
+			t.Fatalf("request %d: unexpected response %d remaining %s", i, resp.StatusCode, resp.Header.Get("X-Quota-Remaining"))
+		}
+	}
+	resp := do("alice")
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("X-Quota-Limit") != "2" || resp.Header.Get("X-Quota-Remaining") != "0" {
+		t.Fatalf("want exhausted quota but got %d %v", resp.StatusCode, resp.Header)
+	}
+	reset := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Unix()
+	if resp.Header.Get("X-Quota-Reset") != strconv.FormatInt(reset, 10) {
+		t.Fatalf("want reset at %d but got %s", reset, resp.Header.Get("X-Quota-Reset"))
+	}
+	if resp := do("bob"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want other consumer to be accepted but got %d", resp.StatusCode)
+	}
+	if resp := do(""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("want anonymous request to be rejected but got %d", resp.StatusCode)
+	}
+
+	debug := QuotaDebugger.DebugHandler()
+	w := httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/quota/usage?name=test-daily&consumer=alice", nil))
+	u := &Usage{}
+	if err := json.Unmarshal(w.Body.Bytes(), u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Used != 2 || u.Remaining != 0 {
+		t.Fatalf("unexpected usage: %+v", u)
+	}
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/debug/quota/reset", strings.NewReader(url.Values{"name": {"test-daily"}, "consumer": {"alice"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	debug.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected reset status: %d", w.Code)
+	}
+	if resp := do("alice"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want reset quota to be accepted but got %d", resp.StatusCode)
+	}
+}
+
+func TestQuotaRetry(t *testing.T) {
+	m := newMiddleware(t, &v1.Quota{
+		Name:     "test-retry",
+		Consumer: &v1.Quota_Header{Header: "X-API-Key"},
+		Limit:    2,
+	})
+	defer m.Close()
+	rt := m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	}))
+	do := func(attempt int) *http.Response {
+		opts := middleware.NewRequestOptions(&config.Endpoint{})
+		opts.Attempt = attempt
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(middleware.NewRequestContext(req.Context(), opts))
+		req.Header.Set("X-API-Key", "alice")
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	// the retries of the request are not counted
+	for attempt, want := range []string{"1", "1", "1"} {
+		if resp := do(attempt); resp.StatusCode != http.StatusOK || resp.Header.Get("X-Quota-Remaining") != want {
+			t.Fatalf("attempt %d: unexpected response %d remaining %s", attempt, resp.StatusCode, resp.Header.Get("X-Quota-Remaining"))
+		}
+	}
+	if resp := do(0); resp.StatusCode != http.StatusOK || resp.Header.Get("X-Quota-Remaining") != "0" {
+		t.Fatalf("want the next request counted but got %d remaining %s", resp.StatusCode, resp.Header.Get("X-Quota-Remaining"))
+	}
+}
+
+func TestQuotaWindow(t *testing.T) {
+	q, err := newQuota(&v1.Quota{
+		Name:     "test-monthly",
+		Consumer: &v1.Quota_JwtClaim{JwtClaim: "tenant"},
+		Limit:    1,
+		Window:   v1.Quota_MONTHLY,
+		TimeZone: "Asia/Shanghai",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-01-31 20:00 UTC is 2024-02-01 04:00 in Shanghai
+	w := q.windowOf(time.Date(2024, 1, 31, 20, 0, 0, 0, time.UTC))
+	if w.id != "2024-02" || !w.reset.Equal(time.Date(2024, 2, 29, 16, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected window: %s %s", w.id, w.reset.UTC())
+	}
+	if _, err := newQuota(&v1.Quota{Name: "invalid", Limit: 1}); err == nil {
+		t.Fatal("want error without consumer")
+	}
+}
+
+func TestQuotaExport(t *testing.T) {
+	received := make(chan []*Usage, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var usages []*Usage
+		json.NewDecoder(r.Body).Decode(&usages)
+		received <- usages
+	}))
+	defer srv.Close()
+	m := newMiddleware(t, &v1.Quota{
+		Name:     "test-export",
+		Consumer: &v1.Quota_Header{Header: "X-API-Key"},
+		Limit:    10,
+		Export:   &v1.Export{Interval: durationpb.New(time.Hour), Sink: &v1.Export_Webhook{Webhook: srv.URL}},
+	})
+	rt := m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	}))
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Key", "carol")
+		if _, err := rt.RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the usage is exported on close
+	m.Close()
+	select {
+	case usages := <-received:
+		if len(usages) != 1 || usages[0].Consumer != "carol" || usages[0].Used != 3 {
+			t.Fatalf("unexpected usages: %+v", usages)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want usage exported")
+	}
+}
+
+func TestQuotaTracking(t *testing.T) {
+	q, err := newQuota(&v1.Quota{
+		Name:     "test-untracked",
+		Consumer: &v1.Quota_Header{Header: "X-API-Key"},
+		Limit:    10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100; i++ {
+		if _, _, err := q.take(context.Background(), "consumer-"+strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the consumers are not tracked without the export
+	if len(q.seen) != 0 {
+		t.Fatalf("want no consumer windows tracked but got %d", len(q.seen))
+	}
+
+	q, err = newQuota(&v1.Quota{
+		Name:     "test-tracked",
+		Consumer: &v1.Quota_Header{Header: "X-API-Key"},
+		Limit:    10,
+		Export:   &v1.Export{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, day := range []int{1, 2, 3} {
+		w := q.windowOf(time.Date(2024, 1, day, 12, 0, 0, 0, time.UTC))
+		q.track("alice", w)
+		q.track("bob", w)
+	}
+	// the windows before the previous one are pruned though the usage is never exported
+	if len(q.seen) != 4 {
+		t.Fatalf("want the consumer windows of the last 2 windows but got %d", len(q.seen))
+	}
+	for cw := range q.seen {
+		if cw.window.id == "2024-01-01" {
+			t.Fatalf("want the past window pruned: %+v", cw)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	v1 "github.com/aide-family/goddess/pkg/middleware/quota/v1"
+	"github.com/redis/go-redis/v9"
+)
+
+const _defaultRedisKeyPrefix = "goddess:quota:"
+
+// Store is the usage counters of the quota windows.
+type Store interface {
+	// IncrBy adds delta to the counter and returns the new value, the counter expires at expireAt.
+	IncrBy(ctx context.Context, key string, delta int64, expireAt time.Time) (int64, error)
+	Get(ctx context.Context, key string) (int64, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// memoryStore is shared by all the quota middlewares, so the usage survives config reloads.
+var _memoryStore = newMemoryStore()
+
+type counter struct {
+	value    int64
+	expireAt time.Time
+}
+
+type memoryStore struct {
+	lock     sync.Mutex
+	counters map[string]*counter
+	// the expired counters are removed on the first write after gcAt.
+	gcAt time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{counters: map[string]*counter{}}
+}
+
+func (s *memoryStore) IncrBy(_ context.Context, key string, delta int64, expireAt time.Time) (int64, error) {
+	now := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if now.After(s.gcAt) {
+		for k, c := range s.counters {
+			if now.After(c.expireAt) {
+				delete(s.counters, k)
+			}
+		}
+		s.gcAt = now.Add(time.Minute)
+	}
+	c, ok := s.counters[key]
+	if !ok || now.After(c.expireAt) {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.value += delta
+	c.expireAt = expireAt
+	return c.value, nil
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	c, ok := s.counters[key]
+	if !ok || time.Now().After(c.expireAt) {
+		return 0, nil
+	}
+	return c.value, nil
+}
+
+func (s *memoryStore) Reset(_ context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.counters, key)
+	return nil
+}
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(in *v1.Quota_Redis) *redisStore {
+	return &redisStore{
+		client: redis.NewClient(&redis.Options{
+			Addr:     in.Addr,
+			Password: in.Password,
+			DB:       int(in.Db),
+		}),
+	}
+}
+
+func (s *redisStore) IncrBy(ctx context.Context, key string, delta int64, expireAt time.Time) (int64, error) {
+	pipe := s.client.TxPipeline()
+	incr := pipe.IncrBy(ctx, key, delta)
+	pipe.ExpireAt(ctx, key, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (int64, error) {
+	n, err := s.client.Get(ctx, key).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+func (s *redisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
+
+func newStore(in *v1.Quota) (Store, string) {
+	switch store := in.GetStore().(type) {
+	case *v1.Quota_Redis_:
+		prefix := store.Redis.KeyPrefix
+		if prefix == "" {
+			prefix = _defaultRedisKeyPrefix
+		}
+		return newRedisStore(store.Redis), prefix
+	default:
+		return _memoryStore, ""
+	}
+}
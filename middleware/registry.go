@@ -4,13 +4,15 @@ import (
 	"errors"
 	"strings"
 
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/metrics"
 	configv1 "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
-	LOG                     = log.NewHelper(log.With(log.GetLogger(), "source", "middleware"))
+	LOG                     = log.NewHelper(log.With(logs.Error, "source", "middleware"))
 	globalRegistry          = NewRegistry()
 	_failedMiddlewareCreate = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "go",
@@ -21,7 +23,7 @@ var (
 )
 
 func init() {
-	prometheus.MustRegister(_failedMiddlewareCreate)
+	metrics.Register(_failedMiddlewareCreate)
 }
 
 // ErrNotFound is middleware not found.
@@ -29,29 +31,58 @@ var ErrNotFound = errors.New("Middleware has not been registered")
 
 // Registry is the interface for callers to get registered middleware.
 type Registry interface {
-	Register(name string, factory Factory)
-	RegisterV2(name string, factory FactoryV2)
+	Register(name string, factory Factory, opts ...RegisterOption)
+	RegisterV2(name string, factory FactoryV2, opts ...RegisterOption)
 	Create(cfg *configv1.Middleware) (MiddlewareV2, error)
+	Shareable(name string) bool
+}
+
+// RegisterOption is the option of the registration of a middleware.
+type RegisterOption func(*registration)
+
+type registration struct {
+	shareable bool
+}
+
+// Shareable declares the instances of the middleware hold no per endpoint state, so the endpoints of the same
+// middleware config share the instance built once per update. The middlewares counting or limiting the
+// requests of their endpoint, such as the rate limiters and the circuit breakers, must not be shareable.
+func Shareable() RegisterOption {
+	return func(r *registration) {
+		r.shareable = true
+	}
 }
 
 type middlewareRegistry struct {
 	middleware map[string]FactoryV2
+	shareable  map[string]bool
 }
 
 // NewRegistry returns a new middleware registry.
 func NewRegistry() Registry {
 	return &middlewareRegistry{
 		middleware: map[string]FactoryV2{},
+		shareable:  map[string]bool{},
 	}
 }
 
 // Register registers one middleware.
-func (p *middlewareRegistry) Register(name string, factory Factory) {
-	p.middleware[createFullName(name)] = wrapFactory(factory)
+func (p *middlewareRegistry) Register(name string, factory Factory, opts ...RegisterOption) {
+	p.RegisterV2(name, wrapFactory(factory), opts...)
 }
 
-func (p *middlewareRegistry) RegisterV2(name string, factory FactoryV2) {
+func (p *middlewareRegistry) RegisterV2(name string, factory FactoryV2, opts ...RegisterOption) {
+	r := &registration{}
+	for _, o := range opts {
+		o(r)
+	}
 	p.middleware[createFullName(name)] = factory
+	p.shareable[createFullName(name)] = r.shareable
+}
+
+// Shareable reports whether the middleware is registered as shareable.
+func (p *middlewareRegistry) Shareable(name string) bool {
+	return p.shareable[createFullName(name)]
 }
 
 // Create instantiates a middleware based on `cfg`.
@@ -92,13 +123,18 @@ func createFullName(name string) string {
 }
 
 // Register registers one middleware.
-func Register(name string, factory Factory) {
-	globalRegistry.Register(name, factory)
+func Register(name string, factory Factory, opts ...RegisterOption) {
+	globalRegistry.Register(name, factory, opts...)
 }
 
 // RegisterV2 registers one v2 middleware.
-func RegisterV2(name string, factory FactoryV2) {
-	globalRegistry.RegisterV2(name, factory)
+func RegisterV2(name string, factory FactoryV2, opts ...RegisterOption) {
+	globalRegistry.RegisterV2(name, factory, opts...)
+}
+
+// IsShareable reports whether the middleware is registered as shareable, see Shareable.
+func IsShareable(name string) bool {
+	return globalRegistry.Shareable(name)
 }
 
 // Create instantiates a middleware based on `cfg`.
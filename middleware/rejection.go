@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/aide-family/goddess/checkpoint"
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The reason codes of the rejections, the middlewares may define their own in the same snake case form, the
+// codes are the values of the reason label so they must be bounded.
+const (
+	RejectionMissingToken          = "missing_token"
+	RejectionTokenMalformed        = "token_malformed"
+	RejectionTokenExpired          = "token_expired"
+	RejectionTokenInvalidSignature = "token_invalid_signature"
+	RejectionTokenInvalid          = "token_invalid"
+	RejectionTokenRevoked          = "token_revoked"
+	RejectionRevocationUnavailable = "revocation_unavailable"
+	RejectionNamespaceRequired     = "namespace_required"
+	RejectionNamespaceNotAllowed   = "namespace_not_allowed"
+	RejectionNamespaceUnavailable  = "namespace_validation_unavailable"
+	RejectionIPDenied              = "ip_denied"
+	RejectionQuotaExceeded         = "quota_exceeded"
+)
+
+// the metadata keys of the rejection in the error response
+const (
+	rejectionMetadataReason     = "rejection_reason"
+	rejectionMetadataMiddleware = "middleware"
+	rejectionMetadataRequestID  = "request_id"
+)
+
+var _metricMiddlewareRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "middleware_rejections_total",
+	Help:      "The total number of the requests rejected by the middlewares by the reason code",
+}, []string{"middleware", "reason", "route"})
+
+func init() {
+	metrics.Register(_metricMiddlewareRejections)
+	checkpoint.Register("go_gateway_middleware_rejections_total", _metricMiddlewareRejections)
+}
+
+// Reject replies the rejection of the request by the middleware name for the reason code, the status code, the
+// reason and the message of the response are from err, eg: merr.ErrorForbidden("namespace is required"), 500 if
+// err is not a kratos error. The reason code, the middleware and the request id are added to the metadata of
+// the response, the rejection is counted by go_gateway_middleware_rejections_total and logged.
+//
+// The auth middlewares with the monitor mode reject by Enforcement.Reject, which calls Reject in the enforce mode.
+func Reject(req *http.Request, name, reason string, err error) (*http.Response, error) {
+	MarkResponseSource(req, name)
+	kerr := errors.Clone(errors.FromError(err))
+	md := make(map[string]string, len(kerr.Metadata)+3)
+	for k, v := range kerr.Metadata {
+		md[k] = v
+	}
+	md[rejectionMetadataReason] = reason
+	md[rejectionMetadataMiddleware] = name
+	if id, ok := RequestID.FromContext(req.Context()); ok && id != "" {
+		md[rejectionMetadataRequestID] = id
+	}
+	kerr.Metadata = md
+
+	route := ""
+	if labels, ok := MetricsLabelsFromContext(req.Context()); ok {
+		route = labels.Path()
+	}
+	_metricMiddlewareRejections.WithLabelValues(name, reason, route).Inc()
+	keyvals := []any{log.DefaultMessageKey, "Request is rejected by the middleware",
+		"reason", reason, "middleware", name, "route", route, "method", req.Method, "path", req.URL.Path,
+		"code", kerr.Code, "request_id", md[rejectionMetadataRequestID], "error", kerr.Message}
+	if kerr.Code >= http.StatusInternalServerError {
+		// the middleware is unable to decide, eg: the validation API is unavailable
+		LOG.Warnw(keyvals...)
+	} else {
+		// the rejections are counted by the metric, the logs of them would flood under an attack
+		LOG.Debugw(keyvals...)
+	}
+
+	body, err := json.Marshal(kerr)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: int(kerr.Code),
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}, nil
+}
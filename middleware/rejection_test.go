@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestReject(t *testing.T) {
+	opts := NewRequestOptions(&config.Endpoint{Path: "/reject"})
+	RequestID.Set(opts, "req-1")
+	req := httptest.NewRequest(http.MethodGet, "/reject", nil)
+	req = req.WithContext(NewRequestContext(req.Context(), opts))
+	counter := _metricMiddlewareRejections.WithLabelValues("ipfilter", RejectionIPDenied, "/reject")
+	before := testutil.ToFloat64(counter)
+
+	resp, err := Reject(req, "ipfilter", RejectionIPDenied, kerrors.Forbidden("FORBIDDEN", "ip denied").WithMetadata(map[string]string{"ip": "10.0.0.1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("want the 403 json rejection but got %d %v", resp.StatusCode, resp.Header)
+	}
+	out := &kerrors.Error{}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Reason != "FORBIDDEN" || out.Message != "ip denied" {
+		t.Fatalf("want the reason and the message of the error but got %+v", out)
+	}
+	want := map[string]string{"ip": "10.0.0.1", "rejection_reason": RejectionIPDenied, "middleware": "ipfilter", "request_id": "req-1"}
+	for k, v := range want {
+		if out.Metadata[k] != v {
+			t.Fatalf("want metadata %s=%s but got %v", k, v, out.Metadata)
+		}
+	}
+	if n := testutil.ToFloat64(counter) - before; n != 1 {
+		t.Fatalf("want the rejection counted once but got %v", n)
+	}
+	if source, _ := ResponseSource.Get(opts); source != "ipfilter" {
+		t.Fatalf("want the response accounted to the middleware but got %q", source)
+	}
+
+	resp, err = Reject(httptest.NewRequest(http.MethodGet, "/", nil), "custom", "custom_reason", errors.New("boom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("want 500 for the error not a kratos error but got %d", resp.StatusCode)
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/go-kratos/kratos/v2/selector"
@@ -20,7 +21,11 @@ type RequestOptions struct {
 	CurrentNode          selector.Node
 	DoneFunc             selector.DoneFunc
 	LastAttempt          bool
-	Values               RequestValues
+	// Attempt is the current attempt of the request, starts from 0.
+	Attempt int
+	Values  RequestValues
+	// UpstreamRequestHooks are applied to the upstream request of the current attempt once the node is selected.
+	UpstreamRequestHooks []func(*http.Request) error
 }
 
 type RequestValues interface {
@@ -97,6 +102,34 @@ func NewRequestOptions(c *config.Endpoint) *RequestOptions {
 	return o
 }
 
+// UpstreamInfo is the upstream selected by the last attempt of the request.
+type UpstreamInfo struct {
+	Addr     string
+	Attempts int
+}
+
+// Upstream returns the upstream selected by the last attempt, the attempts are counted by the retry loop
+// including the ones failed before selecting a node.
+func (o *RequestOptions) Upstream() UpstreamInfo {
+	if len(o.Backends) == 0 && o.Attempt == 0 {
+		return UpstreamInfo{}
+	}
+	info := UpstreamInfo{Attempts: o.Attempt + 1}
+	if len(o.Backends) > 0 {
+		info.Addr = o.Backends[len(o.Backends)-1]
+	}
+	return info
+}
+
+// UpstreamFromContext returns the upstream selected by the last attempt from context.
+func UpstreamFromContext(ctx context.Context) (UpstreamInfo, bool) {
+	o, ok := ctx.Value(contextKey{}).(*RequestOptions)
+	if ok {
+		return o.Upstream(), true
+	}
+	return UpstreamInfo{}, false
+}
+
 // NewRequestContext returns a new Context that carries value.
 func NewRequestContext(ctx context.Context, o *RequestOptions) context.Context {
 	return context.WithValue(ctx, contextKey{}, o)
@@ -156,6 +189,15 @@ func WithSelectorFitler(ctx context.Context, fn selector.NodeFilter) context.Con
 	return ctx
 }
 
+// WithUpstreamRequestHook with upstream request hook of the current attempt into context.
+func WithUpstreamRequestHook(ctx context.Context, hook func(*http.Request) error) context.Context {
+	o, ok := ctx.Value(contextKey{}).(*RequestOptions)
+	if ok {
+		o.UpstreamRequestHooks = append(o.UpstreamRequestHooks, hook)
+	}
+	return ctx
+}
+
 func MetricsLabelsFromContext(ctx context.Context) (MetricsLabels, bool) {
 	o, ok := ctx.Value(contextKey{}).(*RequestOptions)
 	if ok {
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+)
+
+// MarkResponseSource marks the current attempt of the request replied by the middleware instead of the upstream,
+// the response is accounted to the middleware in the metrics.
+func MarkResponseSource(req *http.Request, name string) {
+	if o, ok := FromRequestContext(req.Context()); ok {
+		ResponseSource.Set(o, name)
+	}
+}
+
+// NewStreamingResponse returns the response replied by the middleware with a streaming body, eg: server-sent
+// events. The body is copied to the client as it is read and flushed at the flush_interval of the endpoint,
+// or immediately if not configured, the body is closed once copied.
+func NewStreamingResponse(req *http.Request, name string, statusCode int, header http.Header, body io.ReadCloser) *http.Response {
+	MarkResponseSource(req, name)
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        http.StatusText(statusCode),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          body,
+		ContentLength: -1,
+		Request:       req,
+	}
+}
@@ -14,7 +14,7 @@ import (
 )
 
 func init() {
-	middleware.Register("rewrite", Middleware)
+	middleware.Register("rewrite", Middleware, middleware.Shareable())
 }
 
 func stripPrefix(origin string, prefix string) string {
@@ -46,6 +46,10 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				req.Host = *options.HostRewrite
 			}
 			if options.StripPrefix != nil {
+				// the escaped path is stripped as well so the encoded segments such as %2F are kept
+				if req.URL.RawPath != "" {
+					req.URL.RawPath = stripPrefix(req.URL.RawPath, options.GetStripPrefix())
+				}
 				req.URL.Path = stripPrefix(req.URL.Path, options.GetStripPrefix())
 			}
 			if requestHeadersRewrite != nil {
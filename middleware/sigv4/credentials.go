@@ -0,0 +1,203 @@
+package sigv4
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+var (
+	// the metadata endpoints are variables to be replaced in tests.
+	_ecsEndpoint         = "http://169.254.170.2"
+	_ec2MetadataEndpoint = "http://169.254.169.254"
+
+	// temporary credentials are refreshed ahead of the expiry.
+	_refreshWindow = 5 * time.Minute
+
+	_metadataClient = &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: &http.Transport{Proxy: nil},
+	}
+
+	errNoCredentials = errors.New("sigv4: no credentials found")
+)
+
+// Credentials is the AWS credentials, Expires is zero for the long-term credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+type credentialsProvider interface {
+	Retrieve(ctx context.Context) (*Credentials, error)
+}
+
+type staticProvider struct {
+	creds *Credentials
+}
+
+func (p *staticProvider) Retrieve(context.Context) (*Credentials, error) {
+	return p.creds, nil
+}
+
+type envProvider struct{}
+
+func (envProvider) Retrieve(context.Context) (*Credentials, error) {
+	id := os.Getenv("AWS_ACCESS_KEY_ID")
+	secret := os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if id == "" || secret == "" {
+		return nil, errNoCredentials
+	}
+	return &Credentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+}
+
+// metadataCredentials is the response of the ECS and EC2 credential endpoints.
+type metadataCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func fetchMetadata(ctx context.Context, method, url string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := _metadataClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sigv4: unexpected status %d from %s", resp.StatusCode, url)
+	}
+	return body, nil
+}
+
+func parseMetadataCredentials(body []byte) (*Credentials, error) {
+	out := &metadataCredentials{}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, err
+	}
+	if out.AccessKeyID == "" || out.SecretAccessKey == "" {
+		return nil, errNoCredentials
+	}
+	return &Credentials{
+		AccessKeyID:     out.AccessKeyID,
+		SecretAccessKey: out.SecretAccessKey,
+		SessionToken:    out.Token,
+		Expires:         out.Expiration,
+	}, nil
+}
+
+// ecsProvider retrieves the task role credentials from the ECS container credentials endpoint.
+type ecsProvider struct{}
+
+func (ecsProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	url := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		url = _ecsEndpoint + relative
+	}
+	if url == "" {
+		return nil, errNoCredentials
+	}
+	header := http.Header{}
+	if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+		header.Set("Authorization", token)
+	}
+	body, err := fetchMetadata(ctx, http.MethodGet, url, header)
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadataCredentials(body)
+}
+
+// ec2Provider retrieves the instance role credentials from the EC2 instance metadata service v2.
+type ec2Provider struct{}
+
+func (ec2Provider) Retrieve(ctx context.Context) (*Credentials, error) {
+	token, err := fetchMetadata(ctx, http.MethodPut, _ec2MetadataEndpoint+"/latest/api/token",
+		http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": []string{"21600"}})
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": []string{string(token)}}
+	roles, err := fetchMetadata(ctx, http.MethodGet, _ec2MetadataEndpoint+"/latest/meta-data/iam/security-credentials/", header)
+	if err != nil {
+		return nil, err
+	}
+	role := strings.TrimSpace(strings.SplitN(string(roles), "\n", 2)[0])
+	if role == "" {
+		return nil, errNoCredentials
+	}
+	body, err := fetchMetadata(ctx, http.MethodGet, _ec2MetadataEndpoint+"/latest/meta-data/iam/security-credentials/"+role, header)
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadataCredentials(body)
+}
+
+// chainProvider returns the credentials of the first available provider.
+type chainProvider []credentialsProvider
+
+func (c chainProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	errs := make([]error, 0, len(c))
+	for _, p := range c {
+		creds, err := p.Retrieve(ctx)
+		if err == nil {
+			return creds, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func defaultProvider() credentialsProvider {
+	return chainProvider{envProvider{}, ecsProvider{}, ec2Provider{}}
+}
+
+// cachedProvider caches the credentials until they are about to expire.
+type cachedProvider struct {
+	provider credentialsProvider
+	lock     sync.Mutex
+	creds    *Credentials
+}
+
+func (p *cachedProvider) Retrieve(ctx context.Context) (*Credentials, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := time.Now()
+	if p.creds != nil && (p.creds.Expires.IsZero() || now.Before(p.creds.Expires.Add(-_refreshWindow))) {
+		return p.creds, nil
+	}
+	creds, err := p.provider.Retrieve(ctx)
+	if err != nil {
+		// the cached credentials are used until they are expired.
+		if p.creds != nil && now.Before(p.creds.Expires) {
+			log.Warnf("sigv4: failed to refresh credentials, using the cached credentials: %v", err)
+			return p.creds, nil
+		}
+		return nil, err
+	}
+	p.creds = creds
+	return creds, nil
+}
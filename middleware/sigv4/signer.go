@@ -0,0 +1,223 @@
+package sigv4
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	_algorithm       = "AWS4-HMAC-SHA256"
+	_timeFormat      = "20060102T150405Z"
+	_unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// emptyPayloadHash is the hex encoded sha256 of the empty payload.
+var emptyPayloadHash = hashHex(nil)
+
+// unsignedHeaders are never signed, the hop-by-hop headers are removed or rewritten by the transport
+// and the others are commonly changed by the intermediaries.
+var unsignedHeaders = map[string]struct{}{
+	"authorization":       {},
+	"user-agent":          {},
+	"x-amzn-trace-id":     {},
+	"expect":              {},
+	"content-length":      {},
+	"connection":          {},
+	"proxy-connection":    {},
+	"keep-alive":          {},
+	"proxy-authenticate":  {},
+	"proxy-authorization": {},
+	"te":                  {},
+	"trailer":             {},
+	"transfer-encoding":   {},
+	"upgrade":             {},
+}
+
+type signer struct {
+	region                 string
+	service                string
+	disableURIPathEscaping bool
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// payloadHash returns the hash of the buffered request body, the body is restored for sending.
+func payloadHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return hashHex(body), nil
+}
+
+// escape encodes all the bytes except the unreserved characters, the slash is kept in paths.
+func escape(s string, path bool) string {
+	const upperhex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (path && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(upperhex[c>>4])
+		b.WriteByte(upperhex[c&15])
+	}
+	return b.String()
+}
+
+func (s *signer) canonicalURI(u *url.URL) string {
+	uri := u.EscapedPath()
+	if uri == "" {
+		return "/"
+	}
+	if s.disableURIPathEscaping {
+		return uri
+	}
+	return escape(uri, true)
+}
+
+func canonicalQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	var pairs [][2]string
+	for _, item := range strings.Split(rawQuery, "&") {
+		if item == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(item, "=")
+		if k, err := url.QueryUnescape(key); err == nil {
+			key = k
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		pairs = append(pairs, [2]string{escape(key, false), escape(value, false)})
+	}
+	// sorted by the encoded key and then the value, not by the joined pair: "a-b=2" sorts after "a=1"
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	items := make([]string, 0, len(pairs))
+	for _, pair := range pairs {
+		items = append(items, pair[0]+"="+pair[1])
+	}
+	return strings.Join(items, "&")
+}
+
+// canonicalHeaderValue trims the value and collapses the sequential spaces.
+func canonicalHeaderValue(v string) string {
+	return strings.Join(strings.Fields(v), " ")
+}
+
+func canonicalHeaders(req *http.Request) (string, string) {
+	connectionHeaders := map[string]struct{}{}
+	for _, v := range req.Header["Connection"] {
+		for _, token := range strings.Split(v, ",") {
+			if token = textproto.TrimString(token); token != "" {
+				connectionHeaders[strings.ToLower(token)] = struct{}{}
+			}
+		}
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string][]string{"host": {host}}
+	names := []string{"host"}
+	for key, vs := range req.Header {
+		name := strings.ToLower(key)
+		if _, ok := unsignedHeaders[name]; ok {
+			continue
+		}
+		if _, ok := connectionHeaders[name]; ok {
+			continue
+		}
+		if name == "host" {
+			continue
+		}
+		if _, ok := values[name]; !ok {
+			names = append(names, name)
+		}
+		for _, v := range vs {
+			values[name] = append(values[name], canonicalHeaderValue(v))
+		}
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(values[name], ","))
+		b.WriteByte('\n')
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// sign signs the request with the credentials, the payload hash is the hex encoded sha256 of the body or UNSIGNED-PAYLOAD.
+func (s *signer) sign(req *http.Request, creds *Credentials, payload string, now time.Time) {
+	amzDate := now.UTC().Format(_timeFormat)
+	date := amzDate[:8]
+	req.Header.Del("Authorization")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	} else {
+		req.Header.Del("X-Amz-Security-Token")
+	}
+	if s.service == "s3" || payload == _unsignedPayload {
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+	}
+	headers, signedHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(req.URL.RawQuery),
+		headers,
+		signedHeaders,
+		payload,
+	}, "\n")
+	scope := strings.Join([]string{date, s.region, s.service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{_algorithm, amzDate, scope, hashHex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", _algorithm+" Credential="+creds.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
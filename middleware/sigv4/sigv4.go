@@ -0,0 +1,72 @@
+package sigv4
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/sigv4/v1"
+)
+
+func init() {
+	middleware.Register("sigv4", Middleware, middleware.Shareable())
+}
+
+// Middleware signs the upstream request with AWS Signature Version 4, the request is signed once the
+// upstream node is selected so the rewrites of the other middlewares and the client are covered.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.SigV4{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	if options.Region == "" || options.Service == "" {
+		return nil, errors.New("sigv4: region and service are required")
+	}
+	s := &signer{
+		region:                 options.Region,
+		service:                options.Service,
+		disableURIPathEscaping: options.DisableUriPathEscaping,
+	}
+	provider := defaultProvider()
+	if static := options.StaticCredentials; static != nil {
+		provider = &staticProvider{creds: &Credentials{
+			AccessKeyID:     static.AccessKeyId,
+			SecretAccessKey: static.SecretAccessKey,
+			SessionToken:    static.SessionToken,
+		}}
+	}
+	provider = &cachedProvider{provider: provider}
+	sign := func(req *http.Request) error {
+		creds, err := provider.Retrieve(req.Context())
+		if err != nil {
+			return err
+		}
+		payload := _unsignedPayload
+		if e, ok := middleware.EndpointFromContext(req.Context()); !options.UnsignedPayload && !(ok && e.Stream) {
+			if payload, err = payloadHash(req); err != nil {
+				return err
+			}
+		}
+		s.sign(req, creds, payload, time.Now())
+		return nil
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if _, ok := middleware.FromRequestContext(req.Context()); !ok {
+				if err := sign(req); err != nil {
+					return nil, err
+				}
+				return next.RoundTrip(req)
+			}
+			middleware.WithUpstreamRequestHook(req.Context(), sign)
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
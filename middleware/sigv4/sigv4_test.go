@@ -0,0 +1,205 @@
+package sigv4
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/sigv4/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// the test vectors of https://docs.aws.amazon.com/general/latest/gr/signature-v4-test-suite.html
+var testCredentials = &Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+func TestSignVectors(t *testing.T) {
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		service   string
+		method    string
+		url       string
+		header    map[string]string
+		body      string
+		signature string
+		signed    string
+	}{
+		{
+			name:      "get-vanilla",
+			service:   "service",
+			method:    http.MethodGet,
+			url:       "https://example.amazonaws.com/",
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+			signed:    "host;x-amz-date",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			service:   "service",
+			method:    http.MethodGet,
+			url:       "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+			signed:    "host;x-amz-date",
+		},
+		{
+			// the keys sharing a prefix are sorted by the key, computed by the algorithm of the test suite as
+			// it has no such vector, the same computation reproduces get-vanilla-query-order-key-case
+			name:      "get-query-order-key-prefix",
+			service:   "service",
+			method:    http.MethodGet,
+			url:       "https://example.amazonaws.com/?a-b=2&a=1",
+			signature: "321dff75bd2a219c1b95fc5dbc497343614dbe8f73319c9d9c415bca43078ce2",
+			signed:    "host;x-amz-date",
+		},
+		{
+			name:      "post-vanilla",
+			service:   "service",
+			method:    http.MethodPost,
+			url:       "https://example.amazonaws.com/",
+			signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+			signed:    "host;x-amz-date",
+		},
+		{
+			name:      "iam-list-users",
+			service:   "iam",
+			method:    http.MethodGet,
+			url:       "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			header:    map[string]string{"Content-Type": "application/x-www-form-urlencoded; charset=utf-8"},
+			signature: "5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+			signed:    "content-type;host;x-amz-date",
+		},
+	}
+	for _, tc := range testCases {
+		req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		// hop-by-hop and volatile headers are never signed
+		req.Header.Set("Connection", "keep-alive, X-Hop")
+		req.Header.Set("X-Hop", "1")
+		req.Header.Set("User-Agent", "goddess")
+		payload, err := payloadHash(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := &signer{region: "us-east-1", service: tc.service}
+		s.sign(req, testCredentials, payload, now)
+		want := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/%s/aws4_request, SignedHeaders=%s, Signature=%s",
+			tc.service, tc.signed, tc.signature)
+		if got := req.Header.Get("Authorization"); got != want {
+			t.Fatalf("%s: want %s but got %s", tc.name, want, got)
+		}
+		if b, _ := io.ReadAll(req.Body); string(b) != tc.body {
+			t.Fatalf("%s: want body to be restored", tc.name)
+		}
+	}
+}
+
+func TestCanonicalization(t *testing.T) {
+	if got := canonicalQuery("b=2&a=x%20y&a=1&c"); got != "a=1&a=x%20y&b=2&c=" {
+		t.Fatalf("unexpected canonical query: %s", got)
+	}
+	if got := canonicalQuery("a-b=2&a=1&a=0"); got != "a=0&a=1&a-b=2" {
+		t.Fatalf("unexpected canonical query of the keys sharing a prefix: %s", got)
+	}
+	if got := canonicalHeaderValue("  a   b  c "); got != "a b c" {
+		t.Fatalf("unexpected canonical header value: %q", got)
+	}
+	req := httptest.NewRequest(http.MethodGet, "https://example.amazonaws.com/a%20b/c$", nil)
+	if got := (&signer{}).canonicalURI(req.URL); got != "/a%2520b/c%24" {
+		t.Fatalf("unexpected canonical uri: %s", got)
+	}
+	if got := (&signer{disableURIPathEscaping: true}).canonicalURI(req.URL); got != "/a%20b/c$" {
+		t.Fatalf("unexpected s3 canonical uri: %s", got)
+	}
+}
+
+func TestMiddlewareSignsUpstreamRequest(t *testing.T) {
+	opts, err := anypb.New(&v1.SigV4{
+		Region:            "us-east-1",
+		Service:           "es",
+		StaticCredentials: &v1.StaticCredentials{AccessKeyId: "AKID", SecretAccessKey: "secret", SessionToken: "token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "sigv4", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var signedHost string
+	rt := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// the client selects the node and applies the hooks
+		req.Host = "search.es.amazonaws.com"
+		reqOpt, _ := middleware.FromRequestContext(req.Context())
+		for _, hook := range reqOpt.UpstreamRequestHooks {
+			if err := hook(req); err != nil {
+				return nil, err
+			}
+		}
+		signedHost = req.Host
+		return &http.Response{StatusCode: http.StatusOK, Header: req.Header, Body: http.NoBody}, nil
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/_search", strings.NewReader(`{}`))
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(&config.Endpoint{})))
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signedHost != "search.es.amazonaws.com" || resp.Header.Get("X-Amz-Security-Token") != "token" ||
+		!strings.Contains(resp.Header.Get("Authorization"), "SignedHeaders=host;x-amz-date;x-amz-security-token") {
+		t.Fatalf("unexpected signed request: %v", resp.Header)
+	}
+}
+
+func TestEC2CredentialsRefresh(t *testing.T) {
+	var fetched int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			if r.Method != http.MethodPut {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			w.Write([]byte("imds-token"))
+		case "/latest/meta-data/iam/security-credentials/":
+			w.Write([]byte("role\n"))
+		case "/latest/meta-data/iam/security-credentials/role":
+			if r.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			n := atomic.AddInt32(&fetched, 1)
+			// the first credentials expire within the refresh window
+			expiration := time.Now().Add(time.Minute)
+			if n > 1 {
+				expiration = time.Now().Add(time.Hour)
+			}
+			fmt.Fprintf(w, `{"AccessKeyId":"AKID%d","SecretAccessKey":"secret","Token":"token","Expiration":%q}`, n, expiration.Format(time.RFC3339))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	endpoint := _ec2MetadataEndpoint
+	_ec2MetadataEndpoint = srv.URL
+	defer func() { _ec2MetadataEndpoint = endpoint }()
+
+	p := &cachedProvider{provider: ec2Provider{}}
+	for i, want := range []string{"AKID1", "AKID2", "AKID2"} {
+		creds, err := p.Retrieve(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds.AccessKeyID != want {
+			t.Fatalf("retrieve %d: want %s but got %s", i, want, creds.AccessKeyID)
+		}
+	}
+}
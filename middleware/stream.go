@@ -16,7 +16,15 @@ const (
 	TagResponse = "response"
 )
 
+// metaStreamContext is the request value of the stream context.
+var metaStreamContext = DefineKey[*MetaStreamContext]("meta_stream", Hidden())
+
+// MetaStreamContextKey is the untyped key of the stream context in RequestOptions.Values, the stream context is
+// still set with it for the middlewares reading it directly.
+//
+// Deprecated: use GetMetaStreamContext and InitMetaStreamContext instead.
 type MetaStreamContextKey struct{}
+
 type MetaStreamContext struct {
 	Request    *http.Request
 	Response   *http.Response
@@ -24,7 +32,8 @@ type MetaStreamContext struct {
 	OnFinish   []func(req *http.Request, reply *http.Response)
 	OnChunk    []func(req *http.Request, reply *http.Response, chunk *MetaStreamChunk)
 
-	finishOnce sync.Once
+	finishOnce  sync.Once
+	asyncChunks []*asyncChunkObserver
 }
 
 func (s *MetaStreamContext) DoOnResponse() {
@@ -35,6 +44,10 @@ func (s *MetaStreamContext) DoOnResponse() {
 
 func (s *MetaStreamContext) DoOnFinish() {
 	s.finishOnce.Do(func() {
+		// the callbacks see all the chunks consumed by the async observers
+		for _, o := range s.asyncChunks {
+			o.close()
+		}
 		for _, fn := range s.OnFinish {
 			fn(s.Request, s.Response)
 		}
@@ -42,15 +55,24 @@ func (s *MetaStreamContext) DoOnFinish() {
 }
 
 func InitMetaStreamContext(opts *RequestOptions, value *MetaStreamContext) {
+	metaStreamContext.Set(opts, value)
 	opts.Values.Set(MetaStreamContextKey{}, value)
 }
 
 func GetMetaStreamContext(opts *RequestOptions) (*MetaStreamContext, bool) {
-	value, ok := opts.Values.Get(MetaStreamContextKey{})
+	if v, ok := metaStreamContext.Get(opts); ok {
+		return v, true
+	}
+	// set by the middlewares with the deprecated key
+	if opts == nil || opts.Values == nil {
+		return nil, false
+	}
+	v, ok := opts.Values.Get(MetaStreamContextKey{})
 	if !ok {
 		return nil, false
 	}
-	return value.(*MetaStreamContext), true
+	streamCtx, ok := v.(*MetaStreamContext)
+	return streamCtx, ok
 }
 
 type MetaStreamChunk struct {
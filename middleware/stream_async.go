@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const _defaultChunkQueueSize = 64
+
+var (
+	_metricChunkQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "stream_chunk_queue_depth",
+		Help:      "The chunks queued for the async chunk observers of all the streams",
+	}, []string{"observer"})
+	_metricChunkDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "stream_chunk_dropped_total",
+		Help:      "The total number of the chunks dropped by the async chunk observers with the full queues",
+	}, []string{"observer"})
+	_metricChunkLag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "stream_chunk_lag_seconds",
+		Help:      "The time the chunks wait in the queues of the async chunk observers",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"observer"})
+)
+
+func init() {
+	metrics.Register(_metricChunkQueueDepth, _metricChunkDropped, _metricChunkLag)
+}
+
+// ChunkQueueFullPolicy is the behavior of an async chunk observer once its queue of the stream is full.
+type ChunkQueueFullPolicy int
+
+const (
+	// ChunkQueueBlock blocks the Read or Write of the stream until the chunk is queued, the stream is slowed
+	// down to the consumer.
+	ChunkQueueBlock ChunkQueueFullPolicy = iota
+	// ChunkQueueDrop drops the chunk and counts it, the stream is never slowed down.
+	ChunkQueueDrop
+)
+
+// AsyncChunkOptions is the options of an async chunk observer.
+type AsyncChunkOptions struct {
+	// Name labels the metrics of the observer, eg: the middleware name.
+	Name string
+	// QueueSize is the chunks queued per stream, defaults to 64.
+	QueueSize int
+	Policy    ChunkQueueFullPolicy
+	// OnDrop is called with the dropped chunks in the Read or Write of the stream, eg: to mark the record truncated.
+	OnDrop func(chunk *MetaStreamChunk)
+}
+
+type queuedChunk struct {
+	chunk    *MetaStreamChunk
+	queuedAt time.Time
+}
+
+// asyncChunkObserver consumes the chunks of one stream in order by a worker.
+type asyncChunkObserver struct {
+	opts  AsyncChunkOptions
+	fn    func(req *http.Request, reply *http.Response, chunk *MetaStreamChunk)
+	queue chan queuedChunk
+	done  chan struct{}
+
+	// lock guards the queue against being closed while a chunk is sent
+	lock   sync.RWMutex
+	closed bool
+}
+
+// AddAsyncOnChunk adds the chunk observer running off the Read and Write of the stream, the chunks are queued
+// in order and consumed by a worker of the stream. The queue is drained before the OnFinish callbacks run.
+func (s *MetaStreamContext) AddAsyncOnChunk(opts AsyncChunkOptions, fn func(req *http.Request, reply *http.Response, chunk *MetaStreamChunk)) {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = _defaultChunkQueueSize
+	}
+	o := &asyncChunkObserver{
+		opts:  opts,
+		fn:    fn,
+		queue: make(chan queuedChunk, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	go o.run(s)
+	s.OnChunk = append(s.OnChunk, o.enqueue)
+	s.asyncChunks = append(s.asyncChunks, o)
+}
+
+func (o *asyncChunkObserver) enqueue(_ *http.Request, _ *http.Response, chunk *MetaStreamChunk) {
+	// the chunk is a copy owned by the observers
+	item := queuedChunk{chunk: chunk, queuedAt: time.Now()}
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	if o.closed {
+		o.drop(chunk)
+		return
+	}
+	if o.opts.Policy == ChunkQueueDrop {
+		select {
+		case o.queue <- item:
+		default:
+			o.drop(chunk)
+			return
+		}
+	} else {
+		o.queue <- item
+	}
+	_metricChunkQueueDepth.WithLabelValues(o.opts.Name).Inc()
+}
+
+func (o *asyncChunkObserver) drop(chunk *MetaStreamChunk) {
+	_metricChunkDropped.WithLabelValues(o.opts.Name).Inc()
+	if o.opts.OnDrop != nil {
+		o.opts.OnDrop(chunk)
+	}
+}
+
+func (o *asyncChunkObserver) run(s *MetaStreamContext) {
+	defer close(o.done)
+	depth, lag := _metricChunkQueueDepth.WithLabelValues(o.opts.Name), _metricChunkLag.WithLabelValues(o.opts.Name)
+	for item := range o.queue {
+		depth.Dec()
+		lag.Observe(time.Since(item.queuedAt).Seconds())
+		o.fn(s.Request, s.Response, item.chunk)
+	}
+}
+
+// close stops queueing and waits for the queued chunks to be consumed.
+func (o *asyncChunkObserver) close() {
+	o.lock.Lock()
+	if !o.closed {
+		o.closed = true
+		close(o.queue)
+	}
+	o.lock.Unlock()
+	<-o.done
+}
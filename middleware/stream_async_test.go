@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// echoReadWriteCloser leaves the read buffers untouched so the chunks read are the buffers passed in.
+type echoReadWriteCloser struct{}
+
+func (echoReadWriteCloser) Read(p []byte) (int, error)  { return len(p), nil }
+func (echoReadWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (echoReadWriteCloser) Close() error                { return nil }
+
+func TestAsyncOnChunkOrder(t *testing.T) {
+	const streams, chunks = 8, 200
+	var wg sync.WaitGroup
+	for i := range streams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			streamCtx := &MetaStreamContext{}
+			got := map[string][]int{}
+			streamCtx.AddAsyncOnChunk(AsyncChunkOptions{Name: "test", QueueSize: 1 + i}, func(_ *http.Request, _ *http.Response, chunk *MetaStreamChunk) {
+				n, _ := strconv.Atoi(string(chunk.Data))
+				got[chunk.Tag] = append(got[chunk.Tag], n)
+			})
+			body := WrapReadWriteCloserBody(echoReadWriteCloser{}, streamCtx)
+			// the request and the response are copied concurrently
+			var copies sync.WaitGroup
+			copies.Add(2)
+			go func() {
+				defer copies.Done()
+				for n := range chunks {
+					body.Write([]byte(strconv.Itoa(n)))
+				}
+			}()
+			go func() {
+				defer copies.Done()
+				for n := range chunks {
+					body.Read([]byte(strconv.Itoa(n)))
+				}
+			}()
+			copies.Wait()
+			streamCtx.DoOnFinish()
+			for _, tag := range []string{TagRequest, TagResponse} {
+				if len(got[tag]) != chunks {
+					t.Errorf("stream %d %s: want %d chunks but got %d", i, tag, chunks, len(got[tag]))
+					return
+				}
+				for n, v := range got[tag] {
+					if n != v {
+						t.Errorf("stream %d %s: want chunk %d but got %d", i, tag, n, v)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAsyncOnChunkQueueFull(t *testing.T) {
+	for _, policy := range []ChunkQueueFullPolicy{ChunkQueueDrop, ChunkQueueBlock} {
+		streamCtx := &MetaStreamContext{}
+		release := make(chan struct{})
+		var consumed, dropped int
+		streamCtx.AddAsyncOnChunk(AsyncChunkOptions{
+			Name:      "test",
+			QueueSize: 2,
+			Policy:    policy,
+			OnDrop:    func(*MetaStreamChunk) { dropped++ },
+		}, func(*http.Request, *http.Response, *MetaStreamChunk) {
+			<-release
+			consumed++
+		})
+		body := WrapReadWriteCloserBody(echoReadWriteCloser{}, streamCtx)
+		written := make(chan struct{})
+		go func() {
+			defer close(written)
+			// one is held by the consumer, two are queued
+			for range 5 {
+				body.Write([]byte("x"))
+			}
+		}()
+		select {
+		case <-written:
+			if policy == ChunkQueueBlock {
+				t.Fatal("want the writes blocked by the full queue")
+			}
+		case <-time.After(100 * time.Millisecond):
+			if policy == ChunkQueueDrop {
+				t.Fatal("want the writes not blocked by the full queue")
+			}
+		}
+		close(release)
+		<-written
+		streamCtx.DoOnFinish()
+		if policy == ChunkQueueDrop && (consumed+dropped != 5 || dropped < 2) {
+			t.Fatalf("drop: unexpected consumed %d and dropped %d", consumed, dropped)
+		}
+		if policy == ChunkQueueBlock && (consumed != 5 || dropped != 0) {
+			t.Fatalf("block: unexpected consumed %d and dropped %d", consumed, dropped)
+		}
+	}
+}
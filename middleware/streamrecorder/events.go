@@ -0,0 +1,162 @@
+package streamrecorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+)
+
+const (
+	_defaultMaxEvents = 1000
+	_defaultMaxBytes  = 1 << 20
+)
+
+// Event is a logical record of the response stream, it is a server-sent event for text/event-stream,
+// a line for application/x-ndjson or a raw chunk for the other content types.
+type Event struct {
+	Seq  int
+	Time time.Time
+	// Name and ID are the event and id fields of the server-sent event.
+	Name string
+	ID   string
+	// Data is the joined data fields of the server-sent event, the line or the raw chunk.
+	Data []byte
+	// Invalid reports the line is not a valid JSON value.
+	Invalid bool
+}
+
+type eventFormat int
+
+const (
+	formatRaw eventFormat = iota
+	formatSSE
+	formatNDJSON
+)
+
+func detectEventFormat(reply *http.Response) eventFormat {
+	if reply == nil {
+		return formatRaw
+	}
+	mediaType, _, _ := mime.ParseMediaType(reply.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		return formatSSE
+	case "application/x-ndjson":
+		return formatNDJSON
+	default:
+		return formatRaw
+	}
+}
+
+// eventAssembler reassembles the events from the response chunks, the events beyond the caps are dropped.
+type eventAssembler struct {
+	format    eventFormat
+	maxEvents int
+	maxBytes  int
+
+	buf       []byte
+	bytes     int
+	events    []*Event
+	truncated bool
+}
+
+func (a *eventAssembler) feed(chunk *middleware.MetaStreamChunk) {
+	if a.format == formatRaw {
+		if len(chunk.Data) > 0 {
+			a.emit(&Event{Data: chunk.Data})
+		}
+		return
+	}
+	if !a.truncated {
+		a.buf = append(a.buf, chunk.Data...)
+		a.split()
+		if len(a.buf) > a.maxBytes {
+			// an event never fits the cap
+			a.buf = nil
+			a.truncated = true
+		}
+	}
+	if chunk.Err == io.EOF && len(a.buf) > 0 {
+		// the last record is not terminated
+		a.parse(a.buf)
+		a.buf = nil
+	}
+}
+
+func (a *eventAssembler) split() {
+	sep := []byte("\n")
+	if a.format == formatSSE {
+		a.buf = bytes.ReplaceAll(a.buf, []byte("\r\n"), []byte("\n"))
+		sep = []byte("\n\n")
+	}
+	for {
+		i := bytes.Index(a.buf, sep)
+		if i < 0 {
+			return
+		}
+		record := a.buf[:i]
+		a.buf = a.buf[i+len(sep):]
+		a.parse(record)
+	}
+}
+
+func (a *eventAssembler) parse(record []byte) {
+	switch a.format {
+	case formatSSE:
+		if e, ok := parseSSEEvent(record); ok {
+			a.emit(e)
+		}
+	case formatNDJSON:
+		line := bytes.TrimSpace(record)
+		if len(line) == 0 {
+			return
+		}
+		a.emit(&Event{Data: bytes.Clone(line), Invalid: !json.Valid(line)})
+	}
+}
+
+// parseSSEEvent parses the fields of a server-sent event, comments are ignored.
+func parseSSEEvent(record []byte) (*Event, bool) {
+	e := &Event{}
+	var data [][]byte
+	hasField := false
+	for _, line := range bytes.Split(record, []byte("\n")) {
+		if len(line) == 0 || line[0] == ':' {
+			continue
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			e.Name = string(value)
+		case "id":
+			e.ID = string(value)
+		case "data":
+			data = append(data, value)
+		default:
+			continue
+		}
+		hasField = true
+	}
+	if !hasField {
+		return nil, false
+	}
+	e.Data = bytes.Join(data, []byte("\n"))
+	return e, true
+}
+
+func (a *eventAssembler) emit(e *Event) {
+	if a.truncated || len(a.events) >= a.maxEvents || a.bytes+len(e.Data) > a.maxBytes {
+		a.truncated = true
+		return
+	}
+	e.Seq = len(a.events)
+	e.Time = time.Now()
+	a.bytes += len(e.Data)
+	a.events = append(a.events, e)
+}
@@ -4,35 +4,88 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/aide-family/goddess/middleware"
 	configv1 "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/streamrecorder/v1"
+	"github.com/aide-family/goddess/watchdog"
 )
 
 func init() {
 	middleware.RegisterV2("streamrecorder", New)
 }
 
-func New(*configv1.Middleware) (middleware.MiddlewareV2, error) {
-	return &MetaStreamRecorder{}, nil
+func New(c *configv1.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.StreamRecorder{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	s := NewMetaStreamRecorder()
+	if options.MaxEvents > 0 {
+		s.maxEvents = int(options.MaxEvents)
+	}
+	if options.MaxBytes > 0 {
+		s.maxBytes = int(options.MaxBytes)
+	}
+	if options.Async != nil {
+		s.async = &middleware.AsyncChunkOptions{Name: "streamrecorder", QueueSize: int(options.Async.QueueSize)}
+		if options.Async.DropWhenFull {
+			s.async.Policy = middleware.ChunkQueueDrop
+		}
+	}
+	return s, nil
 }
 
-type MetaStreamRecorder struct{}
+type MetaStreamRecorder struct {
+	maxEvents int
+	maxBytes  int
+	// records the chunks by the worker of the stream if not nil
+	async *middleware.AsyncChunkOptions
+}
 
 var _ middleware.MiddlewareV2 = (*MetaStreamRecorder)(nil)
 
 func NewMetaStreamRecorder() *MetaStreamRecorder {
-	return &MetaStreamRecorder{}
+	return &MetaStreamRecorder{
+		maxEvents: _defaultMaxEvents,
+		maxBytes:  _defaultMaxBytes,
+	}
 }
 
+// _streamRecorder is the request value of the stream recorder.
+var _streamRecorder = middleware.DefineKey[*StreamRecorder]("streamrecorder.recorder", middleware.Hidden())
+
 type (
-	streamRecorderKey struct{}
-	StreamRecorder    struct {
+	StreamRecorder struct {
 		Request  []*middleware.MetaStreamChunk
 		Response []*middleware.MetaStreamChunk
+
+		events *eventAssembler
+		// set by the chunks dropped by the async recording
+		dropped atomic.Bool
 	}
 )
 
+// Events returns the logical records of the response stream reassembled by the content type.
+func (s *StreamRecorder) Events() []*Event {
+	if s.events == nil {
+		return nil
+	}
+	return s.events.events
+}
+
+// Truncated reports the events are dropped by the caps of the recording or the chunks are dropped by the full
+// queue of the async recording.
+func (s *StreamRecorder) Truncated() bool {
+	return s.dropped.Load() || (s.events != nil && s.events.truncated)
+}
+
 func (s *StreamRecorder) Mix() *streamReaderSeeker {
 	mixed := make([]*middleware.MetaStreamChunk, 0, len(s.Request)+len(s.Response))
 	mixed = append(mixed, s.Request...)
@@ -49,15 +102,11 @@ func (s *StreamRecorder) ResponseReader() *streamReaderSeeker {
 }
 
 func InitStreamRecorder(reqOpts *middleware.RequestOptions, recorder *StreamRecorder) {
-	reqOpts.Values.Set(streamRecorderKey{}, recorder)
+	_streamRecorder.Set(reqOpts, recorder)
 }
 
 func GetStreamRecorder(reqOpts *middleware.RequestOptions) (*StreamRecorder, bool) {
-	recorder, ok := reqOpts.Values.Get(streamRecorderKey{})
-	if ok {
-		return recorder.(*StreamRecorder), true
-	}
-	return nil, false
+	return _streamRecorder.Get(reqOpts)
 }
 
 func (s *MetaStreamRecorder) Process(next http.RoundTripper) http.RoundTripper {
@@ -72,20 +121,42 @@ func (s *MetaStreamRecorder) Process(next http.RoundTripper) http.RoundTripper {
 			// not stream request
 			return next.RoundTrip(req)
 		}
+		if watchdog.OptionalDisabled() {
+			// the gateway is degraded, the stream is not recorded
+			return next.RoundTrip(req)
+		}
 
 		recorder := &StreamRecorder{
 			Request:  make([]*middleware.MetaStreamChunk, 0),
 			Response: make([]*middleware.MetaStreamChunk, 0),
 		}
 		InitStreamRecorder(reqOpts, recorder)
-		streamCtx.OnChunk = append(streamCtx.OnChunk, func(req *http.Request, reply *http.Response, chunk *middleware.MetaStreamChunk) {
+		onChunk := func(req *http.Request, reply *http.Response, chunk *middleware.MetaStreamChunk) {
 			switch chunk.Tag {
 			case middleware.TagRequest:
 				recorder.Request = append(recorder.Request, chunk)
 			case middleware.TagResponse:
 				recorder.Response = append(recorder.Response, chunk)
+				if recorder.events == nil {
+					recorder.events = &eventAssembler{
+						format:    detectEventFormat(reply),
+						maxEvents: s.maxEvents,
+						maxBytes:  s.maxBytes,
+					}
+				}
+				recorder.events.feed(chunk)
 			}
-		})
+		}
+		if s.async == nil {
+			streamCtx.OnChunk = append(streamCtx.OnChunk, onChunk)
+			return next.RoundTrip(req)
+		}
+		// the recording is complete once the stream is finished
+		opts := *s.async
+		opts.OnDrop = func(*middleware.MetaStreamChunk) {
+			recorder.dropped.Store(true)
+		}
+		streamCtx.AddAsyncOnChunk(opts, onChunk)
 		return next.RoundTrip(req)
 	})
 }
@@ -0,0 +1,121 @@
+package streamrecorder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func record(t *testing.T, s *MetaStreamRecorder, contentType, body string) *StreamRecorder {
+	reqOpts := middleware.NewRequestOptions(&config.Endpoint{Stream: true})
+	streamCtx := &middleware.MetaStreamContext{}
+	middleware.InitMetaStreamContext(reqOpts, streamCtx)
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			// the chunks are not aligned with the events
+			Body: io.NopCloser(iotest.HalfReader(strings.NewReader(body))),
+		}
+		streamCtx.Request, streamCtx.Response = req, resp
+		resp.Body = middleware.WrapReadCloserBody(resp.Body, middleware.TagResponse, streamCtx)
+		return resp, nil
+	})
+	resp, err := s.Process(next).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 7)
+	for {
+		if _, err := resp.Body.Read(buf); err != nil {
+			break
+		}
+	}
+	resp.Body.Close()
+	streamCtx.DoOnFinish()
+	recorder, ok := GetStreamRecorder(reqOpts)
+	if !ok {
+		t.Fatal("want stream recorder")
+	}
+	return recorder
+}
+
+func TestRecordSSEEvents(t *testing.T) {
+	body := ": keep-alive\n\n" +
+		"event: greeting\nid: 1\ndata: hello\ndata: world\n\n" +
+		"data: {\"n\":2}\r\n\r\n" +
+		"id: 3\ndata: unterminated"
+	recorder := record(t, NewMetaStreamRecorder(), "text/event-stream; charset=utf-8", body)
+	events := recorder.Events()
+	want := []Event{
+		{Seq: 0, Name: "greeting", ID: "1", Data: []byte("hello\nworld")},
+		{Seq: 1, Data: []byte(`{"n":2}`)},
+		{Seq: 2, ID: "3", Data: []byte("unterminated")},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("want %d events but got %d: %+v", len(want), len(events), events)
+	}
+	for i, e := range events {
+		if e.Seq != want[i].Seq || e.Name != want[i].Name || e.ID != want[i].ID || string(e.Data) != string(want[i].Data) || e.Time.IsZero() {
+			t.Fatalf("event %d: want %+v but got %+v", i, want[i], e)
+		}
+	}
+	if recorder.Truncated() {
+		t.Fatal("want not truncated")
+	}
+
+	s := NewMetaStreamRecorder()
+	s.maxEvents = 1
+	recorder = record(t, s, "text/event-stream", body)
+	if len(recorder.Events()) != 1 || !recorder.Truncated() {
+		t.Fatalf("want events capped: %+v", recorder.Events())
+	}
+}
+
+func TestRecordAsync(t *testing.T) {
+	s := NewMetaStreamRecorder()
+	s.async = &middleware.AsyncChunkOptions{Name: "streamrecorder", QueueSize: 1}
+	recorder := record(t, s, "application/x-ndjson", "{\"a\":1}\n{\"b\":2}\n{\"c\":3}\n")
+	events := recorder.Events()
+	if len(events) != 3 || string(events[2].Data) != `{"c":3}` || recorder.Truncated() {
+		t.Fatalf("want all the events recorded before the finish: %+v", events)
+	}
+}
+
+func TestRecordNDJSONEvents(t *testing.T) {
+	recorder := record(t, NewMetaStreamRecorder(), "application/x-ndjson", "{\"a\":1}\n\nnot json\n{\"b\":2}\n")
+	events := recorder.Events()
+	if len(events) != 3 || string(events[0].Data) != `{"a":1}` || events[0].Invalid || !events[1].Invalid || string(events[2].Data) != `{"b":2}` {
+		t.Fatalf("unexpected events: %+v", events)
+	}
+
+	s := NewMetaStreamRecorder()
+	s.maxBytes = 10
+	recorder = record(t, s, "application/x-ndjson", "{\"a\":1}\n{\"b\":2}\n")
+	if len(recorder.Events()) != 1 || !recorder.Truncated() {
+		t.Fatalf("want bytes capped: %+v", recorder.Events())
+	}
+}
+
+func TestRecordRawChunks(t *testing.T) {
+	recorder := record(t, NewMetaStreamRecorder(), "application/octet-stream", "0123456789")
+	var data []byte
+	for i, e := range recorder.Events() {
+		if e.Seq != i {
+			t.Fatalf("want seq %d but got %d", i, e.Seq)
+		}
+		data = append(data, e.Data...)
+	}
+	if len(recorder.Events()) < 2 || string(data) != "0123456789" {
+		t.Fatalf("want raw chunks: %+v", recorder.Events())
+	}
+}
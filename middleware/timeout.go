@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TimeoutSource is the limit which fired when a request timed out.
+type TimeoutSource string
+
+const (
+	// TimeoutSourceRequest is the overall timeout of the request across the attempts.
+	TimeoutSourceRequest TimeoutSource = "request"
+	// TimeoutSourceAttempt is the timeout of an attempt, eg: the per-try timeout of the retry.
+	TimeoutSourceAttempt TimeoutSource = "attempt"
+	// TimeoutSourceResponseHeader is the time the upstream transport waits for the response headers.
+	TimeoutSourceResponseHeader TimeoutSource = "response_header"
+	// TimeoutSourceClientDeadline is the deadline propagated by the trusted caller, eg: grpc-timeout.
+	TimeoutSourceClientDeadline TimeoutSource = "client_deadline"
+)
+
+// TimeoutError is the deadline exceeded error with the source of the deadline, it is set as the cause of
+// the contexts created with the deadlines.
+type TimeoutError struct {
+	Source TimeoutSource
+	Err    error
+}
+
+// NewTimeoutError returns the timeout error of the source, used as the cause of the context deadline.
+func NewTimeoutError(source TimeoutSource) *TimeoutError {
+	return &TimeoutError{Source: source, Err: context.DeadlineExceeded}
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timeout: %v", e.Source, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Is reports the error as context.DeadlineExceeded, so the upstream timeouts are replied 504 like the others.
+func (e *TimeoutError) Is(target error) bool { return target == context.DeadlineExceeded }
+
+// Timeout implements net.Error.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// the error returned by the http transport when ResponseHeaderTimeout expires
+const _responseHeaderTimeoutMessage = "timeout awaiting response headers"
+
+// TimeoutSourceOf returns the source of the timeout error, the cause of the context is used if the error
+// carries none. Empty is returned if the error is not a timeout.
+func TimeoutSourceOf(ctx context.Context, err error) TimeoutSource {
+	if err == nil {
+		return ""
+	}
+	var te *TimeoutError
+	if errors.As(err, &te) {
+		return te.Source
+	}
+	if strings.Contains(err.Error(), _responseHeaderTimeoutMessage) {
+		return TimeoutSourceResponseHeader
+	}
+	if ctx != nil && errors.Is(err, context.DeadlineExceeded) && errors.As(context.Cause(ctx), &te) {
+		return te.Source
+	}
+	return ""
+}
+
+// WithTimeoutSource wraps the timeout error with its source, the other errors are returned as they are.
+func WithTimeoutSource(ctx context.Context, err error) error {
+	var te *TimeoutError
+	if err == nil || errors.As(err, &te) {
+		return err
+	}
+	if source := TimeoutSourceOf(ctx, err); source != "" {
+		return &TimeoutError{Source: source, Err: err}
+	}
+	return err
+}
@@ -13,6 +13,7 @@ import (
 	v1 "github.com/aide-family/goddess/pkg/middleware/tracing/v1"
 	"github.com/go-kratos/kratos/v2"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
@@ -37,7 +38,7 @@ var globaltp = &struct {
 }{}
 
 func init() {
-	middleware.Register("tracing", Middleware)
+	middleware.Register("tracing", Middleware, middleware.Shareable())
 }
 
 // Middleware is a opentelemetry middleware.
@@ -85,6 +86,12 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				if reply != nil {
 					span.SetAttributes(semconv.HTTPStatusCodeKey.Int(reply.StatusCode))
 				}
+				if upstream, ok := middleware.UpstreamFromContext(ctx); ok && upstream.Addr != "" {
+					span.SetAttributes(
+						attribute.String("upstream.addr", upstream.Addr),
+						attribute.Int("upstream.attempts", upstream.Attempts),
+					)
+				}
 				span.End()
 			}()
 			return next.RoundTrip(req.WithContext(ctx))
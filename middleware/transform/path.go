@@ -0,0 +1,127 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// segment is a map key or an array index of the path.
+type segment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+// parsePath parses the JSONPath-ish path, eg: "$.data.items[0].name".
+func parsePath(in string) ([]segment, error) {
+	p := strings.TrimPrefix(strings.TrimPrefix(in, "$"), ".")
+	if p == "" {
+		return nil, fmt.Errorf("invalid path: %q", in)
+	}
+	var out []segment
+	for _, part := range strings.Split(p, ".") {
+		key := part
+		var indexes []int
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			key = part[:i]
+			rest := part[i:]
+			for rest != "" {
+				end := strings.IndexByte(rest, ']')
+				if rest[0] != '[' || end < 0 {
+					return nil, fmt.Errorf("invalid path: %q", in)
+				}
+				idx, err := strconv.Atoi(rest[1:end])
+				if err != nil || idx < 0 {
+					return nil, fmt.Errorf("invalid index of path: %q", in)
+				}
+				indexes = append(indexes, idx)
+				rest = rest[end+1:]
+			}
+		}
+		if key == "" && len(indexes) == 0 {
+			return nil, fmt.Errorf("invalid path: %q", in)
+		}
+		if key != "" {
+			out = append(out, segment{key: key})
+		}
+		for _, idx := range indexes {
+			out = append(out, segment{index: idx, isIdx: true})
+		}
+	}
+	return out, nil
+}
+
+func getPath(doc interface{}, path []segment) (interface{}, bool) {
+	cur := doc
+	for _, seg := range path {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			if seg.isIdx {
+				return nil, false
+			}
+			next, ok := v[seg.key]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []interface{}:
+			if !seg.isIdx || seg.index >= len(v) {
+				return nil, false
+			}
+			cur = v[seg.index]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// setPath sets the value and creates the missing objects, it returns the new document.
+func setPath(doc interface{}, path []segment, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	seg := path[0]
+	if seg.isIdx {
+		arr, ok := doc.([]interface{})
+		if !ok || seg.index >= len(arr) {
+			return nil, fmt.Errorf("index %d out of range", seg.index)
+		}
+		next, err := setPath(arr[seg.index], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		arr[seg.index] = next
+		return arr, nil
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("field %q is not in an object", seg.key)
+	}
+	next, err := setPath(obj[seg.key], path[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	obj[seg.key] = next
+	return obj, nil
+}
+
+// removePath removes the field, it is a no-op if the field does not exist.
+func removePath(doc interface{}, path []segment) error {
+	last := path[len(path)-1]
+	if last.isIdx {
+		return fmt.Errorf("cannot remove array element %d", last.index)
+	}
+	parent, ok := getPath(doc, path[:len(path)-1])
+	if !ok {
+		return nil
+	}
+	if obj, ok := parent.(map[string]interface{}); ok {
+		delete(obj, last.key)
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+// Package transform is a middleware that transforms the JSON bodies of requests and responses.
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/transform/v1"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const _defaultMaxBodyBytes = 1 << 20
+
+func init() {
+	middleware.Register("transform", Middleware, middleware.Shareable())
+}
+
+// source provides the values of the operations.
+type source struct {
+	req    *http.Request
+	header http.Header
+}
+
+type operation struct {
+	name    string
+	apply   func(doc interface{}, src *source) (interface{}, error)
+	onError v1.OnError
+}
+
+type phase struct {
+	operations      []*operation
+	template        *template.Template
+	templateOnError v1.OnError
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(in interface{}) (string, error) {
+		b, err := json.Marshal(in)
+		return string(b), err
+	},
+}
+
+func newValue(in *v1.Value) (func(doc interface{}, src *source) (interface{}, error), error) {
+	switch v := in.GetSource().(type) {
+	case *v1.Value_Literal:
+		literal := v.Literal.AsInterface()
+		return func(interface{}, *source) (interface{}, error) { return literal, nil }, nil
+	case *v1.Value_Field:
+		path, err := parsePath(v.Field)
+		if err != nil {
+			return nil, err
+		}
+		return func(doc interface{}, _ *source) (interface{}, error) {
+			value, ok := getPath(doc, path)
+			if !ok {
+				return nil, fmt.Errorf("field %q not found", v.Field)
+			}
+			return value, nil
+		}, nil
+	case *v1.Value_Header:
+		return func(_ interface{}, src *source) (interface{}, error) {
+			values := src.header.Values(v.Header)
+			if len(values) == 0 {
+				return nil, fmt.Errorf("header %q not found", v.Header)
+			}
+			return values[0], nil
+		}, nil
+	case *v1.Value_PathParam:
+		return func(_ interface{}, src *source) (interface{}, error) {
+			value, ok := mux.Vars(src.req)[v.PathParam]
+			if !ok {
+				return nil, fmt.Errorf("path param %q not found", v.PathParam)
+			}
+			return value, nil
+		}, nil
+	case *v1.Value_Query:
+		return func(_ interface{}, src *source) (interface{}, error) {
+			query := src.req.URL.Query()
+			if !query.Has(v.Query) {
+				return nil, fmt.Errorf("query %q not found", v.Query)
+			}
+			return query.Get(v.Query), nil
+		}, nil
+	default:
+		return nil, errors.New("value source is required")
+	}
+}
+
+func newOperation(in *v1.Operation) (*operation, error) {
+	op := &operation{onError: in.OnError}
+	switch o := in.GetOp().(type) {
+	case *v1.Operation_Set_:
+		path, err := parsePath(o.Set.Path)
+		if err != nil {
+			return nil, err
+		}
+		value, err := newValue(o.Set.Value)
+		if err != nil {
+			return nil, err
+		}
+		op.name = "set " + o.Set.Path
+		op.apply = func(doc interface{}, src *source) (interface{}, error) {
+			v, err := value(doc, src)
+			if err != nil {
+				return nil, err
+			}
+			return setPath(doc, path, v)
+		}
+	case *v1.Operation_Rename_:
+		from, err := parsePath(o.Rename.From)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parsePath(o.Rename.To)
+		if err != nil {
+			return nil, err
+		}
+		op.name = "rename " + o.Rename.From
+		op.apply = func(doc interface{}, _ *source) (interface{}, error) {
+			v, ok := getPath(doc, from)
+			if !ok {
+				return nil, fmt.Errorf("field %q not found", o.Rename.From)
+			}
+			if err := removePath(doc, from); err != nil {
+				return nil, err
+			}
+			return setPath(doc, to, v)
+		}
+	case *v1.Operation_Remove_:
+		path, err := parsePath(o.Remove.Path)
+		if err != nil {
+			return nil, err
+		}
+		op.name = "remove " + o.Remove.Path
+		op.apply = func(doc interface{}, _ *source) (interface{}, error) {
+			return doc, removePath(doc, path)
+		}
+	default:
+		return nil, errors.New("operation is required")
+	}
+	return op, nil
+}
+
+func newPhase(in *v1.Phase) (*phase, error) {
+	if in == nil {
+		return nil, nil
+	}
+	p := &phase{}
+	for _, o := range in.Operations {
+		op, err := newOperation(o)
+		if err != nil {
+			return nil, err
+		}
+		p.operations = append(p.operations, op)
+	}
+	if in.Template != nil && in.Template.Text != "" {
+		tmpl, err := template.New("transform").Funcs(templateFuncs).Parse(in.Template.Text)
+		if err != nil {
+			return nil, err
+		}
+		p.template = tmpl
+		p.templateOnError = in.Template.OnError
+	}
+	if len(p.operations) == 0 && p.template == nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// transform returns the transformed body, the original body is returned if a pass-through operation fails.
+func (p *phase) transform(body []byte, src *source) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		log.Warnf("Skip transforming invalid JSON body: %+v", err)
+		return body, nil
+	}
+	for _, op := range p.operations {
+		next, err := op.apply(doc, src)
+		if err != nil {
+			return onError(body, op.onError, fmt.Errorf("%s: %w", op.name, err))
+		}
+		doc = next
+	}
+	if p.template == nil {
+		return json.Marshal(doc)
+	}
+	buf := &bytes.Buffer{}
+	data := map[string]interface{}{
+		"Body":   doc,
+		"Header": src.header,
+		"Params": mux.Vars(src.req),
+		"Query":  src.req.URL.Query(),
+	}
+	if err := p.template.Execute(buf, data); err != nil {
+		return onError(body, p.templateOnError, err)
+	}
+	return buf.Bytes(), nil
+}
+
+func onError(body []byte, policy v1.OnError, err error) ([]byte, error) {
+	if policy == v1.OnError_BAD_GATEWAY {
+		return nil, err
+	}
+	log.Warnf("Failed to transform body, pass through: %+v", err)
+	return body, nil
+}
+
+func isJSON(header http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// readBody reads the body up to max bytes, the returned body must be used instead if the body is too large.
+func readBody(in io.ReadCloser, max int64) ([]byte, io.ReadCloser, error) {
+	data, err := io.ReadAll(io.LimitReader(in, max+1))
+	if err != nil {
+		in.Close()
+		return nil, nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, &readCloser{Reader: io.MultiReader(bytes.NewReader(data), in), Closer: in}, nil
+	}
+	in.Close()
+	return data, nil, nil
+}
+
+func newBadGatewayResponse(err error) *http.Response {
+	body, _ := json.Marshal(kerrors.New(http.StatusBadGateway, "TRANSFORM_FAILED", err.Error()))
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode:    http.StatusBadGateway,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+// Middleware is a JSON body transformer.
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Transform{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	requestPhase, err := newPhase(options.Request)
+	if err != nil {
+		return nil, err
+	}
+	responsePhase, err := newPhase(options.Response)
+	if err != nil {
+		return nil, err
+	}
+	maxBodyBytes := options.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = _defaultMaxBodyBytes
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if endpoint, ok := middleware.EndpointFromContext(req.Context()); ok && endpoint.Stream {
+				return next.RoundTrip(req)
+			}
+			if requestPhase != nil && req.Body != nil && req.Body != http.NoBody && isJSON(req.Header) {
+				data, body, err := readBody(req.Body, maxBodyBytes)
+				if err != nil {
+					return nil, err
+				}
+				if body != nil {
+					req.Body = body
+				} else {
+					out, err := requestPhase.transform(data, &source{req: req, header: req.Header})
+					if err != nil {
+						return newBadGatewayResponse(err), nil
+					}
+					req.Body = io.NopCloser(bytes.NewReader(out))
+					req.ContentLength = int64(len(out))
+					req.Header.Set("Content-Length", strconv.Itoa(len(out)))
+				}
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if responsePhase == nil || resp.Body == nil || !isJSON(resp.Header) ||
+				(resp.Header.Get("Content-Encoding") != "" && resp.Header.Get("Content-Encoding") != "identity") {
+				return resp, nil
+			}
+			data, body, err := readBody(resp.Body, maxBodyBytes)
+			if err != nil {
+				return nil, err
+			}
+			if body != nil {
+				resp.Body = body
+				return resp, nil
+			}
+			out, err := responsePhase.transform(data, &source{req: req, header: resp.Header})
+			if err != nil {
+				return newBadGatewayResponse(err), nil
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(out))
+			resp.ContentLength = int64(len(out))
+			if resp.Header.Get("Content-Length") != "" {
+				resp.Header.Set("Content-Length", strconv.Itoa(len(out)))
+			}
+			return resp, nil
+		})
+	}, nil
+}
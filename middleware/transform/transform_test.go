@@ -0,0 +1,123 @@
+package transform
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	v1 "github.com/aide-family/goddess/pkg/middleware/transform/v1"
+	"github.com/gorilla/mux"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Transform) middleware.Middleware {
+	t.Helper()
+	opts, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "transform", Options: opts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}, "Content-Length": []string{"0"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func TestTransform(t *testing.T) {
+	m := newMiddleware(t, &v1.Transform{
+		Request: &v1.Phase{Operations: []*v1.Operation{
+			{Op: &v1.Operation_Rename_{Rename: &v1.Operation_Rename{From: "user_name", To: "$.user.name"}}},
+			{Op: &v1.Operation_Set_{Set: &v1.Operation_Set{Path: "user.id", Value: &v1.Value{Source: &v1.Value_PathParam{PathParam: "id"}}}}},
+			{Op: &v1.Operation_Set_{Set: &v1.Operation_Set{Path: "trace", Value: &v1.Value{Source: &v1.Value_Header{Header: "X-Trace"}}}}},
+			{Op: &v1.Operation_Set_{Set: &v1.Operation_Set{Path: "version", Value: &v1.Value{Source: &v1.Value_Literal{Literal: structpb.NewNumberValue(2)}}}}},
+			{Op: &v1.Operation_Remove_{Remove: &v1.Operation_Remove{Path: "password"}}},
+		}},
+		Response: &v1.Phase{
+			Operations: []*v1.Operation{
+				{Op: &v1.Operation_Remove_{Remove: &v1.Operation_Remove{Path: "items[0].secret"}}},
+			},
+			Template: &v1.Template{Text: `{"data":{{ json .Body }}}`},
+		},
+	})
+	var upstream string
+	rt := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		upstream = string(b)
+		if req.ContentLength != int64(len(b)) {
+			t.Fatalf("want content length %d but got %d", len(b), req.ContentLength)
+		}
+		return jsonResponse(`{"items":[{"id":1,"secret":"x"}]}`), nil
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/users/42", bytes.NewBufferString(`{"user_name":"goddess","password":"p"}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("X-Trace", "abc")
+	req = mux.SetURLVars(req, map[string]string{"id": "42"})
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"trace":"abc","user":{"id":"42","name":"goddess"},"version":2}`; upstream != want {
+		t.Fatalf("want request %s but got %s", want, upstream)
+	}
+	b, _ := io.ReadAll(resp.Body)
+	if want := `{"data":{"items":[{"id":1}]}}`; string(b) != want {
+		t.Fatalf("want response %s but got %s", want, b)
+	}
+	if resp.Header.Get("Content-Length") != "29" {
+		t.Fatalf("want fixed content length but got %s", resp.Header.Get("Content-Length"))
+	}
+}
+
+func TestTransformOnError(t *testing.T) {
+	rename := func(onError v1.OnError) *v1.Transform {
+		return &v1.Transform{
+			MaxBodyBytes: 64,
+			Response: &v1.Phase{Operations: []*v1.Operation{{
+				Op:      &v1.Operation_Rename_{Rename: &v1.Operation_Rename{From: "missing", To: "name"}},
+				OnError: onError,
+			}}},
+		}
+	}
+	testCases := []struct {
+		name     string
+		options  *v1.Transform
+		body     string
+		header   string
+		wantCode int
+		wantBody string
+	}{
+		{"pass through", rename(v1.OnError_PASS_THROUGH), `{"a":1}`, "application/json", http.StatusOK, `{"a":1}`},
+		{"bad gateway", rename(v1.OnError_BAD_GATEWAY), `{"a":1}`, "application/json", http.StatusBadGateway, ""},
+		{"non json", rename(v1.OnError_BAD_GATEWAY), `a=1`, "text/plain", http.StatusOK, `a=1`},
+		{"too large", rename(v1.OnError_BAD_GATEWAY), `{"a":"` + string(bytes.Repeat([]byte("x"), 100)) + `"}`, "application/json", http.StatusOK, `{"a":"` + string(bytes.Repeat([]byte("x"), 100)) + `"}`},
+	}
+	for _, tc := range testCases {
+		m := newMiddleware(t, tc.options)
+		rt := m(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp := jsonResponse(tc.body)
+			resp.Header.Set("Content-Type", tc.header)
+			return resp, nil
+		}))
+		resp, err := rt.RoundTrip(httptest.NewRequest(http.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != tc.wantCode || (tc.wantBody != "" && string(b) != tc.wantBody) {
+			t.Fatalf("%s: unexpected response %d %s", tc.name, resp.StatusCode, b)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sync"
+)
+
+// Key is a typed key of the value shared between the middlewares through RequestOptions.Values. The keys are
+// compared by identity, the values of two keys never collide even if the names are the same.
+type Key[T any] struct {
+	name string
+	keyOptions
+}
+
+// KeyOption is the option of a key.
+type KeyOption func(*keyOptions)
+
+type keyOptions struct {
+	redacted bool
+	hidden   bool
+}
+
+// Redacted logs the value of the key as [redacted], eg: tokens or claims.
+func Redacted() KeyOption {
+	return func(o *keyOptions) {
+		o.redacted = true
+	}
+}
+
+// Hidden never logs the value of the key, eg: the values private to a middleware.
+func Hidden() KeyOption {
+	return func(o *keyOptions) {
+		o.hidden = true
+	}
+}
+
+type exportedKey interface {
+	export(RequestValues) (string, bool)
+}
+
+var _keys = struct {
+	lock   sync.RWMutex
+	byName map[string]exportedKey
+}{byName: map[string]exportedKey{}}
+
+// DefineKey defines a key of the request values, it panics if the name is defined already. The name is the
+// field of the value in the access log and the slow requests, third-party middlewares should prefix the name
+// with the middleware name, eg: "mycompany.tenant".
+func DefineKey[T any](name string, opts ...KeyOption) *Key[T] {
+	k := &Key[T]{name: name}
+	for _, o := range opts {
+		o(&k.keyOptions)
+	}
+	_keys.lock.Lock()
+	defer _keys.lock.Unlock()
+	if _, ok := _keys.byName[name]; ok {
+		panic(fmt.Sprintf("request value key %q is already defined", name))
+	}
+	_keys.byName[name] = k
+	return k
+}
+
+// Name returns the name of the key.
+func (k *Key[T]) Name() string { return k.name }
+
+// Get returns the value of the key from the request options.
+func (k *Key[T]) Get(o *RequestOptions) (T, bool) {
+	var zero T
+	if o == nil || o.Values == nil {
+		return zero, false
+	}
+	v, ok := o.Values.Get(k)
+	if !ok {
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
+// Set sets the value of the key into the request options.
+func (k *Key[T]) Set(o *RequestOptions, v T) {
+	o.Values.Set(k, v)
+}
+
+// FromContext returns the value of the key from the request options of the context.
+func (k *Key[T]) FromContext(ctx context.Context) (T, bool) {
+	o, _ := FromRequestContext(ctx)
+	return k.Get(o)
+}
+
+func (k *Key[T]) export(values RequestValues) (string, bool) {
+	if k.hidden {
+		return "", false
+	}
+	v, ok := values.Get(k)
+	if !ok {
+		return "", false
+	}
+	if k.redacted {
+		return "[redacted]", true
+	}
+	s := fmt.Sprint(v)
+	return s, s != ""
+}
+
+// Claims is the verified claims of the token of the request.
+type Claims map[string]any
+
+// ClientCertIdentity is the identity of the verified client certificate of the mTLS connection.
+type ClientCertIdentity struct {
+	CommonName string
+	DNSNames   []string
+	URIs       []string
+	Emails     []string
+	// SPIFFEID is the URI SAN of the spiffe scheme, eg: spiffe://prod.example.com/ns/default/sa/api
+	SPIFFEID    string
+	Certificate *x509.Certificate
+}
+
+// String returns the SPIFFE ID, or the common name if there is no SPIFFE ID.
+func (c *ClientCertIdentity) String() string {
+	if c == nil {
+		return ""
+	}
+	if c.SPIFFEID != "" {
+		return c.SPIFFEID
+	}
+	return c.CommonName
+}
+
+// The well-known keys of the request values, the owner sets the value and the others only read it.
+var (
+	// RequestID is the id of the request, set by the proxy from the X-Request-Id header.
+	RequestID = DefineKey[string]("request_id")
+	// ConsumerID is the authenticated consumer of the request, set by the jwt middleware, used by the quota
+	// middleware if no consumer is configured.
+	ConsumerID = DefineKey[string]("consumer_id")
+	// Namespace is the validated namespace of the request, set by the namespace middleware.
+	Namespace = DefineKey[string]("namespace")
+	// ClaimsValue is the verified claims of the token, set by the jwt middleware.
+	ClaimsValue = DefineKey[Claims]("claims", Redacted())
+	// UpstreamAddr is the upstream address of the current attempt, set by the proxy client.
+	UpstreamAddr = DefineKey[string]("upstream_addr")
+	// Attempt is the current attempt of the request starting from 0, set by the proxy.
+	Attempt = DefineKey[int]("attempt")
+	// ResponseSource is the middleware replying the current attempt instead of the upstream, set by the
+	// middleware with MarkResponseSource, reset by the proxy before every attempt.
+	ResponseSource = DefineKey[string]("response_source")
+	// BackendGroup is the backend group of the current attempt of the endpoint with failover, set by the proxy client.
+	BackendGroup = DefineKey[string]("backend_group")
+	// ClientCert is the identity of the verified client certificate, set by the proxy on the mTLS connections.
+	ClientCert = DefineKey[*ClientCertIdentity]("client_cert")
+)
+
+// ExportValues returns the values of the request options to log, the redacted values are masked and the hidden
+// ones are skipped.
+func ExportValues(o *RequestOptions) map[string]string {
+	if o == nil || o.Values == nil {
+		return nil
+	}
+	_keys.lock.RLock()
+	defer _keys.lock.RUnlock()
+	var out map[string]string
+	for name, k := range _keys.byName {
+		v, ok := k.export(o.Values)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]string)
+		}
+		out[name] = v
+	}
+	return out
+}
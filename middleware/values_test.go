@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"testing"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestRequestValues(t *testing.T) {
+	opts := NewRequestOptions(&config.Endpoint{})
+	private := DefineKey[string]("test.private", Hidden())
+	// the keys of the same type never collide
+	other := &Key[string]{name: "consumer_id"}
+	ConsumerID.Set(opts, "alice")
+	other.Set(opts, "bob")
+	private.Set(opts, "secret")
+	ClaimsValue.Set(opts, Claims{"sub": "alice"})
+	Attempt.Set(opts, 1)
+
+	if v, ok := ConsumerID.Get(opts); !ok || v != "alice" {
+		t.Fatalf("want the consumer alice but got %q", v)
+	}
+	if _, ok := Namespace.Get(opts); ok {
+		t.Fatal("want the namespace not set")
+	}
+	want := map[string]string{"consumer_id": "alice", "claims": "[redacted]", "attempt": "1"}
+	got := ExportValues(opts)
+	if len(got) != len(want) {
+		t.Fatalf("want the values %v but got %v", want, got)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("want the values %v but got %v", want, got)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want the duplicate key rejected")
+		}
+	}()
+	DefineKey[int]("test.private")
+}
+
+func TestMetaStreamContextKey(t *testing.T) {
+	opts := NewRequestOptions(&config.Endpoint{})
+	streamCtx := &MetaStreamContext{}
+	InitMetaStreamContext(opts, streamCtx)
+	if v, ok := opts.Values.Get(MetaStreamContextKey{}); !ok || v != streamCtx {
+		t.Fatal("want the stream context set with the deprecated key")
+	}
+	if len(ExportValues(opts)) != 0 {
+		t.Fatalf("want the stream context not exported but got %v", ExportValues(opts))
+	}
+	// the stream context set with the deprecated key is read as well
+	opts = NewRequestOptions(&config.Endpoint{})
+	opts.Values.Set(MetaStreamContextKey{}, streamCtx)
+	if v, ok := GetMetaStreamContext(opts); !ok || v != streamCtx {
+		t.Fatal("want the stream context of the deprecated key")
+	}
+}
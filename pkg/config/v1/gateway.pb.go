@@ -12,6 +12,7 @@ import (
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	anypb "google.golang.org/protobuf/types/known/anypb"
 	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -23,6 +24,109 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type UpstreamProtocol int32
+
+const (
+	// HTTP/1.1 for plaintext and ALPN negotiation for TLS, HTTP/2 for gRPC endpoints.
+	UpstreamProtocol_AUTO  UpstreamProtocol = 0
+	UpstreamProtocol_HTTP1 UpstreamProtocol = 1
+	// HTTP/2 negotiated by TLS ALPN, the backends must be TLS.
+	UpstreamProtocol_HTTP2 UpstreamProtocol = 2
+	// HTTP/2 with prior knowledge over cleartext, the backends must not be TLS.
+	UpstreamProtocol_H2C UpstreamProtocol = 3
+)
+
+// Enum value maps for UpstreamProtocol.
+var (
+	UpstreamProtocol_name = map[int32]string{
+		0: "AUTO",
+		1: "HTTP1",
+		2: "HTTP2",
+		3: "H2C",
+	}
+	UpstreamProtocol_value = map[string]int32{
+		"AUTO":  0,
+		"HTTP1": 1,
+		"HTTP2": 2,
+		"H2C":   3,
+	}
+)
+
+func (x UpstreamProtocol) Enum() *UpstreamProtocol {
+	p := new(UpstreamProtocol)
+	*p = x
+	return p
+}
+
+func (x UpstreamProtocol) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UpstreamProtocol) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[0].Descriptor()
+}
+
+func (UpstreamProtocol) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[0]
+}
+
+func (x UpstreamProtocol) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UpstreamProtocol.Descriptor instead.
+func (UpstreamProtocol) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+}
+
+type EnforcementMode int32
+
+const (
+	// the failed requests are rejected.
+	EnforcementMode_ENFORCE EnforcementMode = 0
+	// the failed requests are recorded as would-block but let through.
+	EnforcementMode_MONITOR EnforcementMode = 1
+)
+
+// Enum value maps for EnforcementMode.
+var (
+	EnforcementMode_name = map[int32]string{
+		0: "ENFORCE",
+		1: "MONITOR",
+	}
+	EnforcementMode_value = map[string]int32{
+		"ENFORCE": 0,
+		"MONITOR": 1,
+	}
+)
+
+func (x EnforcementMode) Enum() *EnforcementMode {
+	p := new(EnforcementMode)
+	*p = x
+	return p
+}
+
+func (x EnforcementMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EnforcementMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[1].Descriptor()
+}
+
+func (EnforcementMode) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[1]
+}
+
+func (x EnforcementMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EnforcementMode.Descriptor instead.
+func (EnforcementMode) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{1}
+}
+
 type Protocol int32
 
 const (
@@ -56,11 +160,11 @@ func (x Protocol) String() string {
 }
 
 func (Protocol) Descriptor() protoreflect.EnumDescriptor {
-	return file_config_v1_gateway_proto_enumTypes[0].Descriptor()
+	return file_config_v1_gateway_proto_enumTypes[2].Descriptor()
 }
 
 func (Protocol) Type() protoreflect.EnumType {
-	return &file_config_v1_gateway_proto_enumTypes[0]
+	return &file_config_v1_gateway_proto_enumTypes[2]
 }
 
 func (x Protocol) Number() protoreflect.EnumNumber {
@@ -69,129 +173,4485 @@ func (x Protocol) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Protocol.Descriptor instead.
 func (Protocol) EnumDescriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{2}
 }
 
-type Gateway struct {
-	state   protoimpl.MessageState `protogen:"open.v1"`
-	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	// Deprecated: Marked as deprecated in config/v1/gateway.proto.
-	Hosts         []string        `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty"`
-	Endpoints     []*Endpoint     `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
-	Middlewares   []*Middleware   `protobuf:"bytes,5,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
-	TlsStore      map[string]*TLS `protobuf:"bytes,6,rep,name=tls_store,json=tlsStore,proto3" json:"tls_store,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Discovery     *v1.Discovery   `protobuf:"bytes,7,opt,name=discovery,proto3" json:"discovery,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type Listener_Mode int32
+
+const (
+	Listener_MODE_UNSPECIFIED Listener_Mode = 0
+	// reads the ClientHello only and splices the connection to the backend selected by the SNI without terminating TLS.
+	Listener_TLS_PASSTHROUGH Listener_Mode = 1
+)
+
+// Enum value maps for Listener_Mode.
+var (
+	Listener_Mode_name = map[int32]string{
+		0: "MODE_UNSPECIFIED",
+		1: "TLS_PASSTHROUGH",
+	}
+	Listener_Mode_value = map[string]int32{
+		"MODE_UNSPECIFIED": 0,
+		"TLS_PASSTHROUGH":  1,
+	}
+)
+
+func (x Listener_Mode) Enum() *Listener_Mode {
+	p := new(Listener_Mode)
+	*p = x
+	return p
 }
 
-func (x *Gateway) Reset() {
-	*x = Gateway{}
-	mi := &file_config_v1_gateway_proto_msgTypes[0]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x Listener_Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
 }
 
-func (x *Gateway) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (Listener_Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[3].Descriptor()
 }
 
-func (*Gateway) ProtoMessage() {}
+func (Listener_Mode) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[3]
+}
 
-func (x *Gateway) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[0]
-	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
-	}
-	return mi.MessageOf(x)
+func (x Listener_Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
 }
 
-// Deprecated: Use Gateway.ProtoReflect.Descriptor instead.
-func (*Gateway) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use Listener_Mode.Descriptor instead.
+func (Listener_Mode) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{4, 0}
 }
 
-func (x *Gateway) GetName() string {
-	if x != nil {
-		return x.Name
+type ForwardedHeaders_Style int32
+
+const (
+	// X-Forwarded-For only
+	ForwardedHeaders_X_FORWARDED ForwardedHeaders_Style = 0
+	// RFC 7239 Forwarded only
+	ForwardedHeaders_FORWARDED ForwardedHeaders_Style = 1
+	ForwardedHeaders_BOTH      ForwardedHeaders_Style = 2
+)
+
+// Enum value maps for ForwardedHeaders_Style.
+var (
+	ForwardedHeaders_Style_name = map[int32]string{
+		0: "X_FORWARDED",
+		1: "FORWARDED",
+		2: "BOTH",
 	}
-	return ""
+	ForwardedHeaders_Style_value = map[string]int32{
+		"X_FORWARDED": 0,
+		"FORWARDED":   1,
+		"BOTH":        2,
+	}
+)
+
+func (x ForwardedHeaders_Style) Enum() *ForwardedHeaders_Style {
+	p := new(ForwardedHeaders_Style)
+	*p = x
+	return p
 }
 
-func (x *Gateway) GetVersion() string {
-	if x != nil {
-		return x.Version
+func (x ForwardedHeaders_Style) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ForwardedHeaders_Style) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[4].Descriptor()
+}
+
+func (ForwardedHeaders_Style) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[4]
+}
+
+func (x ForwardedHeaders_Style) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ForwardedHeaders_Style.Descriptor instead.
+func (ForwardedHeaders_Style) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{14, 0}
+}
+
+type MethodPolicy_BodyAction int32
+
+const (
+	// forwards the body to the upstream.
+	MethodPolicy_FORWARD MethodPolicy_BodyAction = 0
+	// drops the body before the request is routed.
+	MethodPolicy_STRIP MethodPolicy_BodyAction = 1
+	// replies 400.
+	MethodPolicy_REJECT MethodPolicy_BodyAction = 2
+)
+
+// Enum value maps for MethodPolicy_BodyAction.
+var (
+	MethodPolicy_BodyAction_name = map[int32]string{
+		0: "FORWARD",
+		1: "STRIP",
+		2: "REJECT",
 	}
-	return ""
+	MethodPolicy_BodyAction_value = map[string]int32{
+		"FORWARD": 0,
+		"STRIP":   1,
+		"REJECT":  2,
+	}
+)
+
+func (x MethodPolicy_BodyAction) Enum() *MethodPolicy_BodyAction {
+	p := new(MethodPolicy_BodyAction)
+	*p = x
+	return p
 }
 
-// Deprecated: Marked as deprecated in config/v1/gateway.proto.
-func (x *Gateway) GetHosts() []string {
-	if x != nil {
-		return x.Hosts
+func (x MethodPolicy_BodyAction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MethodPolicy_BodyAction) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[5].Descriptor()
+}
+
+func (MethodPolicy_BodyAction) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[5]
+}
+
+func (x MethodPolicy_BodyAction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MethodPolicy_BodyAction.Descriptor instead.
+func (MethodPolicy_BodyAction) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{27, 0}
+}
+
+type HeaderAction_Action int32
+
+const (
+	HeaderAction_DROP HeaderAction_Action = 0
+	// truncates every value of the header to max_bytes.
+	HeaderAction_TRUNCATE HeaderAction_Action = 1
+)
+
+// Enum value maps for HeaderAction_Action.
+var (
+	HeaderAction_Action_name = map[int32]string{
+		0: "DROP",
+		1: "TRUNCATE",
 	}
-	return nil
+	HeaderAction_Action_value = map[string]int32{
+		"DROP":     0,
+		"TRUNCATE": 1,
+	}
+)
+
+func (x HeaderAction_Action) Enum() *HeaderAction_Action {
+	p := new(HeaderAction_Action)
+	*p = x
+	return p
 }
 
-func (x *Gateway) GetEndpoints() []*Endpoint {
-	if x != nil {
-		return x.Endpoints
+func (x HeaderAction_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (HeaderAction_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[6].Descriptor()
+}
+
+func (HeaderAction_Action) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[6]
+}
+
+func (x HeaderAction_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use HeaderAction_Action.Descriptor instead.
+func (HeaderAction_Action) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{34, 0}
+}
+
+type EmptyUpstream_Action int32
+
+const (
+	// the requests are replied 503 immediately without retries.
+	EmptyUpstream_FAIL_FAST EmptyUpstream_Action = 0
+	// the requests wait up to wait_timeout for the instances to appear, eg: during the rolling restarts.
+	EmptyUpstream_WAIT EmptyUpstream_Action = 1
+	// the requests are routed to the fallback backend.
+	EmptyUpstream_FALLBACK EmptyUpstream_Action = 2
+)
+
+// Enum value maps for EmptyUpstream_Action.
+var (
+	EmptyUpstream_Action_name = map[int32]string{
+		0: "FAIL_FAST",
+		1: "WAIT",
+		2: "FALLBACK",
 	}
-	return nil
+	EmptyUpstream_Action_value = map[string]int32{
+		"FAIL_FAST": 0,
+		"WAIT":      1,
+		"FALLBACK":  2,
+	}
+)
+
+func (x EmptyUpstream_Action) Enum() *EmptyUpstream_Action {
+	p := new(EmptyUpstream_Action)
+	*p = x
+	return p
 }
 
-func (x *Gateway) GetMiddlewares() []*Middleware {
-	if x != nil {
-		return x.Middlewares
+func (x EmptyUpstream_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EmptyUpstream_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[7].Descriptor()
+}
+
+func (EmptyUpstream_Action) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[7]
+}
+
+func (x EmptyUpstream_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EmptyUpstream_Action.Descriptor instead.
+func (EmptyUpstream_Action) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{36, 0}
+}
+
+type MissingContentType_Action int32
+
+const (
+	MissingContentType_ALLOW  MissingContentType_Action = 0
+	MissingContentType_REJECT MissingContentType_Action = 1
+	// the default_type is set as the Content-Type of the request.
+	MissingContentType_DEFAULT MissingContentType_Action = 2
+)
+
+// Enum value maps for MissingContentType_Action.
+var (
+	MissingContentType_Action_name = map[int32]string{
+		0: "ALLOW",
+		1: "REJECT",
+		2: "DEFAULT",
 	}
-	return nil
+	MissingContentType_Action_value = map[string]int32{
+		"ALLOW":   0,
+		"REJECT":  1,
+		"DEFAULT": 2,
+	}
+)
+
+func (x MissingContentType_Action) Enum() *MissingContentType_Action {
+	p := new(MissingContentType_Action)
+	*p = x
+	return p
 }
 
-func (x *Gateway) GetTlsStore() map[string]*TLS {
-	if x != nil {
-		return x.TlsStore
+func (x MissingContentType_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MissingContentType_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[8].Descriptor()
+}
+
+func (MissingContentType_Action) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[8]
+}
+
+func (x MissingContentType_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MissingContentType_Action.Descriptor instead.
+func (MissingContentType_Action) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{38, 0}
+}
+
+type StickyCookie_SameSite int32
+
+const (
+	StickyCookie_LAX    StickyCookie_SameSite = 0
+	StickyCookie_STRICT StickyCookie_SameSite = 1
+	StickyCookie_NONE   StickyCookie_SameSite = 2
+)
+
+// Enum value maps for StickyCookie_SameSite.
+var (
+	StickyCookie_SameSite_name = map[int32]string{
+		0: "LAX",
+		1: "STRICT",
+		2: "NONE",
 	}
-	return nil
+	StickyCookie_SameSite_value = map[string]int32{
+		"LAX":    0,
+		"STRICT": 1,
+		"NONE":   2,
+	}
+)
+
+func (x StickyCookie_SameSite) Enum() *StickyCookie_SameSite {
+	p := new(StickyCookie_SameSite)
+	*p = x
+	return p
 }
 
-func (x *Gateway) GetDiscovery() *v1.Discovery {
-	if x != nil {
-		return x.Discovery
+func (x StickyCookie_SameSite) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StickyCookie_SameSite) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[9].Descriptor()
+}
+
+func (StickyCookie_SameSite) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[9]
+}
+
+func (x StickyCookie_SameSite) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StickyCookie_SameSite.Descriptor instead.
+func (StickyCookie_SameSite) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{39, 0}
+}
+
+type NodeFilters_OnNoMatch int32
+
+const (
+	// the request fails with 503
+	NodeFilters_ERROR NodeFilters_OnNoMatch = 0
+	// the filters are ignored and all the nodes are selected
+	NodeFilters_FALLBACK NodeFilters_OnNoMatch = 1
+)
+
+// Enum value maps for NodeFilters_OnNoMatch.
+var (
+	NodeFilters_OnNoMatch_name = map[int32]string{
+		0: "ERROR",
+		1: "FALLBACK",
+	}
+	NodeFilters_OnNoMatch_value = map[string]int32{
+		"ERROR":    0,
+		"FALLBACK": 1,
+	}
+)
+
+func (x NodeFilters_OnNoMatch) Enum() *NodeFilters_OnNoMatch {
+	p := new(NodeFilters_OnNoMatch)
+	*p = x
+	return p
+}
+
+func (x NodeFilters_OnNoMatch) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NodeFilters_OnNoMatch) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[10].Descriptor()
+}
+
+func (NodeFilters_OnNoMatch) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[10]
+}
+
+func (x NodeFilters_OnNoMatch) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NodeFilters_OnNoMatch.Descriptor instead.
+func (NodeFilters_OnNoMatch) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{44, 0}
+}
+
+type Retry_AttemptTimeoutMode int32
+
+const (
+	// every attempt is limited by per_try_timeout
+	Retry_PER_TRY Retry_AttemptTimeoutMode = 0
+	// the remaining overall timeout is divided across the remaining attempts
+	Retry_REMAINING Retry_AttemptTimeoutMode = 1
+	// attempts are only limited by the overall timeout
+	Retry_NONE Retry_AttemptTimeoutMode = 2
+)
+
+// Enum value maps for Retry_AttemptTimeoutMode.
+var (
+	Retry_AttemptTimeoutMode_name = map[int32]string{
+		0: "PER_TRY",
+		1: "REMAINING",
+		2: "NONE",
+	}
+	Retry_AttemptTimeoutMode_value = map[string]int32{
+		"PER_TRY":   0,
+		"REMAINING": 1,
+		"NONE":      2,
+	}
+)
+
+func (x Retry_AttemptTimeoutMode) Enum() *Retry_AttemptTimeoutMode {
+	p := new(Retry_AttemptTimeoutMode)
+	*p = x
+	return p
+}
+
+func (x Retry_AttemptTimeoutMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Retry_AttemptTimeoutMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[11].Descriptor()
+}
+
+func (Retry_AttemptTimeoutMode) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[11]
+}
+
+func (x Retry_AttemptTimeoutMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Retry_AttemptTimeoutMode.Descriptor instead.
+func (Retry_AttemptTimeoutMode) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{46, 0}
+}
+
+type Retry_OnExhaustion int32
+
+const (
+	// the same as LAST_RESPONSE
+	Retry_ON_EXHAUSTION_UNSPECIFIED Retry_OnExhaustion = 0
+	// the most recent upstream response is returned even if it matched a retry condition
+	Retry_LAST_RESPONSE Retry_OnExhaustion = 1
+	// the gateway error (502, or 504 on timeout) is returned
+	Retry_GATEWAY_ERROR Retry_OnExhaustion = 2
+)
+
+// Enum value maps for Retry_OnExhaustion.
+var (
+	Retry_OnExhaustion_name = map[int32]string{
+		0: "ON_EXHAUSTION_UNSPECIFIED",
+		1: "LAST_RESPONSE",
+		2: "GATEWAY_ERROR",
+	}
+	Retry_OnExhaustion_value = map[string]int32{
+		"ON_EXHAUSTION_UNSPECIFIED": 0,
+		"LAST_RESPONSE":             1,
+		"GATEWAY_ERROR":             2,
+	}
+)
+
+func (x Retry_OnExhaustion) Enum() *Retry_OnExhaustion {
+	p := new(Retry_OnExhaustion)
+	*p = x
+	return p
+}
+
+func (x Retry_OnExhaustion) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Retry_OnExhaustion) Descriptor() protoreflect.EnumDescriptor {
+	return file_config_v1_gateway_proto_enumTypes[12].Descriptor()
+}
+
+func (Retry_OnExhaustion) Type() protoreflect.EnumType {
+	return &file_config_v1_gateway_proto_enumTypes[12]
+}
+
+func (x Retry_OnExhaustion) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Retry_OnExhaustion.Descriptor instead.
+func (Retry_OnExhaustion) EnumDescriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{46, 1}
+}
+
+type Gateway struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Name    string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Deprecated: Marked as deprecated in config/v1/gateway.proto.
+	Hosts            []string          `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Endpoints        []*Endpoint       `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	Middlewares      []*Middleware     `protobuf:"bytes,5,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	TlsStore         map[string]*TLS   `protobuf:"bytes,6,rep,name=tls_store,json=tlsStore,proto3" json:"tls_store,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Discovery        *v1.Discovery     `protobuf:"bytes,7,opt,name=discovery,proto3" json:"discovery,omitempty"`
+	ForwardedHeaders *ForwardedHeaders `protobuf:"bytes,8,opt,name=forwarded_headers,json=forwardedHeaders,proto3" json:"forwarded_headers,omitempty"`
+	// fallback handles the requests not matched by any endpoint.
+	Fallback *Fallback `protobuf:"bytes,9,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	// default response header allow/deny lists of the endpoints, eg: ["X-Internal-*", "Server"]
+	ResponseHeaderAllow []string `protobuf:"bytes,10,rep,name=response_header_allow,json=responseHeaderAllow,proto3" json:"response_header_allow,omitempty"`
+	ResponseHeaderDeny  []string `protobuf:"bytes,11,rep,name=response_header_deny,json=responseHeaderDeny,proto3" json:"response_header_deny,omitempty"`
+	// prewarm establishes upstream connections of the changed endpoints before the new router is swapped in.
+	Prewarm *Prewarm `protobuf:"bytes,12,opt,name=prewarm,proto3" json:"prewarm,omitempty"`
+	// host groups are virtual gateways sharing this gateway, each with its own middlewares, fallback and endpoint defaults.
+	HostGroups []*HostGroup `protobuf:"bytes,13,rep,name=host_groups,json=hostGroups,proto3" json:"host_groups,omitempty"`
+	// forwards the remaining time budget to the upstreams, disabled if not set.
+	DeadlinePropagation *DeadlinePropagation `protobuf:"bytes,14,opt,name=deadline_propagation,json=deadlinePropagation,proto3" json:"deadline_propagation,omitempty"`
+	// limits the memory of the request bodies buffered by all the non-stream endpoints, unlimited if not set.
+	BufferBudget *BufferBudget `protobuf:"bytes,15,opt,name=buffer_budget,json=bufferBudget,proto3" json:"buffer_budget,omitempty"`
+	// resolves the upstream hostnames to the addresses instead of the system DNS, eg: {"api.internal": {addresses: ["10.0.0.1"]}}
+	HostOverrides map[string]*HostOverride `protobuf:"bytes,16,rep,name=host_overrides,json=hostOverrides,proto3" json:"host_overrides,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// caches the DNS lookups of the upstream hostnames not overridden, disabled if not set.
+	// deprecated by resolver, `goddess config migrate --to v1.1` moves it to resolver.
+	//
+	// Deprecated: Marked as deprecated in config/v1/gateway.proto.
+	DnsCacheTtl *durationpb.Duration `protobuf:"bytes,17,opt,name=dns_cache_ttl,json=dnsCacheTtl,proto3" json:"dns_cache_ttl,omitempty"`
+	// resolves the upstream hostnames through the nameservers with the cache, overrides dns_cache_ttl.
+	Resolver *Resolver `protobuf:"bytes,18,opt,name=resolver,proto3" json:"resolver,omitempty"`
+	// listeners serving the connections other than the HTTP proxy, eg: the TLS passthrough listeners.
+	Listeners []*Listener `protobuf:"bytes,19,rep,name=listeners,proto3" json:"listeners,omitempty"`
+	// default request header limits of the endpoints, unlimited if not set.
+	HeaderLimits *HeaderLimits `protobuf:"bytes,20,opt,name=header_limits,json=headerLimits,proto3" json:"header_limits,omitempty"`
+	// the upstreams shared by the endpoints referencing them by Endpoint.service.
+	Services []*Service `protobuf:"bytes,21,rep,name=services,proto3" json:"services,omitempty"`
+	// forwards the identity of the verified client certificates of the mTLS listeners to the upstreams.
+	ClientCert *ClientCert `protobuf:"bytes,22,opt,name=client_cert,json=clientCert,proto3" json:"client_cert,omitempty"`
+	// the default forward proxy the upstreams are dialed through, overridden by the endpoints.
+	EgressProxy *EgressProxy `protobuf:"bytes,23,opt,name=egress_proxy,json=egressProxy,proto3" json:"egress_proxy,omitempty"`
+	// the security response headers of the non-gRPC endpoints, overridden by the endpoints, not set if empty.
+	SecurityHeaders *SecurityHeaders `protobuf:"bytes,24,opt,name=security_headers,json=securityHeaders,proto3" json:"security_headers,omitempty"`
+	// the methods routed by the gateway and the handling of the bodies of GET and HEAD requests.
+	MethodPolicy  *MethodPolicy `protobuf:"bytes,25,opt,name=method_policy,json=methodPolicy,proto3" json:"method_policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Gateway) Reset() {
+	*x = Gateway{}
+	mi := &file_config_v1_gateway_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Gateway) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Gateway) ProtoMessage() {}
+
+func (x *Gateway) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Gateway.ProtoReflect.Descriptor instead.
+func (*Gateway) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Gateway) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Gateway) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in config/v1/gateway.proto.
+func (x *Gateway) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *Gateway) GetEndpoints() []*Endpoint {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+func (x *Gateway) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *Gateway) GetTlsStore() map[string]*TLS {
+	if x != nil {
+		return x.TlsStore
+	}
+	return nil
+}
+
+func (x *Gateway) GetDiscovery() *v1.Discovery {
+	if x != nil {
+		return x.Discovery
+	}
+	return nil
+}
+
+func (x *Gateway) GetForwardedHeaders() *ForwardedHeaders {
+	if x != nil {
+		return x.ForwardedHeaders
+	}
+	return nil
+}
+
+func (x *Gateway) GetFallback() *Fallback {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
+func (x *Gateway) GetResponseHeaderAllow() []string {
+	if x != nil {
+		return x.ResponseHeaderAllow
+	}
+	return nil
+}
+
+func (x *Gateway) GetResponseHeaderDeny() []string {
+	if x != nil {
+		return x.ResponseHeaderDeny
+	}
+	return nil
+}
+
+func (x *Gateway) GetPrewarm() *Prewarm {
+	if x != nil {
+		return x.Prewarm
+	}
+	return nil
+}
+
+func (x *Gateway) GetHostGroups() []*HostGroup {
+	if x != nil {
+		return x.HostGroups
+	}
+	return nil
+}
+
+func (x *Gateway) GetDeadlinePropagation() *DeadlinePropagation {
+	if x != nil {
+		return x.DeadlinePropagation
+	}
+	return nil
+}
+
+func (x *Gateway) GetBufferBudget() *BufferBudget {
+	if x != nil {
+		return x.BufferBudget
+	}
+	return nil
+}
+
+func (x *Gateway) GetHostOverrides() map[string]*HostOverride {
+	if x != nil {
+		return x.HostOverrides
+	}
+	return nil
+}
+
+// Deprecated: Marked as deprecated in config/v1/gateway.proto.
+func (x *Gateway) GetDnsCacheTtl() *durationpb.Duration {
+	if x != nil {
+		return x.DnsCacheTtl
+	}
+	return nil
+}
+
+func (x *Gateway) GetResolver() *Resolver {
+	if x != nil {
+		return x.Resolver
+	}
+	return nil
+}
+
+func (x *Gateway) GetListeners() []*Listener {
+	if x != nil {
+		return x.Listeners
+	}
+	return nil
+}
+
+func (x *Gateway) GetHeaderLimits() *HeaderLimits {
+	if x != nil {
+		return x.HeaderLimits
+	}
+	return nil
+}
+
+func (x *Gateway) GetServices() []*Service {
+	if x != nil {
+		return x.Services
+	}
+	return nil
+}
+
+func (x *Gateway) GetClientCert() *ClientCert {
+	if x != nil {
+		return x.ClientCert
+	}
+	return nil
+}
+
+func (x *Gateway) GetEgressProxy() *EgressProxy {
+	if x != nil {
+		return x.EgressProxy
+	}
+	return nil
+}
+
+func (x *Gateway) GetSecurityHeaders() *SecurityHeaders {
+	if x != nil {
+		return x.SecurityHeaders
+	}
+	return nil
+}
+
+func (x *Gateway) GetMethodPolicy() *MethodPolicy {
+	if x != nil {
+		return x.MethodPolicy
+	}
+	return nil
+}
+
+// ClientCert sets the headers of the identity of the verified client certificate, the values of the headers sent
+// by the clients are always removed so they can not be spoofed by the connections without client certificates.
+type ClientCert struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the subject common name, eg: X-Client-Cert-CN, not forwarded if empty.
+	CnHeader string `protobuf:"bytes,1,opt,name=cn_header,json=cnHeader,proto3" json:"cn_header,omitempty"`
+	// the URI SAN of the spiffe scheme, eg: X-Client-Cert-SPIFFE.
+	SpiffeHeader string `protobuf:"bytes,2,opt,name=spiffe_header,json=spiffeHeader,proto3" json:"spiffe_header,omitempty"`
+	// the DNS, URI and email SANs separated by comma, eg: X-Client-Cert-SAN.
+	SanHeader string `protobuf:"bytes,3,opt,name=san_header,json=sanHeader,proto3" json:"san_header,omitempty"`
+	// the URL-encoded PEM of the certificate like the Cert field of XFCC, eg: X-Client-Cert.
+	PemHeader     string `protobuf:"bytes,4,opt,name=pem_header,json=pemHeader,proto3" json:"pem_header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientCert) Reset() {
+	*x = ClientCert{}
+	mi := &file_config_v1_gateway_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientCert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientCert) ProtoMessage() {}
+
+func (x *ClientCert) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientCert.ProtoReflect.Descriptor instead.
+func (*ClientCert) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClientCert) GetCnHeader() string {
+	if x != nil {
+		return x.CnHeader
+	}
+	return ""
+}
+
+func (x *ClientCert) GetSpiffeHeader() string {
+	if x != nil {
+		return x.SpiffeHeader
+	}
+	return ""
+}
+
+func (x *ClientCert) GetSanHeader() string {
+	if x != nil {
+		return x.SanHeader
+	}
+	return ""
+}
+
+func (x *ClientCert) GetPemHeader() string {
+	if x != nil {
+		return x.PemHeader
+	}
+	return ""
+}
+
+// ClientCertPolicy rejects the requests with 403 unless the client certificate matches.
+type ClientCertPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// requires a verified client certificate, implied by allowed_sans and spiffe_trust_domain.
+	Required bool `protobuf:"varint,1,opt,name=required,proto3" json:"required,omitempty"`
+	// one of the DNS, URI or email SANs must match one of the patterns, `*` matches any characters,
+	// eg: ["*.svc.cluster.local", "spiffe://prod.example.com/ns/payments/*"]
+	AllowedSans []string `protobuf:"bytes,2,rep,name=allowed_sans,json=allowedSans,proto3" json:"allowed_sans,omitempty"`
+	// the trust domain of the SPIFFE ID, eg: prod.example.com
+	SpiffeTrustDomain string `protobuf:"bytes,3,opt,name=spiffe_trust_domain,json=spiffeTrustDomain,proto3" json:"spiffe_trust_domain,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ClientCertPolicy) Reset() {
+	*x = ClientCertPolicy{}
+	mi := &file_config_v1_gateway_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientCertPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientCertPolicy) ProtoMessage() {}
+
+func (x *ClientCertPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientCertPolicy.ProtoReflect.Descriptor instead.
+func (*ClientCertPolicy) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ClientCertPolicy) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *ClientCertPolicy) GetAllowedSans() []string {
+	if x != nil {
+		return x.AllowedSans
+	}
+	return nil
+}
+
+func (x *ClientCertPolicy) GetSpiffeTrustDomain() string {
+	if x != nil {
+		return x.SpiffeTrustDomain
+	}
+	return ""
+}
+
+// Service defines the backends, timeouts, retries and middlewares once for the endpoints referencing it,
+// the references are expanded into the endpoints on loading.
+type Service struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// required and unique.
+	Name     string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Protocol Protocol             `protobuf:"varint,2,opt,name=protocol,proto3,enum=goddess.config.v1.Protocol" json:"protocol,omitempty"`
+	Backends []*Backend           `protobuf:"bytes,3,rep,name=backends,proto3" json:"backends,omitempty"`
+	Timeout  *durationpb.Duration `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Retry    *Retry               `protobuf:"bytes,5,opt,name=retry,proto3" json:"retry,omitempty"`
+	// prepended to the middlewares of the endpoint, an endpoint middleware with the same name replaces it.
+	Middlewares []*Middleware `protobuf:"bytes,6,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	// merged into the metadata of the endpoint, the endpoint values take precedence.
+	Metadata           map[string]string `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	UpstreamProtocol   UpstreamProtocol  `protobuf:"varint,8,opt,name=upstream_protocol,json=upstreamProtocol,proto3,enum=goddess.config.v1.UpstreamProtocol" json:"upstream_protocol,omitempty"`
+	UpstreamPathPrefix string            `protobuf:"bytes,9,opt,name=upstream_path_prefix,json=upstreamPathPrefix,proto3" json:"upstream_path_prefix,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Service) Reset() {
+	*x = Service{}
+	mi := &file_config_v1_gateway_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Service) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Service) ProtoMessage() {}
+
+func (x *Service) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Service.ProtoReflect.Descriptor instead.
+func (*Service) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Service) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Service) GetProtocol() Protocol {
+	if x != nil {
+		return x.Protocol
+	}
+	return Protocol_UNSPECIFIED
+}
+
+func (x *Service) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *Service) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Service) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *Service) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *Service) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Service) GetUpstreamProtocol() UpstreamProtocol {
+	if x != nil {
+		return x.UpstreamProtocol
+	}
+	return UpstreamProtocol_AUTO
+}
+
+func (x *Service) GetUpstreamPathPrefix() string {
+	if x != nil {
+		return x.UpstreamPathPrefix
+	}
+	return ""
+}
+
+// Listener accepts the connections on its own address, the HTTP endpoints are not served by it.
+type Listener struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the name of the listener in the metrics, required and unique.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// eg: ":8443", the address is not changed by a reload.
+	Address string        `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Mode    Listener_Mode `protobuf:"varint,3,opt,name=mode,proto3,enum=goddess.config.v1.Listener_Mode" json:"mode,omitempty"`
+	// matched in order, the first route matching the SNI is selected.
+	Routes []*PassthroughRoute `protobuf:"bytes,4,rep,name=routes,proto3" json:"routes,omitempty"`
+	// timeout of reading the ClientHello, default is 10s.
+	HandshakeTimeout *durationpb.Duration `protobuf:"bytes,5,opt,name=handshake_timeout,json=handshakeTimeout,proto3" json:"handshake_timeout,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Listener) Reset() {
+	*x = Listener{}
+	mi := &file_config_v1_gateway_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Listener) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Listener) ProtoMessage() {}
+
+func (x *Listener) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Listener.ProtoReflect.Descriptor instead.
+func (*Listener) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Listener) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Listener) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Listener) GetMode() Listener_Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return Listener_MODE_UNSPECIFIED
+}
+
+func (x *Listener) GetRoutes() []*PassthroughRoute {
+	if x != nil {
+		return x.Routes
+	}
+	return nil
+}
+
+func (x *Listener) GetHandshakeTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.HandshakeTimeout
+	}
+	return nil
+}
+
+type PassthroughRoute struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the name of the route in the metrics, default is the first host.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// the SNI hosts, eg: ["db.example.com", "*.legacy.example.com"], "*" matches any SNI including none.
+	Hosts []string `protobuf:"bytes,2,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	// the backends are selected in round robin, eg: "10.0.0.1:5432" or "discovery:///service_name".
+	Backends []*Backend `protobuf:"bytes,3,rep,name=backends,proto3" json:"backends,omitempty"`
+	// the connection is closed if no byte is transferred in either direction for the idle timeout, default is 5m.
+	IdleTimeout *durationpb.Duration `protobuf:"bytes,4,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`
+	// the connection is closed after the max duration, unlimited if not set.
+	MaxConnectionDuration *durationpb.Duration `protobuf:"bytes,5,opt,name=max_connection_duration,json=maxConnectionDuration,proto3" json:"max_connection_duration,omitempty"`
+	// timeout of dialing a backend, default is 5s.
+	DialTimeout   *durationpb.Duration `protobuf:"bytes,6,opt,name=dial_timeout,json=dialTimeout,proto3" json:"dial_timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PassthroughRoute) Reset() {
+	*x = PassthroughRoute{}
+	mi := &file_config_v1_gateway_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PassthroughRoute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PassthroughRoute) ProtoMessage() {}
+
+func (x *PassthroughRoute) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PassthroughRoute.ProtoReflect.Descriptor instead.
+func (*PassthroughRoute) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PassthroughRoute) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PassthroughRoute) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *PassthroughRoute) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *PassthroughRoute) GetIdleTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return nil
+}
+
+func (x *PassthroughRoute) GetMaxConnectionDuration() *durationpb.Duration {
+	if x != nil {
+		return x.MaxConnectionDuration
+	}
+	return nil
+}
+
+func (x *PassthroughRoute) GetDialTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.DialTimeout
+	}
+	return nil
+}
+
+type Resolver struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// nameservers queried in order, eg: ["10.0.0.2:53"], the port is 53 if not set. the system resolver is used if empty.
+	Nameservers []string `protobuf:"bytes,1,rep,name=nameservers,proto3" json:"nameservers,omitempty"`
+	// timeout of a lookup across the nameservers, default is 2s.
+	LookupTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=lookup_timeout,json=lookupTimeout,proto3" json:"lookup_timeout,omitempty"`
+	// the TTL of the records is clamped into [min_ttl, max_ttl], the records of the system resolver are cached for max_ttl.
+	// the lookups are not cached if max_ttl is not set.
+	MinTtl *durationpb.Duration `protobuf:"bytes,3,opt,name=min_ttl,json=minTtl,proto3" json:"min_ttl,omitempty"`
+	MaxTtl *durationpb.Duration `protobuf:"bytes,4,opt,name=max_ttl,json=maxTtl,proto3" json:"max_ttl,omitempty"`
+	// caches the lookups of the hosts without the records (NXDOMAIN or no A/AAAA), disabled if not set, the
+	// timeouts and the server failures are not cached.
+	NegativeTtl *durationpb.Duration `protobuf:"bytes,5,opt,name=negative_ttl,json=negativeTtl,proto3" json:"negative_ttl,omitempty"`
+	// rotates the addresses of the host on each dial, the addresses are dialed in the order of the answer otherwise.
+	RoundRobin    bool `protobuf:"varint,6,opt,name=round_robin,json=roundRobin,proto3" json:"round_robin,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resolver) Reset() {
+	*x = Resolver{}
+	mi := &file_config_v1_gateway_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resolver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resolver) ProtoMessage() {}
+
+func (x *Resolver) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resolver.ProtoReflect.Descriptor instead.
+func (*Resolver) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Resolver) GetNameservers() []string {
+	if x != nil {
+		return x.Nameservers
+	}
+	return nil
+}
+
+func (x *Resolver) GetLookupTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.LookupTimeout
+	}
+	return nil
+}
+
+func (x *Resolver) GetMinTtl() *durationpb.Duration {
+	if x != nil {
+		return x.MinTtl
+	}
+	return nil
+}
+
+func (x *Resolver) GetMaxTtl() *durationpb.Duration {
+	if x != nil {
+		return x.MaxTtl
+	}
+	return nil
+}
+
+func (x *Resolver) GetNegativeTtl() *durationpb.Duration {
+	if x != nil {
+		return x.NegativeTtl
+	}
+	return nil
+}
+
+func (x *Resolver) GetRoundRobin() bool {
+	if x != nil {
+		return x.RoundRobin
+	}
+	return false
+}
+
+type HostOverride struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the IPs with an optional port, eg: ["10.0.0.1", "10.0.0.2:8443"], dialed in order until one succeeds.
+	Addresses     []string `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostOverride) Reset() {
+	*x = HostOverride{}
+	mi := &file_config_v1_gateway_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostOverride) ProtoMessage() {}
+
+func (x *HostOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostOverride.ProtoReflect.Descriptor instead.
+func (*HostOverride) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *HostOverride) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+// BufferBudget bounds the bytes of the request bodies buffered at the same time gateway-wide.
+type BufferBudget struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// eg: 268435456 for 256MiB
+	MaxTotalBufferedBytes int64 `protobuf:"varint,1,opt,name=max_total_buffered_bytes,json=maxTotalBufferedBytes,proto3" json:"max_total_buffered_bytes,omitempty"`
+	// how long a request waits for the budget before it is rejected, rejected at once if not set.
+	QueueTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=queue_timeout,json=queueTimeout,proto3" json:"queue_timeout,omitempty"`
+	// Retry-After of the rejected requests, default is 1s.
+	RetryAfter    *durationpb.Duration `protobuf:"bytes,3,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BufferBudget) Reset() {
+	*x = BufferBudget{}
+	mi := &file_config_v1_gateway_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BufferBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BufferBudget) ProtoMessage() {}
+
+func (x *BufferBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BufferBudget.ProtoReflect.Descriptor instead.
+func (*BufferBudget) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BufferBudget) GetMaxTotalBufferedBytes() int64 {
+	if x != nil {
+		return x.MaxTotalBufferedBytes
+	}
+	return 0
+}
+
+func (x *BufferBudget) GetQueueTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTimeout
+	}
+	return nil
+}
+
+func (x *BufferBudget) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+// DeadlinePropagation tells the upstreams how much time is left for the request.
+type DeadlinePropagation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// header carrying the remaining milliseconds to http upstreams, eg: X-Request-Deadline,
+	// grpc upstreams always receive grpc-timeout.
+	Header string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// network margin subtracted from the remaining budget, default is 5ms.
+	Margin *durationpb.Duration `protobuf:"bytes,2,opt,name=margin,proto3" json:"margin,omitempty"`
+	// callers whose deadline header (or grpc-timeout) shortens the timeout of the request, eg: ["10.0.0.0/8"]
+	TrustedCidrs  []string `protobuf:"bytes,3,rep,name=trusted_cidrs,json=trustedCidrs,proto3" json:"trusted_cidrs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeadlinePropagation) Reset() {
+	*x = DeadlinePropagation{}
+	mi := &file_config_v1_gateway_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeadlinePropagation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeadlinePropagation) ProtoMessage() {}
+
+func (x *DeadlinePropagation) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeadlinePropagation.ProtoReflect.Descriptor instead.
+func (*DeadlinePropagation) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeadlinePropagation) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *DeadlinePropagation) GetMargin() *durationpb.Duration {
+	if x != nil {
+		return x.Margin
+	}
+	return nil
+}
+
+func (x *DeadlinePropagation) GetTrustedCidrs() []string {
+	if x != nil {
+		return x.TrustedCidrs
+	}
+	return nil
+}
+
+// HostGroup groups the endpoints by host, the middleware chain of an endpoint is
+// endpoint middlewares -> host group middlewares -> gateway middlewares, the gateway middlewares handle the request first.
+type HostGroup struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// endpoints with the host are assigned to the group unless Endpoint.host_group is set,
+	// a leading `*.` matches the subdomains, eg: ["api.example.com", "*.example.org"]
+	Hosts       []string      `protobuf:"bytes,2,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Middlewares []*Middleware `protobuf:"bytes,3,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	// fallback of the unmatched requests to the hosts, the host entries of Gateway.fallback take precedence.
+	Fallback *Fallback `protobuf:"bytes,4,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	// defaults of the endpoints in the group, used if not set by the endpoint.
+	Timeout             *durationpb.Duration `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Retry               *Retry               `protobuf:"bytes,6,opt,name=retry,proto3" json:"retry,omitempty"`
+	ResponseHeaderAllow []string             `protobuf:"bytes,7,rep,name=response_header_allow,json=responseHeaderAllow,proto3" json:"response_header_allow,omitempty"`
+	ResponseHeaderDeny  []string             `protobuf:"bytes,8,rep,name=response_header_deny,json=responseHeaderDeny,proto3" json:"response_header_deny,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HostGroup) Reset() {
+	*x = HostGroup{}
+	mi := &file_config_v1_gateway_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostGroup) ProtoMessage() {}
+
+func (x *HostGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostGroup.ProtoReflect.Descriptor instead.
+func (*HostGroup) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HostGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HostGroup) GetHosts() []string {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+func (x *HostGroup) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *HostGroup) GetFallback() *Fallback {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
+func (x *HostGroup) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *HostGroup) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *HostGroup) GetResponseHeaderAllow() []string {
+	if x != nil {
+		return x.ResponseHeaderAllow
+	}
+	return nil
+}
+
+func (x *HostGroup) GetResponseHeaderDeny() []string {
+	if x != nil {
+		return x.ResponseHeaderDeny
+	}
+	return nil
+}
+
+type Prewarm struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// prewarm all changed endpoints, otherwise only the endpoints with `prewarm: true`.
+	AllEndpoints bool `protobuf:"varint,1,opt,name=all_endpoints,json=allEndpoints,proto3" json:"all_endpoints,omitempty"`
+	// connections per upstream node, default is 1.
+	Connections uint32 `protobuf:"varint,2,opt,name=connections,proto3" json:"connections,omitempty"`
+	// max connections established in one reload, 0 means unlimited.
+	Budget uint32 `protobuf:"varint,3,opt,name=budget,proto3" json:"budget,omitempty"`
+	// the reload waits for the prewarm at most timeout, default is 3s.
+	Timeout       *durationpb.Duration `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Prewarm) Reset() {
+	*x = Prewarm{}
+	mi := &file_config_v1_gateway_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Prewarm) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Prewarm) ProtoMessage() {}
+
+func (x *Prewarm) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Prewarm.ProtoReflect.Descriptor instead.
+func (*Prewarm) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Prewarm) GetAllEndpoints() bool {
+	if x != nil {
+		return x.AllEndpoints
+	}
+	return false
+}
+
+func (x *Prewarm) GetConnections() uint32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *Prewarm) GetBudget() uint32 {
+	if x != nil {
+		return x.Budget
+	}
+	return 0
+}
+
+func (x *Prewarm) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type Fallback struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	NotFound         *FallbackAction        `protobuf:"bytes,1,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	MethodNotAllowed *FallbackAction        `protobuf:"bytes,2,opt,name=method_not_allowed,json=methodNotAllowed,proto3" json:"method_not_allowed,omitempty"`
+	// per host fallback overrides the gateway level actions, eg: {"api.example.com": {...}}
+	Hosts         map[string]*Fallback `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fallback) Reset() {
+	*x = Fallback{}
+	mi := &file_config_v1_gateway_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fallback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fallback) ProtoMessage() {}
+
+func (x *Fallback) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fallback.ProtoReflect.Descriptor instead.
+func (*Fallback) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Fallback) GetNotFound() *FallbackAction {
+	if x != nil {
+		return x.NotFound
+	}
+	return nil
+}
+
+func (x *Fallback) GetMethodNotAllowed() *FallbackAction {
+	if x != nil {
+		return x.MethodNotAllowed
+	}
+	return nil
+}
+
+func (x *Fallback) GetHosts() map[string]*Fallback {
+	if x != nil {
+		return x.Hosts
+	}
+	return nil
+}
+
+type FallbackAction struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Action:
+	//
+	//	*FallbackAction_Static_
+	//	*FallbackAction_Redirect_
+	//	*FallbackAction_Endpoint
+	Action        isFallbackAction_Action `protobuf_oneof:"action"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FallbackAction) Reset() {
+	*x = FallbackAction{}
+	mi := &file_config_v1_gateway_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FallbackAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FallbackAction) ProtoMessage() {}
+
+func (x *FallbackAction) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FallbackAction.ProtoReflect.Descriptor instead.
+func (*FallbackAction) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FallbackAction) GetAction() isFallbackAction_Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *FallbackAction) GetStatic() *FallbackAction_Static {
+	if x != nil {
+		if x, ok := x.Action.(*FallbackAction_Static_); ok {
+			return x.Static
+		}
+	}
+	return nil
+}
+
+func (x *FallbackAction) GetRedirect() *FallbackAction_Redirect {
+	if x != nil {
+		if x, ok := x.Action.(*FallbackAction_Redirect_); ok {
+			return x.Redirect
+		}
+	}
+	return nil
+}
+
+func (x *FallbackAction) GetEndpoint() *Endpoint {
+	if x != nil {
+		if x, ok := x.Action.(*FallbackAction_Endpoint); ok {
+			return x.Endpoint
+		}
+	}
+	return nil
+}
+
+type isFallbackAction_Action interface {
+	isFallbackAction_Action()
+}
+
+type FallbackAction_Static_ struct {
+	Static *FallbackAction_Static `protobuf:"bytes,1,opt,name=static,proto3,oneof"`
+}
+
+type FallbackAction_Redirect_ struct {
+	Redirect *FallbackAction_Redirect `protobuf:"bytes,2,opt,name=redirect,proto3,oneof"`
+}
+
+type FallbackAction_Endpoint struct {
+	// proxies the request to the default endpoint, path and method of the endpoint are ignored for matching.
+	Endpoint *Endpoint `protobuf:"bytes,3,opt,name=endpoint,proto3,oneof"`
+}
+
+func (*FallbackAction_Static_) isFallbackAction_Action() {}
+
+func (*FallbackAction_Redirect_) isFallbackAction_Action() {}
+
+func (*FallbackAction_Endpoint) isFallbackAction_Action() {}
+
+type ForwardedHeaders struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Style ForwardedHeaders_Style `protobuf:"varint,1,opt,name=style,proto3,enum=goddess.config.v1.ForwardedHeaders_Style" json:"style,omitempty"`
+	// CIDRs or IPs of the trusted proxies, forwarded values received from other peers are stripped.
+	// all peers are trusted if empty.
+	TrustedProxies []string `protobuf:"bytes,2,rep,name=trusted_proxies,json=trustedProxies,proto3" json:"trusted_proxies,omitempty"`
+	// identifier of the gateway used as the `by` parameter, eg: _gateway
+	By            string `protobuf:"bytes,3,opt,name=by,proto3" json:"by,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardedHeaders) Reset() {
+	*x = ForwardedHeaders{}
+	mi := &file_config_v1_gateway_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardedHeaders) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardedHeaders) ProtoMessage() {}
+
+func (x *ForwardedHeaders) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardedHeaders.ProtoReflect.Descriptor instead.
+func (*ForwardedHeaders) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ForwardedHeaders) GetStyle() ForwardedHeaders_Style {
+	if x != nil {
+		return x.Style
+	}
+	return ForwardedHeaders_X_FORWARDED
+}
+
+func (x *ForwardedHeaders) GetTrustedProxies() []string {
+	if x != nil {
+		return x.TrustedProxies
+	}
+	return nil
+}
+
+func (x *ForwardedHeaders) GetBy() string {
+	if x != nil {
+		return x.By
+	}
+	return ""
+}
+
+type TLS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Insecure      bool                   `protobuf:"varint,1,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	Cacert        string                 `protobuf:"bytes,2,opt,name=cacert,proto3" json:"cacert,omitempty"`
+	Cert          string                 `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"`
+	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	ServerName    string                 `protobuf:"bytes,5,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TLS) Reset() {
+	*x = TLS{}
+	mi := &file_config_v1_gateway_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLS) ProtoMessage() {}
+
+func (x *TLS) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLS.ProtoReflect.Descriptor instead.
+func (*TLS) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TLS) GetInsecure() bool {
+	if x != nil {
+		return x.Insecure
+	}
+	return false
+}
+
+func (x *TLS) GetCacert() string {
+	if x != nil {
+		return x.Cacert
+	}
+	return ""
+}
+
+func (x *TLS) GetCert() string {
+	if x != nil {
+		return x.Cert
+	}
+	return ""
+}
+
+func (x *TLS) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *TLS) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+type PriorityConfig struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Name      string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version   string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Endpoints []*Endpoint            `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	// the gateway stops applying the priority config when the ttl has elapsed since the file was written.
+	Ttl *durationpb.Duration `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// ramps the matched requests into the endpoints gradually, all the requests are served by them at once if not set.
+	Rollout       *Rollout `protobuf:"bytes,5,opt,name=rollout,proto3" json:"rollout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriorityConfig) Reset() {
+	*x = PriorityConfig{}
+	mi := &file_config_v1_gateway_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriorityConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriorityConfig) ProtoMessage() {}
+
+func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriorityConfig.ProtoReflect.Descriptor instead.
+func (*PriorityConfig) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PriorityConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PriorityConfig) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PriorityConfig) GetEndpoints() []*Endpoint {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+func (x *PriorityConfig) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *PriorityConfig) GetRollout() *Rollout {
+	if x != nil {
+		return x.Rollout
+	}
+	return nil
+}
+
+type Rollout struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// default is the time the priority config file was written.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// the percentage ramps linearly from initial_percent to 100 over the duration since the start time.
+	RampDuration *durationpb.Duration `protobuf:"bytes,2,opt,name=ramp_duration,json=rampDuration,proto3" json:"ramp_duration,omitempty"`
+	// the explicit percentages after the start time, override ramp_duration.
+	Steps []*RolloutStep `protobuf:"bytes,3,rep,name=steps,proto3" json:"steps,omitempty"`
+	// the attribute of the request hashed into the stable bucket, eg: "header:X-User-Id", "query:uid" or
+	// "cookie:session", the client ip is hashed if not set or the attribute is missing.
+	HashKey string `protobuf:"bytes,4,opt,name=hash_key,json=hashKey,proto3" json:"hash_key,omitempty"`
+	// the percentage at the start time, default is 1.
+	InitialPercent float64 `protobuf:"fixed64,5,opt,name=initial_percent,json=initialPercent,proto3" json:"initial_percent,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Rollout) Reset() {
+	*x = Rollout{}
+	mi := &file_config_v1_gateway_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rollout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rollout) ProtoMessage() {}
+
+func (x *Rollout) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rollout.ProtoReflect.Descriptor instead.
+func (*Rollout) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Rollout) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *Rollout) GetRampDuration() *durationpb.Duration {
+	if x != nil {
+		return x.RampDuration
+	}
+	return nil
+}
+
+func (x *Rollout) GetSteps() []*RolloutStep {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *Rollout) GetHashKey() string {
+	if x != nil {
+		return x.HashKey
+	}
+	return ""
+}
+
+func (x *Rollout) GetInitialPercent() float64 {
+	if x != nil {
+		return x.InitialPercent
+	}
+	return 0
+}
+
+type RolloutStep struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	After *durationpb.Duration   `protobuf:"bytes,1,opt,name=after,proto3" json:"after,omitempty"`
+	// eg: 5 for 5% of the requests.
+	Percent       float64 `protobuf:"fixed64,2,opt,name=percent,proto3" json:"percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RolloutStep) Reset() {
+	*x = RolloutStep{}
+	mi := &file_config_v1_gateway_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RolloutStep) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RolloutStep) ProtoMessage() {}
+
+func (x *RolloutStep) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RolloutStep.ProtoReflect.Descriptor instead.
+func (*RolloutStep) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RolloutStep) GetAfter() *durationpb.Duration {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+func (x *RolloutStep) GetPercent() float64 {
+	if x != nil {
+		return x.Percent
+	}
+	return 0
+}
+
+// PriorityRollout is set on the endpoints of a priority config with rollout when merged into the gateway config.
+type PriorityRollout struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the name of the priority config.
+	Name    string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Rollout *Rollout `protobuf:"bytes,2,opt,name=rollout,proto3" json:"rollout,omitempty"`
+	// the endpoint replaced by the priority config serves the requests out of the rollout, not found if not set.
+	Baseline      *Endpoint `protobuf:"bytes,3,opt,name=baseline,proto3" json:"baseline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PriorityRollout) Reset() {
+	*x = PriorityRollout{}
+	mi := &file_config_v1_gateway_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PriorityRollout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PriorityRollout) ProtoMessage() {}
+
+func (x *PriorityRollout) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PriorityRollout.ProtoReflect.Descriptor instead.
+func (*PriorityRollout) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PriorityRollout) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PriorityRollout) GetRollout() *Rollout {
+	if x != nil {
+		return x.Rollout
+	}
+	return nil
+}
+
+func (x *PriorityRollout) GetBaseline() *Endpoint {
+	if x != nil {
+		return x.Baseline
+	}
+	return nil
+}
+
+type Endpoint struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Path        string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Method      string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Protocol    Protocol               `protobuf:"varint,4,opt,name=protocol,proto3,enum=goddess.config.v1.Protocol" json:"protocol,omitempty"`
+	Timeout     *durationpb.Duration   `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Middlewares []*Middleware          `protobuf:"bytes,6,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	Backends    []*Backend             `protobuf:"bytes,7,rep,name=backends,proto3" json:"backends,omitempty"`
+	Retry       *Retry                 `protobuf:"bytes,8,opt,name=retry,proto3" json:"retry,omitempty"`
+	Metadata    map[string]string      `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Host        string                 `protobuf:"bytes,10,opt,name=host,proto3" json:"host,omitempty"`
+	// stream endpoints are used to support bidirectional request/response like websocket or grpc stream.
+	Stream bool `protobuf:"varint,11,opt,name=stream,proto3" json:"stream,omitempty"`
+	// admission queues requests by priority class when the endpoint is saturated, disabled if not set.
+	Admission *Admission `protobuf:"bytes,12,opt,name=admission,proto3" json:"admission,omitempty"`
+	// emits the upstream address and attempt count as response headers for trusted debug requests.
+	UpstreamDebugHeaders *UpstreamDebugHeaders `protobuf:"bytes,13,opt,name=upstream_debug_headers,json=upstreamDebugHeaders,proto3" json:"upstream_debug_headers,omitempty"`
+	// response headers copied to the client, all headers are allowed if empty, the gateway default is used if not set.
+	// a trailing `*` matches the header prefix, deny takes precedence over allow.
+	ResponseHeaderAllow []string `protobuf:"bytes,14,rep,name=response_header_allow,json=responseHeaderAllow,proto3" json:"response_header_allow,omitempty"`
+	ResponseHeaderDeny  []string `protobuf:"bytes,15,rep,name=response_header_deny,json=responseHeaderDeny,proto3" json:"response_header_deny,omitempty"`
+	// maintenance short-circuits the endpoint with 503 without touching the upstream.
+	Maintenance *Maintenance `protobuf:"bytes,16,opt,name=maintenance,proto3" json:"maintenance,omitempty"`
+	// rewrites the http status from the upstream grpc-status for non-grpc clients on unary grpc endpoints.
+	MapGrpcStatusToHttp bool `protobuf:"varint,17,opt,name=map_grpc_status_to_http,json=mapGrpcStatusToHttp,proto3" json:"map_grpc_status_to_http,omitempty"`
+	// establishes upstream connections after reload, see Gateway.prewarm.
+	Prewarm bool `protobuf:"varint,18,opt,name=prewarm,proto3" json:"prewarm,omitempty"`
+	// flush interval of the response body, negative means flushing immediately after each write.
+	// default is immediate for stream endpoints and no periodic flush for others.
+	FlushInterval *durationpb.Duration `protobuf:"bytes,19,opt,name=flush_interval,json=flushInterval,proto3" json:"flush_interval,omitempty"`
+	// response content types flushed immediately on buffered endpoints, eg: ["text/event-stream", "application/x-ndjson"]
+	FlushContentTypes []string `protobuf:"bytes,20,rep,name=flush_content_types,json=flushContentTypes,proto3" json:"flush_content_types,omitempty"`
+	// pins the client to the upstream node through a gateway-issued cookie.
+	StickyCookie *StickyCookie `protobuf:"bytes,21,opt,name=sticky_cookie,json=stickyCookie,proto3" json:"sticky_cookie,omitempty"`
+	// assigns the endpoint to the host group explicitly, otherwise the group is resolved by host.
+	HostGroup string `protobuf:"bytes,22,opt,name=host_group,json=hostGroup,proto3" json:"host_group,omitempty"`
+	// overrides Gateway.deadline_propagation.
+	DeadlinePropagation *DeadlinePropagation `protobuf:"bytes,23,opt,name=deadline_propagation,json=deadlinePropagation,proto3" json:"deadline_propagation,omitempty"`
+	// the HTTP version used to connect the backends, gRPC endpoints require HTTP/2.
+	UpstreamProtocol UpstreamProtocol `protobuf:"varint,24,opt,name=upstream_protocol,json=upstreamProtocol,proto3,enum=goddess.config.v1.UpstreamProtocol" json:"upstream_protocol,omitempty"`
+	// the subset of the upstream nodes selected by the metadata
+	NodeFilters *NodeFilters `protobuf:"bytes,25,opt,name=node_filters,json=nodeFilters,proto3" json:"node_filters,omitempty"`
+	// the base path the upstream path is joined under, eg: /internal/api/v3, the raw path and the query are preserved.
+	UpstreamPathPrefix string `protobuf:"bytes,26,opt,name=upstream_path_prefix,json=upstreamPathPrefix,proto3" json:"upstream_path_prefix,omitempty"`
+	// overrides Gateway.host_overrides by hostname.
+	HostOverrides map[string]*HostOverride `protobuf:"bytes,27,rep,name=host_overrides,json=hostOverrides,proto3" json:"host_overrides,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// media types of the request bodies accepted, eg: ["application/json", "text/*"], the parameters are ignored.
+	// all types are accepted if empty.
+	AllowedContentTypes []string `protobuf:"bytes,28,rep,name=allowed_content_types,json=allowedContentTypes,proto3" json:"allowed_content_types,omitempty"`
+	// how the requests without Content-Type are handled when allowed_content_types is set.
+	MissingContentType *MissingContentType `protobuf:"bytes,29,opt,name=missing_content_type,json=missingContentType,proto3" json:"missing_content_type,omitempty"`
+	// adjusts the concurrency offered to the upstream by its latency and error rate, disabled if not set.
+	AdaptiveConcurrency *AdaptiveConcurrency `protobuf:"bytes,30,opt,name=adaptive_concurrency,json=adaptiveConcurrency,proto3" json:"adaptive_concurrency,omitempty"`
+	// checks the websocket upgrades of the stream endpoint, the other requests are not affected.
+	Websocket *WebSocketPolicy `protobuf:"bytes,31,opt,name=websocket,proto3" json:"websocket,omitempty"`
+	// the behavior of the requests while the discovery returns zero instances, the endpoint keeps the last
+	// instances if not set.
+	EmptyUpstream *EmptyUpstream `protobuf:"bytes,32,opt,name=empty_upstream,json=emptyUpstream,proto3" json:"empty_upstream,omitempty"`
+	// set by the merge of a priority config with rollout, see PriorityConfig.rollout.
+	PriorityRollout *PriorityRollout `protobuf:"bytes,33,opt,name=priority_rollout,json=priorityRollout,proto3" json:"priority_rollout,omitempty"`
+	// serves the files by the gateway instead of proxying to the backends, the middlewares are still applied.
+	Static *Static `protobuf:"bytes,34,opt,name=static,proto3" json:"static,omitempty"`
+	// overrides Gateway.header_limits.
+	HeaderLimits *HeaderLimits `protobuf:"bytes,35,opt,name=header_limits,json=headerLimits,proto3" json:"header_limits,omitempty"`
+	// collects the header count and size histograms and the largest header names, disabled if not set.
+	HeaderStats *HeaderStats `protobuf:"bytes,36,opt,name=header_stats,json=headerStats,proto3" json:"header_stats,omitempty"`
+	// the service the unset fields of the endpoint are copied from, see Gateway.services.
+	Service string `protobuf:"bytes,37,opt,name=service,proto3" json:"service,omitempty"`
+	// checks the client certificate of the mTLS listeners, not checked if not set.
+	ClientCertPolicy *ClientCertPolicy `protobuf:"bytes,38,opt,name=client_cert_policy,json=clientCertPolicy,proto3" json:"client_cert_policy,omitempty"`
+	// logs the beginning of the upstream response bodies of the error statuses, disabled if not set.
+	UpstreamBodyCapture *UpstreamBodyCapture `protobuf:"bytes,39,opt,name=upstream_body_capture,json=upstreamBodyCapture,proto3" json:"upstream_body_capture,omitempty"`
+	// names of the transport decorators registered by client.RegisterTransportDecorator, applied in order
+	// to the upstream transports of the endpoint.
+	TransportDecorators []string `protobuf:"bytes,40,rep,name=transport_decorators,json=transportDecorators,proto3" json:"transport_decorators,omitempty"`
+	// overrides the egress proxy of the gateway, an empty url dials the upstreams directly.
+	EgressProxy *EgressProxy `protobuf:"bytes,41,opt,name=egress_proxy,json=egressProxy,proto3" json:"egress_proxy,omitempty"`
+	// emits the Server-Timing response header of the gateway phases, disabled if not set.
+	ServerTiming *ServerTiming `protobuf:"bytes,42,opt,name=server_timing,json=serverTiming,proto3" json:"server_timing,omitempty"`
+	// overrides the fields of Gateway.security_headers, the security headers are set with the defaults if only
+	// the endpoint sets it.
+	SecurityHeaders *SecurityHeaders `protobuf:"bytes,43,opt,name=security_headers,json=securityHeaders,proto3" json:"security_headers,omitempty"`
+	// overrides of the methods of the gRPC service route, eg: path /helloworld.Greeter/*, each method is routed
+	// by its own route before the service route.
+	GrpcMethods []*GRPCMethod `protobuf:"bytes,44,rep,name=grpc_methods,json=grpcMethods,proto3" json:"grpc_methods,omitempty"`
+	// limits the concurrent streams and the bandwidth of the stream endpoint, unlimited if not set.
+	StreamLimits *StreamLimits `protobuf:"bytes,45,opt,name=stream_limits,json=streamLimits,proto3" json:"stream_limits,omitempty"`
+	// fails over from the backends of the endpoint, the primary group, to the backend groups in order.
+	Failover *Failover `protobuf:"bytes,46,opt,name=failover,proto3" json:"failover,omitempty"`
+	// annotates the route with its service level objectives, exported by the route_slo_info metric.
+	Slo *SLO `protobuf:"bytes,47,opt,name=slo,proto3" json:"slo,omitempty"`
+	// the time an attempt waits for the upstream response headers once the request is written, eg: 5s. The attempt
+	// fails with the upstream_header_timeout error class and is retried on the other nodes like the other upstream
+	// errors, nothing reaches the client before the headers. Only the timeouts of the attempt apply if not set.
+	ResponseHeaderTimeout *durationpb.Duration `protobuf:"bytes,48,opt,name=response_header_timeout,json=responseHeaderTimeout,proto3" json:"response_header_timeout,omitempty"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *Endpoint) Reset() {
+	*x = Endpoint{}
+	mi := &file_config_v1_gateway_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Endpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Endpoint) ProtoMessage() {}
+
+func (x *Endpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
+func (*Endpoint) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Endpoint) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Endpoint) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Endpoint) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Endpoint) GetProtocol() Protocol {
+	if x != nil {
+		return x.Protocol
+	}
+	return Protocol_UNSPECIFIED
+}
+
+func (x *Endpoint) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *Endpoint) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *Endpoint) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Endpoint) GetStream() bool {
+	if x != nil {
+		return x.Stream
+	}
+	return false
+}
+
+func (x *Endpoint) GetAdmission() *Admission {
+	if x != nil {
+		return x.Admission
+	}
+	return nil
+}
+
+func (x *Endpoint) GetUpstreamDebugHeaders() *UpstreamDebugHeaders {
+	if x != nil {
+		return x.UpstreamDebugHeaders
+	}
+	return nil
+}
+
+func (x *Endpoint) GetResponseHeaderAllow() []string {
+	if x != nil {
+		return x.ResponseHeaderAllow
+	}
+	return nil
+}
+
+func (x *Endpoint) GetResponseHeaderDeny() []string {
+	if x != nil {
+		return x.ResponseHeaderDeny
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMaintenance() *Maintenance {
+	if x != nil {
+		return x.Maintenance
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMapGrpcStatusToHttp() bool {
+	if x != nil {
+		return x.MapGrpcStatusToHttp
+	}
+	return false
+}
+
+func (x *Endpoint) GetPrewarm() bool {
+	if x != nil {
+		return x.Prewarm
+	}
+	return false
+}
+
+func (x *Endpoint) GetFlushInterval() *durationpb.Duration {
+	if x != nil {
+		return x.FlushInterval
+	}
+	return nil
+}
+
+func (x *Endpoint) GetFlushContentTypes() []string {
+	if x != nil {
+		return x.FlushContentTypes
+	}
+	return nil
+}
+
+func (x *Endpoint) GetStickyCookie() *StickyCookie {
+	if x != nil {
+		return x.StickyCookie
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHostGroup() string {
+	if x != nil {
+		return x.HostGroup
+	}
+	return ""
+}
+
+func (x *Endpoint) GetDeadlinePropagation() *DeadlinePropagation {
+	if x != nil {
+		return x.DeadlinePropagation
+	}
+	return nil
+}
+
+func (x *Endpoint) GetUpstreamProtocol() UpstreamProtocol {
+	if x != nil {
+		return x.UpstreamProtocol
+	}
+	return UpstreamProtocol_AUTO
+}
+
+func (x *Endpoint) GetNodeFilters() *NodeFilters {
+	if x != nil {
+		return x.NodeFilters
+	}
+	return nil
+}
+
+func (x *Endpoint) GetUpstreamPathPrefix() string {
+	if x != nil {
+		return x.UpstreamPathPrefix
+	}
+	return ""
+}
+
+func (x *Endpoint) GetHostOverrides() map[string]*HostOverride {
+	if x != nil {
+		return x.HostOverrides
+	}
+	return nil
+}
+
+func (x *Endpoint) GetAllowedContentTypes() []string {
+	if x != nil {
+		return x.AllowedContentTypes
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMissingContentType() *MissingContentType {
+	if x != nil {
+		return x.MissingContentType
+	}
+	return nil
+}
+
+func (x *Endpoint) GetAdaptiveConcurrency() *AdaptiveConcurrency {
+	if x != nil {
+		return x.AdaptiveConcurrency
+	}
+	return nil
+}
+
+func (x *Endpoint) GetWebsocket() *WebSocketPolicy {
+	if x != nil {
+		return x.Websocket
+	}
+	return nil
+}
+
+func (x *Endpoint) GetEmptyUpstream() *EmptyUpstream {
+	if x != nil {
+		return x.EmptyUpstream
+	}
+	return nil
+}
+
+func (x *Endpoint) GetPriorityRollout() *PriorityRollout {
+	if x != nil {
+		return x.PriorityRollout
+	}
+	return nil
+}
+
+func (x *Endpoint) GetStatic() *Static {
+	if x != nil {
+		return x.Static
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHeaderLimits() *HeaderLimits {
+	if x != nil {
+		return x.HeaderLimits
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHeaderStats() *HeaderStats {
+	if x != nil {
+		return x.HeaderStats
+	}
+	return nil
+}
+
+func (x *Endpoint) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *Endpoint) GetClientCertPolicy() *ClientCertPolicy {
+	if x != nil {
+		return x.ClientCertPolicy
+	}
+	return nil
+}
+
+func (x *Endpoint) GetUpstreamBodyCapture() *UpstreamBodyCapture {
+	if x != nil {
+		return x.UpstreamBodyCapture
+	}
+	return nil
+}
+
+func (x *Endpoint) GetTransportDecorators() []string {
+	if x != nil {
+		return x.TransportDecorators
+	}
+	return nil
+}
+
+func (x *Endpoint) GetEgressProxy() *EgressProxy {
+	if x != nil {
+		return x.EgressProxy
+	}
+	return nil
+}
+
+func (x *Endpoint) GetServerTiming() *ServerTiming {
+	if x != nil {
+		return x.ServerTiming
+	}
+	return nil
+}
+
+func (x *Endpoint) GetSecurityHeaders() *SecurityHeaders {
+	if x != nil {
+		return x.SecurityHeaders
+	}
+	return nil
+}
+
+func (x *Endpoint) GetGrpcMethods() []*GRPCMethod {
+	if x != nil {
+		return x.GrpcMethods
+	}
+	return nil
+}
+
+func (x *Endpoint) GetStreamLimits() *StreamLimits {
+	if x != nil {
+		return x.StreamLimits
+	}
+	return nil
+}
+
+func (x *Endpoint) GetFailover() *Failover {
+	if x != nil {
+		return x.Failover
+	}
+	return nil
+}
+
+func (x *Endpoint) GetSlo() *SLO {
+	if x != nil {
+		return x.Slo
+	}
+	return nil
+}
+
+func (x *Endpoint) GetResponseHeaderTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.ResponseHeaderTimeout
+	}
+	return nil
+}
+
+// SLO is the service level objectives of the route, the failed requests are the 5xx and transport errors.
+type SLO struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the target ratio of the requests not failed, eg: 0.999, no availability objective if 0.
+	Availability float64 `protobuf:"fixed64,1,opt,name=availability,proto3" json:"availability,omitempty"`
+	// the latency the requests are counted as violating over, eg: 300ms, no latency objective if not set.
+	LatencyThreshold *durationpb.Duration `protobuf:"bytes,2,opt,name=latency_threshold,json=latencyThreshold,proto3" json:"latency_threshold,omitempty"`
+	// the target ratio of the requests within the latency threshold, eg: 0.99 for the p99 under the threshold.
+	LatencyTarget float64 `protobuf:"fixed64,3,opt,name=latency_target,json=latencyTarget,proto3" json:"latency_target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SLO) Reset() {
+	*x = SLO{}
+	mi := &file_config_v1_gateway_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SLO) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SLO) ProtoMessage() {}
+
+func (x *SLO) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SLO.ProtoReflect.Descriptor instead.
+func (*SLO) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SLO) GetAvailability() float64 {
+	if x != nil {
+		return x.Availability
+	}
+	return 0
+}
+
+func (x *SLO) GetLatencyThreshold() *durationpb.Duration {
+	if x != nil {
+		return x.LatencyThreshold
+	}
+	return nil
+}
+
+func (x *SLO) GetLatencyTarget() float64 {
+	if x != nil {
+		return x.LatencyTarget
+	}
+	return 0
+}
+
+// Failover sends the requests to the first backend group with the nodes and below its error rate threshold,
+// the backends of the endpoint are the primary group.
+type Failover struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the secondary groups in the order failed over to.
+	Groups []*BackendGroup `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	// the error rate, 5xx and transport errors, of a group over which the next group is failed over to, eg: 0.5,
+	// only the groups without the nodes are failed over if 0.
+	ErrorRate float64 `protobuf:"fixed64,2,opt,name=error_rate,json=errorRate,proto3" json:"error_rate,omitempty"`
+	// the requests of a group in the window needed to judge its error rate, default is 20.
+	MinRequests uint32 `protobuf:"varint,3,opt,name=min_requests,json=minRequests,proto3" json:"min_requests,omitempty"`
+	// the window of the error rate, and the time the group is skipped for before it is tried again, default is 30s.
+	Window *durationpb.Duration `protobuf:"bytes,4,opt,name=window,proto3" json:"window,omitempty"`
+	// the percentage of the requests sent to the first secondary group while the primary group serves to keep it warm,
+	// eg: 1 for 1%.
+	WarmPercent   float64 `protobuf:"fixed64,5,opt,name=warm_percent,json=warmPercent,proto3" json:"warm_percent,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Failover) Reset() {
+	*x = Failover{}
+	mi := &file_config_v1_gateway_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Failover) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Failover) ProtoMessage() {}
+
+func (x *Failover) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Failover.ProtoReflect.Descriptor instead.
+func (*Failover) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Failover) GetGroups() []*BackendGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *Failover) GetErrorRate() float64 {
+	if x != nil {
+		return x.ErrorRate
+	}
+	return 0
+}
+
+func (x *Failover) GetMinRequests() uint32 {
+	if x != nil {
+		return x.MinRequests
+	}
+	return 0
+}
+
+func (x *Failover) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *Failover) GetWarmPercent() float64 {
+	if x != nil {
+		return x.WarmPercent
+	}
+	return 0
+}
+
+type BackendGroup struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the name of the group in the metrics and the logs, the primary group is "primary".
+	Name          string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Backends      []*Backend `protobuf:"bytes,2,rep,name=backends,proto3" json:"backends,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackendGroup) Reset() {
+	*x = BackendGroup{}
+	mi := &file_config_v1_gateway_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackendGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendGroup) ProtoMessage() {}
+
+func (x *BackendGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendGroup.ProtoReflect.Descriptor instead.
+func (*BackendGroup) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *BackendGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BackendGroup) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+// StreamLimits limits the streams of the stream endpoint, the streams are counted across the reloads.
+type StreamLimits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the concurrent streams of the endpoint, the others are rejected with 503, unlimited if 0.
+	MaxStreams uint32 `protobuf:"varint,1,opt,name=max_streams,json=maxStreams,proto3" json:"max_streams,omitempty"`
+	// the concurrent streams of a client, the others are rejected with 429, unlimited if 0.
+	MaxClientStreams uint32 `protobuf:"varint,2,opt,name=max_client_streams,json=maxClientStreams,proto3" json:"max_client_streams,omitempty"`
+	// identifies the clients by the consumer id set by the middlewares, eg: jwt, the requests without it are
+	// identified by the ip as well.
+	ClientByConsumer bool `protobuf:"varint,3,opt,name=client_by_consumer,json=clientByConsumer,proto3" json:"client_by_consumer,omitempty"`
+	// the bytes per second of each direction of a stream, unlimited if 0.
+	StreamBytesPerSecond uint64 `protobuf:"varint,4,opt,name=stream_bytes_per_second,json=streamBytesPerSecond,proto3" json:"stream_bytes_per_second,omitempty"`
+	// the bytes per second of each direction of all the streams of the endpoint, unlimited if 0.
+	EndpointBytesPerSecond uint64 `protobuf:"varint,5,opt,name=endpoint_bytes_per_second,json=endpointBytesPerSecond,proto3" json:"endpoint_bytes_per_second,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *StreamLimits) Reset() {
+	*x = StreamLimits{}
+	mi := &file_config_v1_gateway_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLimits) ProtoMessage() {}
+
+func (x *StreamLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLimits.ProtoReflect.Descriptor instead.
+func (*StreamLimits) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *StreamLimits) GetMaxStreams() uint32 {
+	if x != nil {
+		return x.MaxStreams
+	}
+	return 0
+}
+
+func (x *StreamLimits) GetMaxClientStreams() uint32 {
+	if x != nil {
+		return x.MaxClientStreams
+	}
+	return 0
+}
+
+func (x *StreamLimits) GetClientByConsumer() bool {
+	if x != nil {
+		return x.ClientByConsumer
+	}
+	return false
+}
+
+func (x *StreamLimits) GetStreamBytesPerSecond() uint64 {
+	if x != nil {
+		return x.StreamBytesPerSecond
+	}
+	return 0
+}
+
+func (x *StreamLimits) GetEndpointBytesPerSecond() uint64 {
+	if x != nil {
+		return x.EndpointBytesPerSecond
+	}
+	return 0
+}
+
+type GRPCMethod struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the method name, eg: SayHello
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// replaces the middlewares of the service route if not empty.
+	Middlewares []*Middleware `protobuf:"bytes,2,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	// replaces the timeout of the service route if set.
+	Timeout *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// replaces the retry of the service route if set.
+	Retry         *Retry `protobuf:"bytes,4,opt,name=retry,proto3" json:"retry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GRPCMethod) Reset() {
+	*x = GRPCMethod{}
+	mi := &file_config_v1_gateway_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GRPCMethod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GRPCMethod) ProtoMessage() {}
+
+func (x *GRPCMethod) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GRPCMethod.ProtoReflect.Descriptor instead.
+func (*GRPCMethod) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GRPCMethod) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GRPCMethod) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *GRPCMethod) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *GRPCMethod) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+// SecurityHeaders sets the security headers missing in the responses, the headers set by the upstreams are kept
+// unless force. The empty values are the defaults or the values of the gateway, "off" disables the header.
+type SecurityHeaders struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// disables all the security headers of the endpoint.
+	Disabled bool `protobuf:"varint,1,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// overwrites the headers set by the upstreams.
+	Force bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
+	// Strict-Transport-Security, max-age=31536000 by default, replaces the one of the gateway if set by the endpoint.
+	Hsts *HSTS `protobuf:"bytes,3,opt,name=hsts,proto3" json:"hsts,omitempty"`
+	// X-Content-Type-Options, nosniff by default.
+	ContentTypeOptions string `protobuf:"bytes,4,opt,name=content_type_options,json=contentTypeOptions,proto3" json:"content_type_options,omitempty"`
+	// Referrer-Policy, strict-origin-when-cross-origin by default.
+	ReferrerPolicy string `protobuf:"bytes,5,opt,name=referrer_policy,json=referrerPolicy,proto3" json:"referrer_policy,omitempty"`
+	// X-Frame-Options, DENY by default.
+	FrameOptions string `protobuf:"bytes,6,opt,name=frame_options,json=frameOptions,proto3" json:"frame_options,omitempty"`
+	// the frame-ancestors directive of Content-Security-Policy, eg: 'self', not set by default.
+	FrameAncestors string `protobuf:"bytes,7,opt,name=frame_ancestors,json=frameAncestors,proto3" json:"frame_ancestors,omitempty"`
+	// Permissions-Policy, camera=(), microphone=(), geolocation=() by default.
+	PermissionsPolicy string `protobuf:"bytes,8,opt,name=permissions_policy,json=permissionsPolicy,proto3" json:"permissions_policy,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *SecurityHeaders) Reset() {
+	*x = SecurityHeaders{}
+	mi := &file_config_v1_gateway_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SecurityHeaders) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SecurityHeaders) ProtoMessage() {}
+
+func (x *SecurityHeaders) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SecurityHeaders.ProtoReflect.Descriptor instead.
+func (*SecurityHeaders) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SecurityHeaders) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *SecurityHeaders) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+func (x *SecurityHeaders) GetHsts() *HSTS {
+	if x != nil {
+		return x.Hsts
+	}
+	return nil
+}
+
+func (x *SecurityHeaders) GetContentTypeOptions() string {
+	if x != nil {
+		return x.ContentTypeOptions
+	}
+	return ""
+}
+
+func (x *SecurityHeaders) GetReferrerPolicy() string {
+	if x != nil {
+		return x.ReferrerPolicy
+	}
+	return ""
+}
+
+func (x *SecurityHeaders) GetFrameOptions() string {
+	if x != nil {
+		return x.FrameOptions
+	}
+	return ""
+}
+
+func (x *SecurityHeaders) GetFrameAncestors() string {
+	if x != nil {
+		return x.FrameAncestors
+	}
+	return ""
+}
+
+func (x *SecurityHeaders) GetPermissionsPolicy() string {
+	if x != nil {
+		return x.PermissionsPolicy
+	}
+	return ""
+}
+
+// MethodPolicy sets the methods routed by the gateway, GET, HEAD, POST, PUT, DELETE, CONNECT, OPTIONS, TRACE,
+// PATCH and the methods of the endpoints by default. The requests of the other methods are replied 501 and counted
+// under the _other method label, the requests of the disabled methods are replied 405, the endpoints of the
+// disabled methods fail the update.
+type MethodPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the methods routed in addition to the default ones, eg: PROPFIND.
+	ExtraMethods []string `protobuf:"bytes,1,rep,name=extra_methods,json=extraMethods,proto3" json:"extra_methods,omitempty"`
+	// the default or extra methods not routed, eg: TRACE.
+	DisabledMethods []string `protobuf:"bytes,2,rep,name=disabled_methods,json=disabledMethods,proto3" json:"disabled_methods,omitempty"`
+	// the handling of the bodies of GET and HEAD requests.
+	GetHeadBody   MethodPolicy_BodyAction `protobuf:"varint,3,opt,name=get_head_body,json=getHeadBody,proto3,enum=goddess.config.v1.MethodPolicy_BodyAction" json:"get_head_body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MethodPolicy) Reset() {
+	*x = MethodPolicy{}
+	mi := &file_config_v1_gateway_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MethodPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MethodPolicy) ProtoMessage() {}
+
+func (x *MethodPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MethodPolicy.ProtoReflect.Descriptor instead.
+func (*MethodPolicy) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *MethodPolicy) GetExtraMethods() []string {
+	if x != nil {
+		return x.ExtraMethods
+	}
+	return nil
+}
+
+func (x *MethodPolicy) GetDisabledMethods() []string {
+	if x != nil {
+		return x.DisabledMethods
+	}
+	return nil
+}
+
+func (x *MethodPolicy) GetGetHeadBody() MethodPolicy_BodyAction {
+	if x != nil {
+		return x.GetHeadBody
+	}
+	return MethodPolicy_FORWARD
+}
+
+type HSTS struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Disabled bool                   `protobuf:"varint,1,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// 365 days if not set.
+	MaxAge            *durationpb.Duration `protobuf:"bytes,2,opt,name=max_age,json=maxAge,proto3" json:"max_age,omitempty"`
+	IncludeSubdomains bool                 `protobuf:"varint,3,opt,name=include_subdomains,json=includeSubdomains,proto3" json:"include_subdomains,omitempty"`
+	Preload           bool                 `protobuf:"varint,4,opt,name=preload,proto3" json:"preload,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HSTS) Reset() {
+	*x = HSTS{}
+	mi := &file_config_v1_gateway_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HSTS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HSTS) ProtoMessage() {}
+
+func (x *HSTS) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HSTS.ProtoReflect.Descriptor instead.
+func (*HSTS) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *HSTS) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *HSTS) GetMaxAge() *durationpb.Duration {
+	if x != nil {
+		return x.MaxAge
+	}
+	return nil
+}
+
+func (x *HSTS) GetIncludeSubdomains() bool {
+	if x != nil {
+		return x.IncludeSubdomains
+	}
+	return false
+}
+
+func (x *HSTS) GetPreload() bool {
+	if x != nil {
+		return x.Preload
+	}
+	return false
+}
+
+type ServerTiming struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// request header required to emit the header, eg: X-Gateway-Debug, the header is not emitted if empty and
+	// only the ttfb is recorded.
+	Header string `protobuf:"bytes,2,opt,name=header,proto3" json:"header,omitempty"`
+	// trusted values of the request header, required with the header.
+	Values        []string `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerTiming) Reset() {
+	*x = ServerTiming{}
+	mi := &file_config_v1_gateway_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerTiming) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerTiming) ProtoMessage() {}
+
+func (x *ServerTiming) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerTiming.ProtoReflect.Descriptor instead.
+func (*ServerTiming) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ServerTiming) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *ServerTiming) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *ServerTiming) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// EgressProxy is the forward proxy the upstream connections are tunneled through, TLS to the upstreams is
+// still established end to end through the tunnel.
+type EgressProxy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// url of the proxy, http://[user:password@]host:port for HTTP CONNECT or socks5://[user:password@]host:port.
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// the hosts dialed directly: hostnames, domain suffixes like .example.com, IPs or CIDRs.
+	NoProxy       []string `protobuf:"bytes,2,rep,name=no_proxy,json=noProxy,proto3" json:"no_proxy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EgressProxy) Reset() {
+	*x = EgressProxy{}
+	mi := &file_config_v1_gateway_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EgressProxy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EgressProxy) ProtoMessage() {}
+
+func (x *EgressProxy) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EgressProxy.ProtoReflect.Descriptor instead.
+func (*EgressProxy) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *EgressProxy) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *EgressProxy) GetNoProxy() []string {
+	if x != nil {
+		return x.NoProxy
+	}
+	return nil
+}
+
+// UpstreamBodyCapture records the first bytes of the upstream response bodies while they are copied to the client,
+// the response received by the client is not changed.
+type UpstreamBodyCapture struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the bytes recorded, default 4096, at most 65536.
+	MaxBytes uint32 `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// the status range of the responses recorded, default 500-599.
+	StatusMin uint32 `protobuf:"varint,2,opt,name=status_min,json=statusMin,proto3" json:"status_min,omitempty"`
+	StatusMax uint32 `protobuf:"varint,3,opt,name=status_max,json=statusMax,proto3" json:"status_max,omitempty"`
+	// the values of the JSON fields with the names are masked at any depth, eg: ["password", "token"]
+	MaskFields    []string `protobuf:"bytes,4,rep,name=mask_fields,json=maskFields,proto3" json:"mask_fields,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpstreamBodyCapture) Reset() {
+	*x = UpstreamBodyCapture{}
+	mi := &file_config_v1_gateway_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpstreamBodyCapture) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpstreamBodyCapture) ProtoMessage() {}
+
+func (x *UpstreamBodyCapture) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpstreamBodyCapture.ProtoReflect.Descriptor instead.
+func (*UpstreamBodyCapture) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpstreamBodyCapture) GetMaxBytes() uint32 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *UpstreamBodyCapture) GetStatusMin() uint32 {
+	if x != nil {
+		return x.StatusMin
+	}
+	return 0
+}
+
+func (x *UpstreamBodyCapture) GetStatusMax() uint32 {
+	if x != nil {
+		return x.StatusMax
+	}
+	return 0
+}
+
+func (x *UpstreamBodyCapture) GetMaskFields() []string {
+	if x != nil {
+		return x.MaskFields
+	}
+	return nil
+}
+
+type HeaderStats struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// the number of the largest header names tracked per direction, default 20, at most 100.
+	TopK          uint32 `protobuf:"varint,2,opt,name=top_k,json=topK,proto3" json:"top_k,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderStats) Reset() {
+	*x = HeaderStats{}
+	mi := &file_config_v1_gateway_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderStats) ProtoMessage() {}
+
+func (x *HeaderStats) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderStats.ProtoReflect.Descriptor instead.
+func (*HeaderStats) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *HeaderStats) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *HeaderStats) GetTopK() uint32 {
+	if x != nil {
+		return x.TopK
+	}
+	return 0
+}
+
+// HeaderLimits caps the request headers forwarded upstream, the requests over the limits are replied 431.
+type HeaderLimits struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the total bytes of the header names and values, 0 means unlimited.
+	MaxTotalBytes uint32 `protobuf:"varint,1,opt,name=max_total_bytes,json=maxTotalBytes,proto3" json:"max_total_bytes,omitempty"`
+	// the bytes of any header value, 0 means unlimited.
+	MaxValueBytes uint32 `protobuf:"varint,2,opt,name=max_value_bytes,json=maxValueBytes,proto3" json:"max_value_bytes,omitempty"`
+	// applied before the limits are checked, eg: dropping Cookie for the API routes that never need it.
+	Actions       []*HeaderAction `protobuf:"bytes,3,rep,name=actions,proto3" json:"actions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderLimits) Reset() {
+	*x = HeaderLimits{}
+	mi := &file_config_v1_gateway_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderLimits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderLimits) ProtoMessage() {}
+
+func (x *HeaderLimits) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderLimits.ProtoReflect.Descriptor instead.
+func (*HeaderLimits) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *HeaderLimits) GetMaxTotalBytes() uint32 {
+	if x != nil {
+		return x.MaxTotalBytes
+	}
+	return 0
+}
+
+func (x *HeaderLimits) GetMaxValueBytes() uint32 {
+	if x != nil {
+		return x.MaxValueBytes
+	}
+	return 0
+}
+
+func (x *HeaderLimits) GetActions() []*HeaderAction {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+type HeaderAction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Action        HeaderAction_Action    `protobuf:"varint,2,opt,name=action,proto3,enum=goddess.config.v1.HeaderAction_Action" json:"action,omitempty"`
+	MaxBytes      uint32                 `protobuf:"varint,3,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderAction) Reset() {
+	*x = HeaderAction{}
+	mi := &file_config_v1_gateway_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderAction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderAction) ProtoMessage() {}
+
+func (x *HeaderAction) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderAction.ProtoReflect.Descriptor instead.
+func (*HeaderAction) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *HeaderAction) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HeaderAction) GetAction() HeaderAction_Action {
+	if x != nil {
+		return x.Action
+	}
+	return HeaderAction_DROP
+}
+
+func (x *HeaderAction) GetMaxBytes() uint32 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+type Static struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the directory the files are served from.
+	Dir string `protobuf:"bytes,1,opt,name=dir,proto3" json:"dir,omitempty"`
+	// the name of the file system registered by proxy.RegisterStaticFS, eg: the assets embedded into the binary.
+	// takes precedence over dir.
+	Embed string `protobuf:"bytes,2,opt,name=embed,proto3" json:"embed,omitempty"`
+	// maps the request paths to the file names, eg: {"/openapi.json": "spec/v1.json"}, the other paths are
+	// resolved under the root if empty and replied 404 otherwise.
+	Files map[string]string `protobuf:"bytes,3,rep,name=files,proto3" json:"files,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// the prefix removed from the request path before resolving the file, eg: /static.
+	StripPrefix string `protobuf:"bytes,4,opt,name=strip_prefix,json=stripPrefix,proto3" json:"strip_prefix,omitempty"`
+	// the Cache-Control header of the responses, eg: "public, max-age=3600".
+	CacheControl string `protobuf:"bytes,5,opt,name=cache_control,json=cacheControl,proto3" json:"cache_control,omitempty"`
+	// disables the ETag generated from the file content.
+	DisableEtag bool `protobuf:"varint,6,opt,name=disable_etag,json=disableEtag,proto3" json:"disable_etag,omitempty"`
+	// the file served for the directories, eg: index.html, directories are replied 404 if empty.
+	Index         string `protobuf:"bytes,7,opt,name=index,proto3" json:"index,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Static) Reset() {
+	*x = Static{}
+	mi := &file_config_v1_gateway_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Static) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Static) ProtoMessage() {}
+
+func (x *Static) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Static.ProtoReflect.Descriptor instead.
+func (*Static) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *Static) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *Static) GetEmbed() string {
+	if x != nil {
+		return x.Embed
+	}
+	return ""
+}
+
+func (x *Static) GetFiles() map[string]string {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+func (x *Static) GetStripPrefix() string {
+	if x != nil {
+		return x.StripPrefix
+	}
+	return ""
+}
+
+func (x *Static) GetCacheControl() string {
+	if x != nil {
+		return x.CacheControl
+	}
+	return ""
+}
+
+func (x *Static) GetDisableEtag() bool {
+	if x != nil {
+		return x.DisableEtag
+	}
+	return false
+}
+
+func (x *Static) GetIndex() string {
+	if x != nil {
+		return x.Index
+	}
+	return ""
+}
+
+type EmptyUpstream struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Action EmptyUpstream_Action   `protobuf:"varint,1,opt,name=action,proto3,enum=goddess.config.v1.EmptyUpstream_Action" json:"action,omitempty"`
+	// default 1s.
+	WaitTimeout   *durationpb.Duration `protobuf:"bytes,2,opt,name=wait_timeout,json=waitTimeout,proto3" json:"wait_timeout,omitempty"`
+	Fallback      *Backend             `protobuf:"bytes,3,opt,name=fallback,proto3" json:"fallback,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmptyUpstream) Reset() {
+	*x = EmptyUpstream{}
+	mi := &file_config_v1_gateway_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmptyUpstream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyUpstream) ProtoMessage() {}
+
+func (x *EmptyUpstream) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyUpstream.ProtoReflect.Descriptor instead.
+func (*EmptyUpstream) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *EmptyUpstream) GetAction() EmptyUpstream_Action {
+	if x != nil {
+		return x.Action
+	}
+	return EmptyUpstream_FAIL_FAST
+}
+
+func (x *EmptyUpstream) GetWaitTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.WaitTimeout
+	}
+	return nil
+}
+
+func (x *EmptyUpstream) GetFallback() *Backend {
+	if x != nil {
+		return x.Fallback
+	}
+	return nil
+}
+
+type WebSocketPolicy struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sec-WebSocket-Protocol values accepted, the other offers are removed before reaching the upstream,
+	// the upgrade is rejected with 400 if none of the offers is accepted. any value is accepted if empty.
+	Subprotocols []string `protobuf:"bytes,1,rep,name=subprotocols,proto3" json:"subprotocols,omitempty"`
+	// Origin values accepted, eg: ["https://app.example.com", "https://*.example.com"], the upgrade is rejected
+	// with 403 otherwise. any origin is accepted if empty.
+	AllowedOrigins []string `protobuf:"bytes,2,rep,name=allowed_origins,json=allowedOrigins,proto3" json:"allowed_origins,omitempty"`
+	// rejects the upgrades without the Origin header, eg: the non-browser clients.
+	RequireOrigin bool `protobuf:"varint,3,opt,name=require_origin,json=requireOrigin,proto3" json:"require_origin,omitempty"`
+	// Sec-WebSocket-Extensions offered to the upstream, eg: ["permessage-deflate"], the other offers are removed.
+	// all extensions are offered if empty.
+	AllowedExtensions []string `protobuf:"bytes,4,rep,name=allowed_extensions,json=allowedExtensions,proto3" json:"allowed_extensions,omitempty"`
+	// removes all the extension offers, takes precedence over allowed_extensions.
+	DisableExtensions bool `protobuf:"varint,5,opt,name=disable_extensions,json=disableExtensions,proto3" json:"disable_extensions,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *WebSocketPolicy) Reset() {
+	*x = WebSocketPolicy{}
+	mi := &file_config_v1_gateway_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WebSocketPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WebSocketPolicy) ProtoMessage() {}
+
+func (x *WebSocketPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WebSocketPolicy.ProtoReflect.Descriptor instead.
+func (*WebSocketPolicy) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *WebSocketPolicy) GetSubprotocols() []string {
+	if x != nil {
+		return x.Subprotocols
+	}
+	return nil
+}
+
+func (x *WebSocketPolicy) GetAllowedOrigins() []string {
+	if x != nil {
+		return x.AllowedOrigins
+	}
+	return nil
+}
+
+func (x *WebSocketPolicy) GetRequireOrigin() bool {
+	if x != nil {
+		return x.RequireOrigin
+	}
+	return false
+}
+
+func (x *WebSocketPolicy) GetAllowedExtensions() []string {
+	if x != nil {
+		return x.AllowedExtensions
+	}
+	return nil
+}
+
+func (x *WebSocketPolicy) GetDisableExtensions() bool {
+	if x != nil {
+		return x.DisableExtensions
+	}
+	return false
+}
+
+type MissingContentType struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Action        MissingContentType_Action `protobuf:"varint,1,opt,name=action,proto3,enum=goddess.config.v1.MissingContentType_Action" json:"action,omitempty"`
+	DefaultType   string                    `protobuf:"bytes,2,opt,name=default_type,json=defaultType,proto3" json:"default_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MissingContentType) Reset() {
+	*x = MissingContentType{}
+	mi := &file_config_v1_gateway_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MissingContentType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MissingContentType) ProtoMessage() {}
+
+func (x *MissingContentType) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MissingContentType.ProtoReflect.Descriptor instead.
+func (*MissingContentType) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *MissingContentType) GetAction() MissingContentType_Action {
+	if x != nil {
+		return x.Action
+	}
+	return MissingContentType_ALLOW
+}
+
+func (x *MissingContentType) GetDefaultType() string {
+	if x != nil {
+		return x.DefaultType
+	}
+	return ""
+}
+
+type StickyCookie struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// default is goddess_affinity
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// max age of the cookie, the cookie lasts for the browser session if not set.
+	Ttl *durationpb.Duration `protobuf:"bytes,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// HMAC key signing the cookie, required.
+	SigningKey string `protobuf:"bytes,3,opt,name=signing_key,json=signingKey,proto3" json:"signing_key,omitempty"`
+	Secure     bool   `protobuf:"varint,4,opt,name=secure,proto3" json:"secure,omitempty"`
+	// HttpOnly is set unless the cookie should be readable by scripts.
+	DisableHttpOnly bool                  `protobuf:"varint,5,opt,name=disable_http_only,json=disableHttpOnly,proto3" json:"disable_http_only,omitempty"`
+	SameSite        StickyCookie_SameSite `protobuf:"varint,6,opt,name=same_site,json=sameSite,proto3,enum=goddess.config.v1.StickyCookie_SameSite" json:"same_site,omitempty"`
+	// default is /
+	Path          string `protobuf:"bytes,7,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StickyCookie) Reset() {
+	*x = StickyCookie{}
+	mi := &file_config_v1_gateway_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StickyCookie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StickyCookie) ProtoMessage() {}
+
+func (x *StickyCookie) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StickyCookie.ProtoReflect.Descriptor instead.
+func (*StickyCookie) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *StickyCookie) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StickyCookie) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *StickyCookie) GetSigningKey() string {
+	if x != nil {
+		return x.SigningKey
+	}
+	return ""
+}
+
+func (x *StickyCookie) GetSecure() bool {
+	if x != nil {
+		return x.Secure
+	}
+	return false
+}
+
+func (x *StickyCookie) GetDisableHttpOnly() bool {
+	if x != nil {
+		return x.DisableHttpOnly
+	}
+	return false
+}
+
+func (x *StickyCookie) GetSameSite() StickyCookie_SameSite {
+	if x != nil {
+		return x.SameSite
+	}
+	return StickyCookie_LAX
+}
+
+func (x *StickyCookie) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type Maintenance struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Enabled bool                   `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// message of the response, used as the grpc-message for grpc endpoints.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// body of the http response, default is the message.
+	Body          string               `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	ContentType   string               `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	RetryAfter    *durationpb.Duration `protobuf:"bytes,5,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Maintenance) Reset() {
+	*x = Maintenance{}
+	mi := &file_config_v1_gateway_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Maintenance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Maintenance) ProtoMessage() {}
+
+func (x *Maintenance) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Maintenance.ProtoReflect.Descriptor instead.
+func (*Maintenance) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *Maintenance) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *Maintenance) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Maintenance) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Maintenance) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Maintenance) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+type Middleware struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Options  *anypb.Any             `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	Required bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	// supported by the auth middlewares, eg: jwt and namespace.
+	EnforcementMode EnforcementMode `protobuf:"varint,4,opt,name=enforcement_mode,json=enforcementMode,proto3,enum=goddess.config.v1.EnforcementMode" json:"enforcement_mode,omitempty"`
+	// header of the responses let through in the monitor mode, default is X-Auth-Monitor.
+	MonitorHeader string `protobuf:"bytes,5,opt,name=monitor_header,json=monitorHeader,proto3" json:"monitor_header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Middleware) Reset() {
+	*x = Middleware{}
+	mi := &file_config_v1_gateway_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Middleware) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Middleware) ProtoMessage() {}
+
+func (x *Middleware) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Middleware.ProtoReflect.Descriptor instead.
+func (*Middleware) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *Middleware) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Middleware) GetOptions() *anypb.Any {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *Middleware) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *Middleware) GetEnforcementMode() EnforcementMode {
+	if x != nil {
+		return x.EnforcementMode
+	}
+	return EnforcementMode_ENFORCE
+}
+
+func (x *Middleware) GetMonitorHeader() string {
+	if x != nil {
+		return x.MonitorHeader
+	}
+	return ""
+}
+
+type Backend struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// localhost
+	// 127.0.0.1:8000
+	// discovery:///service_name
+	Target        string            `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Weight        *int64            `protobuf:"varint,2,opt,name=weight,proto3,oneof" json:"weight,omitempty"`
+	HealthCheck   *HealthCheck      `protobuf:"bytes,3,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
+	Tls           bool              `protobuf:"varint,4,opt,name=tls,proto3" json:"tls,omitempty"`
+	TlsConfigName string            `protobuf:"bytes,5,opt,name=tls_config_name,json=tlsConfigName,proto3" json:"tls_config_name,omitempty"`
+	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// the subset of the nodes of this backend selected by the metadata
+	NodeFilters *NodeFilters `protobuf:"bytes,7,opt,name=node_filters,json=nodeFilters,proto3" json:"node_filters,omitempty"`
+	// overrides Endpoint.upstream_path_prefix for the nodes of this backend.
+	UpstreamPathPrefix string `protobuf:"bytes,8,opt,name=upstream_path_prefix,json=upstreamPathPrefix,proto3" json:"upstream_path_prefix,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Backend) Reset() {
+	*x = Backend{}
+	mi := &file_config_v1_gateway_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Backend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Backend) ProtoMessage() {}
+
+func (x *Backend) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
+func (*Backend) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *Backend) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Backend) GetWeight() int64 {
+	if x != nil && x.Weight != nil {
+		return *x.Weight
+	}
+	return 0
+}
+
+func (x *Backend) GetHealthCheck() *HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+func (x *Backend) GetTls() bool {
+	if x != nil {
+		return x.Tls
+	}
+	return false
+}
+
+func (x *Backend) GetTlsConfigName() string {
+	if x != nil {
+		return x.TlsConfigName
+	}
+	return ""
+}
+
+func (x *Backend) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Backend) GetNodeFilters() *NodeFilters {
+	if x != nil {
+		return x.NodeFilters
+	}
+	return nil
+}
+
+func (x *Backend) GetUpstreamPathPrefix() string {
+	if x != nil {
+		return x.UpstreamPathPrefix
+	}
+	return ""
+}
+
+type NodeMatcher struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the metadata key of the node, "version" falls back to the version of the service instance
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// the value equals to
+	Equals string `protobuf:"bytes,2,opt,name=equals,proto3" json:"equals,omitempty"`
+	// the value is one of
+	In            []string `protobuf:"bytes,3,rep,name=in,proto3" json:"in,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeMatcher) Reset() {
+	*x = NodeMatcher{}
+	mi := &file_config_v1_gateway_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeMatcher) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeMatcher) ProtoMessage() {}
+
+func (x *NodeMatcher) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeMatcher.ProtoReflect.Descriptor instead.
+func (*NodeMatcher) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *NodeMatcher) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *NodeMatcher) GetEquals() string {
+	if x != nil {
+		return x.Equals
+	}
+	return ""
+}
+
+func (x *NodeMatcher) GetIn() []string {
+	if x != nil {
+		return x.In
+	}
+	return nil
+}
+
+type NodeFilters struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// the node is selected if all the matchers match
+	Matchers      []*NodeMatcher        `protobuf:"bytes,1,rep,name=matchers,proto3" json:"matchers,omitempty"`
+	OnNoMatch     NodeFilters_OnNoMatch `protobuf:"varint,2,opt,name=on_no_match,json=onNoMatch,proto3,enum=goddess.config.v1.NodeFilters_OnNoMatch" json:"on_no_match,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeFilters) Reset() {
+	*x = NodeFilters{}
+	mi := &file_config_v1_gateway_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeFilters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeFilters) ProtoMessage() {}
+
+func (x *NodeFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeFilters.ProtoReflect.Descriptor instead.
+func (*NodeFilters) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *NodeFilters) GetMatchers() []*NodeMatcher {
+	if x != nil {
+		return x.Matchers
+	}
+	return nil
+}
+
+func (x *NodeFilters) GetOnNoMatch() NodeFilters_OnNoMatch {
+	if x != nil {
+		return x.OnNoMatch
+	}
+	return NodeFilters_ERROR
+}
+
+type HealthCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	mi := &file_config_v1_gateway_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheck) ProtoMessage() {}
+
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-type TLS struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Insecure      bool                   `protobuf:"varint,1,opt,name=insecure,proto3" json:"insecure,omitempty"`
-	Cacert        string                 `protobuf:"bytes,2,opt,name=cacert,proto3" json:"cacert,omitempty"`
-	Cert          string                 `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"`
-	Key           string                 `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	ServerName    string                 `protobuf:"bytes,5,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{45}
+}
+
+type Retry struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// default attempts is 1
+	Attempts uint32 `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	// zero means no per-attempt timeout, only the overall timeout applies.
+	PerTryTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=per_try_timeout,json=perTryTimeout,proto3" json:"per_try_timeout,omitempty"`
+	Conditions    []*Condition         `protobuf:"bytes,3,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	// primary,secondary
+	Priorities         []string                 `protobuf:"bytes,4,rep,name=priorities,proto3" json:"priorities,omitempty"`
+	AttemptTimeoutMode Retry_AttemptTimeoutMode `protobuf:"varint,5,opt,name=attempt_timeout_mode,json=attemptTimeoutMode,proto3,enum=goddess.config.v1.Retry_AttemptTimeoutMode" json:"attempt_timeout_mode,omitempty"`
+	// the response when all the attempts failed
+	OnExhaustion  Retry_OnExhaustion `protobuf:"varint,6,opt,name=on_exhaustion,json=onExhaustion,proto3,enum=goddess.config.v1.Retry_OnExhaustion" json:"on_exhaustion,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TLS) Reset() {
-	*x = TLS{}
-	mi := &file_config_v1_gateway_proto_msgTypes[1]
+func (x *Retry) Reset() {
+	*x = Retry{}
+	mi := &file_config_v1_gateway_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TLS) String() string {
+func (x *Retry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TLS) ProtoMessage() {}
+func (*Retry) ProtoMessage() {}
 
-func (x *TLS) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[1]
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -202,70 +4662,80 @@ func (x *TLS) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TLS.ProtoReflect.Descriptor instead.
-func (*TLS) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *TLS) GetInsecure() bool {
+func (x *Retry) GetAttempts() uint32 {
 	if x != nil {
-		return x.Insecure
+		return x.Attempts
 	}
-	return false
+	return 0
 }
 
-func (x *TLS) GetCacert() string {
+func (x *Retry) GetPerTryTimeout() *durationpb.Duration {
 	if x != nil {
-		return x.Cacert
+		return x.PerTryTimeout
 	}
-	return ""
+	return nil
 }
 
-func (x *TLS) GetCert() string {
+func (x *Retry) GetConditions() []*Condition {
 	if x != nil {
-		return x.Cert
+		return x.Conditions
 	}
-	return ""
+	return nil
 }
 
-func (x *TLS) GetKey() string {
+func (x *Retry) GetPriorities() []string {
 	if x != nil {
-		return x.Key
+		return x.Priorities
 	}
-	return ""
+	return nil
 }
 
-func (x *TLS) GetServerName() string {
+func (x *Retry) GetAttemptTimeoutMode() Retry_AttemptTimeoutMode {
 	if x != nil {
-		return x.ServerName
+		return x.AttemptTimeoutMode
 	}
-	return ""
+	return Retry_PER_TRY
 }
 
-type PriorityConfig struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Endpoints     []*Endpoint            `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+func (x *Retry) GetOnExhaustion() Retry_OnExhaustion {
+	if x != nil {
+		return x.OnExhaustion
+	}
+	return Retry_ON_EXHAUSTION_UNSPECIFIED
+}
+
+type Condition struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Condition:
+	//
+	//	*Condition_ByStatusCode
+	//	*Condition_ByHeader
+	//	*Condition_ByBodyContains
+	Condition     isCondition_Condition `protobuf_oneof:"condition"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PriorityConfig) Reset() {
-	*x = PriorityConfig{}
-	mi := &file_config_v1_gateway_proto_msgTypes[2]
+func (x *Condition) Reset() {
+	*x = Condition{}
+	mi := &file_config_v1_gateway_proto_msgTypes[47]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PriorityConfig) String() string {
+func (x *Condition) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PriorityConfig) ProtoMessage() {}
+func (*Condition) ProtoMessage() {}
 
-func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[2]
+func (x *Condition) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[47]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -276,65 +4746,95 @@ func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PriorityConfig.ProtoReflect.Descriptor instead.
-func (*PriorityConfig) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use Condition.ProtoReflect.Descriptor instead.
+func (*Condition) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{47}
 }
 
-func (x *PriorityConfig) GetName() string {
+func (x *Condition) GetCondition() isCondition_Condition {
 	if x != nil {
-		return x.Name
+		return x.Condition
 	}
-	return ""
+	return nil
 }
 
-func (x *PriorityConfig) GetVersion() string {
+func (x *Condition) GetByStatusCode() string {
 	if x != nil {
-		return x.Version
+		if x, ok := x.Condition.(*Condition_ByStatusCode); ok {
+			return x.ByStatusCode
+		}
 	}
 	return ""
 }
 
-func (x *PriorityConfig) GetEndpoints() []*Endpoint {
+func (x *Condition) GetByHeader() *ConditionHeader {
 	if x != nil {
-		return x.Endpoints
+		if x, ok := x.Condition.(*Condition_ByHeader); ok {
+			return x.ByHeader
+		}
 	}
 	return nil
 }
 
-type Endpoint struct {
-	state       protoimpl.MessageState `protogen:"open.v1"`
-	Path        string                 `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Method      string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
-	Description string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Protocol    Protocol               `protobuf:"varint,4,opt,name=protocol,proto3,enum=goddess.config.v1.Protocol" json:"protocol,omitempty"`
-	Timeout     *durationpb.Duration   `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
-	Middlewares []*Middleware          `protobuf:"bytes,6,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
-	Backends    []*Backend             `protobuf:"bytes,7,rep,name=backends,proto3" json:"backends,omitempty"`
-	Retry       *Retry                 `protobuf:"bytes,8,opt,name=retry,proto3" json:"retry,omitempty"`
-	Metadata    map[string]string      `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Host        string                 `protobuf:"bytes,10,opt,name=host,proto3" json:"host,omitempty"`
-	// stream endpoints are used to support bidirectional request/response like websocket or grpc stream.
-	Stream        bool `protobuf:"varint,11,opt,name=stream,proto3" json:"stream,omitempty"`
+func (x *Condition) GetByBodyContains() *ConditionBodyContains {
+	if x != nil {
+		if x, ok := x.Condition.(*Condition_ByBodyContains); ok {
+			return x.ByBodyContains
+		}
+	}
+	return nil
+}
+
+type isCondition_Condition interface {
+	isCondition_Condition()
+}
+
+type Condition_ByStatusCode struct {
+	// "500-599", "429"
+	ByStatusCode string `protobuf:"bytes,1,opt,name=by_status_code,json=byStatusCode,proto3,oneof"`
+}
+
+type Condition_ByHeader struct {
+	// {"name": "grpc-status", "value": "14"}
+	ByHeader *ConditionHeader `protobuf:"bytes,2,opt,name=by_header,json=byHeader,proto3,oneof"`
+}
+
+type Condition_ByBodyContains struct {
+	// {"pattern": "RATE_LIMITED", "max_bytes": 1024}
+	ByBodyContains *ConditionBodyContains `protobuf:"bytes,3,opt,name=by_body_contains,json=byBodyContains,proto3,oneof"`
+}
+
+func (*Condition_ByStatusCode) isCondition_Condition() {}
+
+func (*Condition_ByHeader) isCondition_Condition() {}
+
+func (*Condition_ByBodyContains) isCondition_Condition() {}
+
+type UpstreamDebugHeaders struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// request header that triggers the debug response headers, eg: X-Gateway-Debug
+	Header string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// trusted values of the request header, required if header is set.
+	Values        []string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Endpoint) Reset() {
-	*x = Endpoint{}
-	mi := &file_config_v1_gateway_proto_msgTypes[3]
+func (x *UpstreamDebugHeaders) Reset() {
+	*x = UpstreamDebugHeaders{}
+	mi := &file_config_v1_gateway_proto_msgTypes[48]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Endpoint) String() string {
+func (x *UpstreamDebugHeaders) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Endpoint) ProtoMessage() {}
+func (*UpstreamDebugHeaders) ProtoMessage() {}
 
-func (x *Endpoint) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[3]
+func (x *UpstreamDebugHeaders) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[48]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -345,178 +4845,184 @@ func (x *Endpoint) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
-func (*Endpoint) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use UpstreamDebugHeaders.ProtoReflect.Descriptor instead.
+func (*UpstreamDebugHeaders) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{48}
 }
 
-func (x *Endpoint) GetPath() string {
+func (x *UpstreamDebugHeaders) GetHeader() string {
 	if x != nil {
-		return x.Path
+		return x.Header
 	}
 	return ""
 }
 
-func (x *Endpoint) GetMethod() string {
+func (x *UpstreamDebugHeaders) GetValues() []string {
 	if x != nil {
-		return x.Method
+		return x.Values
 	}
-	return ""
+	return nil
 }
 
-func (x *Endpoint) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+type Admission struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// max concurrent requests admitted into the endpoint handler.
+	MaxConcurrency uint32 `protobuf:"varint,1,opt,name=max_concurrency,json=maxConcurrency,proto3" json:"max_concurrency,omitempty"`
+	// max requests waiting for admission across all classes.
+	QueueSize uint32 `protobuf:"varint,2,opt,name=queue_size,json=queueSize,proto3" json:"queue_size,omitempty"`
+	// Types that are valid to be assigned to Classifier:
+	//
+	//	*Admission_Header
+	//	*Admission_JwtClaim
+	Classifier isAdmission_Classifier `protobuf_oneof:"classifier"`
+	// priority classes ordered from the highest to the lowest.
+	Classes []*PriorityClass `protobuf:"bytes,5,rep,name=classes,proto3" json:"classes,omitempty"`
+	// class used when the request attribute does not match any class, default is the lowest class.
+	DefaultClass  string `protobuf:"bytes,6,opt,name=default_class,json=defaultClass,proto3" json:"default_class,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Endpoint) GetProtocol() Protocol {
-	if x != nil {
-		return x.Protocol
-	}
-	return Protocol_UNSPECIFIED
+func (x *Admission) Reset() {
+	*x = Admission{}
+	mi := &file_config_v1_gateway_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *Endpoint) GetTimeout() *durationpb.Duration {
-	if x != nil {
-		return x.Timeout
-	}
-	return nil
+func (x *Admission) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func (x *Endpoint) GetMiddlewares() []*Middleware {
-	if x != nil {
-		return x.Middlewares
-	}
-	return nil
-}
+func (*Admission) ProtoMessage() {}
 
-func (x *Endpoint) GetBackends() []*Backend {
+func (x *Admission) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[49]
 	if x != nil {
-		return x.Backends
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *Endpoint) GetRetry() *Retry {
-	if x != nil {
-		return x.Retry
-	}
-	return nil
+// Deprecated: Use Admission.ProtoReflect.Descriptor instead.
+func (*Admission) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{49}
 }
 
-func (x *Endpoint) GetMetadata() map[string]string {
+func (x *Admission) GetMaxConcurrency() uint32 {
 	if x != nil {
-		return x.Metadata
+		return x.MaxConcurrency
 	}
-	return nil
+	return 0
 }
 
-func (x *Endpoint) GetHost() string {
+func (x *Admission) GetQueueSize() uint32 {
 	if x != nil {
-		return x.Host
+		return x.QueueSize
 	}
-	return ""
+	return 0
 }
 
-func (x *Endpoint) GetStream() bool {
+func (x *Admission) GetClassifier() isAdmission_Classifier {
 	if x != nil {
-		return x.Stream
+		return x.Classifier
 	}
-	return false
-}
-
-type Middleware struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Options       *anypb.Any             `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
-	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *Middleware) Reset() {
-	*x = Middleware{}
-	mi := &file_config_v1_gateway_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *Middleware) String() string {
-	return protoimpl.X.MessageStringOf(x)
+	return nil
 }
 
-func (*Middleware) ProtoMessage() {}
-
-func (x *Middleware) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[4]
+func (x *Admission) GetHeader() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
+		if x, ok := x.Classifier.(*Admission_Header); ok {
+			return x.Header
 		}
-		return ms
 	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use Middleware.ProtoReflect.Descriptor instead.
-func (*Middleware) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{4}
+	return ""
 }
 
-func (x *Middleware) GetName() string {
+func (x *Admission) GetJwtClaim() string {
 	if x != nil {
-		return x.Name
+		if x, ok := x.Classifier.(*Admission_JwtClaim); ok {
+			return x.JwtClaim
+		}
 	}
 	return ""
 }
 
-func (x *Middleware) GetOptions() *anypb.Any {
+func (x *Admission) GetClasses() []*PriorityClass {
 	if x != nil {
-		return x.Options
+		return x.Classes
 	}
 	return nil
 }
 
-func (x *Middleware) GetRequired() bool {
+func (x *Admission) GetDefaultClass() string {
 	if x != nil {
-		return x.Required
+		return x.DefaultClass
 	}
-	return false
+	return ""
 }
 
-type Backend struct {
+type isAdmission_Classifier interface {
+	isAdmission_Classifier()
+}
+
+type Admission_Header struct {
+	// request header used to classify requests, eg: X-Priority
+	Header string `protobuf:"bytes,3,opt,name=header,proto3,oneof"`
+}
+
+type Admission_JwtClaim struct {
+	// jwt claim used to classify requests, read from the bearer token without verification.
+	JwtClaim string `protobuf:"bytes,4,opt,name=jwt_claim,json=jwtClaim,proto3,oneof"`
+}
+
+func (*Admission_Header) isAdmission_Classifier() {}
+
+func (*Admission_JwtClaim) isAdmission_Classifier() {}
+
+// AIMD on the concurrency limit of the endpoint: the limit grows by one each healthy window and is
+// multiplied by the backoff ratio when the latency or the error rate of the window exceeds the target.
+type AdaptiveConcurrency struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// localhost
-	// 127.0.0.1:8000
-	// discovery:///service_name
-	Target        string            `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
-	Weight        *int64            `protobuf:"varint,2,opt,name=weight,proto3,oneof" json:"weight,omitempty"`
-	HealthCheck   *HealthCheck      `protobuf:"bytes,3,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
-	Tls           bool              `protobuf:"varint,4,opt,name=tls,proto3" json:"tls,omitempty"`
-	TlsConfigName string            `protobuf:"bytes,5,opt,name=tls_config_name,json=tlsConfigName,proto3" json:"tls_config_name,omitempty"`
-	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// default is 1
+	MinLimit uint32 `protobuf:"varint,1,opt,name=min_limit,json=minLimit,proto3" json:"min_limit,omitempty"`
+	// the initial limit, required.
+	MaxLimit uint32 `protobuf:"varint,2,opt,name=max_limit,json=maxLimit,proto3" json:"max_limit,omitempty"`
+	// the average latency above which the limit decreases, ignored if not set.
+	TargetLatency *durationpb.Duration `protobuf:"bytes,3,opt,name=target_latency,json=targetLatency,proto3" json:"target_latency,omitempty"`
+	// the error rate above which the limit decreases, eg: 0.1, ignored if zero.
+	MaxErrorRate float64 `protobuf:"fixed64,4,opt,name=max_error_rate,json=maxErrorRate,proto3" json:"max_error_rate,omitempty"`
+	// default is 1s
+	Window *durationpb.Duration `protobuf:"bytes,5,opt,name=window,proto3" json:"window,omitempty"`
+	// the windows with fewer requests keep the limit, default is 10.
+	MinRequests uint32 `protobuf:"varint,6,opt,name=min_requests,json=minRequests,proto3" json:"min_requests,omitempty"`
+	// default is 0.5
+	BackoffRatio float64 `protobuf:"fixed64,7,opt,name=backoff_ratio,json=backoffRatio,proto3" json:"backoff_ratio,omitempty"`
+	// Retry-After of the rejected requests, default is the window.
+	RetryAfter    *durationpb.Duration `protobuf:"bytes,8,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Backend) Reset() {
-	*x = Backend{}
-	mi := &file_config_v1_gateway_proto_msgTypes[5]
+func (x *AdaptiveConcurrency) Reset() {
+	*x = AdaptiveConcurrency{}
+	mi := &file_config_v1_gateway_proto_msgTypes[50]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Backend) String() string {
+func (x *AdaptiveConcurrency) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Backend) ProtoMessage() {}
+func (*AdaptiveConcurrency) ProtoMessage() {}
 
-func (x *Backend) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[5]
+func (x *AdaptiveConcurrency) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[50]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -527,74 +5033,93 @@ func (x *Backend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
-func (*Backend) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use AdaptiveConcurrency.ProtoReflect.Descriptor instead.
+func (*AdaptiveConcurrency) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{50}
 }
 
-func (x *Backend) GetTarget() string {
+func (x *AdaptiveConcurrency) GetMinLimit() uint32 {
 	if x != nil {
-		return x.Target
+		return x.MinLimit
 	}
-	return ""
+	return 0
 }
 
-func (x *Backend) GetWeight() int64 {
-	if x != nil && x.Weight != nil {
-		return *x.Weight
+func (x *AdaptiveConcurrency) GetMaxLimit() uint32 {
+	if x != nil {
+		return x.MaxLimit
 	}
 	return 0
 }
 
-func (x *Backend) GetHealthCheck() *HealthCheck {
+func (x *AdaptiveConcurrency) GetTargetLatency() *durationpb.Duration {
 	if x != nil {
-		return x.HealthCheck
+		return x.TargetLatency
 	}
 	return nil
 }
 
-func (x *Backend) GetTls() bool {
+func (x *AdaptiveConcurrency) GetMaxErrorRate() float64 {
 	if x != nil {
-		return x.Tls
+		return x.MaxErrorRate
 	}
-	return false
+	return 0
 }
 
-func (x *Backend) GetTlsConfigName() string {
+func (x *AdaptiveConcurrency) GetWindow() *durationpb.Duration {
 	if x != nil {
-		return x.TlsConfigName
+		return x.Window
 	}
-	return ""
+	return nil
 }
 
-func (x *Backend) GetMetadata() map[string]string {
+func (x *AdaptiveConcurrency) GetMinRequests() uint32 {
 	if x != nil {
-		return x.Metadata
+		return x.MinRequests
+	}
+	return 0
+}
+
+func (x *AdaptiveConcurrency) GetBackoffRatio() float64 {
+	if x != nil {
+		return x.BackoffRatio
+	}
+	return 0
+}
+
+func (x *AdaptiveConcurrency) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
 	}
 	return nil
 }
 
-type HealthCheck struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+type PriorityClass struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// attribute values mapped to this class.
+	Values []string `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
+	// max time waiting in queue, default is the endpoint timeout.
+	MaxWait       *durationpb.Duration `protobuf:"bytes,3,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheck) Reset() {
-	*x = HealthCheck{}
-	mi := &file_config_v1_gateway_proto_msgTypes[6]
+func (x *PriorityClass) Reset() {
+	*x = PriorityClass{}
+	mi := &file_config_v1_gateway_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheck) String() string {
+func (x *PriorityClass) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheck) ProtoMessage() {}
+func (*PriorityClass) ProtoMessage() {}
 
-func (x *HealthCheck) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[6]
+func (x *PriorityClass) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -605,38 +5130,59 @@ func (x *HealthCheck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
-func (*HealthCheck) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use PriorityClass.ProtoReflect.Descriptor instead.
+func (*PriorityClass) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{51}
 }
 
-type Retry struct {
+func (x *PriorityClass) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PriorityClass) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+func (x *PriorityClass) GetMaxWait() *durationpb.Duration {
+	if x != nil {
+		return x.MaxWait
+	}
+	return nil
+}
+
+type FallbackAction_Static struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// default attempts is 1
-	Attempts      uint32               `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
-	PerTryTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=per_try_timeout,json=perTryTimeout,proto3" json:"per_try_timeout,omitempty"`
-	Conditions    []*Condition         `protobuf:"bytes,3,rep,name=conditions,proto3" json:"conditions,omitempty"`
-	// primary,secondary
-	Priorities    []string `protobuf:"bytes,4,rep,name=priorities,proto3" json:"priorities,omitempty"`
+	// default is the status code of the fallback, eg: 404
+	Status  int32             `protobuf:"varint,1,opt,name=status,proto3" json:"status,omitempty"`
+	Headers map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// text/template with .Method, .Host, .Path, .Query and .Status, the request values are not escaped,
+	// json quotes them into the json bodies, eg: {"path": {{ json .Path }}}
+	Body          string `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Retry) Reset() {
-	*x = Retry{}
-	mi := &file_config_v1_gateway_proto_msgTypes[7]
+func (x *FallbackAction_Static) Reset() {
+	*x = FallbackAction_Static{}
+	mi := &file_config_v1_gateway_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Retry) String() string {
+func (x *FallbackAction_Static) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Retry) ProtoMessage() {}
+func (*FallbackAction_Static) ProtoMessage() {}
 
-func (x *Retry) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[7]
+func (x *FallbackAction_Static) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -647,65 +5193,57 @@ func (x *Retry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
-func (*Retry) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use FallbackAction_Static.ProtoReflect.Descriptor instead.
+func (*FallbackAction_Static) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{13, 0}
 }
 
-func (x *Retry) GetAttempts() uint32 {
+func (x *FallbackAction_Static) GetStatus() int32 {
 	if x != nil {
-		return x.Attempts
+		return x.Status
 	}
 	return 0
 }
 
-func (x *Retry) GetPerTryTimeout() *durationpb.Duration {
-	if x != nil {
-		return x.PerTryTimeout
-	}
-	return nil
-}
-
-func (x *Retry) GetConditions() []*Condition {
+func (x *FallbackAction_Static) GetHeaders() map[string]string {
 	if x != nil {
-		return x.Conditions
+		return x.Headers
 	}
 	return nil
 }
 
-func (x *Retry) GetPriorities() []string {
+func (x *FallbackAction_Static) GetBody() string {
 	if x != nil {
-		return x.Priorities
+		return x.Body
 	}
-	return nil
+	return ""
 }
 
-type Condition struct {
+type FallbackAction_Redirect struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Types that are valid to be assigned to Condition:
-	//
-	//	*Condition_ByStatusCode
-	//	*Condition_ByHeader
-	Condition     isCondition_Condition `protobuf_oneof:"condition"`
+	// text/template with .Method, .Host, .Path, .Query and .Status
+	Url string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	// default is 302
+	Status        int32 `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Condition) Reset() {
-	*x = Condition{}
-	mi := &file_config_v1_gateway_proto_msgTypes[8]
+func (x *FallbackAction_Redirect) Reset() {
+	*x = FallbackAction_Redirect{}
+	mi := &file_config_v1_gateway_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Condition) String() string {
+func (x *FallbackAction_Redirect) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Condition) ProtoMessage() {}
+func (*FallbackAction_Redirect) ProtoMessage() {}
 
-func (x *Condition) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[8]
+func (x *FallbackAction_Redirect) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -716,65 +5254,38 @@ func (x *Condition) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Condition.ProtoReflect.Descriptor instead.
-func (*Condition) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{8}
-}
-
-func (x *Condition) GetCondition() isCondition_Condition {
-	if x != nil {
-		return x.Condition
-	}
-	return nil
+// Deprecated: Use FallbackAction_Redirect.ProtoReflect.Descriptor instead.
+func (*FallbackAction_Redirect) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{13, 1}
 }
 
-func (x *Condition) GetByStatusCode() string {
+func (x *FallbackAction_Redirect) GetUrl() string {
 	if x != nil {
-		if x, ok := x.Condition.(*Condition_ByStatusCode); ok {
-			return x.ByStatusCode
-		}
+		return x.Url
 	}
 	return ""
 }
 
-func (x *Condition) GetByHeader() *ConditionHeader {
+func (x *FallbackAction_Redirect) GetStatus() int32 {
 	if x != nil {
-		if x, ok := x.Condition.(*Condition_ByHeader); ok {
-			return x.ByHeader
-		}
+		return x.Status
 	}
-	return nil
-}
-
-type isCondition_Condition interface {
-	isCondition_Condition()
-}
-
-type Condition_ByStatusCode struct {
-	// "500-599", "429"
-	ByStatusCode string `protobuf:"bytes,1,opt,name=by_status_code,json=byStatusCode,proto3,oneof"`
-}
-
-type Condition_ByHeader struct {
-	// {"name": "grpc-status", "value": "14"}
-	ByHeader *ConditionHeader `protobuf:"bytes,2,opt,name=by_header,json=byHeader,proto3,oneof"`
+	return 0
 }
 
-func (*Condition_ByStatusCode) isCondition_Condition() {}
-
-func (*Condition_ByHeader) isCondition_Condition() {}
-
 type ConditionHeader struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// matches the header value by regular expression instead of the exact value.
+	ValueRegex    string `protobuf:"bytes,3,opt,name=value_regex,json=valueRegex,proto3" json:"value_regex,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ConditionHeader) Reset() {
 	*x = ConditionHeader{}
-	mi := &file_config_v1_gateway_proto_msgTypes[12]
+	mi := &file_config_v1_gateway_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -786,7 +5297,7 @@ func (x *ConditionHeader) String() string {
 func (*ConditionHeader) ProtoMessage() {}
 
 func (x *ConditionHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_config_v1_gateway_proto_msgTypes[12]
+	mi := &file_config_v1_gateway_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -799,7 +5310,7 @@ func (x *ConditionHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConditionHeader.ProtoReflect.Descriptor instead.
 func (*ConditionHeader) Descriptor() ([]byte, []int) {
-	return file_config_v1_gateway_proto_rawDescGZIP(), []int{8, 0}
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{47, 0}
 }
 
 func (x *ConditionHeader) GetName() string {
@@ -816,6 +5327,66 @@ func (x *ConditionHeader) GetValue() string {
 	return ""
 }
 
+func (x *ConditionHeader) GetValueRegex() string {
+	if x != nil {
+		return x.ValueRegex
+	}
+	return ""
+}
+
+type ConditionBodyContains struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Pattern string                 `protobuf:"bytes,1,opt,name=pattern,proto3" json:"pattern,omitempty"`
+	// only the first max_bytes of the response body are inspected, default is 4096.
+	MaxBytes      uint32 `protobuf:"varint,2,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConditionBodyContains) Reset() {
+	*x = ConditionBodyContains{}
+	mi := &file_config_v1_gateway_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConditionBodyContains) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConditionBodyContains) ProtoMessage() {}
+
+func (x *ConditionBodyContains) ProtoReflect() protoreflect.Message {
+	mi := &file_config_v1_gateway_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConditionBodyContains.ProtoReflect.Descriptor instead.
+func (*ConditionBodyContains) Descriptor() ([]byte, []int) {
+	return file_config_v1_gateway_proto_rawDescGZIP(), []int{47, 1}
+}
+
+func (x *ConditionBodyContains) GetPattern() string {
+	if x != nil {
+		return x.Pattern
+	}
+	return ""
+}
+
+func (x *ConditionBodyContains) GetMaxBytes() uint32 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
 var File_config_v1_gateway_proto protoreflect.FileDescriptor
 
 var file_config_v1_gateway_proto_rawDesc = []byte{
@@ -825,145 +5396,1009 @@ var file_config_v1_gateway_proto_rawDesc = []byte{
 	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e,
 	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
 	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
-	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65,
-	0x72, 0x79, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa8, 0x03, 0x0a, 0x07, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61,
-	0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
-	0x18, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x42, 0x02,
-	0x18, 0x01, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e, 0x64,
-	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67,
-	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
-	0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f,
-	0x69, 0x6e, 0x74, 0x73, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61,
-	0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x64, 0x64,
-	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69,
-	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
-	0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x45, 0x0a, 0x09, 0x74, 0x6c, 0x73, 0x5f, 0x73, 0x74, 0x6f,
-	0x72, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
-	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x74,
-	0x65, 0x77, 0x61, 0x79, 0x2e, 0x54, 0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x08, 0x74, 0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3d, 0x0a, 0x09,
-	0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76,
-	0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79,
-	0x52, 0x09, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x1a, 0x53, 0x0a, 0x0d, 0x54,
-	0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
-	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c,
-	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e,
+	0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1c, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76,
+	0x65, 0x72, 0x79, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xb5, 0x0d, 0x0a, 0x07, 0x47, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x18, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x42,
+	0x02, 0x18, 0x01, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
 	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
-	0x31, 0x2e, 0x54, 0x4c, 0x53, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
-	0x22, 0x80, 0x01, 0x0a, 0x03, 0x54, 0x4c, 0x53, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x65,
-	0x63, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x65,
-	0x63, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04,
-	0x63, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x72, 0x74,
-	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
-	0x65, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d,
-	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e,
-	0x61, 0x6d, 0x65, 0x22, 0x79, 0x0a, 0x0e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x43,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72,
-	0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73,
-	0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73,
-	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f,
-	0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x9f,
-	0x04, 0x0a, 0x08, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70,
-	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12,
-	0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
-	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
-	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x08, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67, 0x6f,
+	0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x73, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77,
+	0x61, 0x72, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c,
+	0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x45, 0x0a, 0x09, 0x74, 0x6c, 0x73, 0x5f, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x54, 0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x52, 0x08, 0x74, 0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x3d, 0x0a,
+	0x09, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x64, 0x69, 0x73, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72,
+	0x79, 0x52, 0x09, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x79, 0x12, 0x50, 0x0a, 0x11,
+	0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x64, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x77,
+	0x61, 0x72, 0x64, 0x65, 0x64, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52, 0x10, 0x66, 0x6f,
+	0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x64, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x37,
+	0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x08, 0x66,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x18, 0x0a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x30, 0x0a, 0x14, 0x72,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x64,
+	0x65, 0x6e, 0x79, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x65, 0x6e, 0x79, 0x12, 0x34, 0x0a,
+	0x07, 0x70, 0x72, 0x65, 0x77, 0x61, 0x72, 0x6d, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x72, 0x65, 0x77, 0x61, 0x72, 0x6d, 0x52, 0x07, 0x70, 0x72, 0x65, 0x77,
+	0x61, 0x72, 0x6d, 0x12, 0x3d, 0x0a, 0x0b, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x6f, 0x73,
+	0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x0a, 0x68, 0x6f, 0x73, 0x74, 0x47, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x12, 0x59, 0x0a, 0x14, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x70,
+	0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x26, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x50, 0x72, 0x6f,
+	0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x13, 0x64, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x44, 0x0a,
+	0x0d, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x5f, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x18, 0x0f,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x42,
+	0x75, 0x64, 0x67, 0x65, 0x74, 0x52, 0x0c, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x42, 0x75, 0x64,
+	0x67, 0x65, 0x74, 0x12, 0x54, 0x0a, 0x0e, 0x68, 0x6f, 0x73, 0x74, 0x5f, 0x6f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x73, 0x18, 0x10, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x67, 0x6f,
 	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63,
-	0x6f, 0x6c, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20,
+	0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x68, 0x6f, 0x73, 0x74,
+	0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x12, 0x41, 0x0a, 0x0d, 0x64, 0x6e, 0x73,
+	0x5f, 0x63, 0x61, 0x63, 0x68, 0x65, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x02, 0x18, 0x01, 0x52,
+	0x0b, 0x64, 0x6e, 0x73, 0x43, 0x61, 0x63, 0x68, 0x65, 0x54, 0x74, 0x6c, 0x12, 0x37, 0x0a, 0x08,
+	0x72, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b,
+	0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x52, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x6c, 0x76, 0x65, 0x72, 0x12, 0x39, 0x0a, 0x09, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x73, 0x18, 0x13, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x52, 0x09, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x73,
+	0x12, 0x44, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x73, 0x18, 0x14, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x0c, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x73, 0x18, 0x15, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x52, 0x08, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x3e,
+	0x0a, 0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x18, 0x16, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65,
+	0x72, 0x74, 0x52, 0x0a, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x12, 0x41,
+	0x0a, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x17,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50,
+	0x72, 0x6f, 0x78, 0x79, 0x52, 0x0b, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x6f, 0x78,
+	0x79, 0x12, 0x4d, 0x0a, 0x10, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x68, 0x65,
+	0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x52,
+	0x0f, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
+	0x12, 0x44, 0x0a, 0x0d, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x0c, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x1a, 0x53, 0x0a, 0x0d, 0x54, 0x6c, 0x73, 0x53, 0x74, 0x6f,
+	0x72, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x4c, 0x53,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x61, 0x0a, 0x12, 0x48,
+	0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x35, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x8c,
+	0x01, 0x0a, 0x0a, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x12, 0x1b, 0x0a,
+	0x09, 0x63, 0x6e, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x63, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x70,
+	0x69, 0x66, 0x66, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12,
+	0x1d, 0x0a, 0x0a, 0x73, 0x61, 0x6e, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1d,
+	0x0a, 0x0a, 0x70, 0x65, 0x6d, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x65, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x81, 0x01,
+	0x0a, 0x10, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43, 0x65, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x73, 0x61, 0x6e, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x53, 0x61, 0x6e,
+	0x73, 0x12, 0x2e, 0x0a, 0x13, 0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x5f, 0x74, 0x72, 0x75, 0x73,
+	0x74, 0x5f, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11,
+	0x73, 0x70, 0x69, 0x66, 0x66, 0x65, 0x54, 0x72, 0x75, 0x73, 0x74, 0x44, 0x6f, 0x6d, 0x61, 0x69,
+	0x6e, 0x22, 0xbb, 0x04, 0x0a, 0x07, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x37, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x73, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x04, 0x20,
 	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07,
-	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c,
 	0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67,
 	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
 	0x2e, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64,
-	0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b,
-	0x65, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64,
-	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42,
-	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73,
-	0x12, 0x2e, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x18, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79,
-	0x12, 0x45, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x09, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x29, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
-	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e,
-	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18,
-	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73,
-	0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x72,
-	0x65, 0x61, 0x6d, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x50,
+	0x0a, 0x11, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x63, 0x6f, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x10,
+	0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x12, 0x30, 0x0a, 0x14, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x70, 0x61, 0x74,
+	0x68, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12,
+	0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x61, 0x74, 0x68, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
+	0xa6, 0x02, 0x0a, 0x08, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x34, 0x0a, 0x04, 0x6d, 0x6f,
+	0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x12, 0x3b, 0x0a, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x73, 0x73, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x52, 0x06, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x73, 0x12, 0x46, 0x0a,
+	0x11, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x10, 0x68, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0x31, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a,
+	0x10, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45,
+	0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x4c, 0x53, 0x5f, 0x50, 0x41, 0x53, 0x53, 0x54,
+	0x48, 0x52, 0x4f, 0x55, 0x47, 0x48, 0x10, 0x01, 0x22, 0xc3, 0x02, 0x0a, 0x10, 0x50, 0x61, 0x73,
+	0x73, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x52, 0x6f, 0x75, 0x74, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12,
+	0x3c, 0x0a, 0x0c, 0x69, 0x64, 0x6c, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0b, 0x69, 0x64, 0x6c, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x51, 0x0a,
+	0x17, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x15, 0x6d, 0x61, 0x78, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x3c, 0x0a, 0x0c, 0x64, 0x69, 0x61, 0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x0b, 0x64, 0x69, 0x61, 0x6c, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22, 0xb5,
+	0x02, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x72, 0x12, 0x20, 0x0a, 0x0b, 0x6e,
+	0x61, 0x6d, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x0b, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x73, 0x12, 0x40, 0x0a,
+	0x0e, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0d, 0x6c, 0x6f, 0x6f, 0x6b, 0x75, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12,
+	0x32, 0x0a, 0x07, 0x6d, 0x69, 0x6e, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6d, 0x69, 0x6e,
+	0x54, 0x74, 0x6c, 0x12, 0x32, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x06, 0x6d, 0x61, 0x78, 0x54, 0x74, 0x6c, 0x12, 0x3c, 0x0a, 0x0c, 0x6e, 0x65, 0x67, 0x61, 0x74,
+	0x69, 0x76, 0x65, 0x5f, 0x74, 0x74, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x6e, 0x65, 0x67, 0x61, 0x74, 0x69,
+	0x76, 0x65, 0x54, 0x74, 0x6c, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x6f, 0x75, 0x6e, 0x64, 0x5f, 0x72,
+	0x6f, 0x62, 0x69, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x72, 0x6f, 0x75, 0x6e,
+	0x64, 0x52, 0x6f, 0x62, 0x69, 0x6e, 0x22, 0x2c, 0x0a, 0x0c, 0x48, 0x6f, 0x73, 0x74, 0x4f, 0x76,
+	0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x61, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x65, 0x73, 0x22, 0xc3, 0x01, 0x0a, 0x0c, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x42,
+	0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x37, 0x0a, 0x18, 0x6d, 0x61, 0x78, 0x5f, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x15, 0x6d, 0x61, 0x78, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x3e,
+	0x0a, 0x0d, 0x71, 0x75, 0x65, 0x75, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0c, 0x71, 0x75, 0x65, 0x75, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x3a,
+	0x0a, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a,
+	0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0x85, 0x01, 0x0a, 0x13, 0x44,
+	0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x06, 0x6d, 0x61,
+	0x72, 0x67, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6d, 0x61, 0x72, 0x67, 0x69, 0x6e, 0x12, 0x23, 0x0a,
+	0x0d, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x69, 0x64, 0x72, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x43, 0x69, 0x64,
+	0x72, 0x73, 0x22, 0xfa, 0x02, 0x0a, 0x09, 0x48, 0x6f, 0x73, 0x74, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69,
+	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b,
+	0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x66,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x08, 0x66, 0x61, 0x6c, 0x6c,
+	0x62, 0x61, 0x63, 0x6b, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x65, 0x74,
+	0x72, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74,
+	0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x30, 0x0a,
+	0x14, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x5f, 0x64, 0x65, 0x6e, 0x79, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x65, 0x6e, 0x79, 0x22,
+	0x9d, 0x01, 0x0a, 0x07, 0x50, 0x72, 0x65, 0x77, 0x61, 0x72, 0x6d, 0x12, 0x23, 0x0a, 0x0d, 0x61,
+	0x6c, 0x6c, 0x5f, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x61, 0x6c, 0x6c, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73,
+	0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x06, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x22,
+	0xb0, 0x02, 0x0a, 0x08, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x12, 0x3e, 0x0a, 0x09,
+	0x6e, 0x6f, 0x74, 0x5f, 0x66, 0x6f, 0x75, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x41, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x46, 0x6f, 0x75, 0x6e, 0x64, 0x12, 0x4f, 0x0a, 0x12,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6c,
+	0x6c, 0x62, 0x61, 0x63, 0x6b, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x4e, 0x6f, 0x74, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x12, 0x3c, 0x0a,
+	0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x68, 0x6f, 0x73, 0x74, 0x73, 0x1a, 0x55, 0x0a, 0x0a, 0x48,
+	0x6f, 0x73, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46,
+	0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xdd, 0x03, 0x0a, 0x0e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x42, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61,
+	0x63, 0x6b, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x48,
+	0x00, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x48, 0x0a, 0x08, 0x72, 0x65, 0x64,
+	0x69, 0x72, 0x65, 0x63, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x52,
+	0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x48, 0x00, 0x52, 0x08, 0x72, 0x65, 0x64, 0x69, 0x72,
+	0x65, 0x63, 0x74, 0x12, 0x39, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x48, 0x00, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x1a, 0xc1,
+	0x01, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x4f, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x35, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x34, 0x0a, 0x08, 0x52, 0x65, 0x64, 0x69, 0x72, 0x65, 0x63, 0x74, 0x12, 0x10,
+	0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c,
+	0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x42, 0x08, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0xbf, 0x01, 0x0a, 0x10, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x64,
+	0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x3f, 0x0a, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x29, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x77, 0x61,
+	0x72, 0x64, 0x65, 0x64, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x2e, 0x53, 0x74, 0x79, 0x6c,
+	0x65, 0x52, 0x05, 0x73, 0x74, 0x79, 0x6c, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73,
+	0x74, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x78, 0x69, 0x65,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x62, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x62,
+	0x79, 0x22, 0x31, 0x0a, 0x05, 0x53, 0x74, 0x79, 0x6c, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x58, 0x5f,
+	0x46, 0x4f, 0x52, 0x57, 0x41, 0x52, 0x44, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x46,
+	0x4f, 0x52, 0x57, 0x41, 0x52, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x42, 0x4f,
+	0x54, 0x48, 0x10, 0x02, 0x22, 0x80, 0x01, 0x0a, 0x03, 0x54, 0x4c, 0x53, 0x12, 0x1a, 0x0a, 0x08,
+	0x69, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x69, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x65,
+	0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74,
+	0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x63, 0x65, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xdc, 0x01, 0x0a, 0x0e, 0x50, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x12, 0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c,
+	0x12, 0x34, 0x0a, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x72,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x22, 0xfe, 0x01, 0x0a, 0x07, 0x52, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61,
+	0x6d, 0x70, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x3e, 0x0a,
+	0x0d, 0x72, 0x61, 0x6d, 0x70, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0c, 0x72, 0x61, 0x6d, 0x70, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x34, 0x0a,
+	0x05, 0x73, 0x74, 0x65, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x53, 0x74, 0x65, 0x70, 0x52, 0x05, 0x73, 0x74,
+	0x65, 0x70, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x68, 0x61, 0x73, 0x68, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x68, 0x61, 0x73, 0x68, 0x4b, 0x65, 0x79, 0x12, 0x27,
+	0x0a, 0x0f, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x69, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c,
+	0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x58, 0x0a, 0x0b, 0x52, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x53, 0x74, 0x65, 0x70, 0x12, 0x2f, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x22, 0x94, 0x01, 0x0a, 0x0f, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x6f,
+	0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x34, 0x0a, 0x07, 0x72, 0x6f, 0x6c,
+	0x6c, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52,
+	0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x12,
+	0x37, 0x0a, 0x08, 0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08,
+	0x62, 0x61, 0x73, 0x65, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0xf5, 0x17, 0x0a, 0x08, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x33, 0x0a, 0x07,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x64, 0x64, 0x6c,
+	0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
+	0x65, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x07,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x65,
+	0x74, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x45, 0x0a, 0x08, 0x6d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x3a, 0x0a,
+	0x09, 0x61, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x09,
+	0x61, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x5d, 0x0a, 0x16, 0x75, 0x70, 0x73,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x64, 0x65, 0x62, 0x75, 0x67, 0x5f, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x27, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x62, 0x75, 0x67, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x52, 0x14, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x62, 0x75,
+	0x67, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x41, 0x6c, 0x6c, 0x6f, 0x77, 0x12, 0x30, 0x0a, 0x14,
+	0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f,
+	0x64, 0x65, 0x6e, 0x79, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x09, 0x52, 0x12, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x44, 0x65, 0x6e, 0x79, 0x12, 0x40,
+	0x0a, 0x0b, 0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61,
+	0x6e, 0x63, 0x65, 0x52, 0x0b, 0x6d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65,
+	0x12, 0x34, 0x0a, 0x17, 0x6d, 0x61, 0x70, 0x5f, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x5f, 0x74, 0x6f, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x18, 0x11, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x13, 0x6d, 0x61, 0x70, 0x47, 0x72, 0x70, 0x63, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x54, 0x6f, 0x48, 0x74, 0x74, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x77, 0x61, 0x72,
+	0x6d, 0x18, 0x12, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x65, 0x77, 0x61, 0x72, 0x6d,
+	0x12, 0x40, 0x0a, 0x0e, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x18, 0x13, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76,
+	0x61, 0x6c, 0x12, 0x2e, 0x0a, 0x13, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x5f, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x14, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x11, 0x66, 0x6c, 0x75, 0x73, 0x68, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x12, 0x44, 0x0a, 0x0d, 0x73, 0x74, 0x69, 0x63, 0x6b, 0x79, 0x5f, 0x63, 0x6f, 0x6f,
+	0x6b, 0x69, 0x65, 0x18, 0x15, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74,
+	0x69, 0x63, 0x6b, 0x79, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x52, 0x0c, 0x73, 0x74, 0x69, 0x63,
+	0x6b, 0x79, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x68, 0x6f, 0x73, 0x74,
+	0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x16, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x68, 0x6f,
+	0x73, 0x74, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x59, 0x0a, 0x14, 0x64, 0x65, 0x61, 0x64, 0x6c,
+	0x69, 0x6e, 0x65, 0x5f, 0x70, 0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x17, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69,
+	0x6e, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x13, 0x64,
+	0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x50, 0x72, 0x6f, 0x70, 0x61, 0x67, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x50, 0x0a, 0x11, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x18, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x23, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x52, 0x10, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x41, 0x0a, 0x0c, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x19, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4e,
+	0x6f, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x52, 0x0b, 0x6e, 0x6f, 0x64, 0x65,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x75, 0x70, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18,
+	0x1a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50,
+	0x61, 0x74, 0x68, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x55, 0x0a, 0x0e, 0x68, 0x6f, 0x73,
+	0x74, 0x5f, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x18, 0x1b, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x2e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e, 0x48,
+	0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0d, 0x68, 0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73,
+	0x12, 0x32, 0x0a, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x1c, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x13, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x73, 0x12, 0x57, 0x0a, 0x14, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x1d, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x25, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x43, 0x6f,
+	0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x12, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x59, 0x0a,
+	0x14, 0x61, 0x64, 0x61, 0x70, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x41, 0x64, 0x61, 0x70, 0x74, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x52, 0x13, 0x61, 0x64, 0x61, 0x70, 0x74, 0x69, 0x76, 0x65, 0x43, 0x6f, 0x6e,
+	0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x40, 0x0a, 0x09, 0x77, 0x65, 0x62, 0x73,
+	0x6f, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x57, 0x65, 0x62, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52,
+	0x09, 0x77, 0x65, 0x62, 0x73, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x47, 0x0a, 0x0e, 0x65, 0x6d,
+	0x70, 0x74, 0x79, 0x5f, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x18, 0x20, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x55, 0x70, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x52, 0x0d, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x55, 0x70, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x12, 0x4d, 0x0a, 0x10, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x5f,
+	0x72, 0x6f, 0x6c, 0x6c, 0x6f, 0x75, 0x74, 0x18, 0x21, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x6f, 0x6c, 0x6c, 0x6f, 0x75,
+	0x74, 0x52, 0x0f, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x52, 0x6f, 0x6c, 0x6c, 0x6f,
+	0x75, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x69, 0x63, 0x18, 0x22, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x69, 0x63, 0x52, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x69, 0x63, 0x12, 0x44, 0x0a, 0x0d, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x5f,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x23, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x0c, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x41, 0x0a, 0x0c, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x73, 0x18, 0x24, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x25, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x51, 0x0a, 0x12, 0x63, 0x6c, 0x69, 0x65,
+	0x6e, 0x74, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x26,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x43,
+	0x65, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e,
+	0x74, 0x43, 0x65, 0x72, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x5a, 0x0a, 0x15, 0x75,
+	0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x63, 0x61, 0x70,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x27, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67, 0x6f, 0x64,
+	0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55,
+	0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x42, 0x6f, 0x64, 0x79, 0x43, 0x61, 0x70, 0x74, 0x75,
+	0x72, 0x65, 0x52, 0x13, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x42, 0x6f, 0x64, 0x79,
+	0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x12, 0x31, 0x0a, 0x14, 0x74, 0x72, 0x61, 0x6e, 0x73,
+	0x70, 0x6f, 0x72, 0x74, 0x5f, 0x64, 0x65, 0x63, 0x6f, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x18,
+	0x28, 0x20, 0x03, 0x28, 0x09, 0x52, 0x13, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74,
+	0x44, 0x65, 0x63, 0x6f, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x41, 0x0a, 0x0c, 0x65, 0x67,
+	0x72, 0x65, 0x73, 0x73, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x29, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x6f, 0x78, 0x79,
+	0x52, 0x0b, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x12, 0x44, 0x0a,
+	0x0d, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x74, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x18, 0x2a,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54,
+	0x69, 0x6d, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d,
+	0x69, 0x6e, 0x67, 0x12, 0x4d, 0x0a, 0x10, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x5f,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x2b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x52, 0x0f, 0x73, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x12, 0x40, 0x0a, 0x0c, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x73, 0x18, 0x2c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x52, 0x50,
+	0x43, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x52, 0x0b, 0x67, 0x72, 0x70, 0x63, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x73, 0x12, 0x44, 0x0a, 0x0d, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x73, 0x18, 0x2d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x52, 0x0c, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x66, 0x61,
+	0x69, 0x6c, 0x6f, 0x76, 0x65, 0x72, 0x18, 0x2e, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67,
+	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x46, 0x61, 0x69, 0x6c, 0x6f, 0x76, 0x65, 0x72, 0x52, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x6f,
+	0x76, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x03, 0x73, 0x6c, 0x6f, 0x18, 0x2f, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x4c, 0x4f, 0x52, 0x03, 0x73, 0x6c, 0x6f, 0x12, 0x51, 0x0a,
+	0x17, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x30, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x15, 0x72, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x61, 0x0a,
+	0x12, 0x48, 0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x73, 0x45, 0x6e,
+	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x35, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4f, 0x76, 0x65,
+	0x72, 0x72, 0x69, 0x64, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x98, 0x01, 0x0a, 0x03, 0x53, 0x4c, 0x4f, 0x12, 0x22, 0x0a, 0x0c, 0x61, 0x76, 0x61, 0x69,
+	0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c,
+	0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x46, 0x0a, 0x11,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x10, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x54, 0x68, 0x72, 0x65, 0x73,
+	0x68, 0x6f, 0x6c, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x6c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x22, 0xdb, 0x01, 0x0a, 0x08,
+	0x46, 0x61, 0x69, 0x6c, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x37, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x65,
+	0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x73, 0x12, 0x31, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06,
+	0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x21, 0x0a, 0x0c, 0x77, 0x61, 0x72, 0x6d, 0x5f, 0x70,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x77, 0x61,
+	0x72, 0x6d, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x5a, 0x0a, 0x0c, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x36, 0x0a,
+	0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x73, 0x22, 0xfd, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x61, 0x78, 0x5f, 0x63,
+	0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x10, 0x6d, 0x61, 0x78, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x5f,
+	0x62, 0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x10, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x42, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75,
+	0x6d, 0x65, 0x72, 0x12, 0x35, 0x0a, 0x17, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x14, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x39, 0x0a, 0x19, 0x65, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x16, 0x65,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x42, 0x79, 0x74, 0x65, 0x73, 0x50, 0x65, 0x72, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x22, 0xc6, 0x01, 0x0a, 0x0a, 0x47, 0x52, 0x50, 0x43, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64,
+	0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69,
+	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d,
+	0x65, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2e,
+	0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e,
+	0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x22, 0xc8,
+	0x02, 0x0a, 0x0f, 0x53, 0x65, 0x63, 0x75, 0x72, 0x69, 0x74, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x14,
+	0x0a, 0x05, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x66,
+	0x6f, 0x72, 0x63, 0x65, 0x12, 0x2b, 0x0a, 0x04, 0x68, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x53, 0x54, 0x53, 0x52, 0x04, 0x68, 0x73, 0x74,
+	0x73, 0x12, 0x30, 0x0a, 0x14, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x12, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x4f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x5f,
+	0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x72, 0x65,
+	0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x23, 0x0a, 0x0d,
+	0x66, 0x72, 0x61, 0x6d, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x5f, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x74, 0x6f, 0x72, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x66, 0x72, 0x61, 0x6d,
+	0x65, 0x41, 0x6e, 0x63, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x70, 0x65,
+	0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x5f, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x73, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x22, 0xe0, 0x01, 0x0a, 0x0c, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x23, 0x0a, 0x0d, 0x65, 0x78,
+	0x74, 0x72, 0x61, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0c, 0x65, 0x78, 0x74, 0x72, 0x61, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x12,
+	0x29, 0x0a, 0x10, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x69, 0x73, 0x61, 0x62,
+	0x6c, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x12, 0x4e, 0x0a, 0x0d, 0x67, 0x65,
+	0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x2e, 0x42, 0x6f, 0x64, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x67,
+	0x65, 0x74, 0x48, 0x65, 0x61, 0x64, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x30, 0x0a, 0x0a, 0x42, 0x6f,
+	0x64, 0x79, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0b, 0x0a, 0x07, 0x46, 0x4f, 0x52, 0x57,
+	0x41, 0x52, 0x44, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x53, 0x54, 0x52, 0x49, 0x50, 0x10, 0x01,
+	0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10, 0x02, 0x22, 0x9f, 0x01, 0x0a,
+	0x04, 0x48, 0x53, 0x54, 0x53, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x12, 0x32, 0x0a, 0x07, 0x6d, 0x61, 0x78, 0x5f, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x6d,
+	0x61, 0x78, 0x41, 0x67, 0x65, 0x12, 0x2d, 0x0a, 0x12, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x5f, 0x73, 0x75, 0x62, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x11, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x53, 0x75, 0x62, 0x64, 0x6f, 0x6d,
+	0x61, 0x69, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x22, 0x58,
+	0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x54, 0x69, 0x6d, 0x69, 0x6e, 0x67, 0x12, 0x18,
+	0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x22, 0x3a, 0x0a, 0x0b, 0x45, 0x67, 0x72, 0x65,
+	0x73, 0x73, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x6e, 0x6f, 0x5f,
+	0x70, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6e, 0x6f, 0x50,
+	0x72, 0x6f, 0x78, 0x79, 0x22, 0x91, 0x01, 0x0a, 0x13, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x42, 0x6f, 0x64, 0x79, 0x43, 0x61, 0x70, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1b, 0x0a, 0x09,
+	0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x5f, 0x6d, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x4d, 0x69, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x5f, 0x6d, 0x61, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x4d, 0x61, 0x78, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x73, 0x6b, 0x5f,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x6d, 0x61,
+	0x73, 0x6b, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x3c, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65,
+	0x64, 0x12, 0x13, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x5f, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x04, 0x74, 0x6f, 0x70, 0x4b, 0x22, 0x99, 0x01, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x74,
+	0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0d, 0x6d, 0x61, 0x78, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x26, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x56, 0x61, 0x6c,
+	0x75, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0xa1, 0x01, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x3e, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x26, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62,
+	0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42,
+	0x79, 0x74, 0x65, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x08,
+	0x0a, 0x04, 0x44, 0x52, 0x4f, 0x50, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x54, 0x52, 0x55, 0x4e,
+	0x43, 0x41, 0x54, 0x45, 0x10, 0x01, 0x22, 0xa7, 0x02, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x69,
+	0x63, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x64, 0x69, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x62, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x62, 0x65, 0x64, 0x12, 0x3a, 0x0a, 0x05, 0x66, 0x69, 0x6c,
+	0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x69, 0x63, 0x2e, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05,
+	0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x70, 0x5f, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74, 0x72,
+	0x69, 0x70, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x61, 0x63, 0x68,
+	0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0c, 0x63, 0x61, 0x63, 0x68, 0x65, 0x43, 0x6f, 0x6e, 0x74, 0x72, 0x6f, 0x6c, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x65, 0x74, 0x61, 0x67, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0b, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x45, 0x74, 0x61, 0x67,
+	0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x1a, 0x38, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x45,
 	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
 	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
-	0x22, 0x6c, 0x0a, 0x0a, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x12, 0x12,
-	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x22, 0xc9,
-	0x02, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61,
-	0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67,
-	0x65, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x03, 0x48, 0x00, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x88, 0x01, 0x01, 0x12,
-	0x41, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e,
-	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
-	0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x03, 0x74, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74,
-	0x6c, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x44, 0x0a, 0x08,
-	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28,
+	0x22, 0xf7, 0x01, 0x0a, 0x0d, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x3f, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x27, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x55, 0x70, 0x73, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x3c, 0x0a, 0x0c, 0x77, 0x61, 0x69, 0x74, 0x5f, 0x74, 0x69, 0x6d, 0x65,
+	0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x77, 0x61, 0x69, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x12, 0x36, 0x0a, 0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52,
+	0x08, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x22, 0x2f, 0x0a, 0x06, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x0d, 0x0a, 0x09, 0x46, 0x41, 0x49, 0x4c, 0x5f, 0x46, 0x41, 0x53, 0x54,
+	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x57, 0x41, 0x49, 0x54, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08,
+	0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b, 0x10, 0x02, 0x22, 0xe3, 0x01, 0x0a, 0x0f, 0x57,
+	0x65, 0x62, 0x53, 0x6f, 0x63, 0x6b, 0x65, 0x74, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x22,
+	0x0a, 0x0c, 0x73, 0x75, 0x62, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x75, 0x62, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x6f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c,
+	0x6f, 0x77, 0x65, 0x64, 0x4f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x72,
+	0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0d, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x4f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x12, 0x2d, 0x0a, 0x12, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x65, 0x78,
+	0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x11,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x2d, 0x0a, 0x12, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x65, 0x78, 0x74,
+	0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x64,
+	0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73,
+	0x22, 0xab, 0x01, 0x0a, 0x12, 0x4d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x44, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2c, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x73, 0x73,
+	0x69, 0x6e, 0x67, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x2e, 0x41,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x22, 0x2c, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x09, 0x0a, 0x05, 0x41, 0x4c,
+	0x4c, 0x4f, 0x57, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10,
+	0x01, 0x12, 0x0b, 0x0a, 0x07, 0x44, 0x45, 0x46, 0x41, 0x55, 0x4c, 0x54, 0x10, 0x02, 0x22, 0xba,
+	0x02, 0x0a, 0x0c, 0x53, 0x74, 0x69, 0x63, 0x6b, 0x79, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03, 0x74, 0x74, 0x6c,
+	0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65,
+	0x79, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x64, 0x69, 0x73,
+	0x61, 0x62, 0x6c, 0x65, 0x5f, 0x68, 0x74, 0x74, 0x70, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x48, 0x74, 0x74,
+	0x70, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x45, 0x0a, 0x09, 0x73, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x69,
+	0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x74, 0x69,
+	0x63, 0x6b, 0x79, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2e, 0x53, 0x61, 0x6d, 0x65, 0x53, 0x69,
+	0x74, 0x65, 0x52, 0x08, 0x73, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x22, 0x29, 0x0a, 0x08, 0x53, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x74, 0x65, 0x12, 0x07, 0x0a, 0x03,
+	0x4c, 0x41, 0x58, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54, 0x10,
+	0x01, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x02, 0x22, 0xb4, 0x01, 0x0a, 0x0b,
+	0x4d, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x65,
+	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e,
+	0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x62,
+	0x6f, 0x64, 0x79, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3a, 0x0a, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x22, 0xe2, 0x01, 0x0a, 0x0a, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x07, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
+	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
+	0x64, 0x12, 0x4d, 0x0a, 0x10, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x22, 0x2e, 0x67, 0x6f,
+	0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x45, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52,
+	0x0f, 0x65, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x4d, 0x6f, 0x64, 0x65,
+	0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f, 0x72, 0x5f, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6d, 0x6f, 0x6e, 0x69, 0x74, 0x6f,
+	0x72, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0xbe, 0x03, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x77,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x06, 0x77,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x88, 0x01, 0x01, 0x12, 0x41, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e,
 	0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
-	0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42,
-	0x09, 0x0a, 0x07, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x0d, 0x0a, 0x0b, 0x48, 0x65,
-	0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x22, 0xc4, 0x01, 0x0a, 0x05, 0x52, 0x65,
-	0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12,
-	0x41, 0x0a, 0x0f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f,
-	0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x70, 0x65, 0x72, 0x54, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f,
-	0x75, 0x74, 0x12, 0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73,
-	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69,
-	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x04,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65, 0x73,
-	0x22, 0xb8, 0x01, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26,
-	0x0a, 0x0e, 0x62, 0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x62, 0x79, 0x53, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x62, 0x79, 0x5f, 0x68, 0x65, 0x61,
-	0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b,
+	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x74,
+	0x6c, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x74, 0x6c, 0x73, 0x12, 0x26, 0x0a,
+	0x0f, 0x74, 0x6c, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73,
+	0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x41, 0x0a, 0x0c, 0x6e,
+	0x6f, 0x64, 0x65, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x73, 0x52, 0x0b, 0x6e, 0x6f, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x12, 0x30,
+	0x0a, 0x14, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x5f,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x75, 0x70,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x50, 0x61, 0x74, 0x68, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x09, 0x0a,
+	0x07, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x47, 0x0a, 0x0b, 0x4e, 0x6f, 0x64, 0x65,
+	0x4d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x65, 0x71, 0x75,
+	0x61, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x65, 0x71, 0x75, 0x61, 0x6c,
+	0x73, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x6e, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x6e, 0x22, 0xb9, 0x01, 0x0a, 0x0b, 0x4e, 0x6f, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x73, 0x12, 0x3a, 0x0a, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x65, 0x72, 0x52, 0x08, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x72, 0x73, 0x12, 0x48, 0x0a,
+	0x0b, 0x6f, 0x6e, 0x5f, 0x6e, 0x6f, 0x5f, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x28, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x73, 0x2e, 0x4f, 0x6e, 0x4e, 0x6f, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x09, 0x6f, 0x6e,
+	0x4e, 0x6f, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x22, 0x24, 0x0a, 0x09, 0x4f, 0x6e, 0x4e, 0x6f, 0x4d,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x00, 0x12,
+	0x0c, 0x0a, 0x08, 0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b, 0x10, 0x01, 0x22, 0x0d, 0x0a,
+	0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x22, 0x80, 0x04, 0x0a,
+	0x05, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x73, 0x12, 0x41, 0x0a, 0x0f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x79, 0x5f, 0x74, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x70, 0x65, 0x72, 0x54, 0x72, 0x79, 0x54, 0x69,
+	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x6f, 0x64, 0x64,
 	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
-	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00,
-	0x52, 0x08, 0x62, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x1a, 0x32, 0x0a, 0x06, 0x68, 0x65,
-	0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x0b,
-	0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2a, 0x2f, 0x0a, 0x08, 0x50,
-	0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45,
-	0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x54, 0x50,
-	0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x47, 0x52, 0x50, 0x43, 0x10, 0x02, 0x42, 0x2e, 0x5a, 0x2c,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x64, 0x65, 0x2d,
-	0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2f, 0x70,
-	0x6b, 0x67, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65,
+	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x12, 0x5d, 0x0a, 0x14, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x2e, 0x41, 0x74, 0x74, 0x65,
+	0x6d, 0x70, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x12,
+	0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x4d, 0x6f,
+	0x64, 0x65, 0x12, 0x4a, 0x0a, 0x0d, 0x6f, 0x6e, 0x5f, 0x65, 0x78, 0x68, 0x61, 0x75, 0x73, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x6f, 0x64, 0x64,
+	0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x74, 0x72, 0x79, 0x2e, 0x4f, 0x6e, 0x45, 0x78, 0x68, 0x61, 0x75, 0x73, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x0c, 0x6f, 0x6e, 0x45, 0x78, 0x68, 0x61, 0x75, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3a,
+	0x0a, 0x12, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x50, 0x45, 0x52, 0x5f, 0x54, 0x52, 0x59, 0x10,
+	0x00, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x45, 0x4d, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x01,
+	0x12, 0x08, 0x0a, 0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x02, 0x22, 0x53, 0x0a, 0x0c, 0x4f, 0x6e,
+	0x45, 0x78, 0x68, 0x61, 0x75, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x19, 0x4f, 0x4e,
+	0x5f, 0x45, 0x58, 0x48, 0x41, 0x55, 0x53, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x55, 0x4e, 0x53, 0x50,
+	0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x4c, 0x41, 0x53,
+	0x54, 0x5f, 0x52, 0x45, 0x53, 0x50, 0x4f, 0x4e, 0x53, 0x45, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d,
+	0x47, 0x41, 0x54, 0x45, 0x57, 0x41, 0x59, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x02, 0x22,
+	0xf9, 0x02, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x26, 0x0a,
+	0x0e, 0x62, 0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x62, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x62, 0x79, 0x5f, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x48, 0x00, 0x52,
+	0x08, 0x62, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x56, 0x0a, 0x10, 0x62, 0x79, 0x5f,
+	0x62, 0x6f, 0x64, 0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x48,
+	0x00, 0x52, 0x0e, 0x62, 0x79, 0x42, 0x6f, 0x64, 0x79, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x73, 0x1a, 0x53, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x5f, 0x72,
+	0x65, 0x67, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x52, 0x65, 0x67, 0x65, 0x78, 0x1a, 0x46, 0x0a, 0x0d, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72,
+	0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x42, 0x0b,
+	0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x46, 0x0a, 0x14, 0x55,
+	0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x44, 0x65, 0x62, 0x75, 0x67, 0x48, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x73, 0x22, 0xfb, 0x01, 0x0a, 0x09, 0x41, 0x64, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x43,
+	0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x71, 0x75,
+	0x65, 0x75, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x18, 0x0a, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x09, 0x6a, 0x77, 0x74, 0x5f, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x08, 0x6a, 0x77, 0x74, 0x43, 0x6c, 0x61,
+	0x69, 0x6d, 0x12, 0x3a, 0x0a, 0x07, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x52, 0x07, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x12, 0x23,
+	0x0a, 0x0d, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x42, 0x0c, 0x0a, 0x0a, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x22, 0xee, 0x02, 0x0a, 0x13, 0x41, 0x64, 0x61, 0x70, 0x74, 0x69, 0x76, 0x65, 0x43, 0x6f,
+	0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x69, 0x6e,
+	0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x69,
+	0x6e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x12, 0x40, 0x0a, 0x0e, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x6c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x6d,
+	0x61, 0x78, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x61, 0x74, 0x65, 0x12, 0x31, 0x0a, 0x06, 0x77,
+	0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x21,
+	0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x6d, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x5f, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x62, 0x61, 0x63, 0x6b, 0x6f, 0x66,
+	0x66, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x3a, 0x0a, 0x0b, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72, 0x65, 0x74, 0x72, 0x79, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x22, 0x71, 0x0a, 0x0d, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x12,
+	0x34, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6d, 0x61,
+	0x78, 0x57, 0x61, 0x69, 0x74, 0x2a, 0x3b, 0x0a, 0x10, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x08, 0x0a, 0x04, 0x41, 0x55, 0x54,
+	0x4f, 0x10, 0x00, 0x12, 0x09, 0x0a, 0x05, 0x48, 0x54, 0x54, 0x50, 0x31, 0x10, 0x01, 0x12, 0x09,
+	0x0a, 0x05, 0x48, 0x54, 0x54, 0x50, 0x32, 0x10, 0x02, 0x12, 0x07, 0x0a, 0x03, 0x48, 0x32, 0x43,
+	0x10, 0x03, 0x2a, 0x2b, 0x0a, 0x0f, 0x45, 0x6e, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x45, 0x4e, 0x46, 0x4f, 0x52, 0x43, 0x45,
+	0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x4d, 0x4f, 0x4e, 0x49, 0x54, 0x4f, 0x52, 0x10, 0x01, 0x2a,
+	0x2f, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x0f, 0x0a, 0x0b, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04,
+	0x48, 0x54, 0x54, 0x50, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x47, 0x52, 0x50, 0x43, 0x10, 0x02,
+	0x42, 0x2e, 0x5a, 0x2c, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61,
+	0x69, 0x64, 0x65, 0x2d, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -978,51 +6413,236 @@ func file_config_v1_gateway_proto_rawDescGZIP() []byte {
 	return file_config_v1_gateway_proto_rawDescData
 }
 
-var file_config_v1_gateway_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_config_v1_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_config_v1_gateway_proto_enumTypes = make([]protoimpl.EnumInfo, 13)
+var file_config_v1_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 65)
 var file_config_v1_gateway_proto_goTypes = []any{
-	(Protocol)(0),               // 0: goddess.config.v1.Protocol
-	(*Gateway)(nil),             // 1: goddess.config.v1.Gateway
-	(*TLS)(nil),                 // 2: goddess.config.v1.TLS
-	(*PriorityConfig)(nil),      // 3: goddess.config.v1.PriorityConfig
-	(*Endpoint)(nil),            // 4: goddess.config.v1.Endpoint
-	(*Middleware)(nil),          // 5: goddess.config.v1.Middleware
-	(*Backend)(nil),             // 6: goddess.config.v1.Backend
-	(*HealthCheck)(nil),         // 7: goddess.config.v1.HealthCheck
-	(*Retry)(nil),               // 8: goddess.config.v1.Retry
-	(*Condition)(nil),           // 9: goddess.config.v1.Condition
-	nil,                         // 10: goddess.config.v1.Gateway.TlsStoreEntry
-	nil,                         // 11: goddess.config.v1.Endpoint.MetadataEntry
-	nil,                         // 12: goddess.config.v1.Backend.MetadataEntry
-	(*ConditionHeader)(nil),     // 13: goddess.config.v1.Condition.header
-	(*v1.Discovery)(nil),        // 14: goddess.discovery.v1.Discovery
-	(*durationpb.Duration)(nil), // 15: google.protobuf.Duration
-	(*anypb.Any)(nil),           // 16: google.protobuf.Any
+	(UpstreamProtocol)(0),           // 0: goddess.config.v1.UpstreamProtocol
+	(EnforcementMode)(0),            // 1: goddess.config.v1.EnforcementMode
+	(Protocol)(0),                   // 2: goddess.config.v1.Protocol
+	(Listener_Mode)(0),              // 3: goddess.config.v1.Listener.Mode
+	(ForwardedHeaders_Style)(0),     // 4: goddess.config.v1.ForwardedHeaders.Style
+	(MethodPolicy_BodyAction)(0),    // 5: goddess.config.v1.MethodPolicy.BodyAction
+	(HeaderAction_Action)(0),        // 6: goddess.config.v1.HeaderAction.Action
+	(EmptyUpstream_Action)(0),       // 7: goddess.config.v1.EmptyUpstream.Action
+	(MissingContentType_Action)(0),  // 8: goddess.config.v1.MissingContentType.Action
+	(StickyCookie_SameSite)(0),      // 9: goddess.config.v1.StickyCookie.SameSite
+	(NodeFilters_OnNoMatch)(0),      // 10: goddess.config.v1.NodeFilters.OnNoMatch
+	(Retry_AttemptTimeoutMode)(0),   // 11: goddess.config.v1.Retry.AttemptTimeoutMode
+	(Retry_OnExhaustion)(0),         // 12: goddess.config.v1.Retry.OnExhaustion
+	(*Gateway)(nil),                 // 13: goddess.config.v1.Gateway
+	(*ClientCert)(nil),              // 14: goddess.config.v1.ClientCert
+	(*ClientCertPolicy)(nil),        // 15: goddess.config.v1.ClientCertPolicy
+	(*Service)(nil),                 // 16: goddess.config.v1.Service
+	(*Listener)(nil),                // 17: goddess.config.v1.Listener
+	(*PassthroughRoute)(nil),        // 18: goddess.config.v1.PassthroughRoute
+	(*Resolver)(nil),                // 19: goddess.config.v1.Resolver
+	(*HostOverride)(nil),            // 20: goddess.config.v1.HostOverride
+	(*BufferBudget)(nil),            // 21: goddess.config.v1.BufferBudget
+	(*DeadlinePropagation)(nil),     // 22: goddess.config.v1.DeadlinePropagation
+	(*HostGroup)(nil),               // 23: goddess.config.v1.HostGroup
+	(*Prewarm)(nil),                 // 24: goddess.config.v1.Prewarm
+	(*Fallback)(nil),                // 25: goddess.config.v1.Fallback
+	(*FallbackAction)(nil),          // 26: goddess.config.v1.FallbackAction
+	(*ForwardedHeaders)(nil),        // 27: goddess.config.v1.ForwardedHeaders
+	(*TLS)(nil),                     // 28: goddess.config.v1.TLS
+	(*PriorityConfig)(nil),          // 29: goddess.config.v1.PriorityConfig
+	(*Rollout)(nil),                 // 30: goddess.config.v1.Rollout
+	(*RolloutStep)(nil),             // 31: goddess.config.v1.RolloutStep
+	(*PriorityRollout)(nil),         // 32: goddess.config.v1.PriorityRollout
+	(*Endpoint)(nil),                // 33: goddess.config.v1.Endpoint
+	(*SLO)(nil),                     // 34: goddess.config.v1.SLO
+	(*Failover)(nil),                // 35: goddess.config.v1.Failover
+	(*BackendGroup)(nil),            // 36: goddess.config.v1.BackendGroup
+	(*StreamLimits)(nil),            // 37: goddess.config.v1.StreamLimits
+	(*GRPCMethod)(nil),              // 38: goddess.config.v1.GRPCMethod
+	(*SecurityHeaders)(nil),         // 39: goddess.config.v1.SecurityHeaders
+	(*MethodPolicy)(nil),            // 40: goddess.config.v1.MethodPolicy
+	(*HSTS)(nil),                    // 41: goddess.config.v1.HSTS
+	(*ServerTiming)(nil),            // 42: goddess.config.v1.ServerTiming
+	(*EgressProxy)(nil),             // 43: goddess.config.v1.EgressProxy
+	(*UpstreamBodyCapture)(nil),     // 44: goddess.config.v1.UpstreamBodyCapture
+	(*HeaderStats)(nil),             // 45: goddess.config.v1.HeaderStats
+	(*HeaderLimits)(nil),            // 46: goddess.config.v1.HeaderLimits
+	(*HeaderAction)(nil),            // 47: goddess.config.v1.HeaderAction
+	(*Static)(nil),                  // 48: goddess.config.v1.Static
+	(*EmptyUpstream)(nil),           // 49: goddess.config.v1.EmptyUpstream
+	(*WebSocketPolicy)(nil),         // 50: goddess.config.v1.WebSocketPolicy
+	(*MissingContentType)(nil),      // 51: goddess.config.v1.MissingContentType
+	(*StickyCookie)(nil),            // 52: goddess.config.v1.StickyCookie
+	(*Maintenance)(nil),             // 53: goddess.config.v1.Maintenance
+	(*Middleware)(nil),              // 54: goddess.config.v1.Middleware
+	(*Backend)(nil),                 // 55: goddess.config.v1.Backend
+	(*NodeMatcher)(nil),             // 56: goddess.config.v1.NodeMatcher
+	(*NodeFilters)(nil),             // 57: goddess.config.v1.NodeFilters
+	(*HealthCheck)(nil),             // 58: goddess.config.v1.HealthCheck
+	(*Retry)(nil),                   // 59: goddess.config.v1.Retry
+	(*Condition)(nil),               // 60: goddess.config.v1.Condition
+	(*UpstreamDebugHeaders)(nil),    // 61: goddess.config.v1.UpstreamDebugHeaders
+	(*Admission)(nil),               // 62: goddess.config.v1.Admission
+	(*AdaptiveConcurrency)(nil),     // 63: goddess.config.v1.AdaptiveConcurrency
+	(*PriorityClass)(nil),           // 64: goddess.config.v1.PriorityClass
+	nil,                             // 65: goddess.config.v1.Gateway.TlsStoreEntry
+	nil,                             // 66: goddess.config.v1.Gateway.HostOverridesEntry
+	nil,                             // 67: goddess.config.v1.Service.MetadataEntry
+	nil,                             // 68: goddess.config.v1.Fallback.HostsEntry
+	(*FallbackAction_Static)(nil),   // 69: goddess.config.v1.FallbackAction.Static
+	(*FallbackAction_Redirect)(nil), // 70: goddess.config.v1.FallbackAction.Redirect
+	nil,                             // 71: goddess.config.v1.FallbackAction.Static.HeadersEntry
+	nil,                             // 72: goddess.config.v1.Endpoint.MetadataEntry
+	nil,                             // 73: goddess.config.v1.Endpoint.HostOverridesEntry
+	nil,                             // 74: goddess.config.v1.Static.FilesEntry
+	nil,                             // 75: goddess.config.v1.Backend.MetadataEntry
+	(*ConditionHeader)(nil),         // 76: goddess.config.v1.Condition.header
+	(*ConditionBodyContains)(nil),   // 77: goddess.config.v1.Condition.body_contains
+	(*v1.Discovery)(nil),            // 78: goddess.discovery.v1.Discovery
+	(*durationpb.Duration)(nil),     // 79: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),   // 80: google.protobuf.Timestamp
+	(*anypb.Any)(nil),               // 81: google.protobuf.Any
 }
 var file_config_v1_gateway_proto_depIdxs = []int32{
-	4,  // 0: goddess.config.v1.Gateway.endpoints:type_name -> goddess.config.v1.Endpoint
-	5,  // 1: goddess.config.v1.Gateway.middlewares:type_name -> goddess.config.v1.Middleware
-	10, // 2: goddess.config.v1.Gateway.tls_store:type_name -> goddess.config.v1.Gateway.TlsStoreEntry
-	14, // 3: goddess.config.v1.Gateway.discovery:type_name -> goddess.discovery.v1.Discovery
-	4,  // 4: goddess.config.v1.PriorityConfig.endpoints:type_name -> goddess.config.v1.Endpoint
-	0,  // 5: goddess.config.v1.Endpoint.protocol:type_name -> goddess.config.v1.Protocol
-	15, // 6: goddess.config.v1.Endpoint.timeout:type_name -> google.protobuf.Duration
-	5,  // 7: goddess.config.v1.Endpoint.middlewares:type_name -> goddess.config.v1.Middleware
-	6,  // 8: goddess.config.v1.Endpoint.backends:type_name -> goddess.config.v1.Backend
-	8,  // 9: goddess.config.v1.Endpoint.retry:type_name -> goddess.config.v1.Retry
-	11, // 10: goddess.config.v1.Endpoint.metadata:type_name -> goddess.config.v1.Endpoint.MetadataEntry
-	16, // 11: goddess.config.v1.Middleware.options:type_name -> google.protobuf.Any
-	7,  // 12: goddess.config.v1.Backend.health_check:type_name -> goddess.config.v1.HealthCheck
-	12, // 13: goddess.config.v1.Backend.metadata:type_name -> goddess.config.v1.Backend.MetadataEntry
-	15, // 14: goddess.config.v1.Retry.per_try_timeout:type_name -> google.protobuf.Duration
-	9,  // 15: goddess.config.v1.Retry.conditions:type_name -> goddess.config.v1.Condition
-	13, // 16: goddess.config.v1.Condition.by_header:type_name -> goddess.config.v1.Condition.header
-	2,  // 17: goddess.config.v1.Gateway.TlsStoreEntry.value:type_name -> goddess.config.v1.TLS
-	18, // [18:18] is the sub-list for method output_type
-	18, // [18:18] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	33,  // 0: goddess.config.v1.Gateway.endpoints:type_name -> goddess.config.v1.Endpoint
+	54,  // 1: goddess.config.v1.Gateway.middlewares:type_name -> goddess.config.v1.Middleware
+	65,  // 2: goddess.config.v1.Gateway.tls_store:type_name -> goddess.config.v1.Gateway.TlsStoreEntry
+	78,  // 3: goddess.config.v1.Gateway.discovery:type_name -> goddess.discovery.v1.Discovery
+	27,  // 4: goddess.config.v1.Gateway.forwarded_headers:type_name -> goddess.config.v1.ForwardedHeaders
+	25,  // 5: goddess.config.v1.Gateway.fallback:type_name -> goddess.config.v1.Fallback
+	24,  // 6: goddess.config.v1.Gateway.prewarm:type_name -> goddess.config.v1.Prewarm
+	23,  // 7: goddess.config.v1.Gateway.host_groups:type_name -> goddess.config.v1.HostGroup
+	22,  // 8: goddess.config.v1.Gateway.deadline_propagation:type_name -> goddess.config.v1.DeadlinePropagation
+	21,  // 9: goddess.config.v1.Gateway.buffer_budget:type_name -> goddess.config.v1.BufferBudget
+	66,  // 10: goddess.config.v1.Gateway.host_overrides:type_name -> goddess.config.v1.Gateway.HostOverridesEntry
+	79,  // 11: goddess.config.v1.Gateway.dns_cache_ttl:type_name -> google.protobuf.Duration
+	19,  // 12: goddess.config.v1.Gateway.resolver:type_name -> goddess.config.v1.Resolver
+	17,  // 13: goddess.config.v1.Gateway.listeners:type_name -> goddess.config.v1.Listener
+	46,  // 14: goddess.config.v1.Gateway.header_limits:type_name -> goddess.config.v1.HeaderLimits
+	16,  // 15: goddess.config.v1.Gateway.services:type_name -> goddess.config.v1.Service
+	14,  // 16: goddess.config.v1.Gateway.client_cert:type_name -> goddess.config.v1.ClientCert
+	43,  // 17: goddess.config.v1.Gateway.egress_proxy:type_name -> goddess.config.v1.EgressProxy
+	39,  // 18: goddess.config.v1.Gateway.security_headers:type_name -> goddess.config.v1.SecurityHeaders
+	40,  // 19: goddess.config.v1.Gateway.method_policy:type_name -> goddess.config.v1.MethodPolicy
+	2,   // 20: goddess.config.v1.Service.protocol:type_name -> goddess.config.v1.Protocol
+	55,  // 21: goddess.config.v1.Service.backends:type_name -> goddess.config.v1.Backend
+	79,  // 22: goddess.config.v1.Service.timeout:type_name -> google.protobuf.Duration
+	59,  // 23: goddess.config.v1.Service.retry:type_name -> goddess.config.v1.Retry
+	54,  // 24: goddess.config.v1.Service.middlewares:type_name -> goddess.config.v1.Middleware
+	67,  // 25: goddess.config.v1.Service.metadata:type_name -> goddess.config.v1.Service.MetadataEntry
+	0,   // 26: goddess.config.v1.Service.upstream_protocol:type_name -> goddess.config.v1.UpstreamProtocol
+	3,   // 27: goddess.config.v1.Listener.mode:type_name -> goddess.config.v1.Listener.Mode
+	18,  // 28: goddess.config.v1.Listener.routes:type_name -> goddess.config.v1.PassthroughRoute
+	79,  // 29: goddess.config.v1.Listener.handshake_timeout:type_name -> google.protobuf.Duration
+	55,  // 30: goddess.config.v1.PassthroughRoute.backends:type_name -> goddess.config.v1.Backend
+	79,  // 31: goddess.config.v1.PassthroughRoute.idle_timeout:type_name -> google.protobuf.Duration
+	79,  // 32: goddess.config.v1.PassthroughRoute.max_connection_duration:type_name -> google.protobuf.Duration
+	79,  // 33: goddess.config.v1.PassthroughRoute.dial_timeout:type_name -> google.protobuf.Duration
+	79,  // 34: goddess.config.v1.Resolver.lookup_timeout:type_name -> google.protobuf.Duration
+	79,  // 35: goddess.config.v1.Resolver.min_ttl:type_name -> google.protobuf.Duration
+	79,  // 36: goddess.config.v1.Resolver.max_ttl:type_name -> google.protobuf.Duration
+	79,  // 37: goddess.config.v1.Resolver.negative_ttl:type_name -> google.protobuf.Duration
+	79,  // 38: goddess.config.v1.BufferBudget.queue_timeout:type_name -> google.protobuf.Duration
+	79,  // 39: goddess.config.v1.BufferBudget.retry_after:type_name -> google.protobuf.Duration
+	79,  // 40: goddess.config.v1.DeadlinePropagation.margin:type_name -> google.protobuf.Duration
+	54,  // 41: goddess.config.v1.HostGroup.middlewares:type_name -> goddess.config.v1.Middleware
+	25,  // 42: goddess.config.v1.HostGroup.fallback:type_name -> goddess.config.v1.Fallback
+	79,  // 43: goddess.config.v1.HostGroup.timeout:type_name -> google.protobuf.Duration
+	59,  // 44: goddess.config.v1.HostGroup.retry:type_name -> goddess.config.v1.Retry
+	79,  // 45: goddess.config.v1.Prewarm.timeout:type_name -> google.protobuf.Duration
+	26,  // 46: goddess.config.v1.Fallback.not_found:type_name -> goddess.config.v1.FallbackAction
+	26,  // 47: goddess.config.v1.Fallback.method_not_allowed:type_name -> goddess.config.v1.FallbackAction
+	68,  // 48: goddess.config.v1.Fallback.hosts:type_name -> goddess.config.v1.Fallback.HostsEntry
+	69,  // 49: goddess.config.v1.FallbackAction.static:type_name -> goddess.config.v1.FallbackAction.Static
+	70,  // 50: goddess.config.v1.FallbackAction.redirect:type_name -> goddess.config.v1.FallbackAction.Redirect
+	33,  // 51: goddess.config.v1.FallbackAction.endpoint:type_name -> goddess.config.v1.Endpoint
+	4,   // 52: goddess.config.v1.ForwardedHeaders.style:type_name -> goddess.config.v1.ForwardedHeaders.Style
+	33,  // 53: goddess.config.v1.PriorityConfig.endpoints:type_name -> goddess.config.v1.Endpoint
+	79,  // 54: goddess.config.v1.PriorityConfig.ttl:type_name -> google.protobuf.Duration
+	30,  // 55: goddess.config.v1.PriorityConfig.rollout:type_name -> goddess.config.v1.Rollout
+	80,  // 56: goddess.config.v1.Rollout.start_time:type_name -> google.protobuf.Timestamp
+	79,  // 57: goddess.config.v1.Rollout.ramp_duration:type_name -> google.protobuf.Duration
+	31,  // 58: goddess.config.v1.Rollout.steps:type_name -> goddess.config.v1.RolloutStep
+	79,  // 59: goddess.config.v1.RolloutStep.after:type_name -> google.protobuf.Duration
+	30,  // 60: goddess.config.v1.PriorityRollout.rollout:type_name -> goddess.config.v1.Rollout
+	33,  // 61: goddess.config.v1.PriorityRollout.baseline:type_name -> goddess.config.v1.Endpoint
+	2,   // 62: goddess.config.v1.Endpoint.protocol:type_name -> goddess.config.v1.Protocol
+	79,  // 63: goddess.config.v1.Endpoint.timeout:type_name -> google.protobuf.Duration
+	54,  // 64: goddess.config.v1.Endpoint.middlewares:type_name -> goddess.config.v1.Middleware
+	55,  // 65: goddess.config.v1.Endpoint.backends:type_name -> goddess.config.v1.Backend
+	59,  // 66: goddess.config.v1.Endpoint.retry:type_name -> goddess.config.v1.Retry
+	72,  // 67: goddess.config.v1.Endpoint.metadata:type_name -> goddess.config.v1.Endpoint.MetadataEntry
+	62,  // 68: goddess.config.v1.Endpoint.admission:type_name -> goddess.config.v1.Admission
+	61,  // 69: goddess.config.v1.Endpoint.upstream_debug_headers:type_name -> goddess.config.v1.UpstreamDebugHeaders
+	53,  // 70: goddess.config.v1.Endpoint.maintenance:type_name -> goddess.config.v1.Maintenance
+	79,  // 71: goddess.config.v1.Endpoint.flush_interval:type_name -> google.protobuf.Duration
+	52,  // 72: goddess.config.v1.Endpoint.sticky_cookie:type_name -> goddess.config.v1.StickyCookie
+	22,  // 73: goddess.config.v1.Endpoint.deadline_propagation:type_name -> goddess.config.v1.DeadlinePropagation
+	0,   // 74: goddess.config.v1.Endpoint.upstream_protocol:type_name -> goddess.config.v1.UpstreamProtocol
+	57,  // 75: goddess.config.v1.Endpoint.node_filters:type_name -> goddess.config.v1.NodeFilters
+	73,  // 76: goddess.config.v1.Endpoint.host_overrides:type_name -> goddess.config.v1.Endpoint.HostOverridesEntry
+	51,  // 77: goddess.config.v1.Endpoint.missing_content_type:type_name -> goddess.config.v1.MissingContentType
+	63,  // 78: goddess.config.v1.Endpoint.adaptive_concurrency:type_name -> goddess.config.v1.AdaptiveConcurrency
+	50,  // 79: goddess.config.v1.Endpoint.websocket:type_name -> goddess.config.v1.WebSocketPolicy
+	49,  // 80: goddess.config.v1.Endpoint.empty_upstream:type_name -> goddess.config.v1.EmptyUpstream
+	32,  // 81: goddess.config.v1.Endpoint.priority_rollout:type_name -> goddess.config.v1.PriorityRollout
+	48,  // 82: goddess.config.v1.Endpoint.static:type_name -> goddess.config.v1.Static
+	46,  // 83: goddess.config.v1.Endpoint.header_limits:type_name -> goddess.config.v1.HeaderLimits
+	45,  // 84: goddess.config.v1.Endpoint.header_stats:type_name -> goddess.config.v1.HeaderStats
+	15,  // 85: goddess.config.v1.Endpoint.client_cert_policy:type_name -> goddess.config.v1.ClientCertPolicy
+	44,  // 86: goddess.config.v1.Endpoint.upstream_body_capture:type_name -> goddess.config.v1.UpstreamBodyCapture
+	43,  // 87: goddess.config.v1.Endpoint.egress_proxy:type_name -> goddess.config.v1.EgressProxy
+	42,  // 88: goddess.config.v1.Endpoint.server_timing:type_name -> goddess.config.v1.ServerTiming
+	39,  // 89: goddess.config.v1.Endpoint.security_headers:type_name -> goddess.config.v1.SecurityHeaders
+	38,  // 90: goddess.config.v1.Endpoint.grpc_methods:type_name -> goddess.config.v1.GRPCMethod
+	37,  // 91: goddess.config.v1.Endpoint.stream_limits:type_name -> goddess.config.v1.StreamLimits
+	35,  // 92: goddess.config.v1.Endpoint.failover:type_name -> goddess.config.v1.Failover
+	34,  // 93: goddess.config.v1.Endpoint.slo:type_name -> goddess.config.v1.SLO
+	79,  // 94: goddess.config.v1.Endpoint.response_header_timeout:type_name -> google.protobuf.Duration
+	79,  // 95: goddess.config.v1.SLO.latency_threshold:type_name -> google.protobuf.Duration
+	36,  // 96: goddess.config.v1.Failover.groups:type_name -> goddess.config.v1.BackendGroup
+	79,  // 97: goddess.config.v1.Failover.window:type_name -> google.protobuf.Duration
+	55,  // 98: goddess.config.v1.BackendGroup.backends:type_name -> goddess.config.v1.Backend
+	54,  // 99: goddess.config.v1.GRPCMethod.middlewares:type_name -> goddess.config.v1.Middleware
+	79,  // 100: goddess.config.v1.GRPCMethod.timeout:type_name -> google.protobuf.Duration
+	59,  // 101: goddess.config.v1.GRPCMethod.retry:type_name -> goddess.config.v1.Retry
+	41,  // 102: goddess.config.v1.SecurityHeaders.hsts:type_name -> goddess.config.v1.HSTS
+	5,   // 103: goddess.config.v1.MethodPolicy.get_head_body:type_name -> goddess.config.v1.MethodPolicy.BodyAction
+	79,  // 104: goddess.config.v1.HSTS.max_age:type_name -> google.protobuf.Duration
+	47,  // 105: goddess.config.v1.HeaderLimits.actions:type_name -> goddess.config.v1.HeaderAction
+	6,   // 106: goddess.config.v1.HeaderAction.action:type_name -> goddess.config.v1.HeaderAction.Action
+	74,  // 107: goddess.config.v1.Static.files:type_name -> goddess.config.v1.Static.FilesEntry
+	7,   // 108: goddess.config.v1.EmptyUpstream.action:type_name -> goddess.config.v1.EmptyUpstream.Action
+	79,  // 109: goddess.config.v1.EmptyUpstream.wait_timeout:type_name -> google.protobuf.Duration
+	55,  // 110: goddess.config.v1.EmptyUpstream.fallback:type_name -> goddess.config.v1.Backend
+	8,   // 111: goddess.config.v1.MissingContentType.action:type_name -> goddess.config.v1.MissingContentType.Action
+	79,  // 112: goddess.config.v1.StickyCookie.ttl:type_name -> google.protobuf.Duration
+	9,   // 113: goddess.config.v1.StickyCookie.same_site:type_name -> goddess.config.v1.StickyCookie.SameSite
+	79,  // 114: goddess.config.v1.Maintenance.retry_after:type_name -> google.protobuf.Duration
+	81,  // 115: goddess.config.v1.Middleware.options:type_name -> google.protobuf.Any
+	1,   // 116: goddess.config.v1.Middleware.enforcement_mode:type_name -> goddess.config.v1.EnforcementMode
+	58,  // 117: goddess.config.v1.Backend.health_check:type_name -> goddess.config.v1.HealthCheck
+	75,  // 118: goddess.config.v1.Backend.metadata:type_name -> goddess.config.v1.Backend.MetadataEntry
+	57,  // 119: goddess.config.v1.Backend.node_filters:type_name -> goddess.config.v1.NodeFilters
+	56,  // 120: goddess.config.v1.NodeFilters.matchers:type_name -> goddess.config.v1.NodeMatcher
+	10,  // 121: goddess.config.v1.NodeFilters.on_no_match:type_name -> goddess.config.v1.NodeFilters.OnNoMatch
+	79,  // 122: goddess.config.v1.Retry.per_try_timeout:type_name -> google.protobuf.Duration
+	60,  // 123: goddess.config.v1.Retry.conditions:type_name -> goddess.config.v1.Condition
+	11,  // 124: goddess.config.v1.Retry.attempt_timeout_mode:type_name -> goddess.config.v1.Retry.AttemptTimeoutMode
+	12,  // 125: goddess.config.v1.Retry.on_exhaustion:type_name -> goddess.config.v1.Retry.OnExhaustion
+	76,  // 126: goddess.config.v1.Condition.by_header:type_name -> goddess.config.v1.Condition.header
+	77,  // 127: goddess.config.v1.Condition.by_body_contains:type_name -> goddess.config.v1.Condition.body_contains
+	64,  // 128: goddess.config.v1.Admission.classes:type_name -> goddess.config.v1.PriorityClass
+	79,  // 129: goddess.config.v1.AdaptiveConcurrency.target_latency:type_name -> google.protobuf.Duration
+	79,  // 130: goddess.config.v1.AdaptiveConcurrency.window:type_name -> google.protobuf.Duration
+	79,  // 131: goddess.config.v1.AdaptiveConcurrency.retry_after:type_name -> google.protobuf.Duration
+	79,  // 132: goddess.config.v1.PriorityClass.max_wait:type_name -> google.protobuf.Duration
+	28,  // 133: goddess.config.v1.Gateway.TlsStoreEntry.value:type_name -> goddess.config.v1.TLS
+	20,  // 134: goddess.config.v1.Gateway.HostOverridesEntry.value:type_name -> goddess.config.v1.HostOverride
+	25,  // 135: goddess.config.v1.Fallback.HostsEntry.value:type_name -> goddess.config.v1.Fallback
+	71,  // 136: goddess.config.v1.FallbackAction.Static.headers:type_name -> goddess.config.v1.FallbackAction.Static.HeadersEntry
+	20,  // 137: goddess.config.v1.Endpoint.HostOverridesEntry.value:type_name -> goddess.config.v1.HostOverride
+	138, // [138:138] is the sub-list for method output_type
+	138, // [138:138] is the sub-list for method input_type
+	138, // [138:138] is the sub-list for extension type_name
+	138, // [138:138] is the sub-list for extension extendee
+	0,   // [0:138] is the sub-list for field type_name
 }
 
 func init() { file_config_v1_gateway_proto_init() }
@@ -1030,18 +6650,28 @@ func file_config_v1_gateway_proto_init() {
 	if File_config_v1_gateway_proto != nil {
 		return
 	}
-	file_config_v1_gateway_proto_msgTypes[5].OneofWrappers = []any{}
-	file_config_v1_gateway_proto_msgTypes[8].OneofWrappers = []any{
+	file_config_v1_gateway_proto_msgTypes[13].OneofWrappers = []any{
+		(*FallbackAction_Static_)(nil),
+		(*FallbackAction_Redirect_)(nil),
+		(*FallbackAction_Endpoint)(nil),
+	}
+	file_config_v1_gateway_proto_msgTypes[42].OneofWrappers = []any{}
+	file_config_v1_gateway_proto_msgTypes[47].OneofWrappers = []any{
 		(*Condition_ByStatusCode)(nil),
 		(*Condition_ByHeader)(nil),
+		(*Condition_ByBodyContains)(nil),
+	}
+	file_config_v1_gateway_proto_msgTypes[49].OneofWrappers = []any{
+		(*Admission_Header)(nil),
+		(*Admission_JwtClaim)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_config_v1_gateway_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   13,
+			NumEnums:      13,
+			NumMessages:   65,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
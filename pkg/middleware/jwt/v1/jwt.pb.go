@@ -23,11 +23,13 @@ const (
 
 // Jwt middleware config.
 type Jwt struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Secret        string                 `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
-	ExpireTime    *durationpb.Duration   `protobuf:"bytes,2,opt,name=expireTime,proto3" json:"expireTime,omitempty"`
-	Issuer        string                 `protobuf:"bytes,3,opt,name=issuer,proto3" json:"issuer,omitempty"`
-	Algorithms    []string               `protobuf:"bytes,4,rep,name=algorithms,proto3" json:"algorithms,omitempty"`
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Secret     string                 `protobuf:"bytes,1,opt,name=secret,proto3" json:"secret,omitempty"`
+	ExpireTime *durationpb.Duration   `protobuf:"bytes,2,opt,name=expireTime,proto3" json:"expireTime,omitempty"`
+	Issuer     string                 `protobuf:"bytes,3,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	Algorithms []string               `protobuf:"bytes,4,rep,name=algorithms,proto3" json:"algorithms,omitempty"`
+	// revocation rejects the revoked tokens, disabled if not set.
+	Revocation    *Revocation `protobuf:"bytes,5,opt,name=revocation,proto3" json:"revocation,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -90,6 +92,210 @@ func (x *Jwt) GetAlgorithms() []string {
 	return nil
 }
 
+func (x *Jwt) GetRevocation() *Revocation {
+	if x != nil {
+		return x.Revocation
+	}
+	return nil
+}
+
+// Revocation is the denylist of the tokens keyed by jti or the sha256 of the raw token.
+type Revocation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Store:
+	//
+	//	*Revocation_Memory_
+	//	*Revocation_Redis_
+	Store isRevocation_Store `protobuf_oneof:"store"`
+	// rejects the request if the store is unavailable, default is to accept.
+	FailClosed    bool `protobuf:"varint,3,opt,name=fail_closed,json=failClosed,proto3" json:"fail_closed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revocation) Reset() {
+	*x = Revocation{}
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revocation) ProtoMessage() {}
+
+func (x *Revocation) ProtoReflect() protoreflect.Message {
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revocation.ProtoReflect.Descriptor instead.
+func (*Revocation) Descriptor() ([]byte, []int) {
+	return file_middleware_jwt_v1_jwt_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Revocation) GetStore() isRevocation_Store {
+	if x != nil {
+		return x.Store
+	}
+	return nil
+}
+
+func (x *Revocation) GetMemory() *Revocation_Memory {
+	if x != nil {
+		if x, ok := x.Store.(*Revocation_Memory_); ok {
+			return x.Memory
+		}
+	}
+	return nil
+}
+
+func (x *Revocation) GetRedis() *Revocation_Redis {
+	if x != nil {
+		if x, ok := x.Store.(*Revocation_Redis_); ok {
+			return x.Redis
+		}
+	}
+	return nil
+}
+
+func (x *Revocation) GetFailClosed() bool {
+	if x != nil {
+		return x.FailClosed
+	}
+	return false
+}
+
+type isRevocation_Store interface {
+	isRevocation_Store()
+}
+
+type Revocation_Memory_ struct {
+	Memory *Revocation_Memory `protobuf:"bytes,1,opt,name=memory,proto3,oneof"`
+}
+
+type Revocation_Redis_ struct {
+	Redis *Revocation_Redis `protobuf:"bytes,2,opt,name=redis,proto3,oneof"`
+}
+
+func (*Revocation_Memory_) isRevocation_Store() {}
+
+func (*Revocation_Redis_) isRevocation_Store() {}
+
+type Revocation_Memory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revocation_Memory) Reset() {
+	*x = Revocation_Memory{}
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revocation_Memory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revocation_Memory) ProtoMessage() {}
+
+func (x *Revocation_Memory) ProtoReflect() protoreflect.Message {
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revocation_Memory.ProtoReflect.Descriptor instead.
+func (*Revocation_Memory) Descriptor() ([]byte, []int) {
+	return file_middleware_jwt_v1_jwt_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type Revocation_Redis struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Addr     string                 `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	Password string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Db       int32                  `protobuf:"varint,3,opt,name=db,proto3" json:"db,omitempty"`
+	// default is "goddess:jwt:revoked:"
+	KeyPrefix     string `protobuf:"bytes,4,opt,name=key_prefix,json=keyPrefix,proto3" json:"key_prefix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Revocation_Redis) Reset() {
+	*x = Revocation_Redis{}
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Revocation_Redis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Revocation_Redis) ProtoMessage() {}
+
+func (x *Revocation_Redis) ProtoReflect() protoreflect.Message {
+	mi := &file_middleware_jwt_v1_jwt_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Revocation_Redis.ProtoReflect.Descriptor instead.
+func (*Revocation_Redis) Descriptor() ([]byte, []int) {
+	return file_middleware_jwt_v1_jwt_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *Revocation_Redis) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *Revocation_Redis) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *Revocation_Redis) GetDb() int32 {
+	if x != nil {
+		return x.Db
+	}
+	return 0
+}
+
+func (x *Revocation_Redis) GetKeyPrefix() string {
+	if x != nil {
+		return x.KeyPrefix
+	}
+	return ""
+}
+
 var File_middleware_jwt_v1_jwt_proto protoreflect.FileDescriptor
 
 var file_middleware_jwt_v1_jwt_proto_rawDesc = []byte{
@@ -98,7 +304,7 @@ var file_middleware_jwt_v1_jwt_proto_rawDesc = []byte{
 	0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
 	0x65, 0x2e, 0x6a, 0x77, 0x74, 0x2e, 0x76, 0x31, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
 	0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x90, 0x01, 0x0a, 0x03, 0x4a, 0x77, 0x74,
+	0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xd7, 0x01, 0x0a, 0x03, 0x4a, 0x77, 0x74,
 	0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
 	0x72, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67,
@@ -107,11 +313,35 @@ var file_middleware_jwt_v1_jwt_proto_rawDesc = []byte{
 	0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x18, 0x03, 0x20,
 	0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x61,
 	0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x0a, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x73, 0x42, 0x36, 0x5a, 0x34, 0x67,
-	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x64, 0x65, 0x2d, 0x66,
-	0x61, 0x6d, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2f, 0x70, 0x6b,
-	0x67, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x6a, 0x77, 0x74,
-	0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x0a, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x73, 0x12, 0x45, 0x0a, 0x0a, 0x72,
+	0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x25, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2e, 0x6a, 0x77, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x6f,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x72, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0xb5, 0x02, 0x0a, 0x0a, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x46, 0x0a, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x2c, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x6d, 0x69, 0x64, 0x64,
+	0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x6a, 0x77, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65,
+	0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x48,
+	0x00, 0x52, 0x06, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x43, 0x0a, 0x05, 0x72, 0x65, 0x64,
+	0x69, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65,
+	0x73, 0x73, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x6a, 0x77,
+	0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x76, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x52, 0x65, 0x64, 0x69, 0x73, 0x48, 0x00, 0x52, 0x05, 0x72, 0x65, 0x64, 0x69, 0x73, 0x12, 0x1f,
+	0x0a, 0x0b, 0x66, 0x61, 0x69, 0x6c, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x0a, 0x66, 0x61, 0x69, 0x6c, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x64, 0x1a,
+	0x08, 0x0a, 0x06, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x1a, 0x66, 0x0a, 0x05, 0x52, 0x65, 0x64,
+	0x69, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f,
+	0x72, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x64, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02,
+	0x64, 0x62, 0x12, 0x1d, 0x0a, 0x0a, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6b, 0x65, 0x79, 0x50, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x42, 0x07, 0x0a, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x64, 0x65, 0x2d, 0x66, 0x61,
+	0x6d, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2f, 0x70, 0x6b, 0x67,
+	0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x6a, 0x77, 0x74, 0x2f,
+	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -126,18 +356,24 @@ func file_middleware_jwt_v1_jwt_proto_rawDescGZIP() []byte {
 	return file_middleware_jwt_v1_jwt_proto_rawDescData
 }
 
-var file_middleware_jwt_v1_jwt_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_middleware_jwt_v1_jwt_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_middleware_jwt_v1_jwt_proto_goTypes = []any{
 	(*Jwt)(nil),                 // 0: goddess.middleware.jwt.v1.Jwt
-	(*durationpb.Duration)(nil), // 1: google.protobuf.Duration
+	(*Revocation)(nil),          // 1: goddess.middleware.jwt.v1.Revocation
+	(*Revocation_Memory)(nil),   // 2: goddess.middleware.jwt.v1.Revocation.Memory
+	(*Revocation_Redis)(nil),    // 3: goddess.middleware.jwt.v1.Revocation.Redis
+	(*durationpb.Duration)(nil), // 4: google.protobuf.Duration
 }
 var file_middleware_jwt_v1_jwt_proto_depIdxs = []int32{
-	1, // 0: goddess.middleware.jwt.v1.Jwt.expireTime:type_name -> google.protobuf.Duration
-	1, // [1:1] is the sub-list for method output_type
-	1, // [1:1] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 0: goddess.middleware.jwt.v1.Jwt.expireTime:type_name -> google.protobuf.Duration
+	1, // 1: goddess.middleware.jwt.v1.Jwt.revocation:type_name -> goddess.middleware.jwt.v1.Revocation
+	2, // 2: goddess.middleware.jwt.v1.Revocation.memory:type_name -> goddess.middleware.jwt.v1.Revocation.Memory
+	3, // 3: goddess.middleware.jwt.v1.Revocation.redis:type_name -> goddess.middleware.jwt.v1.Revocation.Redis
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_middleware_jwt_v1_jwt_proto_init() }
@@ -145,13 +381,17 @@ func file_middleware_jwt_v1_jwt_proto_init() {
 	if File_middleware_jwt_v1_jwt_proto != nil {
 		return
 	}
+	file_middleware_jwt_v1_jwt_proto_msgTypes[1].OneofWrappers = []any{
+		(*Revocation_Memory_)(nil),
+		(*Revocation_Redis_)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_middleware_jwt_v1_jwt_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   1,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
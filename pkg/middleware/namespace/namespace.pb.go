@@ -124,8 +124,21 @@ type ValidateApi struct {
 	BodyTemplate string `protobuf:"bytes,5,opt,name=bodyTemplate,proto3" json:"bodyTemplate,omitempty"`
 	// Expected success status codes (default: [200])
 	SuccessStatusCodes []int32 `protobuf:"varint,6,rep,packed,name=successStatusCodes,proto3" json:"successStatusCodes,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+	// Max concurrent validation calls, unlimited if 0
+	MaxConcurrency uint32 `protobuf:"varint,7,opt,name=maxConcurrency,proto3" json:"maxConcurrency,omitempty"`
+	// Max validation calls per second, unlimited if 0
+	RateLimit float64 `protobuf:"fixed64,8,opt,name=rateLimit,proto3" json:"rateLimit,omitempty"`
+	// Burst of the validation calls over the rate limit (default: 1)
+	Burst uint32 `protobuf:"varint,9,opt,name=burst,proto3" json:"burst,omitempty"`
+	// Max time a request waits for the concurrency or the rate limit (default: 100ms)
+	QueueTimeout *durationpb.Duration `protobuf:"bytes,10,opt,name=queueTimeout,proto3" json:"queueTimeout,omitempty"`
+	// Accept the requests when the validation is limited or the circuit breaker is open,
+	// default is to reject them with 503
+	FailOpen bool `protobuf:"varint,11,opt,name=failOpen,proto3" json:"failOpen,omitempty"`
+	// Circuit breaker of the validation API, disabled if not set
+	CircuitBreaker *ValidateBreaker `protobuf:"bytes,12,opt,name=circuitBreaker,proto3" json:"circuitBreaker,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *ValidateApi) Reset() {
@@ -200,6 +213,113 @@ func (x *ValidateApi) GetSuccessStatusCodes() []int32 {
 	return nil
 }
 
+func (x *ValidateApi) GetMaxConcurrency() uint32 {
+	if x != nil {
+		return x.MaxConcurrency
+	}
+	return 0
+}
+
+func (x *ValidateApi) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+func (x *ValidateApi) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+func (x *ValidateApi) GetQueueTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.QueueTimeout
+	}
+	return nil
+}
+
+func (x *ValidateApi) GetFailOpen() bool {
+	if x != nil {
+		return x.FailOpen
+	}
+	return false
+}
+
+func (x *ValidateApi) GetCircuitBreaker() *ValidateBreaker {
+	if x != nil {
+		return x.CircuitBreaker
+	}
+	return nil
+}
+
+// ValidateBreaker is the sre circuit breaker of the validation API, the transport errors
+// and the 5xx responses are the failures.
+type ValidateBreaker struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Success ratio below which the breaker opens (default: 0.6)
+	Success float64 `protobuf:"fixed64,1,opt,name=success,proto3" json:"success,omitempty"`
+	// Min requests in the window before the breaker opens (default: 20)
+	Request int64 `protobuf:"varint,2,opt,name=request,proto3" json:"request,omitempty"`
+	// Stat window (default: 3s)
+	Window        *durationpb.Duration `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateBreaker) Reset() {
+	*x = ValidateBreaker{}
+	mi := &file_middleware_namespace_namespace_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateBreaker) ProtoMessage() {}
+
+func (x *ValidateBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_middleware_namespace_namespace_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateBreaker.ProtoReflect.Descriptor instead.
+func (*ValidateBreaker) Descriptor() ([]byte, []int) {
+	return file_middleware_namespace_namespace_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ValidateBreaker) GetSuccess() float64 {
+	if x != nil {
+		return x.Success
+	}
+	return 0
+}
+
+func (x *ValidateBreaker) GetRequest() int64 {
+	if x != nil {
+		return x.Request
+	}
+	return 0
+}
+
+func (x *ValidateBreaker) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
 var File_middleware_namespace_namespace_proto protoreflect.FileDescriptor
 
 var file_middleware_namespace_namespace_proto_rawDesc = []byte{
@@ -223,7 +343,7 @@ var file_middleware_namespace_namespace_proto_rawDesc = []byte{
 	0x65, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x69,
 	0x52, 0x0b, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x69, 0x12, 0x1a, 0x0a,
 	0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x22, 0xd1, 0x02, 0x0a, 0x0b, 0x56, 0x61,
+	0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x22, 0xe2, 0x04, 0x0a, 0x0b, 0x56, 0x61,
 	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x41, 0x70, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c,
 	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x16, 0x0a, 0x06, 0x6d,
 	0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74,
@@ -241,15 +361,39 @@ var file_middleware_namespace_namespace_proto_rawDesc = []byte{
 	0x65, 0x12, 0x2e, 0x0a, 0x12, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74,
 	0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x05, 0x52, 0x12, 0x73,
 	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65,
-	0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72,
-	0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
-	0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x3c, 0x5a,
-	0x3a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x64, 0x65,
-	0x2d, 0x66, 0x61, 0x6d, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2f,
-	0x70, 0x6b, 0x67, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x6e,
-	0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	0x73, 0x12, 0x26, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x63, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x43, 0x6f,
+	0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x61, 0x74,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x72, 0x61,
+	0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x12, 0x3d, 0x0a,
+	0x0c, 0x71, 0x75, 0x65, 0x75, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x1a, 0x0a, 0x08,
+	0x66, 0x61, 0x69, 0x6c, 0x4f, 0x70, 0x65, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x66, 0x61, 0x69, 0x6c, 0x4f, 0x70, 0x65, 0x6e, 0x12, 0x58, 0x0a, 0x0e, 0x63, 0x69, 0x72, 0x63,
+	0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x30, 0x2e, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c,
+	0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x2e,
+	0x76, 0x31, 0x2e, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b,
+	0x65, 0x72, 0x52, 0x0e, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72, 0x65, 0x61, 0x6b,
+	0x65, 0x72, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x78,
+	0x0a, 0x0f, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65,
+	0x72, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x42, 0x3c, 0x5a, 0x3a, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x69, 0x64, 0x65, 0x2d, 0x66, 0x61, 0x6d, 0x69,
+	0x6c, 0x79, 0x2f, 0x67, 0x6f, 0x64, 0x64, 0x65, 0x73, 0x73, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6d,
+	0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -264,22 +408,26 @@ func file_middleware_namespace_namespace_proto_rawDescGZIP() []byte {
 	return file_middleware_namespace_namespace_proto_rawDescData
 }
 
-var file_middleware_namespace_namespace_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_middleware_namespace_namespace_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_middleware_namespace_namespace_proto_goTypes = []any{
 	(*Namespace)(nil),           // 0: goddess.middleware.namespace.v1.Namespace
 	(*ValidateApi)(nil),         // 1: goddess.middleware.namespace.v1.ValidateApi
-	nil,                         // 2: goddess.middleware.namespace.v1.ValidateApi.HeadersEntry
-	(*durationpb.Duration)(nil), // 3: google.protobuf.Duration
+	(*ValidateBreaker)(nil),     // 2: goddess.middleware.namespace.v1.ValidateBreaker
+	nil,                         // 3: goddess.middleware.namespace.v1.ValidateApi.HeadersEntry
+	(*durationpb.Duration)(nil), // 4: google.protobuf.Duration
 }
 var file_middleware_namespace_namespace_proto_depIdxs = []int32{
 	1, // 0: goddess.middleware.namespace.v1.Namespace.validateApi:type_name -> goddess.middleware.namespace.v1.ValidateApi
-	3, // 1: goddess.middleware.namespace.v1.ValidateApi.timeout:type_name -> google.protobuf.Duration
-	2, // 2: goddess.middleware.namespace.v1.ValidateApi.headers:type_name -> goddess.middleware.namespace.v1.ValidateApi.HeadersEntry
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	4, // 1: goddess.middleware.namespace.v1.ValidateApi.timeout:type_name -> google.protobuf.Duration
+	3, // 2: goddess.middleware.namespace.v1.ValidateApi.headers:type_name -> goddess.middleware.namespace.v1.ValidateApi.HeadersEntry
+	4, // 3: goddess.middleware.namespace.v1.ValidateApi.queueTimeout:type_name -> google.protobuf.Duration
+	2, // 4: goddess.middleware.namespace.v1.ValidateApi.circuitBreaker:type_name -> goddess.middleware.namespace.v1.ValidateBreaker
+	4, // 5: goddess.middleware.namespace.v1.ValidateBreaker.window:type_name -> google.protobuf.Duration
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_middleware_namespace_namespace_proto_init() }
@@ -293,7 +441,7 @@ func file_middleware_namespace_namespace_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_middleware_namespace_namespace_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
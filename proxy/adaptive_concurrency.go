@@ -0,0 +1,221 @@
+package proxy
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrAdaptiveConcurrencyExceeded is returned when the endpoint is at its adaptive concurrency limit.
+var ErrAdaptiveConcurrencyExceeded = errors.New("adaptive concurrency limit exceeded")
+
+const (
+	_defaultAdaptiveWindow      = time.Second
+	_defaultAdaptiveMinRequests = 10
+	_defaultAdaptiveBackoff     = 0.5
+)
+
+var (
+	_metricAdaptiveLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "adaptive_concurrency_limit",
+		Help:      "The current adaptive concurrency limit of the endpoint",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+	_metricAdaptiveRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "adaptive_concurrency_rejected_total",
+		Help:      "The total number of requests rejected by the adaptive concurrency limit",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricAdaptiveLimit)
+	goddessmetrics.Register(_metricAdaptiveRejected)
+}
+
+// adaptiveLimiter limits the concurrent requests of the endpoint, the limit is adjusted by AIMD once per window
+// from the requests observed by the endpoint observer. The limit restarts from the max limit after a config update.
+type adaptiveLimiter struct {
+	minLimit      float64
+	maxLimit      float64
+	targetLatency time.Duration
+	maxErrorRate  float64
+	window        time.Duration
+	minRequests   int
+	backoff       float64
+	retryAfter    time.Duration
+	labels        middleware.MetricsLabels
+	now           func() time.Time
+
+	lock        sync.Mutex
+	limit       float64
+	inflight    int
+	windowStart time.Time
+	requests    int
+	errors      int
+	latency     time.Duration
+}
+
+func newAdaptiveLimiter(e *config.Endpoint) (*adaptiveLimiter, error) {
+	c := e.AdaptiveConcurrency
+	if c == nil {
+		return nil, nil
+	}
+	if c.MaxLimit == 0 {
+		return nil, errors.New("adaptive concurrency requires max_limit to be greater than 0")
+	}
+	if c.MinLimit > c.MaxLimit {
+		return nil, errors.New("adaptive concurrency requires min_limit not greater than max_limit")
+	}
+	if c.BackoffRatio < 0 || c.BackoffRatio >= 1 {
+		return nil, errors.New("adaptive concurrency requires backoff_ratio in [0, 1)")
+	}
+	l := &adaptiveLimiter{
+		minLimit:     math.Max(float64(c.MinLimit), 1),
+		maxLimit:     float64(c.MaxLimit),
+		maxErrorRate: c.MaxErrorRate,
+		window:       _defaultAdaptiveWindow,
+		minRequests:  _defaultAdaptiveMinRequests,
+		backoff:      _defaultAdaptiveBackoff,
+		labels:       middleware.NewMetricsLabels(e),
+		now:          time.Now,
+	}
+	if c.TargetLatency != nil {
+		l.targetLatency = c.TargetLatency.AsDuration()
+	}
+	if c.Window != nil && c.Window.AsDuration() > 0 {
+		l.window = c.Window.AsDuration()
+	}
+	if c.MinRequests > 0 {
+		l.minRequests = int(c.MinRequests)
+	}
+	if c.BackoffRatio > 0 {
+		l.backoff = c.BackoffRatio
+	}
+	l.retryAfter = l.window
+	if c.RetryAfter != nil && c.RetryAfter.AsDuration() > 0 {
+		l.retryAfter = c.RetryAfter.AsDuration()
+	}
+	l.limit = l.maxLimit
+	l.windowStart = l.now()
+	_metricAdaptiveLimit.WithLabelValues(l.metricLabels()...).Set(l.limit)
+	return l, nil
+}
+
+func (l *adaptiveLimiter) metricLabels() []string {
+	return []string{l.labels.Protocol(), l.labels.Method(), l.labels.Path(), l.labels.Service(), l.labels.BasePath()}
+}
+
+// acquire admits the request if the endpoint is under the limit, release must be called once the request completes.
+func (l *adaptiveLimiter) acquire() (release func(), ok bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if float64(l.inflight) >= math.Floor(l.limit) {
+		_metricAdaptiveRejected.WithLabelValues(l.metricLabels()...).Inc()
+		return nil, false
+	}
+	l.inflight++
+	return func() {
+		l.lock.Lock()
+		l.inflight--
+		l.lock.Unlock()
+	}, true
+}
+
+func (l *adaptiveLimiter) currentLimit() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return int(l.limit)
+}
+
+// record adds the request to the current window and adjusts the limit once the window ends.
+func (l *adaptiveLimiter) record(latency time.Duration, failed bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.requests++
+	l.latency += latency
+	if failed {
+		l.errors++
+	}
+	now := l.now()
+	if now.Sub(l.windowStart) < l.window {
+		return
+	}
+	if l.requests >= l.minRequests {
+		avgLatency := l.latency / time.Duration(l.requests)
+		errorRate := float64(l.errors) / float64(l.requests)
+		if (l.targetLatency > 0 && avgLatency > l.targetLatency) || (l.maxErrorRate > 0 && errorRate > l.maxErrorRate) {
+			l.limit = math.Max(l.minLimit, l.limit*l.backoff)
+		} else {
+			l.limit = math.Min(l.maxLimit, l.limit+1)
+		}
+		_metricAdaptiveLimit.WithLabelValues(l.metricLabels()...).Set(l.limit)
+	}
+	l.windowStart, l.requests, l.errors, l.latency = now, 0, 0, 0
+}
+
+// observer feeds the limiter from the endpoint observer, the rejections of the limiter are reported to the
+// underlying observer directly so they are not counted as the upstream failures.
+func (l *adaptiveLimiter) observer(next Observer) Observer {
+	return &adaptiveObserver{Observer: next, limiter: l}
+}
+
+type adaptiveObserver struct {
+	Observer
+	limiter *adaptiveLimiter
+}
+
+type adaptiveFailedKey struct{}
+
+func (o *adaptiveObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	o.Observer.HandleRequest(req, responseHeader, statusCode, err)
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok && isUpstreamFailure(statusCode, err) {
+		opts.Values.Set(adaptiveFailedKey{}, true)
+	}
+}
+
+func (o *adaptiveObserver) HandleLatency(req *http.Request, latency time.Duration) {
+	o.Observer.HandleLatency(req, latency)
+	failed := false
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok {
+		_, failed = opts.Values.Get(adaptiveFailedKey{})
+	}
+	o.limiter.record(latency, failed)
+}
+
+// isUpstreamFailure reports whether the request failed because of the upstream,
+// the cancellations of the clients and the rejections of the gateway are not.
+func isUpstreamFailure(statusCode int, err error) bool {
+	switch ClassifyError(err) {
+	case ErrorClassUpstreamConnect, ErrorClassUpstreamTimeout, ErrorClassUpstreamHeaderTimeout, ErrorClassUpstreamReset:
+		return true
+	case ErrorClassNone:
+		return statusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+func writeAdaptiveConcurrencyError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, retryAfter time.Duration, observer Observer) {
+	statusCode := http.StatusServiceUnavailable
+	observer.HandleRequest(r, w.Header(), statusCode, ErrAdaptiveConcurrencyExceeded)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", ErrAdaptiveConcurrencyExceeded.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Error(w, ErrAdaptiveConcurrencyExceeded.Error(), statusCode)
+}
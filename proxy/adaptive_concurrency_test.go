@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+type adaptiveSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+func newTestAdaptiveLimiter(t *testing.T, c *config.AdaptiveConcurrency) (*adaptiveLimiter, func(samples ...adaptiveSample)) {
+	t.Helper()
+	l, err := newAdaptiveLimiter(&config.Endpoint{Path: "/test", Method: http.MethodGet, AdaptiveConcurrency: c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	l.windowStart = now
+	// replays the samples spread over one window
+	window := func(samples ...adaptiveSample) {
+		for i, s := range samples {
+			if i == len(samples)-1 {
+				now = now.Add(l.window)
+			}
+			l.record(s.latency, s.failed)
+		}
+	}
+	return l, window
+}
+
+func repeatSample(s adaptiveSample, n int) []adaptiveSample {
+	out := make([]adaptiveSample, n)
+	for i := range out {
+		out[i] = s
+	}
+	return out
+}
+
+func TestAdaptiveLimiterLatency(t *testing.T) {
+	l, window := newTestAdaptiveLimiter(t, &config.AdaptiveConcurrency{
+		MinLimit:      2,
+		MaxLimit:      20,
+		TargetLatency: durationpb.New(100 * time.Millisecond),
+	})
+	slow := adaptiveSample{latency: 300 * time.Millisecond}
+	fast := adaptiveSample{latency: 10 * time.Millisecond}
+
+	// multiplicative decrease down to the min limit while the upstream is slow
+	for _, want := range []int{10, 5, 2, 2} {
+		window(repeatSample(slow, 10)...)
+		if got := l.currentLimit(); got != want {
+			t.Fatalf("want limit %d after a slow window but got %d", want, got)
+		}
+	}
+	// additive increase once the upstream recovers
+	for _, want := range []int{3, 4, 5} {
+		window(repeatSample(fast, 10)...)
+		if got := l.currentLimit(); got != want {
+			t.Fatalf("want limit %d after a fast window but got %d", want, got)
+		}
+	}
+	// too few requests to judge the window
+	window(repeatSample(slow, 3)...)
+	if got := l.currentLimit(); got != 5 {
+		t.Fatalf("want the limit kept but got %d", got)
+	}
+}
+
+func TestAdaptiveLimiterErrorRate(t *testing.T) {
+	l, window := newTestAdaptiveLimiter(t, &config.AdaptiveConcurrency{
+		MaxLimit:     8,
+		MaxErrorRate: 0.2,
+		BackoffRatio: 0.75,
+	})
+	ok := adaptiveSample{latency: time.Millisecond}
+	failed := adaptiveSample{latency: time.Millisecond, failed: true}
+
+	// 1 of 10 failed is under the error rate
+	window(append(repeatSample(ok, 9), failed)...)
+	if got := l.currentLimit(); got != 8 {
+		t.Fatalf("want the limit capped at the max but got %d", got)
+	}
+	window(append(repeatSample(ok, 5), repeatSample(failed, 5)...)...)
+	if got := l.currentLimit(); got != 6 {
+		t.Fatalf("want limit 6 after a failing window but got %d", got)
+	}
+	window(append(repeatSample(ok, 5), repeatSample(failed, 5)...)...)
+	if got := l.currentLimit(); got != 4 {
+		t.Fatalf("want limit 4 after a failing window but got %d", got)
+	}
+}
+
+func TestAdaptiveLimiterAcquire(t *testing.T) {
+	l, window := newTestAdaptiveLimiter(t, &config.AdaptiveConcurrency{
+		MaxLimit:      4,
+		TargetLatency: durationpb.New(time.Millisecond),
+	})
+	window(repeatSample(adaptiveSample{latency: time.Second}, 10)...)
+	release, ok := l.acquire()
+	if !ok {
+		t.Fatal("want the first request admitted")
+	}
+	l.acquire()
+	if _, ok := l.acquire(); ok {
+		t.Fatal("want the request over the limit rejected")
+	}
+	release()
+	if _, ok := l.acquire(); !ok {
+		t.Fatal("want the request admitted once released")
+	}
+
+	w := httptest.NewRecorder()
+	writeAdaptiveConcurrencyError(w, httptest.NewRequest(http.MethodGet, "/test", nil), &config.Endpoint{Path: "/test"}, 1500*time.Millisecond, NewObservable().Observe(&config.Endpoint{}))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("want 503 with Retry-After but got %d %v", w.Code, w.Header())
+	}
+}
+
+func TestIsUpstreamFailure(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		err        error
+		want       bool
+	}{
+		{http.StatusOK, nil, false},
+		{http.StatusNotFound, nil, false},
+		{http.StatusBadGateway, nil, true},
+		{http.StatusServiceUnavailable, ErrAdaptiveConcurrencyExceeded, false},
+		{http.StatusServiceUnavailable, ErrBufferBudgetExceeded, false},
+	}
+	for _, test := range tests {
+		if got := isUpstreamFailure(test.statusCode, test.err); got != test.want {
+			t.Errorf("%d %v: want %t but got %t", test.statusCode, test.err, test.want, got)
+		}
+	}
+}
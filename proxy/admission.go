@@ -0,0 +1,281 @@
+package proxy
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ErrAdmissionQueueFull is returned when the admission queue is full.
+	ErrAdmissionQueueFull = errors.New("admission queue is full")
+	// ErrAdmissionWaitTimeout is returned when the max wait of the priority class is exceeded.
+	ErrAdmissionWaitTimeout = errors.New("admission wait timeout")
+)
+
+var (
+	_metricAdmissionQueueDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "admission_queue_depth",
+		Help:      "The number of requests waiting for admission",
+	}, []string{"protocol", "method", "path", "service", "basePath", "class"})
+	_metricAdmissionWaitSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "admission_wait_seconds",
+		Help:      "Time spent waiting for admission(sec).",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"protocol", "method", "path", "service", "basePath", "class"})
+	_metricAdmissionRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "admission_rejected_total",
+		Help:      "The total number of requests rejected by admission",
+	}, []string{"protocol", "method", "path", "service", "basePath", "class", "reason"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricAdmissionQueueDepth)
+	goddessmetrics.Register(_metricAdmissionWaitSeconds)
+	goddessmetrics.Register(_metricAdmissionRejected)
+}
+
+type admissionClass struct {
+	name    string
+	maxWait time.Duration
+}
+
+type admissionWaiter struct {
+	class int
+	ready chan error
+}
+
+// admission is a bounded priority queue in front of the endpoint handler,
+// higher classes are dequeued first and lower classes are evicted when the queue is full.
+type admission struct {
+	lock      sync.Mutex
+	inflight  int
+	limit     int
+	queueSize int
+	queued    int
+	queues    [][]*admissionWaiter
+	classes   []*admissionClass
+	classify  func(*http.Request) int
+	labels    middleware.MetricsLabels
+}
+
+func newAdmission(e *config.Endpoint) (*admission, error) {
+	c := e.Admission
+	if c == nil {
+		return nil, nil
+	}
+	if c.MaxConcurrency == 0 {
+		return nil, errors.New("admission requires max_concurrency to be greater than 0")
+	}
+	if len(c.Classes) == 0 {
+		return nil, errors.New("admission requires at least one priority class")
+	}
+	a := &admission{
+		limit:     int(c.MaxConcurrency),
+		queueSize: int(c.QueueSize),
+		queues:    make([][]*admissionWaiter, len(c.Classes)),
+		classes:   make([]*admissionClass, 0, len(c.Classes)),
+		labels:    middleware.NewMetricsLabels(e),
+	}
+	defaultClass := len(c.Classes) - 1
+	values := map[string]int{}
+	for i, class := range c.Classes {
+		maxWait := calcTimeout(e)
+		if class.MaxWait != nil && class.MaxWait.AsDuration() > 0 {
+			maxWait = class.MaxWait.AsDuration()
+		}
+		a.classes = append(a.classes, &admissionClass{name: class.Name, maxWait: maxWait})
+		for _, v := range class.Values {
+			values[v] = i
+		}
+		if c.DefaultClass != "" && class.Name == c.DefaultClass {
+			defaultClass = i
+		}
+	}
+	attribute := func(*http.Request) string { return "" }
+	switch classifier := c.Classifier.(type) {
+	case *config.Admission_Header:
+		attribute = func(req *http.Request) string { return req.Header.Get(classifier.Header) }
+	case *config.Admission_JwtClaim:
+		attribute = func(req *http.Request) string { return unverifiedJWTClaim(req, classifier.JwtClaim) }
+	}
+	a.classify = func(req *http.Request) int {
+		if i, ok := values[attribute(req)]; ok {
+			return i
+		}
+		return defaultClass
+	}
+	return a, nil
+}
+
+// unverifiedJWTClaim reads a claim from the bearer token without verifying the signature,
+// it is only used for classifying requests, the token is still verified by the jwt middleware.
+func unverifiedJWTClaim(req *http.Request, claim string) string {
+	auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(auths) != 2 || !strings.EqualFold(auths[0], "Bearer") {
+		return ""
+	}
+	parts := strings.Split(auths[1], ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := map[string]any{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	v, ok := claims[claim]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func (a *admission) metricLabels(class int) []string {
+	return []string{a.labels.Protocol(), a.labels.Method(), a.labels.Path(), a.labels.Service(), a.labels.BasePath(), a.classes[class].name}
+}
+
+func (a *admission) reject(class int, reason string) {
+	_metricAdmissionRejected.WithLabelValues(append(a.metricLabels(class), reason)...).Inc()
+}
+
+// Acquire admits the request or waits in the queue of its priority class,
+// the returned release function must be called once the request is completed.
+func (a *admission) Acquire(ctx context.Context, req *http.Request) (func(), error) {
+	class := a.classify(req)
+	a.lock.Lock()
+	if a.inflight < a.limit {
+		a.inflight++
+		a.lock.Unlock()
+		return a.release, nil
+	}
+	if a.queued >= a.queueSize && !a.evictLowerLocked(class) {
+		a.lock.Unlock()
+		a.reject(class, "queue_full")
+		return nil, ErrAdmissionQueueFull
+	}
+	w := &admissionWaiter{class: class, ready: make(chan error, 1)}
+	a.queues[class] = append(a.queues[class], w)
+	a.queued++
+	_metricAdmissionQueueDepth.WithLabelValues(a.metricLabels(class)...).Inc()
+	a.lock.Unlock()
+
+	startAt := time.Now()
+	timer := time.NewTimer(a.classes[class].maxWait)
+	defer timer.Stop()
+	var err error
+	select {
+	case err = <-w.ready:
+		_metricAdmissionWaitSeconds.WithLabelValues(a.metricLabels(class)...).Observe(time.Since(startAt).Seconds())
+		if err != nil {
+			return nil, err
+		}
+		return a.release, nil
+	case <-timer.C:
+		err = ErrAdmissionWaitTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	_metricAdmissionWaitSeconds.WithLabelValues(a.metricLabels(class)...).Observe(time.Since(startAt).Seconds())
+	if !a.dequeue(w) {
+		// the waiter has been granted or evicted concurrently
+		if granted := <-w.ready; granted == nil {
+			a.release()
+		} else {
+			err = granted
+		}
+	}
+	if errors.Is(err, ErrAdmissionWaitTimeout) {
+		a.reject(class, "wait_timeout")
+	}
+	return nil, err
+}
+
+// evictLowerLocked evicts the newest waiter of the lowest class lower than the given class.
+func (a *admission) evictLowerLocked(class int) bool {
+	for i := len(a.queues) - 1; i > class; i-- {
+		q := a.queues[i]
+		if len(q) == 0 {
+			continue
+		}
+		w := q[len(q)-1]
+		a.queues[i] = q[:len(q)-1]
+		a.queued--
+		_metricAdmissionQueueDepth.WithLabelValues(a.metricLabels(i)...).Dec()
+		a.reject(i, "evicted")
+		w.ready <- ErrAdmissionQueueFull
+		return true
+	}
+	return false
+}
+
+func (a *admission) dequeue(w *admissionWaiter) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	q := a.queues[w.class]
+	for i, item := range q {
+		if item == w {
+			a.queues[w.class] = append(q[:i], q[i+1:]...)
+			a.queued--
+			_metricAdmissionQueueDepth.WithLabelValues(a.metricLabels(w.class)...).Dec()
+			return true
+		}
+	}
+	return false
+}
+
+func (a *admission) release() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, q := range a.queues {
+		if len(q) == 0 {
+			continue
+		}
+		// hand over the slot to the head of the highest class
+		w := q[0]
+		a.queues[i] = q[1:]
+		a.queued--
+		_metricAdmissionQueueDepth.WithLabelValues(a.metricLabels(i)...).Dec()
+		w.ready <- nil
+		return
+	}
+	a.inflight--
+}
+
+func writeAdmissionError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, err error, observer Observer) {
+	statusCode := http.StatusTooManyRequests
+	if errors.Is(err, context.Canceled) {
+		statusCode = 499
+	}
+	observer.HandleRequest(r, w.Header(), statusCode, err)
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", err.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Error(w, err.Error(), statusCode)
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestAdmission(t *testing.T, queueSize uint32) *admission {
+	a, err := newAdmission(&config.Endpoint{
+		Path: "/admission",
+		Admission: &config.Admission{
+			MaxConcurrency: 1,
+			QueueSize:      queueSize,
+			Classifier:     &config.Admission_Header{Header: "X-Priority"},
+			Classes: []*config.PriorityClass{
+				{Name: "paid", Values: []string{"paid"}},
+				{Name: "free", Values: []string{"free"}, MaxWait: durationpb.New(time.Second)},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func waitQueued(a *admission, n int) {
+	for {
+		a.lock.Lock()
+		queued := a.queued
+		a.lock.Unlock()
+		if queued >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAdmissionPriorityOrder(t *testing.T) {
+	a := newTestAdmission(t, 2)
+	release, err := a.Acquire(context.Background(), httptest.NewRequest("GET", "/admission", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := make(chan string, 2)
+	acquire := func(class string) {
+		r := httptest.NewRequest("GET", "/admission", nil)
+		r.Header.Set("X-Priority", class)
+		release, err := a.Acquire(context.Background(), r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		order <- class
+		release()
+	}
+	go acquire("free")
+	waitQueued(a, 1)
+	go acquire("paid")
+	waitQueued(a, 2)
+
+	release()
+	if first := <-order; first != "paid" {
+		t.Fatalf("want paid to be dequeued first but got: %s", first)
+	}
+	if second := <-order; second != "free" {
+		t.Fatalf("want free to be dequeued second but got: %s", second)
+	}
+}
+
+func TestAdmissionQueueFull(t *testing.T) {
+	a := newTestAdmission(t, 1)
+	release, err := a.Acquire(context.Background(), httptest.NewRequest("GET", "/admission", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	evicted := make(chan error, 1)
+	go func() {
+		r := httptest.NewRequest("GET", "/admission", nil)
+		r.Header.Set("X-Priority", "free")
+		_, err := a.Acquire(context.Background(), r)
+		evicted <- err
+	}()
+	waitQueued(a, 1)
+
+	// the queue is full, the free request is evicted by the paid one
+	paid := httptest.NewRequest("GET", "/admission", nil)
+	paid.Header.Set("X-Priority", "paid")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := a.Acquire(ctx, paid); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want paid request waiting until deadline but got: %v", err)
+	}
+	if err := <-evicted; !errors.Is(err, ErrAdmissionQueueFull) {
+		t.Fatalf("want free request evicted but got: %v", err)
+	}
+
+	// no lower class to evict, the free request is rejected immediately
+	go a.Acquire(context.Background(), paid)
+	waitQueued(a, 1)
+	free := httptest.NewRequest("GET", "/admission", nil)
+	free.Header.Set("X-Priority", "free")
+	if _, err := a.Acquire(context.Background(), free); !errors.Is(err, ErrAdmissionQueueFull) {
+		t.Fatalf("want free request rejected but got: %v", err)
+	}
+}
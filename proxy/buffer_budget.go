@@ -0,0 +1,208 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrBufferBudgetExceeded is returned when buffering the request body would exceed the gateway-wide budget.
+var ErrBufferBudgetExceeded = errors.New("buffered request bytes budget exceeded")
+
+const _defaultBufferRetryAfter = time.Second
+
+var (
+	_metricBufferedBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "buffered_request_bytes",
+		Help:      "The bytes of the request bodies currently buffered",
+	})
+	_metricBufferBudgetRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "buffer_budget_rejected_total",
+		Help:      "The total number of requests rejected by the buffered bytes budget",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricBufferedBytes)
+	goddessmetrics.Register(_metricBufferBudgetRejected)
+}
+
+// bufferBudget accounts the bytes of the buffered request bodies of all the endpoints,
+// it outlives the config updates so the bodies buffered before an update are still released.
+type bufferBudget struct {
+	used   atomic.Int64
+	config atomic.Pointer[config.BufferBudget]
+
+	lock     sync.Mutex
+	released chan struct{}
+}
+
+func newBufferBudget() *bufferBudget {
+	return &bufferBudget{released: make(chan struct{})}
+}
+
+func (b *bufferBudget) update(c *config.BufferBudget) {
+	b.config.Store(c)
+	// the waiters check against the new limit
+	b.notify()
+}
+
+func (b *bufferBudget) limit() int64 {
+	if c := b.config.Load(); c.GetMaxTotalBufferedBytes() > 0 {
+		return c.GetMaxTotalBufferedBytes()
+	}
+	return math.MaxInt64
+}
+
+func (b *bufferBudget) retryAfter() time.Duration {
+	if d := b.config.Load().GetRetryAfter(); d != nil && d.AsDuration() > 0 {
+		return d.AsDuration()
+	}
+	return _defaultBufferRetryAfter
+}
+
+func (b *bufferBudget) tryAcquire(n int64) bool {
+	limit := b.limit()
+	for {
+		used := b.used.Load()
+		if used+n > limit {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+n) {
+			_metricBufferedBytes.Add(float64(n))
+			return true
+		}
+	}
+}
+
+// acquire reserves n bytes, it waits up to the queue timeout for the bytes released by the other requests.
+func (b *bufferBudget) acquire(ctx context.Context, n int64) error {
+	if n > b.limit() {
+		// never fits
+		return ErrBufferBudgetExceeded
+	}
+	if b.tryAcquire(n) {
+		return nil
+	}
+	wait := b.config.Load().GetQueueTimeout().AsDuration()
+	if wait <= 0 {
+		return ErrBufferBudgetExceeded
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		b.lock.Lock()
+		released := b.released
+		b.lock.Unlock()
+		// checked again after the channel is taken so a release in between is not missed
+		if b.tryAcquire(n) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return ErrBufferBudgetExceeded
+		case <-released:
+		}
+	}
+}
+
+func (b *bufferBudget) release(n int64) {
+	if n == 0 {
+		return
+	}
+	b.used.Add(-n)
+	_metricBufferedBytes.Sub(float64(n))
+	b.notify()
+}
+
+func (b *bufferBudget) notify() {
+	b.lock.Lock()
+	close(b.released)
+	b.released = make(chan struct{})
+	b.lock.Unlock()
+}
+
+// usage returns the ratio of the buffered bytes to the budget, 0 if the budget is unlimited.
+func (b *bufferBudget) usage() float64 {
+	limit := b.limit()
+	if limit == math.MaxInt64 {
+		return 0
+	}
+	return float64(b.used.Load()) / float64(limit)
+}
+
+// readBody buffers the request body within the budget, the bytes are reserved by the Content-Length up front,
+// or as they arrive if the length is unknown. release returns the reserved bytes once the request completes.
+func (b *bufferBudget) readBody(req *http.Request) (body []byte, release func(), err error) {
+	var reserved int64
+	release = func() { b.release(reserved) }
+	if req.ContentLength > 0 {
+		if err := b.acquire(req.Context(), req.ContentLength); err != nil {
+			return nil, release, err
+		}
+		reserved = req.ContentLength
+		buf := bytes.NewBuffer(make([]byte, 0, req.ContentLength))
+		_, err = buf.ReadFrom(req.Body)
+		return buf.Bytes(), release, err
+	}
+	body, err = io.ReadAll(&budgetReader{ctx: req.Context(), r: req.Body, budget: b, reserved: &reserved})
+	return body, release, err
+}
+
+type budgetReader struct {
+	ctx      context.Context
+	r        io.Reader
+	budget   *bufferBudget
+	reserved *int64
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		if aerr := r.budget.acquire(r.ctx, int64(n)); aerr != nil {
+			return 0, aerr
+		}
+		*r.reserved += int64(n)
+	}
+	return n, err
+}
+
+func writeBufferBudgetError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, retryAfter time.Duration, observer Observer) {
+	statusCode := http.StatusServiceUnavailable
+	labels := []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"]}
+	_metricBufferBudgetRejected.WithLabelValues(labels...).Inc()
+	observer.HandleRequest(r, w.Header(), statusCode, ErrBufferBudgetExceeded)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", ErrBufferBudgetExceeded.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Error(w, ErrBufferBudgetExceeded.Error(), statusCode)
+}
+
+// BufferBudgetUsage returns the ratio of the buffered request bytes to max_total_buffered_bytes,
+// 0 if the budget is not configured.
+func (p *Proxy) BufferBudgetUsage() float64 {
+	return p.buffers.usage()
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestBufferBudget(t *testing.T) {
+	b := newBufferBudget()
+	b.update(&config.BufferBudget{MaxTotalBufferedBytes: 10})
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("123456"))
+	body, release, err := b.readBody(req)
+	if err != nil || string(body) != "123456" {
+		t.Fatalf("want the body buffered but got %q %v", body, err)
+	}
+	// the length of the chunked body is unknown, the bytes are reserved as they arrive
+	chunked := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("123456"))
+	chunked.ContentLength = -1
+	if _, release, err := b.readBody(chunked); !errors.Is(err, ErrBufferBudgetExceeded) {
+		t.Fatalf("want the budget exceeded but got %v", err)
+	} else {
+		release()
+	}
+	if _, _, err := b.readBody(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 11)))); !errors.Is(err, ErrBufferBudgetExceeded) {
+		t.Fatalf("want the body larger than the budget rejected but got %v", err)
+	}
+
+	// the request waits for the budget released by the others
+	b.update(&config.BufferBudget{MaxTotalBufferedBytes: 10, QueueTimeout: durationpb.New(200 * time.Millisecond)})
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	if err := b.acquire(context.Background(), 6); err != nil {
+		t.Fatalf("want the budget acquired once released but got %v", err)
+	}
+	if err := b.acquire(context.Background(), 6); err == nil {
+		t.Fatal("want the budget exceeded")
+	}
+	b.release(6)
+	if used := b.used.Load(); used != 0 {
+		t.Fatalf("want all the bytes released but %d used", used)
+	}
+}
+
+func TestBufferBudgetRejection(t *testing.T) {
+	p, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.buffers.update(&config.BufferBudget{MaxTotalBufferedBytes: 4, RetryAfter: durationpb.New(2 * time.Second)})
+	w := httptest.NewRecorder()
+	writeBufferBudgetError(w, httptest.NewRequest(http.MethodPost, "/", nil), &config.Endpoint{Path: "/"}, p.buffers.retryAfter(), p.observable.Observe(&config.Endpoint{}))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("want 503 with Retry-After but got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,315 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/watchdog"
+	"github.com/google/uuid"
+)
+
+const (
+	// _captureHeader carries the capture token of the requests to capture, it is never forwarded.
+	_captureHeader = "X-Capture"
+
+	_defaultCaptureSize         = 100
+	_maxCaptureSize             = 1000
+	_defaultCaptureTTL          = 10 * time.Minute
+	_defaultCaptureMaxBodyBytes = 64 << 10
+	_maxReplayBodyBytes         = 1 << 20
+)
+
+// CapturedRequest is a request captured for the replay, the sensitive headers are redacted in the listing.
+type CapturedRequest struct {
+	ID        string      `json:"id"`
+	Time      time.Time   `json:"time"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	Method    string      `json:"method"`
+	Host      string      `json:"host"`
+	URL       string      `json:"url"`
+	Route     string      `json:"route"`
+	Header    http.Header `json:"header"`
+	Body      string      `json:"body"`
+	// Truncated is set if the body exceeds max_body_bytes, the truncated body is replayed.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CaptureConfig is the runtime config of the request capture, the capture is disabled without a token.
+type CaptureConfig struct {
+	// Token is the value of the X-Capture header of the captured requests, it is never returned.
+	Token string `json:"token,omitempty"`
+	// TokenSet reports whether the capture is enabled.
+	TokenSet bool `json:"token_set"`
+	// TTL is the time the captured requests are kept, eg: 10m.
+	TTL string `json:"ttl"`
+	// Size is the max number of the captured requests kept.
+	Size int `json:"size"`
+	// MaxBodyBytes is the max size of the captured body.
+	MaxBodyBytes int64 `json:"max_body_bytes"`
+}
+
+type captureConfig struct {
+	token        string
+	ttl          time.Duration
+	size         int
+	maxBodyBytes int64
+}
+
+func (c *captureConfig) export() *CaptureConfig {
+	return &CaptureConfig{TokenSet: c.token != "", TTL: c.ttl.String(), Size: c.size, MaxBodyBytes: c.maxBodyBytes}
+}
+
+func parseCaptureConfig(in *CaptureConfig) (*captureConfig, error) {
+	c := &captureConfig{token: in.Token, ttl: _defaultCaptureTTL, size: in.Size, maxBodyBytes: in.MaxBodyBytes}
+	if in.TTL != "" {
+		ttl, err := time.ParseDuration(in.TTL)
+		if err != nil {
+			return nil, err
+		}
+		c.ttl = ttl
+	}
+	if c.ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+	if c.size <= 0 || c.size > _maxCaptureSize {
+		return nil, errors.New("size must be in [1, 1000]")
+	}
+	if c.maxBodyBytes <= 0 || c.maxBodyBytes > _maxReplayBodyBytes {
+		return nil, errors.New("max_body_bytes must be in [1, 1048576]")
+	}
+	return c, nil
+}
+
+type capturedRequest struct {
+	CapturedRequest
+	header http.Header
+	body   []byte
+}
+
+// captureStore keeps the latest captured requests until they expire.
+type captureStore struct {
+	config atomic.Pointer[captureConfig]
+
+	lock     sync.Mutex
+	requests []*capturedRequest
+	now      func() time.Time
+	// nothing is captured while the optional features are disabled by the degraded mode of the watchdog
+	optionalDisabled func() bool
+}
+
+func newCaptureStore() *captureStore {
+	s := &captureStore{now: time.Now, optionalDisabled: watchdog.OptionalDisabled}
+	s.config.Store(&captureConfig{ttl: _defaultCaptureTTL, size: _defaultCaptureSize, maxBodyBytes: _defaultCaptureMaxBodyBytes})
+	return s
+}
+
+// takeCaptureToken removes the X-Capture header from the request and returns its token, it is called for
+// every request, including the stream ones, so the token is never forwarded.
+func takeCaptureToken(req *http.Request) string {
+	token := req.Header.Get(_captureHeader)
+	if token != "" {
+		req.Header.Del(_captureHeader)
+	}
+	return token
+}
+
+// capture stores the request carrying the capture token, the buffered body is shared with the proxy.
+func (s *captureStore) capture(req *http.Request, token, route string, body []byte) {
+	if token == "" || s.optionalDisabled() {
+		return
+	}
+	c := s.config.Load()
+	if c.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.token)) != 1 {
+		return
+	}
+	now := s.now()
+	entry := &capturedRequest{
+		CapturedRequest: CapturedRequest{
+			ID:        uuid.NewString(),
+			Time:      now,
+			ExpiresAt: now.Add(c.ttl),
+			Method:    req.Method,
+			Host:      req.Host,
+			URL:       req.URL.RequestURI(),
+			Route:     route,
+			Header:    redactHeader(req.Header),
+		},
+		header: req.Header.Clone(),
+	}
+	if int64(len(body)) > c.maxBodyBytes {
+		body, entry.Truncated = body[:c.maxBodyBytes], true
+	}
+	entry.body = bytes.Clone(body)
+	entry.Body = string(entry.body)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests = append(s.expired(now), entry)
+	if over := len(s.requests) - c.size; over > 0 {
+		s.requests = s.requests[over:]
+	}
+}
+
+// expired drops the expired requests, the caller must hold the lock.
+func (s *captureStore) expired(now time.Time) []*capturedRequest {
+	out := s.requests[:0]
+	for _, r := range s.requests {
+		if now.Before(r.ExpiresAt) {
+			out = append(out, r)
+		}
+	}
+	clear(s.requests[len(out):])
+	return out
+}
+
+// Requests returns the captured requests from the newest.
+func (s *captureStore) Requests() []*CapturedRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests = s.expired(s.now())
+	out := make([]*CapturedRequest, 0, len(s.requests))
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		out = append(out, &s.requests[i].CapturedRequest)
+	}
+	return out
+}
+
+func (s *captureStore) get(id string) (*capturedRequest, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests = s.expired(s.now())
+	for _, r := range s.requests {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// ReplayResult is the response of the replayed request.
+type ReplayResult struct {
+	ID         string      `json:"id"`
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+	Truncated  bool        `json:"truncated,omitempty"`
+	Duration   string      `json:"duration"`
+}
+
+// replay re-sends the captured request with the original headers through the current routing,
+// the path is replaced if given to replay it against another endpoint.
+func (p *Proxy) replay(r *capturedRequest, path string) (*ReplayResult, error) {
+	req, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(r.body))
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if !strings.HasPrefix(path, "/") {
+			return nil, errors.New("path must start with /")
+		}
+		req.URL.Path, req.URL.RawPath = path, ""
+	}
+	req.Host = r.Host
+	req.Header = r.header.Clone()
+	req.ContentLength = int64(len(r.body))
+	if req.ContentLength > 0 || req.Header.Get("Content-Length") != "" {
+		req.Header.Set("Content-Length", strconv.Itoa(len(r.body)))
+	}
+	req.RequestURI = req.URL.RequestURI()
+	req.RemoteAddr = "127.0.0.1:0"
+	w := httptest.NewRecorder()
+	start := time.Now()
+	p.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, _maxReplayBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	out := &ReplayResult{
+		ID:         r.ID,
+		Method:     req.Method,
+		URL:        req.URL.RequestURI(),
+		StatusCode: resp.StatusCode,
+		Header:     redactHeader(resp.Header),
+		Duration:   time.Since(start).String(),
+	}
+	if len(body) > _maxReplayBodyBytes {
+		body, out.Truncated = body[:_maxReplayBodyBytes], true
+	}
+	out.Body = string(body)
+	return out, nil
+}
+
+func (p *Proxy) registerCaptureDebugHandler(debugMux *http.ServeMux) {
+	s := p.captures
+	debugMux.HandleFunc("/debug/proxy/replay", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(s.Requests())
+	})
+	debugMux.HandleFunc("/debug/proxy/replay/config", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(s.config.Load().export())
+			return
+		}
+		in := s.config.Load().export()
+		if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c, err := parseCaptureConfig(in)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		before, _ := json.Marshal(s.config.Load().export())
+		after, _ := json.Marshal(c.export())
+		if err := audit.WriteRequest(req, &audit.Record{Action: "capture.config", Before: string(before), After: string(after)}); err != nil {
+			_errorLog.Errorf("Failed to write audit record of capture.config: %+v", err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		s.config.Store(c)
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(c.export())
+	})
+	debugMux.HandleFunc("/debug/proxy/replay/", func(rw http.ResponseWriter, req *http.Request) {
+		r, ok := s.get(strings.TrimPrefix(req.URL.Path, "/debug/proxy/replay/"))
+		if !ok {
+			http.Error(rw, "captured request not found or expired", http.StatusNotFound)
+			return
+		}
+		if req.Method != http.MethodPost {
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(&r.CapturedRequest)
+			return
+		}
+		path := req.URL.Query().Get("path")
+		if err := audit.WriteRequest(req, &audit.Record{Action: "capture.replay", Target: r.Method + " " + r.URL, After: path}); err != nil {
+			_errorLog.Errorf("Failed to write audit record of capture.replay: %+v", err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		result, err := p.replay(r, path)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(result)
+	})
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestCaptureReplay(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/orders",
+			Method:   "POST",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/v2/orders",
+			Method:   "POST",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/events",
+			Method:   "GET",
+			Stream:   true,
+		}},
+	}
+	var upstream []*http.Request
+	var bodies []string
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			var b []byte
+			if req.Body != nil {
+				b, _ = io.ReadAll(req.Body)
+			}
+			upstream, bodies = append(upstream, req), append(bodies, string(b))
+			return &http.Response{StatusCode: http.StatusCreated, Header: http.Header{"X-Path": {req.URL.Path}}, Body: io.NopCloser(strings.NewReader("created"))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	debug := p.DebugHandler()
+	send := func(token string) {
+		req := httptest.NewRequest(http.MethodPost, "/orders?id=1", strings.NewReader(`{"item":"book"}`))
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set(_captureHeader, token)
+		p.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	list := func() []*CapturedRequest {
+		w := httptest.NewRecorder()
+		debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/proxy/replay", nil))
+		var out []*CapturedRequest
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// the capture is disabled without a token
+	send("s3cret")
+	if got := list(); len(got) != 0 {
+		t.Fatalf("want nothing captured but got %d", len(got))
+	}
+	w := httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/proxy/replay/config", strings.NewReader(`{"token":"s3cret","ttl":"1m","size":10,"max_body_bytes":8}`)))
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "s3cret") {
+		t.Fatalf("want the config updated without the token returned but got %d %s", w.Code, w.Body)
+	}
+	send("wrong")
+	send("s3cret")
+	for _, req := range upstream {
+		if req.Header.Get(_captureHeader) != "" {
+			t.Fatal("want the capture header not forwarded")
+		}
+	}
+	got := list()
+	if len(got) != 1 {
+		t.Fatalf("want the request with the token captured but got %d", len(got))
+	}
+	captured := got[0]
+	if captured.URL != "/orders?id=1" || captured.Route != "/orders" || captured.Header.Get("Authorization") != "[redacted]" ||
+		captured.Body != `{"item":` || !captured.Truncated {
+		t.Fatalf("unexpected captured request: %+v", captured)
+	}
+
+	upstream, bodies = nil, nil
+	w = httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/proxy/replay/"+captured.ID+"?path=/v2/orders", nil))
+	result := &ReplayResult{}
+	if err := json.NewDecoder(w.Body).Decode(result); err != nil {
+		t.Fatal(err)
+	}
+	if result.StatusCode != http.StatusCreated || result.Body != "created" || result.Header.Get("X-Path") != "/v2/orders" {
+		t.Fatalf("unexpected replay result: %+v", result)
+	}
+	if len(upstream) != 1 || upstream[0].Header.Get("Authorization") != "Bearer secret" || bodies[0] != `{"item":` {
+		t.Fatalf("want the original headers replayed but got %v", upstream)
+	}
+	if len(list()) != 1 {
+		t.Fatal("want the replayed request not captured again")
+	}
+
+	// nothing is captured in the degraded mode
+	p.captures.optionalDisabled = func() bool { return true }
+	send("s3cret")
+	if len(list()) != 1 {
+		t.Fatal("want the request not captured in the degraded mode")
+	}
+	p.captures.optionalDisabled = func() bool { return false }
+
+	// the captured requests expire
+	p.captures.now = func() time.Time { return time.Now().Add(time.Hour) }
+	w = httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/proxy/replay/"+captured.ID, nil))
+	if w.Code != http.StatusNotFound || len(list()) != 0 {
+		t.Fatalf("want the captured request expired but got %d", w.Code)
+	}
+
+	// the capture header of the stream endpoints is not forwarded either
+	upstream = nil
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(_captureHeader, "s3cret")
+	p.ServeHTTP(httptest.NewRecorder(), req)
+	if len(upstream) != 1 {
+		t.Fatalf("want the stream request sent upstream but got %d", len(upstream))
+	}
+	if upstream[0].Header.Get(_captureHeader) != "" {
+		t.Fatal("want the capture header of the stream request not forwarded")
+	}
+}
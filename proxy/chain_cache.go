@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aide-family/goddess/middleware"
+)
+
+// chainCache shares the instances of the shareable middlewares among the endpoints of one update, eg: the
+// gateway middlewares applied to every endpoint are built once rather than once per endpoint. The chains are
+// keyed by the structural hash of their middleware configs, the other middlewares are built per endpoint.
+// A nil cache shares nothing.
+type chainCache struct {
+	shareable func(name string) bool
+	chains    map[[sha256.Size]byte][]middleware.MiddlewareV2
+	// the number of the middleware instances reused from the cache
+	shared int
+}
+
+func newChainCache(shareable func(name string) bool) *chainCache {
+	return &chainCache{shareable: shareable, chains: map[[sha256.Size]byte][]middleware.MiddlewareV2{}}
+}
+
+// create returns the instances of the middlewares in the order of the configs, nil for the middlewares
+// not registered.
+func (c *chainCache) create(ms []*config.Middleware, factory middleware.FactoryV2) ([]middleware.MiddlewareV2, error) {
+	if len(ms) == 0 {
+		return nil, nil
+	}
+	var (
+		key    [sha256.Size]byte
+		shared []middleware.MiddlewareV2
+		err    error
+	)
+	if c != nil {
+		if key, err = chainHash(ms); err != nil {
+			// built per endpoint
+			c = nil
+		}
+		shared = c.lookup(key)
+	}
+	instances := make([]middleware.MiddlewareV2, len(ms))
+	for i, m := range ms {
+		if shared != nil && shared[i] != nil {
+			instances[i] = shared[i]
+			c.shared++
+			continue
+		}
+		instance, err := factory(m)
+		if err != nil {
+			if errors.Is(err, middleware.ErrNotFound) {
+				log.Errorf("Skip does not exist middleware: %s", m.Name)
+				continue
+			}
+			return nil, err
+		}
+		instances[i] = instance
+	}
+	if c != nil && shared == nil {
+		shared = make([]middleware.MiddlewareV2, len(ms))
+		for i, m := range ms {
+			if c.shareable(m.Name) {
+				shared[i] = instances[i]
+			}
+		}
+		c.chains[key] = shared
+	}
+	return instances, nil
+}
+
+// chainHash returns the structural hash of the middleware configs, the names, the options and the other
+// fields of the middlewares in their order.
+func chainHash(ms []*config.Middleware) (sum [sha256.Size]byte, _ error) {
+	h := sha256.New()
+	opts := proto.MarshalOptions{Deterministic: true}
+	for _, m := range ms {
+		b, err := opts.Marshal(m)
+		if err != nil {
+			return sum, err
+		}
+		writeLengthPrefixed(h, b)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
+
+func (c *chainCache) lookup(key [sha256.Size]byte) []middleware.MiddlewareV2 {
+	if c == nil {
+		return nil
+	}
+	return c.chains[key]
+}
+
+func writeLengthPrefixed(h hash.Hash, b []byte) {
+	var n [binary.MaxVarintLen64]byte
+	h.Write(n[:binary.PutUvarint(n[:], uint64(len(b)))])
+	h.Write(b)
+}
@@ -0,0 +1,170 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func chainCacheConfig(t testing.TB, endpoints int) *config.Gateway {
+	shared, err := anypb.New(wrapperspb.String("shared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{
+		Name: "Test",
+		Middlewares: []*config.Middleware{
+			{Name: "shareable", Options: shared},
+			{Name: "stateful", Options: shared},
+		},
+	}
+	for i := 0; i < endpoints; i++ {
+		c.Endpoints = append(c.Endpoints, &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+			Path:     fmt.Sprintf("/api/%d", i),
+			Method:   "GET",
+		})
+	}
+	return c
+}
+
+// _expensivePattern is compiled by every instance of the middlewares of the benchmark, eg: the path rules
+var _expensivePattern = func() string {
+	routes := make([]string, 0, 64)
+	for i := 0; i < 64; i++ {
+		routes = append(routes, fmt.Sprintf("^/api/v%d/[a-z]+/(?P<id%d>[0-9a-f-]{36})$", i, i))
+	}
+	return strings.Join(routes, "|")
+}()
+
+func newChainCacheProxy(t testing.TB, built map[string]int, expensive bool) *Proxy {
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(strings.Join(req.Header.Values("X-Instance"), ",")))}, nil
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		if expensive {
+			regexp.MustCompile(_expensivePattern)
+		}
+		built[c.Name]++
+		instance := fmt.Sprintf("%s-%d", c.Name, built[c.Name])
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Add("X-Instance", instance)
+				return next.RoundTrip(req)
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.shareable = func(name string) bool { return name == "shareable" }
+	return p
+}
+
+func TestChainCache(t *testing.T) {
+	built := map[string]int{}
+	p := newChainCacheProxy(t, built, false)
+	if err := p.Update(client.NewBuildContext(&config.Gateway{}), chainCacheConfig(t, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if built["shareable"] != 1 || built["stateful"] != 3 {
+		t.Fatalf("want the shareable middleware built once and the stateful per endpoint but got %v", built)
+	}
+	stateful := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/%d", i), nil))
+		instance, ok := strings.CutPrefix(w.Body.String(), "shareable-1,")
+		if !ok {
+			t.Fatalf("want the shared instance but got %s", w.Body.String())
+		}
+		stateful[instance] = true
+	}
+	if len(stateful) != 3 {
+		t.Fatalf("want a stateful instance per endpoint but got %v", stateful)
+	}
+
+	// the instances are not shared across the updates and the different options
+	c := chainCacheConfig(t, 2)
+	other, err := anypb.New(wrapperspb.String("other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Endpoints[1].Middlewares = []*config.Middleware{{Name: "shareable", Options: other}}
+	c.Endpoints = append(c.Endpoints, &config.Endpoint{Protocol: config.Protocol_HTTP, Path: "/api/2", Method: "GET", Middlewares: []*config.Middleware{{Name: "shareable", Options: other}}})
+	if err := p.Update(client.NewBuildContext(&config.Gateway{}), c); err != nil {
+		t.Fatal(err)
+	}
+	if built["shareable"] != 3 {
+		t.Fatalf("want the shareable middleware built once per distinct config but got %v", built)
+	}
+}
+
+func TestChainHash(t *testing.T) {
+	a, err := anypb.New(wrapperspb.String("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := anypb.New(wrapperspb.String("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := func(ms ...*config.Middleware) [32]byte {
+		sum, err := chainHash(ms)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sum
+	}
+	if hash(&config.Middleware{Name: "cors", Options: a}) != hash(&config.Middleware{Name: "cors", Options: a}) {
+		t.Fatal("want the equal chains hashed equally")
+	}
+	for _, other := range [][32]byte{
+		hash(&config.Middleware{Name: "cors", Options: b}),
+		hash(&config.Middleware{Name: "rewrite", Options: a}),
+		hash(&config.Middleware{Name: "cors", Options: a, Required: true}),
+		hash(&config.Middleware{Name: "cors", Options: a}, &config.Middleware{Name: "cors", Options: a}),
+	} {
+		if other == hash(&config.Middleware{Name: "cors", Options: a}) {
+			t.Fatal("want the different chains hashed differently")
+		}
+	}
+}
+
+// BenchmarkUpdateChainCache measures the update of 800 endpoints sharing the gateway middlewares, each
+// middleware instance compiles a regexp when built.
+func BenchmarkUpdateChainCache(b *testing.B) {
+	for _, bc := range []struct {
+		name      string
+		shareable func(string) bool
+	}{
+		{"unshared", func(string) bool { return false }},
+		{"shared", func(name string) bool { return name == "shareable" }},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			p := newChainCacheProxy(b, map[string]int{}, true)
+			p.shareable = bc.shareable
+			c := chainCacheConfig(b, 800)
+			buildContext := client.NewBuildContext(&config.Gateway{})
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := p.Update(buildContext, c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
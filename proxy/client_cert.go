@@ -0,0 +1,205 @@
+package proxy
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aide-family/goddess/audit"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrClientCertForbidden is returned when the client certificate does not match the policy of the endpoint.
+var ErrClientCertForbidden = errors.New("client certificate forbidden")
+
+const _reasonClientCertForbidden = "CLIENT_CERT_FORBIDDEN"
+
+var _metricClientCertRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "client_cert_requests_total",
+	Help:      "The total number of requests by the SPIFFE trust domain of the verified client certificate, none without it",
+}, []string{"trust_domain", "result"})
+
+func init() {
+	goddessmetrics.Register(_metricClientCertRequests)
+}
+
+// clientCertPolicy forwards the identity of the client certificate to the upstream and checks it.
+type clientCertPolicy struct {
+	headers     *config.ClientCert
+	required    bool
+	sans        []*regexp.Regexp
+	trustDomain string
+}
+
+func newClientCertPolicy(gw *gatewayContext, e *config.Endpoint) (*clientCertPolicy, error) {
+	c := e.ClientCertPolicy
+	if gw.clientCert == nil && c == nil {
+		return nil, nil
+	}
+	p := &clientCertPolicy{
+		headers:     gw.clientCert,
+		required:    c.GetRequired() || len(c.GetAllowedSans()) > 0 || c.GetSpiffeTrustDomain() != "",
+		trustDomain: c.GetSpiffeTrustDomain(),
+	}
+	for _, pattern := range c.GetAllowedSans() {
+		if pattern == "" {
+			return nil, errors.New("empty allowed SAN pattern")
+		}
+		p.sans = append(p.sans, globPattern(pattern))
+	}
+	return p, nil
+}
+
+// globPattern compiles the pattern where `*` matches any characters.
+func globPattern(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$")
+}
+
+// clientCertIdentity returns the identity of the verified client certificate, nil if the connection is not mTLS.
+func clientCertIdentity(req *http.Request) *middleware.ClientCertIdentity {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	cert := req.TLS.PeerCertificates[0]
+	id := &middleware.ClientCertIdentity{
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		Emails:      cert.EmailAddresses,
+		Certificate: cert,
+	}
+	for _, u := range cert.URIs {
+		id.URIs = append(id.URIs, u.String())
+		if u.Scheme == "spiffe" && id.SPIFFEID == "" {
+			id.SPIFFEID = u.String()
+		}
+	}
+	return id
+}
+
+// check removes the identity headers sent by the client, and forwards the identity of the request if it is
+// allowed. The reason of the rejection is returned, empty if the request is allowed.
+func (p *clientCertPolicy) check(req *http.Request, id *middleware.ClientCertIdentity) string {
+	if p == nil {
+		return ""
+	}
+	p.stripHeaders(req.Header)
+	reason := p.reject(id)
+	if reason != "" {
+		_metricClientCertRequests.WithLabelValues(trustDomain(id), "denied").Inc()
+		if id != nil {
+			// the identities are unbounded, they are audited instead of labeled
+			rec := &audit.Record{Actor: "cert:" + id.String(), Remote: req.RemoteAddr, Action: "client_cert.deny",
+				Target: req.Host + req.URL.Path, After: reason, RequestID: req.Header.Get("X-Request-Id")}
+			if err := audit.Write(rec); err != nil {
+				log.Errorf("failed to write audit record of client cert denial: %v", err)
+			}
+		}
+		return reason
+	}
+	if id == nil {
+		return ""
+	}
+	_metricClientCertRequests.WithLabelValues(trustDomain(id), "allowed").Inc()
+	p.setHeaders(req.Header, id)
+	return ""
+}
+
+// trustDomain returns the trust domain of the SPIFFE ID of the identity, none if it has no SPIFFE ID.
+func trustDomain(id *middleware.ClientCertIdentity) string {
+	if id == nil || id.SPIFFEID == "" {
+		return "none"
+	}
+	u, err := url.Parse(id.SPIFFEID)
+	if err != nil || u.Host == "" {
+		return "none"
+	}
+	return u.Host
+}
+
+func (p *clientCertPolicy) reject(id *middleware.ClientCertIdentity) string {
+	if id == nil {
+		if p.required {
+			return "missing"
+		}
+		return ""
+	}
+	if p.trustDomain != "" {
+		u, err := url.Parse(id.SPIFFEID)
+		if id.SPIFFEID == "" || err != nil || u.Host != p.trustDomain {
+			return "trust_domain"
+		}
+	}
+	if len(p.sans) == 0 {
+		return ""
+	}
+	for _, sans := range [][]string{id.DNSNames, id.URIs, id.Emails} {
+		for _, san := range sans {
+			for _, pattern := range p.sans {
+				if pattern.MatchString(san) {
+					return ""
+				}
+			}
+		}
+	}
+	return "san"
+}
+
+func (p *clientCertPolicy) stripHeaders(h http.Header) {
+	if p.headers == nil {
+		return
+	}
+	for _, name := range []string{p.headers.CnHeader, p.headers.SpiffeHeader, p.headers.SanHeader, p.headers.PemHeader} {
+		if name != "" {
+			h.Del(name)
+		}
+	}
+}
+
+func (p *clientCertPolicy) setHeaders(h http.Header, id *middleware.ClientCertIdentity) {
+	c := p.headers
+	if c == nil {
+		return
+	}
+	if c.CnHeader != "" && id.CommonName != "" {
+		h.Set(c.CnHeader, id.CommonName)
+	}
+	if c.SpiffeHeader != "" && id.SPIFFEID != "" {
+		h.Set(c.SpiffeHeader, id.SPIFFEID)
+	}
+	if c.SanHeader != "" {
+		sans := make([]string, 0, len(id.DNSNames)+len(id.URIs)+len(id.Emails))
+		sans = append(append(append(sans, id.DNSNames...), id.URIs...), id.Emails...)
+		if len(sans) > 0 {
+			h.Set(c.SanHeader, strings.Join(sans, ","))
+		}
+	}
+	if c.PemHeader != "" {
+		h.Set(c.PemHeader, url.QueryEscape(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: id.Certificate.Raw}))))
+	}
+}
+
+func writeClientCertError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, reason string, observer Observer) {
+	statusCode := http.StatusForbidden
+	observer.HandleRequest(r, w.Header(), statusCode, ErrClientCertForbidden)
+	message := fmt.Sprintf("%s: %s", ErrClientCertForbidden, reason)
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", message)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeErrorResponse(w, statusCode, _reasonClientCertForbidden, message)
+}
@@ -0,0 +1,153 @@
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestClientCert(t *testing.T, cn string, uri string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		URIs:         []*url.URL{u},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestClientCertPolicy(t *testing.T) {
+	var upstreamHeader http.Header
+	var identity *middleware.ClientCertIdentity
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			upstreamHeader = req.Header
+			identity, _ = middleware.ClientCert.FromContext(req.Context())
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{
+		ClientCert: &config.ClientCert{CnHeader: "X-Client-Cert-CN", SpiffeHeader: "X-Client-Cert-SPIFFE", PemHeader: "X-Client-Cert"},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/public",
+			Method:   "GET",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/payments",
+			Method:   "GET",
+			ClientCertPolicy: &config.ClientCertPolicy{
+				AllowedSans:       []string{"spiffe://prod.example.com/ns/payments/*"},
+				SpiffeTrustDomain: "prod.example.com",
+			},
+		}},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	request := func(path string, cert *x509.Certificate) *httptest.ResponseRecorder {
+		upstreamHeader, identity = nil, nil
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Client-Cert-CN", "spoofed")
+		if cert != nil {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request("/public", nil); w.Code != http.StatusOK || upstreamHeader.Get("X-Client-Cert-CN") != "" || identity != nil {
+		t.Fatalf("want the spoofed header removed without mTLS but got %d %v", w.Code, upstreamHeader)
+	}
+	cert := newTestClientCert(t, "payments", "spiffe://prod.example.com/ns/payments/sa/api")
+	if w := request("/payments", cert); w.Code != http.StatusOK {
+		t.Fatalf("want the allowed certificate passed but got %d", w.Code)
+	}
+	if upstreamHeader.Get("X-Client-Cert-CN") != "payments" || upstreamHeader.Get("X-Client-Cert-SPIFFE") != "spiffe://prod.example.com/ns/payments/sa/api" ||
+		!strings.HasPrefix(upstreamHeader.Get("X-Client-Cert"), url.QueryEscape("-----BEGIN CERTIFICATE-----")) {
+		t.Fatalf("want the identity forwarded but got %v", upstreamHeader)
+	}
+	if identity.String() != "spiffe://prod.example.com/ns/payments/sa/api" {
+		t.Fatalf("want the identity exposed to the middlewares but got %v", identity)
+	}
+
+	sink := &recordingAuditSink{}
+	audit.SetSinks(sink)
+	defer audit.SetSinks()
+	denied := testutil.ToFloat64(_metricClientCertRequests.WithLabelValues("prod.example.com", "denied"))
+	for _, tc := range []struct {
+		cert   *x509.Certificate
+		reason string
+	}{
+		{nil, "missing"},
+		{newTestClientCert(t, "orders", "spiffe://prod.example.com/ns/orders/sa/api"), "san"},
+		{newTestClientCert(t, "payments", "spiffe://dev.example.com/ns/payments/sa/api"), "trust_domain"},
+	} {
+		w := request("/payments", tc.cert)
+		resp := middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusForbidden, _reasonClientCertForbidden)
+		if !strings.HasSuffix(resp.Message, tc.reason) {
+			t.Fatalf("want the request rejected by %s but got %q", tc.reason, resp.Message)
+		}
+	}
+	// the requests are counted by the trust domain and the denied identities are audited
+	if got := testutil.ToFloat64(_metricClientCertRequests.WithLabelValues("prod.example.com", "denied")) - denied; got != 1 {
+		t.Fatalf("want 1 request denied in the trust domain but got %v", got)
+	}
+	if len(sink.records) != 2 || sink.records[0].Actor != "cert:spiffe://prod.example.com/ns/orders/sa/api" || sink.records[0].After != "san" {
+		t.Fatalf("unexpected audit records %+v", sink.records)
+	}
+}
+
+type recordingAuditSink struct {
+	records []*audit.Record
+}
+
+func (s *recordingAuditSink) Name() string { return "recording" }
+
+func (s *recordingAuditSink) Write(rec *audit.Record) error {
+	s.records = append(s.records, rec)
+	return nil
+}
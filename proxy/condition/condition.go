@@ -1,9 +1,12 @@
 package condition
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -53,6 +56,7 @@ type byHeader struct {
 	parsed struct {
 		name   string
 		values map[string]struct{}
+		regex  *regexp.Regexp
 	}
 }
 
@@ -61,13 +65,30 @@ func (c *byHeader) Judge(resp *http.Response) bool {
 	if v == "" {
 		return false
 	}
+	if c.parsed.regex != nil {
+		return c.parsed.regex.MatchString(v)
+	}
 	_, ok := c.parsed.values[v]
 	return ok
 }
 
 func (c *byHeader) Prepare() error {
+	if c.ByHeader.Name == "" {
+		return fmt.Errorf("invalid header condition: empty name")
+	}
 	c.parsed.name = c.ByHeader.Name
 	c.parsed.values = map[string]struct{}{}
+	if c.ByHeader.ValueRegex != "" {
+		if c.ByHeader.Value != "" {
+			return fmt.Errorf("invalid header condition %s: value and value_regex are exclusive", c.ByHeader.Name)
+		}
+		regex, err := regexp.Compile(c.ByHeader.ValueRegex)
+		if err != nil {
+			return err
+		}
+		c.parsed.regex = regex
+		return nil
+	}
 	if strings.HasPrefix(c.ByHeader.Value, "[") {
 		values, err := parseAsStringList(c.ByHeader.Value)
 		if err != nil {
@@ -82,6 +103,49 @@ func (c *byHeader) Prepare() error {
 	return nil
 }
 
+const defaultBodyContainsMaxBytes = 4 << 10
+
+type byBodyContains struct {
+	*config.Condition_ByBodyContains
+	pattern  []byte
+	maxBytes int64
+}
+
+func (c *byBodyContains) Prepare() error {
+	if c.ByBodyContains.Pattern == "" {
+		return fmt.Errorf("invalid body condition: empty pattern")
+	}
+	c.pattern = []byte(c.ByBodyContains.Pattern)
+	c.maxBytes = defaultBodyContainsMaxBytes
+	if c.ByBodyContains.MaxBytes > 0 {
+		c.maxBytes = int64(c.ByBodyContains.MaxBytes)
+	}
+	if c.maxBytes < int64(len(c.pattern)) {
+		return fmt.Errorf("invalid body condition: max_bytes %d is less than the pattern", c.maxBytes)
+	}
+	return nil
+}
+
+// Judge inspects the prefix of the response body,
+// the body is reconstructed so that it can still be read from the beginning.
+func (c *byBodyContains) Judge(resp *http.Response) bool {
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false
+	}
+	body := resp.Body
+	prefix, err := io.ReadAll(io.LimitReader(body, c.maxBytes))
+	resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(prefix), body), Closer: body}
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(prefix, c.pattern)
+}
+
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
 func parseAsStringList(in string) ([]string, error) {
 	var out []string
 	if err := json.Unmarshal([]byte(in), &out); err != nil {
@@ -102,6 +166,14 @@ func ParseConditon(in ...*config.Condition) ([]Condition, error) {
 				return nil, err
 			}
 			conditions = append(conditions, cond)
+		case *config.Condition_ByBodyContains:
+			cond := &byBodyContains{
+				Condition_ByBodyContains: v,
+			}
+			if err := cond.Prepare(); err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, cond)
 		case *config.Condition_ByStatusCode:
 			cond := &byStatusCode{
 				Condition_ByStatusCode: v,
@@ -174,3 +174,69 @@ func TestRetryByHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryByHeaderRegex(t *testing.T) {
+	cond := &byHeader{
+		Condition_ByHeader: &config.Condition_ByHeader{
+			ByHeader: &config.ConditionHeader{
+				Name:       "x-upstream-state",
+				ValueRegex: "^(throttled|overloaded)$",
+			},
+		},
+	}
+	if err := cond.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+	for value, result := range map[string]bool{"throttled": true, "overloaded": true, "ok": false, "": false} {
+		resp := &http.Response{Header: http.Header{}, Body: nopBody}
+		resp.Header.Set("x-upstream-state", value)
+		if cond.Judge(resp) != result {
+			t.Errorf("%s: expected %v", value, result)
+		}
+	}
+	invalid := []*config.ConditionHeader{
+		{Name: "x", ValueRegex: "("},
+		{Name: "x", Value: "a", ValueRegex: "a"},
+		{ValueRegex: "a"},
+	}
+	for _, header := range invalid {
+		cond := &byHeader{Condition_ByHeader: &config.Condition_ByHeader{ByHeader: header}}
+		if err := cond.Prepare(); err == nil {
+			t.Errorf("%v: expected prepare error", header)
+		}
+	}
+}
+
+func TestRetryByBodyContains(t *testing.T) {
+	testCases := []struct {
+		body     string
+		maxBytes uint32
+		result   bool
+	}{
+		{body: `{"error":"RATE_LIMITED"}`, result: true},
+		{body: `{"data":"ok"}`, result: false},
+		{body: `{"padding":"xxxxxxxx","error":"RATE_LIMITED"}`, maxBytes: 16, result: false},
+	}
+	for _, testCase := range testCases {
+		cond := &byBodyContains{
+			Condition_ByBodyContains: &config.Condition_ByBodyContains{
+				ByBodyContains: &config.ConditionBodyContains{Pattern: "RATE_LIMITED", MaxBytes: testCase.maxBytes},
+			},
+		}
+		if err := cond.Prepare(); err != nil {
+			t.Fatal(err)
+		}
+		resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(testCase.body))}
+		if result := cond.Judge(resp); result != testCase.result {
+			t.Errorf("%s: expected %v, got %v", testCase.body, testCase.result, result)
+		}
+		// the body is still readable from the beginning
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != testCase.body {
+			t.Errorf("expected body %s, got %s", testCase.body, body)
+		}
+	}
+}
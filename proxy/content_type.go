@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrUnsupportedContentType is returned when the Content-Type of the request body is not allowed by the endpoint.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+const _reasonUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+var _metricContentTypeRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "content_type_rejected_total",
+	Help:      "The total number of requests rejected by the allowed content types",
+}, []string{"protocol", "method", "path", "service", "basePath", "reason"})
+
+func init() {
+	goddessmetrics.Register(_metricContentTypeRejected)
+}
+
+// contentTypePolicy checks the media types of the request bodies, the parameters such as charset and boundary are ignored.
+type contentTypePolicy struct {
+	exact    map[string]struct{}
+	prefixes []string // the types of the wildcard subtypes with the trailing slash, eg: text/
+	any      bool
+	missing  config.MissingContentType_Action
+	fallback string
+}
+
+func newContentTypePolicy(e *config.Endpoint) (*contentTypePolicy, error) {
+	if len(e.AllowedContentTypes) == 0 {
+		return nil, nil
+	}
+	p := &contentTypePolicy{
+		exact:   make(map[string]struct{}, len(e.AllowedContentTypes)),
+		missing: e.MissingContentType.GetAction(),
+	}
+	for _, allowed := range e.AllowedContentTypes {
+		mediaType, _, err := mime.ParseMediaType(allowed)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed content type %q: %w", allowed, err)
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		switch {
+		case !ok || typ == "" || subtype == "":
+			return nil, fmt.Errorf("invalid allowed content type %q: type/subtype is required", allowed)
+		case typ == "*" && subtype == "*":
+			p.any = true
+		case typ == "*":
+			return nil, fmt.Errorf("invalid allowed content type %q: only the subtype can be a wildcard", allowed)
+		case subtype == "*":
+			p.prefixes = append(p.prefixes, typ+"/")
+		default:
+			p.exact[mediaType] = struct{}{}
+		}
+	}
+	if p.missing == config.MissingContentType_DEFAULT {
+		p.fallback = e.MissingContentType.GetDefaultType()
+		if _, _, err := mime.ParseMediaType(p.fallback); err != nil {
+			return nil, fmt.Errorf("invalid default content type %q: %w", p.fallback, err)
+		}
+	}
+	return p, nil
+}
+
+// hasBody reports whether the request carries a body to be checked.
+func hasBody(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return req.ContentLength != 0
+}
+
+// check returns the reason of the rejection, empty if the request is allowed.
+// The Content-Type of the request is set to the default type if it has none.
+func (p *contentTypePolicy) check(req *http.Request) string {
+	if p == nil || !hasBody(req) {
+		return ""
+	}
+	contentType := req.Header.Get("Content-Type")
+	if contentType == "" {
+		switch p.missing {
+		case config.MissingContentType_REJECT:
+			return "missing"
+		case config.MissingContentType_DEFAULT:
+			req.Header.Set("Content-Type", p.fallback)
+		}
+		return ""
+	}
+	if p.any {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "invalid"
+	}
+	if _, ok := p.exact[mediaType]; ok {
+		return ""
+	}
+	for _, prefix := range p.prefixes {
+		if strings.HasPrefix(mediaType, prefix) {
+			return ""
+		}
+	}
+	return "mismatch"
+}
+
+func writeContentTypeError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, reason string, observer Observer) {
+	statusCode := http.StatusUnsupportedMediaType
+	labels := []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"], reason}
+	_metricContentTypeRejected.WithLabelValues(labels...).Inc()
+	observer.HandleRequest(r, w.Header(), statusCode, ErrUnsupportedContentType)
+	message := fmt.Sprintf("%s: %q", ErrUnsupportedContentType, r.Header.Get("Content-Type"))
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", message)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeErrorResponse(w, statusCode, _reasonUnsupportedMediaType, message)
+}
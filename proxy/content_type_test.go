@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestContentTypePolicy(t *testing.T) {
+	p, err := newContentTypePolicy(&config.Endpoint{AllowedContentTypes: []string{"application/json", "multipart/form-data", "text/*"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		method      string
+		contentType string
+		reason      string
+	}{
+		{http.MethodPost, "application/json", ""},
+		{http.MethodPost, "Application/JSON; charset=utf-8", ""},
+		{http.MethodPut, "multipart/form-data; boundary=xyz", ""},
+		{http.MethodPatch, "text/csv", ""},
+		{http.MethodPost, "application/xml", "mismatch"},
+		{http.MethodPost, "application/json-seq", "mismatch"},
+		{http.MethodPost, "application/", "invalid"},
+		// the methods without bodies are not checked
+		{http.MethodGet, "application/xml", ""},
+		// allowed by default
+		{http.MethodPost, "", ""},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, "/", nil)
+		if test.contentType != "" {
+			req.Header.Set("Content-Type", test.contentType)
+		}
+		if reason := p.check(req); reason != test.reason {
+			t.Errorf("%s %q: want reason %q but got %q", test.method, test.contentType, test.reason, reason)
+		}
+	}
+
+	p, err = newContentTypePolicy(&config.Endpoint{
+		AllowedContentTypes: []string{"application/json"},
+		MissingContentType:  &config.MissingContentType{Action: config.MissingContentType_DEFAULT, DefaultType: "application/json"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}"))
+	if reason := p.check(req); reason != "" || req.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("want the default type set but got %q %q", reason, req.Header.Get("Content-Type"))
+	}
+
+	for _, allowed := range []string{"json", "*/json", "application/"} {
+		if _, err := newContentTypePolicy(&config.Endpoint{AllowedContentTypes: []string{allowed}}); err == nil {
+			t.Errorf("want %q rejected", allowed)
+		}
+	}
+}
+
+func TestContentTypeRejection(t *testing.T) {
+	p, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &config.Endpoint{
+		Path:                "/upload",
+		Method:              http.MethodPost,
+		AllowedContentTypes: []string{"application/json"},
+		MissingContentType:  &config.MissingContentType{Action: config.MissingContentType_REJECT},
+	}
+	policy, err := newContentTypePolicy(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("x"))
+	w := httptest.NewRecorder()
+	writeContentTypeError(w, req, e, policy.check(req), p.observable.Observe(e))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusUnsupportedMediaType, _reasonUnsupportedMediaType)
+}
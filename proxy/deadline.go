@@ -0,0 +1,160 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const _defaultDeadlineMargin = 5 * time.Millisecond
+
+// deadlinePropagation forwards the remaining budget of the attempt to the upstream.
+type deadlinePropagation struct {
+	header  string
+	grpc    bool
+	margin  time.Duration
+	trusted []*net.IPNet
+}
+
+func newDeadlinePropagation(gw *gatewayContext, e *config.Endpoint) (*deadlinePropagation, error) {
+	c := e.DeadlinePropagation
+	if c == nil {
+		c = gw.deadlinePropagation
+	}
+	if c == nil {
+		return nil, nil
+	}
+	d := &deadlinePropagation{
+		header: c.Header,
+		grpc:   e.Protocol == config.Protocol_GRPC,
+		margin: _defaultDeadlineMargin,
+	}
+	if c.Margin != nil {
+		d.margin = c.Margin.AsDuration()
+	}
+	for _, cidr := range c.TrustedCidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deadline propagation trusted cidr: %w", err)
+		}
+		d.trusted = append(d.trusted, ipNet)
+	}
+	return d, nil
+}
+
+func (d *deadlinePropagation) isTrusted(req *http.Request) bool {
+	if len(d.trusted) == 0 {
+		return false
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range d.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// timeout returns the minimum of the configured timeout and the deadline of the trusted caller, and the
+// source of the timeout returned.
+func (d *deadlinePropagation) timeout(req *http.Request, timeout time.Duration) (time.Duration, middleware.TimeoutSource) {
+	source := middleware.TimeoutSourceRequest
+	if d == nil || !d.isTrusted(req) {
+		return timeout, source
+	}
+	if d.header != "" {
+		if ms, err := strconv.ParseInt(req.Header.Get(d.header), 10, 64); err == nil && ms > 0 {
+			if incoming := time.Duration(ms) * time.Millisecond; incoming < timeout {
+				timeout, source = incoming, middleware.TimeoutSourceClientDeadline
+			}
+		}
+	}
+	if isGRPCRequest(req) {
+		if incoming, ok := decodeGRPCTimeout(req.Header.Get("Grpc-Timeout")); ok && incoming < timeout {
+			timeout, source = incoming, middleware.TimeoutSourceClientDeadline
+		}
+	}
+	return timeout, source
+}
+
+// apply registers the hook setting the remaining budget on the upstream request of the attempt.
+func (d *deadlinePropagation) apply(reqOpts *middleware.RequestOptions) {
+	if d == nil {
+		return
+	}
+	reqOpts.UpstreamRequestHooks = append(reqOpts.UpstreamRequestHooks, d.setHeaders)
+}
+
+func (d *deadlinePropagation) setHeaders(req *http.Request) error {
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return nil
+	}
+	remaining := time.Until(deadline) - d.margin
+	if remaining < time.Millisecond {
+		remaining = time.Millisecond
+	}
+	if d.grpc {
+		req.Header.Set("Grpc-Timeout", encodeGRPCTimeout(remaining))
+	}
+	if d.header != "" {
+		req.Header.Set(d.header, strconv.FormatInt(remaining.Milliseconds(), 10))
+	}
+	return nil
+}
+
+// encodeGRPCTimeout encodes the timeout with the finest unit fitting in 8 digits,
+// see https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md
+func encodeGRPCTimeout(t time.Duration) string {
+	const maxValue = 99999999
+	for _, unit := range []struct {
+		d      time.Duration
+		suffix string
+	}{
+		{time.Nanosecond, "n"},
+		{time.Microsecond, "u"},
+		{time.Millisecond, "m"},
+		{time.Second, "S"},
+		{time.Minute, "M"},
+	} {
+		if v := t / unit.d; v <= maxValue {
+			return strconv.FormatInt(int64(v), 10) + unit.suffix
+		}
+	}
+	return strconv.FormatInt(int64(t/time.Hour), 10) + "H"
+}
+
+func decodeGRPCTimeout(s string) (time.Duration, bool) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, false
+	}
+	v, err := strconv.ParseInt(s[:len(s)-1], 10, 64)
+	if err != nil || v <= 0 {
+		return 0, false
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[s[len(s)-1]]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(v) * unit, true
+}
@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestDeadlinePropagation(t *testing.T) {
+	gw := &gatewayContext{deadlinePropagation: &config.DeadlinePropagation{
+		Header:       "X-Request-Timeout-Ms",
+		Margin:       durationpb.New(10 * time.Millisecond),
+		TrustedCidrs: []string{"10.0.0.0/8"},
+	}}
+	d, err := newDeadlinePropagation(gw, &config.Endpoint{Protocol: config.Protocol_GRPC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/helloworld.Greeter/SayHello", nil)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Grpc-Timeout", "200m")
+	req.Header.Set("X-Request-Timeout-Ms", "500")
+	if got, source := d.timeout(req, time.Second); got != time.Second || source != middleware.TimeoutSourceRequest {
+		t.Fatalf("want the deadline of the untrusted caller ignored but got %s", got)
+	}
+	req.RemoteAddr = "10.1.2.3:1234"
+	if got, source := d.timeout(req, time.Second); got != 200*time.Millisecond || source != middleware.TimeoutSourceClientDeadline {
+		t.Fatalf("want the minimum deadline of the trusted caller but got %s", got)
+	}
+	if got, _ := d.timeout(req, 100*time.Millisecond); got != 100*time.Millisecond {
+		t.Fatalf("want the configured timeout but got %s", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	upstream := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(ctx)
+	if err := d.setHeaders(upstream); err != nil {
+		t.Fatal(err)
+	}
+	ms, err := strconv.ParseInt(upstream.Header.Get("X-Request-Timeout-Ms"), 10, 64)
+	if err != nil || ms > 990 || ms < 900 {
+		t.Fatalf("unexpected timeout header: %q", upstream.Header.Get("X-Request-Timeout-Ms"))
+	}
+	if timeout, ok := decodeGRPCTimeout(upstream.Header.Get("Grpc-Timeout")); !ok || timeout > 990*time.Millisecond {
+		t.Fatalf("unexpected grpc-timeout: %q", upstream.Header.Get("Grpc-Timeout"))
+	}
+
+	if _, err := newDeadlinePropagation(gw, &config.Endpoint{DeadlinePropagation: &config.DeadlinePropagation{TrustedCidrs: []string{"invalid"}}}); err == nil {
+		t.Fatal("want error with invalid cidr")
+	}
+}
+
+func TestGRPCTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{50 * time.Millisecond, "50000000n"},
+		{3 * time.Second, "3000000u"},
+		{10 * time.Minute, "600000m"},
+		{48 * time.Hour, "172800S"},
+	} {
+		got := encodeGRPCTimeout(tc.timeout)
+		if got != tc.want {
+			t.Fatalf("want %s but got %s", tc.want, got)
+		}
+		if timeout, ok := decodeGRPCTimeout(got); !ok || timeout != tc.timeout {
+			t.Fatalf("want %s decoded but got %s", tc.timeout, timeout)
+		}
+	}
+	if _, ok := decodeGRPCTimeout("1x"); ok {
+		t.Fatal("want invalid unit rejected")
+	}
+}
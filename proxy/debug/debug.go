@@ -1,10 +1,12 @@
 package debug
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/pprof"
 	"path"
 	"strings"
+	"sync"
 
 	rmux "github.com/aide-family/goddess/router/mux"
 	"github.com/go-kratos/kratos/v2/log"
@@ -13,9 +15,10 @@ import (
 
 const (
 	_debugPrefix = "/debug"
+	_statuszPath = "/statusz"
 )
 
-var globalService = &debugService{
+var globalService = &Service{
 	handlers: map[string]http.HandlerFunc{
 		"/debug/ping":               func(rw http.ResponseWriter, r *http.Request) {},
 		"/debug/pprof/":             pprof.Index,
@@ -30,44 +33,105 @@ var globalService = &debugService{
 		"/debug/pprof/mutex":        pprof.Handler("mutex").ServeHTTP,
 		"/debug/pprof/threadcreate": pprof.Handler("threadcreate").ServeHTTP,
 	},
-	mux: mux.NewRouter(),
+	mux:      mux.NewRouter(),
+	statuses: map[string]func() any{},
 }
 
 func Register(name string, debuggable Debuggable) {
 	globalService.Register(name, debuggable)
 }
 
+// RegisterStatus adds the section of the name to /statusz, the status is encoded as JSON on every request.
+func RegisterStatus(name string, status func() any) {
+	globalService.RegisterStatus(name, status)
+}
+
 func MashupWithDebugHandler(origin http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-		if strings.HasPrefix(req.URL.Path, _debugPrefix) {
-			rmux.ProtectedHandler(globalService).ServeHTTP(w, req)
-			return
-		}
-		origin.ServeHTTP(w, req)
-	})
+	return globalService.Mashup(origin)
+}
+
+// NewService returns the debug handlers of an instance, eg: an embedded gateway, the paths and the status
+// sections not registered on it are served by the global ones.
+func NewService() *Service {
+	return &Service{mux: mux.NewRouter(), statuses: map[string]func() any{}, parent: globalService}
 }
 
 type Debuggable interface {
 	DebugHandler() http.Handler
 }
 
-type debugService struct {
+// Service serves the registered debug handlers under /debug and the status sections on /statusz.
+type Service struct {
 	handlers map[string]http.HandlerFunc
 	mux      *mux.Router
+	// serves the paths not registered on the service
+	parent *Service
+
+	statusLock sync.Mutex
+	statuses   map[string]func() any
 }
 
-func (d *debugService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+func (d *Service) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == _statuszPath {
+		d.serveStatusz(w, req)
+		return
+	}
 	for path, handler := range d.handlers {
 		if path == req.URL.Path {
 			handler(w, req)
 			return
 		}
 	}
+	if d.parent != nil {
+		if match := (mux.RouteMatch{}); !d.mux.Match(req, &match) {
+			d.parent.ServeHTTP(w, req)
+			return
+		}
+	}
 	d.mux.ServeHTTP(w, req)
 }
 
-func (d *debugService) Register(name string, debuggable Debuggable) {
+// Register serves the debug handler of the debuggable under /debug/name.
+func (d *Service) Register(name string, debuggable Debuggable) {
 	path := path.Join(_debugPrefix, name)
 	d.mux.PathPrefix(path).Handler(debuggable.DebugHandler())
 	log.Infof("register debug: %s", path)
 }
+
+// RegisterStatus adds the section of the name to /statusz, the status is encoded as JSON on every request.
+func (d *Service) RegisterStatus(name string, status func() any) {
+	d.statusLock.Lock()
+	defer d.statusLock.Unlock()
+	d.statuses[name] = status
+}
+
+// Mashup returns the handler serving /debug and /statusz by the service and the other paths by origin.
+func (d *Service) Mashup(origin http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, _debugPrefix) || req.URL.Path == _statuszPath {
+			rmux.ProtectedHandler(d).ServeHTTP(w, req)
+			return
+		}
+		origin.ServeHTTP(w, req)
+	})
+}
+
+// collectStatuses returns the status sections of the parents and the service, the service overrides the parents.
+func (d *Service) collectStatuses(out map[string]any) {
+	if d.parent != nil {
+		d.parent.collectStatuses(out)
+	}
+	d.statusLock.Lock()
+	defer d.statusLock.Unlock()
+	for name, status := range d.statuses {
+		out[name] = status()
+	}
+}
+
+// serveStatusz writes the registered status sections.
+func (d *Service) serveStatusz(w http.ResponseWriter, _ *http.Request) {
+	out := map[string]any{}
+	d.collectStatuses(out)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
@@ -0,0 +1,326 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	drainKindNode  = "node"
+	drainKindRoute = "route"
+)
+
+var _metricDrainsActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "drains_active",
+	Help:      "The active drains of backend nodes and routes",
+}, []string{"kind", "target"})
+
+func init() {
+	goddessmetrics.Register(_metricDrainsActive)
+}
+
+// Drain is an active drain of a backend node or a route.
+type Drain struct {
+	Kind      string     `json:"kind"`
+	Service   string     `json:"service,omitempty"`
+	Address   string     `json:"address,omitempty"`
+	Method    string     `json:"method,omitempty"`
+	Path      string     `json:"path,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpireAt  *time.Time `json:"expire_at,omitempty"`
+
+	// digests of the endpoints matched by the route drain, the drain is dropped once they are changed.
+	digests []string
+}
+
+func (d *Drain) target() string {
+	if d.Kind == drainKindNode {
+		return d.Service + "/" + d.Address
+	}
+	return d.Method + " " + d.Path
+}
+
+func (d *Drain) expired(now time.Time) bool {
+	return d.ExpireAt != nil && now.After(*d.ExpireAt)
+}
+
+// DrainManager takes backend nodes and routes out of rotation, the drains are kept across config reloads.
+type DrainManager struct {
+	lock   sync.RWMutex
+	drains map[string]*Drain
+	// endpoints of the current router, used to bind the route drains to the endpoint config.
+	endpoints []*config.Endpoint
+}
+
+func newDrainManager() *DrainManager {
+	return &DrainManager{drains: map[string]*Drain{}}
+}
+
+func drainKey(kind, target string) string {
+	return kind + ":" + target
+}
+
+func (m *DrainManager) add(d *Drain, duration time.Duration) {
+	d.CreatedAt = time.Now()
+	if duration > 0 {
+		expireAt := d.CreatedAt.Add(duration)
+		d.ExpireAt = &expireAt
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if d.Kind == drainKindRoute {
+		d.digests = matchedEndpointDigests(m.endpoints, d.Method, d.Path)
+	}
+	m.drains[drainKey(d.Kind, d.target())] = d
+	_metricDrainsActive.WithLabelValues(d.Kind, d.target()).Set(1)
+	log.Warnf("drain %s %s", d.Kind, d.target())
+}
+
+func (m *DrainManager) remove(kind, target string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.removeLocked(drainKey(kind, target))
+}
+
+func (m *DrainManager) removeLocked(key string) bool {
+	d, ok := m.drains[key]
+	if !ok {
+		return false
+	}
+	delete(m.drains, key)
+	_metricDrainsActive.DeleteLabelValues(d.Kind, d.target())
+	log.Warnf("undrain %s %s", d.Kind, d.target())
+	return true
+}
+
+// get returns the active drain, the expired drain is removed.
+func (m *DrainManager) get(kind, target string) (*Drain, bool) {
+	key := drainKey(kind, target)
+	m.lock.RLock()
+	d, ok := m.drains[key]
+	empty := len(m.drains) == 0
+	m.lock.RUnlock()
+	if empty || !ok {
+		return nil, false
+	}
+	if d.expired(time.Now()) {
+		m.lock.Lock()
+		m.removeLocked(key)
+		m.lock.Unlock()
+		return nil, false
+	}
+	return d, true
+}
+
+// List returns the active drains.
+func (m *DrainManager) List() []*Drain {
+	now := time.Now()
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	out := make([]*Drain, 0, len(m.drains))
+	for key, d := range m.drains {
+		if d.expired(now) {
+			m.removeLocked(key)
+			continue
+		}
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+func (m *DrainManager) hasNodeDrains() bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for _, d := range m.drains {
+		if d.Kind == drainKindNode {
+			return true
+		}
+	}
+	return false
+}
+
+func matchedEndpointDigests(endpoints []*config.Endpoint, method, path string) []string {
+	var out []string
+	for _, e := range endpoints {
+		if e.Path == path && strings.EqualFold(e.Method, method) {
+			out = append(out, endpointDigest(e))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// updateEndpoints drops the route drains whose endpoints are changed by the reload.
+func (m *DrainManager) updateEndpoints(endpoints []*config.Endpoint) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.endpoints = endpoints
+	for key, d := range m.drains {
+		if d.Kind != drainKindRoute {
+			continue
+		}
+		digests := matchedEndpointDigests(endpoints, d.Method, d.Path)
+		if strings.Join(digests, ",") != strings.Join(d.digests, ",") {
+			log.Infof("drop drain of the changed route: %s", d.target())
+			m.removeLocked(key)
+		}
+	}
+}
+
+// nodeFilter excludes the drained nodes of the endpoint services.
+func (m *DrainManager) nodeFilter(e *config.Endpoint) selector.NodeFilter {
+	services := map[string]struct{}{"": {}}
+	if service := e.Metadata["service"]; service != "" {
+		services[service] = struct{}{}
+	}
+	for _, b := range e.Backends {
+		services[discoveryName(b.Target)] = struct{}{}
+	}
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		if !m.hasNodeDrains() {
+			return nodes
+		}
+		newNodes := nodes[:0:0]
+		for _, n := range nodes {
+			if m.isNodeDrained(services, n) {
+				continue
+			}
+			newNodes = append(newNodes, n)
+		}
+		return newNodes
+	}
+}
+
+func (m *DrainManager) isNodeDrained(services map[string]struct{}, n selector.Node) bool {
+	for service := range services {
+		if _, ok := m.get(drainKindNode, service+"/"+n.Address()); ok {
+			return true
+		}
+	}
+	if n.ServiceName() != "" {
+		if _, ok := m.get(drainKindNode, n.ServiceName()+"/"+n.Address()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// routeHandler replies 503 while the route is drained, the maintenance settings of the endpoint are used if configured.
+func (m *DrainManager) routeHandler(e *config.Endpoint, next http.Handler) http.Handler {
+	drained := proto.Clone(e).(*config.Endpoint)
+	if drained.Maintenance == nil {
+		drained.Maintenance = &config.Maintenance{Message: "endpoint drained"}
+	}
+	drained.Maintenance.Enabled = true
+	unavailable := maintenanceHandler(drained)
+	target := strings.ToUpper(e.Method) + " " + e.Path
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := m.get(drainKindRoute, target); ok {
+			unavailable.ServeHTTP(w, req)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+type drainRequest struct {
+	Service  string `json:"service"`
+	Address  string `json:"address"`
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Duration string `json:"duration"`
+}
+
+func (r *drainRequest) duration() (time.Duration, error) {
+	if r.Duration == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(r.Duration)
+}
+
+func (r *drainRequest) validate(kind string) error {
+	switch kind {
+	case drainKindNode:
+		if r.Address == "" {
+			return errors.New("address is required")
+		}
+	case drainKindRoute:
+		if r.Path == "" {
+			return errors.New("path is required")
+		}
+		r.Method = strings.ToUpper(r.Method)
+	}
+	return nil
+}
+
+// DebugHandler implemented debug handler.
+func (m *DrainManager) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	handle := func(kind string, drain bool) http.HandlerFunc {
+		return func(rw http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+			in := &drainRequest{}
+			if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := in.validate(kind); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			duration, err := in.duration()
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			d := &Drain{Kind: kind, Service: in.Service, Address: in.Address, Method: in.Method, Path: in.Path}
+			action := "drain." + kind
+			if !drain {
+				action = "undrain." + kind
+			}
+			if err := audit.WriteRequest(r, &audit.Record{Action: action, Target: d.target(), After: in.Duration}); err != nil {
+				log.Errorf("Failed to write audit record of %s %s: %+v", action, d.target(), err)
+				http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+				return
+			}
+			if !drain {
+				if !m.remove(kind, d.target()) {
+					http.Error(rw, "drain not found", http.StatusNotFound)
+				}
+				return
+			}
+			m.add(d, duration)
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(d)
+		}
+	}
+	debugMux.HandleFunc("/debug/admin/drain-node", handle(drainKindNode, true))
+	debugMux.HandleFunc("/debug/admin/undrain-node", handle(drainKindNode, false))
+	debugMux.HandleFunc("/debug/admin/drain-route", handle(drainKindRoute, true))
+	debugMux.HandleFunc("/debug/admin/undrain-route", handle(drainKindRoute, false))
+	debugMux.HandleFunc("/debug/admin/drains", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(m.List())
+	})
+	return debugMux
+}
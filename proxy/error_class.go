@@ -0,0 +1,164 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+)
+
+// ErrorClass tells where a failed request went wrong.
+type ErrorClass string
+
+const (
+	// ErrorClassNone is the class of the requests without an error.
+	ErrorClassNone ErrorClass = "none"
+	// ErrorClassUpstreamConnect is the failure to establish the connection to the upstream, including TLS.
+	ErrorClassUpstreamConnect ErrorClass = "upstream_connect"
+	// ErrorClassEgressProxy is the failure to tunnel to the upstream through the egress proxy.
+	ErrorClassEgressProxy ErrorClass = "egress_proxy"
+	// ErrorClassNoHealthyUpstream is the discovery returning zero instances of the endpoint.
+	ErrorClassNoHealthyUpstream ErrorClass = "no_healthy_upstream"
+	// ErrorClassUpstreamTimeout is the upstream not responding in time.
+	ErrorClassUpstreamTimeout ErrorClass = "upstream_timeout"
+	// ErrorClassUpstreamHeaderTimeout is the upstream not sending the response headers in response_header_timeout.
+	ErrorClassUpstreamHeaderTimeout ErrorClass = "upstream_header_timeout"
+	// ErrorClassUpstreamReset is the connection closed by the upstream before the response completes.
+	ErrorClassUpstreamReset ErrorClass = "upstream_reset"
+	// ErrorClassClientCancel is the client going away before the response.
+	ErrorClassClientCancel ErrorClass = "client_cancel"
+	// ErrorClassMiddlewareReject is the request answered by a middleware without reaching the upstream.
+	ErrorClassMiddlewareReject ErrorClass = "middleware_reject"
+	// ErrorClassGatewayInternal is any other failure of the gateway.
+	ErrorClassGatewayInternal ErrorClass = "gateway_internal"
+)
+
+// the messages of the errors not exposed as the types by net/http.
+var (
+	_resetErrors = []string{
+		"connection reset by peer",
+		"broken pipe",
+		"server closed idle connection",
+		"http2: server sent GOAWAY",
+		"stream error",
+		"unexpected EOF",
+	}
+	_connectErrors = []string{
+		"connection refused",
+		"no such host",
+		"no route to host",
+		"network is unreachable",
+		"tls: ",
+		"x509: ",
+	}
+)
+
+// ClassifyError returns the class of the error of proxying the request.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+	if errors.Is(err, context.Canceled) || err.Error() == "client disconnected" {
+		return ErrorClassClientCancel
+	}
+	if middleware.TimeoutSourceOf(nil, err) == middleware.TimeoutSourceResponseHeader {
+		return ErrorClassUpstreamHeaderTimeout
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassUpstreamTimeout
+	}
+	if errors.Is(err, client.ErrNoHealthyUpstream) {
+		return ErrorClassNoHealthyUpstream
+	}
+	if errors.Is(err, client.ErrNoMatchingNodes) {
+		return ErrorClassUpstreamConnect
+	}
+	var egressErr *client.EgressProxyError
+	if errors.As(err, &egressErr) {
+		return ErrorClassEgressProxy
+	}
+	if isPolicyRejection(err) {
+		return ErrorClassMiddlewareReject
+	}
+	if isGatewayRejection(err) {
+		return ErrorClassGatewayInternal
+	}
+	var (
+		opErr      *net.OpError
+		netErr     net.Error
+		dnsErr     *net.DNSError
+		tlsErr     tls.RecordHeaderError
+		certErr    *tls.CertificateVerificationError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &dnsErr),
+		errors.As(err, &tlsErr),
+		errors.As(err, &certErr),
+		errors.As(err, &unknownCA),
+		errors.As(err, &hostErr),
+		errors.As(err, &invalidErr),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.As(err, &opErr) && opErr.Op == "dial":
+		return ErrorClassUpstreamConnect
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassUpstreamTimeout
+	case errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorClassUpstreamReset
+	}
+	msg := err.Error()
+	for _, s := range _connectErrors {
+		if strings.Contains(msg, s) {
+			return ErrorClassUpstreamConnect
+		}
+	}
+	for _, s := range _resetErrors {
+		if strings.Contains(msg, s) {
+			return ErrorClassUpstreamReset
+		}
+	}
+	if strings.Contains(msg, "timeout") {
+		return ErrorClassUpstreamTimeout
+	}
+	return ErrorClassGatewayInternal
+}
+
+// isPolicyRejection reports whether the request is rejected by the endpoint policies, like the middlewares do.
+func isPolicyRejection(err error) bool {
+	return errors.Is(err, ErrUnsupportedContentType) || errors.Is(err, ErrWebSocketOriginNotAllowed) || errors.Is(err, ErrWebSocketSubprotocolNotAllowed) ||
+		errors.Is(err, ErrRequestHeaderTooLarge) || errors.Is(err, ErrClientCertForbidden)
+}
+
+func isGatewayRejection(err error) bool {
+	return errors.Is(err, ErrAdmissionQueueFull) || errors.Is(err, ErrAdmissionWaitTimeout) || errors.Is(err, ErrBufferBudgetExceeded) ||
+		errors.Is(err, ErrAdaptiveConcurrencyExceeded) || errors.Is(err, ErrStreamLimitExceeded) || errors.Is(err, ErrClientStreamLimitExceeded)
+}
+
+// requestErrorClass classifies the request by the error, the failures of the requests never sent to
+// any upstream are from the middlewares.
+func requestErrorClass(req *http.Request, statusCode int, err error) ErrorClass {
+	class := ClassifyError(err)
+	if class != ErrorClassNone && (class != ErrorClassGatewayInternal || isGatewayRejection(err)) {
+		return class
+	}
+	if err == nil && statusCode < http.StatusBadRequest {
+		return ErrorClassNone
+	}
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok && len(opts.Backends) == 0 {
+		return ErrorClassMiddlewareReject
+	}
+	return class
+}
@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestClassifyError(t *testing.T) {
+	dial := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	read := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	testCases := []struct {
+		err  error
+		want ErrorClass
+	}{
+		{err: nil, want: ErrorClassNone},
+		{err: context.Canceled, want: ErrorClassClientCancel},
+		{err: errors.New("client disconnected"), want: ErrorClassClientCancel},
+		{err: &url.Error{Op: "Get", URL: "http://upstream", Err: context.DeadlineExceeded}, want: ErrorClassUpstreamTimeout},
+		{err: &url.Error{Op: "Get", URL: "http://upstream", Err: dial}, want: ErrorClassUpstreamConnect},
+		{err: &net.DNSError{Err: "no such host", Name: "upstream", IsNotFound: true}, want: ErrorClassUpstreamConnect},
+		{err: fmt.Errorf("proxy: %w", x509.UnknownAuthorityError{}), want: ErrorClassUpstreamConnect},
+		{err: errors.New("tls: failed to verify certificate"), want: ErrorClassUpstreamConnect},
+		{err: client.ErrNoMatchingNodes, want: ErrorClassUpstreamConnect},
+		{err: &url.Error{Op: "Get", URL: "http://upstream", Err: &client.EgressProxyError{Proxy: "proxy:3128", Err: dial}}, want: ErrorClassEgressProxy},
+		{err: fmt.Errorf("attempt: %w", client.ErrNoHealthyUpstream), want: ErrorClassNoHealthyUpstream},
+		{err: &url.Error{Op: "Get", URL: "http://upstream", Err: read}, want: ErrorClassUpstreamReset},
+		{err: &url.Error{Op: "Get", URL: "http://upstream", Err: io.EOF}, want: ErrorClassUpstreamReset},
+		{err: errors.New("http: server closed idle connection"), want: ErrorClassUpstreamReset},
+		{err: errors.New("net/http: timeout awaiting response headers"), want: ErrorClassUpstreamHeaderTimeout},
+		{err: fmt.Errorf("upstream: %w", middleware.NewTimeoutError(middleware.TimeoutSourceResponseHeader)), want: ErrorClassUpstreamHeaderTimeout},
+		{err: middleware.NewTimeoutError(middleware.TimeoutSourceAttempt), want: ErrorClassUpstreamTimeout},
+		{err: ErrAdmissionWaitTimeout, want: ErrorClassGatewayInternal},
+		{err: errors.New("unknown"), want: ErrorClassGatewayInternal},
+	}
+	for _, tc := range testCases {
+		if got := ClassifyError(tc.err); got != tc.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestRequestErrorClass(t *testing.T) {
+	newRequest := func(backends ...string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		opts := middleware.NewRequestOptions(&config.Endpoint{})
+		opts.Backends = backends
+		return req.WithContext(middleware.NewRequestContext(req.Context(), opts))
+	}
+	if got := requestErrorClass(newRequest(), http.StatusForbidden, nil); got != ErrorClassMiddlewareReject {
+		t.Fatalf("want the response without upstream rejected by middleware but got %s", got)
+	}
+	if got := requestErrorClass(newRequest(), http.StatusBadGateway, errors.New("signing failed")); got != ErrorClassMiddlewareReject {
+		t.Fatalf("want the error without upstream from middleware but got %s", got)
+	}
+	if got := requestErrorClass(newRequest("127.0.0.1:80"), http.StatusServiceUnavailable, nil); got != ErrorClassNone {
+		t.Fatalf("want the upstream response unclassified but got %s", got)
+	}
+	if got := requestErrorClass(newRequest(), http.StatusServiceUnavailable, ErrBufferBudgetExceeded); got != ErrorClassGatewayInternal {
+		t.Fatalf("want the gateway rejection internal but got %s", got)
+	}
+}
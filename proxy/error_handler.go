@@ -2,25 +2,74 @@ package proxy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/logs"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+const (
+	_reasonNoHealthyUpstream = "NO_HEALTHY_UPSTREAM"
+	_reasonGatewayTimeout    = "GATEWAY_TIMEOUT"
+)
+
+var _metricRequestTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "request_timeouts_total",
+	Help:      "The total number of requests replied 504 by the source of the timeout",
+}, []string{"protocol", "method", "path", "service", "basePath", "source"})
+
+func init() {
+	goddessmetrics.Register(_metricRequestTimeouts)
+}
+
+var (
+	// _errorLog writes the failures of proxying the requests to the error stream.
+	_errorLog = log.NewHelper(log.With(logs.Error, "source", "proxy"))
+	// _accessLog writes the requests not routed to any endpoint to the access stream.
+	_accessLog = log.NewHelper(logs.Access)
+)
+
+// isNoHealthyUpstream reports whether the endpoint has zero instances, the request is not retried.
+func isNoHealthyUpstream(err error) bool {
+	return errors.Is(err, client.ErrNoHealthyUpstream)
+}
+
 func writeError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, err error, observer Observer) {
 	var statusCode int
+	timeoutSource := middleware.TimeoutSource("")
 	switch {
 	case errors.Is(err, context.Canceled),
 		err.Error() == "client disconnected":
 		statusCode = 499
 	case errors.Is(err, context.DeadlineExceeded):
 		statusCode = 504
+		timeoutSource = middleware.TimeoutSourceOf(r.Context(), err)
+		if timeoutSource == "" {
+			timeoutSource = "unknown"
+		}
+		_metricRequestTimeouts.WithLabelValues(e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"], string(timeoutSource)).Inc()
+	case errors.Is(err, ErrClientStreamLimitExceeded):
+		statusCode = 429
+	case errors.Is(err, ErrStreamLimitExceeded):
+		statusCode = 503
+	case errors.Is(err, client.ErrNoMatchingNodes),
+		errors.Is(err, client.ErrNoHealthyUpstream):
+		_errorLog.Errorf("Failed to handle request: %s: %+v", r.URL.String(), err)
+		statusCode = 503
 	default:
-		log.Errorf("Failed to handle request: %s: %+v", r.URL.String(), err)
+		_errorLog.Errorf("Failed to handle request: %s: %+v", r.URL.String(), err)
 		statusCode = 502
 	}
 	observer.HandleRequest(r, w.Header(), statusCode, err)
@@ -31,16 +80,40 @@ func writeError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, err
 		w.Header().Set("Grpc-Status", code)
 		w.Header().Set("Grpc-Message", err.Error())
 		statusCode = 200
+	} else if isNoHealthyUpstream(err) {
+		writeErrorResponse(w, statusCode, _reasonNoHealthyUpstream, err.Error())
+		return
+	} else if errors.Is(err, ErrClientStreamLimitExceeded) {
+		writeErrorResponse(w, statusCode, _reasonClientStreamLimitExceeded, err.Error())
+		return
+	} else if errors.Is(err, ErrStreamLimitExceeded) {
+		writeErrorResponse(w, statusCode, _reasonStreamLimitExceeded, err.Error())
+		return
+	} else if timeoutSource != "" {
+		writeErrorResponseWithMetadata(w, statusCode, _reasonGatewayTimeout, err.Error(), map[string]string{"timeout_source": string(timeoutSource)})
+		return
 	}
 	w.WriteHeader(statusCode)
 }
 
+// writeErrorResponse replies the error in the JSON form of the kratos errors, the same as the middleware rejections.
+func writeErrorResponse(w http.ResponseWriter, statusCode int, reason, message string) {
+	writeErrorResponseWithMetadata(w, statusCode, reason, message, nil)
+}
+
+func writeErrorResponseWithMetadata(w http.ResponseWriter, statusCode int, reason, message string, md map[string]string) {
+	body, _ := json.Marshal(kerrors.New(statusCode, reason, message).WithMetadata(md))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write(body)
+}
+
 // notFoundHandler replies to the request with an HTTP 404 not found error.
 func notFoundHandler(w http.ResponseWriter, r *http.Request) {
 	code := http.StatusNotFound
 	message := "404 page not found"
 	http.Error(w, message, code)
-	log.Context(r.Context()).Errorw(
+	_accessLog.WithContext(r.Context()).Errorw(
 		"source", "accesslog",
 		"host", r.Host,
 		"method", r.Method,
@@ -57,7 +130,7 @@ func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
 	code := http.StatusMethodNotAllowed
 	message := http.StatusText(code)
 	http.Error(w, message, code)
-	log.Context(r.Context()).Errorw(
+	_accessLog.WithContext(r.Context()).Errorw(
 		"source", "accesslog",
 		"host", r.Host,
 		"method", r.Method,
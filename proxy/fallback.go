@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/aide-family/goddess/client"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// fallbackFuncs are the funcs of the static bodies, json quotes the request values into the json bodies.
+var fallbackFuncs = template.FuncMap{
+	"json": func(in interface{}) (string, error) {
+		b, err := json.Marshal(in)
+		return string(b), err
+	},
+}
+
+type fallbackData struct {
+	Method string
+	Host   string
+	Path   string
+	Query  string
+	Status int
+}
+
+// fallback dispatches the unmatched requests to the configured action of the request host.
+type fallback struct {
+	status  int
+	actions map[string]http.Handler
+	next    http.Handler
+}
+
+func (f *fallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if h, ok := f.actions[host]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+	// wildcard hosts of the host groups
+	for i := strings.IndexByte(host, '.'); i >= 0; i = strings.IndexByte(host, '.') {
+		host = host[i+1:]
+		if h, ok := f.actions["*."+host]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+	}
+	if h, ok := f.actions[""]; ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+// buildFallback builds the not found and method not allowed handlers from config,
+// the given handlers are used if no action is configured.
+func (p *Proxy) buildFallback(ctx context.Context, buildCtx *client.BuildContext, gw *gatewayContext, c *config.Fallback) (notFound, methodNotAllowed http.Handler, closers []io.Closer, retError error) {
+	notFound, methodNotAllowed = p.notFoundHandler, p.methodNotAllowedHandler
+	var groups []*config.HostGroup
+	for _, group := range gw.hostGroups.groups {
+		if group.Fallback != nil {
+			groups = append(groups, group)
+		}
+	}
+	if c == nil && len(groups) == 0 {
+		return notFound, methodNotAllowed, nil, nil
+	}
+	if c == nil {
+		c = &config.Fallback{}
+	}
+	defer func() {
+		if retError != nil {
+			for _, closer := range closers {
+				_ = closer.Close()
+			}
+		}
+	}()
+	nf := &fallback{status: http.StatusNotFound, actions: map[string]http.Handler{}, next: notFound}
+	mna := &fallback{status: http.StatusMethodNotAllowed, actions: map[string]http.Handler{}, next: methodNotAllowed}
+	build := func(host string, c *config.Fallback) error {
+		for _, item := range []struct {
+			f      *fallback
+			action *config.FallbackAction
+		}{{nf, c.NotFound}, {mna, c.MethodNotAllowed}} {
+			if item.action == nil {
+				continue
+			}
+			h, closer, err := p.buildFallbackAction(ctx, buildCtx, gw, item.f.status, item.action)
+			if err != nil {
+				return fmt.Errorf("invalid fallback of host %q: %w", host, err)
+			}
+			if closer != nil {
+				closers = append(closers, closer)
+			}
+			item.f.actions[host] = h
+		}
+		return nil
+	}
+	if err := build("", c); err != nil {
+		return nil, nil, nil, err
+	}
+	// the host entries of the gateway fallback override the host group fallback.
+	for _, group := range groups {
+		for _, host := range group.Hosts {
+			if err := build(strings.ToLower(host), group.Fallback); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	for host, hc := range c.Hosts {
+		if host == "" {
+			return nil, nil, nil, errors.New("invalid fallback: empty host")
+		}
+		if len(hc.Hosts) > 0 {
+			return nil, nil, nil, fmt.Errorf("invalid fallback of host %q: nested hosts are not supported", host)
+		}
+		if err := build(strings.ToLower(host), hc); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return nf, mna, closers, nil
+}
+
+func (p *Proxy) buildFallbackAction(ctx context.Context, buildCtx *client.BuildContext, gw *gatewayContext, status int, c *config.FallbackAction) (http.Handler, io.Closer, error) {
+	metricPath := "/fallback/" + strconv.Itoa(status)
+	switch action := c.Action.(type) {
+	case *config.FallbackAction_Static_:
+		tmpl, err := template.New("body").Funcs(fallbackFuncs).Parse(action.Static.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := status
+		if action.Static.Status > 0 {
+			code = int(action.Static.Status)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body bytes.Buffer
+			if err := tmpl.Execute(&body, newFallbackData(r, status)); err != nil {
+				_errorLog.Errorf("Failed to render fallback body: %+v", err)
+			}
+			for k, v := range action.Static.Headers {
+				w.Header().Set(k, v)
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+			w.WriteHeader(code)
+			_, _ = w.Write(body.Bytes())
+			MetricRequestsTotal.WithLabelValues("HTTP", r.Method, metricPath, strconv.Itoa(code), "", "").Inc()
+		}), nil, nil
+	case *config.FallbackAction_Redirect_:
+		tmpl, err := template.New("url").Parse(action.Redirect.Url)
+		if err != nil {
+			return nil, nil, err
+		}
+		code := http.StatusFound
+		if action.Redirect.Status > 0 {
+			code = int(action.Redirect.Status)
+		}
+		if code < 300 || code > 399 {
+			return nil, nil, fmt.Errorf("invalid redirect status: %d", code)
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var url strings.Builder
+			if err := tmpl.Execute(&url, newFallbackData(r, status)); err != nil {
+				_errorLog.Errorf("Failed to render fallback redirect url: %+v", err)
+			}
+			http.Redirect(w, r, url.String(), code)
+			MetricRequestsTotal.WithLabelValues("HTTP", r.Method, metricPath, strconv.Itoa(code), "", "").Inc()
+		}), nil, nil
+	case *config.FallbackAction_Endpoint:
+		e := proto.Clone(action.Endpoint).(*config.Endpoint)
+		if e.Path == "" {
+			e.Path = metricPath
+		}
+		return p.buildEndpoint(ctx, buildCtx, gw, e)
+	default:
+		return nil, nil, fmt.Errorf("unknown fallback action: %T", action)
+	}
+}
+
+func newFallbackData(r *http.Request, status int) *fallbackData {
+	return &fallbackData{
+		Method: r.Method,
+		Host:   r.Host,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Status: status,
+	}
+}
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// flushPolicy decides how the response body is flushed to the client.
+type flushPolicy struct {
+	interval     time.Duration
+	configured   bool
+	contentTypes map[string]struct{}
+}
+
+func newFlushPolicy(e *config.Endpoint) *flushPolicy {
+	p := &flushPolicy{contentTypes: make(map[string]struct{}, len(e.FlushContentTypes))}
+	if e.FlushInterval != nil {
+		p.interval = e.FlushInterval.AsDuration()
+		p.configured = true
+	}
+	for _, contentType := range e.FlushContentTypes {
+		p.contentTypes[strings.ToLower(contentType)] = struct{}{}
+	}
+	return p
+}
+
+// streamInterval is the flush interval of the reverse proxy on stream endpoints.
+func (p *flushPolicy) streamInterval() time.Duration {
+	if !p.configured {
+		return -1
+	}
+	return p.interval
+}
+
+// copier returns the body copier of buffered endpoints, the streaming responses replied by the middlewares are
+// flushed at the configured interval or immediately.
+func (p *flushPolicy) copier(w http.ResponseWriter, resp *http.Response, fromMiddleware bool) bodyCopier {
+	if isNoBufferingResponse(resp) {
+		return copyNoBuffering(w)
+	}
+	interval := p.interval
+	if fromMiddleware && resp.ContentLength < 0 && interval == 0 {
+		interval = -1
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		if _, ok := p.contentTypes[mediaType]; ok {
+			interval = -1
+		}
+	}
+	if interval == 0 {
+		return io.Copy
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return io.Copy
+	}
+	return func(dst io.Writer, src io.Reader) (int64, error) {
+		fw := &flushWriter{dst: dst, flusher: flusher, latency: interval}
+		defer fw.stop()
+		return io.Copy(fw, src)
+	}
+}
+
+// flushWriter flushes the written data after the latency, or immediately if the latency is negative.
+// see https://github.com/golang/go/blob/master/src/net/http/httputil/reverseproxy.go
+type flushWriter struct {
+	dst     io.Writer
+	flusher http.Flusher
+	latency time.Duration
+
+	lock         sync.Mutex
+	timer        *time.Timer
+	flushPending bool
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	n, err := w.dst.Write(p)
+	if w.latency < 0 {
+		w.flusher.Flush()
+		return n, err
+	}
+	if w.flushPending {
+		return n, err
+	}
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.latency, w.delayedFlush)
+	} else {
+		w.timer.Reset(w.latency)
+	}
+	w.flushPending = true
+	return n, err
+}
+
+func (w *flushWriter) delayedFlush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if !w.flushPending {
+		return
+	}
+	w.flusher.Flush()
+	w.flushPending = false
+}
+
+func (w *flushWriter) stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.flushPending = false
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
@@ -0,0 +1,82 @@
+package proxy
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddlewareStreamingResponse(t *testing.T) {
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("want the request replied by the middleware")
+			return nil, io.EOF
+		}), nil
+	}
+	events, eventsWriter := io.Pipe()
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{"Content-Type": {"text/event-stream"}}
+				return middleware.NewStreamingResponse(req, "progress", http.StatusOK, header, events), nil
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:    config.Protocol_HTTP,
+		Path:        "/operations/check",
+		Method:      "GET",
+		Middlewares: []*config.Middleware{{Name: "progress"}},
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	// the stream stays open after the first event
+	go eventsWriter.Write([]byte("data: 50%\n\n"))
+	resp, err := http.Get(srv.URL + "/operations/check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	line := make(chan string, 1)
+	go func() {
+		s, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- s
+	}()
+	select {
+	case s := <-line:
+		if s != "data: 50%\n" {
+			t.Fatalf("unexpected event %q", s)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("want the event flushed before the stream ends")
+	}
+	eventsWriter.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	counter := MetricMiddlewareSentBytes.WithLabelValues("HTTP", http.MethodGet, "/operations/check", "progress")
+	for i := 0; i < 100 && testutil.ToFloat64(counter) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := testutil.ToFloat64(counter); got != float64(len("data: 50%\n\n")) {
+		t.Fatalf("want the sent bytes accounted to the middleware but got %v", got)
+	}
+	if got := testutil.ToFloat64(MetricSentBytes.WithLabelValues("HTTP", http.MethodGet, "/operations/check", "", "")); got != 0 {
+		t.Fatalf("want the sent bytes of the middleware response not accounted to the upstream but got %v", got)
+	}
+}
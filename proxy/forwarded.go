@@ -0,0 +1,287 @@
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/router"
+)
+
+// forwardedElement is a forwarded-element of the RFC 7239 Forwarded header.
+type forwardedElement struct {
+	For   string
+	By    string
+	Proto string
+	Host  string
+}
+
+func (e *forwardedElement) String() string {
+	pairs := make([]string, 0, 4)
+	if e.For != "" {
+		pairs = append(pairs, "for="+quoteForwardedValue(e.For))
+	}
+	if e.By != "" {
+		pairs = append(pairs, "by="+quoteForwardedValue(e.By))
+	}
+	if e.Proto != "" {
+		pairs = append(pairs, "proto="+quoteForwardedValue(e.Proto))
+	}
+	if e.Host != "" {
+		pairs = append(pairs, "host="+quoteForwardedValue(e.Host))
+	}
+	return strings.Join(pairs, ";")
+}
+
+// forwardedHeaders manages the X-Forwarded-For and Forwarded request headers.
+type forwardedHeaders struct {
+	style   config.ForwardedHeaders_Style
+	trusted []*net.IPNet
+	by      string
+}
+
+func newForwardedHeaders(c *config.ForwardedHeaders) (*forwardedHeaders, error) {
+	f := &forwardedHeaders{style: config.ForwardedHeaders_X_FORWARDED}
+	if c == nil {
+		return f, nil
+	}
+	f.style = c.Style
+	f.by = c.By
+	for _, item := range c.TrustedProxies {
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+		_, cidr, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+		f.trusted = append(f.trusted, cidr)
+	}
+	return f, nil
+}
+
+func (f *forwardedHeaders) isTrusted(ip net.IP) bool {
+	if len(f.trusted) == 0 {
+		return true
+	}
+	for _, cidr := range f.trusted {
+		if cidr.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply appends the gateway hop to the forwarded headers of the request.
+func (f *forwardedHeaders) Apply(req *http.Request) {
+	clientIP, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return
+	}
+	if !f.isTrusted(net.ParseIP(clientIP)) {
+		// values set by an untrusted peer can not be relied on
+		for _, key := range router.ForwardedHeaders {
+			if _, ok := req.Header[key]; ok {
+				req.Header.Del(key)
+			}
+		}
+	}
+	prior, err := parseForwarded(req.Header.Values("Forwarded"))
+	if err != nil {
+		// malformed header must not be forwarded partially
+		prior = nil
+		if f.style != config.ForwardedHeaders_X_FORWARDED {
+			req.Header.Del("Forwarded")
+		}
+	}
+	if len(prior) == 0 {
+		prior = xffToForwarded(req.Header.Values("X-Forwarded-For"))
+	}
+	if f.style != config.ForwardedHeaders_FORWARDED {
+		if _, ok := req.Header["X-Forwarded-For"]; !ok && len(prior) > 0 {
+			if legacy := forwardedToXFF(prior); legacy != "" {
+				req.Header.Set("X-Forwarded-For", legacy)
+			}
+		}
+		setXFFHeader(req)
+	}
+	if f.style != config.ForwardedHeaders_X_FORWARDED {
+		proto := "http"
+		if req.TLS != nil {
+			proto = "https"
+		}
+		elements := append(prior, &forwardedElement{
+			For:   formatForwardedNode(clientIP),
+			By:    f.by,
+			Proto: proto,
+			Host:  req.Host,
+		})
+		values := make([]string, 0, len(elements))
+		for _, e := range elements {
+			values = append(values, e.String())
+		}
+		req.Header.Set("Forwarded", strings.Join(values, ", "))
+	}
+}
+
+// parseForwarded parses the Forwarded header values, see https://www.rfc-editor.org/rfc/rfc7239#section-4
+func parseForwarded(values []string) ([]*forwardedElement, error) {
+	var out []*forwardedElement
+	for _, value := range values {
+		rawElements, err := splitQuoted(value, ',')
+		if err != nil {
+			return nil, err
+		}
+		for _, rawElement := range rawElements {
+			if strings.TrimSpace(rawElement) == "" {
+				continue
+			}
+			pairs, err := splitQuoted(rawElement, ';')
+			if err != nil {
+				return nil, err
+			}
+			e := &forwardedElement{}
+			for _, pair := range pairs {
+				pair = strings.TrimSpace(pair)
+				if pair == "" {
+					continue
+				}
+				key, val, ok := strings.Cut(pair, "=")
+				if !ok {
+					return nil, fmt.Errorf("invalid forwarded pair: %q", pair)
+				}
+				val, err = unquoteForwardedValue(strings.TrimSpace(val))
+				if err != nil {
+					return nil, err
+				}
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "for":
+					e.For = val
+				case "by":
+					e.By = val
+				case "proto":
+					e.Proto = val
+				case "host":
+					e.Host = val
+				}
+			}
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// splitQuoted splits the input by sep outside of the quoted-strings.
+func splitQuoted(in string, sep byte) ([]string, error) {
+	var out []string
+	quoted, escaped := false, false
+	start := 0
+	for i := 0; i < len(in); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && in[i] == '\\':
+			escaped = true
+		case in[i] == '"':
+			quoted = !quoted
+		case !quoted && in[i] == sep:
+			out = append(out, in[start:i])
+			start = i + 1
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quoted-string: %q", in)
+	}
+	return append(out, in[start:]), nil
+}
+
+func unquoteForwardedValue(in string) (string, error) {
+	if !strings.HasPrefix(in, `"`) {
+		return in, nil
+	}
+	if len(in) < 2 || !strings.HasSuffix(in, `"`) {
+		return "", fmt.Errorf("invalid quoted-string: %q", in)
+	}
+	var b strings.Builder
+	inner := in[1 : len(in)-1]
+	for i := 0; i < len(inner); i++ {
+		if inner[i] == '\\' && i+1 < len(inner) {
+			i++
+		}
+		b.WriteByte(inner[i])
+	}
+	return b.String(), nil
+}
+
+func isTokenChar(c byte) bool {
+	if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
+
+func quoteForwardedValue(in string) string {
+	for i := 0; i < len(in); i++ {
+		if !isTokenChar(in[i]) {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(in) + `"`
+		}
+	}
+	return in
+}
+
+// formatForwardedNode formats the ip as a node identifier, IPv6 addresses are enclosed in brackets.
+func formatForwardedNode(ip string) string {
+	if strings.Contains(ip, ":") {
+		return "[" + ip + "]"
+	}
+	return ip
+}
+
+// forwardedNodeIP returns the ip of the node identifier, obfuscated and unknown identifiers are ignored.
+func forwardedNodeIP(node string) string {
+	if strings.HasPrefix(node, "[") {
+		end := strings.Index(node, "]")
+		if end < 0 {
+			return ""
+		}
+		return node[1:end]
+	}
+	host := node
+	if h, _, err := net.SplitHostPort(node); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) == nil {
+		return ""
+	}
+	return host
+}
+
+func forwardedToXFF(elements []*forwardedElement) string {
+	ips := make([]string, 0, len(elements))
+	for _, e := range elements {
+		if ip := forwardedNodeIP(e.For); ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+	return strings.Join(ips, ", ")
+}
+
+func xffToForwarded(values []string) []*forwardedElement {
+	var out []*forwardedElement
+	for _, value := range values {
+		for _, ip := range strings.Split(value, ",") {
+			ip = strings.TrimSpace(ip)
+			if net.ParseIP(ip) == nil {
+				continue
+			}
+			out = append(out, &forwardedElement{For: formatForwardedNode(ip)})
+		}
+	}
+	return out
+}
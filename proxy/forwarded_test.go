@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestParseForwarded(t *testing.T) {
+	elements, err := parseForwarded([]string{
+		`for=192.0.2.43;proto=http, for="[2001:db8:cafe::17]:4711"`,
+		`For="_gazonk";by=_hidden;host="example.com", for=unknown;by="a\"b"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*forwardedElement{
+		{For: "192.0.2.43", Proto: "http"},
+		{For: "[2001:db8:cafe::17]:4711"},
+		{For: "_gazonk", By: "_hidden", Host: "example.com"},
+		{For: "unknown", By: `a"b`},
+	}
+	if !reflect.DeepEqual(elements, want) {
+		t.Fatalf("want %+v but got %+v", want, elements)
+	}
+	for _, e := range want {
+		got, err := parseForwarded([]string{e.String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, []*forwardedElement{e}) {
+			t.Fatalf("round trip of %q got %+v", e.String(), got[0])
+		}
+	}
+	if _, err := parseForwarded([]string{`for="192.0.2.43`}); err == nil {
+		t.Fatal("want error for unterminated quoted-string")
+	}
+}
+
+func TestForwardedHeadersApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *config.ForwardedHeaders
+		remoteAddr string
+		tls        bool
+		headers    map[string]string
+		forwarded  string
+		xff        string
+	}{
+		{
+			name:       "default",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.1"},
+			xff:        "192.0.2.1, 10.0.0.1",
+		},
+		{
+			name:       "forwarded from xff",
+			config:     &config.ForwardedHeaders{Style: config.ForwardedHeaders_FORWARDED, By: "_gateway"},
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.1, 2001:db8::2"},
+			forwarded:  `for=192.0.2.1, for="[2001:db8::2]", for="[2001:db8::1]";by=_gateway;proto=http;host=example.com`,
+			xff:        "192.0.2.1, 2001:db8::2",
+		},
+		{
+			name:       "xff from forwarded",
+			config:     &config.ForwardedHeaders{Style: config.ForwardedHeaders_BOTH},
+			remoteAddr: "10.0.0.1:1234",
+			tls:        true,
+			headers:    map[string]string{"Forwarded": `for=_hidden, for="[2001:db8::2]:80";proto=https`},
+			forwarded:  `for=_hidden, for="[2001:db8::2]:80";proto=https, for=10.0.0.1;proto=https;host=example.com`,
+			xff:        "2001:db8::2, 10.0.0.1",
+		},
+		{
+			name:       "untrusted peer",
+			config:     &config.ForwardedHeaders{Style: config.ForwardedHeaders_BOTH, TrustedProxies: []string{"10.0.0.0/8", "2001:db8::1"}},
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{"Forwarded": "for=1.1.1.1", "X-Forwarded-For": "1.1.1.1"},
+			forwarded:  "for=192.0.2.1;proto=http;host=example.com",
+			xff:        "192.0.2.1",
+		},
+		{
+			name:       "trusted peer",
+			config:     &config.ForwardedHeaders{Style: config.ForwardedHeaders_BOTH, TrustedProxies: []string{"10.0.0.0/8", "2001:db8::1"}},
+			remoteAddr: "[2001:db8::1]:1234",
+			headers:    map[string]string{"Forwarded": "for=1.1.1.1", "X-Forwarded-For": "1.1.1.1"},
+			forwarded:  `for=1.1.1.1, for="[2001:db8::1]";proto=http;host=example.com`,
+			xff:        "1.1.1.1, 2001:db8::1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := newForwardedHeaders(test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest("GET", "http://example.com/", nil)
+			if test.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			req.RemoteAddr = test.remoteAddr
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			f.Apply(req)
+			if got := req.Header.Get("Forwarded"); got != test.forwarded {
+				t.Errorf("want Forwarded %q but got %q", test.forwarded, got)
+			}
+			if got := req.Header.Get("X-Forwarded-For"); got != test.xff {
+				t.Errorf("want X-Forwarded-For %q but got %q", test.xff, got)
+			}
+		})
+	}
+}
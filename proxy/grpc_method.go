@@ -0,0 +1,238 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// _maxGRPCMethodLabels caps the methods labeled by a gRPC service route besides the configured ones, the
+	// others are labeled by the route path.
+	_maxGRPCMethodLabels = 64
+
+	_grpcStatusUnimplemented = "12"
+)
+
+// endpointRoute is an endpoint to be routed, index is the index of the endpoint in the config, -1 for
+// the routes of the gRPC methods.
+type endpointRoute struct {
+	endpoint *config.Endpoint
+	index    int
+}
+
+// resolveRoutes resolves the host groups of the endpoints and expands the gRPC methods, the routes of the gRPC
+// methods are placed before the service route matching them since the first matched route wins.
+func resolveRoutes(gw *gatewayContext, endpoints []*config.Endpoint) ([]endpointRoute, error) {
+	routes := make([]endpointRoute, 0, len(endpoints))
+	for i, e := range endpoints {
+		e, err := gw.hostGroups.resolve(e)
+		if err != nil {
+			return nil, err
+		}
+		methods, err := expandGRPCMethods(e)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range methods {
+			routes = insertGRPCMethodRoute(routes, endpointRoute{endpoint: m, index: -1})
+		}
+		if grpcServicePrefix(e) == "" && e.Protocol == config.Protocol_GRPC {
+			routes = insertGRPCMethodRoute(routes, endpointRoute{endpoint: e, index: i})
+			continue
+		}
+		routes = append(routes, endpointRoute{endpoint: e, index: i})
+	}
+	return routes, nil
+}
+
+// insertGRPCMethodRoute inserts the method route before the first gRPC service route matching it.
+func insertGRPCMethodRoute(routes []endpointRoute, route endpointRoute) []endpointRoute {
+	e := route.endpoint
+	for i, r := range routes {
+		if prefix := grpcServicePrefix(r.endpoint); prefix != "" && r.endpoint.Host == e.Host && strings.HasPrefix(e.Path, prefix) {
+			return slices.Insert(routes, i, route)
+		}
+	}
+	return append(routes, route)
+}
+
+// grpcServicePrefix returns the prefix of the gRPC service route, eg: /helloworld.Greeter/ of /helloworld.Greeter/*,
+// empty if the endpoint is not a gRPC service route.
+func grpcServicePrefix(e *config.Endpoint) string {
+	if e.Protocol != config.Protocol_GRPC || !strings.HasSuffix(e.Path, "/*") {
+		return ""
+	}
+	return strings.TrimSuffix(e.Path, "*")
+}
+
+// expandGRPCMethods returns the endpoints of the methods of the gRPC service route with the overrides.
+func expandGRPCMethods(e *config.Endpoint) ([]*config.Endpoint, error) {
+	if len(e.GrpcMethods) == 0 {
+		return nil, nil
+	}
+	prefix := grpcServicePrefix(e)
+	if prefix == "" || strings.Count(prefix, "/") != 2 {
+		return nil, fmt.Errorf("grpc_methods of %s require a GRPC endpoint with the path of a service, eg: /helloworld.Greeter/*", e.Path)
+	}
+	out := make([]*config.Endpoint, 0, len(e.GrpcMethods))
+	for _, m := range e.GrpcMethods {
+		if m.Name == "" || strings.ContainsAny(m.Name, "/*{}") {
+			return nil, fmt.Errorf("invalid grpc method %q of %s", m.Name, e.Path)
+		}
+		method := proto.Clone(e).(*config.Endpoint)
+		method.Path = prefix + m.Name
+		method.GrpcMethods = nil
+		if len(m.Middlewares) > 0 {
+			method.Middlewares = m.Middlewares
+		}
+		if m.Timeout != nil {
+			method.Timeout = m.Timeout
+		}
+		if m.Retry != nil {
+			method.Retry = m.Retry
+		}
+		out = append(out, method)
+	}
+	return out, nil
+}
+
+// grpcMethods records the methods answered through a gRPC service route up to _maxGRPCMethodLabels besides the
+// configured ones.
+type grpcMethods struct {
+	route string
+	limit int
+	lock  sync.RWMutex
+	known map[string]struct{}
+}
+
+// isKnown reports whether the method is labeled.
+func (m *grpcMethods) isKnown(path string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	_, ok := m.known[path]
+	return ok
+}
+
+// label returns the method of the path if it is known or there is room for it, the route path otherwise.
+func (m *grpcMethods) label(path string) string {
+	if m.isKnown(path) {
+		return path
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.known[path]; ok {
+		return path
+	}
+	if len(m.known) >= m.limit {
+		return m.route
+	}
+	m.known[path] = struct{}{}
+	return path
+}
+
+func (m *grpcMethods) methods() []string {
+	if m == nil {
+		return nil
+	}
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	out := make([]string, 0, len(m.known))
+	for method := range m.known {
+		out = append(out, method)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// grpcMethodRegistry holds the methods of the gRPC service routes of an update, the methods of the previous
+// config and the ones gone from the upstreams do not hold the labels after a reload.
+type grpcMethodRegistry struct {
+	lock   sync.Mutex
+	routes map[string]*grpcMethods
+}
+
+func newGRPCMethodRegistry() *grpcMethodRegistry {
+	return &grpcMethodRegistry{routes: map[string]*grpcMethods{}}
+}
+
+// get returns the methods of the gRPC service route seeded by its grpc_methods, nil for the other endpoints.
+func (r *grpcMethodRegistry) get(e *config.Endpoint) *grpcMethods {
+	prefix := grpcServicePrefix(e)
+	if prefix == "" {
+		return nil
+	}
+	key := e.Host + e.Path
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if m, ok := r.routes[key]; ok {
+		return m
+	}
+	m := &grpcMethods{route: e.Path, limit: len(e.GrpcMethods) + _maxGRPCMethodLabels, known: map[string]struct{}{}}
+	for _, method := range e.GrpcMethods {
+		m.known[prefix+method.Name] = struct{}{}
+	}
+	r.routes[key] = m
+	return m
+}
+
+type grpcMethodKey struct{}
+
+// grpcMethodLabel is the method label of a request of the gRPC service route, the method takes a label once
+// the upstream answers it, so the methods probed by the clients and unknown to the upstream do not.
+type grpcMethodLabel struct {
+	methods *grpcMethods
+	path    string
+	label   atomic.Pointer[string]
+}
+
+// withGRPCMethod sets the method label of the request of the gRPC service route.
+func (m *grpcMethods) withGRPCMethod(req *http.Request) *http.Request {
+	if m == nil {
+		return req
+	}
+	// the :path pseudo-header of HTTP/2 is the request path
+	return req.WithContext(context.WithValue(req.Context(), grpcMethodKey{}, &grpcMethodLabel{methods: m, path: req.URL.Path}))
+}
+
+// observeGRPCMethod labels the method of the request by the upstream response, the methods answered with
+// Unimplemented are labeled by the route path.
+func observeGRPCMethod(req *http.Request, resp *http.Response) {
+	l, ok := req.Context().Value(grpcMethodKey{}).(*grpcMethodLabel)
+	if !ok {
+		return
+	}
+	label := l.methods.route
+	// the HTTP 404 is mapped to Unimplemented by the gRPC clients
+	if resp.StatusCode != http.StatusNotFound && resp.Header.Get("Grpc-Status") != _grpcStatusUnimplemented {
+		label = l.methods.label(l.path)
+	}
+	l.label.Store(&label)
+}
+
+func (l *grpcMethodLabel) String() string {
+	if label := l.label.Load(); label != nil {
+		return *label
+	}
+	// not answered by the upstream
+	if l.methods.isKnown(l.path) {
+		return l.path
+	}
+	return l.methods.route
+}
+
+// metricsPath returns the path label of the request, it is the gRPC method on the gRPC service routes.
+func metricsPath(req *http.Request, labels middleware.MetricsLabels) string {
+	if l, ok := req.Context().Value(grpcMethodKey{}).(*grpcMethodLabel); ok {
+		return l.String()
+	}
+	return labels.Path()
+}
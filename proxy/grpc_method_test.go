@@ -0,0 +1,182 @@
+package proxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestGRPCMethodRoutes(t *testing.T) {
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{
+				"Content-Type": []string{"application/grpc"},
+				"X-Route":      []string{e.Path},
+				"X-Timeout":    []string{e.Timeout.AsDuration().String()},
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Trailer: http.Header{"Grpc-Status": []string{"0"}}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:    config.Protocol_GRPC,
+		Path:        "/helloworld.Greeter/*",
+		Method:      "POST",
+		Timeout:     durationpb.New(time.Second),
+		GrpcMethods: []*config.GRPCMethod{{Name: "SayHello", Timeout: durationpb.New(5 * time.Second)}},
+	}, {
+		// routed before the service route preceding it
+		Protocol: config.Protocol_GRPC,
+		Path:     "/helloworld.Greeter/SayBye",
+		Method:   "POST",
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	do := func(path string) http.Header {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Content-Type", "application/grpc")
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w.Result().Header
+	}
+	for path, want := range map[string][2]string{
+		"/helloworld.Greeter/SayHello": {"/helloworld.Greeter/SayHello", "5s"},
+		"/helloworld.Greeter/SayBye":   {"/helloworld.Greeter/SayBye", "0s"},
+		"/helloworld.Greeter/SayHi":    {"/helloworld.Greeter/*", "1s"},
+	} {
+		h := do(path)
+		if got := [2]string{h.Get("X-Route"), h.Get("X-Timeout")}; got != want {
+			t.Fatalf("%s: want %v but got %v", path, want, got)
+		}
+	}
+
+	// the requests of the service route are labeled by the method
+	counter := MetricRequestsTotal.WithLabelValues("GRPC", "POST", "/helloworld.Greeter/SayHi", "200", "", "")
+	if got := testutil.ToFloat64(counter); got != 1 {
+		t.Fatalf("want 1 request of the method but got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/proxy/router/inspect?path=/helloworld.Greeter/", nil))
+	var inspect []*struct {
+		Endpoint EndpointInspect `json:"endpoint"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&inspect); err != nil {
+		t.Fatal(err)
+	}
+	// the routes are ordered as SayHello, SayBye and the service route
+	if len(inspect) != 3 || inspect[2].Endpoint.Path != "/helloworld.Greeter/*" || !slices.Equal(inspect[2].Endpoint.GRPCMethods, []string{"/helloworld.Greeter/SayHello", "/helloworld.Greeter/SayHi"}) {
+		t.Fatalf("unexpected inspect: %+v", inspect)
+	}
+}
+
+func TestGRPCMethodsInvalid(t *testing.T) {
+	for _, e := range []*config.Endpoint{{
+		Protocol:    config.Protocol_HTTP,
+		Path:        "/helloworld.Greeter/*",
+		GrpcMethods: []*config.GRPCMethod{{Name: "SayHello"}},
+	}, {
+		Protocol:    config.Protocol_GRPC,
+		Path:        "/helloworld.Greeter/SayHello",
+		GrpcMethods: []*config.GRPCMethod{{Name: "SayHello"}},
+	}, {
+		Protocol:    config.Protocol_GRPC,
+		Path:        "/helloworld.Greeter/*",
+		GrpcMethods: []*config.GRPCMethod{{Name: "Say/Hello"}},
+	}} {
+		if _, err := expandGRPCMethods(e); err == nil {
+			t.Fatalf("want the error of %+v", e)
+		}
+	}
+}
+
+func TestGRPCMethodLabelCap(t *testing.T) {
+	m := newGRPCMethodRegistry().get(&config.Endpoint{Protocol: config.Protocol_GRPC, Path: "/helloworld.Greeter/*"})
+	for i := range _maxGRPCMethodLabels {
+		method := fmt.Sprintf("/helloworld.Greeter/M%d", i)
+		if got := m.label(method); got != method {
+			t.Fatalf("want %s but got %s", method, got)
+		}
+	}
+	if got := m.label("/helloworld.Greeter/Over"); got != "/helloworld.Greeter/*" {
+		t.Fatalf("want the route over the cap but got %s", got)
+	}
+	if got := m.label("/helloworld.Greeter/M0"); got != "/helloworld.Greeter/M0" {
+		t.Fatalf("want the known method but got %s", got)
+	}
+}
+
+func TestGRPCMethodLabels(t *testing.T) {
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": []string{"application/grpc"}, "Grpc-Status": []string{"0"}}
+			if req.URL.Path == "/labels.Greeter/Missing" {
+				header.Set("Grpc-Status", "12")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	update := func(methods ...string) {
+		t.Helper()
+		e := &config.Endpoint{Protocol: config.Protocol_GRPC, Path: "/labels.Greeter/*", Method: "POST"}
+		for _, m := range methods {
+			e.GrpcMethods = append(e.GrpcMethods, &config.GRPCMethod{Name: m})
+		}
+		c := &config.Gateway{Endpoints: []*config.Endpoint{e}}
+		if err := p.Update(client.NewBuildContext(c), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	methods := func() []string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		p.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/proxy/router/inspect?path=/labels.Greeter/", nil))
+		var inspect []*struct {
+			Endpoint EndpointInspect `json:"endpoint"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&inspect); err != nil {
+			t.Fatal(err)
+		}
+		return inspect[len(inspect)-1].Endpoint.GRPCMethods
+	}
+	update("SayHello")
+	for _, path := range []string{"/labels.Greeter/SayHi", "/labels.Greeter/Missing"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Content-Type", "application/grpc")
+		p.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	// the configured methods are seeded and the unimplemented ones are labeled by the route
+	if got := methods(); !slices.Equal(got, []string{"/labels.Greeter/SayHello", "/labels.Greeter/SayHi"}) {
+		t.Fatalf("unexpected methods %v", got)
+	}
+	if got := testutil.ToFloat64(MetricRequestsTotal.WithLabelValues("GRPC", "POST", "/labels.Greeter/*", "200", "", "")); got != 1 {
+		t.Fatalf("want the unimplemented method labeled by the route but got %v", got)
+	}
+	// the methods are reset on reload
+	update()
+	if got := methods(); len(got) != 0 {
+		t.Fatalf("want the methods reset but got %v", got)
+	}
+}
@@ -0,0 +1,90 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"google.golang.org/grpc/codes"
+)
+
+func isGRPCRequest(req *http.Request) bool {
+	return strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc")
+}
+
+// shouldMapGRPCStatus reports whether the grpc-status should be mapped into the http status,
+// true grpc clients always receive the original response.
+func shouldMapGRPCStatus(e *config.Endpoint, req *http.Request) bool {
+	return e.MapGrpcStatusToHttp &&
+		e.Protocol == config.Protocol_GRPC &&
+		!e.Stream &&
+		!isGRPCRequest(req)
+}
+
+// _maxGRPCStatusBodyBytes bounds the body read by mapGRPCStatus to receive the trailers.
+const _maxGRPCStatusBodyBytes = 1 << 20
+
+// mapGRPCStatus reads the whole response to receive the trailers, and replaces the response
+// with the mapped http status and a json error body if the grpc-status is not OK. The response
+// with the body larger than _maxGRPCStatusBodyBytes is passed through unmapped.
+func mapGRPCStatus(resp *http.Response) error {
+	if resp.Body == nil {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, _maxGRPCStatusBodyBytes+1))
+	if err != nil {
+		resp.Body.Close()
+		return err
+	}
+	if len(data) > _maxGRPCStatusBodyBytes {
+		resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+		return nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	grpcStatus, grpcMessage := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if grpcStatus == "" {
+		grpcStatus, grpcMessage = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if grpcStatus == "" || grpcStatus == "0" {
+		return nil
+	}
+	code, err := strconv.ParseUint(grpcStatus, 10, 32)
+	if err != nil {
+		return err
+	}
+	// see https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-HTTP2.md, grpc-message is percent-encoded
+	if message, err := url.PathUnescape(grpcMessage); err == nil {
+		grpcMessage = message
+	}
+	statusCode := status.FromGRPCCode(codes.Code(code))
+	body, err := json.Marshal(errors.New(statusCode, codes.Code(code).String(), grpcMessage))
+	if err != nil {
+		return err
+	}
+	for k := range resp.Header {
+		if strings.HasPrefix(k, "Grpc-") {
+			delete(resp.Header, k)
+		}
+	}
+	resp.Header.Set("Content-Type", "application/json")
+	resp.Header.Del("Content-Length")
+	resp.Trailer = nil
+	resp.StatusCode = statusCode
+	resp.ContentLength = int64(len(body))
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return nil
+}
+
+// prefixedBody is the body with the bytes already read put back in front of it.
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
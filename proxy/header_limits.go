@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrRequestHeaderTooLarge is returned when the request headers exceed the header limits of the endpoint.
+var ErrRequestHeaderTooLarge = errors.New("request header too large")
+
+const _reasonRequestHeaderTooLarge = "REQUEST_HEADER_FIELDS_TOO_LARGE"
+
+var (
+	_metricRequestHeaderBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "request_header_bytes",
+		Help:      "The total bytes of the request header names and values of the endpoints with the header limits",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 7),
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+	_metricRequestHeaderRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "request_header_rejected_total",
+		Help:      "The total number of requests rejected by the header limits",
+	}, []string{"protocol", "method", "path", "service", "basePath", "reason"})
+	_metricRequestHeaderModified = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "request_header_modified_total",
+		Help:      "The total number of request headers dropped or truncated by the header actions",
+	}, []string{"protocol", "method", "path", "service", "basePath", "header", "action"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricRequestHeaderBytes, _metricRequestHeaderRejected, _metricRequestHeaderModified)
+}
+
+// headerLimits applies the header actions and checks the sizes of the request headers.
+type headerLimits struct {
+	maxTotal int
+	maxValue int
+	actions  []headerAction
+	labels   []string
+}
+
+type headerAction struct {
+	name     string
+	action   config.HeaderAction_Action
+	maxBytes int
+}
+
+func newHeaderLimits(gw *gatewayContext, e *config.Endpoint) (*headerLimits, error) {
+	c := e.HeaderLimits
+	if c == nil {
+		c = gw.headerLimits
+	}
+	if c == nil {
+		return nil, nil
+	}
+	l := &headerLimits{
+		maxTotal: int(c.MaxTotalBytes),
+		maxValue: int(c.MaxValueBytes),
+		labels:   []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"]},
+	}
+	for _, a := range c.Actions {
+		if a.Name == "" {
+			return nil, errors.New("header action requires the header name")
+		}
+		if a.Action == config.HeaderAction_TRUNCATE && a.MaxBytes == 0 {
+			return nil, fmt.Errorf("header action of %s requires max_bytes to truncate", a.Name)
+		}
+		l.actions = append(l.actions, headerAction{
+			name:     http.CanonicalHeaderKey(a.Name),
+			action:   a.Action,
+			maxBytes: int(a.MaxBytes),
+		})
+	}
+	return l, nil
+}
+
+// check applies the header actions and returns the reason of the rejection, empty if the request is allowed.
+func (l *headerLimits) check(req *http.Request) string {
+	return checkRequestHeaders(req, l, nil)
+}
+
+// apply drops or truncates the headers of the actions.
+func (l *headerLimits) apply(req *http.Request) {
+	if l == nil {
+		return
+	}
+	for _, a := range l.actions {
+		values, ok := req.Header[a.name]
+		if !ok {
+			continue
+		}
+		switch a.action {
+		case config.HeaderAction_DROP:
+			delete(req.Header, a.name)
+			l.modified(a.name, "drop")
+		case config.HeaderAction_TRUNCATE:
+			truncated := false
+			for i, v := range values {
+				if len(v) > a.maxBytes {
+					values[i] = v[:a.maxBytes]
+					truncated = true
+				}
+			}
+			if truncated {
+				l.modified(a.name, "truncate")
+			}
+		}
+	}
+}
+
+// exceeded returns the limit the header sizes exceed, empty if none.
+func (l *headerLimits) exceeded(sizes headerSizes) string {
+	if l == nil {
+		return ""
+	}
+	_metricRequestHeaderBytes.WithLabelValues(l.labels...).Observe(float64(sizes.bytes))
+	if l.maxValue > 0 && sizes.largest > l.maxValue {
+		return "value"
+	}
+	if l.maxTotal > 0 && sizes.bytes > l.maxTotal {
+		return "total"
+	}
+	return ""
+}
+
+// checkRequestHeaders applies the header actions, and measures the request headers in one pass for both the
+// limits and the stats. The reason of the rejection is returned, empty if the request is allowed.
+func checkRequestHeaders(req *http.Request, limits *headerLimits, stats *headerStats) string {
+	if limits == nil && stats == nil {
+		return ""
+	}
+	limits.apply(req)
+	sizes := measureHeaders(req.Header, stats.names(headerDirectionRequest))
+	stats.observe(headerDirectionRequest, sizes)
+	return limits.exceeded(sizes)
+}
+
+func (l *headerLimits) modified(name, action string) {
+	_metricRequestHeaderModified.WithLabelValues(append(l.labels[:len(l.labels):len(l.labels)], name, action)...).Inc()
+}
+
+func writeHeaderLimitsError(w http.ResponseWriter, r *http.Request, e *config.Endpoint, reason string, observer Observer) {
+	statusCode := http.StatusRequestHeaderFieldsTooLarge
+	labels := []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"], reason}
+	_metricRequestHeaderRejected.WithLabelValues(labels...).Inc()
+	observer.HandleRequest(r, w.Header(), statusCode, ErrRequestHeaderTooLarge)
+	message := fmt.Sprintf("%s: the %s size exceeds the limit", ErrRequestHeaderTooLarge, reason)
+	if e.Protocol == config.Protocol_GRPC {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(statusCode))))
+		w.Header().Set("Grpc-Message", message)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	writeErrorResponse(w, statusCode, _reasonRequestHeaderTooLarge, message)
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestHeaderLimits(t *testing.T) {
+	gw := &gatewayContext{headerLimits: &config.HeaderLimits{MaxTotalBytes: 1024, MaxValueBytes: 256}}
+	e := &config.Endpoint{Path: "/api/users", Method: http.MethodGet, HeaderLimits: &config.HeaderLimits{
+		MaxTotalBytes: 512,
+		MaxValueBytes: 128,
+		Actions: []*config.HeaderAction{
+			{Name: "cookie", Action: config.HeaderAction_DROP},
+			{Name: "X-Trace-Baggage", Action: config.HeaderAction_TRUNCATE, MaxBytes: 16},
+		},
+	}}
+	l, err := newHeaderLimits(gw, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		header http.Header
+		reason string
+	}{
+		{"small", http.Header{"Accept": {"application/json"}}, ""},
+		{"dropped cookie", http.Header{"Cookie": {strings.Repeat("c", 64<<10)}}, ""},
+		{"truncated", http.Header{"X-Trace-Baggage": {strings.Repeat("b", 1024)}}, ""},
+		{"value", http.Header{"Authorization": {strings.Repeat("a", 129)}}, "value"},
+		{"total", http.Header{"X-A": {strings.Repeat("a", 100)}, "X-B": {strings.Repeat("b", 100)}, "X-C": {strings.Repeat("c", 100)}, "X-D": {strings.Repeat("d", 100)}, "X-E": {strings.Repeat("e", 100)}}, "total"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		req.Header = test.header
+		if reason := l.check(req); reason != test.reason {
+			t.Errorf("%s: want reason %q but got %q", test.name, test.reason, reason)
+		}
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Cookie", "session=1")
+	req.Header.Set("X-Trace-Baggage", strings.Repeat("b", 32))
+	l.check(req)
+	if _, ok := req.Header["Cookie"]; ok {
+		t.Fatal("want the cookie dropped")
+	}
+	if got := req.Header.Get("X-Trace-Baggage"); len(got) != 16 {
+		t.Fatalf("want the header truncated to 16 bytes but got %d", len(got))
+	}
+
+	// the gateway limits are used if the endpoint has none
+	l, err = newHeaderLimits(gw, &config.Endpoint{Path: "/other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/other", nil)
+	req.Header.Set("Authorization", strings.Repeat("a", 200))
+	if reason := l.check(req); reason != "" {
+		t.Fatalf("want the gateway limits used but got %q", reason)
+	}
+
+	if _, err := newHeaderLimits(gw, &config.Endpoint{HeaderLimits: &config.HeaderLimits{
+		Actions: []*config.HeaderAction{{Name: "Cookie", Action: config.HeaderAction_TRUNCATE}},
+	}}); err == nil {
+		t.Fatal("want the truncation without max_bytes rejected")
+	}
+}
+
+func TestHeaderLimitsRejection(t *testing.T) {
+	p, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &config.Endpoint{Path: "/api/users", Method: http.MethodGet, HeaderLimits: &config.HeaderLimits{MaxTotalBytes: 64}}
+	l, err := newHeaderLimits(&gatewayContext{}, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.Header.Set("Cookie", strings.Repeat("c", 64<<10))
+	w := httptest.NewRecorder()
+	writeHeaderLimitsError(w, req, e, l.check(req), p.observable.Observe(e))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusRequestHeaderFieldsTooLarge, _reasonRequestHeaderTooLarge)
+}
@@ -0,0 +1,293 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_defaultHeaderTopK = 20
+	_maxHeaderTopK     = 100
+	// the header names tracked are truncated to the length so the tracker is bounded by the size as well
+	_maxTrackedHeaderName = 128
+
+	headerDirectionRequest  = "request"
+	headerDirectionResponse = "response"
+)
+
+var (
+	_metricHeaderCount = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "header_count",
+		Help:      "The number of the header values of the endpoints with the header stats",
+		Buckets:   prometheus.ExponentialBuckets(4, 2, 8),
+	}, []string{"protocol", "method", "path", "service", "basePath", "direction"})
+	_metricHeaderBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "header_bytes",
+		Help:      "The total bytes of the header names and values of the endpoints with the header stats",
+		Buckets:   prometheus.ExponentialBuckets(256, 4, 7),
+	}, []string{"protocol", "method", "path", "service", "basePath", "direction"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricHeaderCount, _metricHeaderBytes)
+}
+
+// headerSizes is the sizes of the headers measured in one pass.
+type headerSizes struct {
+	count   int
+	bytes   int
+	largest int
+}
+
+// measureHeaders sums the sizes of the headers, the size of every header is recorded by names if not nil.
+func measureHeaders(h http.Header, names *topHeaders) headerSizes {
+	var sizes headerSizes
+	if names != nil {
+		names.lock.Lock()
+		defer names.lock.Unlock()
+	}
+	for name, values := range h {
+		size := 0
+		for _, v := range values {
+			size += len(name) + len(v)
+			sizes.largest = max(sizes.largest, len(v))
+		}
+		sizes.count += len(values)
+		sizes.bytes += size
+		if names != nil {
+			names.observeLocked(name, size)
+		}
+	}
+	return sizes
+}
+
+// HeaderSize is a header name among the largest observed by a route.
+type HeaderSize struct {
+	Name string `json:"name"`
+	// MaxBytes is the largest total bytes of the header name and values in a message.
+	MaxBytes int `json:"max_bytes"`
+	// Seen is the number of the messages with the header since it is tracked.
+	Seen uint64 `json:"seen"`
+}
+
+// topHeaders tracks up to k header names with the largest sizes, a name not tracked replaces the smallest
+// one when the tracker is full and it is larger.
+type topHeaders struct {
+	k     int
+	lock  sync.Mutex
+	sizes map[string]*HeaderSize
+	// the smallest tracked size, the names not larger are skipped without scanning the tracker
+	floor int
+}
+
+func newTopHeaders(k int) *topHeaders {
+	return &topHeaders{k: k, sizes: make(map[string]*HeaderSize, k)}
+}
+
+func (t *topHeaders) observeLocked(name string, size int) {
+	if len(name) > _maxTrackedHeaderName {
+		name = name[:_maxTrackedHeaderName]
+	}
+	if s, ok := t.sizes[name]; ok {
+		s.Seen++
+		s.MaxBytes = max(s.MaxBytes, size)
+		return
+	}
+	if len(t.sizes) < t.k {
+		t.sizes[name] = &HeaderSize{Name: name, MaxBytes: size, Seen: 1}
+		t.floor = t.smallestLocked()
+		return
+	}
+	if size <= t.floor {
+		return
+	}
+	for n, s := range t.sizes {
+		if s.MaxBytes == t.floor {
+			delete(t.sizes, n)
+			break
+		}
+	}
+	t.sizes[name] = &HeaderSize{Name: name, MaxBytes: size, Seen: 1}
+	t.floor = t.smallestLocked()
+}
+
+func (t *topHeaders) smallestLocked() int {
+	smallest := -1
+	for _, s := range t.sizes {
+		if smallest < 0 || s.MaxBytes < smallest {
+			smallest = s.MaxBytes
+		}
+	}
+	return smallest
+}
+
+func (t *topHeaders) export() []HeaderSize {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	out := make([]HeaderSize, 0, len(t.sizes))
+	for _, s := range t.sizes {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MaxBytes != out[j].MaxBytes {
+			return out[i].MaxBytes > out[j].MaxBytes
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// headerStats collects the header histograms and the largest header names of an endpoint.
+type headerStats struct {
+	method, path, host string
+
+	labels   []string
+	request  *topHeaders
+	response *topHeaders
+}
+
+func (s *headerStats) names(direction string) *topHeaders {
+	if s == nil {
+		return nil
+	}
+	if direction == headerDirectionRequest {
+		return s.request
+	}
+	return s.response
+}
+
+func (s *headerStats) observe(direction string, sizes headerSizes) {
+	if s == nil {
+		return
+	}
+	labels := append(s.labels[:len(s.labels):len(s.labels)], direction)
+	_metricHeaderCount.WithLabelValues(labels...).Observe(float64(sizes.count))
+	_metricHeaderBytes.WithLabelValues(labels...).Observe(float64(sizes.bytes))
+}
+
+// observeResponse measures the response headers.
+func (s *headerStats) observeResponse(h http.Header) {
+	if s == nil {
+		return
+	}
+	s.observe(headerDirectionResponse, measureHeaders(h, s.response))
+}
+
+// RouteHeaderStats is the largest header names observed by a route.
+type RouteHeaderStats struct {
+	Method   string       `json:"method"`
+	Path     string       `json:"path"`
+	Host     string       `json:"host,omitempty"`
+	Request  []HeaderSize `json:"request"`
+	Response []HeaderSize `json:"response"`
+}
+
+// headerStatsRegistry holds the header stats of the routes, the stats of a route are kept across the reloads
+// unless its top_k changes, the stats of the routes removed by a reload are dropped.
+type headerStatsRegistry struct {
+	lock   sync.Mutex
+	routes map[string]*headerStats
+	// building is the routes got by the build in progress
+	building map[string]struct{}
+}
+
+func newHeaderStatsRegistry() *headerStatsRegistry {
+	return &headerStatsRegistry{routes: map[string]*headerStats{}}
+}
+
+func (r *headerStatsRegistry) get(e *config.Endpoint) *headerStats {
+	if !e.HeaderStats.GetEnabled() {
+		return nil
+	}
+	k := int(e.HeaderStats.TopK)
+	if k == 0 {
+		k = _defaultHeaderTopK
+	}
+	k = min(k, _maxHeaderTopK)
+	key := e.Method + " " + e.Host + e.Path
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.building != nil {
+		r.building[key] = struct{}{}
+	}
+	if s, ok := r.routes[key]; ok && s.request.k == k {
+		return s
+	}
+	s := &headerStats{
+		method:   e.Method,
+		path:     e.Path,
+		host:     e.Host,
+		labels:   []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"]},
+		request:  newTopHeaders(k),
+		response: newTopHeaders(k),
+	}
+	r.routes[key] = s
+	return s
+}
+
+// begin starts collecting the routes got by a build.
+func (r *headerStatsRegistry) begin() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.building = map[string]struct{}{}
+}
+
+// commit drops the stats of the routes not got since begin, called once the build is applied.
+func (r *headerStatsRegistry) commit() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for key := range r.routes {
+		if _, ok := r.building[key]; !ok {
+			delete(r.routes, key)
+		}
+	}
+	r.building = nil
+}
+
+func (r *headerStatsRegistry) export() []*RouteHeaderStats {
+	r.lock.Lock()
+	routes := make([]*headerStats, 0, len(r.routes))
+	for _, s := range r.routes {
+		routes = append(routes, s)
+	}
+	r.lock.Unlock()
+	out := make([]*RouteHeaderStats, 0, len(routes))
+	for _, s := range routes {
+		out = append(out, &RouteHeaderStats{
+			Method:   s.method,
+			Path:     s.path,
+			Host:     s.host,
+			Request:  s.request.export(),
+			Response: s.response.export(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+// HeaderStats returns the largest header names observed by the routes with the header stats.
+func (p *Proxy) HeaderStats() []*RouteHeaderStats {
+	return p.headerStats.export()
+}
+
+func (p *Proxy) registerHeaderStatsDebugHandler(debugMux *http.ServeMux) {
+	debugMux.HandleFunc("/debug/proxy/headers", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(p.HeaderStats())
+	})
+}
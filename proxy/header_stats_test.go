@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestTopHeaders(t *testing.T) {
+	names := newTopHeaders(3)
+	h := http.Header{}
+	for i := 0; i < 50; i++ {
+		h.Set("X-Header-"+strconv.Itoa(i), strings.Repeat("v", i))
+	}
+	h.Set("Cookie", strings.Repeat("c", 4096))
+	h.Set("X-"+strings.Repeat("n", 1000), "v")
+	sizes := measureHeaders(h, names)
+	if sizes.count != 52 || sizes.largest != 4096 {
+		t.Fatalf("unexpected sizes %+v", sizes)
+	}
+	got := names.export()
+	if len(got) != 3 {
+		t.Fatalf("want the tracker capped to 3 names but got %d", len(got))
+	}
+	if got[0].Name != "Cookie" || got[0].MaxBytes != len("Cookie")+4096 {
+		t.Fatalf("want the cookie the largest but got %+v", got[0])
+	}
+	if len(got[1].Name) != _maxTrackedHeaderName {
+		t.Fatalf("want the long name truncated but got %d bytes", len(got[1].Name))
+	}
+	if got[2].Name != "X-Header-49" {
+		t.Fatalf("want the third largest header but got %+v", got[2])
+	}
+
+	measureHeaders(http.Header{"Cookie": {"small"}, "X-Small": {"v"}}, names)
+	got = names.export()
+	if got[0].Seen != 2 || got[0].MaxBytes != len("Cookie")+4096 {
+		t.Fatalf("want the max size kept but got %+v", got[0])
+	}
+	for _, s := range got {
+		if s.Name == "X-Small" {
+			t.Fatal("want the smaller header not tracked")
+		}
+	}
+}
+
+func TestHeaderStatsDebug(t *testing.T) {
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Set-Cookie": {strings.Repeat("s", 100)}}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:    config.Protocol_HTTP,
+		Path:        "/api/users",
+		Method:      "GET",
+		HeaderStats: &config.HeaderStats{Enabled: true, TopK: 2},
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/api/orders",
+		Method:   "GET",
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/api/users", "/api/orders"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Cookie", strings.Repeat("c", 1000))
+		p.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/proxy/headers", nil))
+	var out []*RouteHeaderStats
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0].Path != "/api/users" {
+		t.Fatalf("want only the route with the header stats but got %+v", out)
+	}
+	if len(out[0].Request) == 0 || out[0].Request[0].Name != "Cookie" {
+		t.Fatalf("want the cookie tracked but got %+v", out[0].Request)
+	}
+	if len(out[0].Response) != 1 || out[0].Response[0].Name != "Set-Cookie" {
+		t.Fatalf("want the response headers tracked but got %+v", out[0].Response)
+	}
+
+	// the stats of the kept route survive the reload
+	c.Endpoints[1].HeaderStats = &config.HeaderStats{Enabled: true}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if out := p.HeaderStats(); len(out) != 2 || out[1].Path != "/api/users" || len(out[1].Request) == 0 {
+		t.Fatalf("want the stats of the kept route but got %+v", out)
+	}
+	// the stats of the removed route are dropped
+	c.Endpoints = c.Endpoints[1:]
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if out := p.HeaderStats(); len(out) != 1 || out[0].Path != "/api/orders" {
+		t.Fatalf("want the stats of the removed route dropped but got %+v", out)
+	}
+}
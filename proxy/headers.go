@@ -0,0 +1,152 @@
+package proxy
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// hopHeaders are the hop-by-hop headers, see https://www.rfc-editor.org/rfc/rfc7230#section-6.1
+var hopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// removeHopByHopHeaders removes the hop-by-hop headers and the headers listed in Connection.
+func removeHopByHopHeaders(h http.Header) {
+	for _, f := range h["Connection"] {
+		for _, sf := range strings.Split(f, ",") {
+			if sf = textproto.TrimString(sf); sf != "" {
+				h.Del(sf)
+			}
+		}
+	}
+	for _, f := range hopHeaders {
+		h.Del(f)
+	}
+}
+
+func headerValuesContainsToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if strings.EqualFold(textproto.TrimString(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sanitizeRequestHeaders removes the hop-by-hop request headers like httputil.ReverseProxy does,
+// the upgrade request is preserved on stream endpoints only.
+func sanitizeRequestHeaders(h http.Header, stream bool) {
+	keepTrailers := headerValuesContainsToken(h["Te"], "trailers")
+	upgrade := ""
+	if stream && headerValuesContainsToken(h["Connection"], "upgrade") {
+		upgrade = h.Get("Upgrade")
+	}
+	removeHopByHopHeaders(h)
+	// grpc requires the te: trailers header
+	if keepTrailers {
+		h.Set("Te", "trailers")
+	}
+	if upgrade != "" {
+		h.Set("Connection", "Upgrade")
+		h.Set("Upgrade", upgrade)
+	}
+}
+
+type headerMatcher struct {
+	exact  map[string]struct{}
+	prefix []string
+}
+
+func newHeaderMatcher(in []string) *headerMatcher {
+	if len(in) == 0 {
+		return nil
+	}
+	m := &headerMatcher{exact: map[string]struct{}{}}
+	for _, item := range in {
+		item = http.CanonicalHeaderKey(item)
+		if strings.HasSuffix(item, "*") {
+			m.prefix = append(m.prefix, strings.TrimSuffix(item, "*"))
+			continue
+		}
+		m.exact[item] = struct{}{}
+	}
+	return m
+}
+
+func (m *headerMatcher) Match(key string) bool {
+	key = http.CanonicalHeaderKey(key)
+	if _, ok := m.exact[key]; ok {
+		return true
+	}
+	for _, prefix := range m.prefix {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcHeaders are required by the grpc protocol and never filtered on grpc endpoints.
+var grpcHeaders = map[string]struct{}{
+	"Content-Type":            {},
+	"Grpc-Status":             {},
+	"Grpc-Message":            {},
+	"Grpc-Status-Details-Bin": {},
+}
+
+// responseHeaderPolicy filters the upstream response headers copied to the client.
+type responseHeaderPolicy struct {
+	allow *headerMatcher
+	deny  *headerMatcher
+	grpc  bool
+}
+
+func newResponseHeaderPolicy(gw *gatewayContext, e *config.Endpoint) *responseHeaderPolicy {
+	allow, deny := gw.responseHeaderAllow, gw.responseHeaderDeny
+	if len(e.ResponseHeaderAllow) > 0 {
+		allow = e.ResponseHeaderAllow
+	}
+	if len(e.ResponseHeaderDeny) > 0 {
+		deny = e.ResponseHeaderDeny
+	}
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+	return &responseHeaderPolicy{
+		allow: newHeaderMatcher(allow),
+		deny:  newHeaderMatcher(deny),
+		grpc:  e.Protocol == config.Protocol_GRPC,
+	}
+}
+
+// Filter removes the headers not allowed or denied, deny takes precedence over allow.
+func (p *responseHeaderPolicy) Filter(h http.Header) {
+	if p == nil {
+		return
+	}
+	for key := range h {
+		if _, ok := grpcHeaders[http.CanonicalHeaderKey(key)]; ok && p.grpc {
+			continue
+		}
+		if p.deny != nil && p.deny.Match(key) {
+			delete(h, key)
+			continue
+		}
+		if p.allow != nil && !p.allow.Match(key) {
+			delete(h, key)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package proxy
+
+import (
+	"io"
+
+	"github.com/aide-family/goddess/client"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// upstreamServices collects the clients of the endpoints by the service metadata during one update.
+type upstreamServices map[string][]client.NodeFilterInspector
+
+func (s upstreamServices) add(e *config.Endpoint, closer io.Closer) {
+	service := e.Metadata["service"]
+	if service == "" {
+		return
+	}
+	if inspector, ok := closer.(client.NodeFilterInspector); ok {
+		s[service] = append(s[service], inspector)
+	}
+}
+
+// ServiceHealth returns whether the services of the endpoints have upstream nodes matching the node filters,
+// it is keyed by the service metadata of the endpoints.
+func (p *Proxy) ServiceHealth() map[string]bool {
+	services, _ := p.services.Load().(upstreamServices)
+	out := make(map[string]bool, len(services))
+	for service, inspectors := range services {
+		out[service] = false
+		for _, inspector := range inspectors {
+			if inspector.NodeFilterInspect().Matching > 0 {
+				out[service] = true
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// hostGroups resolves the host group of the endpoints.
+type hostGroups struct {
+	groups []*config.HostGroup
+	byName map[string]*config.HostGroup
+	// host or `*.domain` to the group
+	byHost map[string]*config.HostGroup
+}
+
+func newHostGroups(in []*config.HostGroup) (*hostGroups, error) {
+	g := &hostGroups{
+		groups: in,
+		byName: make(map[string]*config.HostGroup, len(in)),
+		byHost: map[string]*config.HostGroup{},
+	}
+	for _, group := range in {
+		if group.Name == "" {
+			return nil, errors.New("invalid host group: empty name")
+		}
+		if _, ok := g.byName[group.Name]; ok {
+			return nil, fmt.Errorf("invalid host group %q: duplicated name", group.Name)
+		}
+		if len(group.Fallback.GetHosts()) > 0 {
+			return nil, fmt.Errorf("invalid host group %q: fallback hosts are not supported", group.Name)
+		}
+		g.byName[group.Name] = group
+		for _, host := range group.Hosts {
+			host = strings.ToLower(host)
+			if other, ok := g.byHost[host]; ok {
+				return nil, fmt.Errorf("invalid host group %q: host %q is already in group %q", group.Name, host, other.Name)
+			}
+			g.byHost[host] = group
+		}
+	}
+	return g, nil
+}
+
+// lookupHost returns the group of the host, the exact host takes precedence over the longest wildcard.
+func (g *hostGroups) lookupHost(host string) (*config.HostGroup, bool) {
+	if len(g.byHost) == 0 || host == "" {
+		return nil, false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if group, ok := g.byHost[host]; ok {
+		return group, true
+	}
+	for {
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return nil, false
+		}
+		host = host[i+1:]
+		if group, ok := g.byHost["*."+host]; ok {
+			return group, true
+		}
+	}
+}
+
+// resolve returns the endpoint with the defaults of its host group, the endpoint is returned as is if it has no group.
+func (g *hostGroups) resolve(e *config.Endpoint) (*config.Endpoint, error) {
+	var group *config.HostGroup
+	if e.HostGroup != "" {
+		var ok bool
+		if group, ok = g.byName[e.HostGroup]; !ok {
+			return nil, fmt.Errorf("endpoint %s %s: unknown host group %q", e.Method, e.Path, e.HostGroup)
+		}
+	} else if group, _ = g.lookupHost(e.Host); group == nil {
+		return e, nil
+	}
+	out := proto.Clone(e).(*config.Endpoint)
+	out.HostGroup = group.Name
+	if out.Timeout == nil {
+		out.Timeout = group.Timeout
+	}
+	if out.Retry == nil {
+		out.Retry = group.Retry
+	}
+	if len(out.ResponseHeaderAllow) == 0 {
+		out.ResponseHeaderAllow = group.ResponseHeaderAllow
+	}
+	if len(out.ResponseHeaderDeny) == 0 {
+		out.ResponseHeaderDeny = group.ResponseHeaderDeny
+	}
+	// the group is the service label of the metrics if the endpoint has no service.
+	if out.Metadata["service"] == "" {
+		if out.Metadata == nil {
+			out.Metadata = map[string]string{}
+		}
+		out.Metadata["service"] = group.Name
+	}
+	return out, nil
+}
+
+func (g *hostGroups) middlewares(e *config.Endpoint) []*config.Middleware {
+	if group, ok := g.byName[e.HostGroup]; ok {
+		return group.Middlewares
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package proxy
+
+import (
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// builtChain is the middleware chain of an endpoint built from one config.
+type builtChain struct {
+	tripper http.RoundTripper
+	hooks   attemptHooks
+}
+
+// middlewareChain holds the current middleware chain of an endpoint over the client of the endpoint,
+// the chain is swapped in place if only the middleware options change so the client and the router are kept.
+// A request loads the chain once, all of its attempts are sent through the same chain.
+type middlewareChain struct {
+	base    http.RoundTripper
+	current atomic.Pointer[builtChain]
+}
+
+func (c *middlewareChain) load() *builtChain {
+	return c.current.Load()
+}
+
+// buildChain builds the endpoint, host group and gateway middlewares of the endpoint over the base tripper.
+func (p *Proxy) buildChain(gw *gatewayContext, e *config.Endpoint, base http.RoundTripper) (*builtChain, error) {
+	var hooks attemptHooks
+	tripper, err := p.buildMiddleware(gw.chains, e.Middlewares, base, &hooks)
+	if err != nil {
+		return nil, err
+	}
+	tripper, err = p.buildMiddleware(gw.chains, gw.hostGroups.middlewares(e), tripper, &hooks)
+	if err != nil {
+		return nil, err
+	}
+	tripper, err = p.buildMiddleware(gw.chains, gw.middlewares, tripper, &hooks)
+	if err != nil {
+		return nil, err
+	}
+	return &builtChain{tripper: tripper, hooks: hooks}, nil
+}
+
+// extraChain is the middleware chain of an endpoint built out of the routes of the config, eg: the fallback
+// endpoints and the rollout baselines.
+type extraChain struct {
+	// the endpoint as built, resolved by the host groups
+	endpoint *config.Endpoint
+	chain    *middlewareChain
+}
+
+// appliedConfig is the last config applied by a full update and the middleware chains of its endpoints,
+// the chains are indexed as the endpoints of the config, nil for the endpoints in maintenance.
+type appliedConfig struct {
+	config *config.Gateway
+	chains []*middlewareChain
+	extras []*extraChain
+}
+
+// onlyMiddlewareOptionsChanged reports whether the configs differ only in the options of the gateway,
+// host group and endpoint middlewares, the names and the order of the middlewares must be the same.
+func onlyMiddlewareOptionsChanged(last, next *config.Gateway) bool {
+	if proto.Equal(last, next) {
+		return false
+	}
+	return proto.Equal(withoutMiddlewareOptions(last), withoutMiddlewareOptions(next))
+}
+
+func middlewaresEqual(a, b []*config.Middleware) bool {
+	return slices.EqualFunc(a, b, func(x, y *config.Middleware) bool { return proto.Equal(x, y) })
+}
+
+// changedHostGroups returns the names of the host groups whose middlewares changed, the groups are the same
+// but the middleware options.
+func changedHostGroups(last, next *config.Gateway) map[string]bool {
+	changed := map[string]bool{}
+	for i, group := range next.HostGroups {
+		if !middlewaresEqual(last.HostGroups[i].Middlewares, group.Middlewares) {
+			changed[group.Name] = true
+		}
+	}
+	return changed
+}
+
+func withoutMiddlewareOptions(c *config.Gateway) *config.Gateway {
+	c = proto.Clone(c).(*config.Gateway)
+	strip := func(ms []*config.Middleware) {
+		for _, m := range ms {
+			m.Options = nil
+		}
+	}
+	strip(c.Middlewares)
+	for _, group := range c.HostGroups {
+		strip(group.Middlewares)
+	}
+	for _, e := range c.Endpoints {
+		strip(e.Middlewares)
+	}
+	return c
+}
+
+// trySwapMiddlewares rebuilds the middleware chains in place if only the middleware options changed since
+// the last update, it reports false if a full update is required. Only the chains with the changed middlewares
+// are rebuilt, including the chains of the fallback endpoints and the rollout baselines, no chain is swapped if
+// any fails to build.
+func (p *Proxy) trySwapMiddlewares(c *config.Gateway) (bool, error) {
+	p.appliedLock.Lock()
+	defer p.appliedLock.Unlock()
+	applied := p.applied
+	if applied == nil || !onlyMiddlewareOptionsChanged(applied.config, c) {
+		return false, nil
+	}
+	for _, e := range c.Endpoints {
+		// the chains of the gRPC method routes are not indexed by the endpoints
+		if len(e.GrpcMethods) > 0 {
+			return false, nil
+		}
+	}
+	gw, err := newGatewayContext(c)
+	if err != nil {
+		return true, err
+	}
+	gw.chains = newChainCache(p.shareable)
+	gatewayChanged := !middlewaresEqual(applied.config.Middlewares, c.Middlewares)
+	groupsChanged := changedHostGroups(applied.config, c)
+	var (
+		targets []*middlewareChain
+		built   []*builtChain
+	)
+	rebuild := func(e *config.Endpoint, chain *middlewareChain) error {
+		b, err := p.buildChain(gw, e, chain.base)
+		if err != nil {
+			return err
+		}
+		targets, built = append(targets, chain), append(built, b)
+		return nil
+	}
+	for i, e := range c.Endpoints {
+		if applied.chains[i] == nil {
+			continue
+		}
+		e, err := gw.hostGroups.resolve(e)
+		if err != nil {
+			return true, err
+		}
+		if !gatewayChanged && !groupsChanged[e.HostGroup] && middlewaresEqual(applied.config.Endpoints[i].Middlewares, e.Middlewares) {
+			continue
+		}
+		if err := rebuild(e, applied.chains[i]); err != nil {
+			return true, err
+		}
+	}
+	// the options of the middlewares of the extra endpoints themselves are not stripped, so only the gateway and
+	// the host group middlewares of them may change
+	for _, extra := range applied.extras {
+		if !gatewayChanged && !groupsChanged[extra.endpoint.HostGroup] {
+			continue
+		}
+		if err := rebuild(extra.endpoint, extra.chain); err != nil {
+			return true, err
+		}
+	}
+	for i, chain := range built {
+		targets[i].current.Store(chain)
+	}
+	p.applied = &appliedConfig{config: proto.Clone(c).(*config.Gateway), chains: applied.chains, extras: applied.extras}
+	log.Infof("swapped the middlewares of %d chains in place", len(built))
+	return true, nil
+}
+
+func (p *Proxy) storeApplied(c *config.Gateway, chains []*middlewareChain, extras []*extraChain) {
+	p.appliedLock.Lock()
+	defer p.appliedLock.Unlock()
+	p.applied = &appliedConfig{config: proto.Clone(c).(*config.Gateway), chains: chains, extras: extras}
+}
@@ -0,0 +1,211 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSwapMiddlewareOptions(t *testing.T) {
+	withVersion := func(version string) *config.Gateway {
+		opts, err := anypb.New(wrapperspb.String(version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &config.Gateway{
+			Name: "Test",
+			Endpoints: []*config.Endpoint{{
+				Protocol:    config.Protocol_HTTP,
+				Path:        "/api",
+				Method:      "GET",
+				Middlewares: []*config.Middleware{{Name: "version", Options: opts}},
+				Retry: &config.Retry{
+					Attempts: 2,
+					Conditions: []*config.Condition{{
+						Condition: &config.Condition_ByStatusCode{ByStatusCode: "503"},
+					}},
+				},
+			}},
+		}
+	}
+	var (
+		lock     sync.Mutex
+		versions []string
+		clients  int
+	)
+	blocking := make(chan struct{})
+	unblock := make(chan struct{})
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		clients++
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			lock.Lock()
+			versions = append(versions, req.Header.Get("X-Version"))
+			lock.Unlock()
+			status := http.StatusOK
+			if req.URL.Query().Get("block") != "" && req.Header.Get("X-Attempt") == "0" {
+				// the first attempt waits for the swap and is retried
+				close(blocking)
+				<-unblock
+				status = http.StatusServiceUnavailable
+			}
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		v := &wrapperspb.StringValue{}
+		if err := anypb.UnmarshalTo(c.Options, v, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				opts, _ := middleware.FromRequestContext(req.Context())
+				req.Header.Set("X-Version", v.Value)
+				req.Header.Set("X-Attempt", strconv.Itoa(opts.Attempt))
+				return next.RoundTrip(req)
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := withVersion("v1")
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api?block=1", nil))
+	}()
+	<-blocking
+	c = withVersion("v2")
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	close(unblock)
+	<-done
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	if clients != 1 {
+		t.Fatalf("want the client kept for the option only change but got %d clients", clients)
+	}
+	// the in-flight request is retried with the chain it started with
+	if want := []string{"v1", "v1", "v2"}; strings.Join(versions, ",") != strings.Join(want, ",") {
+		t.Fatalf("want the versions %v but got %v", want, versions)
+	}
+
+	// the other changes rebuild the endpoints
+	c = withVersion("v2")
+	c.Endpoints[0].Retry.Attempts = 3
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if clients != 2 {
+		t.Fatalf("want the endpoint rebuilt but got %d clients", clients)
+	}
+
+	// no chain is swapped if the new options are invalid
+	c = proto.Clone(c).(*config.Gateway)
+	c.Endpoints[0].Middlewares[0].Options = &anypb.Any{TypeUrl: "type.googleapis.com/unknown"}
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want the invalid options rejected")
+	}
+	versions = nil
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	if len(versions) != 1 || versions[0] != "v2" {
+		t.Fatalf("want the chain kept but got %v", versions)
+	}
+}
+
+func TestSwapMiddlewareOptionsScope(t *testing.T) {
+	options := func(version string) *anypb.Any {
+		opts, err := anypb.New(wrapperspb.String(version))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return opts
+	}
+	newConfig := func(gateway, endpoint string) *config.Gateway {
+		return &config.Gateway{
+			Name:        "Test",
+			Middlewares: []*config.Middleware{{Name: "gateway", Options: options(gateway)}},
+			Endpoints: []*config.Endpoint{{
+				Protocol:    config.Protocol_HTTP,
+				Path:        "/a",
+				Method:      "GET",
+				Middlewares: []*config.Middleware{{Name: "endpoint", Options: options(endpoint)}},
+			}, {
+				Protocol: config.Protocol_HTTP,
+				Path:     "/b",
+				Method:   "GET",
+			}},
+			Fallback: &config.Fallback{NotFound: &config.FallbackAction{Action: &config.FallbackAction_Endpoint{Endpoint: &config.Endpoint{
+				Protocol: config.Protocol_HTTP,
+				Backends: []*config.Backend{{Target: "127.0.0.1:8000"}},
+			}}}},
+		}
+	}
+	var received []string
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			received = append(received, req.Header.Get("X-Gateway"))
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	builds := map[string]int{}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		v := &wrapperspb.StringValue{}
+		if err := anypb.UnmarshalTo(c.Options, v, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+		builds[c.Name]++
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if c.Name == "gateway" {
+					req.Header.Set("X-Gateway", v.Value)
+				}
+				return next.RoundTrip(req)
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConfig("v1", "v1")
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the endpoint with the changed options is rebuilt
+	clear(builds)
+	c = newConfig("v1", "v2")
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if builds["endpoint"] != 1 || builds["gateway"] != 1 {
+		t.Fatalf("want the chain of /a rebuilt only but got %v", builds)
+	}
+
+	// the gateway middlewares of the fallback endpoint are swapped too
+	c = newConfig("v2", "v2")
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if len(received) != 1 || received[0] != "v2" {
+		t.Fatalf("want the fallback endpoint served by the swapped chain but got %v", received)
+	}
+}
@@ -0,0 +1,235 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/router"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// the time the requests of a swapped router are waited for before the router is closed
+const _routerDrainTimeout = 120 * time.Second
+
+// InflightEndpoint is the in-flight requests and streams of an endpoint handler.
+type InflightEndpoint struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Host   string `json:"host,omitempty"`
+	// Draining is true if the handler belongs to a router replaced by a reload and waiting to be closed.
+	Draining bool `json:"draining"`
+	Requests int  `json:"requests"`
+	Streams  int  `json:"streams"`
+	// OldestAge is the age of the oldest in-flight request or stream.
+	OldestAge string `json:"oldest_age"`
+	// StreamRemotes is the remote addresses of the open streams.
+	StreamRemotes []string `json:"stream_remotes,omitempty"`
+}
+
+type inflightRequest struct {
+	start  time.Time
+	stream bool
+	remote string
+}
+
+// inflightTracker tracks the in-flight requests of an endpoint handler.
+type inflightTracker struct {
+	method, path, host string
+
+	lock     sync.Mutex
+	nextID   uint64
+	requests map[uint64]*inflightRequest
+}
+
+func (t *inflightTracker) handler(e *config.Endpoint, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		stream := e.Stream || req.Header.Get("Upgrade") != ""
+		done := t.track(&inflightRequest{start: time.Now(), stream: stream, remote: req.RemoteAddr})
+		defer done()
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (t *inflightTracker) track(r *inflightRequest) func() {
+	t.lock.Lock()
+	t.nextID++
+	id := t.nextID
+	t.requests[id] = r
+	t.lock.Unlock()
+	return func() {
+		t.lock.Lock()
+		delete(t.requests, id)
+		t.lock.Unlock()
+	}
+}
+
+func (t *inflightTracker) export(now time.Time, draining bool) (*InflightEndpoint, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(t.requests) == 0 {
+		return nil, false
+	}
+	out := &InflightEndpoint{Method: t.method, Path: t.path, Host: t.host, Draining: draining}
+	var oldest time.Time
+	for _, r := range t.requests {
+		if r.stream {
+			out.Streams++
+			out.StreamRemotes = append(out.StreamRemotes, r.remote)
+		} else {
+			out.Requests++
+		}
+		if oldest.IsZero() || r.start.Before(oldest) {
+			oldest = r.start
+		}
+	}
+	sort.Strings(out.StreamRemotes)
+	out.OldestAge = now.Sub(oldest).String()
+	return out, true
+}
+
+// routeGeneration is the endpoint handlers of a router built by an update.
+type routeGeneration struct {
+	trackers []*inflightTracker
+	draining atomic.Bool
+}
+
+func (g *routeGeneration) tracker(e *config.Endpoint) *inflightTracker {
+	t := &inflightTracker{method: e.Method, path: e.Path, host: e.Host, requests: map[uint64]*inflightRequest{}}
+	g.trackers = append(g.trackers, t)
+	return t
+}
+
+func (g *routeGeneration) export(now time.Time) []*InflightEndpoint {
+	var out []*InflightEndpoint
+	for _, t := range g.trackers {
+		if e, ok := t.export(now, g.draining.Load()); ok {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// inflightRegistry holds the route generations until their routers are closed.
+type inflightRegistry struct {
+	lock        sync.Mutex
+	generations map[*routeGeneration]struct{}
+}
+
+func newInflightRegistry() *inflightRegistry {
+	return &inflightRegistry{generations: map[*routeGeneration]struct{}{}}
+}
+
+func (r *inflightRegistry) add(g *routeGeneration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.generations[g] = struct{}{}
+}
+
+func (r *inflightRegistry) remove(g *routeGeneration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.generations, g)
+}
+
+func (r *inflightRegistry) export() []*InflightEndpoint {
+	r.lock.Lock()
+	generations := make([]*routeGeneration, 0, len(r.generations))
+	for g := range r.generations {
+		generations = append(generations, g)
+	}
+	r.lock.Unlock()
+	now := time.Now()
+	var out []*InflightEndpoint
+	for _, g := range generations {
+		out = append(out, g.export(now)...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Draining != out[j].Draining {
+			return out[i].Draining
+		}
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return out[i].Method < out[j].Method
+	})
+	return out
+}
+
+func logInflight(reason string, endpoints []*InflightEndpoint) {
+	for _, e := range endpoints {
+		_errorLog.Warnw(
+			"source", "drain",
+			"reason", reason,
+			"method", e.Method,
+			"path", e.Path,
+			"host", e.Host,
+			"requests", e.Requests,
+			"streams", e.Streams,
+			"oldest_age", e.OldestAge,
+			"stream_remotes", e.StreamRemotes,
+		)
+	}
+}
+
+// Inflight returns the endpoints with in-flight requests or open streams, including the ones of the routers
+// replaced by a reload and not closed yet.
+func (p *Proxy) Inflight() []*InflightEndpoint {
+	return p.inflight.export()
+}
+
+// LogInflight logs the endpoints with in-flight requests or open streams, eg: when the graceful shutdown
+// times out, the requests logged are cut off.
+func (p *Proxy) LogInflight(reason string) {
+	endpoints := p.inflight.export()
+	if len(endpoints) == 0 {
+		return
+	}
+	log.Warnf("%d endpoints have in-flight requests on %s", len(endpoints), reason)
+	logInflight(reason, endpoints)
+}
+
+// closeRouter closes the router replaced by an update once its requests complete, the requests still in
+// flight when the drain timeout expires are logged before they are cut off.
+func (p *Proxy) closeRouter(in interface{}, generation *routeGeneration) {
+	if in == nil {
+		return
+	}
+	r, ok := in.(router.Router)
+	if !ok {
+		return
+	}
+	if generation != nil {
+		generation.draining.Store(true)
+	}
+	go func() {
+		if generation != nil {
+			defer p.inflight.remove(generation)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), _routerDrainTimeout)
+		defer cancel()
+		// the router is closed after the requests cut off by the timeout are logged
+		closeCtx, closeNow := context.WithCancel(context.Background())
+		go func() {
+			<-ctx.Done()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && generation != nil {
+				logInflight("router drain timeout", generation.export(time.Now()))
+			}
+			closeNow()
+		}()
+		r.SyncClose(closeCtx)
+	}()
+}
+
+func (p *Proxy) registerInflightDebugHandler(debugMux *http.ServeMux) {
+	debugMux.HandleFunc("/debug/proxy/draining", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(p.Inflight())
+	})
+}
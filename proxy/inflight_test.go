@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestInflight(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			close(started)
+			<-release
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{Protocol: config.Protocol_HTTP, Path: "/slow", Method: "GET"}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	}()
+	<-started
+
+	inflight := func() []*InflightEndpoint {
+		w := httptest.NewRecorder()
+		p.DebugHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/proxy/draining", nil))
+		var out []*InflightEndpoint
+		if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	if got := inflight(); len(got) != 1 || got[0].Path != "/slow" || got[0].Requests != 1 || got[0].Draining {
+		t.Fatalf("want the in-flight request listed but got %+v", got)
+	}
+
+	// the request of the replaced router is listed as draining
+	c = &config.Gateway{Endpoints: []*config.Endpoint{{Protocol: config.Protocol_HTTP, Path: "/other", Method: "GET"}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if got := inflight(); len(got) != 1 || !got[0].Draining {
+		t.Fatalf("want the request listed as draining but got %+v", got)
+	}
+	close(release)
+	<-done
+	for i := 0; i < 100 && len(inflight()) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := inflight(); len(got) != 0 {
+		t.Fatalf("want no in-flight request but got %+v", got)
+	}
+}
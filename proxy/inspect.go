@@ -0,0 +1,193 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/router/mux"
+)
+
+// BackendInspect is the inspect info of an endpoint backend.
+type BackendInspect struct {
+	Target      string `json:"target"`
+	Discovery   string `json:"discovery,omitempty"`
+	NodeFilters string `json:"node_filters,omitempty"`
+}
+
+// EndpointInspect is the inspect info of an endpoint with the live counters since the last reload.
+type EndpointInspect struct {
+	Path      string `json:"path"`
+	Method    string `json:"method,omitempty"`
+	Host      string `json:"host,omitempty"`
+	HostGroup string `json:"host_group,omitempty"`
+	// Service is the service the endpoint is expanded from, the other fields are the expanded values.
+	Service       string                    `json:"service,omitempty"`
+	Protocol      string                    `json:"protocol"`
+	Stream        bool                      `json:"stream"`
+	Maintenance   bool                      `json:"maintenance,omitempty"`
+	Timeout       string                    `json:"timeout"`
+	PerTryTimeout string                    `json:"per_try_timeout"`
+	TimeoutMode   string                    `json:"attempt_timeout_mode"`
+	Attempts      int                       `json:"attempts"`
+	Backends      []*BackendInspect         `json:"backends"`
+	NodeFilters   *client.NodeFilterInspect `json:"node_filters,omitempty"`
+	HostOverrides map[string][]string       `json:"host_overrides,omitempty"`
+	Middlewares   []string                  `json:"middlewares"`
+	// GRPCMethods is the methods of the gRPC service route, the configured overrides and the requested ones.
+	GRPCMethods []string   `json:"grpc_methods,omitempty"`
+	Requests    uint64     `json:"requests"`
+	LastMatch   *time.Time `json:"last_match,omitempty"`
+}
+
+// inspectHandler counts the matched requests of the endpoint handler.
+type inspectHandler struct {
+	http.Handler
+	inspect   EndpointInspect
+	requests  atomic.Uint64
+	lastMatch atomic.Int64
+	// nodeFilters reports the nodes currently matching the node filters
+	nodeFilters client.NodeFilterInspector
+	grpcMethods *grpcMethods
+}
+
+func newInspectHandler(gw *gatewayContext, e *config.Endpoint, handler http.Handler, closer io.Closer) *inspectHandler {
+	h := &inspectHandler{
+		Handler: handler,
+		inspect: EndpointInspect{
+			Path:        e.Path,
+			Method:      e.Method,
+			Host:        e.Host,
+			HostGroup:   e.HostGroup,
+			Service:     e.Service,
+			Protocol:    e.Protocol.String(),
+			Stream:      e.Stream,
+			Maintenance: e.Maintenance.GetEnabled(),
+		},
+	}
+	if strategy, err := prepareRetryStrategy(e); err == nil {
+		h.inspect.Timeout = strategy.timeout.String()
+		h.inspect.PerTryTimeout = strategy.perTryTimeout.String()
+		h.inspect.TimeoutMode = strategy.timeoutMode.String()
+		h.inspect.Attempts = strategy.attempts
+	}
+	for _, b := range e.Backends {
+		h.inspect.Backends = append(h.inspect.Backends, &BackendInspect{
+			Target:      b.Target,
+			Discovery:   discoveryName(b.Target),
+			NodeFilters: client.FormatNodeMatchers(b.NodeFilters.GetMatchers()),
+		})
+	}
+	if inspector, ok := closer.(client.NodeFilterInspector); ok {
+		h.nodeFilters = inspector
+	}
+	if inspector, ok := closer.(client.HostOverridesInspector); ok {
+		h.inspect.HostOverrides = inspector.HostOverrides()
+	}
+	for _, m := range gw.middlewares {
+		h.inspect.Middlewares = append(h.inspect.Middlewares, m.Name)
+	}
+	for _, m := range gw.hostGroups.middlewares(e) {
+		h.inspect.Middlewares = append(h.inspect.Middlewares, m.Name)
+	}
+	for _, m := range e.Middlewares {
+		h.inspect.Middlewares = append(h.inspect.Middlewares, m.Name)
+	}
+	for _, m := range e.GrpcMethods {
+		h.inspect.GRPCMethods = append(h.inspect.GRPCMethods, grpcServicePrefix(e)+m.Name)
+	}
+	return h
+}
+
+func discoveryName(target string) string {
+	if !strings.HasPrefix(target, "discovery://") {
+		return ""
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+func (h *inspectHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	h.requests.Add(1)
+	h.lastMatch.Store(time.Now().UnixNano())
+	h.Handler.ServeHTTP(w, req)
+}
+
+// Inspect implemented mux.Inspector.
+func (h *inspectHandler) Inspect() interface{} {
+	out := h.inspect
+	out.Requests = h.requests.Load()
+	if h.nodeFilters != nil {
+		out.NodeFilters = h.nodeFilters.NodeFilterInspect()
+	}
+	if requested := h.grpcMethods.methods(); len(requested) > 0 {
+		methods := slices.Clone(out.GRPCMethods)
+		for _, method := range requested {
+			if !slices.Contains(methods, method) {
+				methods = append(methods, method)
+			}
+		}
+		slices.Sort(methods)
+		out.GRPCMethods = methods
+	}
+	if last := h.lastMatch.Load(); last > 0 {
+		t := time.Unix(0, last)
+		out.LastMatch = &t
+	}
+	return &out
+}
+
+func filterRouterInspect(in []*mux.RouterInspect, prefix string) []*mux.RouterInspect {
+	if prefix == "" {
+		return in
+	}
+	out := make([]*mux.RouterInspect, 0, len(in))
+	for _, r := range in {
+		if strings.HasPrefix(r.PathTemplate, prefix) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func writeRouterInspectTable(w io.Writer, in []*mux.RouterInspect) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHOST\tGROUP\tSERVICE\tPROTOCOL\tSTREAM\tTIMEOUT\tPER_TRY\tATTEMPTS\tBACKENDS\tMIDDLEWARES\tREQUESTS\tLAST_MATCH")
+	for _, r := range in {
+		e, ok := r.Endpoint.(*EndpointInspect)
+		if !ok {
+			continue
+		}
+		backends := make([]string, 0, len(e.Backends))
+		for _, b := range e.Backends {
+			backends = append(backends, b.Target)
+		}
+		lastMatch := "-"
+		if e.LastMatch != nil {
+			lastMatch = e.LastMatch.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%t\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			orDash(e.Method), e.Path, orDash(e.Host), orDash(e.HostGroup), orDash(e.Service), e.Protocol, e.Stream, e.Timeout, e.PerTryTimeout, e.Attempts,
+			orDash(strings.Join(backends, ",")), orDash(strings.Join(e.Middlewares, ",")), strconv.FormatUint(e.Requests, 10), lastMatch)
+	}
+	return tw.Flush()
+}
+
+func orDash(in string) string {
+	if in == "" {
+		return "-"
+	}
+	return in
+}
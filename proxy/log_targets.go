@@ -0,0 +1,286 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+const (
+	_defaultLogTargetDuration = 5 * time.Minute
+	_maxLogTargetDuration     = time.Hour
+	_maxLogTargets            = 100
+)
+
+// LogTarget is the requests logged verbosely regardless of the log level until the target expires,
+// the requests must match all the fields set.
+type LogTarget struct {
+	ID string `json:"id"`
+	// Method and Path are the route of the requests, the method matches any if empty.
+	Method string `json:"method,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// RemoteIP is the IP address of the client connection.
+	RemoteIP string    `json:"remote_ip,omitempty"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+func (t *LogTarget) match(e *config.Endpoint, req *http.Request, now time.Time) bool {
+	if now.After(t.ExpireAt) {
+		return false
+	}
+	if t.Path != "" && (t.Path != e.Path || (t.Method != "" && t.Method != e.Method)) {
+		return false
+	}
+	if t.RemoteIP != "" {
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			host = req.RemoteAddr
+		}
+		if host != t.RemoteIP {
+			return false
+		}
+	}
+	return true
+}
+
+// logTargets is the active log targets, the requests are checked against them only if any.
+type logTargets struct {
+	lock    sync.Mutex
+	targets atomic.Pointer[[]*LogTarget]
+	timers  map[string]*time.Timer
+}
+
+func newLogTargets() *logTargets {
+	return &logTargets{timers: map[string]*time.Timer{}}
+}
+
+// match returns the target matching the request, nil if none.
+func (l *logTargets) match(e *config.Endpoint, req *http.Request) *LogTarget {
+	targets := l.targets.Load()
+	if targets == nil {
+		return nil
+	}
+	now := time.Now()
+	for _, t := range *targets {
+		if t.match(e, req, now) {
+			return t
+		}
+	}
+	return nil
+}
+
+func (l *logTargets) List() []*LogTarget {
+	if targets := l.targets.Load(); targets != nil {
+		return *targets
+	}
+	return []*LogTarget{}
+}
+
+func (l *logTargets) add(t *LogTarget, duration time.Duration) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	current := l.List()
+	if len(current) >= _maxLogTargets {
+		return fmt.Errorf("too many log targets, at most %d", _maxLogTargets)
+	}
+	t.ID = uuid.NewString()
+	t.ExpireAt = time.Now().Add(duration)
+	next := append(current[:len(current):len(current)], t)
+	l.targets.Store(&next)
+	l.timers[t.ID] = time.AfterFunc(duration, func() { l.remove(t.ID) })
+	log.Warnf("log target %s added: %s %s %s for %s", t.ID, t.Method, t.Path, t.RemoteIP, duration)
+	return nil
+}
+
+func (l *logTargets) remove(id string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	timer, ok := l.timers[id]
+	if !ok {
+		return false
+	}
+	timer.Stop()
+	delete(l.timers, id)
+	next := make([]*LogTarget, 0, len(l.timers))
+	for _, t := range l.List() {
+		if t.ID != id {
+			next = append(next, t)
+		}
+	}
+	if len(next) == 0 {
+		// the requests skip the targets by the nil check
+		l.targets.Store(nil)
+	} else {
+		l.targets.Store(&next)
+	}
+	log.Warnf("log target %s removed", id)
+	return true
+}
+
+// observer logs the requests of the endpoint matching the targets.
+func (l *logTargets) observer(e *config.Endpoint, next Observer) Observer {
+	return &logTargetObserver{Observer: next, targets: l, endpoint: e}
+}
+
+type logTargetObserver struct {
+	Observer
+	targets  *logTargets
+	endpoint *config.Endpoint
+}
+
+func (o *logTargetObserver) HandleRetry(req *http.Request, responseHeader http.Header, state string) {
+	o.Observer.HandleRetry(req, responseHeader, state)
+	if t := o.targets.match(o.endpoint, req); t != nil {
+		logs.Debug.Log(log.LevelDebug, logs.ForceKey, t.ID, "source", "log_target", "msg", "retry",
+			"method", req.Method, "path", req.URL.Path, "route", o.endpoint.Path, "success", state)
+	}
+}
+
+func (o *logTargetObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	o.Observer.HandleRequest(req, responseHeader, statusCode, err)
+	t := o.targets.match(o.endpoint, req)
+	if t == nil {
+		return
+	}
+	keyvals := []any{
+		logs.ForceKey, t.ID,
+		"source", "log_target",
+		"method", req.Method,
+		"host", req.Host,
+		"path", req.URL.Path,
+		"route", o.endpoint.Path,
+		"remote_addr", req.RemoteAddr,
+		"code", statusCode,
+		"request_header", redactHeader(req.Header),
+		"response_header", redactHeader(responseHeader),
+	}
+	if err != nil {
+		keyvals = append(keyvals, "error", err.Error())
+	}
+	if timings, ok := phaseTimingsFromContext(req.Context()); ok {
+		chain, upstream := time.Duration(timings.chain.Load()), time.Duration(timings.upstream.Load())
+		keyvals = append(keyvals,
+			"middleware", max(chain-upstream, 0).String(),
+			"upstream_ttfb", upstream.String(),
+			"body_copy", time.Duration(timings.copy.Load()).String(),
+		)
+	}
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok {
+		upstream := opts.Upstream()
+		// the values include the middleware replying the request instead of the upstream
+		keyvals = append(keyvals, "upstream", upstream.Addr, "attempts", upstream.Attempts, "values", middleware.ExportValues(opts))
+	}
+	logs.Debug.Log(log.LevelDebug, keyvals...)
+}
+
+type logTargetRequest struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	RemoteIP string `json:"remote_ip"`
+	Duration string `json:"duration"`
+}
+
+func (r *logTargetRequest) parse() (*LogTarget, time.Duration, error) {
+	if r.Path == "" && r.RemoteIP == "" {
+		return nil, 0, errors.New("path or remote_ip is required")
+	}
+	if r.RemoteIP != "" && net.ParseIP(r.RemoteIP) == nil {
+		return nil, 0, fmt.Errorf("invalid remote_ip %q", r.RemoteIP)
+	}
+	duration := _defaultLogTargetDuration
+	if r.Duration != "" {
+		var err error
+		if duration, err = time.ParseDuration(r.Duration); err != nil {
+			return nil, 0, err
+		}
+	}
+	if duration <= 0 || duration > _maxLogTargetDuration {
+		return nil, 0, fmt.Errorf("duration must be in (0, %s]", _maxLogTargetDuration)
+	}
+	return &LogTarget{Method: strings.ToUpper(r.Method), Path: r.Path, RemoteIP: r.RemoteIP}, duration, nil
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+func (p *Proxy) registerLogDebugHandler(debugMux *http.ServeMux) {
+	debugMux.HandleFunc("/debug/proxy/log-level", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(&logLevelRequest{Level: logs.GetLevel().String()})
+			return
+		}
+		in := &logLevelRequest{}
+		if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level := log.ParseLevel(in.Level)
+		if !strings.EqualFold(level.String(), in.Level) {
+			http.Error(rw, fmt.Sprintf("invalid level %q", in.Level), http.StatusBadRequest)
+			return
+		}
+		if err := audit.WriteRequest(req, &audit.Record{Action: "log.level", Before: logs.GetLevel().String(), After: level.String()}); err != nil {
+			log.Errorf("Failed to write audit record of log.level: %+v", err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		logs.SetLevel(level)
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(&logLevelRequest{Level: level.String()})
+	})
+	debugMux.HandleFunc("/debug/proxy/log-targets", func(rw http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodPost:
+			in := &logTargetRequest{}
+			if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			t, duration, err := in.parse()
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			target := strings.TrimSpace(t.Method + " " + t.Path + " " + t.RemoteIP)
+			if err := audit.WriteRequest(req, &audit.Record{Action: "log-target.add", Target: target, After: duration.String()}); err != nil {
+				log.Errorf("Failed to write audit record of log-target.add %s: %+v", target, err)
+				http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+				return
+			}
+			if err := p.logTargets.add(t, duration); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(t)
+		case http.MethodDelete:
+			id := req.URL.Query().Get("id")
+			if err := audit.WriteRequest(req, &audit.Record{Action: "log-target.remove", Target: id}); err != nil {
+				log.Errorf("Failed to write audit record of log-target.remove %s: %+v", id, err)
+				http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+				return
+			}
+			if !p.logTargets.remove(id) {
+				http.Error(rw, "log target not found", http.StatusNotFound)
+			}
+		default:
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(p.logTargets.List())
+		}
+	})
+}
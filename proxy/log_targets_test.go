@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+func TestLogTargets(t *testing.T) {
+	out := &bytes.Buffer{}
+	defaultLogger, defaultLevel := log.GetLogger(), logs.GetLevel()
+	log.SetLogger(logs.NewGlobalLogger(out, "text", log.LevelError))
+	defer func() {
+		log.SetLogger(defaultLogger)
+		logs.SetLevel(defaultLevel)
+	}()
+
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Upstream": {"a"}}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{
+		{Protocol: config.Protocol_HTTP, Path: "/api/users", Method: "GET"},
+		{Protocol: config.Protocol_HTTP, Path: "/api/orders", Method: "GET"},
+	}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	debug := p.DebugHandler()
+	admin := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		debug.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	if w := admin(http.MethodPost, "/debug/proxy/log-targets", `{"path":"/api/users","duration":"2h"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("want the duration bounded but got %d", w.Code)
+	}
+	w := admin(http.MethodPost, "/debug/proxy/log-targets", `{"method":"get","path":"/api/users","duration":"1m"}`)
+	target := &LogTarget{}
+	if err := json.NewDecoder(w.Body).Decode(target); err != nil || target.ID == "" {
+		t.Fatalf("want the target added but got %d %q", w.Code, w.Body.String())
+	}
+	for _, path := range []string{"/api/users", "/api/orders"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		p.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	got := out.String()
+	if !strings.Contains(got, target.ID) || !strings.Contains(got, "/api/users") || !strings.Contains(got, "X-Upstream") {
+		t.Fatalf("want the targeted request logged verbosely but got %q", got)
+	}
+	if strings.Contains(got, "/api/orders") || strings.Contains(got, "secret") {
+		t.Fatalf("want only the targeted request logged with the credentials redacted but got %q", got)
+	}
+
+	if w := admin(http.MethodDelete, "/debug/proxy/log-targets?id="+target.ID, ""); w.Code != http.StatusOK {
+		t.Fatalf("want the target removed but got %d", w.Code)
+	}
+	if p.logTargets.targets.Load() != nil {
+		t.Fatal("want the requests skipping the targets after the last one is removed")
+	}
+
+	if w := admin(http.MethodPost, "/debug/proxy/log-level", `{"level":"verbose"}`); w.Code != http.StatusBadRequest {
+		t.Fatalf("want the invalid level rejected but got %d", w.Code)
+	}
+	admin(http.MethodPost, "/debug/proxy/log-level", `{"level":"debug"}`)
+	if logs.GetLevel() != log.LevelDebug {
+		t.Fatalf("want the global level set but got %s", logs.GetLevel())
+	}
+}
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _metricMaintenanceRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "maintenance_requests_total",
+	Help:      "The total number of requests served in maintenance mode",
+}, []string{"protocol", "method", "path", "service", "basePath"})
+
+func init() {
+	goddessmetrics.Register(_metricMaintenanceRequests)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// maintenanceHandler replies 503 to all requests of the endpoint, the requests are not counted as upstream failures.
+func maintenanceHandler(e *config.Endpoint) http.Handler {
+	m := e.Maintenance
+	labels := middleware.NewMetricsLabels(e)
+	message := m.Message
+	if message == "" {
+		message = "service under maintenance"
+	}
+	body := m.Body
+	if body == "" {
+		body = message
+	}
+	contentType := m.ContentType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	retryAfter := ""
+	if m.RetryAfter != nil && m.RetryAfter.AsDuration() > 0 {
+		retryAfter = strconv.Itoa(int(math.Ceil(m.RetryAfter.AsDuration().Seconds())))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_metricMaintenanceRequests.WithLabelValues(labels.Protocol(), r.Method, labels.Path(), labels.Service(), labels.BasePath()).Inc()
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		if e.Protocol == config.Protocol_GRPC {
+			w.Header().Set("Content-Type", "application/grpc")
+			w.Header().Set("Grpc-Status", "14") // Unavailable
+			w.Header().Set("Grpc-Message", message)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(body))
+	})
+}
@@ -0,0 +1,163 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	// the method label of the requests of the methods not routed
+	_otherMethod = "_other"
+
+	_reasonMethodNotImplemented = "METHOD_NOT_IMPLEMENTED"
+	_reasonMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	_reasonBodyNotAllowed       = "BODY_NOT_ALLOWED"
+)
+
+// the methods of RFC 9110 and RFC 5789 routed by default
+var _defaultMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete,
+	http.MethodConnect, http.MethodOptions, http.MethodTrace, http.MethodPatch,
+}
+
+var _metricGetHeadBodies = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "get_head_bodies_total",
+	Help:      "The total number of the GET and HEAD requests with a body by the action of the method policy",
+}, []string{"method", "action"})
+
+func init() {
+	goddessmetrics.Register(_metricGetHeadBodies)
+}
+
+// methodPolicy checks the methods of the requests before they are routed, so the method labels of the metrics
+// are bounded by the methods routed.
+type methodPolicy struct {
+	// the methods routed
+	allowed map[string]struct{}
+	// the default or extra methods not routed, replied 405
+	disabled map[string]struct{}
+	// the Allow header of the 405 responses
+	allow    string
+	bodyMode config.MethodPolicy_BodyAction
+}
+
+// newMethodPolicy builds the policy of the gateway, the methods of the endpoints are routed as the extra methods
+// unless they are disabled.
+func newMethodPolicy(c *config.MethodPolicy, endpoints []*config.Endpoint) (*methodPolicy, error) {
+	p := &methodPolicy{
+		allowed:  make(map[string]struct{}, len(_defaultMethods)+len(c.GetExtraMethods())),
+		disabled: make(map[string]struct{}, len(c.GetDisabledMethods())),
+		bodyMode: c.GetGetHeadBody(),
+	}
+	for _, m := range _defaultMethods {
+		p.allowed[m] = struct{}{}
+	}
+	for _, m := range c.GetExtraMethods() {
+		if !httpguts.ValidHeaderFieldName(m) {
+			return nil, fmt.Errorf("method policy: invalid extra method: %q", m)
+		}
+		p.allowed[m] = struct{}{}
+	}
+	for _, m := range c.GetDisabledMethods() {
+		if _, ok := p.allowed[m]; !ok {
+			return nil, fmt.Errorf("method policy: disabled method %q is neither a default nor an extra method", m)
+		}
+		delete(p.allowed, m)
+		p.disabled[m] = struct{}{}
+	}
+	for _, e := range endpoints {
+		if e.Method == "" {
+			continue
+		}
+		if _, ok := p.disabled[e.Method]; ok {
+			return nil, fmt.Errorf("method policy: endpoint %s %s uses the disabled method", e.Method, e.Path)
+		}
+		p.allowed[e.Method] = struct{}{}
+	}
+	methods := make([]string, 0, len(p.allowed))
+	for m := range p.allowed {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	p.allow = strings.Join(methods, ", ")
+	return p, nil
+}
+
+// metricsMethod returns the method label of the request, _other if the method is not routed.
+func (p *methodPolicy) metricsMethod(req *http.Request) string {
+	if p == nil {
+		return req.Method
+	}
+	if _, ok := p.allowed[req.Method]; ok {
+		return req.Method
+	}
+	if _, ok := p.disabled[req.Method]; ok {
+		return req.Method
+	}
+	return _otherMethod
+}
+
+// check replies the requests of the methods not routed and applies the body action to the GET and HEAD
+// requests, it returns false if the request is replied.
+func (p *methodPolicy) check(w http.ResponseWriter, req *http.Request) bool {
+	if p == nil {
+		return true
+	}
+	if _, ok := p.allowed[req.Method]; !ok {
+		if _, ok := p.disabled[req.Method]; ok {
+			w.Header().Set("Allow", p.allow)
+			p.reject(w, req, http.StatusMethodNotAllowed, _reasonMethodNotAllowed, "method not allowed")
+			return false
+		}
+		p.reject(w, req, http.StatusNotImplemented, _reasonMethodNotImplemented, "method not implemented")
+		return false
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return true
+	}
+	if req.ContentLength == 0 && len(req.TransferEncoding) == 0 {
+		return true
+	}
+	switch p.bodyMode {
+	case config.MethodPolicy_STRIP:
+		_metricGetHeadBodies.WithLabelValues(req.Method, "strip").Inc()
+		req.Body = http.NoBody
+		req.ContentLength = 0
+		req.TransferEncoding = nil
+		req.Header.Del("Content-Length")
+		req.Header.Del("Transfer-Encoding")
+	case config.MethodPolicy_REJECT:
+		_metricGetHeadBodies.WithLabelValues(req.Method, "reject").Inc()
+		p.reject(w, req, http.StatusBadRequest, _reasonBodyNotAllowed, "body is not allowed for "+req.Method)
+		return false
+	default:
+		_metricGetHeadBodies.WithLabelValues(req.Method, "forward").Inc()
+	}
+	return true
+}
+
+func (p *methodPolicy) reject(w http.ResponseWriter, req *http.Request, code int, reason, message string) {
+	writeErrorResponse(w, code, reason, message)
+	method := p.metricsMethod(req)
+	_accessLog.WithContext(req.Context()).Errorw(
+		"source", "accesslog",
+		"host", req.Host,
+		"method", method,
+		"path", req.URL.Path,
+		"query", req.URL.RawQuery,
+		"user_agent", req.Header.Get("User-Agent"),
+		"code", code,
+		"error", message,
+	)
+	MetricRequestsTotal.WithLabelValues("HTTP", method, "/"+strconv.Itoa(code), strconv.Itoa(code), "", "").Inc()
+}
@@ -0,0 +1,143 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newMethodPolicyProxy(t *testing.T, policy *config.MethodPolicy) (*Proxy, *int64) {
+	t.Helper()
+	received := new(int64)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			*received = int64(len(body))
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{
+		MethodPolicy: policy,
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/*",
+		}},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	return p, received
+}
+
+func TestMethodPolicy(t *testing.T) {
+	p, _ := newMethodPolicyProxy(t, &config.MethodPolicy{
+		ExtraMethods:    []string{"PROPFIND"},
+		DisabledMethods: []string{http.MethodTrace},
+	})
+	do := func(method string) *http.Response {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(method, "/api/foo", nil))
+		return w.Result()
+	}
+	other := testutil.ToFloat64(MetricRequestsTotal.WithLabelValues("HTTP", _otherMethod, "/501", "501", "", ""))
+	for _, method := range []string{http.MethodGet, http.MethodPatch, "PROPFIND"} {
+		if resp := do(method); resp.StatusCode != http.StatusOK {
+			t.Fatalf("want %s routed but got %d", method, resp.StatusCode)
+		}
+	}
+	resp := do(http.MethodTrace)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("want 405 for the disabled method but got %d", resp.StatusCode)
+	}
+	if allow := resp.Header.Get("Allow"); strings.Contains(allow, http.MethodTrace) || !strings.Contains(allow, "PROPFIND") {
+		t.Fatalf("unexpected Allow header: %s", allow)
+	}
+	for _, method := range []string{"BREW", "X-RANDOM-1"} {
+		if resp := do(method); resp.StatusCode != http.StatusNotImplemented {
+			t.Fatalf("want 501 for %s but got %d", method, resp.StatusCode)
+		}
+	}
+	if n := testutil.ToFloat64(MetricRequestsTotal.WithLabelValues("HTTP", _otherMethod, "/501", "501", "", "")) - other; n != 2 {
+		t.Fatalf("want 2 requests counted under the _other method but got %v", n)
+	}
+}
+
+func TestMethodPolicyGetHeadBody(t *testing.T) {
+	do := func(p *Proxy, method string) int {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(method, "/api/foo", strings.NewReader("body")))
+		return w.Code
+	}
+	p, received := newMethodPolicyProxy(t, nil)
+	if code := do(p, http.MethodGet); code != http.StatusOK || *received != 4 {
+		t.Fatalf("want the body forwarded by default but got %d %d", code, *received)
+	}
+	p, received = newMethodPolicyProxy(t, &config.MethodPolicy{GetHeadBody: config.MethodPolicy_STRIP})
+	if code := do(p, http.MethodGet); code != http.StatusOK || *received != 0 {
+		t.Fatalf("want the body stripped but got %d %d", code, *received)
+	}
+	if code := do(p, http.MethodPost); code != http.StatusOK || *received != 4 {
+		t.Fatalf("want the body of POST kept but got %d %d", code, *received)
+	}
+	p, _ = newMethodPolicyProxy(t, &config.MethodPolicy{GetHeadBody: config.MethodPolicy_REJECT})
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		if code := do(p, method); code != http.StatusBadRequest {
+			t.Fatalf("want %s with a body rejected but got %d", method, code)
+		}
+	}
+}
+
+func TestMethodPolicyInvalid(t *testing.T) {
+	for _, c := range []*config.MethodPolicy{
+		{ExtraMethods: []string{"BAD METHOD"}},
+		{DisabledMethods: []string{"PROPFIND"}},
+	} {
+		if _, err := newMethodPolicy(c, nil); err == nil {
+			t.Fatalf("want the method policy rejected: %v", c)
+		}
+	}
+	endpoints := []*config.Endpoint{{Method: "PROPFIND", Path: "/dav/*"}}
+	if _, err := newMethodPolicy(&config.MethodPolicy{ExtraMethods: []string{"PROPFIND"}, DisabledMethods: []string{"PROPFIND"}}, endpoints); err == nil {
+		t.Fatal("want the endpoint of the disabled method rejected")
+	}
+}
+
+func TestMethodPolicyEndpointMethods(t *testing.T) {
+	p, err := New(func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}), nil
+	}, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{Protocol: config.Protocol_HTTP, Method: "PROPFIND", Path: "/dav/*"}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	// the method of the endpoint is routed without extra_methods
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("PROPFIND", "/dav/foo", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want the method of the endpoint routed but got %d", w.Code)
+	}
+	c.MethodPolicy = &config.MethodPolicy{ExtraMethods: []string{"PROPFIND"}, DisabledMethods: []string{"PROPFIND"}}
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want the update failed by the endpoint of the disabled method")
+	}
+}
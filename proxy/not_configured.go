@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNotConfigured is returned for the requests received before the first successful Update.
+var ErrNotConfigured = errors.New("gateway is not configured yet")
+
+// _notConfiguredRetryAfter is the Retry-After in seconds of the requests received before the first Update.
+const _notConfiguredRetryAfter = 1
+
+var _metricNotConfiguredRequests = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "not_configured_requests_total",
+	Help:      "The total number of requests rejected before the first config is applied",
+})
+
+func init() {
+	goddessmetrics.Register(_metricNotConfiguredRequests)
+}
+
+// configState tracks whether the first config is applied, the proxy replies 503 until then.
+type configState struct {
+	configured atomic.Bool
+	logged     atomic.Bool
+	once       sync.Once
+	ready      chan struct{}
+}
+
+func newConfigState() *configState {
+	return &configState{ready: make(chan struct{})}
+}
+
+func (s *configState) markConfigured() {
+	s.once.Do(func() {
+		s.configured.Store(true)
+		close(s.ready)
+	})
+}
+
+// Configured reports whether the first config is applied by Update, the readiness of the gateway follows it.
+func (p *Proxy) Configured() bool {
+	return p.state.configured.Load()
+}
+
+// WaitConfigured blocks until the first config is applied or ctx is done.
+func (p *Proxy) WaitConfigured(ctx context.Context) error {
+	select {
+	case <-p.state.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Proxy) serveNotConfigured(w http.ResponseWriter, req *http.Request) {
+	_metricNotConfiguredRequests.Inc()
+	if !p.state.logged.Swap(true) {
+		log.Warnw("source", "not_configured", "method", req.Method, "path", req.URL.Path,
+			"msg", "rejecting requests with 503 until the first config is applied")
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(_notConfiguredRetryAfter))
+	if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(http.StatusServiceUnavailable))))
+		w.Header().Set("Grpc-Message", ErrNotConfigured.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Error(w, ErrNotConfigured.Error(), http.StatusServiceUnavailable)
+}
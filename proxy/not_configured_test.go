@@ -0,0 +1,96 @@
+package proxy
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestNotConfigured(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/configured",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Configured() {
+		t.Fatal("want the proxy not configured before the first update")
+	}
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/configured", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Fatalf("want 503 with Retry-After but got %d %v", w.Code, w.Header())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.WaitConfigured(ctx); err == nil {
+		t.Fatal("want the wait timed out")
+	}
+
+	// the requests racing the first update are either rejected with 503 or served by the new router, never 404
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	codes := make(chan int, 1024)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/configured", nil))
+				select {
+				case codes <- w.Code:
+				default:
+				}
+			}
+		}()
+	}
+	waited := make(chan error, 1)
+	go func() { waited <- p.WaitConfigured(context.Background()) }()
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-waited; err != nil {
+		t.Fatalf("want the wait returned once configured but got %v", err)
+	}
+	close(stop)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK && code != http.StatusServiceUnavailable {
+			t.Fatalf("want 200 or 503 while racing the first update but got %d", code)
+		}
+	}
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/configured", nil))
+	if !p.Configured() || w.Code != http.StatusOK {
+		t.Fatalf("want the request served once configured but got %d", w.Code)
+	}
+}
@@ -1,52 +1,132 @@
 package proxy
 
 import (
+	"errors"
 	"net/http"
+	"runtime/debug"
 	"strconv"
-	"sync"
 	"time"
 
+	"github.com/aide-family/goddess/checkpoint"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+// the exported vectors are the metrics of the observables registered into prometheus.DefaultRegisterer.
 var (
-	MetricRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "go",
-		Subsystem: "gateway",
-		Name:      "requests_code_total",
-		Help:      "The total number of processed requests",
-	}, []string{"protocol", "method", "path", "code", "service", "basePath"})
-	MetricRequestsDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
-		Namespace: "go",
-		Subsystem: "gateway",
-		Name:      "requests_duration_seconds",
-		Help:      "Requests duration(sec).",
-		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
-	}, []string{"protocol", "method", "path", "service", "basePath"})
-	MetricSentBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "go",
-		Subsystem: "gateway",
-		Name:      "requests_tx_bytes",
-		Help:      "Total sent connection bytes",
-	}, []string{"protocol", "method", "path", "service", "basePath"})
-	MetricReceivedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "go",
-		Subsystem: "gateway",
-		Name:      "requests_rx_bytes",
-		Help:      "Total received connection bytes",
-	}, []string{"protocol", "method", "path", "service", "basePath"})
-	MetricRetryState = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Namespace: "go",
-		Subsystem: "gateway",
-		Name:      "requests_retry_state",
-		Help:      "Total request retries",
-	}, []string{"protocol", "method", "path", "service", "basePath", "success"})
-	// ensure the metric is registered only once
-	metricOnce sync.Once
+	_defaultMetrics = newMetrics()
+
+	MetricRequestsTotal       = _defaultMetrics.requestsTotal
+	MetricRequestsDuration    = _defaultMetrics.requestsDuration
+	MetricSentBytes           = _defaultMetrics.sentBytes
+	MetricReceivedBytes       = _defaultMetrics.receivedBytes
+	MetricRequestsErrorClass  = _defaultMetrics.requestsErrorClass
+	MetricMiddlewareResponses = _defaultMetrics.middlewareResponses
+	MetricMiddlewareSentBytes = _defaultMetrics.middlewareSentBytes
+	MetricRetryState          = _defaultMetrics.retryState
 )
 
+// metrics is the proxy core metrics of the observables sharing a registerer.
+type metrics struct {
+	requestsTotal       *prometheus.CounterVec
+	requestsDuration    *prometheus.HistogramVec
+	sentBytes           *prometheus.CounterVec
+	receivedBytes       *prometheus.CounterVec
+	requestsErrorClass  *prometheus.CounterVec
+	middlewareResponses *prometheus.CounterVec
+	middlewareSentBytes *prometheus.CounterVec
+	retryState          *prometheus.CounterVec
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_code_total",
+			Help:      "The total number of processed requests",
+		}, []string{"protocol", "method", "path", "code", "service", "basePath"}),
+		requestsDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_duration_seconds",
+			Help:      "Requests duration(sec).",
+			Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+		}, []string{"protocol", "method", "path", "service", "basePath"}),
+		sentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_tx_bytes",
+			Help:      "Total sent connection bytes",
+		}, []string{"protocol", "method", "path", "service", "basePath"}),
+		receivedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_rx_bytes",
+			Help:      "Total received connection bytes",
+		}, []string{"protocol", "method", "path", "service", "basePath"}),
+		requestsErrorClass: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_error_class_total",
+			Help:      "The total number of processed requests by the class of the error",
+		}, []string{"protocol", "method", "path", "service", "basePath", "error_class"}),
+		middlewareResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "middleware_responses_total",
+			Help:      "The total number of the requests replied by the middlewares instead of the upstream",
+		}, []string{"protocol", "method", "path", "middleware", "code"}),
+		middlewareSentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "middleware_responses_tx_bytes",
+			Help:      "Total sent bytes of the responses replied by the middlewares",
+		}, []string{"protocol", "method", "path", "middleware"}),
+		retryState: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "go",
+			Subsystem: "gateway",
+			Name:      "requests_retry_state",
+			Help:      "Total request retries",
+		}, []string{"protocol", "method", "path", "service", "basePath", "success"}),
+	}
+}
+
+// register registers the metrics into r, the metrics already registered into r are taken over
+// so the observables of the same registerer share the vectors.
+func (m *metrics) register(r prometheus.Registerer) *metrics {
+	return &metrics{
+		requestsTotal:       registerCollector(r, m.requestsTotal),
+		requestsDuration:    registerCollector(r, m.requestsDuration),
+		sentBytes:           registerCollector(r, m.sentBytes),
+		receivedBytes:       registerCollector(r, m.receivedBytes),
+		requestsErrorClass:  registerCollector(r, m.requestsErrorClass),
+		middlewareResponses: registerCollector(r, m.middlewareResponses),
+		middlewareSentBytes: registerCollector(r, m.middlewareSentBytes),
+		retryState:          registerCollector(r, m.retryState),
+	}
+}
+
+func registerCollector[T prometheus.Collector](r prometheus.Registerer, c T) T {
+	if err := r.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			panic(err)
+		}
+		if existing, ok := are.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	return c
+}
+
+func init() {
+	checkpoint.Register("go_gateway_requests_code_total", MetricRequestsTotal)
+	checkpoint.Register("go_gateway_requests_retry_state", MetricRetryState)
+}
+
 // Observable is the interface for observable proxy metrics.
 type Observable interface {
 	Observe(*config.Endpoint) Observer
@@ -61,44 +141,150 @@ type Observer interface {
 	HandleLatency(req *http.Request, latency time.Duration)
 }
 
-// NewObservable creates a new Observable instance and registers the metrics.
-func NewObservable() Observable {
-	metricOnce.Do(func() {
-		prometheus.MustRegister(MetricRequestsTotal)
-		prometheus.MustRegister(MetricRequestsDuration)
-		prometheus.MustRegister(MetricRetryState)
-		prometheus.MustRegister(MetricSentBytes)
-		prometheus.MustRegister(MetricReceivedBytes)
-	})
-	return &observable{}
+// ObservableOption is observable option.
+type ObservableOption func(*observableOptions)
+
+type observableOptions struct {
+	registerer prometheus.Registerer
 }
 
-type observable struct{}
+// WithRegisterer sets the registerer of the gateway metrics, default is prometheus.DefaultRegisterer.
+//
+// The observables of a registerer other than prometheus.DefaultRegisterer count into their own vectors instead of
+// the exported Metric* ones, so the proxies of different registerers are observed separately.
+// The metrics of the other features, eg: admission, method policy, stream limits, collapse, quota, watchdog, the
+// config reloads and the servers, are registered into the registerer as well, they are shared by the registerers.
+// Nothing is registered into prometheus.DefaultRegisterer on init, the /metrics of the router serves it.
+// The not found, method not allowed and method policy rejections are counted into MetricRequestsTotal directly
+// instead of through the observable, so they are not seen by the observables of WithObservable.
+func WithRegisterer(r prometheus.Registerer) ObservableOption {
+	return func(o *observableOptions) {
+		o.registerer = r
+	}
+}
+
+// NewObservable creates a new Observable instance and registers the metrics, the observables of the same
+// registerer share the metrics.
+func NewObservable(opts ...ObservableOption) Observable {
+	o := &observableOptions{registerer: prometheus.DefaultRegisterer}
+	for _, opt := range opts {
+		opt(o)
+	}
+	m := _defaultMetrics
+	if o.registerer != prometheus.DefaultRegisterer {
+		m = newMetrics()
+	}
+	m = m.register(o.registerer)
+	// the metrics of the features are exposed with the proxy metrics
+	if err := goddessmetrics.RegisterInto(o.registerer); err != nil {
+		panic(err)
+	}
+	return &observable{metrics: m}
+}
+
+type observable struct {
+	metrics *metrics
+}
 
 func (o *observable) Observe(endpoint *config.Endpoint) Observer {
-	return &observer{labels: middleware.NewMetricsLabels(endpoint)}
+	return &observer{metrics: o.metrics, labels: middleware.NewMetricsLabels(endpoint)}
 }
 
 type observer struct {
-	labels middleware.MetricsLabels
+	metrics *metrics
+	labels  middleware.MetricsLabels
 }
 
 func (o *observer) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
-	MetricRequestsTotal.WithLabelValues(o.labels.Protocol(), req.Method, o.labels.Path(), strconv.Itoa(statusCode), o.labels.Service(), o.labels.BasePath()).Inc()
+	o.metrics.requestsTotal.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), strconv.Itoa(statusCode), o.labels.Service(), o.labels.BasePath()).Inc()
+	class := requestErrorClass(req, statusCode, err)
+	o.metrics.requestsErrorClass.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath(), string(class)).Inc()
+	if source, ok := middleware.ResponseSource.FromContext(req.Context()); ok && source != "" {
+		o.metrics.middlewareResponses.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), source, strconv.Itoa(statusCode)).Inc()
+	}
 }
 
 func (o *observer) HandleRetry(req *http.Request, responseHeader http.Header, state string) {
-	MetricRetryState.WithLabelValues(o.labels.Protocol(), req.Method, o.labels.Path(), o.labels.Service(), o.labels.BasePath(), state).Inc()
+	o.metrics.retryState.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath(), state).Inc()
 }
 
 func (o *observer) HandleLatency(req *http.Request, latency time.Duration) {
-	MetricRequestsDuration.WithLabelValues(o.labels.Protocol(), req.Method, o.labels.Path(), o.labels.Service(), o.labels.BasePath()).Observe(latency.Seconds())
+	o.metrics.requestsDuration.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath()).Observe(latency.Seconds())
 }
 
 func (o *observer) HandleSentBytes(req *http.Request, bytes int64) {
-	MetricSentBytes.WithLabelValues(o.labels.Protocol(), req.Method, o.labels.Path(), o.labels.Service(), o.labels.BasePath()).Add(float64(bytes))
+	// the bytes of the middleware responses are accounted to the middleware only, not to the upstream
+	if source, ok := middleware.ResponseSource.FromContext(req.Context()); ok && source != "" {
+		o.metrics.middlewareSentBytes.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), source).Add(float64(bytes))
+		return
+	}
+	o.metrics.sentBytes.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath()).Add(float64(bytes))
 }
 
 func (o *observer) HandleReceivedBytes(req *http.Request, bytes int64) {
-	MetricReceivedBytes.WithLabelValues(o.labels.Protocol(), req.Method, o.labels.Path(), o.labels.Service(), o.labels.BasePath()).Add(float64(bytes))
+	o.metrics.receivedBytes.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath()).Add(float64(bytes))
+}
+
+// MultiObservable returns the Observable fanning every call out to the observables in order,
+// a panic of one of them is recovered and logged so the others and the request are not affected.
+func MultiObservable(observables ...Observable) Observable {
+	if len(observables) == 1 {
+		return observables[0]
+	}
+	return multiObservable(observables)
+}
+
+type multiObservable []Observable
+
+func (m multiObservable) Observe(endpoint *config.Endpoint) Observer {
+	observers := make(multiObserver, 0, len(m))
+	for _, o := range m {
+		var observer Observer
+		recoverObserver(func() { observer = o.Observe(endpoint) })
+		if observer != nil {
+			observers = append(observers, observer)
+		}
+	}
+	return observers
+}
+
+type multiObserver []Observer
+
+func (m multiObserver) HandleRetry(req *http.Request, responseHeader http.Header, state string) {
+	for _, o := range m {
+		recoverObserver(func() { o.HandleRetry(req, responseHeader, state) })
+	}
+}
+
+func (m multiObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	for _, o := range m {
+		recoverObserver(func() { o.HandleRequest(req, responseHeader, statusCode, err) })
+	}
+}
+
+func (m multiObserver) HandleSentBytes(req *http.Request, bytes int64) {
+	for _, o := range m {
+		recoverObserver(func() { o.HandleSentBytes(req, bytes) })
+	}
+}
+
+func (m multiObserver) HandleReceivedBytes(req *http.Request, bytes int64) {
+	for _, o := range m {
+		recoverObserver(func() { o.HandleReceivedBytes(req, bytes) })
+	}
+}
+
+func (m multiObserver) HandleLatency(req *http.Request, latency time.Duration) {
+	for _, o := range m {
+		recoverObserver(func() { o.HandleLatency(req, latency) })
+	}
+}
+
+func recoverObserver(fn func()) {
+	defer func() {
+		if err := recover(); err != nil {
+			_errorLog.Errorf("Observer panic recovered: %v\n%s", err, debug.Stack())
+		}
+	}()
+	fn()
 }
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+type countObservable struct {
+	requests int
+	panics   bool
+}
+
+func (c *countObservable) Observe(*config.Endpoint) Observer { return c }
+
+func (c *countObservable) HandleRetry(*http.Request, http.Header, string) {}
+func (c *countObservable) HandleRequest(*http.Request, http.Header, int, error) {
+	if c.panics {
+		panic("buggy observer")
+	}
+	c.requests++
+}
+func (c *countObservable) HandleSentBytes(*http.Request, int64)       {}
+func (c *countObservable) HandleReceivedBytes(*http.Request, int64)   {}
+func (c *countObservable) HandleLatency(*http.Request, time.Duration) {}
+
+func TestMultiObservable(t *testing.T) {
+	first, buggy, last := &countObservable{}, &countObservable{panics: true}, &countObservable{}
+	p, err := New(nil, nil, WithObservable(first), WithObservable(buggy), WithObservable(last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := p.observable.Observe(&config.Endpoint{})
+	observer.HandleRequest(httptest.NewRequest(http.MethodGet, "/", nil), nil, http.StatusOK, nil)
+	if first.requests != 1 || last.requests != 1 {
+		t.Fatalf("want every observable observed but got %d and %d", first.requests, last.requests)
+	}
+
+	single := &countObservable{}
+	if MultiObservable(single) != Observable(single) {
+		t.Fatal("want the single observable unwrapped")
+	}
+}
+
+func benchmarkObserver(b *testing.B, o Observable) {
+	observer := o.Observe(&config.Endpoint{})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		observer.HandleRequest(req, nil, http.StatusOK, nil)
+		observer.HandleLatency(req, time.Millisecond)
+	}
+}
+
+func BenchmarkObserver(b *testing.B) {
+	benchmarkObserver(b, &countObservable{})
+}
+
+func BenchmarkMultiObservableSingle(b *testing.B) {
+	benchmarkObserver(b, MultiObservable(&countObservable{}))
+}
+
+func BenchmarkMultiObservable(b *testing.B) {
+	benchmarkObserver(b, MultiObservable(&countObservable{}, &countObservable{}))
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const _defaultPrewarmTimeout = 3 * time.Second
+
+var _metricPrewarmConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "prewarm_connections_total",
+	Help:      "The total number of upstream connections prewarmed after reload",
+}, []string{"service", "result"})
+
+func init() {
+	goddessmetrics.Register(_metricPrewarmConnections)
+}
+
+type prewarmTarget struct {
+	endpoint  *config.Endpoint
+	prewarmer client.Prewarmer
+}
+
+// endpointKey identifies the route of the endpoint.
+func endpointKey(e *config.Endpoint) string {
+	return fmt.Sprintf("%s %s %s %s", e.Protocol, e.Method, e.Host, e.Path)
+}
+
+func endpointDigest(e *config.Endpoint) string {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(e)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func prewarmService(e *config.Endpoint) string {
+	if service := e.Metadata["service"]; service != "" {
+		return service
+	}
+	if len(e.Backends) > 0 {
+		if name := discoveryName(e.Backends[0].Target); name != "" {
+			return name
+		}
+		return e.Backends[0].Target
+	}
+	return ""
+}
+
+// prewarmer collects the changed endpoints to prewarm during one update.
+type prewarmer struct {
+	config  *config.Prewarm
+	digests map[string]string
+	last    map[string]string
+	targets []*prewarmTarget
+}
+
+func (p *Proxy) newPrewarmer(c *config.Gateway) *prewarmer {
+	p.prewarmLock.Lock()
+	last := p.endpointDigests
+	p.prewarmLock.Unlock()
+	return &prewarmer{
+		config:  c.Prewarm,
+		digests: make(map[string]string, len(c.Endpoints)),
+		last:    last,
+	}
+}
+
+// Add records the endpoint, it is prewarmed only if enabled and changed since the last update,
+// since the upstream transports are shared across updates.
+func (w *prewarmer) Add(e *config.Endpoint, closer io.Closer) {
+	key, digest := endpointKey(e), endpointDigest(e)
+	w.digests[key] = digest
+	if !e.Prewarm && !w.config.GetAllEndpoints() {
+		return
+	}
+	if digest != "" && w.last[key] == digest {
+		return
+	}
+	target, ok := closer.(client.Prewarmer)
+	if !ok {
+		return
+	}
+	w.targets = append(w.targets, &prewarmTarget{endpoint: e, prewarmer: target})
+}
+
+// Run prewarms the endpoints concurrently and waits for them at most the timeout.
+func (w *prewarmer) Run() {
+	if len(w.targets) == 0 {
+		return
+	}
+	timeout := _defaultPrewarmTimeout
+	if w.config.GetTimeout() != nil {
+		timeout = w.config.GetTimeout().AsDuration()
+	}
+	conns := int(w.config.GetConnections())
+	if conns <= 0 {
+		conns = 1
+	}
+	budget := client.NewPrewarmBudget(int(w.config.GetBudget()))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	startAt := time.Now()
+	wg := sync.WaitGroup{}
+	for _, target := range w.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service := prewarmService(target.endpoint)
+			for _, result := range target.prewarmer.Prewarm(ctx, conns, budget) {
+				if result.Err != nil {
+					log.Warnf("Failed to prewarm %s for endpoint [%s] %s: %+v", result.Address, target.endpoint.Protocol, target.endpoint.Path, result.Err)
+					_metricPrewarmConnections.WithLabelValues(service, "failed").Inc()
+					continue
+				}
+				_metricPrewarmConnections.WithLabelValues(service, "success").Inc()
+			}
+		}()
+	}
+	wg.Wait()
+	log.Infof("prewarmed %d endpoints in %s", len(w.targets), time.Since(startAt))
+}
+
+// commitPrewarm records the endpoint digests after the router is swapped in.
+func (p *Proxy) commitPrewarm(w *prewarmer) {
+	p.prewarmLock.Lock()
+	p.endpointDigests = w.digests
+	p.prewarmLock.Unlock()
+}
@@ -13,10 +13,12 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/features"
 	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/aide-family/goddess/router"
@@ -25,15 +27,18 @@ import (
 	"github.com/go-kratos/aegis/circuitbreaker/sre"
 	"github.com/go-kratos/kratos/v2/log"
 	"github.com/go-kratos/kratos/v2/selector"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 // Option is proxy option.
 type Option func(*Proxy)
 
-// WithObservable set observable option.
+// WithObservable set observable option, the observables of more than one option are all observed.
 func WithObservable(o Observable) Option {
 	return func(p *Proxy) {
-		p.observable = o
+		p.observables = append(p.observables, o)
 	}
 }
 
@@ -66,10 +71,34 @@ type Proxy struct {
 	router                       atomic.Value
 	clientFactory                client.Factory
 	middlewareFactory            middleware.FactoryV2
+	shareable                    func(name string) bool
 	observable                   Observable
+	observables                  []Observable
 	notFoundHandler              http.Handler
 	methodNotAllowedHandler      http.Handler
 	prepareAttemptTimeoutContext AttemptTimeoutContext
+
+	prewarmLock     sync.Mutex
+	endpointDigests map[string]string
+	drains          *DrainManager
+	buffers         *bufferBudget
+	slowRequests    *slowRequestRecorder
+	captures        *captureStore
+	state           *configState
+	services        atomic.Value
+	rollouts        atomic.Value
+	inflight        *inflightRegistry
+	headerStats     *headerStatsRegistry
+	streamLimits    *streamLimitsRegistry
+	slos            *sloRegistry
+	logTargets      *logTargets
+	generation      atomic.Pointer[routeGeneration]
+	methods         atomic.Pointer[methodPolicy]
+	routesSummary   atomic.Pointer[routesSummary]
+
+	appliedLock sync.Mutex
+	applied     *appliedConfig
+	rollback    *rollbackGuard
 }
 
 // New is new a gateway proxy.
@@ -77,61 +106,226 @@ func New(clientFactory client.Factory, middlewareFactory middleware.FactoryV2, o
 	p := &Proxy{
 		clientFactory:                clientFactory,
 		middlewareFactory:            middlewareFactory,
+		shareable:                    middleware.IsShareable,
 		prepareAttemptTimeoutContext: defaultAttemptTimeoutContext,
 		notFoundHandler:              http.HandlerFunc(notFoundHandler),
 		methodNotAllowedHandler:      http.HandlerFunc(methodNotAllowedHandler),
+		drains:                       newDrainManager(),
+		buffers:                      newBufferBudget(),
+		slowRequests:                 newSlowRequestRecorder(),
+		captures:                     newCaptureStore(),
+		state:                        newConfigState(),
+		inflight:                     newInflightRegistry(),
+		headerStats:                  newHeaderStatsRegistry(),
+		streamLimits:                 newStreamLimitsRegistry(),
+		slos:                         newSLORegistry(),
+		logTargets:                   newLogTargets(),
 	}
 	for _, opt := range opts {
 		opt(p)
 	}
 	// if no observer is provided, create a default one and register metrics
-	if p.observable == nil {
+	if len(p.observables) == 0 {
 		p.observable = NewObservable()
+	} else {
+		p.observable = MultiObservable(p.observables...)
 	}
 	p.router.Store(mux.NewRouter(p.notFoundHandler, p.methodNotAllowedHandler))
 	return p, nil
 }
 
-func (p *Proxy) buildMiddleware(ms []*config.Middleware, next http.RoundTripper) (http.RoundTripper, error) {
+func (p *Proxy) buildMiddleware(cache *chainCache, ms []*config.Middleware, next http.RoundTripper, hooks *attemptHooks) (http.RoundTripper, error) {
+	instances, err := cache.create(ms, p.middlewareFactory)
+	if err != nil {
+		return nil, err
+	}
+	// the hooks run in the same order as the middlewares
+	built := make(attemptHooks, 0, len(ms))
 	for i := len(ms) - 1; i >= 0; i-- {
-		m, err := p.middlewareFactory(ms[i])
-		if err != nil {
-			if errors.Is(err, middleware.ErrNotFound) {
-				log.Errorf("Skip does not exist middleware: %s", ms[i].Name)
-				continue
-			}
-			return nil, err
+		m := instances[i]
+		if m == nil {
+			continue
+		}
+		if hook, ok := m.(middleware.AttemptHook); ok {
+			built = append(attemptHooks{hook}, built...)
 		}
 		next = m.Process(next)
 	}
+	*hooks = append(built, *hooks...)
 	return next, nil
 }
 
-func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint, ms []*config.Middleware) (_ http.Handler, _ io.Closer, retError error) {
-	client, err := p.clientFactory(buildCtx, e)
+// gatewayContext holds the gateway level settings shared by all endpoints of one update.
+type gatewayContext struct {
+	middlewares         []*config.Middleware
+	forwarded           *forwardedHeaders
+	responseHeaderAllow []string
+	responseHeaderDeny  []string
+	hostGroups          *hostGroups
+	deadlinePropagation *config.DeadlinePropagation
+	headerLimits        *config.HeaderLimits
+	clientCert          *config.ClientCert
+	securityHeaders     *config.SecurityHeaders
+	methods             *methodPolicy
+	// the middleware instances shared by the endpoints of the update, set by the proxy
+	chains *chainCache
+	// the chains of the endpoints built out of the routes, eg: the fallback endpoints and the rollout baselines
+	extraChains []*extraChain
+	// the methods labeled by the gRPC service routes, they are reset on every update
+	grpcMethods *grpcMethodRegistry
+}
+
+func newGatewayContext(c *config.Gateway) (*gatewayContext, error) {
+	forwarded, err := newForwardedHeaders(c.ForwardedHeaders)
+	if err != nil {
+		return nil, err
+	}
+	hostGroups, err := newHostGroups(c.HostGroups)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := newMethodPolicy(c.MethodPolicy, c.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+	return &gatewayContext{
+		middlewares:         c.Middlewares,
+		forwarded:           forwarded,
+		responseHeaderAllow: c.ResponseHeaderAllow,
+		responseHeaderDeny:  c.ResponseHeaderDeny,
+		hostGroups:          hostGroups,
+		deadlinePropagation: c.DeadlinePropagation,
+		headerLimits:        c.HeaderLimits,
+		clientCert:          c.ClientCert,
+		securityHeaders:     c.SecurityHeaders,
+		methods:             methods,
+		grpcMethods:         newGRPCMethodRegistry(),
+	}, nil
+}
+
+func (p *Proxy) buildEndpoint(ctx context.Context, buildCtx *client.BuildContext, gw *gatewayContext, e *config.Endpoint) (http.Handler, io.Closer, error) {
+	handler, closer, chain, err := p.buildEndpointChain(ctx, buildCtx, gw, e)
 	if err != nil {
 		return nil, nil, err
 	}
-	tripper := http.RoundTripper(client)
+	if chain != nil {
+		gw.extraChains = append(gw.extraChains, &extraChain{endpoint: proto.Clone(e).(*config.Endpoint), chain: chain})
+	}
+	return handler, closer, nil
+}
+
+// buildEndpointChain builds the endpoint handler and returns its middleware chain, the chain is nil for the
+// endpoints in maintenance. The build is traced by a span of the endpoint with the child spans of the client
+// and the middlewares.
+func (p *Proxy) buildEndpointChain(ctx context.Context, buildCtx *client.BuildContext, gw *gatewayContext, e *config.Endpoint) (_ http.Handler, _ io.Closer, _ *middlewareChain, retError error) {
+	ctx, span := _tracer.Start(ctx, "build endpoint", trace.WithAttributes(
+		attribute.String("endpoint.protocol", e.Protocol.String()),
+		attribute.String("endpoint.method", e.Method),
+		attribute.String("endpoint.path", e.Path),
+		attribute.String("endpoint.host", e.Host),
+		attribute.Int("endpoint.backends", len(e.Backends)),
+		attribute.Int("endpoint.middlewares", len(e.Middlewares)),
+	))
+	defer func() { endSpan(span, retError) }()
+	if e.Maintenance.GetEnabled() {
+		span.SetAttributes(attribute.Bool("endpoint.maintenance", true))
+		return maintenanceHandler(e), nopCloser{}, nil, nil
+	}
+	_, clientSpan := _tracer.Start(ctx, "build client")
+	var client client.Client
+	var err error
+	if e.Static != nil {
+		client, err = newStaticClient(e)
+	} else {
+		client, err = p.clientFactory(buildCtx, e)
+	}
+	endSpan(clientSpan, err)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tripper := timedTripper(client)
 	closer := io.Closer(client)
 	defer closeOnError(closer, &retError)
 
+	streamLimits, err := p.newStreamLimits(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	if e.Stream {
-		tripper = builtinStreamTripper(tripper)
+		tripper = builtinStreamTripper(streamLimits.tripper(tripper))
 	}
-	tripper, err = p.buildMiddleware(e.Middlewares, tripper)
+	chain := &middlewareChain{base: tripper}
+	_, chainSpan := _tracer.Start(ctx, "build middlewares")
+	built, err := p.buildChain(gw, e, tripper)
+	endSpan(chainSpan, err)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	tripper, err = p.buildMiddleware(ms, tripper)
+	chain.current.Store(built)
+	retryStrategy, err := prepareRetryStrategy(e)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
-	retryStrategy, err := prepareRetryStrategy(e)
+	admission, err := newAdmission(e)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
+	deadline, err := newDeadlinePropagation(gw, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	contentTypes, err := newContentTypePolicy(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerLimits, err := newHeaderLimits(gw, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerStats := p.headerStats.get(e)
+	grpcMethods := gw.grpcMethods.get(e)
+	clientCert, err := newClientCertPolicy(gw, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	upstreamBody, err := newUpstreamBodyCapture(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	limiter, err := newAdaptiveLimiter(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	websocket, err := newWebSocketPolicy(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	drainFilter := p.drains.nodeFilter(e)
+	flush := newFlushPolicy(e)
+	security, err := newSecurityHeaders(gw, e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	debugHeaders, err := newUpstreamDebugHeaders(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	serverTiming, err := newServerTiming(e)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerPolicy := newResponseHeaderPolicy(gw, e)
 	observer := p.observable.Observe(e)
+	rejectObserver := observer
+	if limiter != nil {
+		observer = limiter.observer(observer)
+	}
+	observer = p.slowRequests.observer(observer)
+	observer = p.logTargets.observer(e, observer)
+	observer = p.rollback.observer(observer)
+	if observer, err = p.slos.observer(e, observer); err != nil {
+		return nil, nil, nil, err
+	}
 	markSuccessStat, markFailedStat, markBreakerStat := splitRetryMetricsHandler(observer)
 	retryBreaker := sre.NewBreaker(sre.WithSuccess(0.8), sre.WithRequest(10))
 	markSuccess := func(w http.ResponseWriter, req *http.Request, i int) {
@@ -151,62 +345,152 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 	}
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		startTime := time.Now()
-		setXFFHeader(req)
+		req = grpcMethods.withGRPCMethod(req)
+		identity := clientCertIdentity(req)
+		if reason := clientCert.check(req, identity); reason != "" {
+			writeClientCertError(w, req, e, reason, observer)
+			return
+		}
+		if reason := contentTypes.check(req); reason != "" {
+			writeContentTypeError(w, req, e, reason, observer)
+			return
+		}
+		if reason := checkRequestHeaders(req, headerLimits, headerStats); reason != "" {
+			writeHeaderLimitsError(w, req, e, reason, observer)
+			return
+		}
+		if admission != nil {
+			release, err := admission.Acquire(req.Context(), req)
+			if err != nil {
+				writeAdmissionError(w, req, e, err, observer)
+				return
+			}
+			defer release()
+		}
+		if limiter != nil {
+			release, ok := limiter.acquire()
+			if !ok {
+				writeAdaptiveConcurrencyError(w, req, e, limiter.retryAfter, rejectObserver)
+				return
+			}
+			defer release()
+		}
+		sanitizeRequestHeaders(req.Header, e.Stream)
+		gw.forwarded.Apply(req)
 
 		reqOpts := middleware.NewRequestOptions(e)
-		ctx := middleware.NewRequestContext(req.Context(), reqOpts)
-		ctx, cancel := context.WithTimeout(ctx, retryStrategy.timeout)
+		reqOpts.Filters = append(reqOpts.Filters, drainFilter)
+		if id := req.Header.Get("X-Request-Id"); id != "" {
+			middleware.RequestID.Set(reqOpts, id)
+		}
+		if identity != nil {
+			middleware.ClientCert.Set(reqOpts, identity)
+		}
+		debug := debugHeaders.trusted(req)
+		timings := &phaseTimings{}
+		ctx, trace := serverTiming.start(req)
+		debugHeaders.strip(req)
+		ctx = middleware.NewRequestContext(withPhaseTimings(ctx, timings), reqOpts)
+		// the observer is able to read the request options from the request context
+		req = req.WithContext(ctx)
+		timeout, timeoutSource := deadline.timeout(req, retryStrategy.timeout)
+		ctx, cancel := context.WithTimeoutCause(ctx, timeout, middleware.NewTimeoutError(timeoutSource))
 		defer cancel()
+		// the chain is loaded once so the request is served by one chain even if it is swapped meanwhile
+		current := chain.load()
 		defer func() {
 			observer.HandleLatency(req, time.Since(startTime))
 		}()
 
 		proxyStream := func() {
+			chainStart := time.Now()
 			reqOpts.LastAttempt = true
 			streamCtx := &middleware.MetaStreamContext{}
 			defer streamCtx.DoOnFinish()
 			middleware.InitMetaStreamContext(reqOpts, streamCtx)
 			wrapStreamRequestBody(req, streamCtx)
 			defer req.Body.Close()
+			deadline.apply(reqOpts)
 			reverseProxy := &httputil.ReverseProxy{
 				Rewrite: func(proxyRequest *httputil.ProxyRequest) {},
 				ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+					err = middleware.WithTimeoutSource(ctx, err)
 					reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
 					markFailed(w, req, 0, err)
+					debugHeaders.Write(debug, reqOpts, w.Header())
+					security.Write(req, w.Header())
 					writeError(w, req, e, err, observer)
 				},
 				ModifyResponse: func(resp *http.Response) error {
 					defer streamCtx.DoOnResponse()
+					timings.chain.Store(int64(time.Since(chainStart)))
+					observeGRPCMethod(req, resp)
 					reqOpts.DoneFunc(ctx, selector.DoneInfo{ReplyMD: getReplyMD(e, resp)})
+					// hop-by-hop headers are removed by the reverse proxy
+					headerPolicy.Filter(resp.Header)
+					headerStats.observeResponse(resp.Header)
+					// the stream bodies are not recorded
+					timings.upstreamBody = upstreamBody.marker(resp)
+					logUpstreamBody(req, e, timings.upstreamBody)
+					debugHeaders.Write(debug, reqOpts, resp.Header)
+					security.Write(req, resp.Header)
+					trace.writeHeader(resp.Header, timings, reqOpts.Upstream().Attempts, startTime)
 					markSuccess(w, req, 0)
+					websocket.observeUpgrade(resp)
 					observer.HandleRequest(req, w.Header(), resp.StatusCode, nil)
 					return nil
 				},
-				Transport:     tripper,
-				FlushInterval: -1,
+				Transport:     current.tripper,
+				FlushInterval: flush.streamInterval(),
+			}
+			streamReq := req.Clone(ctx)
+			if err := current.hooks.run(streamReq, 0); err != nil {
+				markFailed(w, req, 0, err)
+				writeError(w, req, e, err, observer)
+				return
+			}
+			reverseProxy.ServeHTTP(w, streamReq)
+			if chain := timings.chain.Load(); chain > 0 {
+				timings.copy.Store(int64(time.Since(chainStart)) - chain)
 			}
-			reverseProxy.ServeHTTP(w, req.Clone(ctx))
 		}
+		captureToken := takeCaptureToken(req)
 		if e.Stream {
+			if err := websocket.check(req); err != nil {
+				websocket.writeError(w, req, err, observer)
+				return
+			}
 			proxyStream()
 			return
 		}
 
-		body, err := io.ReadAll(req.Body)
+		body, release, err := p.buffers.readBody(req)
+		defer release()
+		if errors.Is(err, ErrBufferBudgetExceeded) {
+			writeBufferBudgetError(w, req, e, p.buffers.retryAfter(), observer)
+			return
+		}
 		if err != nil {
 			writeError(w, req, e, err, observer)
 			return
 		}
 		observer.HandleReceivedBytes(req, int64(len(body)))
+		p.captures.capture(req, captureToken, e.Path, body)
 		req.GetBody = func() (io.ReadCloser, error) {
 			reader := bytes.NewReader(body)
 			return io.NopCloser(reader), nil
 		}
-
-		var resp *http.Response
+		// every attempt is cloned from the request captured before the first attempt,
+		// so the changes made by the previous attempts are not carried over
+		pristine := req.Clone(ctx)
+
+		var (
+			resp, lastResp *http.Response
+			attempts       int
+		)
 		for i := 0; i < retryStrategy.attempts; i++ {
 			if i > 0 {
-				if !retryFeature.Enabled() {
+				if !features.Retry.Enabled() {
 					break
 				}
 				if err := retryBreaker.Allow(); err != nil {
@@ -224,17 +508,37 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			}
 			// canceled or deadline exceeded
 			if err = ctx.Err(); err != nil {
+				err = middleware.WithTimeoutSource(ctx, err)
 				markFailed(w, req, i, err)
 				break
 			}
-			tryCtx, cancel := p.prepareAttemptTimeoutContext(ctx, req, retryStrategy.perTryTimeout)
+			tryCtx, cancel := p.prepareAttemptTimeoutContext(ctx, req, retryStrategy.attemptTimeout(ctx, i))
 			defer cancel()
-			reader := bytes.NewReader(body)
-			req.Body = io.NopCloser(reader)
-			resp, err = tripper.RoundTrip(req.Clone(tryCtx))
+			reqOpts.Attempt = i
+			middleware.Attempt.Set(reqOpts, i)
+			middleware.ResponseSource.Set(reqOpts, "")
+			attemptReq := pristine.Clone(tryCtx)
+			attemptReq.Body = io.NopCloser(bytes.NewReader(body))
+			if err = current.hooks.run(attemptReq, i); err != nil {
+				markFailed(w, req, i, err)
+				break
+			}
+			deadline.apply(reqOpts)
+			attempts = i + 1
+			chainStart := time.Now()
+			resp, err = current.tripper.RoundTrip(attemptReq)
+			timings.chain.Add(int64(time.Since(chainStart)))
+			if err == nil {
+				observeGRPCMethod(req, resp)
+			}
 			if err != nil {
+				err = middleware.WithTimeoutSource(tryCtx, err)
 				markFailed(w, req, i, err)
-				log.Errorf("Attempt at [%d/%d], failed to handle request: %s: %+v", i+1, retryStrategy.attempts, req.URL.String(), err)
+				_errorLog.Errorf("Attempt at [%d/%d], failed to handle request: %s: %+v", i+1, retryStrategy.attempts, req.URL.String(), err)
+				if isNoHealthyUpstream(err) {
+					// the other attempts would find no instances either
+					break
+				}
 				continue
 			}
 			if !judgeRetryRequired(retryStrategy.conditions, resp) {
@@ -243,18 +547,45 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 				break
 			}
 			markFailed(w, req, i, errors.New("assertion failed"))
-			resp.Body.Close()
+			lastResp = retryStrategy.keepRetryableResponse(lastResp, resp)
+			resp = nil
 			// continue the retry loop
 		}
+		resp, err = retryStrategy.exhausted(resp, lastResp, err, attempts)
 		if err != nil {
+			debugHeaders.Write(debug, reqOpts, w.Header())
+			security.Write(req, w.Header())
 			writeError(w, req, e, err, observer)
 			return
 		}
+		if shouldMapGRPCStatus(e, req) {
+			if err := mapGRPCStatus(resp); err != nil {
+				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
+				debugHeaders.Write(debug, reqOpts, w.Header())
+				security.Write(req, w.Header())
+				writeError(w, req, e, err, observer)
+				return
+			}
+		}
 
+		removeHopByHopHeaders(resp.Header)
+		headerPolicy.Filter(resp.Header)
+		headerStats.observeResponse(resp.Header)
 		headers := w.Header()
 		for k, v := range resp.Header {
 			headers[k] = v
 		}
+		if len(resp.Trailer) > 0 {
+			// announce the trailers since the Trailer header is a hop-by-hop header
+			trailerKeys := make([]string, 0, len(resp.Trailer))
+			for k := range resp.Trailer {
+				trailerKeys = append(trailerKeys, k)
+			}
+			headers.Add("Trailer", strings.Join(trailerKeys, ", "))
+		}
+		debugHeaders.Write(debug, reqOpts, headers)
+		security.Write(req, headers)
+		trace.writeHeader(headers, timings, reqOpts.Upstream().Attempts, startTime)
 		w.WriteHeader(resp.StatusCode)
 		// flush any non grpc-status headers immediately for HTTP/2 GRPC requests.
 		// otherwise, the http2 server will send `content-length: 0` in error response,
@@ -273,15 +604,12 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			}
 			defer resp.Body.Close()
 
-			copyFunc := io.Copy
-			if isNoBufferingResponse(resp) {
-				copyFunc = copyNoBuffering(w)
-			}
-			sent, err := copyFunc(w, resp.Body)
+			source, _ := middleware.ResponseSource.Get(reqOpts)
+			sent, err := flush.copier(w, resp, source != "")(w, resp.Body)
 			if err != nil {
 				observer.HandleSentBytes(req, sent)
 				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
-				log.Errorf("Failed to copy backend response body to client: [%s] %s %s %d %+v\n", e.Protocol, e.Method, e.Path, sent, err)
+				_errorLog.Errorf("Failed to copy backend response body to client: [%s] %s %s %d %+v\n", e.Protocol, e.Method, e.Path, sent, err)
 				return false, err
 			}
 			observer.HandleSentBytes(req, sent)
@@ -292,27 +620,115 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			}
 			return true, nil
 		}
+		// the body is recorded while it is copied to the client
+		timings.upstreamBody = upstreamBody.wrap(resp)
+		copyStart := time.Now()
 		_, err = doCopyBody()
+		timings.copy.Store(int64(time.Since(copyStart)))
+		trace.writeTrailer(headers, timings, startTime)
+		logUpstreamBody(req, e, timings.upstreamBody)
 		observer.HandleRequest(req, headers, resp.StatusCode, err)
-	}), closer, nil
+	}), closer, chain, nil
 }
 
 // Update updates service endpoint.
-func (p *Proxy) Update(buildContext *client.BuildContext, c *config.Gateway) (retError error) {
-	router := mux.NewRouter(http.HandlerFunc(notFoundHandler), http.HandlerFunc(methodNotAllowedHandler))
-	for _, e := range c.Endpoints {
-		handler, closer, err := p.buildEndpoint(buildContext, e, c.Middlewares)
+// If only the middleware options changed since the last update, the middleware chains are swapped in place
+// without rebuilding the clients and the router.
+// With the rollback option, the config is observed after the update and rolled back if the errors spike.
+func (p *Proxy) Update(buildContext *client.BuildContext, c *config.Gateway) error {
+	if p.rollback != nil {
+		return p.rollback.update(buildContext, c, p.update)
+	}
+	return p.update(buildContext, c)
+}
+
+func (p *Proxy) update(buildContext *client.BuildContext, c *config.Gateway) (retError error) {
+	ctx, span := _tracer.Start(context.Background(), "proxy update", trace.WithAttributes(
+		attribute.String("config.version", c.Version),
+		attribute.Int("config.endpoints", len(c.Endpoints)),
+	))
+	defer func() { endSpan(span, retError) }()
+	if swapped, err := p.trySwapMiddlewares(c); swapped || err != nil {
+		span.SetAttributes(attribute.Bool("update.middlewares_swapped", swapped))
+		return err
+	}
+	gw, err := newGatewayContext(c)
+	if err != nil {
+		return err
+	}
+	p.headerStats.begin()
+	gw.chains = newChainCache(p.shareable)
+	notFound, methodNotAllowed, closers, err := p.buildFallback(ctx, buildContext, gw, c.Fallback)
+	if err != nil {
+		return err
+	}
+	for _, closer := range closers {
+		defer closeOnError(closer, &retError)
+	}
+	router := mux.NewRouter(notFound, methodNotAllowed, closers...)
+	prewarmer := p.newPrewarmer(c)
+	services := upstreamServices{}
+	endpoints := make([]*config.Endpoint, 0, len(c.Endpoints))
+	chains := make([]*middlewareChain, len(c.Endpoints))
+	rollouts := &rolloutSet{}
+	generation := &routeGeneration{}
+	summary := newRoutesSummary()
+	routes, err := resolveRoutes(gw, c.Endpoints)
+	if err != nil {
+		return err
+	}
+	for _, route := range routes {
+		e := route.endpoint
+		endpoints = append(endpoints, e)
+		handler, closer, chain, err := p.buildEndpointChain(ctx, buildContext, gw, e)
 		if err != nil {
 			return err
 		}
 		defer closeOnError(closer, &retError)
-		if err = router.Handle(e.Path, e.Method, e.Host, handler, closer); err != nil {
+		if route.index >= 0 {
+			chains[route.index] = chain
+		}
+		routeCloser := closer
+		if e.PriorityRollout != nil {
+			var baselineCloser io.Closer
+			var r *rollout
+			handler, baselineCloser, r, err = p.buildRollout(ctx, buildContext, gw, e, handler, notFound)
+			if err != nil {
+				return err
+			}
+			defer closeOnError(baselineCloser, &retError)
+			routeCloser = multiCloser{closer, baselineCloser}
+			rollouts.add(e, r)
+		}
+		inspect := newInspectHandler(gw, e, p.drains.routeHandler(e, generation.tracker(e).handler(e, handler)), routeCloser)
+		inspect.grpcMethods = gw.grpcMethods.get(e)
+		summary.add(e, inspect.inspect.Middlewares, inspect.nodeFilters)
+		if err = router.Handle(e.Path, e.Method, e.Host, inspect, routeCloser); err != nil {
 			return err
 		}
+		prewarmer.Add(e, closer)
+		services.add(e, closer)
 		log.Infof("build endpoint: [%s] %s %s", e.Protocol, e.Method, e.Path)
 	}
+	span.SetAttributes(attribute.Int("update.shared_middlewares", gw.chains.shared))
+	prewarmer.Run()
+	p.buffers.update(c.BufferBudget)
+	p.inflight.add(generation)
+	p.methods.Store(gw.methods)
 	old := p.router.Swap(router)
-	tryCloseRouter(old)
+	p.commitPrewarm(prewarmer)
+	p.drains.updateEndpoints(endpoints)
+	p.services.Store(services)
+	prevRollouts, _ := p.rollouts.Swap(rollouts).(*rolloutSet)
+	rollouts.observe(prevRollouts, time.Now())
+	p.storeApplied(c, chains, gw.extraChains)
+	p.slos.update(endpoints)
+	p.headerStats.commit()
+	summary.observe(time.Now())
+	p.routesSummary.Store(summary)
+	// the router is swapped before so the requests seeing the configured state are served by it
+	p.state.markConfigured()
+	p.closeRouter(old, p.generation.Swap(generation))
 	return nil
 }
 
@@ -325,13 +741,25 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			w.WriteHeader(http.StatusBadGateway)
 			buf := make([]byte, 64<<10) //nolint:gomnd
 			n := runtime.Stack(buf, false)
-			log.Errorf("panic recovered: %+v\n%s", err, buf[:n])
+			_errorLog.Errorf("panic recovered: %+v\n%s", err, buf[:n])
 			fmt.Fprintf(os.Stderr, "panic recovered: %+v\n%s\n", err, buf[:n])
 		}
 	}()
+	if !p.state.configured.Load() {
+		p.serveNotConfigured(w, req)
+		return
+	}
+	if !p.methods.Load().check(w, req) {
+		return
+	}
 	p.router.Load().(router.Router).ServeHTTP(w, req)
 }
 
+// Drains returns the drain manager of the proxy.
+func (p *Proxy) Drains() *DrainManager {
+	return p.drains
+}
+
 // DebugHandler implemented debug handler.
 func (p *Proxy) DebugHandler() http.Handler {
 	debugMux := http.NewServeMux()
@@ -340,10 +768,21 @@ func (p *Proxy) DebugHandler() http.Handler {
 		if !ok {
 			return
 		}
-		inspect := mux.InspectMuxRouter(router)
+		inspect := filterRouterInspect(mux.InspectMuxRouter(router), r.URL.Query().Get("path"))
+		if r.URL.Query().Get("format") == "table" {
+			rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			writeRouterInspectTable(rw, inspect)
+			return
+		}
 		rw.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(rw).Encode(inspect)
 	})
+	p.slowRequests.registerDebugHandler(debugMux)
+	p.registerCaptureDebugHandler(debugMux)
+	p.registerInflightDebugHandler(debugMux)
+	p.registerHeaderStatsDebugHandler(debugMux)
+	p.registerLogDebugHandler(debugMux)
+	p.slos.registerDebugHandler(debugMux)
 	return debugMux
 }
 
@@ -361,21 +800,6 @@ func closeOnError(closer io.Closer, err *error) {
 	closer.Close()
 }
 
-func tryCloseRouter(in interface{}) {
-	if in == nil {
-		return
-	}
-	r, ok := in.(router.Router)
-	if !ok {
-		return
-	}
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-		defer cancel()
-		r.SyncClose(ctx)
-	}()
-}
-
 func splitRetryMetricsHandler(observer Observer) (
 	func(http.ResponseWriter, *http.Request, int), func(http.ResponseWriter, *http.Request, int, error), func(http.ResponseWriter, *http.Request, int),
 ) {
@@ -482,3 +906,14 @@ func setXFFHeader(req *http.Request) {
 		}
 	}
 }
+
+type attemptHooks []middleware.AttemptHook
+
+func (h attemptHooks) run(req *http.Request, attempt int) error {
+	for _, hook := range h {
+		if err := hook.OnAttempt(req, attempt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,17 +3,26 @@ package proxy
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aide-family/goddess/client"
+	goddessconfig "github.com/aide-family/goddess/config"
 	"github.com/aide-family/goddess/middleware"
 	"github.com/aide-family/goddess/middleware/logging"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
 	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/selector"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type responseWriter struct {
@@ -331,3 +340,888 @@ func TestRecoverErrAbortHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestUpstreamDebugHeaders(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/debug",
+			Method:   "GET",
+			Retry: &config.Retry{
+				Attempts: 2,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "500"},
+				}},
+			},
+			UpstreamDebugHeaders: &config.UpstreamDebugHeaders{Header: "X-Gateway-Debug", Values: []string{"secret"}},
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			if v := req.Header.Get("X-Gateway-Debug"); v != "" {
+				t.Errorf("want the debug header stripped but the upstream got %q", v)
+			}
+			backends, _ := middleware.RequestBackendsFromContext(req.Context())
+			if len(backends) == 0 {
+				middleware.WithRequestBackends(req.Context(), "127.0.0.1:8001")
+				return &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}, Body: nopBody}, nil
+			}
+			middleware.WithRequestBackends(req.Context(), "127.0.0.1:8002")
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, debug := range []string{"secret", "guess", ""} {
+		r := httptest.NewRequest("GET", "/debug", nil)
+		if debug != "" {
+			r.Header.Set("X-Gateway-Debug", debug)
+		}
+		w := newResponseWriter()
+		p.ServeHTTP(w, r)
+		addr, attempts := w.header.Get("X-Upstream-Addr"), w.header.Get("X-Attempt-Count")
+		if debug != "secret" {
+			if addr != "" || attempts != "" {
+				t.Fatalf("want no debug headers for %q but got: %s %s", debug, addr, attempts)
+			}
+			continue
+		}
+		if addr != "127.0.0.1:8002" || attempts != "2" {
+			t.Fatalf("want upstream 127.0.0.1:8002 after 2 attempts but got: %s %s", addr, attempts)
+		}
+	}
+
+	// the trigger without the trusted values is rejected
+	c.Endpoints[0].UpstreamDebugHeaders.Values = nil
+	if err := p.Update(client.NewBuildContext(c), c); !errors.Is(err, errUpstreamDebugValues) {
+		t.Fatalf("want the upstream debug headers without values rejected but got %v", err)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/foo",
+			Method:   "GET",
+		}},
+		Fallback: &config.Fallback{
+			NotFound: &config.FallbackAction{Action: &config.FallbackAction_Endpoint{
+				Endpoint: &config.Endpoint{Protocol: config.Protocol_HTTP},
+			}},
+			Hosts: map[string]*config.Fallback{
+				"api.example.com": {
+					NotFound: &config.FallbackAction{Action: &config.FallbackAction_Static_{
+						Static: &config.FallbackAction_Static{
+							Headers: map[string]string{"Content-Type": "application/json"},
+							Body:    `{"code":{{.Status}},"path":{{ json .Path }}}`,
+						},
+					}},
+				},
+				"www.example.com": {
+					NotFound: &config.FallbackAction{Action: &config.FallbackAction_Redirect_{
+						Redirect: &config.FallbackAction_Redirect{Url: "https://status.example.com/?from={{.Path}}"},
+					}},
+					MethodNotAllowed: &config.FallbackAction{Action: &config.FallbackAction_Static_{
+						Static: &config.FallbackAction_Static{Status: http.StatusForbidden},
+					}},
+				},
+			},
+		},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Endpoint": []string{e.Path}}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		method string
+		url    string
+		code   int
+		header string
+		value  string
+		body   string
+	}{
+		{method: "GET", url: "http://api.example.com/unknown", code: http.StatusNotFound, header: "Content-Type", value: "application/json", body: `{"code":404,"path":"/unknown"}`},
+		{method: "GET", url: `http://api.example.com/a%22b%5Cc`, code: http.StatusNotFound, body: `{"code":404,"path":"/a\"b\\c"}`},
+		{method: "GET", url: "http://www.example.com:8080/unknown", code: http.StatusFound, header: "Location", value: "https://status.example.com/?from=/unknown"},
+		{method: "POST", url: "http://www.example.com/foo", code: http.StatusForbidden},
+		{method: "POST", url: "http://api.example.com/foo", code: http.StatusMethodNotAllowed},
+		{method: "GET", url: "http://other.example.com/unknown", code: http.StatusOK, header: "X-Endpoint", value: "/fallback/404"},
+	}
+	for _, testCase := range testCases {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest(testCase.method, testCase.url, nil))
+		if w.statusCode != testCase.code {
+			t.Fatalf("%s %s: want %d but got %d", testCase.method, testCase.url, testCase.code, w.statusCode)
+		}
+		if testCase.header != "" && w.header.Get(testCase.header) != testCase.value {
+			t.Fatalf("%s %s: want %s %q but got %q", testCase.method, testCase.url, testCase.header, testCase.value, w.header.Get(testCase.header))
+		}
+		if testCase.body != "" && w.body.String() != testCase.body {
+			t.Fatalf("%s %s: want body %s but got %s", testCase.method, testCase.url, testCase.body, w.body.String())
+		}
+	}
+}
+
+func TestNewObservableRegisterer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	// registering twice into the same registerer must not panic
+	NewObservable(WithRegisterer(registry))
+	observer := NewObservable(WithRegisterer(registry)).Observe(&config.Endpoint{Protocol: config.Protocol_HTTP, Path: "/observable"})
+	NewObservable()
+	observer.HandleRequest(httptest.NewRequest("GET", "/observable", nil), http.Header{}, http.StatusOK, nil)
+	goddessconfig.ObserveReload(goddessconfig.ReloadSourceFile, "v1", "digest", nil)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+	if !found["go_gateway_requests_code_total"] {
+		t.Fatal("want requests metrics registered into the custom registry")
+	}
+	if !found["go_gateway_config_reload_total"] || !found["go_gateway_config_info"] {
+		t.Fatal("want the config reload metrics registered into the custom registry")
+	}
+	// the vectors of the features are registered even before their first observation
+	for _, c := range []prometheus.Collector{_metricAdmissionRejected, _metricGetHeadBodies, _metricStreamLimitRejected} {
+		var are prometheus.AlreadyRegisteredError
+		if err := registry.Register(c); !errors.As(err, &are) {
+			t.Fatalf("want the feature metrics registered into the custom registry but got %v", err)
+		}
+	}
+}
+
+func TestObservableRegistererIsolation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/isolated",
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+		}},
+	}
+	newProxy := func(registry *prometheus.Registry) *Proxy {
+		p, err := New(client.NewFactory(nil), func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+			return middleware.EmptyMiddleware, nil
+		}, WithObservable(NewObservable(WithRegisterer(registry))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Update(client.NewBuildContext(c), c); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	requests := func(registry *prometheus.Registry) float64 {
+		families, err := registry.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var total float64
+		for _, family := range families {
+			if family.GetName() != "go_gateway_requests_code_total" {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				total += metric.GetCounter().GetValue()
+			}
+		}
+		return total
+	}
+	registry1, registry2 := prometheus.NewRegistry(), prometheus.NewRegistry()
+	p1, p2 := newProxy(registry1), newProxy(registry2)
+	for i := 0; i < 3; i++ {
+		p1.ServeHTTP(newResponseWriter(), httptest.NewRequest("GET", "/isolated", nil))
+	}
+	p2.ServeHTTP(newResponseWriter(), httptest.NewRequest("GET", "/isolated", nil))
+	// the proxies of the different registries count their own requests only
+	if n1, n2 := requests(registry1), requests(registry2); n1 != 3 || n2 != 1 {
+		t.Fatalf("want 3 and 1 requests counted but got %v and %v", n1, n2)
+	}
+}
+
+func TestResponseHeaderPolicy(t *testing.T) {
+	c := &config.Gateway{
+		Name:               "Test",
+		ResponseHeaderDeny: []string{"Server", "X-Internal-*"},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/default",
+			Method:   "GET",
+		}, {
+			Protocol:            config.Protocol_HTTP,
+			Path:                "/allow",
+			Method:              "GET",
+			ResponseHeaderAllow: []string{"Content-Type", "X-*"},
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header: http.Header{
+					"Content-Type":     []string{"application/json"},
+					"Connection":       []string{"X-Conn-Scoped"},
+					"X-Conn-Scoped":    []string{"1"},
+					"Keep-Alive":       []string{"timeout=5"},
+					"Server":           []string{"backend"},
+					"X-Internal-Debug": []string{"stack"},
+					"X-Request-Id":     []string{"id"},
+					"Cache-Control":    []string{"no-cache"},
+				},
+				Trailer: http.Header{"Grpc-Status": nil},
+				Body:    nopBody,
+			}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]http.Header{
+		"/default": {
+			"Content-Type":        []string{"application/json"},
+			"X-Request-Id":        []string{"id"},
+			"Cache-Control":       []string{"no-cache"},
+			"Trailer":             []string{"Grpc-Status"},
+			"Trailer:Grpc-Status": nil,
+		},
+		"/allow": {
+			"Content-Type":        []string{"application/json"},
+			"X-Request-Id":        []string{"id"},
+			"Trailer":             []string{"Grpc-Status"},
+			"Trailer:Grpc-Status": nil,
+		},
+	}
+	for path, want := range testCases {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if !reflect.DeepEqual(w.header, want) {
+			t.Fatalf("%s: want %+v but got %+v", path, want, w.header)
+		}
+	}
+}
+
+func TestSanitizeRequestHeaders(t *testing.T) {
+	testCases := []struct {
+		name   string
+		stream bool
+		in     http.Header
+		want   http.Header
+	}{
+		{
+			name: "connection listed headers",
+			in: http.Header{
+				"Connection":          []string{"keep-alive, X-Secret", "Authorization"},
+				"X-Secret":            []string{"1"},
+				"Authorization":       []string{"Bearer token"},
+				"Keep-Alive":          []string{"timeout=5"},
+				"Proxy-Connection":    []string{"keep-alive"},
+				"Proxy-Authorization": []string{"Basic xxx"},
+				"Transfer-Encoding":   []string{"chunked"},
+				"X-Request-Id":        []string{"id"},
+			},
+			want: http.Header{"X-Request-Id": []string{"id"}},
+		},
+		{
+			name: "grpc trailers",
+			in:   http.Header{"Te": []string{"trailers, deflate"}, "Content-Type": []string{"application/grpc"}},
+			want: http.Header{"Te": []string{"trailers"}, "Content-Type": []string{"application/grpc"}},
+		},
+		{
+			name: "upgrade on non stream endpoint",
+			in:   http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+			want: http.Header{},
+		},
+		{
+			name:   "upgrade on stream endpoint",
+			stream: true,
+			in:     http.Header{"Connection": []string{"Upgrade, X-Forwarded-For"}, "Upgrade": []string{"websocket"}, "X-Forwarded-For": []string{"1.1.1.1"}},
+			want:   http.Header{"Connection": []string{"Upgrade"}, "Upgrade": []string{"websocket"}},
+		},
+	}
+	for _, testCase := range testCases {
+		sanitizeRequestHeaders(testCase.in, testCase.stream)
+		if !reflect.DeepEqual(testCase.in, testCase.want) {
+			t.Errorf("%s: want %+v but got %+v", testCase.name, testCase.want, testCase.in)
+		}
+	}
+}
+
+func TestProxySanitizeRequestHeaders(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/sanitize",
+			Method:   "GET",
+		}},
+	}
+	var upstream http.Header
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			upstream = req.Header.Clone()
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/sanitize", nil)
+	r.Header.Set("Connection", "close, X-Forwarded-For, Content-Length")
+	r.Header.Set("Content-Length", "0")
+	r.Header.Set("Upgrade", "h2c")
+	p.ServeHTTP(newResponseWriter(), r)
+	want := http.Header{"X-Forwarded-For": []string{"192.0.2.1"}}
+	if !reflect.DeepEqual(upstream, want) {
+		t.Fatalf("want %+v but got %+v", want, upstream)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/maintenance",
+			Method:   "GET",
+			Maintenance: &config.Maintenance{
+				Enabled:     true,
+				Body:        `{"message":"back soon"}`,
+				ContentType: "application/json",
+				RetryAfter:  durationpb.New(90 * time.Second),
+			},
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		t.Fatal("client must not be built for endpoints under maintenance")
+		return nil, nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/maintenance", nil))
+	if w.statusCode != http.StatusServiceUnavailable {
+		t.Fatalf("want 503 but got %d", w.statusCode)
+	}
+	if w.header.Get("Retry-After") != "90" || w.header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers: %+v", w.header)
+	}
+	if w.body.String() != `{"message":"back soon"}` {
+		t.Fatalf("unexpected body: %s", w.body.String())
+	}
+}
+
+func TestMapGRPCStatusToHTTP(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:            config.Protocol_GRPC,
+			Path:                "/helloworld.Greeter/SayHello",
+			Method:              "POST",
+			MapGrpcStatusToHttp: true,
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": []string{"application/grpc"}},
+				Trailer:    http.Header{"Grpc-Status": []string{"5"}, "Grpc-Message": []string{"user%20not%20found"}},
+				Body:       nopBody,
+			}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", nil)
+	r.Header.Set("Content-Type", "application/json")
+	w := newResponseWriter()
+	p.ServeHTTP(w, r)
+	if w.statusCode != http.StatusNotFound {
+		t.Fatalf("want 404 but got %d", w.statusCode)
+	}
+	if w.header.Get("Content-Type") != "application/json" || w.header.Get("Trailer") != "" {
+		t.Fatalf("unexpected headers: %+v", w.header)
+	}
+	if want := `{"code":404,"reason":"NotFound","message":"user not found"}`; w.body.String() != want {
+		t.Fatalf("want body %s but got %s", want, w.body.String())
+	}
+
+	// grpc clients receive the original response
+	r = httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", nil)
+	r.Header.Set("Content-Type", "application/grpc")
+	w = newResponseWriter()
+	p.ServeHTTP(w, r)
+	if w.statusCode != http.StatusOK || w.header.Get(http.TrailerPrefix+"Grpc-Status") != "5" {
+		t.Fatalf("want original grpc response but got %d %+v", w.statusCode, w.header)
+	}
+}
+
+func TestMapGRPCStatusBodyLimit(t *testing.T) {
+	data := bytes.Repeat([]byte{'x'}, _maxGRPCStatusBodyBytes+1)
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/grpc"}, "Grpc-Status": []string{"5"}},
+		Body:       io.NopCloser(bytes.NewReader(data)),
+	}
+	if err := mapGRPCStatus(resp); err != nil {
+		t.Fatal(err)
+	}
+	// the body over the limit is passed through unmapped
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Grpc-Status") != "5" {
+		t.Fatalf("want the response unmapped but got %d %+v", resp.StatusCode, resp.Header)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, data) {
+		t.Fatalf("want the full body of %d bytes but got %d", len(data), len(body))
+	}
+}
+
+func TestRouterInspect(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Middlewares: []*config.Middleware{{
+			Name: "logging",
+		}},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/foo",
+			Method:   "GET",
+			Timeout:  durationpb.New(3 * time.Second),
+			Backends: []*config.Backend{{Target: "discovery:///foo"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/bar",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	p.ServeHTTP(newResponseWriter(), httptest.NewRequest("GET", "/api/foo", nil))
+
+	w := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/proxy/router/inspect?path=/api", nil))
+	var inspect []struct {
+		PathTemplate string           `json:"path_template"`
+		Endpoint     *EndpointInspect `json:"endpoint"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &inspect); err != nil {
+		t.Fatal(err)
+	}
+	if len(inspect) != 1 || inspect[0].Endpoint == nil {
+		t.Fatalf("want only /api/foo but got: %s", w.Body.String())
+	}
+	e := inspect[0].Endpoint
+	if e.Requests != 1 || e.LastMatch == nil || e.Timeout != "3s" ||
+		!reflect.DeepEqual(e.Middlewares, []string{"logging"}) ||
+		len(e.Backends) != 1 || e.Backends[0].Discovery != "foo" {
+		t.Fatalf("unexpected inspect: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/proxy/router/inspect?format=table", nil))
+	if lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n"); len(lines) != 3 {
+		t.Fatalf("want header and 2 rows but got:\n%s", w.Body.String())
+	}
+}
+
+type prewarmClient struct {
+	RoundTripperCloserFunc
+	prewarmed *int
+}
+
+func (c *prewarmClient) Prewarm(ctx context.Context, conns int, budget *client.PrewarmBudget) []client.PrewarmResult {
+	*c.prewarmed += conns
+	return make([]client.PrewarmResult, conns)
+}
+
+func TestPrewarm(t *testing.T) {
+	prewarmed := 0
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return &prewarmClient{
+			RoundTripperCloserFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+			},
+			prewarmed: &prewarmed,
+		}, nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newConfig := func(timeout time.Duration) *config.Gateway {
+		return &config.Gateway{
+			Name:    "Test",
+			Prewarm: &config.Prewarm{Connections: 2},
+			Endpoints: []*config.Endpoint{{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/prewarm",
+				Prewarm:  true,
+				Timeout:  durationpb.New(timeout),
+			}, {
+				Protocol: config.Protocol_HTTP,
+				Path:     "/cold",
+			}},
+		}
+	}
+	for i, tc := range []struct {
+		config *config.Gateway
+		want   int
+	}{
+		{config: newConfig(time.Second), want: 2},
+		// unchanged endpoints are not prewarmed again
+		{config: newConfig(time.Second), want: 2},
+		{config: newConfig(2 * time.Second), want: 4},
+	} {
+		if err := p.Update(client.NewBuildContext(tc.config), tc.config); err != nil {
+			t.Fatal(err)
+		}
+		if prewarmed != tc.want {
+			t.Fatalf("update %d: want %d prewarmed connections but got %d", i, tc.want, prewarmed)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	newConfig := func(timeout time.Duration) *config.Gateway {
+		return &config.Gateway{
+			Name: "Test",
+			Endpoints: []*config.Endpoint{{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/drain",
+				Method:   "GET",
+				Timeout:  durationpb.New(timeout),
+			}, {
+				Protocol: config.Protocol_HTTP,
+				Path:     "/other",
+				Method:   "GET",
+			}},
+		}
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newConfig(time.Second)
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	admin := p.Drains().DebugHandler()
+	post := func(path, body string) int {
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return w.Code
+	}
+	status := func(path string) int {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w.statusCode
+	}
+	if code := post("/debug/admin/drain-route", `{"method":"get","path":"/drain","duration":"1m"}`); code != http.StatusOK {
+		t.Fatalf("drain route: %d", code)
+	}
+	if status("/drain") != http.StatusServiceUnavailable || status("/other") != http.StatusOK {
+		t.Fatal("want only the drained route unavailable")
+	}
+	// reload without touching the route keeps the drain
+	if err := p.Update(client.NewBuildContext(c), newConfig(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if status("/drain") != http.StatusServiceUnavailable {
+		t.Fatal("want drain kept across unrelated reload")
+	}
+	if code := post("/debug/admin/undrain-route", `{"method":"GET","path":"/drain"}`); code != http.StatusOK {
+		t.Fatalf("undrain route: %d", code)
+	}
+	if status("/drain") != http.StatusOK {
+		t.Fatal("want route available after undrain")
+	}
+
+	post("/debug/admin/drain-route", `{"method":"GET","path":"/drain"}`)
+	if err := p.Update(client.NewBuildContext(c), newConfig(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if status("/drain") != http.StatusOK || len(p.Drains().List()) != 0 {
+		t.Fatal("want drain dropped when the route is changed")
+	}
+
+	post("/debug/admin/drain-node", `{"service":"foo","address":"127.0.0.1:8000","duration":"1ms"}`)
+	filter := p.Drains().nodeFilter(&config.Endpoint{Backends: []*config.Backend{{Target: "discovery:///foo"}}})
+	nodes := []selector.Node{
+		selector.NewNode("http", "127.0.0.1:8000", nil),
+		selector.NewNode("http", "127.0.0.1:8001", nil),
+	}
+	if got := filter(context.Background(), nodes); len(got) != 1 || got[0].Address() != "127.0.0.1:8001" {
+		t.Fatalf("want drained node excluded but got %v", got)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if got := filter(context.Background(), nodes); len(got) != 2 || len(p.Drains().List()) != 0 {
+		t.Fatal("want node drain expired")
+	}
+}
+
+type flushRecorder struct {
+	*responseWriter
+	lock    sync.Mutex
+	flushed []int
+}
+
+func (r *flushRecorder) Write(b []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.responseWriter.Write(b)
+}
+
+func (r *flushRecorder) Flush() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.flushed = append(r.flushed, r.body.Len())
+}
+
+func TestFlushInterval(t *testing.T) {
+	newEndpoint := func(path string) *config.Endpoint {
+		return &config.Endpoint{Protocol: config.Protocol_HTTP, Path: path, Method: "GET", Timeout: durationpb.New(5 * time.Second)}
+	}
+	immediate, interval, buffered := newEndpoint("/immediate"), newEndpoint("/interval"), newEndpoint("/buffered")
+	immediate.FlushContentTypes = []string{"text/event-stream"}
+	interval.FlushInterval = durationpb.New(10 * time.Millisecond)
+	c := &config.Gateway{Name: "Test", Endpoints: []*config.Endpoint{immediate, interval, buffered}}
+	// the upstream drips 3 chunks every 50ms
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				for i := 0; i < 3; i++ {
+					time.Sleep(50 * time.Millisecond)
+					pw.Write([]byte("data\n"))
+				}
+				pw.Close()
+			}()
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"text/event-stream"}}, Body: pr}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string][]int{
+		"/immediate": {5, 10, 15},
+		// the last chunk is flushed by the server when the handler returns
+		"/interval": {5, 10},
+		"/buffered": nil,
+	} {
+		w := &flushRecorder{responseWriter: newResponseWriter()}
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if len(w.flushed) > len(want) {
+			w.flushed = w.flushed[:len(want)]
+		}
+		if !reflect.DeepEqual(w.flushed, want) {
+			t.Errorf("%s: want flushes at %v but got %v", path, want, w.flushed)
+		}
+	}
+}
+
+func TestHostGroups(t *testing.T) {
+	static := func(body string) *config.Fallback {
+		return &config.Fallback{NotFound: &config.FallbackAction{Action: &config.FallbackAction_Static_{
+			Static: &config.FallbackAction_Static{Body: body},
+		}}}
+	}
+	c := &config.Gateway{
+		Name:        "Test",
+		Middlewares: []*config.Middleware{{Name: "global"}},
+		HostGroups: []*config.HostGroup{{
+			Name:        "public",
+			Hosts:       []string{"api.example.com", "*.example.org"},
+			Middlewares: []*config.Middleware{{Name: "group"}},
+			Fallback:    static("public"),
+			Timeout:     durationpb.New(3 * time.Second),
+		}},
+		Endpoints: []*config.Endpoint{
+			{Protocol: config.Protocol_HTTP, Path: "/foo", Host: "api.example.com", Middlewares: []*config.Middleware{{Name: "endpoint"}}},
+			{Protocol: config.Protocol_HTTP, Path: "/bar", HostGroup: "public", Timeout: durationpb.New(time.Second)},
+			{Protocol: config.Protocol_HTTP, Path: "/baz"},
+		},
+		Fallback: static("global"),
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"X-Chain": req.Header.Values("X-Chain"), "X-Service": []string{e.Metadata["service"]}}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				req.Header.Add("X-Chain", c.Name)
+				return next.RoundTrip(req)
+			})
+		}), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://api.example.com/foo", nil))
+	if chain := strings.Join(w.header.Values("X-Chain"), ","); chain != "global,group,endpoint" {
+		t.Fatalf("want chain global,group,endpoint but got %s", chain)
+	}
+	if w.header.Get("X-Service") != "public" {
+		t.Fatalf("want group as service but got %q", w.header.Get("X-Service"))
+	}
+	w = newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://any.example.com/baz", nil))
+	if chain := strings.Join(w.header.Values("X-Chain"), ","); chain != "global" {
+		t.Fatalf("want chain global but got %s", chain)
+	}
+	for host, body := range map[string]string{"www.example.org": "public", "api.example.com": "public", "other.example.com": "global"} {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://"+host+"/unknown", nil))
+		if w.body.String() != body {
+			t.Fatalf("%s: want fallback %s but got %s", host, body, w.body.String())
+		}
+	}
+
+	w2 := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w2, httptest.NewRequest("GET", "/debug/proxy/router/inspect", nil))
+	var inspect []struct {
+		Endpoint *EndpointInspect `json:"endpoint"`
+	}
+	if err := json.Unmarshal(w2.Body.Bytes(), &inspect); err != nil {
+		t.Fatal(err)
+	}
+	timeouts := map[string]string{}
+	for _, r := range inspect {
+		if r.Endpoint == nil {
+			continue
+		}
+		timeouts[r.Endpoint.Path] = r.Endpoint.HostGroup + "/" + r.Endpoint.Timeout
+	}
+	want := map[string]string{"/foo": "public/3s", "/bar": "public/1s", "/baz": "/1s"}
+	if !reflect.DeepEqual(timeouts, want) {
+		t.Fatalf("want %v but got %v", want, timeouts)
+	}
+
+	c.Endpoints = append(c.Endpoints, &config.Endpoint{Protocol: config.Protocol_HTTP, Path: "/unknown", HostGroup: "private"})
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want error of unknown host group")
+	}
+}
+
+func TestNoHealthyUpstream(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/empty",
+			Method:   "GET",
+			Retry:    &config.Retry{Attempts: 3},
+		}},
+	}
+	attempts := 0
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, client.ErrNoHealthyUpstream
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusServiceUnavailable, "NO_HEALTHY_UPSTREAM")
+	if attempts != 1 {
+		t.Fatalf("want the request not retried but got %d attempts", attempts)
+	}
+}
@@ -1,23 +1,104 @@
 package proxy
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"github.com/aide-family/goddess/proxy/condition"
-	"github.com/go-kratos/feature"
+	"github.com/go-kratos/kratos/v2/log"
 )
 
-var retryFeature = feature.MustRegister("gw:Retry", true)
-
 type retryStrategy struct {
 	attempts      int
 	timeout       time.Duration
 	perTryTimeout time.Duration
+	timeoutMode   config.Retry_AttemptTimeoutMode
 	conditions    []condition.Condition
+	onExhaustion  config.Retry_OnExhaustion
+}
+
+var errRetryExhausted = errors.New("all attempts matched the retry conditions")
+
+// _maxRetryableResponseBytes bounds the body of the response kept by the retry loop.
+const _maxRetryableResponseBytes = 1 << 20
+
+// keepRetryableResponse keeps the most recent response matched the retry conditions to be returned by exhausted,
+// the replaced response is closed. The body is buffered since the context of its attempt expires while the later
+// attempts are made, the response is dropped as the gateway error if the body exceeds _maxRetryableResponseBytes.
+func (s *retryStrategy) keepRetryableResponse(last, resp *http.Response) *http.Response {
+	closeResponse(last)
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return resp
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, _maxRetryableResponseBytes+1))
+	if err != nil {
+		log.Warnf("Failed to buffer the upstream response %d to be retried: %+v", resp.StatusCode, err)
+		return nil
+	}
+	if len(body) > _maxRetryableResponseBytes {
+		log.Warnf("Dropping the upstream response %d to be retried, the body is larger than %d bytes", resp.StatusCode, _maxRetryableResponseBytes)
+		return nil
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp
+}
+
+func closeResponse(resp *http.Response) {
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+}
+
+// exhausted returns the response of the retry loop, resp is the response not matched the retry conditions if any
+// and attempts is the number of the attempts made. The last response matched the retry conditions is dropped only
+// if on_exhaustion is the gateway error, and is still returned if the retries are stopped early, eg: by the retry
+// breaker.
+func (s *retryStrategy) exhausted(resp, last *http.Response, err error, attempts int) (*http.Response, error) {
+	if resp != nil {
+		closeResponse(last)
+		return resp, nil
+	}
+	if last != nil && s.onExhaustion == config.Retry_GATEWAY_ERROR && (err != nil || attempts >= s.attempts) {
+		closeResponse(last)
+		last = nil
+	}
+	if last != nil {
+		if err != nil {
+			log.Warnf("Returning the last upstream response %d instead of the error of the later attempt: %+v", last.StatusCode, err)
+		}
+		return last, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, errRetryExhausted
+}
+
+// attemptTimeout returns the timeout of the attempt i with the overall deadline in ctx.
+func (s *retryStrategy) attemptTimeout(ctx context.Context, i int) time.Duration {
+	remaining := s.timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining = time.Until(deadline)
+	}
+	switch s.timeoutMode {
+	case config.Retry_NONE:
+		return remaining
+	case config.Retry_REMAINING:
+		if left := s.attempts - i; left > 1 {
+			return remaining / time.Duration(left)
+		}
+		return remaining
+	default:
+		return s.perTryTimeout
+	}
 }
 
 func calcTimeout(endpoint *config.Endpoint) time.Duration {
@@ -45,6 +126,10 @@ func calcPerTryTimeout(endpoint *config.Endpoint) time.Duration {
 	var perTryTimeout time.Duration
 	if endpoint.Retry != nil && endpoint.Retry.PerTryTimeout != nil {
 		perTryTimeout = endpoint.Retry.PerTryTimeout.AsDuration()
+		if perTryTimeout == 0 {
+			// zero disables the per-try timeout
+			return 0
+		}
 	} else if endpoint.Timeout != nil {
 		perTryTimeout = endpoint.Timeout.AsDuration()
 	}
@@ -62,6 +147,14 @@ func prepareRetryStrategy(e *config.Endpoint) (*retryStrategy, error) {
 		attempts:      calcAttempts(e),
 		timeout:       calcTimeout(e),
 		perTryTimeout: calcPerTryTimeout(e),
+		timeoutMode:   e.Retry.GetAttemptTimeoutMode(),
+		onExhaustion:  e.Retry.GetOnExhaustion(),
+	}
+	if err := validateAttemptTimeout(e.Retry, strategy); err != nil {
+		return nil, err
+	}
+	if strategy.timeoutMode == config.Retry_PER_TRY && strategy.perTryTimeout == 0 {
+		strategy.timeoutMode = config.Retry_NONE
 	}
 	conditions, err := parseRetryConditon(e)
 	if err != nil {
@@ -71,6 +164,22 @@ func prepareRetryStrategy(e *config.Endpoint) (*retryStrategy, error) {
 	return strategy, nil
 }
 
+func validateAttemptTimeout(retry *config.Retry, strategy *retryStrategy) error {
+	switch strategy.timeoutMode {
+	case config.Retry_PER_TRY:
+		if strategy.perTryTimeout > strategy.timeout {
+			return fmt.Errorf("per_try_timeout %s is larger than timeout %s", strategy.perTryTimeout, strategy.timeout)
+		}
+	case config.Retry_REMAINING, config.Retry_NONE:
+		if retry.GetPerTryTimeout().AsDuration() > 0 {
+			return fmt.Errorf("per_try_timeout %s cannot be used with attempt_timeout_mode %s", retry.GetPerTryTimeout().AsDuration(), strategy.timeoutMode)
+		}
+	default:
+		return fmt.Errorf("unknown attempt_timeout_mode: %s", strategy.timeoutMode)
+	}
+	return nil
+}
+
 func parseRetryConditon(endpoint *config.Endpoint) ([]condition.Condition, error) {
 	if endpoint.Retry == nil {
 		return []condition.Condition{}, nil
@@ -83,5 +192,5 @@ func judgeRetryRequired(conditions []condition.Condition, resp *http.Response) b
 }
 
 func defaultAttemptTimeoutContext(ctx context.Context, _ *http.Request, timeout time.Duration) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(ctx, timeout)
+	return context.WithTimeoutCause(ctx, timeout, middleware.NewTimeoutError(middleware.TimeoutSourceAttempt))
 }
@@ -1,9 +1,20 @@
 package proxy
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
 	config "github.com/aide-family/goddess/pkg/config/v1"
 	"google.golang.org/protobuf/types/known/durationpb"
 )
@@ -71,3 +82,413 @@ func TestCalcTimeout(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryByResponseContent(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/body",
+			Method:   "GET",
+			Retry: &config.Retry{
+				Attempts: 2,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByBodyContains{
+						ByBodyContains: &config.ConditionBodyContains{Pattern: `"error":"RATE_LIMITED"`},
+					},
+				}},
+			},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/header",
+			Method:   "GET",
+			Retry: &config.Retry{
+				Attempts: 2,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByHeader{
+						ByHeader: &config.ConditionHeader{Name: "X-Upstream-State", ValueRegex: "^throttled"},
+					},
+				}},
+			},
+		}},
+	}
+	attempts := map[string]int{}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			attempts[req.URL.Path]++
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewBufferString(`{"data":"ok"}`))}
+			if attempts[req.URL.Path] == 1 {
+				resp.Header.Set("X-Upstream-State", "throttled; retry later")
+				resp.Body = io.NopCloser(bytes.NewBufferString(`{"error":"RATE_LIMITED"}`))
+			}
+			return resp, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/body", "/header"} {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if attempts[path] != 2 {
+			t.Fatalf("%s: want 2 attempts but got %d", path, attempts[path])
+		}
+		if w.statusCode != http.StatusOK || w.body.String() != `{"data":"ok"}` {
+			t.Fatalf("%s: want the response of the second attempt but got: %d %s", path, w.statusCode, w.body.String())
+		}
+	}
+}
+
+func TestRetryByBodyContainsOnStream(t *testing.T) {
+	_, err := prepareRetryStrategy(&config.Endpoint{
+		Stream: true,
+		Retry: &config.Retry{
+			Conditions: []*config.Condition{{
+				Condition: &config.Condition_ByBodyContains{
+					ByBodyContains: &config.ConditionBodyContains{Pattern: "RATE_LIMITED"},
+				},
+			}},
+		},
+	})
+	if err == nil {
+		t.Fatal("want error for body condition on stream endpoint")
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		endpoint *config.Endpoint
+		want     []time.Duration
+	}{
+		{
+			name: "per_try",
+			endpoint: &config.Endpoint{
+				Timeout: durationpb.New(10 * time.Second),
+				Retry:   &config.Retry{Attempts: 2, PerTryTimeout: durationpb.New(3 * time.Second)},
+			},
+			want: []time.Duration{3 * time.Second, 3 * time.Second},
+		},
+		{
+			name: "zero per_try",
+			endpoint: &config.Endpoint{
+				Timeout: durationpb.New(90 * time.Second),
+				Retry:   &config.Retry{Attempts: 2, PerTryTimeout: durationpb.New(0)},
+			},
+			want: []time.Duration{90 * time.Second, 90 * time.Second},
+		},
+		{
+			name: "remaining",
+			endpoint: &config.Endpoint{
+				Timeout: durationpb.New(9 * time.Second),
+				Retry:   &config.Retry{Attempts: 3, AttemptTimeoutMode: config.Retry_REMAINING},
+			},
+			want: []time.Duration{3 * time.Second, 4500 * time.Millisecond, 9 * time.Second},
+		},
+	}
+	for _, tc := range testCases {
+		strategy, err := prepareRetryStrategy(tc.endpoint)
+		if err != nil {
+			t.Fatalf("%s: %+v", tc.name, err)
+		}
+		for i, want := range tc.want {
+			// the deadline is fixed to make the remaining budget predictable
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(strategy.timeout))
+			got := strategy.attemptTimeout(ctx, i)
+			cancel()
+			if got > want || want-got > 100*time.Millisecond {
+				t.Errorf("%s: attempt %d want %s but got %s", tc.name, i, want, got)
+			}
+		}
+	}
+}
+
+func TestAttemptTimeoutValidation(t *testing.T) {
+	for _, e := range []*config.Endpoint{
+		{
+			Timeout: durationpb.New(time.Second),
+			Retry:   &config.Retry{PerTryTimeout: durationpb.New(2 * time.Second)},
+		},
+		{
+			Timeout: durationpb.New(time.Second),
+			Retry:   &config.Retry{PerTryTimeout: durationpb.New(time.Second), AttemptTimeoutMode: config.Retry_NONE},
+		},
+	} {
+		if _, err := prepareRetryStrategy(e); err == nil {
+			t.Errorf("want error for %v", e)
+		}
+	}
+}
+
+type trackedBody struct {
+	io.ReadCloser
+	open *int64
+}
+
+func (b *trackedBody) Close() error {
+	atomic.AddInt64(b.open, -1)
+	return b.ReadCloser.Close()
+}
+
+func TestRetryOnExhaustion(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		attempts = map[string]int{}
+		open     int64
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		attempts[r.URL.Path]++
+		n := attempts[r.URL.Path]
+		lock.Unlock()
+		if n >= 3 {
+			// the last attempt fails at the transport level, including the retry of the http client
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "attempt %d", n)
+	}))
+	defer srv.Close()
+
+	// counts the upstream response bodies not closed
+	factory := client.NewFactory(nil)
+	clientFactory := func(ctx *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		c, err := factory(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := c.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			atomic.AddInt64(&open, 1)
+			resp.Body = &trackedBody{ReadCloser: resp.Body, open: &open}
+			return resp, nil
+		}), nil
+	}
+	endpoint := func(path string, onExhaustion config.Retry_OnExhaustion) *config.Endpoint {
+		return &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+			Path:     path,
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+			Retry: &config.Retry{
+				Attempts:     3,
+				OnExhaustion: onExhaustion,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "503"},
+				}},
+			},
+		}
+	}
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{
+			endpoint("/last", config.Retry_LAST_RESPONSE),
+			endpoint("/error", config.Retry_GATEWAY_ERROR),
+			endpoint("/default", config.Retry_ON_EXHAUSTION_UNSPECIFIED),
+		},
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	// the unset on_exhaustion returns the last upstream response as well
+	for _, path := range []string{"/last", "/default"} {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.statusCode != http.StatusServiceUnavailable || w.body.String() != "attempt 2" {
+			t.Fatalf("want the last upstream response of %s but got: %d %s", path, w.statusCode, w.body.String())
+		}
+		if n := atomic.LoadInt64(&open); n != 0 {
+			t.Fatalf("want all the upstream responses closed but %d are open", n)
+		}
+	}
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/error", nil))
+	if w.statusCode != http.StatusBadGateway {
+		t.Fatalf("want gateway error but got: %d %s", w.statusCode, w.body.String())
+	}
+	if n := atomic.LoadInt64(&open); n != 0 {
+		t.Fatalf("want all the upstream responses closed but %d are open", n)
+	}
+}
+
+func TestRetryExhaustionDefault(t *testing.T) {
+	var attempts int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "attempt %d", n)
+	}))
+	defer srv.Close()
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/unavailable",
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+			Retry: &config.Retry{
+				Attempts: 3,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "503"},
+				}},
+			},
+		}},
+	}
+	p, err := New(client.NewFactory(nil), func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	// all the attempts return 503 with on_exhaustion unset, the last upstream response is forwarded
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/unavailable", nil))
+	if w.statusCode != http.StatusServiceUnavailable || w.body.String() != "attempt 3" {
+		t.Fatalf("want the last upstream response but got: %d %s", w.statusCode, w.body.String())
+	}
+	if n := atomic.LoadInt64(&attempts); n != 3 {
+		t.Fatalf("want 3 attempts but got %d", n)
+	}
+}
+
+func TestRetryKeptResponseAfterTimeout(t *testing.T) {
+	var attempts int64
+	body := strings.Repeat("unavailable", 1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&attempts, 1) > 1 {
+			// the last attempt exceeds the per-try timeout
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/unavailable",
+			Method:   "GET",
+			Timeout:  durationpb.New(time.Second),
+			Backends: []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+			Retry: &config.Retry{
+				Attempts:      2,
+				PerTryTimeout: durationpb.New(100 * time.Millisecond),
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "503"},
+				}},
+			},
+		}},
+	}
+	p, err := New(client.NewFactory(nil), func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	// the kept response is returned in full though the context of its attempt has expired
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/unavailable", nil))
+	if w.statusCode != http.StatusServiceUnavailable || w.body.String() != body {
+		t.Fatalf("want the full first upstream response but got: %d %d bytes", w.statusCode, w.body.Len())
+	}
+	if n := atomic.LoadInt64(&attempts); n != 2 {
+		t.Fatalf("want 2 attempts but got %d", n)
+	}
+}
+
+type attemptMiddleware struct{}
+
+func (m *attemptMiddleware) Process(next http.RoundTripper) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Add("X-Injected", "token")
+		return next.RoundTrip(req)
+	})
+}
+
+func (m *attemptMiddleware) OnAttempt(req *http.Request, attempt int) error {
+	if v := req.Header.Get("X-Attempt-Id"); v != "" {
+		return fmt.Errorf("attempt header is carried over: %s", v)
+	}
+	req.Header.Set("X-Attempt-Id", fmt.Sprintf("attempt-%d", attempt))
+	return nil
+}
+
+func (m *attemptMiddleware) Close() error { return nil }
+
+func TestAttemptHook(t *testing.T) {
+	var seen [][]string
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			opts, _ := middleware.FromRequestContext(req.Context())
+			if opts.Attempt != len(seen) {
+				t.Errorf("want attempt %d but got %d", len(seen), opts.Attempt)
+			}
+			seen = append(seen, append(req.Header.Values("X-Attempt-Id"), req.Header.Values("X-Injected")...))
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	c := &config.Gateway{
+		Name:        "Test",
+		Middlewares: []*config.Middleware{{Name: "attempt"}},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/attempt",
+			Method:   "GET",
+			Retry: &config.Retry{
+				Attempts: 3,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "503"},
+				}},
+			},
+		}},
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return &attemptMiddleware{}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/attempt", nil))
+	if len(seen) != 3 {
+		t.Fatalf("want 3 attempts but got %d: %d %s", len(seen), w.statusCode, w.body.String())
+	}
+	for i, values := range seen {
+		want := []string{fmt.Sprintf("attempt-%d", i), "token"}
+		if strings.Join(values, ",") != strings.Join(want, ",") {
+			t.Fatalf("attempt %d: want headers %v but got %v", i, want, values)
+		}
+	}
+}
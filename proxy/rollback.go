@@ -0,0 +1,357 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_defaultRollbackWindow       = time.Minute
+	_defaultRollbackThreshold    = 2
+	_defaultRollbackMinErrorRate = 0.05
+	_defaultRollbackMinRequests  = 100
+	// the error counts are kept in buckets of one second
+	_rollbackTick = time.Second
+)
+
+var (
+	_metricConfigRollbacks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_rollbacks_total",
+		Help:      "The total number of the configs rolled back for the error spikes after the update",
+	}, []string{"result"})
+	_metricConfigRollbackObserving = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_rollback_observing",
+		Help:      "Whether the config applied last is observed for the error spikes",
+	})
+	_metricConfigRollbackErrorRate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "config_rollback_error_rate",
+		Help:      "The error rate before and after the config update observed for the rollback",
+	}, []string{"phase"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricConfigRollbacks, _metricConfigRollbackObserving, _metricConfigRollbackErrorRate)
+}
+
+// RollbackConfig is the config of the rollback of the configs causing error spikes.
+type RollbackConfig struct {
+	// Window is the time the new config is observed after the update, and the time of the baseline before it.
+	Window time.Duration
+	// Threshold is the ratio of the error rate after the update to the baseline the config is rolled back over.
+	Threshold float64
+	// MinErrorRate is the error rate below which the config is never rolled back.
+	MinErrorRate float64
+	// MinRequests is the number of the requests needed to judge the error rate.
+	MinRequests int64
+	// OnRollback is called with the bad config after the previous one is applied again.
+	OnRollback func(bad *config.Gateway)
+}
+
+// WithRollback set the rollback option, a newly applied config is rolled back to the previous one if the
+// 5xx and upstream connect errors spike after the update.
+func WithRollback(c RollbackConfig) Option {
+	return func(p *Proxy) {
+		p.rollback = newRollbackGuard(c)
+	}
+}
+
+// errorStats is the number of the requests and errors in a period.
+type errorStats struct {
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+}
+
+func (s errorStats) rate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+type errorBucket struct {
+	second int64
+	errorStats
+}
+
+// errorBuckets counts the requests and errors of the latest seconds.
+type errorBuckets struct {
+	lock    sync.Mutex
+	buckets []errorBucket
+}
+
+func newErrorBuckets(window time.Duration) *errorBuckets {
+	// the buckets keep a window before the update and a window after it
+	return &errorBuckets{buckets: make([]errorBucket, 2*int(window/_rollbackTick)+1)}
+}
+
+func (b *errorBuckets) add(now time.Time, failed bool) {
+	second := now.Unix()
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	bucket := &b.buckets[second%int64(len(b.buckets))]
+	if bucket.second != second {
+		*bucket = errorBucket{second: second}
+	}
+	bucket.Requests++
+	if failed {
+		bucket.Errors++
+	}
+}
+
+// sum returns the stats of the seconds in [from, to).
+func (b *errorBuckets) sum(from, to time.Time) errorStats {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	var stats errorStats
+	for _, bucket := range b.buckets {
+		if bucket.second >= from.Unix() && bucket.second < to.Unix() {
+			stats.Requests += bucket.Requests
+			stats.Errors += bucket.Errors
+		}
+	}
+	return stats
+}
+
+type rollbackVerdict int
+
+const (
+	// rollbackPending is too few requests observed to judge.
+	rollbackPending rollbackVerdict = iota
+	rollbackHealthy
+	rollbackBad
+)
+
+// judgeRollback compares the error rate observed after the update with the baseline before it.
+func judgeRollback(c *RollbackConfig, baseline, observed errorStats) rollbackVerdict {
+	if observed.Requests < c.MinRequests {
+		return rollbackPending
+	}
+	rate := observed.rate()
+	if rate < c.MinErrorRate {
+		return rollbackHealthy
+	}
+	baselineRate := baseline.rate()
+	if baseline.Requests < c.MinRequests {
+		// the gateway was idle before the update, any error rate over the minimum is a spike
+		baselineRate = 0
+	}
+	if rate > baselineRate*c.Threshold {
+		return rollbackBad
+	}
+	return rollbackHealthy
+}
+
+// RollbackStatus is the status of the config rollback.
+type RollbackStatus struct {
+	Observing    bool            `json:"observing"`
+	Version      string          `json:"version,omitempty"`
+	Since        *time.Time      `json:"since,omitempty"`
+	Baseline     errorStats      `json:"baseline"`
+	Observed     errorStats      `json:"observed"`
+	LastRollback *RollbackRecord `json:"last_rollback,omitempty"`
+}
+
+// RollbackRecord is a rollback of a bad config.
+type RollbackRecord struct {
+	Time         time.Time `json:"time"`
+	Version      string    `json:"version"`
+	Restored     string    `json:"restored"`
+	BaselineRate float64   `json:"baseline_rate"`
+	ObservedRate float64   `json:"observed_rate"`
+	Error        string    `json:"error,omitempty"`
+}
+
+type appliedVersion struct {
+	buildContext *client.BuildContext
+	config       *config.Gateway
+}
+
+type rollbackGuard struct {
+	config  RollbackConfig
+	buckets *errorBuckets
+	now     func() time.Time
+
+	// the updates and the rollbacks are serialized by the update lock, which is taken before the lock
+	updateLock sync.Mutex
+	lock       sync.Mutex
+	current    *appliedVersion
+	previous   *appliedVersion
+	cancel     context.CancelFunc
+	status     RollbackStatus
+}
+
+func newRollbackGuard(c RollbackConfig) *rollbackGuard {
+	if c.Window <= 0 {
+		c.Window = _defaultRollbackWindow
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = _defaultRollbackThreshold
+	}
+	if c.MinErrorRate <= 0 {
+		c.MinErrorRate = _defaultRollbackMinErrorRate
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = _defaultRollbackMinRequests
+	}
+	return &rollbackGuard{config: c, buckets: newErrorBuckets(c.Window), now: time.Now}
+}
+
+func (g *rollbackGuard) observer(next Observer) Observer {
+	if g == nil {
+		return next
+	}
+	return &rollbackObserver{Observer: next, guard: g}
+}
+
+type rollbackObserver struct {
+	Observer
+	guard *rollbackGuard
+}
+
+func (o *rollbackObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	o.Observer.HandleRequest(req, responseHeader, statusCode, err)
+	o.guard.buckets.add(o.guard.now(), failedRequest(statusCode, err))
+}
+
+// update applies the config and starts observing it, the previous config is applied again by the update
+// function if the errors spike in the window.
+func (g *rollbackGuard) update(buildContext *client.BuildContext, c *config.Gateway, update func(*client.BuildContext, *config.Gateway) error) error {
+	g.updateLock.Lock()
+	defer g.updateLock.Unlock()
+	if err := update(buildContext, c); err != nil {
+		return err
+	}
+	g.applied(buildContext, c, update)
+	return nil
+}
+
+func (g *rollbackGuard) applied(buildContext *client.BuildContext, c *config.Gateway, update func(*client.BuildContext, *config.Gateway) error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.cancel != nil {
+		g.cancel()
+		g.cancel = nil
+	}
+	g.previous, g.current = g.current, &appliedVersion{buildContext: buildContext, config: c}
+	if g.previous == nil {
+		// nothing to roll back to
+		return
+	}
+	since := g.now()
+	baseline := g.buckets.sum(since.Add(-g.config.Window), since)
+	g.status = RollbackStatus{Observing: true, Version: c.Version, Since: &since, Baseline: baseline, LastRollback: g.status.LastRollback}
+	_metricConfigRollbackObserving.Set(1)
+	_metricConfigRollbackErrorRate.WithLabelValues("baseline").Set(baseline.rate())
+	_metricConfigRollbackErrorRate.WithLabelValues("observed").Set(0)
+	log.Infof("observing config %q for error spikes in %s, baseline error rate %.4f of %d requests", c.Version, g.config.Window, baseline.rate(), baseline.Requests)
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	go g.observe(ctx, g.current, update)
+}
+
+func (g *rollbackGuard) observe(ctx context.Context, current *appliedVersion, update func(*client.BuildContext, *config.Gateway) error) {
+	ticker := time.NewTicker(_rollbackTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if done := g.check(current, update); done {
+			return
+		}
+	}
+}
+
+// check judges the errors observed since the update, true is returned if the observation is finished.
+func (g *rollbackGuard) check(current *appliedVersion, update func(*client.BuildContext, *config.Gateway) error) bool {
+	g.updateLock.Lock()
+	defer g.updateLock.Unlock()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.current != current || !g.status.Observing {
+		return true
+	}
+	now := g.now()
+	since := *g.status.Since
+	observed := g.buckets.sum(since, now)
+	g.status.Observed = observed
+	_metricConfigRollbackErrorRate.WithLabelValues("observed").Set(observed.rate())
+	finished := now.Sub(since) >= g.config.Window
+	switch judgeRollback(&g.config, g.status.Baseline, observed) {
+	case rollbackBad:
+		g.rollback(update)
+		return true
+	case rollbackHealthy, rollbackPending:
+		if finished {
+			log.Infof("config %q is healthy after %s, error rate %.4f of %d requests", current.config.Version, g.config.Window, observed.rate(), observed.Requests)
+			g.status.Observing = false
+			_metricConfigRollbackObserving.Set(0)
+			return true
+		}
+	}
+	return false
+}
+
+// rollback applies the previous config again, the locks are held by the caller.
+func (g *rollbackGuard) rollback(update func(*client.BuildContext, *config.Gateway) error) {
+	bad, previous := g.current, g.previous
+	record := &RollbackRecord{
+		Time:         g.now(),
+		Version:      bad.config.Version,
+		Restored:     previous.config.Version,
+		BaselineRate: g.status.Baseline.rate(),
+		ObservedRate: g.status.Observed.rate(),
+	}
+	log.Errorf("config %q error rate %.4f of %d requests spiked over the baseline %.4f, rolling back to %q",
+		bad.config.Version, record.ObservedRate, g.status.Observed.Requests, record.BaselineRate, previous.config.Version)
+	g.status.Observing = false
+	g.status.LastRollback = record
+	g.cancel = nil
+	_metricConfigRollbackObserving.Set(0)
+	if err := update(previous.buildContext, previous.config); err != nil {
+		log.Errorf("failed to roll back to config %q: %v", previous.config.Version, err)
+		record.Error = err.Error()
+		_metricConfigRollbacks.WithLabelValues("failure").Inc()
+		return
+	}
+	g.current = previous
+	_metricConfigRollbacks.WithLabelValues("success").Inc()
+	if g.config.OnRollback != nil {
+		g.config.OnRollback(bad.config)
+	}
+}
+
+func (g *rollbackGuard) export() *RollbackStatus {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	status := g.status
+	if status.LastRollback != nil {
+		record := *status.LastRollback
+		status.LastRollback = &record
+	}
+	return &status
+}
+
+// RollbackStatus returns the status of the config rollback, nil if the rollback is disabled.
+func (p *Proxy) RollbackStatus() *RollbackStatus {
+	if p.rollback == nil {
+		return nil
+	}
+	return p.rollback.export()
+}
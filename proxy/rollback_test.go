@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestJudgeRollback(t *testing.T) {
+	c := newRollbackGuard(RollbackConfig{Threshold: 2, MinErrorRate: 0.05, MinRequests: 100}).config
+	tests := []struct {
+		name     string
+		baseline errorStats
+		observed errorStats
+		want     rollbackVerdict
+	}{
+		{"too few requests", errorStats{1000, 0}, errorStats{99, 99}, rollbackPending},
+		{"below the min error rate", errorStats{1000, 0}, errorStats{1000, 49}, rollbackHealthy},
+		{"spike over the baseline", errorStats{1000, 10}, errorStats{1000, 100}, rollbackBad},
+		{"errors as before", errorStats{1000, 80}, errorStats{1000, 100}, rollbackHealthy},
+		{"idle before the update", errorStats{10, 0}, errorStats{200, 20}, rollbackBad},
+	}
+	for _, tt := range tests {
+		if got := judgeRollback(&c, tt.baseline, tt.observed); got != tt.want {
+			t.Errorf("%s: want verdict %d but got %d", tt.name, tt.want, got)
+		}
+	}
+}
+
+// syntheticStream feeds the requests of the error rate into the guard, one request every 10ms.
+func syntheticStream(g *rollbackGuard, now *time.Time, d time.Duration, errorRate float64) {
+	obs := g.observer(&noopObserver{})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	var errs float64
+	for end := now.Add(d); now.Before(end); *now = now.Add(10 * time.Millisecond) {
+		errs += errorRate
+		if errs >= 1 {
+			errs--
+			obs.HandleRequest(req, nil, http.StatusBadGateway, nil)
+			continue
+		}
+		obs.HandleRequest(req, nil, http.StatusOK, nil)
+	}
+}
+
+type noopObserver struct{}
+
+func (noopObserver) HandleRetry(*http.Request, http.Header, string)       {}
+func (noopObserver) HandleRequest(*http.Request, http.Header, int, error) {}
+func (noopObserver) HandleSentBytes(*http.Request, int64)                 {}
+func (noopObserver) HandleReceivedBytes(*http.Request, int64)             {}
+func (noopObserver) HandleLatency(*http.Request, time.Duration)           {}
+
+func TestRollbackGuard(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var applied []string
+	var bad []string
+	update := func(_ *client.BuildContext, c *config.Gateway) error {
+		applied = append(applied, c.Version)
+		return nil
+	}
+	newGuard := func() *rollbackGuard {
+		g := newRollbackGuard(RollbackConfig{
+			Window:     10 * time.Second,
+			OnRollback: func(c *config.Gateway) { bad = append(bad, c.Version) },
+		})
+		g.now = func() time.Time { return now }
+		return g
+	}
+	check := func(g *rollbackGuard) {
+		// the observation goroutine is driven by the test instead
+		g.lock.Lock()
+		current := g.current
+		if g.cancel != nil {
+			g.cancel()
+		}
+		g.lock.Unlock()
+		g.check(current, update)
+	}
+
+	g := newGuard()
+	syntheticStream(g, &now, 10*time.Second, 0.01)
+	if err := g.update(nil, &config.Gateway{Version: "v1"}, update); err != nil {
+		t.Fatal(err)
+	}
+	if g.export().Observing {
+		t.Fatal("want the first config not observed")
+	}
+	if err := g.update(nil, &config.Gateway{Version: "v2"}, update); err != nil {
+		t.Fatal(err)
+	}
+	syntheticStream(g, &now, 3*time.Second, 0.5)
+	check(g)
+	status := g.export()
+	if status.Observing || status.LastRollback == nil || status.LastRollback.Restored != "v1" {
+		t.Fatalf("want the config rolled back but got %+v", status)
+	}
+	if len(bad) != 1 || bad[0] != "v2" || applied[len(applied)-1] != "v1" {
+		t.Fatalf("want v1 applied again and v2 marked bad but got %v %v", applied, bad)
+	}
+
+	// the errors as many as before the update
+	bad = nil
+	g = newGuard()
+	syntheticStream(g, &now, 10*time.Second, 0.2)
+	g.update(nil, &config.Gateway{Version: "v1"}, update)
+	g.update(nil, &config.Gateway{Version: "v2"}, update)
+	syntheticStream(g, &now, 5*time.Second, 0.2)
+	check(g)
+	if !g.export().Observing {
+		t.Fatal("want the config observed until the end of the window")
+	}
+	syntheticStream(g, &now, 5*time.Second, 0.2)
+	check(g)
+	if status := g.export(); status.Observing || status.LastRollback != nil || len(bad) != 0 {
+		t.Fatalf("want the config kept but got %+v", status)
+	}
+}
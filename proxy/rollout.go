@@ -0,0 +1,254 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/features"
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const _defaultRolloutInitialPercent = 1
+
+var _metricRolloutPercent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "priority_rollout_percent",
+	Help:      "The percentage of the matched requests served by the priority config under rollout",
+}, []string{"name"})
+
+func init() {
+	goddessmetrics.Register(_metricRolloutPercent)
+}
+
+type rolloutStep struct {
+	after   time.Duration
+	percent float64
+}
+
+// rollout selects the requests served by the endpoints of a priority config by the stable bucket of the request.
+type rollout struct {
+	name    string
+	start   time.Time
+	ramp    time.Duration
+	steps   []rolloutStep
+	initial float64
+	key     func(*http.Request) string
+	gauge   prometheus.Gauge
+}
+
+func newRollout(c *config.PriorityRollout) (*rollout, error) {
+	r := &rollout{
+		name:    c.Name,
+		start:   c.Rollout.GetStartTime().AsTime(),
+		ramp:    c.Rollout.GetRampDuration().AsDuration(),
+		initial: c.Rollout.GetInitialPercent(),
+		gauge:   _metricRolloutPercent.WithLabelValues(c.Name),
+	}
+	if r.initial <= 0 {
+		r.initial = _defaultRolloutInitialPercent
+	}
+	for _, step := range c.Rollout.GetSteps() {
+		if step.Percent < 0 || step.Percent > 100 {
+			return nil, fmt.Errorf("rollout %s: invalid step percent: %v", c.Name, step.Percent)
+		}
+		r.steps = append(r.steps, rolloutStep{after: step.After.AsDuration(), percent: step.Percent})
+	}
+	sort.SliceStable(r.steps, func(i, j int) bool { return r.steps[i].after < r.steps[j].after })
+	key, err := newRolloutKey(c.Rollout.GetHashKey())
+	if err != nil {
+		return nil, fmt.Errorf("rollout %s: %w", c.Name, err)
+	}
+	r.key = key
+	return r, nil
+}
+
+// newRolloutKey parses the hash key, eg: "header:X-User-Id", the client ip is used if the attribute is missing.
+func newRolloutKey(in string) (func(*http.Request) string, error) {
+	var attr func(*http.Request) string
+	kind, name, _ := strings.Cut(in, ":")
+	switch {
+	case in == "":
+		attr = func(*http.Request) string { return "" }
+	case kind == "header" && name != "":
+		attr = func(req *http.Request) string { return req.Header.Get(name) }
+	case kind == "query" && name != "":
+		attr = func(req *http.Request) string { return req.URL.Query().Get(name) }
+	case kind == "cookie" && name != "":
+		attr = func(req *http.Request) string {
+			if c, err := req.Cookie(name); err == nil {
+				return c.Value
+			}
+			return ""
+		}
+	default:
+		return nil, fmt.Errorf("invalid hash key: %q", in)
+	}
+	return func(req *http.Request) string {
+		if v := attr(req); v != "" {
+			return v
+		}
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			return req.RemoteAddr
+		}
+		return host
+	}, nil
+}
+
+// percent returns the percentage of the requests served by the priority config at the time.
+func (r *rollout) percent(now time.Time) float64 {
+	if features.RolloutAbort.Enabled() || now.Before(r.start) {
+		return 0
+	}
+	elapsed := now.Sub(r.start)
+	if len(r.steps) > 0 {
+		p := r.initial
+		for _, step := range r.steps {
+			if elapsed < step.after {
+				break
+			}
+			p = step.percent
+		}
+		return p
+	}
+	if r.ramp <= 0 {
+		return 100
+	}
+	return math.Min(100, r.initial+(100-r.initial)*float64(elapsed)/float64(r.ramp))
+}
+
+// bucket returns the stable bucket of the request in [0, 100).
+func (r *rollout) bucket(req *http.Request) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(r.name))
+	h.Write([]byte{0})
+	h.Write([]byte(r.key(req)))
+	return float64(h.Sum64()%10000) / 100
+}
+
+// rolloutHandler serves the requests in the rollout by the priority config and the others by the baseline.
+type rolloutHandler struct {
+	rollout  *rollout
+	canary   http.Handler
+	baseline http.Handler
+}
+
+func (h *rolloutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	percent := h.rollout.percent(time.Now())
+	h.rollout.gauge.Set(percent)
+	if h.rollout.bucket(req) < percent {
+		h.canary.ServeHTTP(w, req)
+		return
+	}
+	h.baseline.ServeHTTP(w, req)
+}
+
+// RolloutStatus is the live percentage of a priority config under rollout.
+type RolloutStatus struct {
+	Name      string    `json:"name"`
+	Endpoints []string  `json:"endpoints"`
+	StartTime time.Time `json:"start_time"`
+	Percent   float64   `json:"percent"`
+	Aborted   bool      `json:"aborted,omitempty"`
+}
+
+// rolloutSet is the rollouts of the applied config.
+type rolloutSet struct {
+	rollouts map[string]*rollout
+	routes   map[string][]string
+}
+
+func (s *rolloutSet) add(e *config.Endpoint, r *rollout) {
+	if s.rollouts == nil {
+		s.rollouts, s.routes = map[string]*rollout{}, map[string][]string{}
+	}
+	s.rollouts[r.name] = r
+	s.routes[r.name] = append(s.routes[r.name], strings.TrimSpace(e.Method+" "+e.Path))
+}
+
+// statuses returns the rollouts sorted by name.
+func (s *rolloutSet) statuses(now time.Time) []*RolloutStatus {
+	out := make([]*RolloutStatus, 0, len(s.rollouts))
+	for name, r := range s.rollouts {
+		out = append(out, &RolloutStatus{
+			Name:      name,
+			Endpoints: s.routes[name],
+			StartTime: r.start,
+			Percent:   r.percent(now),
+			Aborted:   features.RolloutAbort.Enabled(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// observe sets the percentage metrics of the rollouts and deletes the ones of the previous rollouts removed.
+func (s *rolloutSet) observe(prev *rolloutSet, now time.Time) {
+	if prev != nil {
+		for name := range prev.rollouts {
+			if _, ok := s.rollouts[name]; !ok {
+				_metricRolloutPercent.DeleteLabelValues(name)
+			}
+		}
+	}
+	for _, r := range s.rollouts {
+		r.gauge.Set(r.percent(now))
+	}
+}
+
+// Rollouts returns the live percentages of the priority configs under rollout.
+func (p *Proxy) Rollouts() []*RolloutStatus {
+	s, _ := p.rollouts.Load().(*rolloutSet)
+	if s == nil {
+		return []*RolloutStatus{}
+	}
+	return s.statuses(time.Now())
+}
+
+// buildRollout wraps the handler of the endpoint under rollout, the requests out of the rollout are served
+// by the baseline endpoint or the not found handler. The returned closer closes the baseline endpoint.
+func (p *Proxy) buildRollout(ctx context.Context, buildCtx *client.BuildContext, gw *gatewayContext, e *config.Endpoint, canary, notFound http.Handler) (http.Handler, io.Closer, *rollout, error) {
+	r, err := newRollout(e.PriorityRollout)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h := &rolloutHandler{rollout: r, canary: canary, baseline: notFound}
+	baseline := e.PriorityRollout.Baseline
+	if baseline == nil {
+		return h, nopCloser{}, r, nil
+	}
+	baseline, err = gw.hostGroups.resolve(baseline)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	handler, closer, err := p.buildEndpoint(ctx, buildCtx, gw, baseline)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	h.baseline = handler
+	return h, closer, r, nil
+}
+
+// multiCloser closes all the closers, the errors of them are joined.
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/features"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRolloutPercent(t *testing.T) {
+	start := time.Now()
+	ramp, err := newRollout(&config.PriorityRollout{Name: "ramp", Rollout: &config.Rollout{
+		StartTime:    timestamppb.New(start),
+		RampDuration: durationpb.New(time.Hour),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps, err := newRollout(&config.PriorityRollout{Name: "steps", Rollout: &config.Rollout{
+		StartTime: timestamppb.New(start),
+		Steps: []*config.RolloutStep{
+			{After: durationpb.New(10 * time.Minute), Percent: 50},
+			{After: durationpb.New(0), Percent: 5},
+			{After: durationpb.New(time.Hour), Percent: 100},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		r       *rollout
+		elapsed time.Duration
+		want    float64
+	}{
+		{ramp, -time.Minute, 0},
+		{ramp, 0, 1},
+		{ramp, 30 * time.Minute, 50.5},
+		{ramp, 2 * time.Hour, 100},
+		{steps, time.Minute, 5},
+		{steps, 10 * time.Minute, 50},
+		{steps, 90 * time.Minute, 100},
+	}
+	for _, test := range tests {
+		if got := test.r.percent(start.Add(test.elapsed)); got != test.want {
+			t.Errorf("%s: want %v%% after %s but got %v%%", test.r.name, test.want, test.elapsed, got)
+		}
+	}
+	if _, err := newRollout(&config.PriorityRollout{Name: "x", Rollout: &config.Rollout{HashKey: "body:id"}}); err == nil {
+		t.Fatal("want the invalid hash key rejected")
+	}
+}
+
+func TestRollout(t *testing.T) {
+	withPercent := func(percent float64) *config.Gateway {
+		baseline := &config.Endpoint{Protocol: config.Protocol_HTTP, Path: "/api", Method: "GET", Metadata: map[string]string{"version": "baseline"}}
+		return &config.Gateway{
+			Name: "Test",
+			Endpoints: []*config.Endpoint{{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/api",
+				Method:   "GET",
+				Metadata: map[string]string{"version": "canary"},
+				PriorityRollout: &config.PriorityRollout{
+					Name: "canary",
+					Rollout: &config.Rollout{
+						StartTime: timestamppb.New(time.Now().Add(-time.Minute)),
+						Steps:     []*config.RolloutStep{{After: durationpb.New(0), Percent: percent}},
+						HashKey:   "header:X-User-Id",
+					},
+					Baseline: baseline,
+				},
+			}},
+		}
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		version := e.Metadata["version"]
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(version))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := func() map[string]int {
+		out := map[string]int{}
+		for i := 0; i < 200; i++ {
+			req := httptest.NewRequest(http.MethodGet, "/api", nil)
+			req.Header.Set("X-User-Id", strconv.Itoa(i))
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, req)
+			out[w.Body.String()]++
+		}
+		return out
+	}
+	c := withPercent(0)
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if got := count(); got["baseline"] != 200 {
+		t.Fatalf("want all requests served by the baseline but got %v", got)
+	}
+	c = withPercent(30)
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	got := count()
+	if got["canary"] < 30 || got["canary"] > 90 || got["canary"]+got["baseline"] != 200 {
+		t.Fatalf("want about 30%% requests served by the canary but got %v", got)
+	}
+	// the same user is always in the same bucket
+	if again := count(); again["canary"] != got["canary"] {
+		t.Fatalf("want the stable buckets but got %v and %v", got, again)
+	}
+	if status := p.Rollouts(); len(status) != 1 || status[0].Percent != 30 || status[0].Endpoints[0] != "GET /api" {
+		t.Fatalf("unexpected rollout status: %+v", status)
+	}
+
+	if err := features.Apply(map[string]json.RawMessage{"gw:PriorityRolloutAbort": json.RawMessage("true")}); err != nil {
+		t.Fatal(err)
+	}
+	defer features.Apply(map[string]json.RawMessage{"gw:PriorityRolloutAbort": json.RawMessage("false")})
+	if got := count(); got["baseline"] != 200 {
+		t.Fatalf("want the aborted rollout served by the baseline but got %v", got)
+	}
+}
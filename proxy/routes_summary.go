@@ -0,0 +1,213 @@
+package proxy
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aide-family/goddess/client"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+// the routes listed by each entry of the summary, the counts are exact
+const _routesSummaryLimit = 20
+
+// _securityMiddlewares are the middlewares the routes are counted with and without, eg: to alert on the routes
+// without jwt.
+var _securityMiddlewares = []string{"jwt", "namespace", "quota", "identity", "cors"}
+
+var (
+	_metricRoutes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "routes",
+		Help:      "The number of the routes of the applied config by protocol",
+	}, []string{"protocol"})
+	_metricRoutesFeature = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "routes_feature",
+		Help:      "The number of the routes of the applied config with the feature: retries, streaming or http1_upstream",
+	}, []string{"feature"})
+	_metricRoutesWithoutMiddleware = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "routes_without_middleware",
+		Help:      "The number of the routes of the applied config without the security relevant middleware",
+	}, []string{"middleware"})
+	// the summary of the last update, the zero nodes are counted on scrape
+	_lastRoutesSummary     atomic.Pointer[routesSummary]
+	_metricRoutesZeroNodes = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "routes_zero_nodes",
+		Help:      "The number of the routes whose backend discovery currently resolves zero nodes",
+	}, func() float64 {
+		s := _lastRoutesSummary.Load()
+		if s == nil {
+			return 0
+		}
+		return float64(s.zeroNodes().Count)
+	})
+)
+
+func init() {
+	goddessmetrics.Register(_metricRoutes, _metricRoutesFeature, _metricRoutesWithoutMiddleware, _metricRoutesZeroNodes)
+}
+
+// RouteList is the number of the routes and the first of them.
+type RouteList struct {
+	Count  int      `json:"count"`
+	Routes []string `json:"routes,omitempty"`
+}
+
+func (l *RouteList) add(route string) {
+	l.Count++
+	if len(l.Routes) < _routesSummaryLimit {
+		l.Routes = append(l.Routes, route)
+	}
+}
+
+// MiddlewareRoutes are the routes with and without the middleware in their chains.
+type MiddlewareRoutes struct {
+	With    RouteList `json:"with"`
+	Without RouteList `json:"without"`
+}
+
+// RoutesSummary is the aggregate of the routes of the applied config, it is computed by the updates except
+// the routes of zero nodes counted when it is read.
+type RoutesSummary struct {
+	UpdatedAt     time.Time                    `json:"updated_at"`
+	Routes        int                          `json:"routes"`
+	Protocols     map[string]*RouteList        `json:"protocols"`
+	Retries       RouteList                    `json:"retries"`
+	Streaming     RouteList                    `json:"streaming"`
+	HTTP1Upstream RouteList                    `json:"http1_upstream"`
+	Middlewares   map[string]*MiddlewareRoutes `json:"middlewares"`
+	ZeroNodes     RouteList                    `json:"zero_nodes"`
+}
+
+type routeNodes struct {
+	route     string
+	inspector client.NodeFilterInspector
+}
+
+type routesSummary struct {
+	summary RoutesSummary
+	nodes   []routeNodes
+}
+
+func newRoutesSummary() *routesSummary {
+	s := &routesSummary{summary: RoutesSummary{
+		Protocols:   map[string]*RouteList{},
+		Middlewares: make(map[string]*MiddlewareRoutes, len(_securityMiddlewares)),
+	}}
+	for _, name := range _securityMiddlewares {
+		s.summary.Middlewares[name] = &MiddlewareRoutes{}
+	}
+	return s
+}
+
+func routeName(e *config.Endpoint) string {
+	method := e.Method
+	if method == "" {
+		method = "*"
+	}
+	return method + " " + e.Host + e.Path
+}
+
+// add counts the route, the middlewares are the names of the effective chain of the route.
+func (s *routesSummary) add(e *config.Endpoint, middlewares []string, nodes client.NodeFilterInspector) {
+	route := routeName(e)
+	sum := &s.summary
+	sum.Routes++
+	protocol := e.Protocol.String()
+	if sum.Protocols[protocol] == nil {
+		sum.Protocols[protocol] = &RouteList{}
+	}
+	sum.Protocols[protocol].add(route)
+	if calcAttempts(e) > 1 {
+		sum.Retries.add(route)
+	}
+	if e.Stream {
+		sum.Streaming.add(route)
+	}
+	if http1Upstream(e) {
+		sum.HTTP1Upstream.add(route)
+	}
+	for name, routes := range sum.Middlewares {
+		if slices.ContainsFunc(middlewares, func(m string) bool { return strings.EqualFold(m, name) }) {
+			routes.With.add(route)
+		} else {
+			routes.Without.add(route)
+		}
+	}
+	if nodes != nil {
+		s.nodes = append(s.nodes, routeNodes{route: route, inspector: nodes})
+	}
+}
+
+// http1Upstream reports whether the route connects its backends by HTTP/1 only, eg: the AUTO protocol of the
+// plaintext backends.
+func http1Upstream(e *config.Endpoint) bool {
+	switch e.UpstreamProtocol {
+	case config.UpstreamProtocol_HTTP1:
+		return true
+	case config.UpstreamProtocol_AUTO:
+		if e.Protocol == config.Protocol_GRPC {
+			return false
+		}
+		for _, b := range e.Backends {
+			if b.Tls {
+				return false
+			}
+		}
+		return len(e.Backends) > 0
+	}
+	return false
+}
+
+func (s *routesSummary) zeroNodes() RouteList {
+	var out RouteList
+	for _, n := range s.nodes {
+		if n.inspector.NodeFilterInspect().Nodes == 0 {
+			out.add(n.route)
+		}
+	}
+	return out
+}
+
+// observe sets the gauges of the summary of the applied config.
+func (s *routesSummary) observe(now time.Time) {
+	s.summary.UpdatedAt = now
+	_metricRoutes.Reset()
+	for protocol, routes := range s.summary.Protocols {
+		_metricRoutes.WithLabelValues(protocol).Set(float64(routes.Count))
+	}
+	_metricRoutesFeature.WithLabelValues("retries").Set(float64(s.summary.Retries.Count))
+	_metricRoutesFeature.WithLabelValues("streaming").Set(float64(s.summary.Streaming.Count))
+	_metricRoutesFeature.WithLabelValues("http1_upstream").Set(float64(s.summary.HTTP1Upstream.Count))
+	for name, routes := range s.summary.Middlewares {
+		_metricRoutesWithoutMiddleware.WithLabelValues(name).Set(float64(routes.Without.Count))
+	}
+	_lastRoutesSummary.Store(s)
+}
+
+func (s *routesSummary) export() *RoutesSummary {
+	out := s.summary
+	out.ZeroNodes = s.zeroNodes()
+	return &out
+}
+
+// RoutesSummary returns the summary of the routes of the applied config, nil before the first update.
+func (p *Proxy) RoutesSummary() *RoutesSummary {
+	s := p.routesSummary.Load()
+	if s == nil {
+		return nil
+	}
+	return s.export()
+}
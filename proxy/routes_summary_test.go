@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+type nodesClient struct {
+	RoundTripperCloserFunc
+	nodes int
+}
+
+func (c *nodesClient) NodeFilterInspect() *client.NodeFilterInspect {
+	return &client.NodeFilterInspect{Nodes: c.nodes, Matching: c.nodes}
+}
+
+func TestRoutesSummary(t *testing.T) {
+	clients := map[string]*nodesClient{}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		c := &nodesClient{RoundTripperCloserFunc: func(*http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}, nil
+		}, nodes: 1}
+		clients[e.Path] = c
+		return c, nil
+	}
+	middlewareFactory := func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.RoutesSummary() != nil {
+		t.Fatal("want no summary before the first update")
+	}
+	c := &config.Gateway{
+		Name:        "Test",
+		Middlewares: []*config.Middleware{{Name: "cors"}},
+		Endpoints: []*config.Endpoint{
+			{
+				Protocol:    config.Protocol_HTTP,
+				Path:        "/orders",
+				Method:      "GET",
+				Backends:    []*config.Backend{{Target: "127.0.0.1:8000"}},
+				Middlewares: []*config.Middleware{{Name: "jwt"}},
+				Retry:       &config.Retry{Attempts: 3},
+			},
+			{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/events",
+				Stream:   true,
+				Backends: []*config.Backend{{Target: "127.0.0.1:8000", Tls: true}},
+			},
+			{
+				Protocol: config.Protocol_GRPC,
+				Path:     "/helloworld.Greeter/*",
+				Method:   "POST",
+				Backends: []*config.Backend{{Target: "127.0.0.1:9000"}},
+			},
+		},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	clients["/events"].nodes = 0
+	s := p.RoutesSummary()
+	if s.Routes != 3 || s.Protocols["HTTP"].Count != 2 || s.Protocols["GRPC"].Count != 1 {
+		t.Fatalf("unexpected protocols: %+v", s.Protocols)
+	}
+	if s.Retries.Count != 1 || s.Retries.Routes[0] != "GET /orders" || s.Streaming.Routes[0] != "* /events" {
+		t.Fatalf("unexpected retries %+v and streaming %+v", s.Retries, s.Streaming)
+	}
+	if s.HTTP1Upstream.Count != 1 || s.HTTP1Upstream.Routes[0] != "GET /orders" {
+		t.Fatalf("want the plaintext HTTP route of HTTP/1 upstream but got %+v", s.HTTP1Upstream)
+	}
+	if jwt := s.Middlewares["jwt"]; jwt.With.Count != 1 || jwt.Without.Count != 2 || s.Middlewares["cors"].Without.Count != 0 {
+		t.Fatalf("unexpected middlewares: jwt %+v cors %+v", jwt, s.Middlewares["cors"])
+	}
+	if s.ZeroNodes.Count != 1 || s.ZeroNodes.Routes[0] != "* /events" {
+		t.Fatalf("want the route of zero nodes counted when read but got %+v", s.ZeroNodes)
+	}
+	if got := testutil.ToFloat64(_metricRoutesWithoutMiddleware.WithLabelValues("jwt")); got != 2 {
+		t.Fatalf("want 2 routes without jwt but got %v", got)
+	}
+	if got := testutil.ToFloat64(_metricRoutesZeroNodes); got != 1 {
+		t.Fatalf("want 1 route of zero nodes but got %v", got)
+	}
+}
+
+func TestRouteListLimit(t *testing.T) {
+	var l RouteList
+	for i := 0; i < _routesSummaryLimit+5; i++ {
+		l.add("GET /")
+	}
+	if l.Count != _routesSummaryLimit+5 || len(l.Routes) != _routesSummaryLimit {
+		t.Fatalf("want the count exact and the routes capped but got %d %d", l.Count, len(l.Routes))
+	}
+}
@@ -0,0 +1,142 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	_securityHeaderOff = "off"
+	_defaultHSTSMaxAge = 365 * 24 * time.Hour
+	_cspHeader         = "Content-Security-Policy"
+)
+
+// securityHeaders sets the security headers of the responses of the non-gRPC endpoints.
+type securityHeaders struct {
+	// the headers set in order, the values are never empty
+	keys   []string
+	values []string
+	force  bool
+}
+
+func newSecurityHeaders(gw *gatewayContext, e *config.Endpoint) (*securityHeaders, error) {
+	base, override := gw.securityHeaders, e.SecurityHeaders
+	if e.Protocol == config.Protocol_GRPC || (base == nil && override == nil) || override.GetDisabled() {
+		return nil, nil
+	}
+	if base == nil {
+		base = &config.SecurityHeaders{}
+	}
+	if base.Disabled && override == nil {
+		return nil, nil
+	}
+	value := func(name string, get func(*config.SecurityHeaders) string, def string) (string, string) {
+		v := def
+		if s := get(base); s != "" {
+			v = s
+		}
+		if s := get(override); s != "" {
+			v = s
+		}
+		if v == _securityHeaderOff {
+			v = ""
+		}
+		return name, v
+	}
+	s := &securityHeaders{force: base.Force || override.GetForce()}
+	add := func(name, value string) {
+		if value != "" {
+			s.keys = append(s.keys, name)
+			s.values = append(s.values, value)
+		}
+	}
+	hsts := base.Hsts
+	if override.GetHsts() != nil {
+		hsts = override.Hsts
+	}
+	hstsValue, err := hstsHeader(hsts)
+	if err != nil {
+		return nil, err
+	}
+	add("Strict-Transport-Security", hstsValue)
+	add(value("X-Content-Type-Options", (*config.SecurityHeaders).GetContentTypeOptions, "nosniff"))
+	add(value("Referrer-Policy", (*config.SecurityHeaders).GetReferrerPolicy, "strict-origin-when-cross-origin"))
+	add(value("X-Frame-Options", (*config.SecurityHeaders).GetFrameOptions, "DENY"))
+	if name, ancestors := value(_cspHeader, (*config.SecurityHeaders).GetFrameAncestors, ""); ancestors != "" {
+		add(name, "frame-ancestors "+ancestors)
+	}
+	add(value("Permissions-Policy", (*config.SecurityHeaders).GetPermissionsPolicy, "camera=(), microphone=(), geolocation=()"))
+	return s, nil
+}
+
+func hstsHeader(c *config.HSTS) (string, error) {
+	if c.GetDisabled() {
+		return "", nil
+	}
+	maxAge := _defaultHSTSMaxAge
+	if c.GetMaxAge() != nil {
+		maxAge = c.MaxAge.AsDuration()
+	}
+	if maxAge < 0 {
+		return "", fmt.Errorf("security headers: hsts max_age must not be negative: %s", maxAge)
+	}
+	if c.GetPreload() && (!c.IncludeSubdomains || maxAge < _defaultHSTSMaxAge) {
+		return "", fmt.Errorf("security headers: hsts preload requires include_subdomains and max_age of at least %s", _defaultHSTSMaxAge)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "max-age=%d", int64(maxAge/time.Second))
+	if c.GetIncludeSubdomains() {
+		b.WriteString("; includeSubDomains")
+	}
+	if c.GetPreload() {
+		b.WriteString("; preload")
+	}
+	return b.String(), nil
+}
+
+// Write sets the headers not set by the upstream, or all of them if forced, the gRPC requests of the
+// non-gRPC endpoints are skipped as well. The forced frame-ancestors is merged into the Content-Security-Policy
+// of the upstream instead of replacing the other directives of it.
+func (s *securityHeaders) Write(req *http.Request, h http.Header) {
+	if s == nil || strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		return
+	}
+	for i, key := range s.keys {
+		existing := h.Values(key)
+		if len(existing) > 0 {
+			if !s.force {
+				continue
+			}
+			if key == _cspHeader {
+				h[key] = mergeCSPDirective(existing, s.values[i])
+				continue
+			}
+		}
+		h.Set(key, s.values[i])
+	}
+}
+
+// mergeCSPDirective replaces the directive of the same name in each of the policies, or appends it.
+func mergeCSPDirective(policies []string, directive string) []string {
+	name, _, _ := strings.Cut(directive, " ")
+	merged := make([]string, 0, len(policies))
+	for _, policy := range policies {
+		directives := []string{}
+		for _, d := range strings.Split(policy, ";") {
+			d = strings.TrimSpace(d)
+			if d == "" {
+				continue
+			}
+			if n, _, _ := strings.Cut(d, " "); strings.EqualFold(n, name) {
+				continue
+			}
+			directives = append(directives, d)
+		}
+		merged = append(merged, strings.Join(append(directives, directive), "; "))
+	}
+	return merged
+}
@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"X-Frame-Options": []string{"SAMEORIGIN"}}
+			if req.URL.Path == "/csp" {
+				header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors *; img-src *")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{
+		SecurityHeaders: &config.SecurityHeaders{
+			Hsts:           &config.HSTS{MaxAge: durationpb.New(time.Hour), IncludeSubdomains: true},
+			ReferrerPolicy: "no-referrer",
+		},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/default",
+			Method:   "GET",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/stream",
+			Method:   "GET",
+			Stream:   true,
+		}, {
+			Protocol:        config.Protocol_HTTP,
+			Path:            "/override",
+			Method:          "GET",
+			SecurityHeaders: &config.SecurityHeaders{Force: true, PermissionsPolicy: "off", FrameAncestors: "'self'"},
+		}, {
+			Protocol:        config.Protocol_HTTP,
+			Path:            "/csp",
+			Method:          "GET",
+			SecurityHeaders: &config.SecurityHeaders{Force: true, FrameAncestors: "'none'"},
+		}, {
+			Protocol:        config.Protocol_HTTP,
+			Path:            "/disabled",
+			Method:          "GET",
+			SecurityHeaders: &config.SecurityHeaders{Disabled: true},
+		}, {
+			Protocol: config.Protocol_GRPC,
+			Path:     "/grpc",
+			Method:   "POST",
+		}},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	do := func(method, path string) http.Header {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w.Result().Header
+	}
+
+	for _, path := range []string{"/default", "/stream"} {
+		h := do(http.MethodGet, path)
+		for key, want := range map[string]string{
+			"Strict-Transport-Security": "max-age=3600; includeSubDomains",
+			"X-Content-Type-Options":    "nosniff",
+			"Referrer-Policy":           "no-referrer",
+			// set by the upstream
+			"X-Frame-Options":         "SAMEORIGIN",
+			"Permissions-Policy":      "camera=(), microphone=(), geolocation=()",
+			"Content-Security-Policy": "",
+		} {
+			if got := h.Values(key); strings.Join(got, ",") != want {
+				t.Fatalf("%s: %s want %q but got %q", path, key, want, got)
+			}
+		}
+	}
+	h := do(http.MethodGet, "/override")
+	if got := h.Get("X-Frame-Options"); got != "DENY" {
+		t.Fatalf("want forced X-Frame-Options but got %q", got)
+	}
+	if got := h.Get("Content-Security-Policy"); got != "frame-ancestors 'self'" {
+		t.Fatalf("unexpected Content-Security-Policy %q", got)
+	}
+	if got := h.Get("Permissions-Policy"); got != "" {
+		t.Fatalf("want Permissions-Policy off but got %q", got)
+	}
+	// the forced frame-ancestors keeps the other directives of the upstream policy
+	if got := do(http.MethodGet, "/csp").Get("Content-Security-Policy"); got != "default-src 'self'; img-src *; frame-ancestors 'none'" {
+		t.Fatalf("want frame-ancestors merged into the upstream policy but got %q", got)
+	}
+	for _, tc := range []struct{ method, path string }{{http.MethodGet, "/disabled"}, {http.MethodPost, "/grpc"}} {
+		if got := do(tc.method, tc.path).Get("X-Content-Type-Options"); got != "" {
+			t.Fatalf("%s: want no security headers but got %q", tc.path, got)
+		}
+	}
+}
+
+func TestSecurityHeadersInvalidHSTS(t *testing.T) {
+	gw := &gatewayContext{securityHeaders: &config.SecurityHeaders{Hsts: &config.HSTS{Preload: true}}}
+	if _, err := newSecurityHeaders(gw, &config.Endpoint{}); err == nil {
+		t.Fatal("want the error of preload without include_subdomains")
+	}
+}
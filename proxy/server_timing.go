@@ -0,0 +1,129 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const serverTimingHeader = "Server-Timing"
+
+var (
+	// the connect phase is recorded by go_gateway_upstream_conn_wait_seconds of the client for all the endpoints
+	_metricUpstreamTTFBSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "upstream_ttfb_seconds",
+		Help:      "The time from the upstream connection to the first response byte of the attempts of the endpoints with the server timing",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 15),
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricUpstreamTTFBSeconds)
+}
+
+var errServerTimingValues = errors.New("server_timing: values must not be empty, the timings are only written to the trusted requests")
+
+// serverTiming traces the upstream attempts of the endpoint and emits the phases in the Server-Timing header
+// of the trusted requests.
+type serverTiming struct {
+	// the header is not emitted without the trigger, the ttfb is still recorded
+	trusted *upstreamDebugHeaders
+	labels  []string
+}
+
+func newServerTiming(e *config.Endpoint) (*serverTiming, error) {
+	c := e.ServerTiming
+	if !c.GetEnabled() {
+		return nil, nil
+	}
+	s := &serverTiming{labels: []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"]}}
+	if c.Header != "" {
+		if len(c.Values) == 0 {
+			return nil, errServerTimingValues
+		}
+		s.trusted = newDebugTrigger(c.Header, c.Values)
+	}
+	return s, nil
+}
+
+// upstreamTrace is the connect and ttfb of the last upstream attempt.
+type upstreamTrace struct {
+	timing *serverTiming
+	// whether the header is emitted
+	emit bool
+
+	getConn atomic.Int64
+	gotConn atomic.Int64
+	connect atomic.Int64
+	ttfb    atomic.Int64
+}
+
+// start traces the upstream attempts sent with the returned context, nil is returned if disabled.
+func (s *serverTiming) start(req *http.Request) (context.Context, *upstreamTrace) {
+	if s == nil {
+		return req.Context(), nil
+	}
+	t := &upstreamTrace{timing: s, emit: s.trusted.trusted(req)}
+	s.trusted.strip(req)
+	return httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GetConn: func(string) {
+			t.getConn.Store(time.Now().UnixNano())
+		},
+		GotConn: func(httptrace.GotConnInfo) {
+			now := time.Now().UnixNano()
+			t.gotConn.Store(now)
+			if start := t.getConn.Load(); start > 0 {
+				t.connect.Store(now - start)
+			}
+		},
+		GotFirstResponseByte: func() {
+			if start := t.gotConn.Load(); start > 0 {
+				ttfb := time.Duration(time.Now().UnixNano() - start)
+				t.ttfb.Store(int64(ttfb))
+				_metricUpstreamTTFBSeconds.WithLabelValues(s.labels...).Observe(ttfb.Seconds())
+			}
+		},
+	}), t
+}
+
+// writeHeader sets the phases known before the response body into the header.
+func (t *upstreamTrace) writeHeader(header http.Header, timings *phaseTimings, attempts int, start time.Time) {
+	if t == nil || !t.emit {
+		return
+	}
+	chain, upstream := time.Duration(timings.chain.Load()), time.Duration(timings.upstream.Load())
+	metrics := []string{
+		serverTimingMetric("mw", max(chain-upstream, 0)),
+		serverTimingMetric("connect", time.Duration(t.connect.Load())),
+		serverTimingMetric("ttfb", time.Duration(t.ttfb.Load())),
+		fmt.Sprintf(`retries;desc="%d"`, max(attempts-1, 0)),
+		serverTimingMetric("total", time.Since(start)),
+	}
+	header.Set(serverTimingHeader, strings.Join(metrics, ", "))
+}
+
+// writeTrailer sets the transfer of the response body and the total into the trailer, it is dropped by the
+// responses with the Content-Length over HTTP/1.
+func (t *upstreamTrace) writeTrailer(header http.Header, timings *phaseTimings, start time.Time) {
+	if t == nil || !t.emit {
+		return
+	}
+	header.Set(http.TrailerPrefix+serverTimingHeader, serverTimingMetric("transfer", time.Duration(timings.copy.Load()))+", "+
+		serverTimingMetric("total", time.Since(start)))
+}
+
+// serverTimingMetric formats the duration in milliseconds.
+func serverTimingMetric(name string, d time.Duration) string {
+	return fmt.Sprintf("%s;dur=%.3f", name, float64(d)/float64(time.Millisecond))
+}
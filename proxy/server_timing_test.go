@@ -0,0 +1,87 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestServerTiming(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+			// the real transport fires the trace hooks
+			return http.DefaultTransport.RoundTrip(req)
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:     config.Protocol_HTTP,
+		Path:         "/trusted",
+		Method:       "GET",
+		ServerTiming: &config.ServerTiming{Enabled: true, Header: "X-Gateway-Debug", Values: []string{"secret"}},
+	}, {
+		Protocol:     config.Protocol_HTTP,
+		Path:         "/all",
+		Method:       "GET",
+		ServerTiming: &config.ServerTiming{Enabled: true},
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/disabled",
+		Method:   "GET",
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	do := func(path, debug string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if debug != "" {
+			req.Header.Set("X-Gateway-Debug", debug)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	header := regexp.MustCompile(`^mw;dur=[0-9.]+, connect;dur=[0-9.]+, ttfb;dur=[0-9.]+, retries;desc="0", total;dur=[0-9.]+$`)
+	for _, tc := range []struct{ path, debug string }{{"/trusted", "secret"}} {
+		resp := do(tc.path, tc.debug)
+		if got := resp.Header.Get(serverTimingHeader); !header.MatchString(got) {
+			t.Fatalf("%s: unexpected Server-Timing %q", tc.path, got)
+		}
+		if got := resp.Trailer.Get(serverTimingHeader); !strings.HasPrefix(got, "transfer;dur=") || !strings.Contains(got, "total;dur=") {
+			t.Fatalf("%s: unexpected Server-Timing trailer %q", tc.path, got)
+		}
+	}
+	// the header is not emitted without the trigger header
+	for _, tc := range []struct{ path, debug string }{{"/trusted", ""}, {"/trusted", "guess"}, {"/all", ""}, {"/all", "secret"}, {"/disabled", "secret"}} {
+		if got := do(tc.path, tc.debug).Header.Get(serverTimingHeader); got != "" {
+			t.Fatalf("%s %q: want no Server-Timing but got %q", tc.path, tc.debug, got)
+		}
+	}
+
+	// the trigger without the trusted values is rejected
+	c.Endpoints[0].ServerTiming.Values = nil
+	if err := p.Update(client.NewBuildContext(c), c); !errors.Is(err, errServerTimingValues) {
+		t.Fatalf("want the server timing without values rejected but got %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	// the requests of the routes with the SLOs are counted in buckets of one minute over the last hour
+	_sloBucket  = time.Minute
+	_sloBuckets = 60
+	// the routes the windows are kept for, the others are exported by the metrics only
+	_maxSLORoutes = 1000
+)
+
+var (
+	_metricRouteSLOInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "route_slo_info",
+		Help:      "The service level objectives of the routes of the applied config, 1 for each route with the SLO",
+	}, []string{"protocol", "method", "path", "service", "basePath", "availability", "latency_threshold_seconds", "latency_target"})
+	_metricSLOLatencyViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "requests_slo_latency_violations_total",
+		Help:      "The total number of the requests slower than the latency threshold of the SLO of the route",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricRouteSLOInfo, _metricSLOLatencyViolations)
+}
+
+// failedRequest reports whether the request counts against the availability, the 5xx and the upstream
+// connection errors.
+func failedRequest(statusCode int, err error) bool {
+	return statusCode >= http.StatusInternalServerError || ClassifyError(err) == ErrorClassUpstreamConnect
+}
+
+func checkSLO(c *config.SLO) error {
+	if c.Availability < 0 || c.Availability >= 1 {
+		return errors.New("slo: availability must be in [0, 1)")
+	}
+	if c.LatencyTarget < 0 || c.LatencyTarget >= 1 {
+		return errors.New("slo: latency_target must be in [0, 1)")
+	}
+	if c.LatencyThreshold.AsDuration() < 0 {
+		return errors.New("slo: latency_threshold must not be negative")
+	}
+	if c.LatencyTarget > 0 && c.LatencyThreshold.AsDuration() == 0 {
+		return errors.New("slo: latency_target requires latency_threshold")
+	}
+	return nil
+}
+
+type sloBucket struct {
+	minute   int64
+	requests int64
+	failed   int64
+	observed int64
+	slow     int64
+}
+
+// sloWindow counts the requests of a route in the last hour, the oldest bucket is partial.
+type sloWindow struct {
+	lock    sync.Mutex
+	buckets [_sloBuckets]sloBucket
+}
+
+func (w *sloWindow) bucket(now time.Time) *sloBucket {
+	minute := now.Unix() / int64(_sloBucket/time.Second)
+	b := &w.buckets[minute%_sloBuckets]
+	if b.minute != minute {
+		*b = sloBucket{minute: minute}
+	}
+	return b
+}
+
+func (w *sloWindow) addRequest(now time.Time, failed bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	b := w.bucket(now)
+	b.requests++
+	if failed {
+		b.failed++
+	}
+}
+
+func (w *sloWindow) addLatency(now time.Time, slow bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	b := w.bucket(now)
+	b.observed++
+	if slow {
+		b.slow++
+	}
+}
+
+// sum returns the counts of the buckets of the last hour.
+func (w *sloWindow) sum(now time.Time) sloBucket {
+	from := now.Unix()/int64(_sloBucket/time.Second) - _sloBuckets
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var out sloBucket
+	for _, b := range w.buckets {
+		if b.minute > from {
+			out.requests += b.requests
+			out.failed += b.failed
+			out.observed += b.observed
+			out.slow += b.slow
+		}
+	}
+	return out
+}
+
+type sloRoute struct {
+	route  string
+	slo    *config.SLO
+	window *sloWindow
+}
+
+// sloRegistry keeps the windows of the routes with the SLOs across the reloads.
+type sloRegistry struct {
+	lock    sync.Mutex
+	windows map[string]*sloWindow
+	// the routes of the applied config
+	routes []*sloRoute
+	now    func() time.Time
+}
+
+func newSLORegistry() *sloRegistry {
+	return &sloRegistry{windows: map[string]*sloWindow{}, now: time.Now}
+}
+
+// window returns the window of the route, nil if the routes tracked are at the limit.
+func (r *sloRegistry) window(route string) *sloWindow {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	w, ok := r.windows[route]
+	if !ok && len(r.windows) < _maxSLORoutes {
+		w = &sloWindow{}
+		r.windows[route] = w
+	}
+	return w
+}
+
+// observer counts the requests of the endpoint against its SLO, next is returned if the endpoint has no SLO.
+func (r *sloRegistry) observer(e *config.Endpoint, next Observer) (Observer, error) {
+	if e.Slo == nil {
+		return next, nil
+	}
+	if err := checkSLO(e.Slo); err != nil {
+		return nil, err
+	}
+	return &sloObserver{
+		Observer:  next,
+		labels:    middleware.NewMetricsLabels(e),
+		threshold: e.Slo.LatencyThreshold.AsDuration(),
+		window:    r.window(routeName(e)),
+		now:       r.now,
+	}, nil
+}
+
+// update exports the SLOs of the endpoints of the applied config and drops the windows of the other routes.
+func (r *sloRegistry) update(endpoints []*config.Endpoint) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_metricRouteSLOInfo.Reset()
+	routes := make([]*sloRoute, 0)
+	seen := map[string]struct{}{}
+	for _, e := range endpoints {
+		if e.Slo == nil {
+			continue
+		}
+		labels := middleware.NewMetricsLabels(e)
+		_metricRouteSLOInfo.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(),
+			formatRatio(e.Slo.Availability), strconv.FormatFloat(e.Slo.LatencyThreshold.AsDuration().Seconds(), 'f', -1, 64),
+			formatRatio(e.Slo.LatencyTarget)).Set(1)
+		route := routeName(e)
+		seen[route] = struct{}{}
+		routes = append(routes, &sloRoute{route: route, slo: e.Slo, window: r.windows[route]})
+	}
+	for route := range r.windows {
+		if _, ok := seen[route]; !ok {
+			delete(r.windows, route)
+		}
+	}
+	r.routes = routes
+}
+
+func formatRatio(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+type sloObserver struct {
+	Observer
+	labels    middleware.MetricsLabels
+	threshold time.Duration
+	// nil if the route is not tracked
+	window *sloWindow
+	now    func() time.Time
+}
+
+func (o *sloObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	o.Observer.HandleRequest(req, responseHeader, statusCode, err)
+	if o.window != nil {
+		o.window.addRequest(o.now(), failedRequest(statusCode, err))
+	}
+}
+
+func (o *sloObserver) HandleLatency(req *http.Request, latency time.Duration) {
+	o.Observer.HandleLatency(req, latency)
+	if o.threshold <= 0 {
+		return
+	}
+	slow := latency > o.threshold
+	if slow {
+		_metricSLOLatencyViolations.WithLabelValues(o.labels.Protocol(), req.Method, metricsPath(req, o.labels), o.labels.Service(), o.labels.BasePath()).Inc()
+	}
+	if o.window != nil {
+		o.window.addLatency(o.now(), slow)
+	}
+}
+
+// SLOStatus is the approximate state of the SLOs of the routes, see the metrics for the exact one.
+type SLOStatus struct {
+	Approximate bool        `json:"approximate"`
+	Note        string      `json:"note"`
+	Window      string      `json:"window"`
+	Routes      []*RouteSLO `json:"routes"`
+}
+
+// RouteSLO is the SLO of a route and the ratios observed in the window, the ratios are omitted without requests.
+type RouteSLO struct {
+	Route            string  `json:"route"`
+	Availability     float64 `json:"availability,omitempty"`
+	LatencyThreshold string  `json:"latency_threshold,omitempty"`
+	LatencyTarget    float64 `json:"latency_target,omitempty"`
+	// Untracked is set if the window of the route is not kept for the limit of the routes.
+	Untracked bool  `json:"untracked,omitempty"`
+	Requests  int64 `json:"requests"`
+	// ObservedAvailability is the ratio of the requests not failed, the 5xx and the upstream connection errors.
+	ObservedAvailability *float64 `json:"observed_availability,omitempty"`
+	// LatencyViolationRatio is the ratio of the requests slower than the latency threshold.
+	LatencyViolationRatio *float64 `json:"latency_violation_ratio,omitempty"`
+	// the burn rates are the error ratios over the error budgets of the targets, 1 spends the budget in the period
+	// of the SLO exactly.
+	AvailabilityBurnRate *float64 `json:"availability_burn_rate,omitempty"`
+	LatencyBurnRate      *float64 `json:"latency_burn_rate,omitempty"`
+}
+
+// Status returns the SLOs of the routes of the applied config with the ratios of the last hour.
+func (r *sloRegistry) Status() *SLOStatus {
+	r.lock.Lock()
+	routes := r.routes
+	r.lock.Unlock()
+	now := r.now()
+	out := &SLOStatus{
+		Approximate: true,
+		Note: "computed in memory by this instance from one-minute buckets of the last hour, the oldest bucket is partial " +
+			"and the counts restart with the process, use the metrics for the SLO reports",
+		Window: (_sloBuckets * _sloBucket).String(),
+		Routes: make([]*RouteSLO, 0, len(routes)),
+	}
+	for _, route := range routes {
+		s := &RouteSLO{Route: route.route, Availability: route.slo.Availability, LatencyTarget: route.slo.LatencyTarget}
+		if threshold := route.slo.LatencyThreshold.AsDuration(); threshold > 0 {
+			s.LatencyThreshold = threshold.String()
+		}
+		out.Routes = append(out.Routes, s)
+		if route.window == nil {
+			s.Untracked = true
+			continue
+		}
+		sum := route.window.sum(now)
+		s.Requests = sum.requests
+		if sum.requests > 0 {
+			failed := float64(sum.failed) / float64(sum.requests)
+			s.ObservedAvailability = ptr(1 - failed)
+			if s.Availability > 0 {
+				s.AvailabilityBurnRate = ptr(failed / (1 - s.Availability))
+			}
+		}
+		if sum.observed > 0 && s.LatencyThreshold != "" {
+			slow := float64(sum.slow) / float64(sum.observed)
+			s.LatencyViolationRatio = ptr(slow)
+			if s.LatencyTarget > 0 {
+				s.LatencyBurnRate = ptr(slow / (1 - s.LatencyTarget))
+			}
+		}
+	}
+	sort.Slice(out.Routes, func(i, j int) bool { return out.Routes[i].Route < out.Routes[j].Route })
+	return out
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func (r *sloRegistry) registerDebugHandler(debugMux *http.ServeMux) {
+	debugMux.HandleFunc("/debug/proxy/slo", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(r.Status())
+	})
+}
@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestSLO(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	r := newSLORegistry()
+	r.now = func() time.Time { return now }
+	e := &config.Endpoint{Path: "/orders/*", Method: http.MethodGet, Slo: &config.SLO{
+		Availability:     0.99,
+		LatencyThreshold: durationpb.New(300 * time.Millisecond),
+		LatencyTarget:    0.9,
+	}}
+	obs, err := r.observer(e, &noopObserver{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.update([]*config.Endpoint{e, {Path: "/users/*"}})
+	if got := testutil.ToFloat64(_metricRouteSLOInfo.WithLabelValues(e.Protocol.String(), "GET", "/orders/*", "", "", "0.99", "0.3", "0.9")); got != 1 {
+		t.Fatalf("want the slo info exported but got %v", got)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	violations := testutil.ToFloat64(_metricSLOLatencyViolations.WithLabelValues(e.Protocol.String(), "GET", "/orders/*", "", ""))
+	for i := 0; i < 100; i++ {
+		// the requests of the previous hour are out of the window
+		if i == 50 {
+			now = now.Add(time.Hour)
+		}
+		status, latency := http.StatusOK, 100*time.Millisecond
+		if i%10 == 0 {
+			status, latency = http.StatusBadGateway, time.Second
+		}
+		obs.HandleRequest(req, nil, status, nil)
+		obs.HandleLatency(req, latency)
+	}
+	if got := testutil.ToFloat64(_metricSLOLatencyViolations.WithLabelValues(e.Protocol.String(), "GET", "/orders/*", "", "")) - violations; got != 10 {
+		t.Fatalf("want 10 latency violations counted but got %v", got)
+	}
+
+	status := r.Status()
+	if !status.Approximate || len(status.Routes) != 1 {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+	s := status.Routes[0]
+	if s.Route != "GET /orders/*" || s.Requests != 50 || *s.ObservedAvailability != 0.9 || *s.LatencyViolationRatio != 0.1 {
+		t.Fatalf("unexpected route slo: %+v", s)
+	}
+	if burn := *s.AvailabilityBurnRate; burn < 9.99 || burn > 10.01 {
+		t.Fatalf("want the availability budget burnt 10 times but got %v", burn)
+	}
+
+	// the windows of the routes removed are dropped
+	r.update(nil)
+	if len(r.windows) != 0 || len(r.Status().Routes) != 0 {
+		t.Fatalf("want the windows dropped but got %d", len(r.windows))
+	}
+}
+
+func TestSLOInvalid(t *testing.T) {
+	for _, slo := range []*config.SLO{
+		{Availability: 1},
+		{LatencyTarget: 0.99},
+		{LatencyThreshold: durationpb.New(-time.Second)},
+	} {
+		if _, err := newSLORegistry().observer(&config.Endpoint{Path: "/", Slo: slo}, &noopObserver{}); err == nil {
+			t.Errorf("want the slo %v rejected", slo)
+		}
+	}
+}
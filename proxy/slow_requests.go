@@ -0,0 +1,384 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/audit"
+	"github.com/aide-family/goddess/logs"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	_defaultSlowThreshold = time.Second
+	_defaultSlowSize      = 100
+	_maxSlowSize          = 10000
+	// the latencies of the latest requests the slowest fraction is computed from
+	_slowSampleSize = 10000
+	// the threshold of the slowest fraction is recomputed every the number of requests
+	_slowRecomputeEvery = 1000
+)
+
+// the request headers recorded with the values redacted, the headers containing the words are redacted too.
+var (
+	_sensitiveHeaders = map[string]struct{}{
+		"Authorization":       {},
+		"Proxy-Authorization": {},
+		"Cookie":              {},
+		"Set-Cookie":          {},
+	}
+	_sensitiveHeaderWords = []string{"token", "secret", "key", "password", "auth", "session", "signature"}
+)
+
+// SlowRequest is the diagnostic context of a request slower than the threshold, the bodies are never recorded.
+type SlowRequest struct {
+	Time       time.Time          `json:"time"`
+	Method     string             `json:"method"`
+	Host       string             `json:"host"`
+	Path       string             `json:"path"`
+	Route      string             `json:"route"`
+	Upstream   string             `json:"upstream,omitempty"`
+	Attempts   int                `json:"attempts"`
+	StatusCode int                `json:"status_code"`
+	Error      string             `json:"error,omitempty"`
+	Duration   string             `json:"duration"`
+	Phases     *SlowRequestPhases `json:"phases"`
+	Header     http.Header        `json:"header"`
+	// UpstreamBody is the beginning of the upstream body of the error status with upstream_body_capture.
+	UpstreamBody          string `json:"upstream_body,omitempty"`
+	UpstreamBodyTruncated bool   `json:"upstream_body_truncated,omitempty"`
+	// Values is the request values shared between the middlewares, the sensitive values are redacted.
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// SlowRequestPhases is where the time of the slow request went.
+type SlowRequestPhases struct {
+	// Middleware is the time spent in the middleware chain excluding the upstream round trips.
+	Middleware string `json:"middleware"`
+	// UpstreamTTFB is the time of the upstream round trips until the response headers, summed over the attempts.
+	UpstreamTTFB string `json:"upstream_ttfb"`
+	// BodyCopy is the time copying the response body to the client.
+	BodyCopy string `json:"body_copy"`
+}
+
+// SlowRequestsConfig is the runtime config of the slow requests recorder.
+type SlowRequestsConfig struct {
+	// Threshold is the latency of the requests recorded, eg: 500ms, disabled if 0s.
+	Threshold string `json:"threshold"`
+	// SlowestFraction records the slowest fraction of the latest requests as well, eg: 0.001, disabled if 0.
+	SlowestFraction float64 `json:"slowest_fraction"`
+	// Size is the number of the latest slow requests kept.
+	Size int `json:"size"`
+	// Log logs the slow requests as well.
+	Log bool `json:"log"`
+}
+
+type slowRequestsConfig struct {
+	threshold time.Duration
+	fraction  float64
+	size      int
+	log       bool
+}
+
+func (c *slowRequestsConfig) export() *SlowRequestsConfig {
+	return &SlowRequestsConfig{Threshold: c.threshold.String(), SlowestFraction: c.fraction, Size: c.size, Log: c.log}
+}
+
+func parseSlowRequestsConfig(in *SlowRequestsConfig) (*slowRequestsConfig, error) {
+	c := &slowRequestsConfig{fraction: in.SlowestFraction, size: in.Size, log: in.Log}
+	if in.Threshold != "" {
+		threshold, err := time.ParseDuration(in.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		c.threshold = threshold
+	}
+	if c.threshold < 0 {
+		return nil, errors.New("threshold must not be negative")
+	}
+	if c.fraction < 0 || c.fraction >= 1 {
+		return nil, errors.New("slowest_fraction must be in [0, 1)")
+	}
+	if c.size <= 0 || c.size > _maxSlowSize {
+		return nil, errors.New("size must be in [1, 10000]")
+	}
+	return c, nil
+}
+
+// phaseTimings are collected by the handler and the upstream tripper of the request.
+type phaseTimings struct {
+	// the time of the round trips through the middleware chain, including the upstream ones
+	chain    atomic.Int64
+	upstream atomic.Int64
+	copy     atomic.Int64
+
+	statusCode int
+	err        error
+	// the upstream body of the error status, nil if not recorded
+	upstreamBody *capturedBody
+}
+
+type phaseTimingsKey struct{}
+
+func withPhaseTimings(ctx context.Context, t *phaseTimings) context.Context {
+	return context.WithValue(ctx, phaseTimingsKey{}, t)
+}
+
+func phaseTimingsFromContext(ctx context.Context) (*phaseTimings, bool) {
+	t, ok := ctx.Value(phaseTimingsKey{}).(*phaseTimings)
+	return t, ok
+}
+
+// timedTripper measures the upstream round trips of the requests, it is the innermost tripper of the endpoint.
+func timedTripper(next http.RoundTripper) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t, ok := phaseTimingsFromContext(req.Context())
+		if !ok {
+			return next.RoundTrip(req)
+		}
+		start := time.Now()
+		defer func() { t.upstream.Add(int64(time.Since(start))) }()
+		return next.RoundTrip(req)
+	})
+}
+
+// slowRequestRecorder keeps the latest slow requests in a ring buffer.
+type slowRequestRecorder struct {
+	config atomic.Pointer[slowRequestsConfig]
+
+	lock    sync.Mutex
+	entries []*SlowRequest
+	next    int
+
+	samples       []atomic.Int64
+	sampled       atomic.Uint64
+	fractionLimit atomic.Int64
+}
+
+func newSlowRequestRecorder() *slowRequestRecorder {
+	r := &slowRequestRecorder{samples: make([]atomic.Int64, _slowSampleSize)}
+	r.config.Store(&slowRequestsConfig{threshold: _defaultSlowThreshold, size: _defaultSlowSize})
+	return r
+}
+
+func (r *slowRequestRecorder) update(c *slowRequestsConfig) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	old := r.config.Swap(c)
+	if old.size != c.size {
+		// keeps the latest entries
+		entries := r.list()
+		if len(entries) > c.size {
+			entries = entries[:c.size]
+		}
+		slices.Reverse(entries)
+		r.entries, r.next = entries, len(entries)%c.size
+	}
+	if old.fraction != c.fraction {
+		r.fractionLimit.Store(0)
+	}
+}
+
+// list returns the entries from the newest, the lock must be held.
+func (r *slowRequestRecorder) list() []*SlowRequest {
+	out := make([]*SlowRequest, 0, len(r.entries))
+	for i := 1; i <= len(r.entries); i++ {
+		out = append(out, r.entries[(r.next-i+len(r.entries))%len(r.entries)])
+	}
+	return out
+}
+
+// Requests returns the slow requests from the newest.
+func (r *slowRequestRecorder) Requests() []*SlowRequest {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.list()
+}
+
+func (r *slowRequestRecorder) isSlow(c *slowRequestsConfig, latency time.Duration) bool {
+	slow := c.threshold > 0 && latency >= c.threshold
+	if c.fraction <= 0 {
+		return slow
+	}
+	n := r.sampled.Add(1)
+	r.samples[(n-1)%_slowSampleSize].Store(int64(latency))
+	if n%_slowRecomputeEvery == 0 {
+		r.recomputeFractionLimit(c.fraction, min(n, _slowSampleSize))
+	}
+	limit := time.Duration(r.fractionLimit.Load())
+	return slow || (limit > 0 && latency >= limit)
+}
+
+func (r *slowRequestRecorder) recomputeFractionLimit(fraction float64, n uint64) {
+	latencies := make([]int64, n)
+	for i := range latencies {
+		latencies[i] = r.samples[i].Load()
+	}
+	slices.Sort(latencies)
+	idx := int(float64(n) * (1 - fraction))
+	if idx >= len(latencies) {
+		idx = len(latencies) - 1
+	}
+	r.fractionLimit.Store(latencies[idx])
+}
+
+func (r *slowRequestRecorder) record(req *http.Request, latency time.Duration) {
+	t, ok := phaseTimingsFromContext(req.Context())
+	if !ok {
+		return
+	}
+	c := r.config.Load()
+	if !r.isSlow(c, latency) {
+		return
+	}
+	entry := &SlowRequest{
+		Time:       time.Now(),
+		Method:     req.Method,
+		Host:       req.Host,
+		Path:       req.URL.Path,
+		StatusCode: t.statusCode,
+		Duration:   latency.String(),
+		Header:     redactHeader(req.Header),
+	}
+	if t.err != nil {
+		entry.Error = t.err.Error()
+	}
+	if t.upstreamBody != nil {
+		entry.UpstreamBody, entry.UpstreamBodyTruncated = t.upstreamBody.String(), t.upstreamBody.truncated
+	}
+	chain, upstream := time.Duration(t.chain.Load()), time.Duration(t.upstream.Load())
+	entry.Phases = &SlowRequestPhases{
+		Middleware:   max(chain-upstream, 0).String(),
+		UpstreamTTFB: upstream.String(),
+		BodyCopy:     time.Duration(t.copy.Load()).String(),
+	}
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok {
+		entry.Route = opts.Endpoint.Path
+		upstream := opts.Upstream()
+		entry.Upstream, entry.Attempts = upstream.Addr, upstream.Attempts
+		entry.Values = middleware.ExportValues(opts)
+	}
+	if c.log {
+		log.NewHelper(logs.Debug).WithContext(req.Context()).Warnw(
+			"source", "slow_request",
+			"method", entry.Method,
+			"host", entry.Host,
+			"path", entry.Path,
+			"route", entry.Route,
+			"upstream", entry.Upstream,
+			"attempts", entry.Attempts,
+			"code", entry.StatusCode,
+			"duration", entry.Duration,
+			"middleware", entry.Phases.Middleware,
+			"upstream_ttfb", entry.Phases.UpstreamTTFB,
+			"body_copy", entry.Phases.BodyCopy,
+			"values", entry.Values,
+			"error", entry.Error,
+			"upstream_body", entry.UpstreamBody,
+		)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	size := r.config.Load().size
+	if len(r.entries) < size {
+		r.entries = append(r.entries, entry)
+		r.next = len(r.entries) % size
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % size
+}
+
+func redactHeader(in http.Header) http.Header {
+	out := make(http.Header, len(in))
+	for k, v := range in {
+		if isSensitiveHeader(k) {
+			out[k] = []string{"[redacted]"}
+			continue
+		}
+		out[k] = slices.Clone(v)
+	}
+	return out
+}
+
+func isSensitiveHeader(key string) bool {
+	if _, ok := _sensitiveHeaders[http.CanonicalHeaderKey(key)]; ok {
+		return true
+	}
+	lower := strings.ToLower(key)
+	for _, word := range _sensitiveHeaderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// observer records the status and the latency of the requests observed by the endpoint observer.
+func (r *slowRequestRecorder) observer(next Observer) Observer {
+	return &slowRequestObserver{Observer: next, recorder: r}
+}
+
+type slowRequestObserver struct {
+	Observer
+	recorder *slowRequestRecorder
+}
+
+func (o *slowRequestObserver) HandleRequest(req *http.Request, responseHeader http.Header, statusCode int, err error) {
+	o.Observer.HandleRequest(req, responseHeader, statusCode, err)
+	if t, ok := phaseTimingsFromContext(req.Context()); ok {
+		t.statusCode, t.err = statusCode, err
+	}
+}
+
+func (o *slowRequestObserver) HandleLatency(req *http.Request, latency time.Duration) {
+	o.Observer.HandleLatency(req, latency)
+	o.recorder.record(req, latency)
+}
+
+type slowRequestsResponse struct {
+	Config   *SlowRequestsConfig `json:"config"`
+	Requests []*SlowRequest      `json:"requests"`
+}
+
+func (r *slowRequestRecorder) registerDebugHandler(debugMux *http.ServeMux) {
+	debugMux.HandleFunc("/debug/proxy/slow-requests", func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(&slowRequestsResponse{Config: r.config.Load().export(), Requests: r.Requests()})
+	})
+	debugMux.HandleFunc("/debug/proxy/slow-requests/config", func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			rw.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(rw).Encode(r.config.Load().export())
+			return
+		}
+		in := r.config.Load().export()
+		if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		c, err := parseSlowRequestsConfig(in)
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+		before, _ := json.Marshal(r.config.Load().export())
+		after, _ := json.Marshal(c.export())
+		if err := audit.WriteRequest(req, &audit.Record{Action: "slow-requests.config", Before: string(before), After: string(after)}); err != nil {
+			log.Errorf("Failed to write audit record of slow-requests.config: %+v", err)
+			http.Error(rw, "failed to write audit record", http.StatusInternalServerError)
+			return
+		}
+		r.update(c)
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(c.export())
+	})
+}
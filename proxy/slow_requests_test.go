@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestSlowRequests(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/slow",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Slow") != "" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	debug := p.DebugHandler()
+	configure := func(body string) int {
+		w := httptest.NewRecorder()
+		debug.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/proxy/slow-requests/config", strings.NewReader(body)))
+		return w.Code
+	}
+	if code := configure(`{"threshold":"10ms","size":2}`); code != http.StatusOK {
+		t.Fatalf("want the config updated but got %d", code)
+	}
+	if code := configure(`{"size":0}`); code != http.StatusBadRequest {
+		t.Fatalf("want the invalid size rejected but got %d", code)
+	}
+
+	for _, slow := range []string{"1", "", "2", "3"} {
+		req := httptest.NewRequest(http.MethodGet, "/slow?id="+slow, nil)
+		req.Header.Set("X-Slow", slow)
+		req.Header.Set("Authorization", "Bearer secret")
+		req.Header.Set("X-Api-Token", "secret")
+		p.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/proxy/slow-requests", nil))
+	out := &slowRequestsResponse{}
+	if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+	// the ring buffer keeps the latest 2 slow requests from the newest
+	if len(out.Requests) != 2 || out.Requests[0].Header.Get("X-Slow") != "3" || out.Requests[1].Header.Get("X-Slow") != "2" {
+		t.Fatalf("want the latest 2 slow requests but got %+v", out.Requests)
+	}
+	entry := out.Requests[0]
+	if entry.Route != "/slow" || entry.StatusCode != http.StatusAccepted {
+		t.Fatalf("unexpected slow request: %+v", entry)
+	}
+	if upstream, err := time.ParseDuration(entry.Phases.UpstreamTTFB); err != nil || upstream < 20*time.Millisecond {
+		t.Fatalf("want the upstream time recorded but got %q", entry.Phases.UpstreamTTFB)
+	}
+	if entry.Header.Get("Authorization") != "[redacted]" || entry.Header.Get("X-Api-Token") != "[redacted]" {
+		t.Fatalf("want the sensitive headers redacted but got %v", entry.Header)
+	}
+	if strings.Contains(entry.Path, "?") {
+		t.Fatalf("want the query not recorded but got %q", entry.Path)
+	}
+}
+
+func TestSlowestFraction(t *testing.T) {
+	r := newSlowRequestRecorder()
+	c := &slowRequestsConfig{fraction: 0.01, size: 10}
+	r.update(c)
+	slow := 0
+	for i := 0; i < 2*_slowRecomputeEvery; i++ {
+		latency := time.Millisecond
+		if i%100 == 99 {
+			latency = time.Second
+		}
+		if r.isSlow(c, latency) && i >= _slowRecomputeEvery {
+			slow++
+		}
+	}
+	// the slowest 1% after the threshold is computed
+	if slow != 10 {
+		t.Fatalf("want 10 slow requests but got %d", slow)
+	}
+}
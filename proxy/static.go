@@ -0,0 +1,256 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+var _staticFS = struct {
+	lock   sync.RWMutex
+	byName map[string]fs.FS
+}{byName: map[string]fs.FS{}}
+
+// RegisterStaticFS registers the file system served by the static endpoints with Static.embed, eg: the assets
+// embedded into the binary with embed.FS.
+func RegisterStaticFS(name string, fsys fs.FS) {
+	_staticFS.lock.Lock()
+	defer _staticFS.lock.Unlock()
+	_staticFS.byName[name] = fsys
+}
+
+func lookupStaticFS(name string) (fs.FS, bool) {
+	_staticFS.lock.RLock()
+	defer _staticFS.lock.RUnlock()
+	fsys, ok := _staticFS.byName[name]
+	return fsys, ok
+}
+
+// staticClient serves the files of a static endpoint in place of the upstream client, so the middlewares of
+// the endpoint are applied as usual. The directory listing is never served, and the paths escaping the root,
+// including through the symlinks, are replied 404.
+type staticClient struct {
+	fsys         fs.FS
+	root         *os.Root
+	files        map[string]string
+	stripPrefix  string
+	cacheControl string
+	etag         bool
+	index        string
+	etags        sync.Map // etagKey -> string
+}
+
+type etagKey struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func newStaticClient(e *config.Endpoint) (*staticClient, error) {
+	s := e.Static
+	c := &staticClient{
+		files:        s.Files,
+		stripPrefix:  strings.TrimSuffix(s.StripPrefix, "/"),
+		cacheControl: s.CacheControl,
+		etag:         !s.DisableEtag,
+		index:        s.Index,
+	}
+	switch {
+	case s.Embed != "":
+		fsys, ok := lookupStaticFS(s.Embed)
+		if !ok {
+			return nil, fmt.Errorf("static file system %q is not registered", s.Embed)
+		}
+		c.fsys = fsys
+	case s.Dir != "":
+		root, err := os.OpenRoot(s.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open static dir: %w", err)
+		}
+		c.root, c.fsys = root, root.FS()
+	default:
+		return nil, errors.New("static endpoint requires dir or embed")
+	}
+	for p, name := range c.files {
+		if !fs.ValidPath(name) {
+			c.Close()
+			return nil, fmt.Errorf("invalid static file %q of %s", name, p)
+		}
+	}
+	return c, nil
+}
+
+func (c *staticClient) Close() error {
+	if c.root != nil {
+		return c.root.Close()
+	}
+	return nil
+}
+
+// RoundTrip replies the request with the file, the response is buffered so the static endpoints are meant
+// for small assets, eg: the maintenance page or the OpenAPI spec.
+func (c *staticClient) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := &staticResponseWriter{header: http.Header{}}
+	c.ServeHTTP(w, req)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	contentLength := int64(w.body.Len())
+	if req.Method == http.MethodHead {
+		contentLength = -1
+		if n, err := strconv.ParseInt(w.header.Get("Content-Length"), 10, 64); err == nil {
+			contentLength = n
+		}
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(w.status) + " " + http.StatusText(w.status),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.header,
+		Body:          io.NopCloser(bytes.NewReader(w.body.Bytes())),
+		ContentLength: contentLength,
+		Request:       req,
+	}, nil
+}
+
+func (c *staticClient) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := c.resolve(req.URL.Path)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	f, stat, ok := c.open(name)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	if c.cacheControl != "" {
+		w.Header().Set("Cache-Control", c.cacheControl)
+	}
+	if c.etag {
+		etag, err := c.etagOf(name, stat, content)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	}
+	// the conditional and range requests are handled by the standard library
+	http.ServeContent(w, req, stat.Name(), stat.ModTime(), content)
+}
+
+// resolve returns the file name of the request path, false if the path is not served.
+func (c *staticClient) resolve(p string) (string, bool) {
+	if c.stripPrefix != "" {
+		if p != c.stripPrefix && !strings.HasPrefix(p, c.stripPrefix+"/") {
+			return "", false
+		}
+		p = strings.TrimPrefix(p, c.stripPrefix)
+	}
+	if len(c.files) > 0 {
+		name, ok := c.files[p]
+		return name, ok
+	}
+	name := strings.TrimPrefix(p, "/")
+	if name == "" || strings.HasSuffix(name, "/") {
+		name = path.Join(name, ".")
+	}
+	// the paths with the dot-dot, empty or backslash elements are rejected instead of cleaned
+	if !fs.ValidPath(name) || strings.Contains(name, "\\") {
+		return "", false
+	}
+	return name, true
+}
+
+// open opens the file, the index file is opened for the directories.
+func (c *staticClient) open(name string) (fs.File, fs.FileInfo, bool) {
+	f, stat, ok := openRegular(c.fsys, name)
+	if ok || stat == nil || !stat.IsDir() || c.index == "" {
+		return f, stat, ok
+	}
+	return openRegular(c.fsys, path.Join(name, c.index))
+}
+
+func openRegular(fsys fs.FS, name string) (fs.File, fs.FileInfo, bool) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, false
+	}
+	if !stat.Mode().IsRegular() {
+		f.Close()
+		return nil, stat, false
+	}
+	return f, stat, true
+}
+
+// etagOf returns the strong ETag of the file content, cached by the size and the modification time.
+func (c *staticClient) etagOf(name string, stat fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := etagKey{name: name, size: stat.Size(), modTime: stat.ModTime()}
+	if etag, ok := c.etags.Load(key); ok {
+		return etag.(string), nil
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, content); err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	etag := `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+	c.etags.Store(key, etag)
+	return etag, nil
+}
+
+type staticResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *staticResponseWriter) Header() http.Header { return w.header }
+
+func (w *staticResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *staticResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
@@ -0,0 +1,144 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestStaticEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "maintenance.html"), []byte("<h1>maintenance</h1>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "docs", "index.html"), []byte("docs"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	RegisterStaticFS("test-spec", fstest.MapFS{"v1.json": {Data: []byte(`{"openapi":"3.0.0"}`)}})
+
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		t.Error("want no upstream client for the static endpoints")
+		return nil, io.EOF
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if err != nil {
+					return nil, err
+				}
+				resp.Header.Set("X-Middleware", c.Name)
+				return resp, nil
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:    config.Protocol_HTTP,
+		Path:        "/static/*",
+		Middlewares: []*config.Middleware{{Name: "headers"}},
+		Static: &config.Static{
+			Dir:          dir,
+			StripPrefix:  "/static",
+			CacheControl: "public, max-age=60",
+			Index:        "index.html",
+		},
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/openapi.json",
+		Method:   "GET",
+		Static: &config.Static{
+			Embed: "test-spec",
+			Files: map[string]string{"/openapi.json": "v1.json"},
+		},
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	serve := func(method, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/", nil)
+		req.URL.Path = path
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/static/maintenance.html", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "<h1>maintenance</h1>" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w.Header().Get("X-Middleware") != "headers" {
+		t.Fatal("want the middlewares applied to the static endpoint")
+	}
+	if w.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatalf("unexpected Cache-Control %q", w.Header().Get("Cache-Control"))
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("want the ETag generated")
+	}
+	if w := serve(http.MethodGet, "/static/maintenance.html", http.Header{"If-None-Match": {etag}}); w.Code != http.StatusNotModified {
+		t.Fatalf("want 304 for the conditional request but got %d", w.Code)
+	}
+	if w := serve(http.MethodGet, "/static/maintenance.html", http.Header{"Range": {"bytes=4-14"}}); w.Code != http.StatusPartialContent || w.Body.String() != "maintenance" {
+		t.Fatalf("unexpected range response %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(http.MethodGet, "/static/docs/", nil); w.Code != http.StatusOK || w.Body.String() != "docs" {
+		t.Fatalf("want the index file of the directory but got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(http.MethodPost, "/static/maintenance.html", nil); w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("want 405 for POST but got %d", w.Code)
+	}
+	for _, path := range []string{
+		"/static/../../etc/passwd",
+		"/static/docs/../../../etc/passwd",
+		"/static/..%2f..%2fetc/passwd",
+		"/static/..\\..\\etc\\passwd",
+		"/static/missing.html",
+	} {
+		if w := serve(http.MethodGet, path, nil); w.Code != http.StatusNotFound {
+			t.Fatalf("want 404 for %s but got %d", path, w.Code)
+		}
+	}
+
+	w = serve(http.MethodGet, "/openapi.json", nil)
+	if w.Code != http.StatusOK || w.Body.String() != `{"openapi":"3.0.0"}` {
+		t.Fatalf("unexpected embedded response %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("unexpected Content-Type %q", ct)
+	}
+}
+
+func TestStaticDirListingDisabled(t *testing.T) {
+	c, err := newStaticClient(&config.Endpoint{Static: &config.Static{Dir: t.TempDir()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 for the directory but got %d", w.Code)
+	}
+	if _, err := newStaticClient(&config.Endpoint{Static: &config.Static{Embed: "unknown"}}); err == nil {
+		t.Fatal("want the unknown file system rejected")
+	}
+}
@@ -0,0 +1,378 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ErrStreamLimitExceeded is returned when the endpoint is at its max_streams.
+	ErrStreamLimitExceeded = errors.New("stream limit exceeded")
+	// ErrClientStreamLimitExceeded is returned when the client is at its max_client_streams.
+	ErrClientStreamLimitExceeded = errors.New("client stream limit exceeded")
+)
+
+const (
+	_reasonStreamLimitExceeded       = "STREAM_LIMIT_EXCEEDED"
+	_reasonClientStreamLimitExceeded = "CLIENT_STREAM_LIMIT_EXCEEDED"
+	_streamLimitsSource              = "stream_limits"
+
+	streamDirectionUpload   = "upload"
+	streamDirectionDownload = "download"
+
+	// the bytes read or written at once by a throttled stream are bounded, so the rate is smooth
+	_minThrottleChunk = 1 << 10
+	_maxThrottleChunk = 32 << 10
+)
+
+var (
+	_metricStreamLimitRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "stream_limit_rejected_total",
+		Help:      "The total number of the streams rejected by the stream limits",
+	}, []string{"protocol", "method", "path", "service", "basePath", "reason"})
+	_metricStreamThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "stream_throttled_seconds_total",
+		Help:      "The total seconds the streams waited for the bandwidth caps",
+	}, []string{"protocol", "method", "path", "service", "basePath", "direction"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricStreamLimitRejected, _metricStreamThrottled)
+}
+
+// bandwidth is a token bucket of bytes, the bytes are taken after they are transferred so the bucket may
+// go into debt, the next transfer waits until the debt is paid.
+type bandwidth struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBandwidth(bytesPerSecond uint64) *bandwidth {
+	if bytesPerSecond == 0 {
+		return nil
+	}
+	rate := float64(bytesPerSecond)
+	return &bandwidth{rate: rate, burst: max(rate/10, _minThrottleChunk), last: time.Now()}
+}
+
+// chunk is the max bytes transferred at once.
+func (b *bandwidth) chunk() int {
+	if b == nil {
+		return _maxThrottleChunk
+	}
+	return min(max(int(b.rate/10), _minThrottleChunk), _maxThrottleChunk)
+}
+
+// reserve takes n bytes and returns the delay before they may be transferred.
+func (b *bandwidth) reserve(n int) time.Duration {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// wait takes n bytes and blocks until they may be transferred or the context is done.
+func (b *bandwidth) wait(ctx context.Context, n int) (time.Duration, error) {
+	if b == nil || n <= 0 {
+		return 0, nil
+	}
+	d := b.reserve(n)
+	if d <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return d, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// throttle is the bandwidth caps of one direction of a stream.
+type throttle struct {
+	ctx      context.Context
+	stream   *bandwidth
+	endpoint *bandwidth
+	waited   prometheus.Counter
+}
+
+func (t *throttle) chunk() int {
+	return min(t.stream.chunk(), t.endpoint.chunk())
+}
+
+func (t *throttle) wait(n int) error {
+	d, err := t.stream.wait(t.ctx, n)
+	if err != nil {
+		return err
+	}
+	d2, err := t.endpoint.wait(t.ctx, n)
+	if err != nil {
+		return err
+	}
+	if d += d2; d > 0 {
+		t.waited.Add(d.Seconds())
+	}
+	return nil
+}
+
+func (t *throttle) read(r io.Reader, p []byte) (int, error) {
+	if len(p) > t.chunk() {
+		p = p[:t.chunk()]
+	}
+	n, err := r.Read(p)
+	if werr := t.wait(n); werr != nil {
+		return n, werr
+	}
+	return n, err
+}
+
+func (t *throttle) write(w io.Writer, p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p[:min(len(p), t.chunk())]
+		n, err := w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if err := t.wait(n); err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+	return written, nil
+}
+
+type throttledReadCloser struct {
+	io.ReadCloser
+	throttle *throttle
+}
+
+func (b *throttledReadCloser) Read(p []byte) (int, error) {
+	return b.throttle.read(b.ReadCloser, p)
+}
+
+// throttledReadWriteCloser throttles the upgraded connection, the reads are sent to the client and the writes
+// are sent to the upstream.
+type throttledReadWriteCloser struct {
+	io.ReadWriteCloser
+	download *throttle
+	upload   *throttle
+}
+
+func (b *throttledReadWriteCloser) Read(p []byte) (int, error) {
+	return b.download.read(b.ReadWriteCloser, p)
+}
+
+func (b *throttledReadWriteCloser) Write(p []byte) (int, error) {
+	return b.upload.write(b.ReadWriteCloser, p)
+}
+
+// streamCounters is the streams of a route, it is kept across the reloads.
+type streamCounters struct {
+	lock    sync.Mutex
+	total   uint32
+	clients map[string]uint32
+
+	// the endpoint bandwidth of each direction, replaced when endpoint_bytes_per_second changes
+	rate     uint64
+	upload   *bandwidth
+	download *bandwidth
+}
+
+func (c *streamCounters) acquire(client string, maxStreams, maxClientStreams uint32) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if maxStreams > 0 && c.total >= maxStreams {
+		return ErrStreamLimitExceeded
+	}
+	if maxClientStreams > 0 && c.clients[client] >= maxClientStreams {
+		return ErrClientStreamLimitExceeded
+	}
+	c.total++
+	c.clients[client]++
+	return nil
+}
+
+func (c *streamCounters) release(client string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.total--
+	if c.clients[client]--; c.clients[client] == 0 {
+		delete(c.clients, client)
+	}
+}
+
+func (c *streamCounters) bandwidths(rate uint64) (upload, download *bandwidth) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.rate != rate {
+		c.rate = rate
+		c.upload, c.download = newBandwidth(rate), newBandwidth(rate)
+	}
+	return c.upload, c.download
+}
+
+// streamLimitsRegistry holds the streams of the routes, so the streams opened before a reload are still counted.
+type streamLimitsRegistry struct {
+	lock   sync.Mutex
+	routes map[string]*streamCounters
+}
+
+func newStreamLimitsRegistry() *streamLimitsRegistry {
+	return &streamLimitsRegistry{routes: map[string]*streamCounters{}}
+}
+
+func (r *streamLimitsRegistry) get(e *config.Endpoint) *streamCounters {
+	key := e.Method + " " + e.Host + e.Path
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	c, ok := r.routes[key]
+	if !ok {
+		c = &streamCounters{clients: map[string]uint32{}}
+		r.routes[key] = c
+	}
+	return c
+}
+
+// streamLimits limits the concurrent streams and the bandwidth of a stream endpoint.
+type streamLimits struct {
+	counters         *streamCounters
+	maxStreams       uint32
+	maxClientStreams uint32
+	clientByConsumer bool
+	streamRate       uint64
+	endpointUpload   *bandwidth
+	endpointDownload *bandwidth
+	labels           []string
+}
+
+func (p *Proxy) newStreamLimits(e *config.Endpoint) (*streamLimits, error) {
+	c := e.StreamLimits
+	if c == nil {
+		return nil, nil
+	}
+	if !e.Stream {
+		return nil, errors.New("stream limits require a stream endpoint")
+	}
+	l := &streamLimits{
+		counters:         p.streamLimits.get(e),
+		maxStreams:       c.MaxStreams,
+		maxClientStreams: c.MaxClientStreams,
+		clientByConsumer: c.ClientByConsumer,
+		streamRate:       c.StreamBytesPerSecond,
+		labels:           []string{e.Protocol.String(), e.Method, e.Path, e.Metadata["service"], e.Metadata["basePath"]},
+	}
+	l.endpointUpload, l.endpointDownload = l.counters.bandwidths(c.EndpointBytesPerSecond)
+	return l, nil
+}
+
+// client identifies the client of the request by the consumer id or the ip.
+func (l *streamLimits) client(req *http.Request) string {
+	if l.clientByConsumer {
+		if consumer, ok := middleware.ConsumerID.FromContext(req.Context()); ok && consumer != "" {
+			return "consumer:" + consumer
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func (l *streamLimits) throttle(ctx context.Context, endpoint *bandwidth, direction string) *throttle {
+	stream := newBandwidth(l.streamRate)
+	if stream == nil && endpoint == nil {
+		return nil
+	}
+	return &throttle{
+		ctx:      ctx,
+		stream:   stream,
+		endpoint: endpoint,
+		waited:   _metricStreamThrottled.WithLabelValues(append(l.labels, direction)...),
+	}
+}
+
+// tripper counts the stream until it finishes and throttles its bodies, it is below the middlewares so
+// the consumer id set by them is known.
+func (l *streamLimits) tripper(tripper http.RoundTripper) http.RoundTripper {
+	if l == nil {
+		return tripper
+	}
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		client := l.client(req)
+		if err := l.counters.acquire(client, l.maxStreams, l.maxClientStreams); err != nil {
+			reason := "endpoint"
+			if errors.Is(err, ErrClientStreamLimitExceeded) {
+				reason = "client"
+			}
+			_metricStreamLimitRejected.WithLabelValues(append(l.labels, reason)...).Inc()
+			// the stream never reaches the upstream, so the middlewares above, eg: the circuit breaker,
+			// do not account the rejection to the upstream
+			middleware.MarkResponseSource(req, _streamLimitsSource)
+			return nil, err
+		}
+		release := sync.OnceFunc(func() { l.counters.release(client) })
+		if streamCtx, ok := metaStreamContext(req); ok {
+			streamCtx.OnFinish = append(streamCtx.OnFinish, func(*http.Request, *http.Response) { release() })
+		} else {
+			// not proxied by the stream handler, the stream is counted during the round trip only
+			defer release()
+		}
+		upload := l.throttle(req.Context(), l.endpointUpload, streamDirectionUpload)
+		download := l.throttle(req.Context(), l.endpointDownload, streamDirectionDownload)
+		if upload != nil && req.Body != nil && req.Body != http.NoBody {
+			outreq := *req
+			outreq.Body = &throttledReadCloser{ReadCloser: req.Body, throttle: upload}
+			req = &outreq
+		}
+		resp, err := tripper.RoundTrip(req)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if download == nil || resp.Body == nil {
+			return resp, nil
+		}
+		if rwc, ok := resp.Body.(io.ReadWriteCloser); ok && resp.StatusCode == http.StatusSwitchingProtocols {
+			resp.Body = &throttledReadWriteCloser{ReadWriteCloser: rwc, download: download, upload: upload}
+			return resp, nil
+		}
+		resp.Body = &throttledReadCloser{ReadCloser: resp.Body, throttle: download}
+		return resp, nil
+	})
+}
+
+func metaStreamContext(req *http.Request) (*middleware.MetaStreamContext, bool) {
+	reqOpts, ok := middleware.FromRequestContext(req.Context())
+	if !ok {
+		return nil, false
+	}
+	return middleware.GetMetaStreamContext(reqOpts)
+}
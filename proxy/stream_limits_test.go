@@ -0,0 +1,225 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/circuitbreaker"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	circuitbreakerv1 "github.com/aide-family/goddess/pkg/middleware/circuitbreaker/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newTestThrottle(ctx context.Context, stream, endpoint *bandwidth) *throttle {
+	return &throttle{ctx: ctx, stream: stream, endpoint: endpoint, waited: prometheus.NewCounter(prometheus.CounterOpts{Name: "test"})}
+}
+
+func assertDuration(t *testing.T, got, want time.Duration) {
+	t.Helper()
+	if got < want*9/10 || got > want*11/10 {
+		t.Fatalf("want the transfer taking %s within 10%% but took %s", want, got)
+	}
+}
+
+func TestStreamBandwidth(t *testing.T) {
+	const rate = 256 << 10
+	start := time.Now()
+	body := &throttledReadCloser{
+		ReadCloser: io.NopCloser(bytes.NewReader(make([]byte, rate/2))),
+		throttle:   newTestThrottle(context.Background(), newBandwidth(rate), nil),
+	}
+	if n, err := io.Copy(io.Discard, body); err != nil || n != rate/2 {
+		t.Fatalf("want %d bytes copied but got %d: %v", rate/2, n, err)
+	}
+	assertDuration(t, time.Since(start), 500*time.Millisecond)
+
+	// the endpoint bandwidth is shared by the streams
+	endpoint := newBandwidth(rate)
+	start = time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var conn bytes.Buffer
+			w := &throttledReadWriteCloser{upload: newTestThrottle(context.Background(), newBandwidth(rate), endpoint)}
+			w.ReadWriteCloser = struct {
+				io.ReadWriter
+				io.Closer
+			}{&conn, io.NopCloser(nil)}
+			if n, err := w.Write(make([]byte, rate/4)); err != nil || n != rate/4 {
+				t.Errorf("want %d bytes written but got %d: %v", rate/4, n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	assertDuration(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestStreamBandwidthCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	body := &throttledReadCloser{
+		ReadCloser: io.NopCloser(bytes.NewReader(make([]byte, 1<<20))),
+		throttle:   newTestThrottle(ctx, newBandwidth(1<<10), nil),
+	}
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := io.Copy(io.Discard, body)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want canceled but got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("want the throttled read returning once canceled but took %s", elapsed)
+	}
+}
+
+func TestProxyStreamLimits(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/stream",
+			Method:   "GET",
+			Stream:   true,
+			StreamLimits: &config.StreamLimits{
+				MaxStreams:       2,
+				MaxClientStreams: 1,
+			},
+		}},
+	}
+	started := make(chan struct{}, 4)
+	var lock sync.Mutex
+	var writers []*io.PipeWriter
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			r, w := io.Pipe()
+			lock.Lock()
+			writers = append(writers, w)
+			lock.Unlock()
+			started <- struct{}{}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: r}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	newRequest := func(ip string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		req.RemoteAddr = ip + ":1234"
+		return req
+	}
+
+	var wg sync.WaitGroup
+	open := func(ip string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.ServeHTTP(httptest.NewRecorder(), newRequest(ip))
+		}()
+		<-started
+	}
+	open("10.0.0.1")
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, newRequest("10.0.0.1"))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusTooManyRequests, _reasonClientStreamLimitExceeded)
+
+	open("10.0.0.2")
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, newRequest("10.0.0.3"))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusServiceUnavailable, _reasonStreamLimitExceeded)
+
+	// the streams are counted across the reloads
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, newRequest("10.0.0.3"))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusServiceUnavailable, _reasonStreamLimitExceeded)
+
+	lock.Lock()
+	for _, w := range writers {
+		w.Close()
+	}
+	lock.Unlock()
+	wg.Wait()
+	open("10.0.0.1")
+	lock.Lock()
+	writers[len(writers)-1].Close()
+	lock.Unlock()
+	wg.Wait()
+}
+
+func TestStreamLimitsRequireStream(t *testing.T) {
+	p, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.newStreamLimits(&config.Endpoint{StreamLimits: &config.StreamLimits{MaxStreams: 1}}); err == nil {
+		t.Fatal("want an error for the stream limits of a unary endpoint")
+	}
+}
+
+func TestStreamLimitsCircuitBreaker(t *testing.T) {
+	breaker := middlewaretest.NewConfig("circuitbreaker", &circuitbreakerv1.CircuitBreaker{
+		Trigger: &circuitbreakerv1.CircuitBreaker_SuccessRatio{SuccessRatio: &circuitbreakerv1.SuccessRatio{Success: 0.9, Request: 1}},
+		Action:  &circuitbreakerv1.CircuitBreaker_ResponseData{ResponseData: &circuitbreakerv1.ResponseData{StatusCode: http.StatusTeapot}},
+	})
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:     config.Protocol_HTTP,
+			Path:         "/stream",
+			Method:       "GET",
+			Stream:       true,
+			StreamLimits: &config.StreamLimits{MaxStreams: 1},
+			Middlewares:  []*config.Middleware{breaker},
+		}},
+	}
+	r, w := io.Pipe()
+	defer w.Close()
+	started := make(chan struct{}, 1)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: r}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, circuitbreaker.New(clientFactory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+	}()
+	<-started
+
+	// the rejections of the stream limits are not the failures of the upstream, the breaker is kept closed
+	for i := 0; i < 50; i++ {
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		middlewaretest.AssertErrorResponse(t, rec.Result(), http.StatusServiceUnavailable, _reasonStreamLimitExceeded)
+	}
+	w.Close()
+	<-done
+}
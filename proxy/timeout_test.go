@@ -0,0 +1,189 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestTimeoutSources(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	slowURL, err := url.Parse(slow.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the upstream transport of the endpoints, the header timeout is only set for the response_header endpoint
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		transport := &http.Transport{}
+		if e.Path == "/response-header" {
+			transport.ResponseHeaderTimeout = 50 * time.Millisecond
+		}
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			req.URL.Scheme, req.URL.Host = slowURL.Scheme, slowURL.Host
+			return transport.RoundTrip(req)
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/request",
+		Method:   "GET",
+		Timeout:  durationpb.New(50 * time.Millisecond),
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/attempt",
+		Method:   "GET",
+		Timeout:  durationpb.New(5 * time.Second),
+		Retry:    &config.Retry{Attempts: 1, PerTryTimeout: durationpb.New(50 * time.Millisecond)},
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/response-header",
+		Method:   "GET",
+		Timeout:  durationpb.New(5 * time.Second),
+	}, {
+		Protocol: config.Protocol_HTTP,
+		Path:     "/client-deadline",
+		Method:   "GET",
+		Timeout:  durationpb.New(5 * time.Second),
+		DeadlinePropagation: &config.DeadlinePropagation{
+			Header:       "X-Request-Timeout-Ms",
+			TrustedCidrs: []string{"192.0.2.0/24"},
+		},
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path   string
+		source middleware.TimeoutSource
+	}{
+		{"/request", middleware.TimeoutSourceRequest},
+		{"/attempt", middleware.TimeoutSourceAttempt},
+		{"/response-header", middleware.TimeoutSourceResponseHeader},
+		{"/client-deadline", middleware.TimeoutSourceClientDeadline},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.path, nil)
+		req.Header.Set("X-Request-Timeout-Ms", "50")
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		out := middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusGatewayTimeout, _reasonGatewayTimeout)
+		if got := out.Metadata["timeout_source"]; got != string(test.source) {
+			t.Errorf("%s: want the timeout source %s but got %q", test.path, test.source, got)
+		}
+		counter := _metricRequestTimeouts.WithLabelValues("HTTP", http.MethodGet, test.path, "", "", string(test.source))
+		if testutil.ToFloat64(counter) != 1 {
+			t.Errorf("%s: want the timeout counted by the source", test.path)
+		}
+	}
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	// the stalled upstream accepts the connections but never replies
+	stalled, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stalled.Close()
+	var accepted atomic.Int64
+	go func() {
+		for {
+			conn, err := stalled.Accept()
+			if err != nil {
+				return
+			}
+			accepted.Add(1)
+			// the connections are held open until the listener is closed
+			defer conn.Close()
+		}
+	}()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer healthy.Close()
+
+	const headerTimeout = 200 * time.Millisecond
+	endpoint := func(path string, backends ...string) *config.Endpoint {
+		e := &config.Endpoint{
+			Protocol:              config.Protocol_HTTP,
+			Path:                  path,
+			Method:                "GET",
+			Timeout:               durationpb.New(10 * time.Second),
+			ResponseHeaderTimeout: durationpb.New(headerTimeout),
+			Retry:                 &config.Retry{Attempts: uint32(len(backends))},
+		}
+		for _, b := range backends {
+			e.Backends = append(e.Backends, &config.Backend{Target: b})
+		}
+		return e
+	}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{
+		endpoint("/retried", stalled.Addr().String(), strings.TrimPrefix(healthy.URL, "http://")),
+		endpoint("/stalled", stalled.Addr().String()),
+	}}
+	p, err := New(client.NewFactory(nil), func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	// the requests picking the stalled node are retried onto the healthy one within the header timeout
+	for i := 0; i < 20 && accepted.Load() == 0; i++ {
+		start := time.Now()
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/retried", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "ok" {
+			t.Fatalf("want the response of the healthy node but got %d %s", w.Code, w.Body.String())
+		}
+		if elapsed := time.Since(start); elapsed > headerTimeout+time.Second {
+			t.Fatalf("want the request served within the header timeout but took %s", elapsed)
+		}
+	}
+	if accepted.Load() == 0 {
+		t.Fatal("want the stalled node picked by an attempt")
+	}
+
+	classified := MetricRequestsErrorClass.WithLabelValues("HTTP", http.MethodGet, "/stalled", "", "", string(ErrorClassUpstreamHeaderTimeout))
+	before := testutil.ToFloat64(classified)
+	start := time.Now()
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stalled", nil))
+	out := middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusGatewayTimeout, _reasonGatewayTimeout)
+	if got := out.Metadata["timeout_source"]; got != string(middleware.TimeoutSourceResponseHeader) {
+		t.Fatalf("want the response header timeout but got %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > headerTimeout+time.Second {
+		t.Fatalf("want the stalled upstream given up after the header timeout but took %s", elapsed)
+	}
+	if got := testutil.ToFloat64(classified) - before; got != 1 {
+		t.Fatalf("want the request classified as upstream_header_timeout but got %v", got)
+	}
+}
@@ -0,0 +1,20 @@
+package proxy
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// _tracer traces the updates of the proxy. The spans are exported by the tracer provider of the tracing
+// middleware, and dropped until a tracing middleware is configured.
+var _tracer = otel.Tracer("gateway")
+
+// endSpan ends the span with the error recorded if any.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,101 @@
+package proxy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestUpdateSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	errBuild := errors.New("build failed")
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		if e.Path == "/broken" {
+			return nil, errBuild
+		}
+		return RoundTripperCloserFunc(nil), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Version: "v1", Endpoints: []*config.Endpoint{
+		{Protocol: config.Protocol_HTTP, Path: "/api/users", Method: "GET"},
+		{Protocol: config.Protocol_HTTP, Path: "/api/orders", Method: "GET"},
+	}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	var update sdktrace.ReadOnlySpan
+	builds := map[string]sdktrace.ReadOnlySpan{}
+	children := map[string]int{}
+	for _, s := range spans {
+		switch s.Name() {
+		case "proxy update":
+			update = s
+		case "build endpoint":
+			builds[spanAttr(s, "endpoint.path").AsString()] = s
+		case "build client", "build middlewares":
+			children[s.Parent().SpanID().String()]++
+		}
+	}
+	if update == nil {
+		t.Fatal("want the update traced")
+	}
+	if spanAttr(update, "config.version").AsString() != "v1" || spanAttr(update, "config.endpoints").AsInt64() != 2 {
+		t.Fatalf("unexpected update attributes %+v", update.Attributes())
+	}
+	if len(builds) != 2 {
+		t.Fatalf("want a span per endpoint but got %d", len(builds))
+	}
+	for path, s := range builds {
+		if s.Parent().SpanID() != update.SpanContext().SpanID() {
+			t.Fatalf("want the build of %s a child of the update", path)
+		}
+		if children[s.SpanContext().SpanID().String()] != 2 {
+			t.Fatalf("want the client and the middlewares traced for %s", path)
+		}
+	}
+
+	recorder = tracetest.NewSpanRecorder()
+	otel.GetTracerProvider().(*sdktrace.TracerProvider).RegisterSpanProcessor(recorder)
+	c = &config.Gateway{Version: "v2", Endpoints: []*config.Endpoint{
+		{Protocol: config.Protocol_HTTP, Path: "/broken", Method: "GET"},
+	}}
+	if err := p.Update(client.NewBuildContext(c), c); !errors.Is(err, errBuild) {
+		t.Fatalf("want the build error but got %v", err)
+	}
+	failed := 0
+	for _, s := range recorder.Ended() {
+		if s.Status().Code == codes.Error {
+			failed++
+		}
+	}
+	// the update, the endpoint and the client
+	if failed != 3 {
+		t.Fatalf("want the error recorded along the build but got %d failed spans", failed)
+	}
+}
+
+func spanAttr(s sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range s.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/aide-family/goddess/watchdog"
+)
+
+const (
+	_defaultUpstreamBodyBytes = 4096
+	_maxUpstreamBodyBytes     = 64 << 10
+)
+
+// upstreamBodyCapture records the beginning of the upstream response bodies of the error statuses.
+type upstreamBodyCapture struct {
+	maxBytes             int
+	statusMin, statusMax int
+	mask                 *regexp.Regexp
+}
+
+func newUpstreamBodyCapture(e *config.Endpoint) (*upstreamBodyCapture, error) {
+	c := e.UpstreamBodyCapture
+	if c == nil {
+		return nil, nil
+	}
+	bc := &upstreamBodyCapture{
+		maxBytes:  int(c.MaxBytes),
+		statusMin: int(c.StatusMin),
+		statusMax: int(c.StatusMax),
+	}
+	if bc.maxBytes == 0 {
+		bc.maxBytes = _defaultUpstreamBodyBytes
+	}
+	if bc.maxBytes > _maxUpstreamBodyBytes {
+		return nil, errors.New("upstream body capture max_bytes must not exceed 65536")
+	}
+	if bc.statusMin == 0 && bc.statusMax == 0 {
+		bc.statusMin, bc.statusMax = 500, 599
+	}
+	if bc.statusMax < bc.statusMin {
+		return nil, errors.New("upstream body capture status_max must not be less than status_min")
+	}
+	if len(c.MaskFields) > 0 {
+		names := make([]string, 0, len(c.MaskFields))
+		for _, name := range c.MaskFields {
+			names = append(names, regexp.QuoteMeta(name))
+		}
+		// the values are matched textually so the truncated bodies are masked as well
+		bc.mask = regexp.MustCompile(`("(?:` + strings.Join(names, "|") + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]*)`)
+	}
+	return bc, nil
+}
+
+// wrap records the body of the response while it is read if the status is in the range, nil is returned
+// if the response is not recorded, eg: the optional features are disabled by the watchdog.
+func (c *upstreamBodyCapture) wrap(resp *http.Response) *capturedBody {
+	if c == nil || resp.StatusCode < c.statusMin || resp.StatusCode > c.statusMax || watchdog.OptionalDisabled() {
+		return nil
+	}
+	b := &capturedBody{capture: c, statusCode: resp.StatusCode}
+	if resp.Body != nil && resp.Body != http.NoBody {
+		resp.Body = &capturingReader{ReadCloser: resp.Body, body: b}
+	}
+	return b
+}
+
+// marker records the response of the stream endpoint as truncated without its body.
+func (c *upstreamBodyCapture) marker(resp *http.Response) *capturedBody {
+	if c == nil || resp.StatusCode < c.statusMin || resp.StatusCode > c.statusMax || watchdog.OptionalDisabled() {
+		return nil
+	}
+	return &capturedBody{capture: c, statusCode: resp.StatusCode, truncated: true}
+}
+
+// capturedBody is the beginning of the response body read by the copy path.
+type capturedBody struct {
+	capture    *upstreamBodyCapture
+	statusCode int
+	buf        []byte
+	truncated  bool
+}
+
+// String returns the recorded body with the fields masked.
+func (b *capturedBody) String() string {
+	if b.capture.mask == nil {
+		return string(b.buf)
+	}
+	return b.capture.mask.ReplaceAllString(string(b.buf), `${1}"[redacted]"`)
+}
+
+// capturingReader copies the bytes read by the copy path into the captured body until it is full.
+type capturingReader struct {
+	io.ReadCloser
+	body *capturedBody
+}
+
+func (r *capturingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		b := r.body
+		room := b.capture.maxBytes - len(b.buf)
+		if n > room {
+			b.truncated = true
+		}
+		if room > 0 {
+			b.buf = append(b.buf, p[:min(n, room)]...)
+		}
+	}
+	return n, err
+}
+
+// logUpstreamBody writes the captured body to the error log.
+func logUpstreamBody(req *http.Request, e *config.Endpoint, b *capturedBody) {
+	if b == nil {
+		return
+	}
+	upstream := ""
+	if opts, ok := middleware.FromRequestContext(req.Context()); ok {
+		upstream = opts.Upstream().Addr
+	}
+	_errorLog.WithContext(req.Context()).Warnw(
+		"msg", "upstream error response",
+		"method", req.Method,
+		"path", req.URL.Path,
+		"route", e.Path,
+		"upstream", upstream,
+		"code", b.statusCode,
+		"stream", e.Stream,
+		"body", b.String(),
+		"truncated", b.truncated,
+	)
+}
@@ -0,0 +1,100 @@
+package proxy
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func TestUpstreamBodyCapture(t *testing.T) {
+	const body = `{"password":"hunter2","token": 1234,"message":"internal error"}`
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			statusCode := http.StatusInternalServerError
+			if req.URL.Path == "/ok" {
+				statusCode = http.StatusOK
+			}
+			return &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(*config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	capture := &config.UpstreamBodyCapture{MaxBytes: 36, MaskFields: []string{"password", "token"}}
+	c := &config.Gateway{Endpoints: []*config.Endpoint{{
+		Protocol:            config.Protocol_HTTP,
+		Path:                "/error",
+		Method:              "GET",
+		UpstreamBodyCapture: capture,
+	}, {
+		Protocol:            config.Protocol_HTTP,
+		Path:                "/ok",
+		Method:              "GET",
+		UpstreamBodyCapture: capture,
+	}}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	debug := p.DebugHandler()
+	w := httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/debug/proxy/slow-requests/config", strings.NewReader(`{"threshold":"1ns"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want the config updated but got %d", w.Code)
+	}
+
+	for _, path := range []string{"/ok", "/error"} {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != body {
+			t.Fatalf("want the client to receive the full body but got %q", w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	debug.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/proxy/slow-requests", nil))
+	out := &slowRequestsResponse{}
+	if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Requests) != 2 {
+		t.Fatalf("want 2 slow requests but got %+v", out.Requests)
+	}
+	if entry := out.Requests[1]; entry.UpstreamBody != "" {
+		t.Fatalf("want the body of the success not captured but got %q", entry.UpstreamBody)
+	}
+	entry := out.Requests[0]
+	if want := `{"password":"[redacted]","token": "[redacted]",`; entry.UpstreamBody != want || !entry.UpstreamBodyTruncated {
+		t.Fatalf("want the masked and truncated body %q but got %q, truncated %v", want, entry.UpstreamBody, entry.UpstreamBodyTruncated)
+	}
+}
+
+func TestUpstreamBodyCaptureConfig(t *testing.T) {
+	for _, c := range []*config.UpstreamBodyCapture{
+		{MaxBytes: 1 << 20},
+		{StatusMin: 500, StatusMax: 400},
+	} {
+		if _, err := newUpstreamBodyCapture(&config.Endpoint{UpstreamBodyCapture: c}); err == nil {
+			t.Fatalf("want the invalid config %v rejected", c)
+		}
+	}
+	bc, err := newUpstreamBodyCapture(&config.Endpoint{UpstreamBodyCapture: &config.UpstreamBodyCapture{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bc.maxBytes != _defaultUpstreamBodyBytes || bc.statusMin != 500 || bc.statusMax != 599 {
+		t.Fatalf("unexpected defaults %+v", bc)
+	}
+	if b := bc.marker(&http.Response{StatusCode: http.StatusBadGateway}); b == nil || !b.truncated || b.String() != "" {
+		t.Fatalf("want the stream response marked truncated but got %+v", b)
+	}
+}
@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+const (
+	upstreamAddrHeader = "X-Upstream-Addr"
+	attemptCountHeader = "X-Attempt-Count"
+)
+
+// upstreamDebugHeaders writes the upstream details to the response of trusted debug requests.
+type upstreamDebugHeaders struct {
+	header string
+	values map[string]struct{}
+}
+
+var errUpstreamDebugValues = errors.New("upstream_debug_headers: values must not be empty, the upstream addresses are only written to the trusted requests")
+
+func newUpstreamDebugHeaders(e *config.Endpoint) (*upstreamDebugHeaders, error) {
+	c := e.UpstreamDebugHeaders
+	if c == nil || c.Header == "" {
+		return nil, nil
+	}
+	if len(c.Values) == 0 {
+		return nil, errUpstreamDebugValues
+	}
+	return newDebugTrigger(c.Header, c.Values), nil
+}
+
+// newDebugTrigger returns the trigger of the header, any non-empty value of the header is trusted if values is empty.
+func newDebugTrigger(header string, values []string) *upstreamDebugHeaders {
+	d := &upstreamDebugHeaders{
+		header: header,
+		values: make(map[string]struct{}, len(values)),
+	}
+	for _, v := range values {
+		d.values[v] = struct{}{}
+	}
+	return d
+}
+
+func (d *upstreamDebugHeaders) trusted(req *http.Request) bool {
+	if d == nil {
+		return false
+	}
+	v := req.Header.Get(d.header)
+	if v == "" {
+		return false
+	}
+	if len(d.values) == 0 {
+		return true
+	}
+	_, ok := d.values[v]
+	return ok
+}
+
+// strip removes the trigger header from the request so it is not proxied to the upstream.
+func (d *upstreamDebugHeaders) strip(req *http.Request) {
+	if d != nil {
+		req.Header.Del(d.header)
+	}
+}
+
+// Write sets the debug headers into the response header if the request is trusted.
+func (d *upstreamDebugHeaders) Write(trusted bool, reqOpts *middleware.RequestOptions, header http.Header) {
+	if !trusted {
+		return
+	}
+	upstream := reqOpts.Upstream()
+	if upstream.Addr != "" {
+		header.Set(upstreamAddrHeader, upstream.Addr)
+	}
+	header.Set(attemptCountHeader, strconv.Itoa(upstream.Attempts))
+}
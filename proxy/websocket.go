@@ -0,0 +1,205 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/aide-family/goddess/middleware"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// ErrWebSocketOriginNotAllowed is returned when the Origin of the upgrade is not allowed by the endpoint.
+	ErrWebSocketOriginNotAllowed = errors.New("websocket origin not allowed")
+	// ErrWebSocketSubprotocolNotAllowed is returned when none of the offered subprotocols is allowed by the endpoint.
+	ErrWebSocketSubprotocolNotAllowed = errors.New("websocket subprotocol not allowed")
+)
+
+var (
+	_metricWebSocketUpgrades = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "websocket_upgrades_total",
+		Help:      "The total number of websocket upgrades accepted by the upstream by the negotiated subprotocol",
+	}, []string{"protocol", "method", "path", "service", "basePath", "subprotocol"})
+	_metricWebSocketRejected = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "websocket_rejected_total",
+		Help:      "The total number of websocket upgrades rejected by the websocket policy",
+	}, []string{"protocol", "method", "path", "service", "basePath", "reason"})
+)
+
+func init() {
+	goddessmetrics.Register(_metricWebSocketUpgrades)
+	goddessmetrics.Register(_metricWebSocketRejected)
+}
+
+type originPattern struct {
+	scheme string
+	host   string
+	// the host is a suffix like .example.com
+	wildcard bool
+}
+
+func (p *originPattern) match(u *url.URL) bool {
+	if p.scheme != u.Scheme {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	if p.wildcard {
+		return strings.HasSuffix(host, p.host) && len(host) > len(p.host)
+	}
+	return host == p.host
+}
+
+// webSocketPolicy checks the websocket upgrades of the stream endpoint before they reach the upstream.
+type webSocketPolicy struct {
+	subprotocols      map[string]struct{}
+	origins           []*originPattern
+	requireOrigin     bool
+	extensions        map[string]struct{}
+	disableExtensions bool
+	labels            middleware.MetricsLabels
+}
+
+func newWebSocketPolicy(e *config.Endpoint) (*webSocketPolicy, error) {
+	c := e.Websocket
+	if c == nil {
+		return nil, nil
+	}
+	if !e.Stream {
+		return nil, errors.New("websocket policy requires a stream endpoint")
+	}
+	p := &webSocketPolicy{
+		requireOrigin:     c.RequireOrigin,
+		disableExtensions: c.DisableExtensions,
+		labels:            middleware.NewMetricsLabels(e),
+	}
+	if len(c.Subprotocols) > 0 {
+		p.subprotocols = make(map[string]struct{}, len(c.Subprotocols))
+		for _, protocol := range c.Subprotocols {
+			p.subprotocols[protocol] = struct{}{}
+		}
+	}
+	if len(c.AllowedExtensions) > 0 {
+		p.extensions = make(map[string]struct{}, len(c.AllowedExtensions))
+		for _, extension := range c.AllowedExtensions {
+			p.extensions[strings.ToLower(extension)] = struct{}{}
+		}
+	}
+	for _, origin := range c.AllowedOrigins {
+		u, err := url.Parse(strings.ToLower(origin))
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return nil, fmt.Errorf("invalid websocket allowed origin %q: scheme://host[:port] is required", origin)
+		}
+		pattern := &originPattern{scheme: u.Scheme, host: u.Host}
+		if strings.HasPrefix(u.Host, "*.") {
+			pattern.host, pattern.wildcard = u.Host[1:], true
+		}
+		p.origins = append(p.origins, pattern)
+	}
+	return p, nil
+}
+
+// check returns the error of the rejected upgrade, the offers not allowed are removed from the request.
+// The requests other than the websocket upgrades are not checked.
+func (p *webSocketPolicy) check(req *http.Request) error {
+	if p == nil || !isWebSocketRequest(req) {
+		return nil
+	}
+	if err := p.checkOrigin(req.Header.Get("Origin")); err != nil {
+		return err
+	}
+	if p.subprotocols != nil {
+		offers := headerTokens(req.Header.Values("Sec-WebSocket-Protocol"))
+		if len(offers) > 0 {
+			accepted := offers[:0]
+			for _, offer := range offers {
+				if _, ok := p.subprotocols[offer]; ok {
+					accepted = append(accepted, offer)
+				}
+			}
+			if len(accepted) == 0 {
+				return ErrWebSocketSubprotocolNotAllowed
+			}
+			req.Header.Set("Sec-WebSocket-Protocol", strings.Join(accepted, ", "))
+		}
+	}
+	switch {
+	case p.disableExtensions:
+		req.Header.Del("Sec-WebSocket-Extensions")
+	case p.extensions != nil:
+		var accepted []string
+		for _, offer := range headerTokens(req.Header.Values("Sec-WebSocket-Extensions")) {
+			name, _, _ := strings.Cut(offer, ";")
+			if _, ok := p.extensions[strings.ToLower(strings.TrimSpace(name))]; ok {
+				accepted = append(accepted, offer)
+			}
+		}
+		req.Header.Del("Sec-WebSocket-Extensions")
+		if len(accepted) > 0 {
+			req.Header.Set("Sec-WebSocket-Extensions", strings.Join(accepted, ", "))
+		}
+	}
+	return nil
+}
+
+func (p *webSocketPolicy) checkOrigin(origin string) error {
+	if origin == "" {
+		if p.requireOrigin {
+			return ErrWebSocketOriginNotAllowed
+		}
+		return nil
+	}
+	if len(p.origins) == 0 {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return ErrWebSocketOriginNotAllowed
+	}
+	for _, pattern := range p.origins {
+		if pattern.match(u) {
+			return nil
+		}
+	}
+	return ErrWebSocketOriginNotAllowed
+}
+
+// observeUpgrade counts the upgrade accepted by the upstream by the negotiated subprotocol.
+func (p *webSocketPolicy) observeUpgrade(resp *http.Response) {
+	if p == nil || resp.StatusCode != http.StatusSwitchingProtocols {
+		return
+	}
+	_metricWebSocketUpgrades.WithLabelValues(p.labels.Protocol(), p.labels.Method(), p.labels.Path(), p.labels.Service(), p.labels.BasePath(),
+		resp.Header.Get("Sec-WebSocket-Protocol")).Inc()
+}
+
+func (p *webSocketPolicy) writeError(w http.ResponseWriter, r *http.Request, err error, observer Observer) {
+	statusCode, reason, label := http.StatusForbidden, "WEBSOCKET_ORIGIN_NOT_ALLOWED", "origin"
+	if errors.Is(err, ErrWebSocketSubprotocolNotAllowed) {
+		statusCode, reason, label = http.StatusBadRequest, "WEBSOCKET_SUBPROTOCOL_NOT_ALLOWED", "subprotocol"
+	}
+	_metricWebSocketRejected.WithLabelValues(p.labels.Protocol(), p.labels.Method(), p.labels.Path(), p.labels.Service(), p.labels.BasePath(), label).Inc()
+	observer.HandleRequest(r, w.Header(), statusCode, err)
+	writeErrorResponse(w, statusCode, reason, err.Error())
+}
+
+// headerTokens splits the comma-separated values of the header.
+func headerTokens(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package proxy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aide-family/goddess/client"
+	"github.com/aide-family/goddess/middleware"
+	"github.com/aide-family/goddess/middleware/middlewaretest"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+)
+
+func newWebSocketRequest(origin string, protocols, extensions string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if protocols != "" {
+		req.Header.Set("Sec-WebSocket-Protocol", protocols)
+	}
+	if extensions != "" {
+		req.Header.Set("Sec-WebSocket-Extensions", extensions)
+	}
+	return req
+}
+
+func TestWebSocketPolicy(t *testing.T) {
+	p, err := newWebSocketPolicy(&config.Endpoint{
+		Path:   "/ws",
+		Stream: true,
+		Websocket: &config.WebSocketPolicy{
+			Subprotocols:      []string{"graphql-ws", "v2.chat"},
+			AllowedOrigins:    []string{"https://app.example.com", "https://*.example.org"},
+			AllowedExtensions: []string{"permessage-deflate"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		req        *http.Request
+		err        error
+		protocols  string
+		extensions string
+	}{
+		{name: "allowed", req: newWebSocketRequest("https://app.example.com", "v1.chat, v2.chat", "permessage-deflate; client_max_window_bits, x-webkit-deflate-frame"),
+			protocols: "v2.chat", extensions: "permessage-deflate; client_max_window_bits"},
+		{name: "wildcard origin", req: newWebSocketRequest("https://a.b.example.org", "", "")},
+		{name: "without origin", req: newWebSocketRequest("", "", "")},
+		{name: "origin", req: newWebSocketRequest("https://evil.example.com", "", ""), err: ErrWebSocketOriginNotAllowed},
+		{name: "origin scheme", req: newWebSocketRequest("http://app.example.com", "", ""), err: ErrWebSocketOriginNotAllowed},
+		{name: "wildcard apex", req: newWebSocketRequest("https://example.org", "", ""), err: ErrWebSocketOriginNotAllowed},
+		{name: "subprotocol", req: newWebSocketRequest("https://app.example.com", "v1.chat", ""), err: ErrWebSocketSubprotocolNotAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := p.check(test.req); !errors.Is(err, test.err) {
+				t.Fatalf("want %v but got %v", test.err, err)
+			}
+			if got := test.req.Header.Get("Sec-WebSocket-Protocol"); test.err == nil && got != test.protocols {
+				t.Fatalf("want protocols %q but got %q", test.protocols, got)
+			}
+			if got := test.req.Header.Get("Sec-WebSocket-Extensions"); test.err == nil && got != test.extensions {
+				t.Fatalf("want extensions %q but got %q", test.extensions, got)
+			}
+		})
+	}
+
+	// the other requests of the route are not checked
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	if err := p.check(req); err != nil {
+		t.Fatalf("want the non-websocket request passed but got %v", err)
+	}
+
+	if _, err := newWebSocketPolicy(&config.Endpoint{Websocket: &config.WebSocketPolicy{}}); err == nil {
+		t.Fatal("want the policy rejected on the non-stream endpoint")
+	}
+	if _, err := newWebSocketPolicy(&config.Endpoint{Stream: true, Websocket: &config.WebSocketPolicy{AllowedOrigins: []string{"example.com"}}}); err == nil {
+		t.Fatal("want the origin without the scheme rejected")
+	}
+}
+
+func TestProxyWebSocketPolicy(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/ws",
+			Method:   "GET",
+			Stream:   true,
+			Websocket: &config.WebSocketPolicy{
+				AllowedOrigins:    []string{"https://app.example.com"},
+				DisableExtensions: true,
+			},
+		}},
+	}
+	var upstream []*http.Request
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			upstream = append(upstream, req)
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: nopBody}, nil
+		}), nil
+	}
+	p, err := New(clientFactory, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.EmptyMiddleware, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, newWebSocketRequest("https://evil.example.com", "", ""))
+	middlewaretest.AssertErrorResponse(t, w.Result(), http.StatusForbidden, "WEBSOCKET_ORIGIN_NOT_ALLOWED")
+	if len(upstream) != 0 {
+		t.Fatal("want the rejected upgrade not reaching the upstream")
+	}
+
+	p.ServeHTTP(httptest.NewRecorder(), newWebSocketRequest("https://app.example.com", "", "permessage-deflate"))
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ws", nil))
+	if len(upstream) != 2 {
+		t.Fatalf("want 2 requests reaching the upstream but got %d", len(upstream))
+	}
+	if ext := upstream[0].Header.Get("Sec-WebSocket-Extensions"); ext != "" {
+		t.Fatalf("want the extensions removed but got %q", ext)
+	}
+}
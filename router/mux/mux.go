@@ -37,9 +37,10 @@ type muxRouter struct {
 	allCloser []io.Closer
 }
 
+// ProtectedHandler rejects the requests passed by a proxy, so the handler is only reachable directly.
 func ProtectedHandler(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("X-Forwarded-For") != "" {
+		if router.IsForwarded(r) {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		}
@@ -47,11 +48,12 @@ func ProtectedHandler(h http.Handler) http.Handler {
 	})
 }
 
-// NewRouter new a mux router.
-func NewRouter(notFoundHandler, methodNotAllowedHandler http.Handler) router.Router {
+// NewRouter new a mux router, the closers are closed along with the router.
+func NewRouter(notFoundHandler, methodNotAllowedHandler http.Handler, closers ...io.Closer) router.Router {
 	r := &muxRouter{
-		Router: mux.NewRouter().StrictSlash(EnableStrictSlash),
-		wg:     &sync.WaitGroup{},
+		Router:    mux.NewRouter().StrictSlash(EnableStrictSlash),
+		wg:        &sync.WaitGroup{},
+		allCloser: closers,
 	}
 	r.Router.Handle("/metrics", ProtectedHandler(promhttp.Handler()))
 	r.Router.NotFoundHandler = notFoundHandler
@@ -134,12 +136,18 @@ func waitTimeout(ctx context.Context, wg *sync.WaitGroup) bool {
 	}
 }
 
+// Inspector is implemented by the route handlers which expose extra inspect info.
+type Inspector interface {
+	Inspect() interface{}
+}
+
 type RouterInspect struct {
-	PathTemplate     string   `json:"path_template"`
-	PathRegexp       string   `json:"path_regexp"`
-	QueriesTemplates []string `json:"queries_templates"`
-	QueriesRegexps   []string `json:"queries_regexps"`
-	Methods          []string `json:"methods"`
+	PathTemplate     string      `json:"path_template"`
+	PathRegexp       string      `json:"path_regexp"`
+	QueriesTemplates []string    `json:"queries_templates"`
+	QueriesRegexps   []string    `json:"queries_regexps"`
+	Methods          []string    `json:"methods"`
+	Endpoint         interface{} `json:"endpoint,omitempty"`
 }
 
 func InspectMuxRouter(in interface{}) []*RouterInspect {
@@ -154,13 +162,17 @@ func InspectMuxRouter(in interface{}) []*RouterInspect {
 		queriesTemplates, _ := route.GetQueriesTemplates()
 		queriesRegexps, _ := route.GetQueriesRegexp()
 		methods, _ := route.GetMethods()
-		out = append(out, &RouterInspect{
+		inspect := &RouterInspect{
 			PathTemplate:     pathTemplate,
 			PathRegexp:       pathRegexp,
 			QueriesTemplates: queriesTemplates,
 			QueriesRegexps:   queriesRegexps,
 			Methods:          methods,
-		})
+		}
+		if inspector, ok := route.GetHandler().(Inspector); ok {
+			inspect.Endpoint = inspector.Inspect()
+		}
+		out = append(out, inspect)
 		return nil
 	})
 	return out
@@ -1,6 +1,8 @@
 package mux
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -32,3 +34,25 @@ func TestPathClean(t *testing.T) {
 		}
 	}
 }
+
+func TestProtectedHandler(t *testing.T) {
+	h := ProtectedHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/statusz", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want the direct request served but got %d", w.Code)
+	}
+	for key, value := range map[string]string{
+		"X-Forwarded-For": "203.0.113.1",
+		"Forwarded":       "for=203.0.113.1",
+		"X-Real-Ip":       "203.0.113.1",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/debug/statusz", nil)
+		req.Header.Set(key, value)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("want the request with %s rejected but got %d", key, w.Code)
+		}
+	}
+}
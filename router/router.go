@@ -12,3 +12,16 @@ type Router interface {
 	Handle(pattern, method, host string, handler http.Handler, closer io.Closer) error
 	SyncClose(ctx context.Context) error
 }
+
+// ForwardedHeaders is the request headers set by the proxies in front of the gateway.
+var ForwardedHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Forwarded-Proto", "X-Forwarded-Host", "X-Real-Ip"}
+
+// IsForwarded reports whether the request is passed by a proxy, ie: it carries any of the forwarded headers.
+func IsForwarded(req *http.Request) bool {
+	for _, key := range ForwardedHeaders {
+		if _, ok := req.Header[key]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const (
+	// 1/3 of the 90 days lifetime of Let's Encrypt certificates, the certificates are renewed at 2/3 lifetime.
+	_defaultACMERenewBefore = 30 * 24 * time.Hour
+	_defaultACMECacheDir    = "./acme"
+)
+
+var (
+	_metricACMECertificates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "acme_certificates_total",
+		Help:      "The total number of ACME certificate issuance and renewal results",
+	}, []string{"domain", "result"})
+	_metricACMECertificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "acme_certificate_expiry_timestamp_seconds",
+		Help:      "The expiry timestamp of the ACME certificates",
+	}, []string{"domain"})
+)
+
+func init() {
+	metrics.Register(_metricACMECertificates, _metricACMECertificateExpiry)
+}
+
+// ACMEConfig is the config of ACME certificate management.
+type ACMEConfig struct {
+	Domains []string
+	// default is Let's Encrypt production directory.
+	DirectoryURL string
+	Email        string
+	// directory of the account key and certificates, default is ./acme
+	CacheDir string
+	// certificates are renewed RenewBefore they expire, default is 30 days.
+	RenewBefore time.Duration
+}
+
+// ACME obtains and renews certificates of the TLS listeners, the HTTP-01 challenge is served by
+// HTTPHandler on the plaintext listeners and the TLS-ALPN-01 challenge by the TLS config.
+type ACME struct {
+	manager *autocert.Manager
+	domains map[string]struct{}
+}
+
+// NewACME new an ACME certificate manager.
+func NewACME(c *ACMEConfig) (*ACME, error) {
+	if len(c.Domains) == 0 {
+		return nil, errors.New("acme domains are required")
+	}
+	cacheDir := c.CacheDir
+	if cacheDir == "" {
+		cacheDir = _defaultACMECacheDir
+	}
+	renewBefore := c.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = _defaultACMERenewBefore
+	}
+	directoryURL := c.DirectoryURL
+	if directoryURL == "" {
+		directoryURL = autocert.DefaultACMEDirectory
+	}
+	domains := make(map[string]struct{}, len(c.Domains))
+	for _, domain := range c.Domains {
+		domains[domain] = struct{}{}
+	}
+	return &ACME{
+		manager: &autocert.Manager{
+			Prompt:      autocert.AcceptTOS,
+			HostPolicy:  autocert.HostWhitelist(c.Domains...),
+			Cache:       &metricsCache{Cache: autocert.DirCache(cacheDir), expiry: map[string]time.Time{}},
+			RenewBefore: renewBefore,
+			Email:       c.Email,
+			Client:      &acme.Client{DirectoryURL: directoryURL},
+		},
+		domains: domains,
+	}, nil
+}
+
+// TLSConfig returns the tls config of the TLS listeners.
+func (a *ACME) TLSConfig() *tls.Config {
+	tlsConfig := a.manager.TLSConfig()
+	getCertificate := tlsConfig.GetCertificate
+	tlsConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		cert, err := getCertificate(hello)
+		if err != nil {
+			if _, ok := a.domains[strings.TrimSuffix(hello.ServerName, ".")]; ok {
+				log.Errorf("Failed to get acme certificate of %s: %+v", hello.ServerName, err)
+				_metricACMECertificates.WithLabelValues(hello.ServerName, "failed").Inc()
+			}
+			return nil, err
+		}
+		return cert, nil
+	}
+	return tlsConfig
+}
+
+// HTTPHandler serves the HTTP-01 challenge and passes the other requests to the fallback.
+func (a *ACME) HTTPHandler(fallback http.Handler) http.Handler {
+	return a.manager.HTTPHandler(fallback)
+}
+
+// metricsCache records the issued and renewed certificates, autocert writes the cache once a certificate is obtained.
+type metricsCache struct {
+	autocert.Cache
+	lock   sync.Mutex
+	expiry map[string]time.Time
+}
+
+// certDomain returns the domain of the certificate key, the account and challenge keys are ignored.
+func certDomain(key string) (string, bool) {
+	key = strings.TrimSuffix(key, "+rsa")
+	if strings.Contains(key, "+") {
+		return "", false
+	}
+	return key, true
+}
+
+func certNotAfter(data []byte) (time.Time, bool) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return time.Time{}, false
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		leaf, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return leaf.NotAfter, true
+	}
+}
+
+func (c *metricsCache) observe(key string, data []byte, obtained bool) {
+	domain, ok := certDomain(key)
+	if !ok {
+		return
+	}
+	notAfter, ok := certNotAfter(data)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	_, existed := c.expiry[domain]
+	c.expiry[domain] = notAfter
+	c.lock.Unlock()
+	_metricACMECertificateExpiry.WithLabelValues(domain).Set(float64(notAfter.Unix()))
+	if !obtained {
+		return
+	}
+	result := "issued"
+	if existed {
+		result = "renewed"
+	}
+	log.Infof("acme certificate %s of %s, expires at %s", result, domain, notAfter)
+	_metricACMECertificates.WithLabelValues(domain, result).Inc()
+}
+
+func (c *metricsCache) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := c.Cache.Get(ctx, key)
+	if err == nil {
+		c.observe(key, data, false)
+	}
+	return data, err
+}
+
+func (c *metricsCache) Put(ctx context.Context, key string, data []byte) error {
+	if err := c.Cache.Put(ctx, key, data); err != nil {
+		return err
+	}
+	c.observe(key, data, true)
+	return nil
+}
@@ -0,0 +1,374 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type memoryCache struct {
+	sync.Map
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	v, ok := c.Load(key)
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return v.([]byte), nil
+}
+
+func (c *memoryCache) Put(_ context.Context, key string, data []byte) error {
+	c.Store(key, data)
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, key string) error {
+	c.Map.Delete(key)
+	return nil
+}
+
+func certPEM(t *testing.T, domain string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    time.Now(),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+}
+
+func TestACMEMetricsCache(t *testing.T) {
+	ctx := context.Background()
+	c := &metricsCache{Cache: &memoryCache{}, expiry: map[string]time.Time{}}
+	domain := "acme.example.com"
+	notAfter := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+
+	if err := c.Put(ctx, "acme_account+key", []byte("account")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Put(ctx, domain, certPEM(t, domain, notAfter)); err != nil {
+		t.Fatal(err)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificates.WithLabelValues(domain, "issued")); v != 1 {
+		t.Fatalf("want 1 issued but got %v", v)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificateExpiry.WithLabelValues(domain)); v != float64(notAfter.Unix()) {
+		t.Fatalf("want expiry %d but got %v", notAfter.Unix(), v)
+	}
+
+	renewed := notAfter.Add(60 * 24 * time.Hour)
+	if err := c.Put(ctx, domain+"+rsa", certPEM(t, domain, renewed)); err != nil {
+		t.Fatal(err)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificates.WithLabelValues(domain, "renewed")); v != 1 {
+		t.Fatalf("want 1 renewed but got %v", v)
+	}
+	if _, err := c.Get(ctx, domain); err != nil {
+		t.Fatal(err)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificateExpiry.WithLabelValues(domain)); v != float64(notAfter.Unix()) {
+		t.Fatalf("want expiry of the loaded certificate but got %v", v)
+	}
+}
+
+func TestNewACME(t *testing.T) {
+	if _, err := NewACME(&ACMEConfig{}); err == nil {
+		t.Fatal("want error without domains")
+	}
+	a, err := NewACME(&ACMEConfig{Domains: []string{"acme.example.com"}, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.manager.RenewBefore != _defaultACMERenewBefore || a.manager.Client.DirectoryURL != autocert.DefaultACMEDirectory {
+		t.Fatalf("unexpected defaults: %v %s", a.manager.RenewBefore, a.manager.Client.DirectoryURL)
+	}
+	tlsConfig := a.TLSConfig()
+	if len(tlsConfig.NextProtos) == 0 || tlsConfig.NextProtos[len(tlsConfig.NextProtos)-1] != "acme-tls/1" {
+		t.Fatalf("want acme-tls/1 alpn but got %v", tlsConfig.NextProtos)
+	}
+}
+
+// fakeACME is an ACME directory issuing the certificates once the HTTP-01 challenge is served by challenges,
+// the first certificate expires within the default RenewBefore so it is renewed right away.
+type fakeACME struct {
+	*httptest.Server
+	t          *testing.T
+	domain     string
+	challenges http.Handler
+	caKey      *ecdsa.PrivateKey
+	caCert     *x509.Certificate
+
+	lock      sync.Mutex
+	orders    int
+	validated map[int]bool
+	certs     map[int][]byte
+	issued    atomic.Int32
+}
+
+func newFakeACME(t *testing.T, domain string) *fakeACME {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake acme ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeACME{t: t, domain: domain, caKey: caKey, caCert: caCert, validated: map[int]bool{}, certs: map[int][]byte{}}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeACME) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+	if r.URL.Path == "/directory" {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"newNonce":   f.URL + "/nonce",
+			"newAccount": f.URL + "/account",
+			"newOrder":   f.URL + "/order",
+			"revokeCert": f.URL + "/revoke",
+			"keyChange":  f.URL + "/key-change",
+		})
+		return
+	}
+	if r.URL.Path == "/nonce" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	payload := jwsPayload(f.t, r)
+	var id int
+	switch {
+	case r.URL.Path == "/account":
+		w.Header().Set("Location", f.URL+"/account/1")
+		writeJSON(w, http.StatusCreated, map[string]string{"status": "valid"})
+	case r.URL.Path == "/order":
+		f.lock.Lock()
+		f.orders++
+		id = f.orders
+		f.lock.Unlock()
+		w.Header().Set("Location", fmt.Sprintf("%s/order/%d", f.URL, id))
+		writeJSON(w, http.StatusCreated, f.order(id, "pending", ""))
+	case scanID(r.URL.Path, "/order/%d", &id):
+		status := "pending"
+		if f.isValidated(id) {
+			status = "ready"
+		}
+		w.Header().Set("Location", f.URL+r.URL.Path)
+		writeJSON(w, http.StatusOK, f.order(id, status, ""))
+	case scanID(r.URL.Path, "/authz/%d", &id):
+		status := "pending"
+		if f.isValidated(id) {
+			status = "valid"
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status":     status,
+			"identifier": map[string]string{"type": "dns", "value": f.domain},
+			"challenges": []map[string]string{f.challenge(id, status)},
+		})
+	case scanID(r.URL.Path, "/challenge/%d", &id):
+		// validates the challenge against the handler of the gateway like the ACME server would over HTTP
+		token := fmt.Sprintf("token-%d", id)
+		req := httptest.NewRequest(http.MethodGet, "http://"+f.domain+"/.well-known/acme-challenge/"+token, nil)
+		resp := httptest.NewRecorder()
+		f.challenges.ServeHTTP(resp, req)
+		if resp.Code != http.StatusOK || !strings.HasPrefix(resp.Body.String(), token+".") {
+			writeJSON(w, http.StatusForbidden, map[string]string{"type": "urn:ietf:params:acme:error:unauthorized", "detail": resp.Body.String()})
+			return
+		}
+		f.lock.Lock()
+		f.validated[id] = true
+		f.lock.Unlock()
+		writeJSON(w, http.StatusOK, f.challenge(id, "valid"))
+	case scanID(r.URL.Path, "/finalize/%d", &id):
+		var finalize struct {
+			CSR string `json:"csr"`
+		}
+		if err := json.Unmarshal(payload, &finalize); err != nil {
+			f.t.Error(err)
+		}
+		f.issue(id, finalize.CSR)
+		w.Header().Set("Location", fmt.Sprintf("%s/order/%d", f.URL, id))
+		writeJSON(w, http.StatusOK, f.order(id, "valid", fmt.Sprintf("%s/cert/%d", f.URL, id)))
+	case scanID(r.URL.Path, "/cert/%d", &id):
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.certs[id]}))
+		w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw}))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeACME) isValidated(id int) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.validated[id]
+}
+
+func (f *fakeACME) order(id int, status, certURL string) map[string]any {
+	o := map[string]any{
+		"status":         status,
+		"identifiers":    []map[string]string{{"type": "dns", "value": f.domain}},
+		"authorizations": []string{fmt.Sprintf("%s/authz/%d", f.URL, id)},
+		"finalize":       fmt.Sprintf("%s/finalize/%d", f.URL, id),
+	}
+	if certURL != "" {
+		o["certificate"] = certURL
+	}
+	return o
+}
+
+func (f *fakeACME) challenge(id int, status string) map[string]string {
+	return map[string]string{
+		"type":   "http-01",
+		"url":    fmt.Sprintf("%s/challenge/%d", f.URL, id),
+		"token":  fmt.Sprintf("token-%d", id),
+		"status": status,
+	}
+}
+
+func (f *fakeACME) issue(id int, csr64 string) {
+	der, err := base64.RawURLEncoding.DecodeString(csr64)
+	if err != nil {
+		f.t.Error(err)
+		return
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		f.t.Error(err)
+		return
+	}
+	lifetime := 90 * 24 * time.Hour
+	if f.issued.Add(1) == 1 {
+		lifetime = 24 * time.Hour
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(id + 1)),
+		Subject:      pkix.Name{CommonName: csr.DNSNames[0]},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, tmpl, f.caCert, csr.PublicKey, f.caKey)
+	if err != nil {
+		f.t.Error(err)
+		return
+	}
+	f.lock.Lock()
+	f.certs[id] = cert
+	f.lock.Unlock()
+}
+
+func scanID(path, format string, id *int) bool {
+	n, err := fmt.Sscanf(path, format, id)
+	return err == nil && n == 1
+}
+
+func jwsPayload(t *testing.T, r *http.Request) []byte {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		t.Error(err)
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		t.Error(err)
+	}
+	return payload
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestACMEIssuance(t *testing.T) {
+	domain := "issuance.acme.example.com"
+	ca := newFakeACME(t, domain)
+	defer ca.Close()
+	a, err := NewACME(&ACMEConfig{Domains: []string{domain}, DirectoryURL: ca.URL + "/directory", CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.challenges = a.HTTPHandler(http.NotFoundHandler())
+
+	hello := &tls.ClientHelloInfo{ServerName: domain, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}}
+	cert, err := a.TLSConfig().GetCertificate(hello)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Leaf == nil || cert.Leaf.VerifyHostname(domain) != nil {
+		t.Fatalf("want the certificate of %s but got %v", domain, cert.Leaf)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificates.WithLabelValues(domain, "issued")); v != 1 {
+		t.Fatalf("want 1 issued but got %v", v)
+	}
+	// the first certificate expires within RenewBefore, it is renewed in the background
+	deadline := time.Now().Add(10 * time.Second)
+	for testutil.ToFloat64(_metricACMECertificates.WithLabelValues(domain, "renewed")) < 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("want the certificate renewed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificateExpiry.WithLabelValues(domain)); v < float64(time.Now().Add(30*24*time.Hour).Unix()) {
+		t.Fatalf("want the expiry of the renewed certificate but got %v", v)
+	}
+	if v := testutil.ToFloat64(_metricACMECertificates.WithLabelValues(domain, "failed")); v != 0 {
+		t.Fatalf("want no failure but got %v", v)
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// HealthServicePrefix is the path prefix of the reserved gRPC health service,
+// the requests of it are served by the gateway itself instead of the routes.
+const HealthServicePrefix = "/grpc.health.v1.Health/"
+
+// DegradedHeader is the header of the readiness response set once the gateway is degraded.
+const DegradedHeader = "Degraded"
+
+// Health answers grpc.health.v1.Health about the readiness of the gateway,
+// the overall status (the empty service name) is serving once it is ready and until it is shut down or
+// overloaded, the other services report whether they have upstream nodes.
+type Health struct {
+	server *grpc.Server
+	health *health.Server
+
+	grpc           bool
+	readyPath      string
+	degradedStatus int
+
+	lock     sync.Mutex
+	services map[string]bool
+	ready    bool
+	load     *LoadReport
+	shutdown bool
+}
+
+// HealthOption is the option of the health.
+type HealthOption func(*Health)
+
+// WithGRPC sets whether grpc.health.v1.Health is served, default is false so the health service of the
+// upstreams is routed as the other gRPC services.
+func WithGRPC(enabled bool) HealthOption {
+	return func(h *Health) {
+		h.grpc = enabled
+	}
+}
+
+// WithReadyPath serves the readiness of the gateway over HTTP on the path, eg: /readyz.
+func WithReadyPath(path string) HealthOption {
+	return func(h *Health) {
+		h.readyPath = path
+	}
+}
+
+// WithDegradedStatus sets the status code of the readiness response once the gateway is degraded,
+// eg: 429 for the load balancers draining the instances by it, default is 200.
+func WithDegradedStatus(statusCode int) HealthOption {
+	return func(h *Health) {
+		h.degradedStatus = statusCode
+	}
+}
+
+func NewHealth(opts ...HealthOption) *Health {
+	h := &Health{
+		server:         grpc.NewServer(),
+		health:         health.NewServer(),
+		degradedStatus: http.StatusOK,
+		services:       map[string]bool{},
+		load:           &LoadReport{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(h.server, h.health)
+	return h
+}
+
+// Handler serves the gRPC health requests over HTTP/2 and the readiness requests, and passes the others to next.
+func (h *Health) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.grpc && r.ProtoMajor == 2 && strings.HasPrefix(r.URL.Path, HealthServicePrefix) &&
+			strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			h.server.ServeHTTP(w, r)
+			return
+		}
+		if h.readyPath != "" && r.URL.Path == h.readyPath && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+			h.serveReady(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type readyResponse struct {
+	Ready    bool    `json:"ready"`
+	Degraded bool    `json:"degraded"`
+	State    string  `json:"state"`
+	Score    float64 `json:"score"`
+}
+
+func (h *Health) serveReady(w http.ResponseWriter) {
+	h.lock.Lock()
+	ready, load := h.ready && !h.shutdown, h.load
+	h.lock.Unlock()
+	out := &readyResponse{Ready: ready, State: load.State.String(), Score: load.Score}
+	statusCode := http.StatusOK
+	switch {
+	case !ready || load.State == LoadNotReady:
+		out.Ready = false
+		statusCode = http.StatusServiceUnavailable
+	case load.State == LoadDegraded:
+		out.Degraded = true
+		statusCode = h.degradedStatus
+		w.Header().Set(DegradedHeader, "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(out)
+}
+
+// SetReady sets whether the gateway is ready to serve, eg: the config is loaded.
+func (h *Health) SetReady(ready bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.ready = ready
+	h.updateLocked()
+}
+
+// SetLoad sets the load of the gateway, the overall status is not serving while the load is not ready.
+func (h *Health) SetLoad(report *LoadReport) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	changed := h.load.State != report.State
+	h.load = report
+	if changed {
+		h.updateLocked()
+	}
+}
+
+func (h *Health) updateLocked() {
+	h.health.SetServingStatus("", servingStatus(h.ready && h.load.State != LoadNotReady))
+}
+
+// SetServices sets the status of the services, the services absent from the last update become unknown.
+func (h *Health) SetServices(services map[string]bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for name := range h.services {
+		if _, ok := services[name]; !ok {
+			h.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVICE_UNKNOWN)
+		}
+	}
+	for name, serving := range services {
+		if name == "" {
+			continue
+		}
+		h.health.SetServingStatus(name, servingStatus(serving))
+	}
+	h.services = services
+}
+
+// WatchServices updates the status of the services by fn every interval until ctx is done.
+func (h *Health) WatchServices(ctx context.Context, interval time.Duration, fn func() map[string]bool) {
+	h.SetServices(fn())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.SetServices(fn())
+		}
+	}
+}
+
+// Shutdown marks all the services not serving and ignores the later updates,
+// it is called once the gateway starts draining.
+func (h *Health) Shutdown() {
+	h.lock.Lock()
+	h.shutdown = true
+	h.lock.Unlock()
+	h.health.Shutdown()
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestHealth(t *testing.T) {
+	h := NewHealth(WithGRPC(true))
+	routed := make(chan string, 1)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed <- r.URL.Path
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Status", "12")
+	})
+	srv := httptest.NewServer(h2c.NewHandler(h.Handler(next), &http2.Server{}))
+	defer srv.Close()
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	healthClient := healthpb.NewHealthClient(conn)
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+
+	if got := check(""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want not serving before ready but got %s", got)
+	}
+	watch, err := healthClient.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recv := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := watch.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Status
+	}
+	if got := recv(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want not serving but got %s", got)
+	}
+	h.SetReady(true)
+	if got := recv(); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("want serving once ready but got %s", got)
+	}
+
+	h.SetServices(map[string]bool{"helloworld": true, "echo": false})
+	if got := check("helloworld"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("want helloworld serving but got %s", got)
+	}
+	if got := check("echo"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want echo not serving but got %s", got)
+	}
+	h.SetServices(map[string]bool{"helloworld": true})
+	if got := check("echo"); got != healthpb.HealthCheckResponse_SERVICE_UNKNOWN {
+		t.Fatalf("want echo unknown once removed but got %s", got)
+	}
+	if _, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("want not found but got %v", err)
+	}
+
+	// the other gRPC services are routed
+	err = conn.Invoke(ctx, "/helloworld.Greeter/SayHello", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("want the status of the route but got %v", err)
+	}
+	if got := <-routed; got != "/helloworld.Greeter/SayHello" {
+		t.Fatalf("want routed but got %s", got)
+	}
+
+	h.Shutdown()
+	if got := recv(); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want not serving once shut down but got %s", got)
+	}
+	if got := check("helloworld"); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("want helloworld not serving once shut down but got %s", got)
+	}
+}
+
+func TestHealthGRPCDisabled(t *testing.T) {
+	routed := false
+	h := NewHealth().Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { routed = true }))
+	req := httptest.NewRequest(http.MethodPost, HealthServicePrefix+"Check", nil)
+	req.ProtoMajor = 2
+	req.Header.Set("Content-Type", "application/grpc")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if !routed {
+		t.Fatal("want the health service of the upstreams routed by default")
+	}
+}
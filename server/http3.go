@@ -0,0 +1,116 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	_defaultHTTP3IdleTimeout = 30 * time.Second
+	_defaultHTTP3MaxStreams  = 100
+	// the time the clients cache the HTTP/3 endpoint advertised by Alt-Svc
+	_altSvcMaxAge = 24 * time.Hour
+)
+
+// HTTP3Config is the config of the QUIC listeners.
+type HTTP3Config struct {
+	// IdleTimeout closes the connections without activity, default is 30s.
+	IdleTimeout time.Duration
+	// MaxStreams is the max concurrent request streams of a connection, default is 100.
+	MaxStreams int64
+}
+
+// HTTP3ProxyServer is a proxy server serving HTTP/3 over QUIC, sharing the tls config of the tls proxy on
+// the same address.
+type HTTP3ProxyServer struct {
+	server *http3.Server
+
+	lock sync.Mutex
+	conn net.PacketConn
+	// listening is set while the UDP listener is bound, Alt-Svc is only advertised meanwhile
+	listening atomic.Bool
+}
+
+// NewHTTP3Proxy new a gateway server serving HTTP/3 on the UDP address with the tls config.
+func NewHTTP3Proxy(handler http.Handler, addr string, tlsConfig *tls.Config, c *HTTP3Config) *HTTP3ProxyServer {
+	quicConfig := &quic.Config{
+		MaxIdleTimeout:     _defaultHTTP3IdleTimeout,
+		MaxIncomingStreams: _defaultHTTP3MaxStreams,
+	}
+	if c != nil && c.IdleTimeout > 0 {
+		quicConfig.MaxIdleTimeout = c.IdleTimeout
+	}
+	if c != nil && c.MaxStreams > 0 {
+		quicConfig.MaxIncomingStreams = c.MaxStreams
+	}
+	return &HTTP3ProxyServer{
+		server: &http3.Server{
+			Addr:        addr,
+			Handler:     countRequests(addr, handler),
+			TLSConfig:   http3.ConfigureTLSConfig(tlsConfig),
+			QUICConfig:  quicConfig,
+			IdleTimeout: quicConfig.MaxIdleTimeout,
+		},
+	}
+}
+
+// Start the server, failures are logged instead of returned so the tcp listeners keep serving.
+func (s *HTTP3ProxyServer) Start(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", s.server.Addr)
+	if err != nil {
+		log.Errorf("Failed to listen http3 proxy on %s: %+v", s.server.Addr, err)
+		return nil
+	}
+	s.lock.Lock()
+	s.conn = conn
+	s.lock.Unlock()
+	log.Infof("http3 proxy listening on %s", conn.LocalAddr())
+	s.listening.Store(true)
+	err = s.server.Serve(conn)
+	s.listening.Store(false)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, quic.ErrServerClosed) {
+		log.Errorf("Failed to serve http3 proxy on %s: %+v", s.server.Addr, err)
+	}
+	return nil
+}
+
+// Stop the server, GOAWAY is sent to the connections and the requests are waited for until ctx is done.
+func (s *HTTP3ProxyServer) Stop(ctx context.Context) error {
+	log.Info("http3 proxy stopping")
+	s.listening.Store(false)
+	err := s.server.Shutdown(ctx)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// the connection passed to Serve is not closed by the server
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	return err
+}
+
+// AltSvcHandler advertises the HTTP/3 listener on its port to the HTTP/1.1 and HTTP/2 clients, only while
+// the UDP listener is bound so the clients are not sent to a port failed to listen.
+func (s *HTTP3ProxyServer) AltSvcHandler(next http.Handler) (http.Handler, error) {
+	_, port, err := net.SplitHostPort(s.server.Addr)
+	if err != nil {
+		return nil, err
+	}
+	altSvc := fmt.Sprintf(`h3=":%s"; ma=%d`, port, int(_altSvcMaxAge.Seconds()))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor < 3 && s.listening.Load() {
+			w.Header().Set("Alt-Svc", altSvc)
+		}
+		next.ServeHTTP(w, r)
+	}), nil
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestHTTP3Proxy(t *testing.T) {
+	// the certificate of the test server
+	certs := httptest.NewTLSServer(http.NotFoundHandler())
+	certs.Close()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	})
+	s := NewHTTP3Proxy(handler, "127.0.0.1:0", &tls.Config{Certificates: certs.TLS.Certificates}, &HTTP3Config{MaxStreams: 10})
+	go s.Start(context.Background())
+	var addr string
+	for i := 0; i < 100 && addr == ""; i++ {
+		s.lock.Lock()
+		if s.conn != nil {
+			addr = s.conn.LocalAddr().String()
+		}
+		s.lock.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if addr == "" {
+		t.Fatal("the http3 server is not started")
+	}
+
+	tr := &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer tr.Close()
+	resp, err := (&http.Client{Transport: tr}).Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "HTTP/3.0" {
+		t.Fatalf("want the request served over HTTP/3 but got %q", body)
+	}
+	if got := testutil.ToFloat64(_metricServerRequests.WithLabelValues("127.0.0.1:0", "HTTP/3.0")); got != 1 {
+		t.Fatalf("want the request counted by the protocol but got %v", got)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&http.Client{Transport: &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, Timeout: 500 * time.Millisecond}).Get("https://" + addr + "/"); err == nil {
+		t.Fatal("want the requests refused after stop")
+	}
+}
+
+func TestAltSvcHandler(t *testing.T) {
+	s := NewHTTP3Proxy(http.NotFoundHandler(), "0.0.0.0:8443", &tls.Config{}, nil)
+	handler, err := s.AltSvcHandler(http.NotFoundHandler())
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("Alt-Svc"); got != "" {
+		t.Fatalf("want no Alt-Svc before the http3 listener is bound but got %q", got)
+	}
+	s.listening.Store(true)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("Alt-Svc"); got != `h3=":8443"; ma=86400` {
+		t.Fatalf("unexpected Alt-Svc %q", got)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.ProtoMajor = 3
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if got := w.Header().Get("Alt-Svc"); got != "" {
+		t.Fatalf("want no Alt-Svc over HTTP/3 but got %q", got)
+	}
+	if _, err := NewHTTP3Proxy(http.NotFoundHandler(), "8443", &tls.Config{}, nil).AltSvcHandler(http.NotFoundHandler()); err == nil {
+		t.Fatal("want the address without port rejected")
+	}
+}
+
+func TestAltSvcHandlerListenError(t *testing.T) {
+	// the UDP port is taken, the http3 listener fails to bind
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s := NewHTTP3Proxy(http.NotFoundHandler(), conn.LocalAddr().String(), &tls.Config{}, nil)
+	handler, err := s.AltSvcHandler(http.NotFoundHandler())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("Alt-Svc"); got != "" {
+		t.Fatalf("want no Alt-Svc when the http3 listener failed to bind but got %q", got)
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_loadProbeInterval  = 100 * time.Millisecond
+	_loadReportInterval = time.Second
+)
+
+var _metricLoadScore = prometheus.NewGauge(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "load_score",
+	Help:      "The load score of the gateway, the gateway is degraded from 1",
+})
+
+func init() {
+	metrics.Register(_metricLoadScore)
+}
+
+// LoadState is the readiness of the gateway by its load.
+type LoadState int
+
+const (
+	LoadReady LoadState = iota
+	// LoadDegraded is still serving, the load balancers are expected to prefer the other instances.
+	LoadDegraded
+	LoadNotReady
+)
+
+func (s LoadState) String() string {
+	switch s {
+	case LoadDegraded:
+		return "degraded"
+	case LoadNotReady:
+		return "not_ready"
+	default:
+		return "ready"
+	}
+}
+
+// LoadSignals is the signals the load score is computed from.
+type LoadSignals struct {
+	// BBRInflight is the largest ratio of the inflight requests to the limit of the bbr limiters.
+	BBRInflight float64 `json:"bbr_inflight"`
+	// BufferBudget is the ratio of the buffered request bytes to the budget.
+	BufferBudget float64 `json:"buffer_budget"`
+	Goroutines   int     `json:"goroutines"`
+	// ProbeLatency is the largest delay of the probe timer since the last report, it grows when the
+	// goroutines are starved of the CPU.
+	ProbeLatency time.Duration `json:"probe_latency"`
+}
+
+// LoadThresholds is the values of the signals the gateway is degraded at, a zero threshold ignores the signal.
+type LoadThresholds struct {
+	BBRInflight  float64
+	BufferBudget float64
+	Goroutines   int
+	ProbeLatency time.Duration
+	// NotReadyScore is the score the gateway is not ready from, never if 0.
+	NotReadyScore float64
+}
+
+// Score returns the largest ratio of the signals to their thresholds.
+func (t *LoadThresholds) Score(s LoadSignals) float64 {
+	score := 0.0
+	ratio := func(value, threshold float64) {
+		if threshold > 0 {
+			score = max(score, value/threshold)
+		}
+	}
+	ratio(s.BBRInflight, t.BBRInflight)
+	ratio(s.BufferBudget, t.BufferBudget)
+	ratio(float64(s.Goroutines), float64(t.Goroutines))
+	ratio(float64(s.ProbeLatency), float64(t.ProbeLatency))
+	return score
+}
+
+// State returns the state of the score, the gateway is degraded from 1.
+func (t *LoadThresholds) State(score float64) LoadState {
+	switch {
+	case t.NotReadyScore > 0 && score >= t.NotReadyScore:
+		return LoadNotReady
+	case score >= 1:
+		return LoadDegraded
+	default:
+		return LoadReady
+	}
+}
+
+// LoadReport is the load score computed from the signals.
+type LoadReport struct {
+	Score   float64     `json:"score"`
+	State   LoadState   `json:"-"`
+	Signals LoadSignals `json:"signals"`
+}
+
+// LoadMonitor computes the load score every second from the goroutines, the latency probe and the signals
+// sampled by the caller.
+type LoadMonitor struct {
+	thresholds LoadThresholds
+	sample     func(*LoadSignals)
+	onReport   func(*LoadReport)
+	report     atomic.Pointer[LoadReport]
+}
+
+// NewLoadMonitor creates the monitor, sample fills the signals measured outside the server package,
+// onReport is called with every report if not nil.
+func NewLoadMonitor(thresholds LoadThresholds, sample func(*LoadSignals), onReport func(*LoadReport)) *LoadMonitor {
+	m := &LoadMonitor{thresholds: thresholds, sample: sample, onReport: onReport}
+	m.report.Store(&LoadReport{})
+	return m
+}
+
+// Report returns the last report.
+func (m *LoadMonitor) Report() *LoadReport {
+	return m.report.Load()
+}
+
+// Run probes the latency and reports the load until ctx is done.
+func (m *LoadMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(_loadProbeInterval)
+	defer ticker.Stop()
+	last, reported := time.Now(), time.Now()
+	var latency time.Duration
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// the tick is received late once the scheduler is busy, the time of the tick itself is on schedule
+			now := time.Now()
+			latency = max(latency, now.Sub(last)-_loadProbeInterval)
+			last = now
+			if now.Sub(reported) < _loadReportInterval {
+				continue
+			}
+			m.update(latency)
+			latency, reported = 0, now
+		}
+	}
+}
+
+func (m *LoadMonitor) update(latency time.Duration) {
+	signals := LoadSignals{Goroutines: runtime.NumGoroutine(), ProbeLatency: latency}
+	if m.sample != nil {
+		m.sample(&signals)
+	}
+	score := m.thresholds.Score(signals)
+	report := &LoadReport{Score: score, State: m.thresholds.State(score), Signals: signals}
+	m.report.Store(report)
+	_metricLoadScore.Set(score)
+	if m.onReport != nil {
+		m.onReport(report)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadScore(t *testing.T) {
+	thresholds := &LoadThresholds{
+		BBRInflight:   0.5,
+		BufferBudget:  0.25,
+		ProbeLatency:  100 * time.Millisecond,
+		NotReadyScore: 2,
+	}
+	tests := []struct {
+		name    string
+		signals LoadSignals
+		score   float64
+		state   LoadState
+	}{
+		{name: "idle", signals: LoadSignals{}, score: 0, state: LoadReady},
+		{name: "busy", signals: LoadSignals{BBRInflight: 0.25, BufferBudget: 0.125}, score: 0.5, state: LoadReady},
+		{name: "bbr", signals: LoadSignals{BBRInflight: 0.75, BufferBudget: 0.125}, score: 1.5, state: LoadDegraded},
+		{name: "buffer", signals: LoadSignals{BufferBudget: 0.25}, score: 1, state: LoadDegraded},
+		{name: "latency", signals: LoadSignals{ProbeLatency: 250 * time.Millisecond}, score: 2.5, state: LoadNotReady},
+		// the goroutines are ignored without the threshold
+		{name: "goroutines", signals: LoadSignals{Goroutines: 1 << 20}, score: 0, state: LoadReady},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := thresholds.Score(tt.signals)
+			if score != tt.score {
+				t.Fatalf("want score %v but got %v", tt.score, score)
+			}
+			if state := thresholds.State(score); state != tt.state {
+				t.Fatalf("want %s but got %s", tt.state, state)
+			}
+		})
+	}
+	if state := (&LoadThresholds{}).State(100); state != LoadDegraded {
+		t.Fatalf("want degraded without the not ready score but got %s", state)
+	}
+}
+
+func TestHealthReady(t *testing.T) {
+	h := NewHealth(WithReadyPath("/readyz"), WithDegradedStatus(http.StatusTooManyRequests))
+	handler := h.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	check := func(wantCode int, wantDegraded bool) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code != wantCode {
+			t.Fatalf("want %d but got %d", wantCode, w.Code)
+		}
+		out := &readyResponse{}
+		if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Degraded != wantDegraded || (w.Header().Get(DegradedHeader) == "true") != wantDegraded {
+			t.Fatalf("want degraded %v but got %+v, header %q", wantDegraded, out, w.Header().Get(DegradedHeader))
+		}
+	}
+	check(http.StatusServiceUnavailable, false)
+	h.SetReady(true)
+	check(http.StatusOK, false)
+	h.SetLoad(&LoadReport{Score: 1.5, State: LoadDegraded})
+	check(http.StatusTooManyRequests, true)
+	h.SetLoad(&LoadReport{Score: 3, State: LoadNotReady})
+	check(http.StatusServiceUnavailable, false)
+	h.SetLoad(&LoadReport{Score: 0.5, State: LoadReady})
+	check(http.StatusOK, false)
+	h.Shutdown()
+	check(http.StatusServiceUnavailable, false)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("want the other methods routed but got %d", w.Code)
+	}
+}
@@ -0,0 +1,472 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/registry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_defaultHandshakeTimeout    = 10 * time.Second
+	_defaultPassthroughIdle     = 5 * time.Minute
+	_defaultPassthroughDial     = 5 * time.Second
+	_passthroughDiscoveryScheme = "discovery:///"
+)
+
+var (
+	_metricPassthroughConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "passthrough_connections_total",
+		Help:      "The total number of the connections accepted by the passthrough listeners",
+	}, []string{"listener", "route", "result"})
+	_metricPassthroughActive = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "passthrough_active_connections",
+		Help:      "The number of the connections spliced by the passthrough listeners",
+	}, []string{"listener", "route"})
+	_metricPassthroughBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "passthrough_bytes_total",
+		Help:      "The total bytes spliced by the passthrough listeners",
+	}, []string{"listener", "route", "direction"})
+	_metricPassthroughClosed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "passthrough_closed_total",
+		Help:      "The total number of the spliced connections closed by the reason",
+	}, []string{"listener", "route", "reason"})
+)
+
+func init() {
+	metrics.Register(_metricPassthroughConnections, _metricPassthroughActive, _metricPassthroughBytes, _metricPassthroughClosed)
+}
+
+var errClientHelloRead = errors.New("client hello read")
+
+type passthroughRoute struct {
+	name        string
+	hosts       []string
+	targets     []string
+	idle        time.Duration
+	maxDuration time.Duration
+	dialTimeout time.Duration
+	next        atomic.Uint64
+}
+
+// match reports whether the SNI matches the hosts of the route, the SNI is lower case.
+func (r *passthroughRoute) match(sni string) bool {
+	for _, host := range r.hosts {
+		switch {
+		case host == "*":
+			return true
+		case strings.HasPrefix(host, "*."):
+			if strings.HasSuffix(sni, host[1:]) && len(sni) > len(host)-1 {
+				return true
+			}
+		case host == sni:
+			return true
+		}
+	}
+	return false
+}
+
+type passthroughConfig struct {
+	handshakeTimeout time.Duration
+	routes           []*passthroughRoute
+}
+
+func (c *passthroughConfig) route(sni string) *passthroughRoute {
+	for _, r := range c.routes {
+		if r.match(sni) {
+			return r
+		}
+	}
+	return nil
+}
+
+func newPassthroughConfig(c *config.Listener) (*passthroughConfig, error) {
+	if c.Name == "" {
+		return nil, errors.New("listener: name is required")
+	}
+	if c.Mode != config.Listener_TLS_PASSTHROUGH {
+		return nil, fmt.Errorf("listener %s: unsupported mode: %s", c.Name, c.Mode)
+	}
+	out := &passthroughConfig{handshakeTimeout: _defaultHandshakeTimeout}
+	if c.HandshakeTimeout != nil {
+		out.handshakeTimeout = c.HandshakeTimeout.AsDuration()
+	}
+	for i, rc := range c.Routes {
+		if len(rc.Hosts) == 0 || len(rc.Backends) == 0 {
+			return nil, fmt.Errorf("listener %s: route %d: hosts and backends are required", c.Name, i)
+		}
+		r := &passthroughRoute{
+			name:        rc.Name,
+			idle:        _defaultPassthroughIdle,
+			dialTimeout: _defaultPassthroughDial,
+		}
+		if r.name == "" {
+			r.name = rc.Hosts[0]
+		}
+		for _, host := range rc.Hosts {
+			r.hosts = append(r.hosts, strings.ToLower(host))
+		}
+		for _, b := range rc.Backends {
+			if !strings.HasPrefix(b.Target, _passthroughDiscoveryScheme) {
+				if _, _, err := net.SplitHostPort(b.Target); err != nil {
+					return nil, fmt.Errorf("listener %s: route %s: invalid backend: %w", c.Name, r.name, err)
+				}
+			}
+			r.targets = append(r.targets, b.Target)
+		}
+		if rc.IdleTimeout != nil {
+			r.idle = rc.IdleTimeout.AsDuration()
+		}
+		if rc.MaxConnectionDuration != nil {
+			r.maxDuration = rc.MaxConnectionDuration.AsDuration()
+		}
+		if rc.DialTimeout != nil {
+			r.dialTimeout = rc.DialTimeout.AsDuration()
+		}
+		out.routes = append(out.routes, r)
+	}
+	return out, nil
+}
+
+// CheckListener validates the listener config.
+func CheckListener(c *config.Listener) error {
+	_, err := newPassthroughConfig(c)
+	return err
+}
+
+// PassthroughServer routes the TLS connections by the SNI of the ClientHello and splices them to the backends
+// without terminating TLS.
+type PassthroughServer struct {
+	name      string
+	addr      string
+	discovery registry.Discovery
+	config    atomic.Pointer[passthroughConfig]
+	dialer    net.Dialer
+
+	lock   sync.Mutex
+	ln     net.Listener
+	closed bool
+	conns  map[net.Conn]struct{}
+	wg     sync.WaitGroup
+}
+
+// NewPassthrough new a TLS passthrough server of the listener, the discovery resolves the discovery backends.
+func NewPassthrough(c *config.Listener, discovery registry.Discovery) (*PassthroughServer, error) {
+	s := &PassthroughServer{name: c.Name, addr: c.Address, discovery: discovery, conns: map[net.Conn]struct{}{}}
+	if err := s.Update(c); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Update replaces the routes of the server, the connections spliced already are kept.
+func (s *PassthroughServer) Update(c *config.Listener) error {
+	pc, err := newPassthroughConfig(c)
+	if err != nil {
+		return err
+	}
+	if c.Address != s.addr {
+		log.Warnf("the address of the listener %s is changed from %s to %s, it takes effect after restart", s.name, s.addr, c.Address)
+	}
+	s.config.Store(pc)
+	return nil
+}
+
+// Start the server, the error of listening is returned so the app fails to start like the other servers.
+func (s *PassthroughServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("tls passthrough %s: %w", s.name, err)
+	}
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return ln.Close()
+	}
+	s.ln = ln
+	s.lock.Unlock()
+	log.Infof("tls passthrough %s listening on %s", s.name, ln.Addr())
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if !s.track(conn) {
+			conn.Close()
+			return nil
+		}
+		go func() {
+			defer s.untrack(conn)
+			s.serve(conn)
+		}()
+	}
+}
+
+// Stop the server, the spliced connections are closed if they are not finished before the context is done.
+func (s *PassthroughServer) Stop(ctx context.Context) error {
+	log.Infof("tls passthrough %s stopping", s.name)
+	s.lock.Lock()
+	s.closed = true
+	if s.ln != nil {
+		s.ln.Close()
+	}
+	s.lock.Unlock()
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	s.lock.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.lock.Unlock()
+	<-done
+	return nil
+}
+
+func (s *PassthroughServer) track(conn net.Conn) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return false
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *PassthroughServer) untrack(conn net.Conn) {
+	s.lock.Lock()
+	delete(s.conns, conn)
+	s.lock.Unlock()
+	s.wg.Done()
+}
+
+// serve reads the ClientHello and splices the connection to the backend of the matched route.
+func (s *PassthroughServer) serve(conn net.Conn) {
+	defer conn.Close()
+	c := s.config.Load()
+	conn.SetReadDeadline(time.Now().Add(c.handshakeTimeout))
+	sni, hello, err := readClientHello(conn)
+	if err != nil {
+		_metricPassthroughConnections.WithLabelValues(s.name, "", "handshake_failed").Inc()
+		log.Warnf("Failed to read the ClientHello of %s on listener %s: %+v", conn.RemoteAddr(), s.name, err)
+		return
+	}
+	conn.SetReadDeadline(time.Time{})
+	route := c.route(strings.ToLower(sni))
+	if route == nil {
+		_metricPassthroughConnections.WithLabelValues(s.name, "", "no_route").Inc()
+		log.Warnf("No passthrough route of the SNI %q on listener %s", sni, s.name)
+		return
+	}
+	upstream, err := s.dial(route)
+	if err != nil {
+		_metricPassthroughConnections.WithLabelValues(s.name, route.name, "dial_failed").Inc()
+		log.Errorf("Failed to dial the backend of the passthrough route %s on listener %s: %+v", route.name, s.name, err)
+		return
+	}
+	defer upstream.Close()
+	_metricPassthroughConnections.WithLabelValues(s.name, route.name, "spliced").Inc()
+	active := _metricPassthroughActive.WithLabelValues(s.name, route.name)
+	active.Inc()
+	defer active.Dec()
+	if _, err := upstream.Write(hello); err != nil {
+		return
+	}
+	_metricPassthroughBytes.WithLabelValues(s.name, route.name, "upstream").Add(float64(len(hello)))
+	reason := s.splice(conn, upstream, route)
+	_metricPassthroughClosed.WithLabelValues(s.name, route.name, reason).Inc()
+}
+
+// dial dials the backends of the route in round robin until one succeeds.
+func (s *PassthroughServer) dial(route *passthroughRoute) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), route.dialTimeout)
+	defer cancel()
+	var addrs []string
+	for _, target := range route.targets {
+		resolved, err := s.resolve(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, resolved...)
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no backend address")
+	}
+	start := route.next.Add(1)
+	var lastErr error
+	for i := range addrs {
+		addr := addrs[(start+uint64(i))%uint64(len(addrs))]
+		conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// resolve returns the addresses of the target, the discovery instances prefer the tcp or tls endpoints.
+func (s *PassthroughServer) resolve(ctx context.Context, target string) ([]string, error) {
+	service, ok := strings.CutPrefix(target, _passthroughDiscoveryScheme)
+	if !ok {
+		return []string{target}, nil
+	}
+	if s.discovery == nil {
+		return nil, fmt.Errorf("no discovery to resolve %s", target)
+	}
+	instances, err := s.discovery.GetService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, instance := range instances {
+		addr := ""
+		for _, e := range instance.Endpoints {
+			u, err := url.Parse(e)
+			if err != nil {
+				continue
+			}
+			if u.Scheme == "tcp" || u.Scheme == "tls" {
+				addr = u.Host
+				break
+			}
+			if addr == "" {
+				addr = u.Host
+			}
+		}
+		if addr != "" {
+			out = append(out, addr)
+		}
+	}
+	return out, nil
+}
+
+// splice copies the bytes in both directions until both are finished, the idle timeout or the max duration,
+// it returns the reason the connection is closed.
+func (s *PassthroughServer) splice(conn, upstream net.Conn, route *passthroughRoute) string {
+	var lastActive atomic.Int64
+	lastActive.Store(time.Now().UnixNano())
+	var reason atomic.Value
+	reason.Store("closed")
+	closeBoth := func(why string) {
+		reason.CompareAndSwap("closed", why)
+		conn.Close()
+		upstream.Close()
+	}
+	if route.maxDuration > 0 {
+		timer := time.AfterFunc(route.maxDuration, func() { closeBoth("max_duration") })
+		defer timer.Stop()
+	}
+	done := make(chan struct{})
+	defer close(done)
+	if route.idle > 0 {
+		go func() {
+			ticker := time.NewTicker(route.idle / 2)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if time.Since(time.Unix(0, lastActive.Load())) >= route.idle {
+						closeBoth("idle_timeout")
+						return
+					}
+				}
+			}
+		}()
+	}
+	var wg sync.WaitGroup
+	copyTo := func(dst, src net.Conn, direction string) {
+		defer wg.Done()
+		counter := _metricPassthroughBytes.WithLabelValues(s.name, route.name, direction)
+		if _, err := io.Copy(&activeWriter{w: dst, counter: counter, lastActive: &lastActive}, src); err != nil {
+			closeBoth("closed")
+			return
+		}
+		// half close so the peer sees the end of the stream of this direction
+		if tc, ok := dst.(interface{ CloseWrite() error }); ok {
+			tc.CloseWrite()
+		} else {
+			dst.Close()
+		}
+	}
+	wg.Add(2)
+	go copyTo(upstream, conn, "upstream")
+	go copyTo(conn, upstream, "downstream")
+	wg.Wait()
+	return reason.Load().(string)
+}
+
+type activeWriter struct {
+	w          io.Writer
+	counter    prometheus.Counter
+	lastActive *atomic.Int64
+}
+
+func (w *activeWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.counter.Add(float64(n))
+	w.lastActive.Store(time.Now().UnixNano())
+	return n, err
+}
+
+// readClientHello reads the ClientHello of the connection and returns its SNI and the bytes read,
+// the bytes are replayed to the backend.
+func readClientHello(conn net.Conn) (string, []byte, error) {
+	var buf bytes.Buffer
+	var sni string
+	found := false
+	err := tls.Server(&helloConn{Conn: conn, r: io.TeeReader(conn, &buf)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			sni, found = hello.ServerName, true
+			return nil, errClientHelloRead
+		},
+	}).Handshake()
+	if !found {
+		return "", nil, err
+	}
+	return sni, buf.Bytes(), nil
+}
+
+// helloConn records the bytes read by the TLS server and discards the writes, the handshake is never finished.
+type helloConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *helloConn) Read(p []byte) (int, error)  { return c.r.Read(p) }
+func (c *helloConn) Write(p []byte) (int, error) { return 0, io.ErrClosedPipe }
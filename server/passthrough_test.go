@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/aide-family/goddess/pkg/config/v1"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestPassthroughRouteMatch(t *testing.T) {
+	c, err := newPassthroughConfig(&config.Listener{
+		Name: "legacy",
+		Mode: config.Listener_TLS_PASSTHROUGH,
+		Routes: []*config.PassthroughRoute{
+			{Hosts: []string{"DB.example.com"}, Backends: []*config.Backend{{Target: "10.0.0.1:5432"}}},
+			{Name: "legacy", Hosts: []string{"*.legacy.example.com"}, Backends: []*config.Backend{{Target: "discovery:///legacy"}}},
+			{Name: "default", Hosts: []string{"*"}, Backends: []*config.Backend{{Target: "10.0.0.2:443"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sni, want := range map[string]string{
+		"db.example.com":       "DB.example.com",
+		"a.legacy.example.com": "legacy",
+		"legacy.example.com":   "default",
+		"":                     "default",
+	} {
+		if got := c.route(sni); got == nil || got.name != want {
+			t.Errorf("want the SNI %q routed to %s but got %v", sni, want, got)
+		}
+	}
+	for _, invalid := range []*config.Listener{
+		{Name: "x"},
+		{Name: "x", Mode: config.Listener_TLS_PASSTHROUGH, Routes: []*config.PassthroughRoute{{Hosts: []string{"*"}}}},
+		{Name: "x", Mode: config.Listener_TLS_PASSTHROUGH, Routes: []*config.PassthroughRoute{{Hosts: []string{"*"}, Backends: []*config.Backend{{Target: "no-port"}}}}},
+	} {
+		if err := CheckListener(invalid); err == nil {
+			t.Errorf("want the listener %v rejected", invalid)
+		}
+	}
+}
+
+func startPassthrough(t *testing.T, c *config.Listener) string {
+	s, err := NewPassthrough(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start(context.Background())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		s.Stop(ctx)
+	})
+	for i := 0; i < 100; i++ {
+		s.lock.Lock()
+		ln := s.ln
+		s.lock.Unlock()
+		if ln != nil {
+			return ln.Addr().String()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the passthrough server is not started")
+	return ""
+}
+
+func TestPassthrough(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.ServerName))
+	}))
+	defer backend.Close()
+	addr := startPassthrough(t, &config.Listener{
+		Name:    "legacy",
+		Address: "127.0.0.1:0",
+		Mode:    config.Listener_TLS_PASSTHROUGH,
+		Routes: []*config.PassthroughRoute{{
+			Hosts:    []string{"example.com"},
+			Backends: []*config.Backend{{Target: backend.Listener.Addr().String()}},
+		}},
+	})
+	tr := backend.Client().Transport.(*http.Transport).Clone()
+	tr.TLSClientConfig.ServerName = "example.com"
+	tr.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return net.Dial(network, addr)
+	}
+	resp, err := (&http.Client{Transport: tr}).Get("https://example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// the TLS is terminated by the backend
+	if string(body) != "example.com" {
+		t.Fatalf("want the connection spliced to the backend but got %q", body)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: "other.test", InsecureSkipVerify: true})
+	if err == nil {
+		conn.Close()
+		t.Fatal("want the connection without route closed")
+	}
+}
+
+func TestPassthroughIdleTimeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	closed := make(chan struct{})
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// the ClientHello is replayed, then the connection is idle until closed by the gateway
+		io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+	addr := startPassthrough(t, &config.Listener{
+		Name:    "idle",
+		Address: "127.0.0.1:0",
+		Mode:    config.Listener_TLS_PASSTHROUGH,
+		Routes: []*config.PassthroughRoute{{
+			Hosts:       []string{"*"},
+			Backends:    []*config.Backend{{Target: ln.Addr().String()}},
+			IdleTimeout: durationpb.New(50 * time.Millisecond),
+		}},
+	})
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: "idle.test", InsecureSkipVerify: true})
+	if err == nil {
+		conn.Close()
+		t.Fatal("want the handshake not finished by the raw backend")
+	}
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("want the idle connection closed")
+	}
+}
+
+func TestPassthroughListenError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	s, err := NewPassthrough(&config.Listener{
+		Name:    "taken",
+		Address: ln.Addr().String(),
+		Mode:    config.Listener_TLS_PASSTHROUGH,
+		Routes:  []*config.PassthroughRoute{{Hosts: []string{"*"}, Backends: []*config.Backend{{Target: "127.0.0.1:1"}}}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(context.Background()); err == nil {
+		t.Fatal("want the listen error returned")
+	}
+}
@@ -8,7 +8,9 @@ import (
 	"os"
 	"time"
 
+	"github.com/aide-family/goddess/metrics"
 	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
@@ -20,7 +22,15 @@ var (
 	idleTimeout       = time.Second * 120
 )
 
+var _metricServerRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "server_requests_total",
+	Help:      "The total number of the requests accepted by the proxy listeners by the HTTP protocol version",
+}, []string{"listener", "proto"})
+
 func init() {
+	metrics.Register(_metricServerRequests)
 	var err error
 	if v := os.Getenv("PROXY_READ_HEADER_TIMEOUT"); v != "" {
 		if readHeaderTimeout, err = time.ParseDuration(v); err != nil {
@@ -54,7 +64,7 @@ func NewProxy(handler http.Handler, addr string) *ProxyServer {
 	return &ProxyServer{
 		Server: &http.Server{
 			Addr: addr,
-			Handler: h2c.NewHandler(handler, &http2.Server{
+			Handler: h2c.NewHandler(countRequests(addr, handler), &http2.Server{
 				IdleTimeout:          idleTimeout,
 				MaxConcurrentStreams: math.MaxUint32,
 			}),
@@ -66,6 +76,14 @@ func NewProxy(handler http.Handler, addr string) *ProxyServer {
 	}
 }
 
+// countRequests counts the requests of the listener by the protocol, eg: HTTP/1.1, HTTP/2.0 or HTTP/3.0.
+func countRequests(listener string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_metricServerRequests.WithLabelValues(listener, r.Proto).Inc()
+		next.ServeHTTP(w, r)
+	})
+}
+
 // Start the server.
 func (s *ProxyServer) Start(ctx context.Context) error {
 	log.Infof("proxy listening on %s", s.Addr)
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// TLSProxyServer is a proxy server terminating TLS.
+type TLSProxyServer struct {
+	*http.Server
+}
+
+// NewTLSProxy new a gateway server terminating TLS with the tls config.
+func NewTLSProxy(handler http.Handler, addr string, tlsConfig *tls.Config) *TLSProxyServer {
+	return &TLSProxyServer{
+		Server: &http.Server{
+			Addr:              addr,
+			Handler:           countRequests(addr, handler),
+			TLSConfig:         tlsConfig,
+			ReadTimeout:       readTimeout,
+			ReadHeaderTimeout: readHeaderTimeout,
+			WriteTimeout:      writeTimeout,
+			IdleTimeout:       idleTimeout,
+		},
+	}
+}
+
+// Start the server, failures are logged instead of returned so the plaintext listeners keep serving.
+func (s *TLSProxyServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		log.Errorf("Failed to listen tls proxy on %s: %+v", s.Addr, err)
+		return nil
+	}
+	log.Infof("tls proxy listening on %s", s.Addr)
+	err = s.ServeTLS(ln, "", "")
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Errorf("Failed to serve tls proxy on %s: %+v", s.Addr, err)
+	}
+	return nil
+}
+
+// Stop the server.
+func (s *TLSProxyServer) Stop(ctx context.Context) error {
+	log.Info("tls proxy stopping")
+	return s.Shutdown(ctx)
+}
@@ -0,0 +1,189 @@
+// Package tlsreload serves the client certificates of the internal TLS clients from the files re-read
+// periodically, so the short-lived certificates are rotated without restarting the gateway.
+package tlsreload
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultInterval is the interval the files are re-read.
+const DefaultInterval = 30 * time.Second
+
+var (
+	_metricCertNotAfter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "tls_client_cert_not_after_timestamp_seconds",
+		Help:      "The expiry timestamp of the active client certificates of the internal TLS clients",
+	}, []string{"cert"})
+	_metricCertReloadFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "tls_client_cert_reload_failures_total",
+		Help:      "The total number of the failed reloads of the client certificates, the previous certificate is kept",
+	}, []string{"cert"})
+)
+
+func init() {
+	metrics.Register(_metricCertNotAfter, _metricCertReloadFailures)
+}
+
+// Config is the TLS config of an internal client.
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// CAFile verifies the server, the system roots if empty.
+	CAFile     string
+	ServerName string
+}
+
+// ClientConfig returns the TLS config with the client certificate served by the shared provider of the files,
+// nil is returned if c is empty.
+func ClientConfig(c Config) (*tls.Config, error) {
+	if c == (Config{}) {
+		return nil, nil
+	}
+	cfg := &tls.Config{ServerName: c.ServerName}
+	if c.CAFile != "" {
+		b, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		p, err := Get(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.GetClientCertificate = p.GetClientCertificate
+	}
+	return cfg, nil
+}
+
+var (
+	_providersLock sync.Mutex
+	_providers     = map[[2]string]*Provider{}
+)
+
+// Get returns the provider of the files shared by all the clients, the files are re-read every DefaultInterval
+// for the lifetime of the process. The error of the first load is returned.
+func Get(certFile, keyFile string) (*Provider, error) {
+	_providersLock.Lock()
+	defer _providersLock.Unlock()
+	key := [2]string{certFile, keyFile}
+	if p, ok := _providers[key]; ok {
+		return p, nil
+	}
+	p, err := New(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	go p.run(DefaultInterval)
+	_providers[key] = p
+	return p, nil
+}
+
+// Provider holds the current certificate of the files.
+type Provider struct {
+	certFile, keyFile string
+
+	lock     sync.RWMutex
+	cert     *tls.Certificate
+	certPEM  []byte
+	keyPEM   []byte
+	notAfter time.Time
+}
+
+// New loads the certificate of the files, the files are only re-read by Reload.
+func New(certFile, keyFile string) (*Provider, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("both the client certificate and key files are required")
+	}
+	p := &Provider{certFile: certFile, keyFile: keyFile}
+	if _, err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetClientCertificate returns the current certificate, it is expected to be the tls.Config.GetClientCertificate.
+func (p *Provider) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.cert, nil
+}
+
+// NotAfter returns the expiry of the current certificate.
+func (p *Provider) NotAfter() time.Time {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.notAfter
+}
+
+// Reload re-reads the files and replaces the certificate if they changed, the current certificate is kept
+// if the files are invalid.
+func (p *Provider) Reload() (changed bool, err error) {
+	defer func() {
+		if err != nil {
+			_metricCertReloadFailures.WithLabelValues(p.certFile).Inc()
+		}
+	}()
+	certPEM, err := os.ReadFile(p.certFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(p.keyFile)
+	if err != nil {
+		return false, err
+	}
+	p.lock.RLock()
+	unchanged := bytes.Equal(certPEM, p.certPEM) && bytes.Equal(keyPEM, p.keyPEM)
+	p.lock.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("invalid client certificate %s: %w", p.certFile, err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return false, fmt.Errorf("invalid client certificate %s: %w", p.certFile, err)
+	}
+	cert.Leaf = leaf
+	p.lock.Lock()
+	p.cert, p.certPEM, p.keyPEM, p.notAfter = &cert, certPEM, keyPEM, leaf.NotAfter
+	p.lock.Unlock()
+	_metricCertNotAfter.WithLabelValues(p.certFile).Set(float64(leaf.NotAfter.Unix()))
+	return true, nil
+}
+
+func (p *Provider) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		changed, err := p.Reload()
+		if err != nil {
+			log.Errorf("Failed to reload the client certificate %s, keeping the one expiring at %s: %v", p.certFile, p.NotAfter().Format(time.RFC3339), err)
+			continue
+		}
+		if changed {
+			log.Infof("Reloaded the client certificate %s expiring at %s", p.certFile, p.NotAfter().Format(time.RFC3339))
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package tlsreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, certFile, keyFile string, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "gateway"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProviderReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	first := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	writeCert(t, certFile, keyFile, first)
+	p, err := New(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.NotAfter().Equal(first) {
+		t.Fatalf("want %s but got %s", first, p.NotAfter())
+	}
+	if changed, err := p.Reload(); changed || err != nil {
+		t.Fatalf("want unchanged but got %v, %v", changed, err)
+	}
+
+	second := first.Add(24 * time.Hour)
+	writeCert(t, certFile, keyFile, second)
+	if changed, err := p.Reload(); !changed || err != nil {
+		t.Fatalf("want changed but got %v, %v", changed, err)
+	}
+	cert, _ := p.GetClientCertificate(nil)
+	if !cert.Leaf.NotAfter.Equal(second) {
+		t.Fatalf("want the rotated certificate but got %s", cert.Leaf.NotAfter)
+	}
+
+	// the half written pair keeps the previous certificate
+	if err := os.WriteFile(keyFile, []byte("invalid"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.Reload(); err == nil {
+		t.Fatal("want the error of the invalid key")
+	}
+	if cert, _ := p.GetClientCertificate(nil); !cert.Leaf.NotAfter.Equal(second) {
+		t.Fatalf("want the previous certificate but got %s", cert.Leaf.NotAfter)
+	}
+}
+
+func TestClientConfig(t *testing.T) {
+	cfg, err := ClientConfig(Config{})
+	if cfg != nil || err != nil {
+		t.Fatalf("want no config but got %v, %v", cfg, err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCert(t, certFile, keyFile, time.Now().Add(time.Hour))
+	cfg, err = ClientConfig(Config{CertFile: certFile, KeyFile: keyFile, ServerName: "ctrl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ServerName != "ctrl" || cfg.GetClientCertificate == nil {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if _, err := ClientConfig(Config{CertFile: certFile}); err == nil {
+		t.Fatal("want the error of the missing key")
+	}
+}
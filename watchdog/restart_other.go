@@ -0,0 +1,10 @@
+//go:build !unix
+
+package watchdog
+
+import "errors"
+
+// Restart is not supported without exec, the caller exits instead.
+func Restart() error {
+	return errors.New("restart by exec is not supported on this platform")
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package watchdog
+
+import (
+	"os"
+	"syscall"
+)
+
+// Restart replaces the process by a new one of the same executable, arguments and environment, the pid is kept
+// so the supervisors see the same process. It returns only if the exec fails.
+func Restart() error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(self, os.Args, os.Environ())
+}
@@ -0,0 +1,297 @@
+// Package watchdog samples the resources of the gateway and degrades it before it runs out of them, eg: a leak
+// of goroutines in a middleware.
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	goddessmetrics "github.com/aide-family/goddess/metrics"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/transport/http/status"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	_defaultInterval = 5 * time.Second
+	// the gateway leaves the degraded mode once all the readings are below this ratio of the soft limits
+	_recoverRatio   = 0.9
+	_shedRetryAfter = 1
+)
+
+// the resources sampled
+const (
+	resourceGoroutines = "goroutines"
+	resourceHeapInuse  = "heap_inuse_bytes"
+	resourceOpenFDs    = "open_fds"
+)
+
+var (
+	_metricReading = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "watchdog_reading",
+		Help:      "The last reading of the resource sampled by the watchdog: goroutines, heap_inuse_bytes or open_fds",
+	}, []string{"resource"})
+	_metricLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "watchdog_limit",
+		Help:      "The soft and the hard limits of the resources configured for the watchdog",
+	}, []string{"resource", "level"})
+	_metricDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "watchdog_degraded",
+		Help:      "1 if the gateway is in the degraded mode of the watchdog",
+	})
+	_metricTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "watchdog_transitions_total",
+		Help:      "The total number of the transitions of the watchdog: degraded, recovered or hard_limit",
+	}, []string{"to"})
+	_metricShedRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "watchdog_shed_requests_total",
+		Help:      "The total number of the requests shed with 503 in the degraded mode",
+	})
+)
+
+func init() {
+	goddessmetrics.Register(_metricReading, _metricLimit, _metricDegraded, _metricTransitions, _metricShedRequests)
+}
+
+// _optionalDisabled is set by the watchdog with DisableOptional in the degraded mode, the optional features are
+// of the process.
+var _optionalDisabled atomic.Bool
+
+// OptionalDisabled reports whether the optional features are disabled by the degraded mode, eg: the capture of
+// the upstream bodies and the X-Capture requests, and the recording of the streams.
+func OptionalDisabled() bool {
+	return _optionalDisabled.Load()
+}
+
+// Readings is a sample of the resources, OpenFDs is -1 if it is not supported by the platform.
+type Readings struct {
+	Goroutines int    `json:"goroutines"`
+	HeapInuse  uint64 `json:"heap_inuse_bytes"`
+	OpenFDs    int    `json:"open_fds"`
+}
+
+// Limits is the limits of the resources, a zero limit ignores the resource.
+type Limits struct {
+	Goroutines int
+	HeapInuse  uint64
+	OpenFDs    int
+}
+
+// exceeded returns the resources of the readings at their limits scaled by ratio.
+func (l *Limits) exceeded(r Readings, ratio float64) []string {
+	var out []string
+	if l.Goroutines > 0 && float64(r.Goroutines) >= float64(l.Goroutines)*ratio {
+		out = append(out, resourceGoroutines)
+	}
+	if l.HeapInuse > 0 && float64(r.HeapInuse) >= float64(l.HeapInuse)*ratio {
+		out = append(out, resourceHeapInuse)
+	}
+	if l.OpenFDs > 0 && r.OpenFDs >= 0 && float64(r.OpenFDs) >= float64(l.OpenFDs)*ratio {
+		out = append(out, resourceOpenFDs)
+	}
+	return out
+}
+
+func (l *Limits) export(level string) {
+	_metricLimit.WithLabelValues(resourceGoroutines, level).Set(float64(l.Goroutines))
+	_metricLimit.WithLabelValues(resourceHeapInuse, level).Set(float64(l.HeapInuse))
+	_metricLimit.WithLabelValues(resourceOpenFDs, level).Set(float64(l.OpenFDs))
+}
+
+// HardAction is the action of the watchdog at the hard limits.
+type HardAction int
+
+const (
+	// HardActionNone logs and counts the hard limits only.
+	HardActionNone HardAction = iota
+	// HardActionExit stops the gateway gracefully and exits with a non-zero code, eg: to be restarted by the
+	// supervisor.
+	HardActionExit
+	// HardActionRestart stops the gateway gracefully and executes itself again with the same arguments.
+	HardActionRestart
+)
+
+var _hardActionNames = map[string]HardAction{"none": HardActionNone, "exit": HardActionExit, "restart": HardActionRestart}
+
+// ParseHardAction parses the action at the hard limits: none, exit or restart.
+func ParseHardAction(s string) (HardAction, error) {
+	a, ok := _hardActionNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown watchdog hard action %q, want none, exit or restart", s)
+	}
+	return a, nil
+}
+
+// Config is the config of the watchdog, the zero config reports the readings only.
+type Config struct {
+	// Interval is the interval of the samples, default is 5s.
+	Interval time.Duration
+	// the degraded mode is entered at the soft limits and left once the readings recover
+	Soft Limits
+	Hard Limits
+	// ShedFraction is the fraction of the requests shed with 503 in the degraded mode.
+	ShedFraction float64
+	// DisableOptional disables the optional features in the degraded mode, see OptionalDisabled.
+	DisableOptional bool
+	// OnHardLimit is called once the hard limits are first reached if not nil, the action is taken by the caller.
+	OnHardLimit func(Readings)
+}
+
+// Watchdog samples the resources and switches the degraded mode of the gateway.
+type Watchdog struct {
+	c        Config
+	sample   func() Readings
+	hard     atomic.Bool
+	degraded atomic.Bool
+}
+
+// New creates the watchdog, the readings are reported once it runs.
+func New(c Config) (*Watchdog, error) {
+	if c.ShedFraction < 0 || c.ShedFraction > 1 {
+		return nil, fmt.Errorf("watchdog shed fraction %v must be in [0, 1]", c.ShedFraction)
+	}
+	if c.Interval <= 0 {
+		c.Interval = _defaultInterval
+	}
+	c.Soft.export("soft")
+	c.Hard.export("hard")
+	return &Watchdog{c: c, sample: sample}, nil
+}
+
+// Degraded reports whether the gateway is in the degraded mode.
+func (w *Watchdog) Degraded() bool {
+	return w.degraded.Load()
+}
+
+// Run samples the resources every interval until ctx is done.
+func (w *Watchdog) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.c.Interval)
+	defer ticker.Stop()
+	for {
+		w.check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *Watchdog) check() {
+	r := w.sample()
+	_metricReading.WithLabelValues(resourceGoroutines).Set(float64(r.Goroutines))
+	_metricReading.WithLabelValues(resourceHeapInuse).Set(float64(r.HeapInuse))
+	if r.OpenFDs >= 0 {
+		_metricReading.WithLabelValues(resourceOpenFDs).Set(float64(r.OpenFDs))
+	}
+	if exceeded := w.c.Hard.exceeded(r, 1); len(exceeded) > 0 && !w.hard.Swap(true) {
+		_metricTransitions.WithLabelValues("hard_limit").Inc()
+		log.Errorw(log.DefaultMessageKey, "Watchdog hard limits are reached", "resources", strings.Join(exceeded, ","),
+			"goroutines", r.Goroutines, "heap_inuse_bytes", r.HeapInuse, "open_fds", r.OpenFDs)
+		if w.c.OnHardLimit != nil {
+			w.c.OnHardLimit(r)
+		}
+	}
+	if !w.degraded.Load() {
+		if exceeded := w.c.Soft.exceeded(r, 1); len(exceeded) > 0 {
+			w.setDegraded(true)
+			_metricDegraded.Set(1)
+			_metricTransitions.WithLabelValues("degraded").Inc()
+			log.Warnw(log.DefaultMessageKey, "Watchdog soft limits are reached, entering the degraded mode", "resources", strings.Join(exceeded, ","),
+				"goroutines", r.Goroutines, "heap_inuse_bytes", r.HeapInuse, "open_fds", r.OpenFDs,
+				"shed_fraction", w.c.ShedFraction, "disable_optional", w.c.DisableOptional)
+		}
+		return
+	}
+	if len(w.c.Soft.exceeded(r, _recoverRatio)) == 0 {
+		w.setDegraded(false)
+		_metricDegraded.Set(0)
+		_metricTransitions.WithLabelValues("recovered").Inc()
+		log.Infow(log.DefaultMessageKey, "Watchdog readings are recovered, leaving the degraded mode",
+			"goroutines", r.Goroutines, "heap_inuse_bytes", r.HeapInuse, "open_fds", r.OpenFDs)
+	}
+}
+
+func (w *Watchdog) setDegraded(degraded bool) {
+	w.degraded.Store(degraded)
+	if w.c.DisableOptional {
+		_optionalDisabled.Store(degraded)
+	}
+}
+
+// Handler sheds the fraction of the requests with 503 in the degraded mode, it is expected to wrap the proxied
+// requests only, the debug and the health requests are kept to diagnose the gateway.
+func (w *Watchdog) Handler(next http.Handler) http.Handler {
+	if w.c.ShedFraction <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !w.degraded.Load() || rand.Float64() >= w.c.ShedFraction {
+			next.ServeHTTP(rw, req)
+			return
+		}
+		_metricShedRequests.Inc()
+		rw.Header().Set("Retry-After", strconv.Itoa(_shedRetryAfter))
+		if req.ProtoMajor == 2 && strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+			rw.Header().Set("Content-Type", "application/grpc")
+			rw.Header().Set("Grpc-Status", strconv.Itoa(int(status.ToGRPCCode(http.StatusServiceUnavailable))))
+			rw.Header().Set("Grpc-Message", "gateway is degraded")
+			rw.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Error(rw, "gateway is degraded", http.StatusServiceUnavailable)
+	})
+}
+
+var _heapSamples = []metrics.Sample{
+	{Name: "/memory/classes/heap/objects:bytes"},
+	{Name: "/memory/classes/heap/unused:bytes"},
+}
+
+func sample() Readings {
+	// the heap in use is the objects and the unused space of the spans in use, without stopping the world
+	samples := make([]metrics.Sample, len(_heapSamples))
+	copy(samples, _heapSamples)
+	metrics.Read(samples)
+	var heap uint64
+	for _, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			heap += s.Value.Uint64()
+		}
+	}
+	return Readings{Goroutines: runtime.NumGoroutine(), HeapInuse: heap, OpenFDs: openFDs()}
+}
+
+// openFDs returns the number of the open file descriptors, -1 without /proc.
+func openFDs() int {
+	dir, err := os.Open("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	defer dir.Close()
+	names, err := dir.Readdirnames(-1)
+	if err != nil {
+		return -1
+	}
+	// the descriptor of the directory itself
+	return len(names) - 1
+}
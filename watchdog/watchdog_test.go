@@ -0,0 +1,120 @@
+package watchdog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestWatchdog(t *testing.T, c Config, readings *Readings) *Watchdog {
+	t.Helper()
+	w, err := New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.sample = func() Readings { return *readings }
+	t.Cleanup(func() {
+		_optionalDisabled.Store(false)
+		_metricDegraded.Set(0)
+	})
+	return w
+}
+
+func TestDegradedMode(t *testing.T) {
+	readings := &Readings{Goroutines: 100, HeapInuse: 10 << 20, OpenFDs: -1}
+	hard := 0
+	w := newTestWatchdog(t, Config{
+		Soft:            Limits{Goroutines: 1000, HeapInuse: 100 << 20, OpenFDs: 50},
+		Hard:            Limits{Goroutines: 5000},
+		DisableOptional: true,
+		OnHardLimit:     func(Readings) { hard++ },
+	}, readings)
+	degraded := testutil.ToFloat64(_metricTransitions.WithLabelValues("degraded"))
+	recovered := testutil.ToFloat64(_metricTransitions.WithLabelValues("recovered"))
+
+	for _, step := range []struct {
+		readings Readings
+		degraded bool
+	}{
+		// the open file descriptors are ignored without /proc
+		{readings: Readings{Goroutines: 100, HeapInuse: 10 << 20, OpenFDs: -1}},
+		{readings: Readings{Goroutines: 100, HeapInuse: 100 << 20, OpenFDs: -1}, degraded: true},
+		// below the soft limit but not recovered yet
+		{readings: Readings{Goroutines: 950, HeapInuse: 10 << 20, OpenFDs: 10}, degraded: true},
+		{readings: Readings{Goroutines: 100, HeapInuse: 10 << 20, OpenFDs: 10}},
+		{readings: Readings{Goroutines: 100, HeapInuse: 10 << 20, OpenFDs: 60}, degraded: true},
+		{readings: Readings{Goroutines: 6000, HeapInuse: 10 << 20, OpenFDs: 60}, degraded: true},
+		{readings: Readings{Goroutines: 7000, HeapInuse: 10 << 20, OpenFDs: 60}, degraded: true},
+	} {
+		*readings = step.readings
+		w.check()
+		if w.Degraded() != step.degraded || OptionalDisabled() != step.degraded {
+			t.Fatalf("want degraded %v at %+v", step.degraded, step.readings)
+		}
+	}
+	if hard != 1 {
+		t.Fatalf("want the hard limits reported once but got %d", hard)
+	}
+	if got := testutil.ToFloat64(_metricTransitions.WithLabelValues("degraded")) - degraded; got != 2 {
+		t.Fatalf("want 2 transitions to degraded but got %v", got)
+	}
+	if got := testutil.ToFloat64(_metricTransitions.WithLabelValues("recovered")) - recovered; got != 1 {
+		t.Fatalf("want 1 transition to recovered but got %v", got)
+	}
+	if testutil.ToFloat64(_metricReading.WithLabelValues(resourceGoroutines)) != 7000 {
+		t.Fatal("want the goroutines reported")
+	}
+}
+
+func TestShed(t *testing.T) {
+	readings := &Readings{Goroutines: 10}
+	w := newTestWatchdog(t, Config{Soft: Limits{Goroutines: 5}, ShedFraction: 1}, readings)
+	handler := w.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := do(httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("want the requests served before the degraded mode but got %d", rec.Code)
+	}
+	w.check()
+	if OptionalDisabled() {
+		t.Fatal("want the optional features kept without disable_optional")
+	}
+	shed := testutil.ToFloat64(_metricShedRequests)
+	if rec := do(httptest.NewRequest(http.MethodGet, "/", nil)); rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("want the request shed but got %d", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/helloworld.Greeter/SayHello", nil)
+	req.ProtoMajor = 2
+	req.Header.Set("Content-Type", "application/grpc")
+	if rec := do(req); rec.Code != http.StatusOK || rec.Header().Get("Grpc-Status") != "14" {
+		t.Fatalf("want the grpc request shed with unavailable but got %d %v", rec.Code, rec.Header())
+	}
+	if got := testutil.ToFloat64(_metricShedRequests) - shed; got != 2 {
+		t.Fatalf("want 2 requests shed but got %v", got)
+	}
+	// the degraded mode is of the watchdog
+	other := newTestWatchdog(t, Config{Soft: Limits{Goroutines: 100}, ShedFraction: 1}, readings)
+	if other.Degraded() {
+		t.Fatal("want the other watchdog not degraded before it checks")
+	}
+}
+
+func TestConfig(t *testing.T) {
+	if _, err := New(Config{ShedFraction: 1.5}); err == nil {
+		t.Fatal("want the shed fraction out of range rejected")
+	}
+	if a, err := ParseHardAction("restart"); err != nil || a != HardActionRestart {
+		t.Fatalf("want restart but got %v %v", a, err)
+	}
+	if _, err := ParseHardAction("panic"); err == nil {
+		t.Fatal("want the unknown action rejected")
+	}
+	if r := sample(); r.Goroutines <= 0 || r.HeapInuse == 0 {
+		t.Fatalf("want the readings sampled but got %+v", r)
+	}
+}